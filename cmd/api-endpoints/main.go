@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"path/filepath"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/handlers/apidoc"
+
+	"github.com/swaggo/swag"
+)
+
+// api-endpoints generates handlers/api_endpoints.go from the swag annotations of the handlers.
+// The annotations are parsed by swag first, so that the generation fails for annotations swag init would reject.
+func main() {
+	root := flag.String("root", ".", "Root directory of the repository")
+	flag.Parse()
+
+	parser := swag.New(swag.SetExcludedDirsAndFiles("bin,_gitignore,.vscode,.idea"))
+	parser.ParseDependency = true
+	err := parser.ParseAPI(*root, "./handlers/api.go", 1)
+	if err != nil {
+		log.Fatalf("error parsing the swag annotations: %v", err)
+	}
+
+	handlersDir := filepath.Join(*root, "handlers")
+	err = apidoc.Write(handlersDir, filepath.Join(handlersDir, "api_endpoints.go"))
+	if err != nil {
+		log.Fatalf("error generating the api endpoints: %v", err)
+	}
+}
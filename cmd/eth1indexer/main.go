@@ -159,6 +159,35 @@ func main() {
 		logrus.Fatalf("node chain id mismatch, wanted %v got %v", chainId, nodeChainId.String())
 	}
 
+	if utils.Config.Bigtable.Backend == "postgres" {
+		// the postgres storage indexes blocks while saving them and does not track balances, ens names or token prices
+		ps, err := db.InitPostgresStorage(chainId)
+		if err != nil {
+			logrus.Fatalf("error initializing postgres storage: %v", err)
+		}
+		db.BigtableClient = ps
+
+		if *block != 0 {
+			err = IndexFromNode(ps, client, *block, *block, *concurrencyBlocks, *traceMode)
+			if err != nil {
+				logrus.WithError(err).Fatalf("error indexing from node, start: %v end: %v concurrency: %v", *block, *block, *concurrencyBlocks)
+			}
+			logrus.Infof("indexing of block %v completed", *block)
+			return
+		}
+
+		if *endBlocks != 0 && *startBlocks < *endBlocks {
+			err = IndexFromNode(ps, client, *startBlocks, *endBlocks, *concurrencyBlocks, *traceMode)
+			if err != nil {
+				logrus.WithError(err).Fatalf("error indexing from node, start: %v end: %v concurrency: %v", *startBlocks, *endBlocks, *concurrencyBlocks)
+			}
+			return
+		}
+
+		IndexFromNodeLoop(ps, client, *reorgDepth, *offsetBlocks, *bulkBlocks, *concurrencyBlocks, *traceMode)
+		return
+	}
+
 	bt, err := db.InitBigtable(utils.Config.Bigtable.Project, utils.Config.Bigtable.Instance, chainId, utils.Config.RedisCacheEndpoint)
 	if err != nil {
 		logrus.Fatalf("error connecting to bigtable: %v", err)
@@ -389,6 +418,81 @@ func main() {
 	// utils.WaitForCtrlC()
 }
 
+// blockIndexer is the part of the storage needed to index blocks from the node and to handle chain reorgs
+type blockIndexer interface {
+	SaveBlock(block *types.Eth1Block) error
+	DeleteBlock(blockNumber uint64, blockHash []byte) error
+	GetBlockFromBlocksTable(number uint64) (*types.Eth1Block, error)
+	GetLastBlockInBlocksTable() (int, error)
+	SetLastBlockInBlocksTable(lastBlock int64) error
+	SetLastBlockInDataTable(lastBlock int64) error
+}
+
+// IndexFromNodeLoop keeps the blocks table in sync with the node, it is used by storages that index blocks while saving them
+func IndexFromNodeLoop(bi blockIndexer, client *rpc.ErigonClient, reorgDepth int, offset, bulk, concurrency int64, traceMode string) {
+	for ; ; time.Sleep(time.Second * 14) {
+		err := HandleChainReorgs(bi, client, reorgDepth)
+		if err != nil {
+			logrus.Errorf("error handling chain reorgs: %v", err)
+			continue
+		}
+
+		lastBlockFromNode, err := client.GetLatestEth1BlockNumber()
+		if err != nil {
+			logrus.Errorf("error retrieving latest eth block number: %v", err)
+			continue
+		}
+
+		lastBlockFromBlocksTable, err := bi.GetLastBlockInBlocksTable()
+		if err != nil {
+			logrus.Errorf("error retrieving last blocks from blocks table: %v", err)
+			continue
+		}
+
+		logrus.WithFields(
+			logrus.Fields{
+				"node":   lastBlockFromNode,
+				"blocks": lastBlockFromBlocksTable,
+			},
+		).Infof("last blocks")
+
+		if lastBlockFromNode > 0 && lastBlockFromBlocksTable < int(lastBlockFromNode) {
+			logrus.Infof("missing blocks %v to %v in blocks table, indexing ...", lastBlockFromBlocksTable+1, lastBlockFromNode)
+
+			startBlock := int64(lastBlockFromBlocksTable+1) - offset
+			if startBlock < 0 {
+				startBlock = 0
+			}
+
+			bulkBlocks := bulk
+			if bulkBlocks <= 0 || bulkBlocks > int64(lastBlockFromNode)-startBlock+1 {
+				bulkBlocks = int64(lastBlockFromNode) - startBlock + 1
+			}
+
+			for startBlock <= int64(lastBlockFromNode) {
+				endBlock := startBlock + bulkBlocks - 1
+				if endBlock > int64(lastBlockFromNode) {
+					endBlock = int64(lastBlockFromNode)
+				}
+
+				err = IndexFromNode(bi, client, startBlock, endBlock, concurrency, traceMode)
+				if err != nil {
+					utils.LogError(err, "error indexing from node", 0, map[string]interface{}{"start": startBlock, "end": endBlock, "concurrency": concurrency})
+					break
+				}
+
+				startBlock = endBlock + 1
+			}
+			if err != nil {
+				continue
+			}
+		}
+
+		logrus.Infof("index run completed")
+		services.ReportStatus("eth1indexer", "Running", nil)
+	}
+}
+
 func ImportEnsUpdatesLoop(bt *db.Bigtable, client *rpc.ErigonClient, batchSize int64) {
 	for {
 		time.Sleep(time.Second * 5)
@@ -496,7 +600,7 @@ func UpdateTokenPrices(bt *db.Bigtable, client *rpc.ErigonClient, tokenListPath
 	return bt.SaveERC20TokenPrices(tokenPrices)
 }
 
-func HandleChainReorgs(bt *db.Bigtable, client *rpc.ErigonClient, depth int) error {
+func HandleChainReorgs(bt blockIndexer, client *rpc.ErigonClient, depth int) error {
 	ctx := context.Background()
 	// get latest block from the node
 	latestNodeBlock, err := client.GetNativeClient().BlockByNumber(ctx, nil)
@@ -615,7 +719,7 @@ func ProcessMetadataUpdates(bt *db.Bigtable, client *rpc.ErigonClient, prefix st
 	}
 }
 
-func IndexFromNode(bt *db.Bigtable, client *rpc.ErigonClient, start, end, concurrency int64, traceMode string) error {
+func IndexFromNode(bt blockIndexer, client *rpc.ErigonClient, start, end, concurrency int64, traceMode string) error {
 	ctx := context.Background()
 	g, gCtx := errgroup.WithContext(ctx)
 	g.SetLimit(int(concurrency))
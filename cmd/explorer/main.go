@@ -178,7 +178,7 @@ func main() {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		bt, err := db.InitBigtable(utils.Config.Bigtable.Project, utils.Config.Bigtable.Instance, fmt.Sprintf("%d", utils.Config.Chain.ClConfig.DepositChainID), utils.Config.RedisCacheEndpoint)
+		bt, err := db.InitStorage(utils.Config.Bigtable.Project, utils.Config.Bigtable.Instance, fmt.Sprintf("%d", utils.Config.Chain.ClConfig.DepositChainID), utils.Config.RedisCacheEndpoint)
 		if err != nil {
 			logrus.Fatalf("error connecting to bigtable: %v", err)
 		}
@@ -234,7 +234,12 @@ func main() {
 		var rpcClient rpc.Client
 
 		chainID := new(big.Int).SetUint64(utils.Config.Chain.ClConfig.DepositChainID)
-		if utils.Config.Indexer.Node.Type == "lighthouse" {
+		if utils.Config.Indexer.Node.Type == "lighthouse" && len(cfg.Indexer.Node.Endpoints) > 0 {
+			rpcClient, err = rpc.NewMultiClient(cfg.Indexer.Node.Endpoints, chainID, *cfg.Indexer.Node.MaxSyncDistance)
+			if err != nil {
+				utils.LogFatal(err, "new explorer multi client error", 0)
+			}
+		} else if utils.Config.Indexer.Node.Type == "lighthouse" {
 			rpcClient, err = rpc.NewLighthouseClient("http://"+cfg.Indexer.Node.Host+":"+cfg.Indexer.Node.Port, chainID)
 			if err != nil {
 				utils.LogFatal(err, "new explorer lighthouse client error", 0)
@@ -258,6 +263,7 @@ func main() {
 		router := mux.NewRouter()
 
 		apiV1Router := router.PathPrefix("/api/v1").Subrouter()
+		apiV1Router.HandleFunc("/openapi.json", handlers.ApiOpenAPISpec).Methods("GET", "OPTIONS")
 		router.PathPrefix("/api/v1/docs/").Handler(httpSwagger.WrapHandler)
 		apiV1Router.HandleFunc("/latestState", handlers.ApiLatestState).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/epoch/{epoch}", handlers.ApiEpoch).Methods("GET", "OPTIONS")
@@ -269,8 +275,12 @@ func main() {
 		apiV1Router.HandleFunc("/slot/{slot}/deposits", handlers.ApiSlotDeposits).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/slot/{slot}/attesterslashings", handlers.ApiSlotAttesterSlashings).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/slot/{slot}/proposerslashings", handlers.ApiSlotProposerSlashings).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/reorgs", handlers.ApiReorgs).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/slot/{slot}/voluntaryexits", handlers.ApiSlotVoluntaryExits).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/slot/{slot}/withdrawals", handlers.ApiSlotWithdrawals).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/slot/{slot}/depositrequests", handlers.ApiSlotDepositRequests).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/slot/{slot}/withdrawalrequests", handlers.ApiSlotWithdrawalRequests).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/slot/{slot}/consolidations", handlers.ApiSlotConsolidations).Methods("GET", "OPTIONS")
 
 		// deprecated, use slot equivalents
 		apiV1Router.HandleFunc("/block/{slotOrHash}", handlers.ApiSlots).Methods("GET", "OPTIONS")
@@ -327,6 +337,11 @@ func main() {
 		apiV1Router.HandleFunc("/validator/{indexOrPubkey}/widget", handlers.GetMobileWidgetStatsGet).Methods("GET")
 		apiV1Router.HandleFunc("/dashboard/widget", handlers.GetMobileWidgetStatsPost).Methods("POST")
 		apiV1Router.HandleFunc("/ens/lookup/{domain}", handlers.ResolveEnsDomain).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/graphql", handlers.ApiGraphQL).Methods("POST", "OPTIONS")
+		apiV1Router.HandleFunc("/stream", handlers.ApiStream).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/exports", handlers.ApiExportCreate).Methods("POST", "OPTIONS")
+		apiV1Router.HandleFunc("/exports", handlers.ApiExports).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/exports/{id}", handlers.ApiExport).Methods("GET", "OPTIONS")
 		apiV1Router.Use(utils.CORSMiddleware)
 
 		apiV1AuthRouter := apiV1Router.PathPrefix("/user").Subrouter()
@@ -348,10 +363,27 @@ func main() {
 		apiV1AuthRouter.HandleFunc("/stats", handlers.ClientStats).Methods("GET", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/stats/{offset}/{limit}", handlers.ClientStats).Methods("GET", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/ethpool", handlers.RegisterEthpoolSubscription).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/webhooks/{webhookID}/deliveries", handlers.ApiUserWebhookDeliveries).Methods("GET", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/webhooks/{webhookID}/deliveries/{deliveryID}/replay", handlers.ApiUserWebhookDeliveryReplay).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/notifications/history", handlers.ApiUserNotificationHistory).Methods("GET", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/notifications/history/unread", handlers.ApiUserNotificationHistoryUnread).Methods("GET", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/notifications/history/read", handlers.ApiUserNotificationHistoryRead).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/notifications/history/acknowledge", handlers.ApiUserNotificationHistoryAcknowledge).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/notifications/rules", handlers.ApiUserNotificationRules).Methods("GET", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/notifications/rules", handlers.ApiUserNotificationRuleAdd).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/notifications/rules/{ruleID}/update", handlers.ApiUserNotificationRuleUpdate).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/notifications/rules/{ruleID}/delete", handlers.ApiUserNotificationRuleDelete).Methods("POST", "OPTIONS")
 
 		apiV1AuthRouter.Use(utils.CORSMiddleware)
 		apiV1AuthRouter.Use(utils.AuthorizedAPIMiddleware)
 
+		apiV2Router := router.PathPrefix("/api/v2").Subrouter()
+		apiV2Router.HandleFunc("/validator/{indexOrPubkey}/attestations", handlers.ApiV2ValidatorAttestations).Methods("GET", "OPTIONS")
+		apiV2Router.HandleFunc("/validator/{indexOrPubkey}/proposals", handlers.ApiV2ValidatorProposals).Methods("GET", "OPTIONS")
+		apiV2Router.HandleFunc("/validator/{indexOrPubkey}/balancehistory", handlers.ApiV2ValidatorBalanceHistory).Methods("GET", "OPTIONS")
+		apiV2Router.HandleFunc("/validator/{indexOrPubkey}/incomedetailhistory", handlers.ApiV2ValidatorIncomeDetailsHistory).Methods("GET", "OPTIONS")
+		apiV2Router.Use(utils.CORSMiddleware)
+
 		router.HandleFunc("/api/healthz", handlers.ApiHealthz).Methods("GET", "HEAD")
 		router.HandleFunc("/api/healthz-loadbalancer", handlers.ApiHealthzLoadbalancer).Methods("GET", "HEAD")
 
@@ -401,6 +433,9 @@ func main() {
 			router.HandleFunc("/slot/{slot}/attestations", handlers.SlotAttestationsData).Methods("GET")
 			router.HandleFunc("/slot/{slot}/withdrawals", handlers.SlotWithdrawalData).Methods("GET")
 			router.HandleFunc("/slot/{slot}/blsChange", handlers.SlotBlsChangeData).Methods("GET")
+			router.HandleFunc("/slot/{slot}/depositRequests", handlers.SlotDepositRequestsData).Methods("GET")
+			router.HandleFunc("/slot/{slot}/withdrawalRequests", handlers.SlotWithdrawalRequestsData).Methods("GET")
+			router.HandleFunc("/slot/{slot}/consolidationRequests", handlers.SlotConsolidationRequestsData).Methods("GET")
 			router.HandleFunc("/slots/finder", handlers.SlotFinder).Methods("GET")
 			router.HandleFunc("/slots", handlers.Slots).Methods("GET")
 			router.HandleFunc("/slots/data", handlers.SlotsData).Methods("GET")
@@ -458,6 +493,8 @@ func main() {
 			router.HandleFunc("/validator/{index}/stats", handlers.ValidatorStatsTable).Methods("GET")
 			router.HandleFunc("/validators", handlers.Validators).Methods("GET")
 			router.HandleFunc("/validators/data", handlers.ValidatorsData).Methods("GET")
+			router.HandleFunc("/reorgs", handlers.Reorgs).Methods("GET")
+			router.HandleFunc("/reorgs/data", handlers.ReorgsData).Methods("GET")
 			router.HandleFunc("/validators/slashings", handlers.ValidatorsSlashings).Methods("GET")
 			router.HandleFunc("/validators/slashings/data", handlers.ValidatorsSlashingsData).Methods("GET")
 			router.HandleFunc("/validators/leaderboard", handlers.ValidatorsLeaderboard).Methods("GET")
@@ -561,6 +598,12 @@ func main() {
 			authRouter.HandleFunc("/settings/email", handlers.UserUpdateEmailPost).Methods("POST")
 			authRouter.HandleFunc("/notifications", handlers.UserNotificationsCenter).Methods("GET")
 			authRouter.HandleFunc("/notifications/channels", handlers.UsersNotificationChannels).Methods("POST")
+			authRouter.HandleFunc("/notifications/channels", handlers.UserNotificationChannelsPage).Methods("GET")
+			authRouter.HandleFunc("/notifications/channels/{channel}/setup", handlers.UserNotificationChannelSetup).Methods("POST")
+			authRouter.HandleFunc("/notifications/channels/{channel}/remove", handlers.UserNotificationChannelRemove).Methods("POST")
+			authRouter.HandleFunc("/notifications/rules", handlers.UserNotificationRulesPage).Methods("GET")
+			authRouter.HandleFunc("/notifications/rules/add", handlers.UserNotificationRuleAdd).Methods("POST")
+			authRouter.HandleFunc("/notifications/rules/{ruleID}/delete", handlers.UserNotificationRuleDelete).Methods("POST")
 			authRouter.HandleFunc("/notifications/data", handlers.UserNotificationsData).Methods("GET")
 			authRouter.HandleFunc("/notifications/subscribe", handlers.UserNotificationsSubscribe).Methods("POST")
 			authRouter.HandleFunc("/notifications/network/update", handlers.UserModalAddNetworkEvent).Methods("POST")
@@ -591,6 +634,9 @@ func main() {
 			authRouter.HandleFunc("/webhooks/add", handlers.UsersAddWebhook).Methods("POST")
 			authRouter.HandleFunc("/webhooks/{webhookID}/update", handlers.UsersEditWebhook).Methods("POST")
 			authRouter.HandleFunc("/webhooks/{webhookID}/delete", handlers.UsersDeleteWebhook).Methods("POST")
+			authRouter.HandleFunc("/webhooks/{webhookID}/secret", handlers.UsersRotateWebhookSecret).Methods("POST")
+			authRouter.HandleFunc("/webhooks/{webhookID}/deliveries", handlers.NotificationWebhookDeliveriesPage).Methods("GET")
+			authRouter.HandleFunc("/webhooks/{webhookID}/deliveries/{deliveryID}/replay", handlers.UsersReplayWebhookDelivery).Methods("POST")
 
 			err = initStripe(authRouter)
 			if err != nil {
@@ -622,6 +668,7 @@ func main() {
 		}
 
 		ratelimit.Init()
+		ratelimit.SetRequestWeigher("/api/v1/graphql", handlers.ApiGraphQLWeight)
 		router.Use(ratelimit.HttpMiddleware)
 
 		n := negroni.New(negroni.NewRecovery())
@@ -0,0 +1,509 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	gtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/holiman/uint256"
+	"github.com/prysmaticlabs/go-bitfield"
+)
+
+type slotData struct {
+	slot      uint64
+	missed    bool
+	proposer  uint64
+	root      [32]byte
+	parent    [32]byte
+	stateRoot [32]byte
+	bodyRoot  [32]byte
+	sszBlock  []byte
+	blobs     []blobData
+}
+
+type blobData struct {
+	commitment kzg4844.Commitment
+	proof      kzg4844.Proof
+	blob       *kzg4844.Blob
+}
+
+type attestation struct {
+	bits          bitfield.Bitlist
+	committeeBits bitfield.Bitvector64
+	slot          uint64
+	index         uint64
+	blockRoot     [32]byte
+	source        phase0.Checkpoint
+	target        phase0.Checkpoint
+}
+
+var (
+	slots       = map[uint64]*slotData{}
+	rootToSlot  = map[string]uint64{}
+	stateToSlot = map[string]uint64{}
+	genesisRoot [32]byte
+	nonce       uint64
+	wdIndex     uint64
+)
+
+// h derives the pseudo random values of the chain, the fork is part of the seed so that the chains of the forks differ
+func h(parts ...interface{}) [32]byte {
+	return sha256.Sum256([]byte(fmt.Sprint(append([]interface{}{fork}, parts...)...)))
+}
+
+func hx(b []byte) string { return "0x" + hex.EncodeToString(b) }
+
+func pubkey(i int) []byte {
+	s := h("pubkey", i)
+	return append(append([]byte{}, s[:]...), s[:16]...)
+}
+
+// sig returns a placeholder signature, the exporter does not verify signatures
+func sig(tag byte, slot uint64) []byte {
+	b := make([]byte, 96)
+	b[0] = tag
+	binary.LittleEndian.PutUint64(b[1:], slot)
+	return b
+}
+
+func creds(i int) []byte {
+	c := make([]byte, 32)
+	switch {
+	case fork == "electra" && i < 2:
+		c[0] = 0x02
+		copy(c[12:], address(i))
+	case fork != "phase0" && i%4 == 0:
+		c[0] = 0x01
+		copy(c[12:], address(i))
+	default:
+		s := h("bls", i)
+		copy(c[1:], s[1:])
+	}
+	return c
+}
+
+func address(i int) []byte {
+	s := h("address", i)
+	return s[:20]
+}
+
+// committeesOf shuffles all validators into the committees of the slots of the epoch
+func committeesOf(epoch uint64) map[uint64][][]uint64 {
+	perm := make([]uint64, numValidators)
+	for i := range perm {
+		perm[i] = uint64(i)
+	}
+	for i := numValidators - 1; i > 0; i-- {
+		s := h("shuffle", epoch, i)
+		j := int(binary.LittleEndian.Uint64(s[:8]) % uint64(i+1))
+		perm[i], perm[j] = perm[j], perm[i]
+	}
+	res := map[uint64][][]uint64{}
+	n := 0
+	for s := epoch * slotsPerEpoch; s < (epoch+1)*slotsPerEpoch; s++ {
+		for c := 0; c < committees; c++ {
+			res[s] = append(res[s], perm[n:n+committeeSize])
+			n += committeeSize
+		}
+	}
+	return res
+}
+
+func proposerOf(slot uint64) uint64 {
+	s := h("proposer", slot)
+	return binary.LittleEndian.Uint64(s[:8]) % numValidators
+}
+
+func syncCommittee() []uint64 {
+	res := make([]uint64, syncSize)
+	for i := range res {
+		s := h("sync", i)
+		res[i] = binary.LittleEndian.Uint64(s[:8]) % numValidators
+	}
+	return res
+}
+
+// participates decides whether a validator voted in the attestation of its committee at slot
+func participates(slot uint64, validator uint64) bool {
+	s := h("participates", slot, validator)
+	return s[0] > 24
+}
+
+func latestRoot(slot uint64) [32]byte {
+	for s := int64(slot); s >= 0; s-- {
+		if d, ok := slots[uint64(s)]; ok && !d.missed {
+			return d.root
+		}
+	}
+	return genesisRoot
+}
+
+func epochBoundaryRoot(epoch uint64) [32]byte {
+	return latestRoot(epoch * slotsPerEpoch)
+}
+
+// attestationsFor returns the attestations included in the block of slot, they attest the previous slot and
+// additionally the slot before if the previous slot was missed
+func attestationsFor(slot uint64) []attestation {
+	var res []attestation
+	for _, s := range []uint64{slot - 1, slot - 2} {
+		if slot < 1 || s >= slot || (s == slot-2 && (slot < 2 || !slots[slot-1].missed)) {
+			continue
+		}
+		epoch := s / slotsPerEpoch
+		comms := committeesOf(epoch)[s]
+		source := phase0.Checkpoint{}
+		if epoch > 1 {
+			source = phase0.Checkpoint{Epoch: phase0.Epoch(epoch - 1), Root: phase0.Root(epochBoundaryRoot(epoch - 1))}
+		} else {
+			source.Root = phase0.Root(genesisRoot)
+		}
+		target := phase0.Checkpoint{Epoch: phase0.Epoch(epoch), Root: phase0.Root(epochBoundaryRoot(epoch))}
+		if fork == "electra" {
+			// one aggregate covering both committees and one of the second committee only
+			bits := bitfield.NewBitlist(committees * committeeSize)
+			n := uint64(0)
+			for _, c := range comms {
+				for _, v := range c {
+					if participates(s, v) {
+						bits.SetBitAt(n, true)
+					}
+					n++
+				}
+			}
+			both := bitfield.NewBitvector64()
+			both.SetBitAt(0, true)
+			both.SetBitAt(1, true)
+			res = append(res, attestation{bits: bits, committeeBits: both, slot: s, blockRoot: latestRoot(s), source: source, target: target})
+			late := bitfield.NewBitlist(committeeSize)
+			for i, v := range comms[1] {
+				if !participates(s, v) && i%2 == 0 {
+					late.SetBitAt(uint64(i), true)
+				}
+			}
+			if late.Count() > 0 {
+				second := bitfield.NewBitvector64()
+				second.SetBitAt(1, true)
+				res = append(res, attestation{bits: late, committeeBits: second, slot: s, blockRoot: latestRoot(s), source: source, target: target})
+			}
+			continue
+		}
+		for ci, c := range comms {
+			bits := bitfield.NewBitlist(committeeSize)
+			for i, v := range c {
+				if participates(s, v) {
+					bits.SetBitAt(uint64(i), true)
+				}
+			}
+			res = append(res, attestation{bits: bits, slot: s, index: uint64(ci), blockRoot: latestRoot(s), source: source, target: target})
+		}
+	}
+	return res
+}
+
+func (a attestation) data() *phase0.AttestationData {
+	return &phase0.AttestationData{
+		Slot:            phase0.Slot(a.slot),
+		Index:           phase0.CommitteeIndex(a.index),
+		BeaconBlockRoot: a.blockRoot,
+		Source:          &phase0.Checkpoint{Epoch: a.source.Epoch, Root: a.source.Root},
+		Target:          &phase0.Checkpoint{Epoch: a.target.Epoch, Root: a.target.Root},
+	}
+}
+
+func syncBits(slot uint64) bitfield.Bitvector512 {
+	bits := bitfield.NewBitvector512()
+	for i := uint64(0); i < syncSize; i++ {
+		s := h("syncbit", slot, i)
+		bits.SetBitAt(i, s[0] > 12)
+	}
+	return bits
+}
+
+// transactions returns a transfer and, if the block has blobs, a blob transaction referencing them
+func transactions(slot uint64, blobs []blobData) [][]byte {
+	signer := gtypes.NewCancunSigner(big.NewInt(depositChainID))
+	to := gcommon.BytesToAddress(address(int(slot)))
+	var txs [][]byte
+	tx := gtypes.MustSignNewTx(txKey, signer, &gtypes.DynamicFeeTx{
+		ChainID:   big.NewInt(depositChainID),
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(1000000000),
+		GasFeeCap: big.NewInt(30000000000),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(int64(slot) * 1000000000000000),
+	})
+	nonce++
+	b, _ := tx.MarshalBinary()
+	txs = append(txs, b)
+	if len(blobs) > 0 {
+		hashes := make([]gcommon.Hash, len(blobs))
+		for i, blob := range blobs {
+			vh := sha256.Sum256(blob.commitment[:])
+			vh[0] = 0x01
+			hashes[i] = vh
+		}
+		tx := gtypes.MustSignNewTx(txKey, signer, &gtypes.BlobTx{
+			ChainID:    uint256.NewInt(depositChainID),
+			Nonce:      nonce,
+			GasTipCap:  uint256.NewInt(1000000000),
+			GasFeeCap:  uint256.NewInt(30000000000),
+			Gas:        21000,
+			To:         to,
+			BlobFeeCap: uint256.NewInt(1),
+			BlobHashes: hashes,
+		})
+		nonce++
+		b, _ := tx.MarshalBinary()
+		txs = append(txs, b)
+	}
+	return txs
+}
+
+func withdrawals(slot uint64) []*capella.Withdrawal {
+	var res []*capella.Withdrawal
+	for i := 0; i < 2; i++ {
+		v := (wdIndex * 4) % numValidators
+		var a bellatrix.ExecutionAddress
+		copy(a[:], address(int(v)))
+		res = append(res, &capella.Withdrawal{Index: capella.WithdrawalIndex(wdIndex), ValidatorIndex: phase0.ValidatorIndex(v), Address: a, Amount: phase0.Gwei(1000000 + slot*1000)})
+		wdIndex++
+	}
+	return res
+}
+
+func payload(slot uint64, blobs []blobData) *deneb.ExecutionPayload {
+	if slot == 0 {
+		return &deneb.ExecutionPayload{BaseFeePerGas: uint256.NewInt(0)}
+	}
+	p := &deneb.ExecutionPayload{
+		BlockNumber:   slot,
+		GasLimit:      30000000,
+		Timestamp:     genesisTime + slot*secondsPerSlot,
+		BaseFeePerGas: uint256.NewInt(7),
+		ExtraData:     []byte("fixtures"),
+		Withdrawals:   withdrawals(slot),
+		BlobGasUsed:   uint64(len(blobs)) * 131072,
+	}
+	p.ParentHash = phase0.Hash32(h("el", slot-1))
+	p.BlockHash = phase0.Hash32(h("el", slot))
+	copy(p.FeeRecipient[:], address(int(proposerOf(slot))))
+	p.StateRoot = h("elstate", slot)
+	p.ReceiptsRoot = h("receipts", slot)
+	p.PrevRandao = h("randao", slot)
+	for _, tx := range transactions(slot, blobs) {
+		p.Transactions = append(p.Transactions, tx)
+	}
+	p.GasUsed = uint64(len(p.Transactions)) * 21000
+	return p
+}
+
+// zeroBlobs returns n empty blobs with valid kzg commitments and proofs
+func zeroBlobs(n int) []blobData {
+	blob := &kzg4844.Blob{}
+	commitment, err := kzg4844.BlobToCommitment(*blob)
+	if err != nil {
+		panic(err)
+	}
+	proof, err := kzg4844.ComputeBlobProof(*blob, commitment)
+	if err != nil {
+		panic(err)
+	}
+	res := make([]blobData, n)
+	for i := range res {
+		res[i] = blobData{commitment: commitment, proof: proof, blob: blob}
+	}
+	return res
+}
+
+// executionRequests returns the execution requests of the block of slot: a deposit of a new validator, a full exit of
+// validator 4 and a switch of validator 1 to compounding credentials by consolidating it into validator 0
+func executionRequests(slot uint64) *electra.ExecutionRequests {
+	requests := &electra.ExecutionRequests{}
+	if slot != requestsSlot {
+		return requests
+	}
+	deposit := &electra.DepositRequest{WithdrawalCredentials: creds(0), Amount: gwei32, Index: numValidators}
+	copy(deposit.Pubkey[:], pubkey(numValidators))
+	copy(deposit.Signature[:], sig(0xdd, slot))
+	withdrawal := &electra.WithdrawalRequest{}
+	copy(withdrawal.SourceAddress[:], address(4))
+	copy(withdrawal.ValidatorPubkey[:], pubkey(4))
+	consolidation := &electra.ConsolidationRequest{}
+	copy(consolidation.SourceAddress[:], address(1))
+	copy(consolidation.SourcePubkey[:], pubkey(1))
+	copy(consolidation.TargetPubkey[:], pubkey(0))
+	requests.Deposits = []*electra.DepositRequest{deposit}
+	requests.Withdrawals = []*electra.WithdrawalRequest{withdrawal}
+	requests.Consolidations = []*electra.ConsolidationRequest{consolidation}
+	return requests
+}
+
+// buildChain builds the blocks up to the head and returns the fork specific contents of the recorded epochs
+func buildChain() fixtureExpected {
+	expected := fixtureExpected{}
+	for slot := uint64(0); slot <= headSlot+1; slot++ {
+		d := &slotData{slot: slot, proposer: proposerOf(slot)}
+		slots[slot] = d
+		if slot == missedSlot {
+			d.missed = true
+			continue
+		}
+		d.parent = latestRoot(slot - 1)
+		if slot == 0 {
+			d.parent = [32]byte{}
+			d.proposer = 0
+		}
+		d.stateRoot = h("state", slot)
+		switch {
+		case slot == blobSlot && fork == "deneb":
+			d.blobs = zeroBlobs(2)
+		case slot == blobSlot && fork == "electra":
+			d.blobs = zeroBlobs(1)
+		}
+
+		var err error
+		switch fork {
+		case "phase0":
+			err = buildPhase0(d)
+		case "deneb":
+			err = buildDeneb(d)
+		case "electra":
+			err = buildElectra(d)
+		}
+		if err != nil {
+			panic(fmt.Errorf("error building block of slot %v: %w", slot, err))
+		}
+		if slot == 0 {
+			genesisRoot = d.root
+		}
+		rootToSlot[hx(d.root[:])] = slot
+		stateToSlot[hx(d.stateRoot[:])] = slot
+
+		if slot < recordedEpochs*slotsPerEpoch {
+			expected.Blobs += len(d.blobs)
+			if fork == "electra" {
+				requests := executionRequests(slot)
+				expected.DepositRequests += len(requests.Deposits)
+				expected.WithdrawalRequests += len(requests.Withdrawals)
+				expected.ConsolidationRequests += len(requests.Consolidations)
+			}
+		}
+	}
+	return expected
+}
+
+type sszMarshaler interface {
+	MarshalSSZ() ([]byte, error)
+}
+
+type hashRooter interface {
+	HashTreeRoot() ([32]byte, error)
+}
+
+// sealBlock sets the roots and the ssz encoding of the block of d
+func sealBlock(d *slotData, signed sszMarshaler, message, body hashRooter) error {
+	var err error
+	d.root, err = message.HashTreeRoot()
+	if err != nil {
+		return err
+	}
+	d.bodyRoot, err = body.HashTreeRoot()
+	if err != nil {
+		return err
+	}
+	d.sszBlock, err = signed.MarshalSSZ()
+	return err
+}
+
+func randaoAndGraffiti(d *slotData) (phase0.BLSSignature, [32]byte, *phase0.ETH1Data) {
+	var randao phase0.BLSSignature
+	var graffiti [32]byte
+	eth1Data := &phase0.ETH1Data{DepositCount: numValidators, BlockHash: make([]byte, 32)}
+	if d.slot > 0 {
+		copy(randao[:], sig(0xbb, d.slot))
+		copy(graffiti[:], fmt.Sprintf("minimal-%v %d", fork, d.slot))
+		dr := h("depositroot")
+		copy(eth1Data.DepositRoot[:], dr[:])
+	}
+	return randao, graffiti, eth1Data
+}
+
+func syncAggregate(d *slotData) *altair.SyncAggregate {
+	agg := &altair.SyncAggregate{SyncCommitteeBits: bitfield.NewBitvector512()}
+	if d.slot == 0 {
+		agg.SyncCommitteeSignature[0] = 0xc0
+		return agg
+	}
+	agg.SyncCommitteeBits = syncBits(d.slot)
+	copy(agg.SyncCommitteeSignature[:], sig(0xcc, d.slot))
+	return agg
+}
+
+func buildPhase0(d *slotData) error {
+	body := &phase0.BeaconBlockBody{}
+	body.RANDAOReveal, body.Graffiti, body.ETH1Data = randaoAndGraffiti(d)
+	if d.slot > 0 {
+		for _, a := range attestationsFor(d.slot) {
+			att := &phase0.Attestation{AggregationBits: a.bits, Data: a.data()}
+			copy(att.Signature[:], sig(byte(a.index+1), a.slot))
+			body.Attestations = append(body.Attestations, att)
+		}
+	}
+	message := &phase0.BeaconBlock{Slot: phase0.Slot(d.slot), ProposerIndex: phase0.ValidatorIndex(d.proposer), ParentRoot: d.parent, StateRoot: d.stateRoot, Body: body}
+	signed := &phase0.SignedBeaconBlock{Message: message}
+	copy(signed.Signature[:], sig(0xaa, d.slot))
+	return sealBlock(d, signed, message, body)
+}
+
+func buildDeneb(d *slotData) error {
+	body := &deneb.BeaconBlockBody{SyncAggregate: syncAggregate(d)}
+	body.RANDAOReveal, body.Graffiti, body.ETH1Data = randaoAndGraffiti(d)
+	if d.slot > 0 {
+		for _, a := range attestationsFor(d.slot) {
+			att := &phase0.Attestation{AggregationBits: a.bits, Data: a.data()}
+			copy(att.Signature[:], sig(byte(a.index+1), a.slot))
+			body.Attestations = append(body.Attestations, att)
+		}
+	}
+	body.ExecutionPayload = payload(d.slot, d.blobs)
+	for _, b := range d.blobs {
+		body.BlobKZGCommitments = append(body.BlobKZGCommitments, deneb.KZGCommitment(b.commitment))
+	}
+	message := &deneb.BeaconBlock{Slot: phase0.Slot(d.slot), ProposerIndex: phase0.ValidatorIndex(d.proposer), ParentRoot: d.parent, StateRoot: d.stateRoot, Body: body}
+	signed := &deneb.SignedBeaconBlock{Message: message}
+	copy(signed.Signature[:], sig(0xaa, d.slot))
+	return sealBlock(d, signed, message, body)
+}
+
+func buildElectra(d *slotData) error {
+	body := &electra.BeaconBlockBody{SyncAggregate: syncAggregate(d), ExecutionRequests: executionRequests(d.slot)}
+	body.RANDAOReveal, body.Graffiti, body.ETH1Data = randaoAndGraffiti(d)
+	if d.slot > 0 {
+		for _, a := range attestationsFor(d.slot) {
+			att := &electra.Attestation{AggregationBits: a.bits, Data: a.data(), CommitteeBits: a.committeeBits}
+			copy(att.Signature[:], sig(a.committeeBits[0], a.slot))
+			body.Attestations = append(body.Attestations, att)
+		}
+	}
+	body.ExecutionPayload = payload(d.slot, d.blobs)
+	for _, b := range d.blobs {
+		body.BlobKZGCommitments = append(body.BlobKZGCommitments, deneb.KZGCommitment(b.commitment))
+	}
+	message := &electra.BeaconBlock{Slot: phase0.Slot(d.slot), ProposerIndex: phase0.ValidatorIndex(d.proposer), ParentRoot: d.parent, StateRoot: d.stateRoot, Body: body}
+	signed := &electra.SignedBeaconBlock{Message: message}
+	copy(signed.Signature[:], sig(0xaa, d.slot))
+	return sealBlock(d, signed, message, body)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"flag"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/rpc"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"gopkg.in/yaml.v3"
+)
+
+// exporter-fixtures generates the synthetic beacon node fixtures of the exporter tests in exporter/testdata/fixtures.
+// It serves a deterministic minimal preset chain of the given fork from an in process beacon node and records the
+// responses the exporter requests through rpc.FixtureClient, the same way `misc -command epoch-export -fixtures.record`
+// records a real node. The chain config is read from the chain.yml of the fixture directory.
+//
+// The chain has 64 validators and 40 slots, slot 12 is missed. The deneb chain has a block with two blobs and the electra
+// chain a block with one blob and one deposit, withdrawal and consolidation request each.
+//
+//	go run ./cmd/exporter-fixtures -fork deneb -dir exporter/testdata/fixtures/minimal-deneb
+
+const (
+	numValidators  = 64
+	slotsPerEpoch  = 8
+	committees     = 2
+	committeeSize  = 4
+	syncSize       = 512 // mainnet sized sync committee, the ssz block decoder expects the mainnet sized sync aggregate
+	histRoots      = 64  // SLOTS_PER_HISTORICAL_ROOT, EPOCHS_PER_HISTORICAL_VECTOR and EPOCHS_PER_SLASHINGS_VECTOR of the minimal preset
+	headSlot       = 39
+	missedSlot     = 12
+	gwei32         = 32000000000
+	recordedEpochs = 3
+	statisticsDay  = 0
+	depositChainID = 5
+	genesisTime    = 1578009900
+	secondsPerSlot = 5400 // a day spans two epochs
+	blobSlot       = 10
+	requestsSlot   = 19
+	farFuture      = ^uint64(0)
+)
+
+var (
+	fork  string
+	txKey *ecdsa.PrivateKey
+)
+
+// fixtureCase is written to the case.json of the fixture directory, see exporter/slot_exporter_test.go
+type fixtureCase struct {
+	Epochs         []uint64        `json:"epochs"`
+	StatisticsDays []uint64        `json:"statistics_days"`
+	Expected       fixtureExpected `json:"expected"`
+}
+
+type fixtureExpected struct {
+	Blobs                 int `json:"blobs,omitempty"`
+	DepositRequests       int `json:"deposit_requests,omitempty"`
+	WithdrawalRequests    int `json:"withdrawal_requests,omitempty"`
+	ConsolidationRequests int `json:"consolidation_requests,omitempty"`
+}
+
+func main() {
+	flag.StringVar(&fork, "fork", "", "Fork of the chain, all forks up to it are active from genesis: phase0, deneb or electra")
+	dir := flag.String("dir", "", "Fixture directory containing the chain.yml of the chain, the fixtures are written to it")
+	flag.Parse()
+
+	if fork != "phase0" && fork != "deneb" && fork != "electra" {
+		log.Fatalf("unsupported fork %q", fork)
+	}
+	txKey, _ = crypto.ToECDSA(crypto.Keccak256([]byte("fixtures " + fork)))
+
+	chainData, err := os.ReadFile(filepath.Join(*dir, "chain.yml"))
+	if err != nil {
+		log.Fatalf("error reading chain config: %v", err)
+	}
+	cfg := &types.Config{}
+	err = yaml.Unmarshal(chainData, &cfg.Chain.ClConfig)
+	if err != nil {
+		log.Fatalf("error parsing chain config: %v", err)
+	}
+	if cfg.Chain.ClConfig.SlotsPerEpoch != slotsPerEpoch || cfg.Chain.ClConfig.SecondsPerSlot != secondsPerSlot {
+		log.Fatalf("chain config does not match the generated chain, expected %v slots per epoch of %v seconds", slotsPerEpoch, secondsPerSlot)
+	}
+	utils.Config = cfg
+
+	// drop the fixtures of a previous run, requests that are not made anymore would otherwise keep their fixtures
+	for _, recorded := range []string{"eth", "lighthouse"} {
+		err = os.RemoveAll(filepath.Join(*dir, recorded))
+		if err != nil {
+			log.Fatalf("error removing previous fixtures: %v", err)
+		}
+	}
+
+	expected := buildChain()
+
+	srv := httptest.NewServer(http.HandlerFunc(serve))
+	defer srv.Close()
+
+	client, err := rpc.NewFixtureClient(*dir, rpc.FixtureModeRecord, srv.URL, big.NewInt(depositChainID))
+	if err != nil {
+		log.Fatalf("error creating fixture client: %v", err)
+	}
+
+	fc := fixtureCase{StatisticsDays: []uint64{statisticsDay}, Expected: expected}
+	for epoch := uint64(0); epoch < recordedEpochs; epoch++ {
+		fc.Epochs = append(fc.Epochs, epoch)
+		for slot := epoch * slotsPerEpoch; slot < (epoch+1)*slotsPerEpoch; slot++ {
+			_, err := client.GetBlockBySlot(slot)
+			if err != nil {
+				log.Fatalf("error recording block of slot %v: %v", slot, err)
+			}
+		}
+		if epoch > 0 {
+			_, err := client.GetValidatorParticipation(epoch - 1)
+			if err != nil {
+				log.Fatalf("error recording participation of epoch %v: %v", epoch-1, err)
+			}
+		}
+	}
+	first, last := utils.GetFirstAndLastEpochForDay(statisticsDay)
+	for _, epoch := range []uint64{first, last} {
+		_, err := client.GetValidatorState(epoch)
+		if err != nil {
+			log.Fatalf("error recording validator state of epoch %v: %v", epoch, err)
+		}
+	}
+
+	data, err := json.Marshal(fc)
+	if err != nil {
+		log.Fatalf("error encoding test case: %v", err)
+	}
+	err = os.WriteFile(filepath.Join(*dir, "case.json"), append(data, '\n'), 0o644)
+	if err != nil {
+		log.Fatalf("error writing test case: %v", err)
+	}
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/holiman/uint256"
+)
+
+// validatorsOffsetPos is the position of the validators offset in the state, it has to match rpc.decodeStateValidators
+const validatorsOffsetPos = 8 + 32 + 8 + 16 + 112 + 2*32*histRoots + 4 + 72 + 4 + 8
+
+var forkVersions = map[string][]byte{"phase0": {0, 0, 0, 1}, "deneb": {4, 0, 0, 1}, "electra": {5, 0, 0, 1}}
+var previousForkVersions = map[string][]byte{"phase0": {0, 0, 0, 1}, "deneb": {3, 0, 0, 1}, "electra": {4, 0, 0, 1}}
+
+func validatorSSZ(i int) []byte {
+	b := make([]byte, 0, 121)
+	b = append(b, pubkey(i)...)
+	b = append(b, creds(i)...)
+	b = binary.LittleEndian.AppendUint64(b, gwei32)
+	b = append(b, 0)
+	b = binary.LittleEndian.AppendUint64(b, 0)
+	b = binary.LittleEndian.AppendUint64(b, 0)
+	b = binary.LittleEndian.AppendUint64(b, farFuture)
+	b = binary.LittleEndian.AppendUint64(b, farFuture)
+	return b
+}
+
+func balance(i int, epoch uint64) uint64 {
+	s := h("balance", i, epoch)
+	return gwei32 + epoch*14000 - uint64(s[0])*10
+}
+
+// stateSSZ encodes the beacon state at slot. The state containers of go-eth2-client have the mainnet preset sizes,
+// so the minimal preset state is encoded by hand.
+func stateSSZ(slot uint64) []byte {
+	epoch := slot / slotsPerEpoch
+	var fixed, variable []byte
+	var offsets []int // positions in fixed of the offsets, filled in order with the variable parts
+	var parts [][]byte
+	u64 := func(v uint64) { fixed = binary.LittleEndian.AppendUint64(fixed, v) }
+	raw := func(b []byte) { fixed = append(fixed, b...) }
+	off := func(part []byte) {
+		offsets = append(offsets, len(fixed))
+		parts = append(parts, part)
+		fixed = append(fixed, 0, 0, 0, 0)
+	}
+
+	u64(genesisTime)
+	gvr := h("genesis_validators_root")
+	raw(gvr[:])
+	u64(slot)
+	raw(previousForkVersions[fork])
+	raw(forkVersions[fork])
+	u64(0)
+	latest := slots[slot]
+	if latest.missed {
+		latest = slots[slot-1]
+	}
+	u64(latest.slot)
+	u64(latest.proposer)
+	raw(latest.parent[:])
+	raw(make([]byte, 32))
+	raw(latest.bodyRoot[:])
+	for i := uint64(0); i < histRoots; i++ {
+		r := [32]byte{}
+		if i < slot {
+			r = latestRoot(i)
+		}
+		raw(r[:])
+	}
+	for i := uint64(0); i < histRoots; i++ {
+		r := [32]byte{}
+		if i < slot && !slots[i].missed {
+			r = slots[i].stateRoot
+		}
+		raw(r[:])
+	}
+	off(nil) // historical_roots
+	dr := h("depositroot")
+	raw(dr[:])
+	u64(numValidators)
+	raw(make([]byte, 32))
+	off(nil) // eth1_data_votes
+	u64(numValidators)
+	if len(fixed) != validatorsOffsetPos {
+		panic("validators offset mismatch")
+	}
+	var vals, bals, part, inactivity []byte
+	for i := 0; i < numValidators; i++ {
+		vals = append(vals, validatorSSZ(i)...)
+		bals = binary.LittleEndian.AppendUint64(bals, balance(i, epoch))
+		part = append(part, 7)
+		inactivity = binary.LittleEndian.AppendUint64(inactivity, 0)
+	}
+	off(vals)
+	off(bals)
+	for i := 0; i < histRoots; i++ {
+		r := h("randao_mix", i, epoch)
+		raw(r[:])
+	}
+	raw(make([]byte, histRoots*8)) // slashings
+	if fork == "phase0" {
+		// the pending attestations are not read by the exporter
+		off(nil) // previous_epoch_attestations
+		off(nil) // current_epoch_attestations
+	} else if epoch == 0 {
+		off(make([]byte, numValidators))
+		off(part)
+	} else {
+		off(part)
+		off(part)
+	}
+	raw([]byte{0})
+	for i := 0; i < 3; i++ {
+		raw(make([]byte, 8))
+		raw(genesisRoot[:])
+	}
+	if fork != "phase0" {
+		off(inactivity)
+		for i := 0; i < 2; i++ {
+			for _, v := range syncCommittee() {
+				raw(pubkey(int(v)))
+			}
+			agg := h("sync_aggregate_pubkey")
+			raw(append(agg[:], agg[:16]...))
+		}
+		header := &deneb.ExecutionPayloadHeader{BaseFeePerGas: uint256.NewInt(7), ExtraData: []byte("fixtures")}
+		if latest.slot > 0 {
+			header.BlockNumber = latest.slot
+			header.BlockHash = h("el", latest.slot)
+			header.ParentHash = h("el", latest.slot-1)
+			header.Timestamp = genesisTime + latest.slot*secondsPerSlot
+			header.GasLimit = 30000000
+		} else {
+			header.BaseFeePerGas = uint256.NewInt(0)
+			header.ExtraData = nil
+		}
+		hb, err := header.MarshalSSZ()
+		if err != nil {
+			panic(err)
+		}
+		off(hb)
+		u64(wdIndex)
+		u64(0)
+		off(nil) // historical_summaries
+	}
+	if fork == "electra" {
+		u64(numValidators + 1) // deposit_requests_start_index
+		u64(0)
+		u64(0)
+		u64(0)
+		u64(0)
+		u64(0)
+		off(nil) // pending_deposits
+		off(nil) // pending_partial_withdrawals
+		off(nil) // pending_consolidations
+	}
+	pos := len(fixed)
+	for i, o := range offsets {
+		binary.LittleEndian.PutUint32(fixed[o:], uint32(pos))
+		variable = append(variable, parts[i]...)
+		pos += len(parts[i])
+	}
+	return append(fixed, variable...)
+}
+
+func header(d *slotData, finalized bool) []byte {
+	b, _ := json.Marshal(map[string]interface{}{
+		"execution_optimistic": false,
+		"finalized":            finalized,
+		"data": map[string]interface{}{
+			"root":      hx(d.root[:]),
+			"canonical": true,
+			"header": map[string]interface{}{
+				"message": map[string]interface{}{
+					"slot":           strconv.FormatUint(d.slot, 10),
+					"proposer_index": strconv.FormatUint(d.proposer, 10),
+					"parent_root":    hx(d.parent[:]),
+					"state_root":     hx(d.stateRoot[:]),
+					"body_root":      hx(d.bodyRoot[:]),
+				},
+				"signature": hx(sig(0xaa, d.slot)),
+			},
+		},
+	})
+	return b
+}
+
+func writeJSON(w http.ResponseWriter, b []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
+
+func notFound(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	w.Write([]byte(`{"code":404,"message":"NOT_FOUND: beacon block","stacktraces":[]}`))
+}
+
+func slotByID(id string) (*slotData, bool) {
+	if id == "head" {
+		return slots[headSlot], true
+	}
+	if strings.HasPrefix(id, "0x") {
+		s, ok := rootToSlot[id]
+		if !ok {
+			return nil, false
+		}
+		return slots[s], true
+	}
+	s, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || s > headSlot || slots[s].missed {
+		return nil, false
+	}
+	return slots[s], true
+}
+
+func dependentRoot(epoch uint64) [32]byte {
+	if epoch == 0 {
+		return genesisRoot
+	}
+	return latestRoot(epoch*slotsPerEpoch - 1)
+}
+
+// serve answers the beacon node api requests of the exporter, it panics on requests it does not know so that a
+// recording never silently misses a response
+func serve(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+	finalizedEpoch := uint64(headSlot/slotsPerEpoch) - 2
+	switch {
+	case strings.HasPrefix(path, "eth/v1/beacon/headers/"):
+		d, ok := slotByID(parts[4])
+		if !ok {
+			notFound(w)
+			return
+		}
+		writeJSON(w, header(d, d.slot/slotsPerEpoch < finalizedEpoch))
+	case strings.HasPrefix(path, "eth/v2/beacon/blocks/"):
+		d, ok := slotByID(parts[4])
+		if !ok {
+			notFound(w)
+			return
+		}
+		if !strings.Contains(r.Header.Get("Accept"), "application/octet-stream") {
+			panic("json encoded block requested " + r.URL.String())
+		}
+		w.Header().Set("Eth-Consensus-Version", fork)
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(d.sszBlock)
+	case strings.HasPrefix(path, "eth/v1/beacon/blob_sidecars/"):
+		d, ok := slotByID(parts[4])
+		if !ok {
+			notFound(w)
+			return
+		}
+		data := []interface{}{}
+		for i, b := range d.blobs {
+			proof := []string{}
+			for j := 0; j < 17; j++ {
+				p := h("inclusion", d.slot, i, j)
+				proof = append(proof, hx(p[:]))
+			}
+			data = append(data, map[string]interface{}{
+				"index":          strconv.Itoa(i),
+				"blob":           hx(b.blob[:]),
+				"kzg_commitment": hx(b.commitment[:]),
+				"kzg_proof":      hx(b.proof[:]),
+				"signed_block_header": map[string]interface{}{
+					"message": map[string]interface{}{
+						"slot":           strconv.FormatUint(d.slot, 10),
+						"proposer_index": strconv.FormatUint(d.proposer, 10),
+						"parent_root":    hx(d.parent[:]),
+						"state_root":     hx(d.stateRoot[:]),
+						"body_root":      hx(d.bodyRoot[:]),
+					},
+					"signature": hx(sig(0xaa, d.slot)),
+				},
+				"kzg_commitment_inclusion_proof": proof,
+			})
+		}
+		b, _ := json.Marshal(map[string]interface{}{"data": data})
+		writeJSON(w, b)
+	case strings.HasPrefix(path, "eth/v1/validator/duties/proposer/"):
+		epoch, _ := strconv.ParseUint(parts[5], 10, 64)
+		data := []interface{}{}
+		for s := epoch * slotsPerEpoch; s < (epoch+1)*slotsPerEpoch; s++ {
+			p := proposerOf(s)
+			if s == 0 {
+				p = 0
+			}
+			data = append(data, map[string]string{"pubkey": hx(pubkey(int(p))), "validator_index": strconv.FormatUint(p, 10), "slot": strconv.FormatUint(s, 10)})
+		}
+		dep := dependentRoot(epoch)
+		b, _ := json.Marshal(map[string]interface{}{"dependent_root": hx(dep[:]), "execution_optimistic": false, "data": data})
+		writeJSON(w, b)
+	case strings.HasPrefix(path, "eth/v1/beacon/states/") && parts[5] == "committees":
+		epoch, _ := strconv.ParseUint(r.URL.Query().Get("epoch"), 10, 64)
+		comms := committeesOf(epoch)
+		data := []interface{}{}
+		for s := epoch * slotsPerEpoch; s < (epoch+1)*slotsPerEpoch; s++ {
+			for ci, c := range comms[s] {
+				vals := []string{}
+				for _, v := range c {
+					vals = append(vals, strconv.FormatUint(v, 10))
+				}
+				data = append(data, map[string]interface{}{"index": strconv.Itoa(ci), "slot": strconv.FormatUint(s, 10), "validators": vals})
+			}
+		}
+		b, _ := json.Marshal(map[string]interface{}{"execution_optimistic": false, "finalized": true, "data": data})
+		writeJSON(w, b)
+	case strings.HasPrefix(path, "eth/v1/beacon/states/") && parts[5] == "sync_committees":
+		vals := []string{}
+		for _, v := range syncCommittee() {
+			vals = append(vals, strconv.FormatUint(v, 10))
+		}
+		aggs := [][]string{}
+		for i := 0; i < 4; i++ {
+			aggs = append(aggs, vals[i*128:(i+1)*128])
+		}
+		b, _ := json.Marshal(map[string]interface{}{"execution_optimistic": false, "finalized": true, "data": map[string]interface{}{"validators": vals, "validator_aggregates": aggs}})
+		writeJSON(w, b)
+	case strings.HasPrefix(path, "eth/v1/beacon/states/") && parts[5] == "finality_checkpoints":
+		cp := func(e uint64) map[string]string {
+			root := epochBoundaryRoot(e)
+			return map[string]string{"epoch": strconv.FormatUint(e, 10), "root": hx(root[:])}
+		}
+		b, _ := json.Marshal(map[string]interface{}{"execution_optimistic": false, "finalized": false, "data": map[string]interface{}{
+			"previous_justified": cp(finalizedEpoch + 1),
+			"current_justified":  cp(finalizedEpoch + 2),
+			"finalized":          cp(finalizedEpoch + 1),
+		}})
+		writeJSON(w, b)
+	case strings.HasPrefix(path, "eth/v2/debug/beacon/states/"):
+		s, err := strconv.ParseUint(parts[5], 10, 64)
+		if err != nil {
+			notFound(w)
+			return
+		}
+		w.Header().Set("Eth-Consensus-Version", fork)
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(stateSSZ(s))
+	case strings.HasPrefix(path, "lighthouse/validator_inclusion/"):
+		epoch, _ := strconv.ParseUint(parts[2], 10, 64)
+		attesting := func(e uint64) uint64 {
+			n := uint64(0)
+			comms := committeesOf(e)
+			for s := e * slotsPerEpoch; s < (e+1)*slotsPerEpoch; s++ {
+				for _, c := range comms[s] {
+					for _, v := range c {
+						if participates(s, v) {
+							n++
+						}
+					}
+				}
+			}
+			return n * gwei32
+		}
+		active := uint64(numValidators * gwei32)
+		b, _ := json.Marshal(map[string]interface{}{"data": map[string]string{
+			"current_epoch_active_gwei":            strconv.FormatUint(active, 10),
+			"current_epoch_target_attesting_gwei":  strconv.FormatUint(attesting(epoch), 10),
+			"previous_epoch_active_gwei":           strconv.FormatUint(active, 10),
+			"previous_epoch_head_attesting_gwei":   strconv.FormatUint(attesting(epoch-1)-gwei32, 10),
+			"previous_epoch_target_attesting_gwei": strconv.FormatUint(attesting(epoch-1), 10),
+		}})
+		writeJSON(w, b)
+	default:
+		panic("unexpected request " + r.URL.String())
+	}
+}
@@ -0,0 +1,223 @@
+package commands
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+// bigtableMigrationFamilies maps the family names accepted on the command line to the bigtable column families
+var bigtableMigrationFamilies = map[string]string{
+	"balances":     db.VALIDATOR_BALANCES_FAMILY,
+	"attestations": db.ATTESTATIONS_FAMILY,
+	"proposals":    db.PROPOSALS_FAMILY,
+	"sync":         db.SYNC_COMMITTEES_FAMILY,
+}
+
+type BigtableMigratorCommand struct {
+	Config bigtableMigratorConfig
+}
+
+type bigtableMigratorConfig struct {
+	DryRun         bool
+	Families       string
+	CheckpointFile string
+	ReportFile     string
+	VerifyEpochs   uint64
+	Concurrency    int
+}
+
+// bigtableMigrationCheckpoint is persisted after every verified range so an interrupted migration can be resumed
+type bigtableMigrationCheckpoint struct {
+	StartEpoch uint64            `json:"start_epoch"`
+	EndEpoch   uint64            `json:"end_epoch"`
+	NextEpoch  map[string]uint64 `json:"next_epoch"`
+	Mismatches map[string]uint64 `json:"mismatches"`
+}
+
+func (s *BigtableMigratorCommand) ParseCommandOptions() {
+	flag.StringVar(&s.Config.Families, "bigtable-migration.families", "balances,attestations,proposals,sync", "Comma separated list of validator history families to migrate from the v1 to the v2 bigtable schema")
+	flag.StringVar(&s.Config.CheckpointFile, "bigtable-migration.checkpoint", "bigtable-v1v2-migration.json", "File to store the migration progress in, an existing checkpoint for the same epoch range is resumed")
+	flag.StringVar(&s.Config.ReportFile, "bigtable-migration.report", "", "File to append all verification mismatches to")
+	flag.Uint64Var(&s.Config.VerifyEpochs, "bigtable-migration.verify-epochs", 10, "Number of epochs to migrate before the range is verified and the progress is checkpointed")
+	flag.IntVar(&s.Config.Concurrency, "bigtable-migration.concurrency", 4, "Number of epochs to migrate concurrently")
+}
+
+// StartBigtableMigrationCommand migrates the selected families for the epoch range and verifies every migrated range.
+// In dry run mode nothing is written and the v1 data is only compared against what is already present in v2.
+func (s *BigtableMigratorCommand) StartBigtableMigrationCommand(bt *db.Bigtable, startEpoch, endEpoch uint64) error {
+	if endEpoch < startEpoch {
+		return fmt.Errorf("end epoch %v is smaller than start epoch %v", endEpoch, startEpoch)
+	}
+	if s.Config.VerifyEpochs == 0 {
+		return errors.New("bigtable-migration.verify-epochs must be > 0")
+	}
+	if s.Config.Concurrency <= 0 {
+		return errors.New("bigtable-migration.concurrency must be > 0")
+	}
+
+	families := []string{}
+	for _, name := range strings.Split(s.Config.Families, ",") {
+		family, ok := bigtableMigrationFamilies[strings.TrimSpace(name)]
+		if !ok {
+			return fmt.Errorf("unknown family %v, available: balances, attestations, proposals, sync", name)
+		}
+		families = append(families, family)
+	}
+
+	checkpoint, err := s.Config.loadCheckpoint(startEpoch, endEpoch)
+	if err != nil {
+		return err
+	}
+
+	totalMismatches := uint64(0)
+	for _, family := range families {
+		if _, ok := checkpoint.NextEpoch[family]; !ok {
+			checkpoint.NextEpoch[family] = startEpoch
+		}
+
+		for from := checkpoint.NextEpoch[family]; from <= endEpoch; from += s.Config.VerifyEpochs {
+			to := from + s.Config.VerifyEpochs - 1
+			if to > endEpoch {
+				to = endEpoch
+			}
+
+			mismatches, err := s.Config.migrateRange(bt, family, from, to)
+			if err != nil {
+				return errors.Wrapf(err, "error migrating family %v for epochs %v - %v", family, from, to)
+			}
+			for _, mismatch := range mismatches {
+				logrus.Warn(mismatch)
+			}
+			err = s.Config.appendReport(mismatches)
+			if err != nil {
+				return err
+			}
+
+			checkpoint.NextEpoch[family] = to + 1
+			checkpoint.Mismatches[family] += uint64(len(mismatches))
+			if !s.Config.DryRun {
+				err = s.Config.saveCheckpoint(checkpoint)
+				if err != nil {
+					return err
+				}
+			}
+			logrus.WithFields(logrus.Fields{"family": family, "mismatches": len(mismatches)}).Infof("migrated and verified epochs %v - %v of %v - %v", from, to, startEpoch, endEpoch)
+		}
+		totalMismatches += checkpoint.Mismatches[family]
+	}
+
+	if totalMismatches > 0 {
+		return fmt.Errorf("verification found %v mismatches between the v1 and v2 schema", totalMismatches)
+	}
+	return nil
+}
+
+// migrateRange migrates all epochs of a range concurrently and verifies the range afterwards
+func (s *bigtableMigratorConfig) migrateRange(bt *db.Bigtable, family string, from, to uint64) ([]string, error) {
+	validators := make(map[uint64]bool)
+	validatorsMux := &sync.Mutex{}
+
+	g := new(errgroup.Group)
+	g.SetLimit(s.Concurrency)
+	for epoch := from; epoch <= to; epoch++ {
+		epoch := epoch
+		g.Go(func() error {
+			migrated, err := bt.MigrateValidatorHistoryV1V2Schema(family, epoch, s.DryRun)
+			if err != nil {
+				return err
+			}
+			validatorsMux.Lock()
+			for _, validator := range migrated {
+				validators[validator] = true
+			}
+			validatorsMux.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	indices := make([]uint64, 0, len(validators))
+	for validator := range validators {
+		indices = append(indices, validator)
+	}
+	return bt.VerifyValidatorHistoryV1V2Schema(family, indices, from, to)
+}
+
+func (s *bigtableMigratorConfig) loadCheckpoint(startEpoch, endEpoch uint64) (*bigtableMigrationCheckpoint, error) {
+	checkpoint := &bigtableMigrationCheckpoint{
+		StartEpoch: startEpoch,
+		EndEpoch:   endEpoch,
+		NextEpoch:  make(map[string]uint64),
+		Mismatches: make(map[string]uint64),
+	}
+	if s.DryRun || s.CheckpointFile == "" {
+		return checkpoint, nil
+	}
+
+	data, err := os.ReadFile(s.CheckpointFile)
+	if os.IsNotExist(err) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading checkpoint file")
+	}
+
+	existing := &bigtableMigrationCheckpoint{}
+	err = json.Unmarshal(data, existing)
+	if err != nil {
+		return nil, errors.Wrap(err, "error decoding checkpoint file")
+	}
+	if existing.StartEpoch != startEpoch || existing.EndEpoch != endEpoch {
+		return nil, fmt.Errorf("checkpoint file %v belongs to epochs %v - %v, remove it or use the same epoch range", s.CheckpointFile, existing.StartEpoch, existing.EndEpoch)
+	}
+	if existing.NextEpoch == nil {
+		existing.NextEpoch = make(map[string]uint64)
+	}
+	if existing.Mismatches == nil {
+		existing.Mismatches = make(map[string]uint64)
+	}
+	logrus.Infof("resuming migration from checkpoint %v: %v", s.CheckpointFile, existing.NextEpoch)
+	return existing, nil
+}
+
+func (s *bigtableMigratorConfig) saveCheckpoint(checkpoint *bigtableMigrationCheckpoint) error {
+	if s.CheckpointFile == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "error encoding checkpoint")
+	}
+	// write to a temporary file first so an interrupted write does not corrupt the checkpoint
+	tmp := s.CheckpointFile + ".tmp"
+	err = os.WriteFile(tmp, data, 0o644)
+	if err != nil {
+		return errors.Wrap(err, "error writing checkpoint file")
+	}
+	return errors.Wrap(os.Rename(tmp, s.CheckpointFile), "error writing checkpoint file")
+}
+
+func (s *bigtableMigratorConfig) appendReport(mismatches []string) error {
+	if s.ReportFile == "" || len(mismatches) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(s.ReportFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return errors.Wrap(err, "error opening report file")
+	}
+	defer f.Close()
+	_, err = f.WriteString(strings.Join(mismatches, "\n") + "\n")
+	return errors.Wrap(err, "error writing report file")
+}
@@ -66,6 +66,7 @@ var opts = struct {
 	Name                string
 	DryRun              bool
 	Yes                 bool
+	RecordFixtures      string
 }{}
 
 var bt *db.Bigtable
@@ -75,9 +76,10 @@ var rpcClient *rpc.LighthouseClient
 
 func main() {
 	statsPartitionCommand := commands.StatsMigratorCommand{}
+	bigtableMigrationCommand := commands.BigtableMigratorCommand{}
 
 	configPath := flag.String("config", "config/default.config.yml", "Path to the config file")
-	flag.StringVar(&opts.Command, "command", "", "command to run, available: updateAPIKey, applyDbSchema, initBigtableSchema, epoch-export, debug-rewards, debug-blocks, clear-bigtable, index-old-eth1-blocks, update-aggregation-bits, historic-prices-export, index-missing-blocks, export-epoch-missed-slots, migrate-last-attestation-slot-bigtable, export-genesis-validators, update-block-finalization-sequentially, nameValidatorsByRanges, export-stats-totals, export-sync-committee-periods, export-sync-committee-validator-stats, partition-validator-stats, migrate-app-purchases, disable-user-per-email, validate-firebase-tokens")
+	flag.StringVar(&opts.Command, "command", "", "command to run, available: updateAPIKey, applyDbSchema, initBigtableSchema, epoch-export, debug-rewards, debug-blocks, clear-bigtable, index-old-eth1-blocks, update-aggregation-bits, historic-prices-export, index-missing-blocks, export-epoch-missed-slots, migrate-last-attestation-slot-bigtable, export-genesis-validators, update-block-finalization-sequentially, nameValidatorsByRanges, export-stats-totals, export-sync-committee-periods, export-sync-committee-validator-stats, partition-validator-stats, migrate-bigtable-v1-v2, apply-validator-history-retention, migrate-app-purchases, disable-user-per-email, validate-firebase-tokens")
 	flag.Uint64Var(&opts.StartEpoch, "start-epoch", 0, "start epoch")
 	flag.Uint64Var(&opts.EndEpoch, "end-epoch", 0, "end epoch")
 	flag.Uint64Var(&opts.User, "user", 0, "user id")
@@ -99,10 +101,12 @@ func main() {
 	flag.StringVar(&opts.Email, "email", "", "Email of the user")
 	flag.StringVar(&opts.Name, "name", "", "Name")
 	flag.BoolVar(&opts.Yes, "yes", false, "Answer yes to all questions")
+	flag.StringVar(&opts.RecordFixtures, "fixtures.record", "", "Directory to record all beacon node responses to, the recorded fixtures can be replayed using rpc.FixtureClient")
 	dryRun := flag.String("dry-run", "true", "if 'false' it deletes all rows starting with the key, per default it only logs the rows that would be deleted, but does not really delete them")
 	versionFlag := flag.Bool("version", false, "Show version and exit")
 
 	statsPartitionCommand.ParseCommandOptions()
+	bigtableMigrationCommand.ParseCommandOptions()
 	flag.Parse()
 
 	if *versionFlag {
@@ -143,6 +147,14 @@ func main() {
 		if err != nil {
 			utils.LogFatal(err, "lighthouse client error", 0)
 		}
+		if opts.RecordFixtures != "" {
+			logrus.Infof("recording beacon node responses to %v", opts.RecordFixtures)
+			fixtureClient, err := rpc.NewFixtureClient(opts.RecordFixtures, rpc.FixtureModeRecord, "http://"+cfg.Indexer.Node.Host+":"+cfg.Indexer.Node.Port, chainIDBig)
+			if err != nil {
+				utils.LogFatal(err, "fixture client error", 0)
+			}
+			rpcClient = fixtureClient.LighthouseClient
+		}
 		lighthouseClient = rpcClient
 	}()
 
@@ -432,6 +444,11 @@ func main() {
 	case "partition-validator-stats":
 		statsPartitionCommand.Config.DryRun = opts.DryRun
 		err = statsPartitionCommand.StartStatsPartitionCommand()
+	case "apply-validator-history-retention":
+		err = bt.ApplyValidatorHistoryRetention(time.Now())
+	case "migrate-bigtable-v1-v2":
+		bigtableMigrationCommand.Config.DryRun = opts.DryRun
+		err = bigtableMigrationCommand.StartBigtableMigrationCommand(bt, opts.StartEpoch, opts.EndEpoch)
 	case "fix-ens":
 		err = fixEns(erigonClient)
 	case "fix-ens-addresses":
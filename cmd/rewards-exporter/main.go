@@ -100,7 +100,7 @@ func main() {
 
 	client := beacon.NewClient(*bnAddress, time.Minute*5)
 
-	bt, err := db.InitBigtable(utils.Config.Bigtable.Project, utils.Config.Bigtable.Instance, fmt.Sprintf("%d", utils.Config.Chain.ClConfig.DepositChainID), utils.Config.RedisCacheEndpoint)
+	bt, err := db.InitStorage(utils.Config.Bigtable.Project, utils.Config.Bigtable.Instance, fmt.Sprintf("%d", utils.Config.Chain.ClConfig.DepositChainID), utils.Config.RedisCacheEndpoint)
 	if err != nil {
 		logrus.Fatalf("error connecting to bigtable: %v", err)
 	}
@@ -226,7 +226,7 @@ func main() {
 	}
 }
 
-func export(epoch uint64, bt *db.Bigtable, client *beacon.Client, elClient *string) error {
+func export(epoch uint64, bt db.Storage, client *beacon.Client, elClient *string) error {
 	start := time.Now()
 	logrus.Infof("retrieving rewards details for epoch %v", epoch)
 
@@ -121,7 +121,7 @@ func main() {
 	defer db.FrontendReaderDB.Close()
 	defer db.FrontendWriterDB.Close()
 
-	_, err = db.InitBigtable(cfg.Bigtable.Project, cfg.Bigtable.Instance, fmt.Sprintf("%d", utils.Config.Chain.ClConfig.DepositChainID), utils.Config.RedisCacheEndpoint)
+	_, err = db.InitStorage(cfg.Bigtable.Project, cfg.Bigtable.Instance, fmt.Sprintf("%d", utils.Config.Chain.ClConfig.DepositChainID), utils.Config.RedisCacheEndpoint)
 	if err != nil {
 		logrus.Fatalf("error connecting to bigtable: %v", err)
 	}
@@ -139,7 +139,12 @@ func main() {
 	var rpcClient rpc.Client
 
 	chainID := new(big.Int).SetUint64(utils.Config.Chain.ClConfig.DepositChainID)
-	if utils.Config.Indexer.Node.Type == "lighthouse" {
+	if utils.Config.Indexer.Node.Type == "lighthouse" && len(cfg.Indexer.Node.Endpoints) > 0 {
+		rpcClient, err = rpc.NewMultiClient(cfg.Indexer.Node.Endpoints, chainID, *cfg.Indexer.Node.MaxSyncDistance)
+		if err != nil {
+			utils.LogFatal(err, "new explorer multi client error", 0)
+		}
+	} else if utils.Config.Indexer.Node.Type == "lighthouse" {
 		rpcClient, err = rpc.NewLighthouseClient("http://"+cfg.Indexer.Node.Host+":"+cfg.Indexer.Node.Port, chainID)
 		if err != nil {
 			utils.LogFatal(err, "new explorer lighthouse client error", 0)
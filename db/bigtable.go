@@ -20,11 +20,12 @@ import (
 	itypes "github.com/gobitfly/eth-rewards/types"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 	"google.golang.org/api/option"
 	"google.golang.org/protobuf/proto"
 )
 
-var BigtableClient *Bigtable
+var BigtableClient Storage
 
 const (
 	DEFAULT_FAMILY                        = "f"
@@ -76,6 +77,13 @@ type Bigtable struct {
 
 	v2SchemaCutOffEpoch uint64
 
+	archive               validatorHistoryArchive
+	retentionMux          *sync.RWMutex
+	retentionState        map[string]uint64 // replaced instead of modified as it is shared with readers
+	retentionStateUpdated time.Time
+	retentionStateVersion uint64 // incremented by every save of the retention state
+	retentionStateGroup   *singleflight.Group
+
 	machineMetricsQueuedWritesChan chan (types.BulkMutation)
 }
 
@@ -126,10 +134,16 @@ func InitBigtable(project, instance, chainId, redisAddress string) (*Bigtable, e
 		chainId:                        chainId,
 		redisCache:                     rdc,
 		LastAttestationCacheMux:        &sync.Mutex{},
+		retentionMux:                   &sync.RWMutex{},
+		retentionStateGroup:            &singleflight.Group{},
 		v2SchemaCutOffEpoch:            utils.Config.Bigtable.V2SchemaCutOffEpoch,
 		machineMetricsQueuedWritesChan: make(chan types.BulkMutation, MAX_BATCH_MUTATIONS),
 	}
 
+	if utils.Config.BlobIndexer.S3.Bucket != "" {
+		bt.archive = newCachedValidatorHistoryArchive(newS3ValidatorHistoryArchive())
+	}
+
 	if utils.Config.Frontend.Enabled { // Only activate machine metrics inserts on frontend / api instances
 		go bt.commitQueuedMachineMetricWrites()
 	}
@@ -437,7 +451,7 @@ func (bigtable Bigtable) GetMachineRowKey(userID uint64, process string, machine
 // machineData contains the latest machine data in CurrentData
 // and 5 minute old data in fiveMinuteOldData (defined in limit)
 // as well as the insert timestamps of both
-func (bigtable Bigtable) GetMachineMetricsForNotifications(rowKeys gcp_bigtable.RowList) (map[uint64]map[string]*types.MachineMetricSystemUser, error) {
+func (bigtable Bigtable) GetMachineMetricsForNotifications(rowKeys []string) (map[uint64]map[string]*types.MachineMetricSystemUser, error) {
 
 	tmr := time.AfterFunc(REPORT_TIMEOUT, func() {
 		logger.WithFields(logrus.Fields{
@@ -458,7 +472,7 @@ func (bigtable Bigtable) GetMachineMetricsForNotifications(rowKeys gcp_bigtable.
 		gcp_bigtable.LatestNFilter(limit),
 	)
 
-	err := bigtable.tableMachineMetrics.ReadRows(ctx, rowKeys, func(r gcp_bigtable.Row) bool {
+	err := bigtable.tableMachineMetrics.ReadRows(ctx, gcp_bigtable.RowList(rowKeys), func(r gcp_bigtable.Row) bool {
 		success, userID, machine, _ := machineMetricRowParts(r.Key())
 		if !success {
 			return false
@@ -510,6 +524,59 @@ func (bigtable Bigtable) GetMachineMetricsForNotifications(rowKeys gcp_bigtable.
 	return res, nil
 }
 
+// Returns a map[userID]map[machineName]machineData of the beacon node process
+// machineData contains the latest beacon node data in CurrentData as well as its insert timestamp
+func (bigtable Bigtable) GetMachineNodeMetricsForNotifications(rowKeys []string) (map[uint64]map[string]*types.MachineMetricNodeUser, error) {
+
+	tmr := time.AfterFunc(REPORT_TIMEOUT, func() {
+		logger.WithFields(logrus.Fields{
+			"rowKeys": rowKeys,
+		}).Warnf("%s call took longer than %v", utils.GetCurrentFuncName(), REPORT_TIMEOUT)
+	})
+	defer tmr.Stop()
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*200))
+	defer cancel()
+
+	res := make(map[uint64]map[string]*types.MachineMetricNodeUser) // userID -> machine -> data
+
+	filter := gcp_bigtable.ChainFilters(
+		gcp_bigtable.FamilyFilter(MACHINE_METRICS_COLUMN_FAMILY),
+		gcp_bigtable.LatestNFilter(1),
+	)
+
+	err := bigtable.tableMachineMetrics.ReadRows(ctx, gcp_bigtable.RowList(rowKeys), func(r gcp_bigtable.Row) bool {
+		success, userID, machine, _ := machineMetricRowParts(r.Key())
+		if !success {
+			return false
+		}
+
+		for _, ri := range r[MACHINE_METRICS_COLUMN_FAMILY] {
+			obj := &types.MachineMetricNode{}
+			err := proto.Unmarshal(ri.Value, obj)
+			if err != nil {
+				return false
+			}
+
+			if _, found := res[userID]; !found {
+				res[userID] = make(map[string]*types.MachineMetricNodeUser)
+			}
+			res[userID][machine] = &types.MachineMetricNodeUser{
+				UserID:              userID,
+				Machine:             machine,
+				CurrentData:         obj,
+				CurrentDataInsertTs: ri.Timestamp.Time().Unix(),
+			}
+		}
+		return true
+	}, gcp_bigtable.RowFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
 func machineMetricRowParts(r string) (bool, uint64, string, string) {
 	keySplit := strings.Split(r, ":")
 
@@ -551,9 +618,14 @@ func (bigtable *Bigtable) SaveValidatorBalances(epoch uint64, validators []*type
 
 		balanceEncoded := make([]byte, 8)
 		binary.LittleEndian.PutUint64(balanceEncoded, validator.Balance)
-		effectiveBalanceEncoded := uint8(validator.EffectiveBalance / 1e9) // we can encode the effective balance in 1 byte as it is capped at 32ETH and only decrements in 1 ETH steps
-
-		combined := append(balanceEncoded, effectiveBalanceEncoded)
+		// the effective balance only changes in 1 ETH steps, so we can encode it in 1 byte for validators capped at 32ETH
+		// and in 2 bytes for compounding validators with an effective balance above 255 ETH
+		var combined []byte
+		if effectiveBalance := validator.EffectiveBalance / 1e9; effectiveBalance <= math.MaxUint8 {
+			combined = append(balanceEncoded, uint8(effectiveBalance))
+		} else {
+			combined = binary.LittleEndian.AppendUint16(balanceEncoded, uint16(effectiveBalance))
+		}
 		mut := &gcp_bigtable.Mutation{}
 		mut.Set(VALIDATOR_BALANCES_FAMILY, "b", ts, combined)
 		key := fmt.Sprintf("%s:%s:%s:%s", bigtable.chainId, bigtable.validatorIndexToKey(validator.Index), VALIDATOR_BALANCES_FAMILY, epochKey)
@@ -606,6 +678,20 @@ func (bigtable *Bigtable) SaveProposalAssignments(epoch uint64, assignments map[
 	return nil
 }
 
+// LastAttestationCacheInitialized returns true once the in memory last attestation slot cache has been populated
+func (bigtable *Bigtable) LastAttestationCacheInitialized() bool {
+	bigtable.LastAttestationCacheMux.Lock()
+	defer bigtable.LastAttestationCacheMux.Unlock()
+	return bigtable.LastAttestationCache != nil
+}
+
+// CachedLastAttestationSlot returns the last attestation slot of a validator from the in memory cache
+func (bigtable *Bigtable) CachedLastAttestationSlot(validator uint64) uint64 {
+	bigtable.LastAttestationCacheMux.Lock()
+	defer bigtable.LastAttestationCacheMux.Unlock()
+	return bigtable.LastAttestationCache[validator]
+}
+
 func (bigtable *Bigtable) SaveAttestationDuties(duties map[types.Slot]map[types.ValidatorIndex][]types.Slot) error {
 
 	// Initialize in memory last attestation cache lazily
@@ -796,9 +882,32 @@ func (bigtable *Bigtable) getMaxValidatorindexForEpochV2(epoch uint64) (uint64,
 func (bigtable *Bigtable) GetValidatorBalanceHistory(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64][]*types.ValidatorBalance, error) {
 	if endEpoch < bigtable.v2SchemaCutOffEpoch {
 		return bigtable.getValidatorBalanceHistoryV1(validators, startEpoch, endEpoch)
-	} else {
+	}
+
+	prunedEnd, pruned, err := bigtable.prunedRange(VALIDATOR_BALANCES_FAMILY, startEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+	if !pruned {
 		return bigtable.getValidatorBalanceHistoryV2(validators, startEpoch, endEpoch)
 	}
+
+	// epochs removed from bigtable by the retention policy are older than the ones still stored, so append them after the stored ones
+	res := make(map[uint64][]*types.ValidatorBalance, len(validators))
+	if prunedEnd < endEpoch {
+		res, err = bigtable.getValidatorBalanceHistoryV2(validators, prunedEnd+1, endEpoch)
+		if err != nil {
+			return nil, err
+		}
+	}
+	retained, err := bigtable.getValidatorBalanceHistoryRetained(validators, startEpoch, prunedEnd)
+	if err != nil {
+		return nil, err
+	}
+	for validator, balances := range retained {
+		res[validator] = append(res[validator], balances...)
+	}
+	return res, nil
 }
 
 func (bigtable *Bigtable) getValidatorBalanceHistoryV2(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64][]*types.ValidatorBalance, error) {
@@ -874,6 +983,8 @@ func (bigtable *Bigtable) getValidatorBalanceHistoryV2(validators []uint64, star
 					var effectiveBalance uint64
 					if len(balances) == 9 { // in new schema the effective balance is encoded in 1 byte
 						effectiveBalance = uint64(balances[8]) * 1e9
+					} else if len(balances) == 10 { // or in 2 bytes for effective balances above 255 ETH
+						effectiveBalance = uint64(binary.LittleEndian.Uint16(balances[8:10])) * 1e9
 					} else {
 						effectiveBalanceBytes := balances[8:16]
 						effectiveBalance = binary.LittleEndian.Uint64(effectiveBalanceBytes)
@@ -1003,9 +1114,36 @@ func (bigtable *Bigtable) getValidatorBalanceHistoryV1(validators []uint64, star
 func (bigtable *Bigtable) GetValidatorAttestationHistory(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64][]*types.ValidatorAttestation, error) {
 	if endEpoch < bigtable.v2SchemaCutOffEpoch {
 		return bigtable.getValidatorAttestationHistoryV1(validators, startEpoch, endEpoch)
-	} else {
+	}
+
+	prunedEnd, pruned, err := bigtable.prunedRange(ATTESTATIONS_FAMILY, startEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+	if !pruned {
 		return bigtable.getValidatorAttestationHistoryV2(validators, startEpoch, endEpoch)
 	}
+
+	// attestations are sorted by attester slot desc, so append the pruned epochs after the stored ones
+	res := make(map[uint64][]*types.ValidatorAttestation, len(validators))
+	if prunedEnd < endEpoch {
+		res, err = bigtable.getValidatorAttestationHistoryV2(validators, prunedEnd+1, endEpoch)
+		if err != nil {
+			return nil, err
+		}
+	}
+	inclusions, err := bigtable.getValidatorAttestationInclusionsRetained(validators, startEpoch, prunedEnd)
+	if err != nil {
+		return nil, err
+	}
+	retained, err := attestationHistoryFromInclusions(inclusions, startEpoch, prunedEnd)
+	if err != nil {
+		return nil, err
+	}
+	for validator, attestations := range retained {
+		res[validator] = append(res[validator], attestations...)
+	}
+	return res, nil
 }
 
 func (bigtable *Bigtable) getValidatorAttestationHistoryV2(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64][]*types.ValidatorAttestation, error) {
@@ -1018,6 +1156,15 @@ func (bigtable *Bigtable) getValidatorAttestationHistoryV2(validators []uint64,
 	})
 	defer tmr.Stop()
 
+	attestationsMap, err := bigtable.getValidatorAttestationInclusionsV2(validators, startEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+	return attestationHistoryFromInclusions(attestationsMap, startEpoch, endEpoch)
+}
+
+// getValidatorAttestationInclusionsV2 returns all stored inclusions of the attestations of the validators, missed attestations have a status of 0
+func (bigtable *Bigtable) getValidatorAttestationInclusionsV2(validators []uint64, startEpoch uint64, endEpoch uint64) (map[types.ValidatorIndex]map[types.Slot][]*types.ValidatorAttestation, error) {
 	if len(validators) == 0 {
 		return nil, fmt.Errorf("passing empty validator array is unsupported")
 	}
@@ -1028,7 +1175,6 @@ func (bigtable *Bigtable) getValidatorAttestationHistoryV2(validators []uint64,
 	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Minute*5))
 	defer cancel()
 
-	res := make(map[uint64][]*types.ValidatorAttestation, len(validators))
 	resMux := &sync.Mutex{}
 
 	g, gCtx := errgroup.WithContext(ctx)
@@ -1103,6 +1249,13 @@ func (bigtable *Bigtable) getValidatorAttestationHistoryV2(validators []uint64,
 		return nil, err
 	}
 
+	return attestationsMap, nil
+}
+
+// attestationHistoryFromInclusions selects the valid inclusion of every attestation and calculates its inclusion delay
+func attestationHistoryFromInclusions(attestationsMap map[types.ValidatorIndex]map[types.Slot][]*types.ValidatorAttestation, startEpoch uint64, endEpoch uint64) (map[uint64][]*types.ValidatorAttestation, error) {
+	res := make(map[uint64][]*types.ValidatorAttestation, len(attestationsMap))
+
 	// Find all missed and orphaned slots
 	slots := []uint64{}
 	maxSlot := ((endEpoch + 1) * utils.Config.Chain.ClConfig.SlotsPerEpoch) - 1
@@ -1113,7 +1266,7 @@ func (bigtable *Bigtable) getValidatorAttestationHistoryV2(validators []uint64,
 	var missedSlotsMap map[uint64]bool
 	var orphanedSlotsMap map[uint64]bool
 
-	g = new(errgroup.Group)
+	g := new(errgroup.Group)
 
 	g.Go(func() error {
 		var err error
@@ -1400,9 +1553,36 @@ func (bigtable *Bigtable) GetLastAttestationSlots(validators []uint64) (map[uint
 func (bigtable *Bigtable) GetValidatorMissedAttestationHistory(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64]map[uint64]bool, error) {
 	if endEpoch < bigtable.v2SchemaCutOffEpoch {
 		return bigtable.getValidatorMissedAttestationHistoryV1(validators, startEpoch, endEpoch)
-	} else {
+	}
+
+	prunedEnd, pruned, err := bigtable.prunedRange(ATTESTATIONS_FAMILY, startEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+	if !pruned {
 		return bigtable.getValidatorMissedAttestationHistoryV2(validators, startEpoch, endEpoch)
 	}
+
+	res := make(map[uint64]map[uint64]bool)
+	if prunedEnd < endEpoch {
+		res, err = bigtable.getValidatorMissedAttestationHistoryV2(validators, prunedEnd+1, endEpoch)
+		if err != nil {
+			return nil, err
+		}
+	}
+	retained, err := bigtable.getValidatorMissedAttestationHistoryRetained(validators, startEpoch, prunedEnd)
+	if err != nil {
+		return nil, err
+	}
+	for validator, missed := range retained {
+		if res[validator] == nil {
+			res[validator] = make(map[uint64]bool, len(missed))
+		}
+		for slot := range missed {
+			res[validator][slot] = true
+		}
+	}
+	return res, nil
 }
 
 func (bigtable *Bigtable) getValidatorMissedAttestationHistoryV2(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64]map[uint64]bool, error) {
@@ -1844,6 +2024,29 @@ func (bigtable *Bigtable) GetValidatorMissedAttestationsCount(validators []uint6
 		}
 	}
 
+	// epochs that have been pruned without being archived are only available as downsampled aggregates
+	if lastEpoch >= bigtable.v2SchemaCutOffEpoch {
+		prunedEnd, pruned, err := bigtable.prunedRange(ATTESTATIONS_FAMILY, firstEpoch, lastEpoch)
+		if err != nil {
+			return nil, err
+		}
+		if pruned {
+			aggregated, err := bigtable.getValidatorMissedAttestationAggregates(validators, firstEpoch, prunedEnd)
+			if err != nil {
+				return nil, err
+			}
+			for validator, missed := range aggregated {
+				if missed == 0 {
+					continue
+				}
+				if res[validator] == nil {
+					res[validator] = &types.ValidatorMissedAttestationsStatistic{Index: validator}
+				}
+				res[validator].MissedAttestations += missed
+			}
+		}
+	}
+
 	return res, nil
 }
 
@@ -1913,7 +2116,12 @@ func (bigtable *Bigtable) GetValidatorEffectiveness(validators []uint64, epoch u
 		return nil, err
 	}
 
-	res := make([]*types.ValidatorEffectiveness, 0, len(validators))
+	return validatorEffectivenessFromHistory(data), nil
+}
+
+// validatorEffectivenessFromHistory computes the attestation effectiveness in % from the attestation history of the validators
+func validatorEffectivenessFromHistory(data map[uint64][]*types.ValidatorAttestation) []*types.ValidatorEffectiveness {
+	res := make([]*types.ValidatorEffectiveness, 0, len(data))
 	type readings struct {
 		Count uint64
 		Sum   float64
@@ -1943,7 +2151,7 @@ func (bigtable *Bigtable) GetValidatorEffectiveness(validators []uint64, epoch u
 		})
 	}
 
-	return res, nil
+	return res
 }
 
 func (bigtable *Bigtable) GetValidatorBalanceStatistics(validators []uint64, startEpoch, endEpoch uint64) (map[uint64]*types.ValidatorBalanceStatistic, error) {
@@ -2219,6 +2427,24 @@ func (bigtable *Bigtable) getValidatorProposalHistoryV1(validators []uint64, sta
 	return res, nil
 }
 
+// addValidatorEpochIncome adds the income details of a single validator to the epoch total
+func addValidatorEpochIncome(total, rewardDetails *itypes.ValidatorEpochIncome) {
+	total.AttestationHeadReward += rewardDetails.AttestationHeadReward
+	total.AttestationSourceReward += rewardDetails.AttestationSourceReward
+	total.AttestationSourcePenalty += rewardDetails.AttestationSourcePenalty
+	total.AttestationTargetReward += rewardDetails.AttestationTargetReward
+	total.AttestationTargetPenalty += rewardDetails.AttestationTargetPenalty
+	total.FinalityDelayPenalty += rewardDetails.FinalityDelayPenalty
+	total.ProposerSlashingInclusionReward += rewardDetails.ProposerSlashingInclusionReward
+	total.ProposerAttestationInclusionReward += rewardDetails.ProposerAttestationInclusionReward
+	total.ProposerSyncInclusionReward += rewardDetails.ProposerSyncInclusionReward
+	total.SyncCommitteeReward += rewardDetails.SyncCommitteeReward
+	total.SyncCommitteePenalty += rewardDetails.SyncCommitteePenalty
+	total.SlashingReward += rewardDetails.SlashingReward
+	total.SlashingPenalty += rewardDetails.SlashingPenalty
+	total.TxFeeRewardWei = utils.AddBigInts(total.TxFeeRewardWei, rewardDetails.TxFeeRewardWei)
+}
+
 func (bigtable *Bigtable) SaveValidatorIncomeDetails(epoch uint64, rewards map[uint64]*itypes.ValidatorEpochIncome) error {
 
 	start := time.Now()
@@ -2242,20 +2468,7 @@ func (bigtable *Bigtable) SaveValidatorIncomeDetails(epoch uint64, rewards map[u
 
 		muts.Add(key, mut)
 
-		total.AttestationHeadReward += rewardDetails.AttestationHeadReward
-		total.AttestationSourceReward += rewardDetails.AttestationSourceReward
-		total.AttestationSourcePenalty += rewardDetails.AttestationSourcePenalty
-		total.AttestationTargetReward += rewardDetails.AttestationTargetReward
-		total.AttestationTargetPenalty += rewardDetails.AttestationTargetPenalty
-		total.FinalityDelayPenalty += rewardDetails.FinalityDelayPenalty
-		total.ProposerSlashingInclusionReward += rewardDetails.ProposerSlashingInclusionReward
-		total.ProposerAttestationInclusionReward += rewardDetails.ProposerAttestationInclusionReward
-		total.ProposerSyncInclusionReward += rewardDetails.ProposerSyncInclusionReward
-		total.SyncCommitteeReward += rewardDetails.SyncCommitteeReward
-		total.SyncCommitteePenalty += rewardDetails.SyncCommitteePenalty
-		total.SlashingReward += rewardDetails.SlashingReward
-		total.SlashingPenalty += rewardDetails.SlashingPenalty
-		total.TxFeeRewardWei = utils.AddBigInts(total.TxFeeRewardWei, rewardDetails.TxFeeRewardWei)
+		addValidatorEpochIncome(total, rewardDetails)
 	}
 
 	sum, err := proto.Marshal(total)
@@ -0,0 +1,233 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+var errArchiveObjectNotFound = errors.New("archive object not found")
+
+// validatorHistoryArchive stores the parquet files of the validator history that has been removed from bigtable
+type validatorHistoryArchive interface {
+	put(ctx context.Context, key string, data []byte) error
+	// get returns errArchiveObjectNotFound if no object is stored under the key
+	get(ctx context.Context, key string) ([]byte, error)
+}
+
+// validatorHistoryArchiveCacheSize is the number of archive files kept in memory, a file holds the history of a day of validatorHistoryArchiveChunkSize validators
+const validatorHistoryArchiveCacheSize = 16
+
+// cachedValidatorHistoryArchive keeps the most recently read files of an archive in memory.
+// Archive files are written once per complete day and never change afterwards, so cached files do not go stale.
+type cachedValidatorHistoryArchive struct {
+	archive validatorHistoryArchive
+	files   *lru.Cache
+}
+
+func newCachedValidatorHistoryArchive(archive validatorHistoryArchive) *cachedValidatorHistoryArchive {
+	files, _ := lru.New(validatorHistoryArchiveCacheSize)
+	return &cachedValidatorHistoryArchive{archive: archive, files: files}
+}
+
+func (a *cachedValidatorHistoryArchive) put(ctx context.Context, key string, data []byte) error {
+	err := a.archive.put(ctx, key, data)
+	if err != nil {
+		return err
+	}
+	a.files.Remove(key)
+	return nil
+}
+
+// get does not cache missing objects as they are written once the day they belong to has been archived
+func (a *cachedValidatorHistoryArchive) get(ctx context.Context, key string) ([]byte, error) {
+	if data, ok := a.files.Get(key); ok {
+		return data.([]byte), nil
+	}
+	data, err := a.archive.get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	a.files.Add(key, data)
+	return data, nil
+}
+
+// s3ValidatorHistoryArchive stores the archive in the s3 compatible bucket of the blob indexer
+type s3ValidatorHistoryArchive struct {
+	client *s3.Client
+	bucket string
+}
+
+func newS3ValidatorHistoryArchive() *s3ValidatorHistoryArchive {
+	client := utils.NewS3Client(utils.Config.BlobIndexer.S3.Endpoint, utils.Config.BlobIndexer.S3.AccessKeyId, utils.Config.BlobIndexer.S3.AccessKeySecret)
+	return &s3ValidatorHistoryArchive{client: client, bucket: utils.Config.BlobIndexer.S3.Bucket}
+}
+
+func (a *s3ValidatorHistoryArchive) put(ctx context.Context, key string, data []byte) error {
+	_, err := a.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: &a.bucket,
+		Key:    &key,
+		Body:   bytes.NewReader(data),
+	})
+	return err
+}
+
+func (a *s3ValidatorHistoryArchive) get(ctx context.Context, key string) ([]byte, error) {
+	obj, err := a.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &a.bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		// depending on the s3:ListBucket permission missing objects are reported as 404 or 403
+		var httpResponseErr *awshttp.ResponseError
+		if errors.As(err, &httpResponseErr) && (httpResponseErr.HTTPStatusCode() == 404 || httpResponseErr.HTTPStatusCode() == 403) {
+			return nil, errArchiveObjectNotFound
+		}
+		return nil, err
+	}
+	defer obj.Body.Close()
+	return io.ReadAll(obj.Body)
+}
+
+// archiveKey returns the object key of the archive file of a family for a day and a chunk of validatorHistoryArchiveChunkSize validators
+func (bigtable *Bigtable) archiveKey(family string, day uint64, chunk uint64) string {
+	return fmt.Sprintf("validator-history/%s/%s/%d/%d.parquet", bigtable.chainId, family, day, chunk)
+}
+
+type parquetBalance struct {
+	Validator        int64 `parquet:"name=validator, type=INT64, convertedtype=UINT_64"`
+	Epoch            int64 `parquet:"name=epoch, type=INT64, convertedtype=UINT_64"`
+	Balance          int64 `parquet:"name=balance, type=INT64, convertedtype=UINT_64"`
+	EffectiveBalance int64 `parquet:"name=effective_balance, type=INT64, convertedtype=UINT_64"`
+}
+
+// parquetAttestation holds a single inclusion of an attestation, missed attestations have an inclusion slot of 0
+type parquetAttestation struct {
+	Validator     int64 `parquet:"name=validator, type=INT64, convertedtype=UINT_64"`
+	AttesterSlot  int64 `parquet:"name=attester_slot, type=INT64, convertedtype=UINT_64"`
+	InclusionSlot int64 `parquet:"name=inclusion_slot, type=INT64, convertedtype=UINT_64"`
+}
+
+func encodeBalancesParquet(balances map[uint64][]*types.ValidatorBalance) ([]byte, error) {
+	rows := make([]interface{}, 0, len(balances))
+	for validator, history := range balances {
+		for _, b := range history {
+			rows = append(rows, &parquetBalance{
+				Validator:        int64(validator),
+				Epoch:            int64(b.Epoch),
+				Balance:          int64(b.Balance),
+				EffectiveBalance: int64(b.EffectiveBalance),
+			})
+		}
+	}
+	return encodeParquet(new(parquetBalance), rows)
+}
+
+func encodeAttestationsParquet(attestations map[types.ValidatorIndex]map[types.Slot][]*types.ValidatorAttestation) ([]byte, error) {
+	rows := make([]interface{}, 0, len(attestations))
+	for validator, slots := range attestations {
+		for attesterSlot, inclusions := range slots {
+			for _, inclusion := range inclusions {
+				rows = append(rows, &parquetAttestation{
+					Validator:     int64(validator),
+					AttesterSlot:  int64(attesterSlot),
+					InclusionSlot: int64(inclusion.InclusionSlot),
+				})
+			}
+		}
+	}
+	return encodeParquet(new(parquetAttestation), rows)
+}
+
+func encodeParquet(schema interface{}, rows []interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	pw, err := writer.NewParquetWriterFromWriter(buf, schema, 4)
+	if err != nil {
+		return nil, fmt.Errorf("error creating parquet writer: %w", err)
+	}
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+	for _, row := range rows {
+		if err := pw.Write(row); err != nil {
+			return nil, fmt.Errorf("error writing parquet row: %w", err)
+		}
+	}
+	if err := pw.WriteStop(); err != nil {
+		return nil, fmt.Errorf("error finishing parquet file: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeParquet[T any](data []byte) ([]T, error) {
+	pr, err := reader.NewParquetReader(&parquetBuffer{data: data}, new(T), 4)
+	if err != nil {
+		return nil, fmt.Errorf("error opening parquet file: %w", err)
+	}
+	defer pr.ReadStop()
+
+	rows := make([]T, pr.GetNumRows())
+	if err := pr.Read(&rows); err != nil {
+		return nil, fmt.Errorf("error reading parquet rows: %w", err)
+	}
+	return rows, nil
+}
+
+// parquetBuffer implements source.ParquetFile on top of an in memory parquet file
+type parquetBuffer struct {
+	data   []byte
+	offset int64
+}
+
+func (b *parquetBuffer) Open(name string) (source.ParquetFile, error) {
+	return &parquetBuffer{data: b.data}, nil
+}
+
+func (b *parquetBuffer) Create(name string) (source.ParquetFile, error) {
+	return nil, fmt.Errorf("parquet buffers are read only")
+}
+
+func (b *parquetBuffer) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += b.offset
+	case io.SeekEnd:
+		offset += int64(len(b.data))
+	default:
+		return 0, fmt.Errorf("invalid whence %v", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative seek offset %v", offset)
+	}
+	b.offset = offset
+	return offset, nil
+}
+
+func (b *parquetBuffer) Read(p []byte) (int, error) {
+	if b.offset >= int64(len(b.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, b.data[b.offset:])
+	b.offset += int64(n)
+	return n, nil
+}
+
+func (b *parquetBuffer) Write(p []byte) (int, error) {
+	return 0, fmt.Errorf("parquet buffers are read only")
+}
+
+func (b *parquetBuffer) Close() error {
+	return nil
+}
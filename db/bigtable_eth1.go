@@ -790,7 +790,44 @@ func (bigtable *Bigtable) TransformBlock(block *types.Eth1Block, cache *freecach
 	bulkData = &types.BulkMutations{}
 	bulkMetadataUpdates = &types.BulkMutations{}
 
-	idx := types.Eth1BlockIndexed{
+	idx := indexedEth1Block(block)
+
+	// Mark Coinbase for balance update
+	bigtable.markBalanceUpdate(idx.Coinbase, []byte{0x0}, bulkMetadataUpdates, cache)
+
+	// <chainID>:b:<reverse number>
+	key := fmt.Sprintf("%s:B:%s", bigtable.chainId, reversedPaddedBlockNumber(block.GetNumber()))
+	mut := gcp_bigtable.NewMutation()
+
+	b, err := proto.Marshal(idx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error marshalling proto object err: %w", err)
+	}
+
+	mut.Set(DEFAULT_FAMILY, DATA_COLUMN, gcp_bigtable.Timestamp(0), b)
+
+	bulkData.Keys = append(bulkData.Keys, key)
+	bulkData.Muts = append(bulkData.Muts, mut)
+
+	indexes := []string{
+		// Index blocks by the miners address
+		fmt.Sprintf("%s:I:B:%x:TIME:%s", bigtable.chainId, block.GetCoinbase(), reversePaddedBigtableTimestamp(block.Time)),
+	}
+
+	for _, idx := range indexes {
+		mut := gcp_bigtable.NewMutation()
+		mut.Set(DEFAULT_FAMILY, key, gcp_bigtable.Timestamp(0), nil)
+
+		bulkData.Keys = append(bulkData.Keys, idx)
+		bulkData.Muts = append(bulkData.Muts, mut)
+	}
+
+	return bulkData, bulkMetadataUpdates, nil
+}
+
+// indexedEth1Block builds the indexed representation of a block that is shown in the block lists
+func indexedEth1Block(block *types.Eth1Block) *types.Eth1BlockIndexed {
+	idx := &types.Eth1BlockIndexed{
 		Hash:       block.GetHash(),
 		ParentHash: block.GetParentHash(),
 		UncleHash:  block.GetUncleHash(),
@@ -896,37 +933,7 @@ func (bigtable *Bigtable) TransformBlock(block *types.Eth1Block, cache *freecach
 
 	idx.Mev = CalculateMevFromBlock(block).Bytes() // deprecated but we still write the value to keep all blocks consistent
 
-	// Mark Coinbase for balance update
-	bigtable.markBalanceUpdate(idx.Coinbase, []byte{0x0}, bulkMetadataUpdates, cache)
-
-	// <chainID>:b:<reverse number>
-	key := fmt.Sprintf("%s:B:%s", bigtable.chainId, reversedPaddedBlockNumber(block.GetNumber()))
-	mut := gcp_bigtable.NewMutation()
-
-	b, err := proto.Marshal(&idx)
-	if err != nil {
-		return nil, nil, fmt.Errorf("error marshalling proto object err: %w", err)
-	}
-
-	mut.Set(DEFAULT_FAMILY, DATA_COLUMN, gcp_bigtable.Timestamp(0), b)
-
-	bulkData.Keys = append(bulkData.Keys, key)
-	bulkData.Muts = append(bulkData.Muts, mut)
-
-	indexes := []string{
-		// Index blocks by the miners address
-		fmt.Sprintf("%s:I:B:%x:TIME:%s", bigtable.chainId, block.GetCoinbase(), reversePaddedBigtableTimestamp(block.Time)),
-	}
-
-	for _, idx := range indexes {
-		mut := gcp_bigtable.NewMutation()
-		mut.Set(DEFAULT_FAMILY, key, gcp_bigtable.Timestamp(0), nil)
-
-		bulkData.Keys = append(bulkData.Keys, idx)
-		bulkData.Muts = append(bulkData.Muts, mut)
-	}
-
-	return bulkData, bulkMetadataUpdates, nil
+	return idx
 }
 
 func CalculateMevFromBlock(block *types.Eth1Block) *big.Int {
@@ -985,37 +992,11 @@ func (bigtable *Bigtable) TransformTx(blk *types.Eth1Block, cache *freecache.Cac
 			return nil, nil, fmt.Errorf("unexpected number of transactions in block expected at most %d but got: %v, tx: %x", TX_PER_BLOCK_LIMIT-1, i, tx.GetHash())
 		}
 		iReverse := reversePaddedIndex(i, TX_PER_BLOCK_LIMIT)
-		// logger.Infof("address to: %x address: contract: %x, len(to): %v, len(contract): %v, contranct zero: %v", tx.GetTo(), tx.GetContractAddress(), len(tx.GetTo()), len(tx.GetContractAddress()), bytes.Equal(tx.GetContractAddress(), ZERO_ADDRESS))
-		to := tx.GetTo()
-		isContract := false
-		if !bytes.Equal(tx.GetContractAddress(), ZERO_ADDRESS) {
-			to = tx.GetContractAddress()
-			isContract = true
-		}
-		// logger.Infof("sending to: %x", to)
-		method := make([]byte, 0)
-		if len(tx.GetData()) > 3 {
-			method = tx.GetData()[:4]
-		}
 
 		key := fmt.Sprintf("%s:TX:%x", bigtable.chainId, tx.GetHash())
-		fee := new(big.Int).Mul(new(big.Int).SetBytes(tx.GetGasPrice()), big.NewInt(int64(tx.GetGasUsed()))).Bytes()
-		blobFee := new(big.Int).Mul(new(big.Int).SetBytes(tx.GetBlobGasPrice()), big.NewInt(int64(tx.GetBlobGasUsed()))).Bytes()
-		indexedTx := &types.Eth1TransactionIndexed{
-			Hash:               tx.GetHash(),
-			BlockNumber:        blk.GetNumber(),
-			Time:               blk.GetTime(),
-			MethodId:           method,
-			From:               tx.GetFrom(),
-			To:                 to,
-			Value:              tx.GetValue(),
-			TxFee:              fee,
-			GasPrice:           tx.GetGasPrice(),
-			BlobTxFee:          blobFee,
-			BlobGasPrice:       tx.GetBlobGasPrice(),
-			IsContractCreation: isContract,
-			ErrorMsg:           tx.GetErrorMsg(),
-		}
+		indexedTx := indexedEth1Transaction(blk, tx)
+		to := indexedTx.To
+		method := indexedTx.MethodId
 		// Mark Sender and Recipient for balance update
 		bigtable.markBalanceUpdate(indexedTx.From, []byte{0x0}, bulkMetadataUpdates, cache)
 		bigtable.markBalanceUpdate(indexedTx.To, []byte{0x0}, bulkMetadataUpdates, cache)
@@ -1069,6 +1050,40 @@ func (bigtable *Bigtable) TransformTx(blk *types.Eth1Block, cache *freecache.Cac
 	return bulkData, bulkMetadataUpdates, nil
 }
 
+// indexedEth1Transaction builds the indexed representation of a transaction that is shown in the transaction lists
+func indexedEth1Transaction(blk *types.Eth1Block, tx *types.Eth1Transaction) *types.Eth1TransactionIndexed {
+	// logger.Infof("address to: %x address: contract: %x, len(to): %v, len(contract): %v, contranct zero: %v", tx.GetTo(), tx.GetContractAddress(), len(tx.GetTo()), len(tx.GetContractAddress()), bytes.Equal(tx.GetContractAddress(), ZERO_ADDRESS))
+	to := tx.GetTo()
+	isContract := false
+	if !bytes.Equal(tx.GetContractAddress(), ZERO_ADDRESS) {
+		to = tx.GetContractAddress()
+		isContract = true
+	}
+	// logger.Infof("sending to: %x", to)
+	method := make([]byte, 0)
+	if len(tx.GetData()) > 3 {
+		method = tx.GetData()[:4]
+	}
+
+	fee := new(big.Int).Mul(new(big.Int).SetBytes(tx.GetGasPrice()), big.NewInt(int64(tx.GetGasUsed()))).Bytes()
+	blobFee := new(big.Int).Mul(new(big.Int).SetBytes(tx.GetBlobGasPrice()), big.NewInt(int64(tx.GetBlobGasUsed()))).Bytes()
+	return &types.Eth1TransactionIndexed{
+		Hash:               tx.GetHash(),
+		BlockNumber:        blk.GetNumber(),
+		Time:               blk.GetTime(),
+		MethodId:           method,
+		From:               tx.GetFrom(),
+		To:                 to,
+		Value:              tx.GetValue(),
+		TxFee:              fee,
+		GasPrice:           tx.GetGasPrice(),
+		BlobTxFee:          blobFee,
+		BlobGasPrice:       tx.GetBlobGasPrice(),
+		IsContractCreation: isContract,
+		ErrorMsg:           tx.GetErrorMsg(),
+	}
+}
+
 // TransformBlobTx extracts transactions from bigtable more specifically from the table blocks.
 func (bigtable *Bigtable) TransformBlobTx(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error) {
 	startTime := time.Now()
@@ -1088,28 +1103,10 @@ func (bigtable *Bigtable) TransformBlobTx(blk *types.Eth1Block, cache *freecache
 			continue
 		}
 		iReverse := reversePaddedIndex(i, TX_PER_BLOCK_LIMIT)
-		// logger.Infof("address to: %x address: contract: %x, len(to): %v, len(contract): %v, contranct zero: %v", tx.GetTo(), tx.GetContractAddress(), len(tx.GetTo()), len(tx.GetContractAddress()), bytes.Equal(tx.GetContractAddress(), ZERO_ADDRESS))
-		to := tx.GetTo()
-
-		// logger.Infof("sending to: %x", to)
 
 		key := fmt.Sprintf("%s:BTX:%x", bigtable.chainId, tx.GetHash())
-		fee := new(big.Int).Mul(new(big.Int).SetBytes(tx.GetGasPrice()), big.NewInt(int64(tx.GetGasUsed()))).Bytes()
-		blobFee := new(big.Int).Mul(new(big.Int).SetBytes(tx.GetBlobGasPrice()), big.NewInt(int64(tx.GetBlobGasUsed()))).Bytes()
-		indexedTx := &types.Eth1BlobTransactionIndexed{
-			Hash:                tx.GetHash(),
-			BlockNumber:         blk.GetNumber(),
-			Time:                blk.GetTime(),
-			From:                tx.GetFrom(),
-			To:                  to,
-			Value:               tx.GetValue(),
-			TxFee:               fee,
-			GasPrice:            tx.GetGasPrice(),
-			BlobTxFee:           blobFee,
-			BlobGasPrice:        tx.GetBlobGasPrice(),
-			ErrorMsg:            tx.GetErrorMsg(),
-			BlobVersionedHashes: tx.GetBlobVersionedHashes(),
-		}
+		indexedTx := indexedEth1BlobTransaction(blk, tx)
+		to := indexedTx.To
 		// Mark Sender and Recipient for balance update
 		bigtable.markBalanceUpdate(indexedTx.From, []byte{0x0}, bulkMetadataUpdates, cache)
 		bigtable.markBalanceUpdate(indexedTx.To, []byte{0x0}, bulkMetadataUpdates, cache)
@@ -1156,6 +1153,26 @@ func (bigtable *Bigtable) TransformBlobTx(blk *types.Eth1Block, cache *freecache
 	return bulkData, bulkMetadataUpdates, nil
 }
 
+// indexedEth1BlobTransaction builds the indexed representation of a blob transaction that is shown in the blob transaction lists
+func indexedEth1BlobTransaction(blk *types.Eth1Block, tx *types.Eth1Transaction) *types.Eth1BlobTransactionIndexed {
+	fee := new(big.Int).Mul(new(big.Int).SetBytes(tx.GetGasPrice()), big.NewInt(int64(tx.GetGasUsed()))).Bytes()
+	blobFee := new(big.Int).Mul(new(big.Int).SetBytes(tx.GetBlobGasPrice()), big.NewInt(int64(tx.GetBlobGasUsed()))).Bytes()
+	return &types.Eth1BlobTransactionIndexed{
+		Hash:                tx.GetHash(),
+		BlockNumber:         blk.GetNumber(),
+		Time:                blk.GetTime(),
+		From:                tx.GetFrom(),
+		To:                  tx.GetTo(),
+		Value:               tx.GetValue(),
+		TxFee:               fee,
+		GasPrice:            tx.GetGasPrice(),
+		BlobTxFee:           blobFee,
+		BlobGasPrice:        tx.GetBlobGasPrice(),
+		ErrorMsg:            tx.GetErrorMsg(),
+		BlobVersionedHashes: tx.GetBlobVersionedHashes(),
+	}
+}
+
 // custom timestamp
 func encodeIsContractUpdateTs(block_number, tx_idx, trace_idx uint64) (gcp_bigtable.Timestamp, error) {
 	var res uint64
@@ -1204,46 +1221,73 @@ func (bigtable *Bigtable) TransformContract(blk *types.Eth1Block, cache *freecac
 	bulkMetadataUpdates = &types.BulkMutations{}
 	contractUpdateWrites := &types.BulkMutations{}
 
+	updates, err := blockContractUpdates(blk)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, u := range updates {
+		b, err := proto.Marshal(u.update)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mutWrite := gcp_bigtable.NewMutation()
+		ts, err := encodeIsContractUpdateTs(blk.GetNumber(), u.txIdx, u.traceIdx)
+		if err != nil {
+			utils.LogError(err, "error generating bigtable isContract timestamp", 0)
+		} else {
+			mutWrite.Set(ACCOUNT_METADATA_FAMILY, ACCOUNT_IS_CONTRACT, ts, b)
+			contractUpdateWrites.Keys = append(contractUpdateWrites.Keys, fmt.Sprintf("%s:S:%x", bigtable.chainId, u.address))
+			contractUpdateWrites.Muts = append(contractUpdateWrites.Muts, mutWrite)
+		}
+	}
+
+	err = bigtable.WriteBulk(contractUpdateWrites, bigtable.tableMetadata, DEFAULT_BATCH_INSERTS)
+	return bulkData, bulkMetadataUpdates, err
+}
+
+type blockContractUpdate struct {
+	address  []byte
+	txIdx    uint64
+	traceIdx uint64
+	update   *types.IsContractUpdate
+}
+
+// blockContractUpdates returns the contract creations and destructions of the internal transactions of a block
+func blockContractUpdates(blk *types.Eth1Block) ([]blockContractUpdate, error) {
+	updates := []blockContractUpdate{}
+
 	for i, tx := range blk.GetTransactions() {
 		if i >= TX_PER_BLOCK_LIMIT {
-			return nil, nil, fmt.Errorf("unexpected number of transactions in block expected at most %d but got: %v, tx: %x", TX_PER_BLOCK_LIMIT-1, i, tx.GetHash())
+			return nil, fmt.Errorf("unexpected number of transactions in block expected at most %d but got: %v, tx: %x", TX_PER_BLOCK_LIMIT-1, i, tx.GetHash())
 		}
 
 		for j, itx := range tx.GetItx() {
 			if j >= ITX_PER_TX_LIMIT {
-				return nil, nil, fmt.Errorf("unexpected number of internal transactions in block expected at most %d but got: %v, tx: %x", ITX_PER_TX_LIMIT, j, tx.GetHash())
+				return nil, fmt.Errorf("unexpected number of internal transactions in block expected at most %d but got: %v, tx: %x", ITX_PER_TX_LIMIT, j, tx.GetHash())
 			}
 
 			if itx.GetType() == "create" || itx.GetType() == "suicide" {
-				contractUpdate := &types.IsContractUpdate{
-					IsContract: itx.GetType() == "create",
-					// also use success status of enclosing transaction, as even successful sub-calls can still be reverted later in the tx
-					Success: itx.GetErrorMsg() == "" && tx.GetErrorMsg() == "",
-				}
-				b, err := proto.Marshal(contractUpdate)
-				if err != nil {
-					return nil, nil, err
-				}
 				address := itx.GetTo()
 				if itx.GetType() == "suicide" {
 					address = itx.GetFrom()
 				}
-
-				mutWrite := gcp_bigtable.NewMutation()
-				ts, err := encodeIsContractUpdateTs(blk.GetNumber(), uint64(i), uint64(j))
-				if err != nil {
-					utils.LogError(err, "error generating bigtable isContract timestamp", 0)
-				} else {
-					mutWrite.Set(ACCOUNT_METADATA_FAMILY, ACCOUNT_IS_CONTRACT, ts, b)
-					contractUpdateWrites.Keys = append(contractUpdateWrites.Keys, fmt.Sprintf("%s:S:%x", bigtable.chainId, address))
-					contractUpdateWrites.Muts = append(contractUpdateWrites.Muts, mutWrite)
-				}
+				updates = append(updates, blockContractUpdate{
+					address:  address,
+					txIdx:    uint64(i),
+					traceIdx: uint64(j),
+					update: &types.IsContractUpdate{
+						IsContract: itx.GetType() == "create",
+						// also use success status of enclosing transaction, as even successful sub-calls can still be reverted later in the tx
+						Success: itx.GetErrorMsg() == "" && tx.GetErrorMsg() == "",
+					},
+				})
 			}
 		}
 	}
 
-	err = bigtable.WriteBulk(contractUpdateWrites, bigtable.tableMetadata, DEFAULT_BATCH_INSERTS)
-	return bulkData, bulkMetadataUpdates, err
+	return updates, nil
 }
 
 // TransformItx extracts internal transactions from bigtable more specifically from the table blocks.
@@ -1884,27 +1928,7 @@ func (bigtable *Bigtable) TransformUncle(block *types.Eth1Block, cache *freecach
 			return nil, nil, fmt.Errorf("unexpected number of uncles in block expected at most 99 but got: %v", i)
 		}
 		iReversed := reversePaddedIndex(i, 10)
-		r := new(big.Int)
-
-		if len(block.Difficulty) > 0 {
-			r.Add(big.NewInt(int64(uncle.GetNumber())), big.NewInt(8))
-			r.Sub(r, big.NewInt(int64(block.GetNumber())))
-			r.Mul(r, utils.Eth1BlockReward(block.GetNumber(), block.Difficulty))
-			r.Div(r, big.NewInt(8))
-
-			r.Div(utils.Eth1BlockReward(block.GetNumber(), block.Difficulty), big.NewInt(32))
-		}
-
-		uncleIndexed := types.Eth1UncleIndexed{
-			Number:      uncle.GetNumber(),
-			BlockNumber: block.GetNumber(),
-			GasLimit:    uncle.GetGasLimit(),
-			GasUsed:     uncle.GetGasUsed(),
-			BaseFee:     uncle.GetBaseFee(),
-			Difficulty:  uncle.GetDifficulty(),
-			Time:        uncle.GetTime(),
-			Reward:      r.Bytes(),
-		}
+		uncleIndexed := indexedEth1Uncle(block, uncle)
 
 		bigtable.markBalanceUpdate(uncle.Coinbase, []byte{0x0}, bulkMetadataUpdates, cache)
 
@@ -1912,7 +1936,7 @@ func (bigtable *Bigtable) TransformUncle(block *types.Eth1Block, cache *freecach
 		key := fmt.Sprintf("%s:U:%s:%s", bigtable.chainId, reversedPaddedBlockNumber(block.GetNumber()), iReversed)
 		mut := gcp_bigtable.NewMutation()
 
-		b, err := proto.Marshal(&uncleIndexed)
+		b, err := proto.Marshal(uncleIndexed)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error marshalling proto object err: %w", err)
 		}
@@ -1939,6 +1963,31 @@ func (bigtable *Bigtable) TransformUncle(block *types.Eth1Block, cache *freecach
 	return bulkData, bulkMetadataUpdates, nil
 }
 
+// indexedEth1Uncle builds the indexed representation of an uncle of a block
+func indexedEth1Uncle(block *types.Eth1Block, uncle *types.Eth1Block) *types.Eth1UncleIndexed {
+	r := new(big.Int)
+
+	if len(block.Difficulty) > 0 {
+		r.Add(big.NewInt(int64(uncle.GetNumber())), big.NewInt(8))
+		r.Sub(r, big.NewInt(int64(block.GetNumber())))
+		r.Mul(r, utils.Eth1BlockReward(block.GetNumber(), block.Difficulty))
+		r.Div(r, big.NewInt(8))
+
+		r.Div(utils.Eth1BlockReward(block.GetNumber(), block.Difficulty), big.NewInt(32))
+	}
+
+	return &types.Eth1UncleIndexed{
+		Number:      uncle.GetNumber(),
+		BlockNumber: block.GetNumber(),
+		GasLimit:    uncle.GetGasLimit(),
+		GasUsed:     uncle.GetGasUsed(),
+		BaseFee:     uncle.GetBaseFee(),
+		Difficulty:  uncle.GetDifficulty(),
+		Time:        uncle.GetTime(),
+		Reward:      r.Bytes(),
+	}
+}
+
 // TransformWithdrawals accepts an eth1 block and creates bigtable mutations.
 // It transforms the withdrawals contained within a block, extracts the necessary information to create a view and writes that information to bigtable
 // It writes uncles to table data:
@@ -2302,7 +2351,7 @@ func (bigtable *Bigtable) GetAddressTransactionsTableData(address []byte, pageTo
 		return nil, fmt.Errorf("invalid pageToken for function GetAddressTransactionsTableData: %s", pageToken)
 	}
 
-	transactions, keys, err := BigtableClient.GetEth1TxsForAddress(pageToken, DefaultInfScrollRows)
+	transactions, keys, err := bigtable.GetEth1TxsForAddress(pageToken, DefaultInfScrollRows)
 	if err != nil {
 		return nil, err
 	}
@@ -2321,7 +2370,27 @@ func (bigtable *Bigtable) GetAddressTransactionsTableData(address []byte, pageTo
 		idxs[i] = int64(tx_idx)
 	}
 
-	contractInteractionTypes, err := BigtableClient.GetAddressContractInteractionsAtTransactions(transactions, idxs)
+	tableData, err := addressTransactionsTableRows(bigtable, address, transactions, idxs)
+	if err != nil {
+		return nil, err
+	}
+
+	token := ""
+	if len(keys) > 0 {
+		token = skipBlockIfLastTxIndex(keys[len(keys)-1])
+	}
+
+	data := &types.DataTableResponse{
+		Data:        tableData,
+		PagingToken: token,
+	}
+
+	return data, nil
+}
+
+// addressTransactionsTableRows formats the transactions of an address for the transactions table of the address page
+func addressTransactionsTableRows(s Storage, address []byte, transactions []*types.Eth1TransactionIndexed, idxs []int64) ([][]interface{}, error) {
+	contractInteractionTypes, err := s.GetAddressContractInteractionsAtTransactions(transactions, idxs)
 	if err != nil {
 		utils.LogError(err, "error getting contract states", 0)
 	}
@@ -2332,7 +2401,7 @@ func (bigtable *Bigtable) GetAddressTransactionsTableData(address []byte, pageTo
 		names[string(t.From)] = ""
 		names[string(t.To)] = ""
 	}
-	names, _, err = BigtableClient.GetAddressesNamesArMetadata(&names, nil)
+	names, _, err = s.GetAddressesNamesArMetadata(&names, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -2347,27 +2416,17 @@ func (bigtable *Bigtable) GetAddressTransactionsTableData(address []byte, pageTo
 
 		tableData[i] = []interface{}{
 			utils.FormatTransactionHash(t.Hash, t.ErrorMsg == ""),
-			utils.FormatMethod(bigtable.GetMethodLabel(t.MethodId, contractInteraction)),
+			utils.FormatMethod(s.GetMethodLabel(t.MethodId, contractInteraction)),
 			utils.FormatBlockNumber(t.BlockNumber),
 			utils.FormatTimestamp(t.Time.AsTime().Unix()),
 			utils.FormatAddressWithLimitsInAddressPageTable(address, t.From, fromName, false, digitLimitInAddressPagesTable, nameLimitInAddressPagesTable, true),
 			utils.FormatInOutSelf(address, t.From, t.To),
-			utils.FormatAddressWithLimitsInAddressPageTable(address, t.To, BigtableClient.GetAddressLabel(names[string(t.To)], contractInteraction), contractInteraction != types.CONTRACT_NONE, digitLimitInAddressPagesTable, nameLimitInAddressPagesTable, true),
+			utils.FormatAddressWithLimitsInAddressPageTable(address, t.To, s.GetAddressLabel(names[string(t.To)], contractInteraction), contractInteraction != types.CONTRACT_NONE, digitLimitInAddressPagesTable, nameLimitInAddressPagesTable, true),
 			utils.FormatAmount(new(big.Int).SetBytes(t.Value), utils.Config.Frontend.ElCurrency, 6),
 		}
 	}
 
-	token := ""
-	if len(keys) > 0 {
-		token = skipBlockIfLastTxIndex(keys[len(keys)-1])
-	}
-
-	data := &types.DataTableResponse{
-		Data:        tableData,
-		PagingToken: token,
-	}
-
-	return data, nil
+	return tableData, nil
 }
 
 func (bigtable *Bigtable) GetEth1BlocksForAddress(prefix string, limit int64) ([]*types.Eth1BlockIndexed, string, error) {
@@ -2445,11 +2504,21 @@ func (bigtable *Bigtable) GetAddressBlocksMinedTableData(address string, pageTok
 		return nil, fmt.Errorf("invalid pageToken for function GetAddressBlocksMinedTableData: %s", pageToken)
 	}
 
-	blocks, lastKey, err := BigtableClient.GetEth1BlocksForAddress(pageToken, DefaultInfScrollRows)
+	blocks, lastKey, err := bigtable.GetEth1BlocksForAddress(pageToken, DefaultInfScrollRows)
 	if err != nil {
 		return nil, err
 	}
 
+	data := &types.DataTableResponse{
+		Data:        blocksMinedTableRows(blocks),
+		PagingToken: lastKey,
+	}
+
+	return data, nil
+}
+
+// blocksMinedTableRows formats the blocks of a fee recipient for the blocks table of the address page
+func blocksMinedTableRows(blocks []*types.Eth1BlockIndexed) [][]interface{} {
 	tableData := make([][]interface{}, len(blocks))
 	for i, b := range blocks {
 		reward := new(big.Int).Add(utils.Eth1BlockReward(b.Number, b.Difficulty), new(big.Int).SetBytes(b.TxReward))
@@ -2461,13 +2530,7 @@ func (bigtable *Bigtable) GetAddressBlocksMinedTableData(address string, pageTok
 			utils.FormatAmount(reward, utils.Config.Frontend.ElCurrency, 6),
 		}
 	}
-
-	data := &types.DataTableResponse{
-		Data:        tableData,
-		PagingToken: lastKey,
-	}
-
-	return data, nil
+	return tableData
 }
 
 func (bigtable *Bigtable) GetEth1UnclesForAddress(prefix string, limit int64) ([]*types.Eth1UncleIndexed, string, error) {
@@ -2545,11 +2608,21 @@ func (bigtable *Bigtable) GetAddressUnclesMinedTableData(address string, pageTok
 		return nil, fmt.Errorf("invalid pageToken for function GetAddressUnclesMinedTableData: %s", pageToken)
 	}
 
-	uncles, lastKey, err := BigtableClient.GetEth1UnclesForAddress(pageToken, DefaultInfScrollRows)
+	uncles, lastKey, err := bigtable.GetEth1UnclesForAddress(pageToken, DefaultInfScrollRows)
 	if err != nil {
 		return nil, err
 	}
 
+	data := &types.DataTableResponse{
+		Data:        unclesMinedTableRows(uncles),
+		PagingToken: lastKey,
+	}
+
+	return data, nil
+}
+
+// unclesMinedTableRows formats the uncles of a miner for the uncles table of the address page
+func unclesMinedTableRows(uncles []*types.Eth1UncleIndexed) [][]interface{} {
 	tableData := make([][]interface{}, len(uncles))
 	for i, u := range uncles {
 		tableData[i] = []interface{}{
@@ -2559,13 +2632,7 @@ func (bigtable *Bigtable) GetAddressUnclesMinedTableData(address string, pageTok
 			utils.FormatAmount(new(big.Int).SetBytes(u.Reward), utils.Config.Frontend.ElCurrency, 6),
 		}
 	}
-
-	data := &types.DataTableResponse{
-		Data:        tableData,
-		PagingToken: lastKey,
-	}
-
-	return data, nil
+	return tableData
 }
 
 func (bigtable *Bigtable) GetEth1BtxForAddress(prefix string, limit int64) ([]*types.Eth1BlobTransactionIndexed, string, error) {
@@ -2648,12 +2715,27 @@ func (bigtable *Bigtable) GetAddressBlobTableData(address []byte, pageToken stri
 		return nil, err
 	}
 
+	tableData, err := addressBlobTableRows(bigtable, address, transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &types.DataTableResponse{
+		Data:        tableData,
+		PagingToken: lastKey,
+	}
+
+	return data, nil
+}
+
+// addressBlobTableRows formats the blob transactions of an address for the blob transactions table of the address page
+func addressBlobTableRows(s Storage, address []byte, transactions []*types.Eth1BlobTransactionIndexed) ([][]interface{}, error) {
 	names := make(map[string]string)
 	for _, t := range transactions {
 		names[string(t.From)] = ""
 		names[string(t.To)] = ""
 	}
-	names, _, err = BigtableClient.GetAddressesNamesArMetadata(&names, nil)
+	names, _, err := s.GetAddressesNamesArMetadata(&names, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -2676,13 +2758,7 @@ func (bigtable *Bigtable) GetAddressBlobTableData(address []byte, pageToken stri
 			len(t.BlobVersionedHashes),
 		}
 	}
-
-	data := &types.DataTableResponse{
-		Data:        tableData,
-		PagingToken: lastKey,
-	}
-
-	return data, nil
+	return tableData, nil
 }
 
 func (bigtable *Bigtable) GetEth1ItxsForAddress(prefix string, limit int64) ([]*types.Eth1InternalTransactionIndexed, []string, error) {
@@ -2863,7 +2939,12 @@ func (bigtable *Bigtable) GetInternalTransfersForTransaction(transaction []byte,
 		return nil, err
 	}
 
-	contractInteractionTypes, err := BigtableClient.GetAddressContractInteractionsAtParityTraces(parityTrace)
+	return internalTransfersFromParityTrace(bigtable, names, parityTrace, currency)
+}
+
+// internalTransfersFromParityTrace formats the internal transfers of a transaction, names has to contain the names of the involved addresses
+func internalTransfersFromParityTrace(s Storage, names map[string]string, parityTrace []*rpc.ParityTraceResult, currency string) ([]types.ITransaction, error) {
+	contractInteractionTypes, err := s.GetAddressContractInteractionsAtParityTraces(parityTrace)
 	if err != nil {
 		utils.LogError(err, "error getting contract states", 0)
 	}
@@ -2889,14 +2970,14 @@ func (bigtable *Bigtable) GetInternalTransfersForTransaction(transaction []byte,
 			to_contractInteraction = contractInteractionTypes[i][1]
 		}
 
-		fromName := BigtableClient.GetAddressLabel(names[string(from)], from_contractInteraction)
-		toName := BigtableClient.GetAddressLabel(names[string(to)], to_contractInteraction)
+		fromName := s.GetAddressLabel(names[string(from)], from_contractInteraction)
+		toName := s.GetAddressLabel(names[string(to)], to_contractInteraction)
 
 		itx := types.ITransaction{
 			From:      utils.FormatAddress(from, nil, fromName, false, from_contractInteraction != types.CONTRACT_NONE, true),
 			To:        utils.FormatAddress(to, nil, toName, false, to_contractInteraction != types.CONTRACT_NONE, true),
 			Amount:    utils.FormatElCurrency(value, currency, 8, true, false, false, true),
-			TracePath: utils.FormatTracePath(tx_type, parityTrace[i].TraceAddress, parityTrace[i].Error == "", bigtable.GetMethodLabel(input, from_contractInteraction)),
+			TracePath: utils.FormatTracePath(tx_type, parityTrace[i].TraceAddress, parityTrace[i].Error == "", s.GetMethodLabel(input, from_contractInteraction)),
 			Advanced:  tx_type == "delegatecall" || string(value) == "\x00",
 		}
 
@@ -3634,6 +3715,38 @@ func (bigtable *Bigtable) GetBalanceForAddress(address []byte, token []byte) (*t
 	return nil, fmt.Errorf("ACCOUNT_METADATA_FAMILY is not a valid index in row map")
 }
 
+// erc20MetadataViaRpc retrieves the metadata of a token from the node and caches it under cacheKey
+func erc20MetadataViaRpc(address []byte, cacheKey string) (*types.ERC20Metadata, error) {
+	logger.Infof("retrieving metadata for token %x via rpc", address)
+
+	metadata, err := rpc.CurrentGethClient.GetERC20TokenMetadata(address)
+	if err != nil {
+		logger.Warnf("error retrieving metadata for token %x: %v", address, err)
+		metadata = &types.ERC20Metadata{
+			Decimals:    []byte{0x0},
+			Symbol:      "UNKNOWN",
+			TotalSupply: []byte{0x0}}
+
+		err = cache.TieredCache.Set(cacheKey, metadata, time.Minute*10)
+		if err != nil {
+			return nil, err
+		}
+		return metadata, nil
+	}
+
+	// err = bigtable.SaveERC20Metadata(address, metadata)
+	// if err != nil {
+	// 	return nil, err
+	// }
+
+	err = cache.TieredCache.Set(cacheKey, metadata, time.Hour*1)
+	if err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
 func (bigtable *Bigtable) GetERC20MetadataForAddress(address []byte) (*types.ERC20Metadata, error) {
 
 	tmr := time.AfterFunc(REPORT_TIMEOUT, func() {
@@ -3671,34 +3784,7 @@ func (bigtable *Bigtable) GetERC20MetadataForAddress(address []byte) (*types.ERC
 	// }
 
 	if row == nil { // Retrieve token metadata from Ethplorer and store it for later usage
-		logger.Infof("retrieving metadata for token %x via rpc", address)
-
-		metadata, err := rpc.CurrentGethClient.GetERC20TokenMetadata(address)
-		if err != nil {
-			logger.Warnf("error retrieving metadata for token %x: %v", address, err)
-			metadata = &types.ERC20Metadata{
-				Decimals:    []byte{0x0},
-				Symbol:      "UNKNOWN",
-				TotalSupply: []byte{0x0}}
-
-			err = cache.TieredCache.Set(cacheKey, metadata, time.Minute*10)
-			if err != nil {
-				return nil, err
-			}
-			return metadata, nil
-		}
-
-		// err = bigtable.SaveERC20Metadata(address, metadata)
-		// if err != nil {
-		// 	return nil, err
-		// }
-
-		err = cache.TieredCache.Set(cacheKey, metadata, time.Hour*1)
-		if err != nil {
-			return nil, err
-		}
-
-		return metadata, nil
+		return erc20MetadataViaRpc(address, cacheKey)
 	}
 
 	// logger.Infof("retrieving metadata for token %x via bigtable", address)
@@ -3868,6 +3954,11 @@ type contractInteractionAtRequest struct {
 	traceIdx int64
 }
 
+// contractInteractionReader is implemented by the storages that keep the is contract histories of addresses
+type contractInteractionReader interface {
+	GetAddressContractInteractionsAt(requests []contractInteractionAtRequest) ([]types.ContractInteractionType, error)
+}
+
 func (bigtable *Bigtable) getAddressIsContractHistories(histories map[string][]isContractInfo) error {
 	if len(histories) == 0 {
 		return nil
@@ -3929,6 +4020,14 @@ func (bigtable *Bigtable) GetAddressContractInteractionsAt(requests []contractIn
 		return nil, err
 	}
 
+	return contractInteractionsFromHistories(requests, histories)
+}
+
+// contractInteractionsFromHistories evaluates the requests against the is contract histories of the requested addresses
+// the updates of every history have to be sorted by their timestamp in descending order
+func contractInteractionsFromHistories(requests []contractInteractionAtRequest, histories map[string][]isContractInfo) ([]types.ContractInteractionType, error) {
+	results := make([]types.ContractInteractionType, len(requests))
+
 	// evaluate requests; CONTRACT_NONE is default
 	for i, request := range requests {
 		history, ok := histories[request.address]
@@ -3986,6 +4085,10 @@ func (bigtable *Bigtable) GetAddressContractInteractionsAt(requests []contractIn
 
 // convenience function to get contract interaction status per transaction of a block
 func (bigtable *Bigtable) GetAddressContractInteractionsAtBlock(block *types.Eth1Block) ([]types.ContractInteractionType, error) {
+	return contractInteractionsAtBlock(bigtable, block)
+}
+
+func contractInteractionsAtBlock(r contractInteractionReader, block *types.Eth1Block) ([]types.ContractInteractionType, error) {
 	requests := make([]contractInteractionAtRequest, len(block.GetTransactions()))
 	for i, tx := range block.GetTransactions() {
 		address := tx.GetTo()
@@ -4000,12 +4103,16 @@ func (bigtable *Bigtable) GetAddressContractInteractionsAtBlock(block *types.Eth
 		}
 	}
 
-	return bigtable.GetAddressContractInteractionsAt(requests)
+	return r.GetAddressContractInteractionsAt(requests)
 }
 
 // convenience function to get contract interaction status per subtransaction of a transaction
 // 2nd parameter specifies [tx_idx, trace_idx] for each internal tx
 func (bigtable *Bigtable) GetAddressContractInteractionsAtITransactions(itransactions []*types.Eth1InternalTransactionIndexed, idxs [][2]int64) ([][2]types.ContractInteractionType, error) {
+	return contractInteractionsAtITransactions(bigtable, itransactions, idxs)
+}
+
+func contractInteractionsAtITransactions(r contractInteractionReader, itransactions []*types.Eth1InternalTransactionIndexed, idxs [][2]int64) ([][2]types.ContractInteractionType, error) {
 	requests := make([]contractInteractionAtRequest, 0, len(itransactions)*2)
 	for i, tx := range itransactions {
 		requests = append(requests, contractInteractionAtRequest{
@@ -4021,7 +4128,7 @@ func (bigtable *Bigtable) GetAddressContractInteractionsAtITransactions(itransac
 			traceIdx: idxs[i][1],
 		})
 	}
-	results, err := bigtable.GetAddressContractInteractionsAt(requests)
+	results, err := r.GetAddressContractInteractionsAt(requests)
 	if err != nil {
 		return nil, err
 	}
@@ -4035,6 +4142,10 @@ func (bigtable *Bigtable) GetAddressContractInteractionsAtITransactions(itransac
 
 // convenience function to get contract interaction status per parity trace
 func (bigtable *Bigtable) GetAddressContractInteractionsAtParityTraces(traces []*rpc.ParityTraceResult) ([][2]types.ContractInteractionType, error) {
+	return contractInteractionsAtParityTraces(bigtable, traces)
+}
+
+func contractInteractionsAtParityTraces(r contractInteractionReader, traces []*rpc.ParityTraceResult) ([][2]types.ContractInteractionType, error) {
 	requests := make([]contractInteractionAtRequest, 0, len(traces)*2)
 	for i, itx := range traces {
 		from, to, _, _ := itx.ConvertFields()
@@ -4051,7 +4162,7 @@ func (bigtable *Bigtable) GetAddressContractInteractionsAtParityTraces(traces []
 			traceIdx: int64(i),
 		})
 	}
-	results, err := bigtable.GetAddressContractInteractionsAt(requests)
+	results, err := r.GetAddressContractInteractionsAt(requests)
 	if err != nil {
 		return nil, err
 	}
@@ -4065,6 +4176,10 @@ func (bigtable *Bigtable) GetAddressContractInteractionsAtParityTraces(traces []
 
 // convenience function to get contract interaction status per transaction
 func (bigtable *Bigtable) GetAddressContractInteractionsAtTransactions(transactions []*types.Eth1TransactionIndexed, idxs []int64) ([]types.ContractInteractionType, error) {
+	return contractInteractionsAtTransactions(bigtable, transactions, idxs)
+}
+
+func contractInteractionsAtTransactions(r contractInteractionReader, transactions []*types.Eth1TransactionIndexed, idxs []int64) ([]types.ContractInteractionType, error) {
 	requests := make([]contractInteractionAtRequest, len(transactions))
 	for i, tx := range transactions {
 		requests[i] = contractInteractionAtRequest{
@@ -4074,7 +4189,7 @@ func (bigtable *Bigtable) GetAddressContractInteractionsAtTransactions(transacti
 			traceIdx: -1,
 		}
 	}
-	return bigtable.GetAddressContractInteractionsAt(requests)
+	return r.GetAddressContractInteractionsAt(requests)
 }
 
 func (bigtable *Bigtable) SaveAddressName(address []byte, name string) error {
@@ -4474,7 +4589,7 @@ func (bigtable *Bigtable) GetTokenTransactionsTableData(token []byte, address []
 		}
 	}
 
-	transactions, lastKey, err := BigtableClient.GetEth1TxForToken(pageToken, DefaultInfScrollRows)
+	transactions, lastKey, err := bigtable.GetEth1TxForToken(pageToken, DefaultInfScrollRows)
 	if err != nil {
 		return nil, err
 	}
@@ -4696,6 +4811,15 @@ func (bigtable *Bigtable) GetSignature(hex string, st types.SignatureType) (*str
 
 // get a method label for its byte signature with defaults
 func (bigtable *Bigtable) GetMethodLabel(data []byte, interaction types.ContractInteractionType) string {
+	return methodLabel(bigtable, data, interaction)
+}
+
+// signatureReader is implemented by the storages that keep the imported method and event signatures
+type signatureReader interface {
+	GetSignature(hex string, st types.SignatureType) (*string, error)
+}
+
+func methodLabel(r signatureReader, data []byte, interaction types.ContractInteractionType) string {
 	id := data
 	if len(data) > 3 {
 		id = data[:4]
@@ -4713,7 +4837,7 @@ func (bigtable *Bigtable) GetMethodLabel(data []byte, interaction types.Contract
 		if len(id) == 4 {
 			cacheKey := fmt.Sprintf("M:H2L:%s", method)
 			if _, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, time.Hour, &method); err != nil {
-				if sig, err := bigtable.GetSignature(method, types.MethodSignature); err == nil {
+				if sig, err := r.GetSignature(method, types.MethodSignature); err == nil {
 					if sig != nil {
 						method = utils.RemoveRoundBracketsIncludingContent(*sig)
 					}
@@ -4729,6 +4853,10 @@ func (bigtable *Bigtable) GetMethodLabel(data []byte, interaction types.Contract
 
 // get a method label for its byte signature with defaults
 func (bigtable *Bigtable) GetAddressLabel(id string, invoke_overwrite types.ContractInteractionType) string {
+	return addressLabel(id, invoke_overwrite)
+}
+
+func addressLabel(id string, invoke_overwrite types.ContractInteractionType) string {
 	switch invoke_overwrite {
 	case types.CONTRACT_CREATION:
 		return "Contract Creation"
@@ -4741,12 +4869,16 @@ func (bigtable *Bigtable) GetAddressLabel(id string, invoke_overwrite types.Cont
 
 // get an event label for its byte signature with defaults
 func (bigtable *Bigtable) GetEventLabel(id []byte) string {
+	return eventLabel(bigtable, id)
+}
+
+func eventLabel(r signatureReader, id []byte) string {
 	label := ""
 	if len(id) > 0 {
 		event := fmt.Sprintf("0x%x", id)
 		cacheKey := fmt.Sprintf("E:H2L:%s", event)
 		if _, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, time.Hour, &label); err != nil {
-			sig, err := bigtable.GetSignature(event, types.EventSignature)
+			sig, err := r.GetSignature(event, types.EventSignature)
 			if err == nil {
 				if sig != nil {
 					label = *sig
@@ -10,8 +10,8 @@ import (
 	gcp_bigtable "cloud.google.com/go/bigtable"
 )
 
-func InitBigtableSchema() error {
-
+// bigtableSchema returns the column families and their gc policies of all tables
+func bigtableSchema() map[string]map[string]gcp_bigtable.GCPolicy {
 	tables := make(map[string]map[string]gcp_bigtable.GCPolicy)
 
 	tables["beaconchain_validators"] = map[string]gcp_bigtable.GCPolicy{
@@ -48,6 +48,10 @@ func InitBigtableSchema() error {
 		METADATA_UPDATES_FAMILY_BLOCKS: gcp_bigtable.MaxAgeGCPolicy(utils.Day),
 		DEFAULT_FAMILY:                 nil,
 	}
+	return tables
+}
+
+func InitBigtableSchema() error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
 
@@ -55,6 +59,7 @@ func InitBigtableSchema() error {
 	if err != nil {
 		return err
 	}
+	defer admin.Close()
 
 	existingTables, err := admin.Tables(ctx)
 	if err != nil {
@@ -65,7 +70,11 @@ func InitBigtableSchema() error {
 		return fmt.Errorf("aborting bigtable schema init as tables are already present")
 	}
 
-	for name, definition := range tables {
+	return createBigtableSchema(ctx, admin)
+}
+
+func createBigtableSchema(ctx context.Context, admin *gcp_bigtable.AdminClient) error {
+	for name, definition := range bigtableSchema() {
 		err := admin.CreateTable(ctx, name)
 		if err != nil {
 			return err
@@ -0,0 +1,321 @@
+package db
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+)
+
+// V1V2MigrationFamilies are the validator history families that can be migrated from the v1 to the v2 schema
+var V1V2MigrationFamilies = []string{VALIDATOR_BALANCES_FAMILY, ATTESTATIONS_FAMILY, PROPOSALS_FAMILY, SYNC_COMMITTEES_FAMILY}
+
+// MigrateValidatorHistoryV1V2Schema copies the data of a validator history family for a single epoch from the v1 to the v2 schema.
+// It returns the indices of all validators that have v1 data in that epoch, if dryRun is set the data is read but not written.
+func (bigtable *Bigtable) MigrateValidatorHistoryV1V2Schema(family string, epoch uint64, dryRun bool) ([]uint64, error) {
+	validators := make(map[uint64]bool)
+
+	switch family {
+	case VALIDATOR_BALANCES_FAMILY:
+		balances := []*types.Validator{}
+		err := bigtable.readValidatorHistoryV1(family, epoch, func(_ uint64, validator uint64, ri gcp_bigtable.ReadItem) error {
+			if len(ri.Value) != 16 {
+				return fmt.Errorf("unexpected balance encoding of length %v for validator %v", len(ri.Value), validator)
+			}
+			validators[validator] = true
+			balances = append(balances, &types.Validator{
+				Index:            validator,
+				Balance:          binary.LittleEndian.Uint64(ri.Value[0:8]),
+				EffectiveBalance: binary.LittleEndian.Uint64(ri.Value[8:16]),
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !dryRun && len(balances) > 0 {
+			err = bigtable.SaveValidatorBalances(epoch, balances)
+			if err != nil {
+				return nil, fmt.Errorf("error saving validator balances of epoch %v: %w", epoch, err)
+			}
+		}
+	case ATTESTATIONS_FAMILY:
+		duties := make(map[types.Slot]map[types.ValidatorIndex][]types.Slot)
+		err := bigtable.readValidatorHistoryV1(family, epoch, func(slot uint64, validator uint64, ri gcp_bigtable.ReadItem) error {
+			validators[validator] = true
+			if duties[types.Slot(slot)] == nil {
+				duties[types.Slot(slot)] = make(map[types.ValidatorIndex][]types.Slot)
+			}
+			inclusions := duties[types.Slot(slot)][types.ValidatorIndex(validator)]
+			if inclusions == nil {
+				inclusions = []types.Slot{}
+			}
+			// missed attestations are stored with a cell ts of 0 and written as an empty inclusion list
+			if inclusionSlot := max_block_number_v1 - uint64(ri.Timestamp)/1000; inclusionSlot != max_block_number_v1 {
+				inclusions = append(inclusions, types.Slot(inclusionSlot))
+			}
+			duties[types.Slot(slot)][types.ValidatorIndex(validator)] = inclusions
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !dryRun && len(duties) > 0 {
+			err = bigtable.SaveAttestationDuties(duties)
+			if err != nil {
+				return nil, fmt.Errorf("error saving attestation duties of epoch %v: %w", epoch, err)
+			}
+		}
+	case PROPOSALS_FAMILY:
+		assignments := make(map[uint64]uint64)
+		muts := types.NewBulkMutations(int(utils.Config.Chain.ClConfig.SlotsPerEpoch))
+		err := bigtable.readValidatorHistoryV1(family, epoch, func(slot uint64, validator uint64, ri gcp_bigtable.ReadItem) error {
+			validators[validator] = true
+			assignments[slot] = validator
+			if inclusionSlot := max_block_number_v1 - uint64(ri.Timestamp)/1000; inclusionSlot != max_block_number_v1 {
+				mut := gcp_bigtable.NewMutation()
+				mut.Set(PROPOSALS_FAMILY, "b", gcp_bigtable.Timestamp((MAX_CL_BLOCK_NUMBER-slot)*1000), []byte{})
+				muts.Add(fmt.Sprintf("%s:%s:%s:%s:%s", bigtable.chainId, bigtable.validatorIndexToKey(validator), PROPOSALS_FAMILY, bigtable.reversedPaddedEpoch(epoch), bigtable.reversedPaddedSlot(slot)), mut)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !dryRun && len(assignments) > 0 {
+			err = bigtable.SaveProposalAssignments(epoch, assignments)
+			if err != nil {
+				return nil, fmt.Errorf("error saving proposal assignments of epoch %v: %w", epoch, err)
+			}
+			err = bigtable.WriteBulk(muts, bigtable.tableValidatorsHistory, MAX_BATCH_MUTATIONS)
+			if err != nil {
+				return nil, fmt.Errorf("error saving proposals of epoch %v: %w", epoch, err)
+			}
+		}
+	case SYNC_COMMITTEES_FAMILY:
+		duties := make(map[types.Slot]map[types.ValidatorIndex]bool)
+		err := bigtable.readValidatorHistoryV1(family, epoch, func(slot uint64, validator uint64, ri gcp_bigtable.ReadItem) error {
+			validators[validator] = true
+			if duties[types.Slot(slot)] == nil {
+				duties[types.Slot(slot)] = make(map[types.ValidatorIndex]bool)
+			}
+			duties[types.Slot(slot)][types.ValidatorIndex(validator)] = max_block_number_v1-uint64(ri.Timestamp)/1000 != max_block_number_v1
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !dryRun && len(duties) > 0 {
+			err = bigtable.SaveSyncComitteeDuties(duties)
+			if err != nil {
+				return nil, fmt.Errorf("error saving sync committee duties of epoch %v: %w", epoch, err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported family %v for v1 to v2 migration", family)
+	}
+
+	res := make([]uint64, 0, len(validators))
+	for validator := range validators {
+		res = append(res, validator)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res, nil
+}
+
+// readValidatorHistoryV1 calls handle for every v1 cell of a family in an epoch, key is the epoch for balances and the slot for all other families
+func (bigtable *Bigtable) readValidatorHistoryV1(family string, epoch uint64, handle func(key uint64, validator uint64, ri gcp_bigtable.ReadItem) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+	defer cancel()
+
+	ranges := bigtable.getSlotRangesForEpochV1(epoch, epoch)
+	keyIndex := 4
+	maxKey := uint64(max_block_number_v1)
+	if family == VALIDATOR_BALANCES_FAMILY {
+		ranges = bigtable.getEpochRangesV1(epoch, epoch)
+		keyIndex = 3
+		maxKey = max_epoch_v1
+	}
+
+	// attestations keep all versions as a validator can be included multiple times
+	filter := gcp_bigtable.ChainFilters(gcp_bigtable.FamilyFilter(family), gcp_bigtable.LatestNFilter(1))
+	if family == ATTESTATIONS_FAMILY {
+		filter = gcp_bigtable.FamilyFilter(family)
+	}
+
+	var handleErr error
+	err := bigtable.tableBeaconchain.ReadRows(ctx, ranges, func(r gcp_bigtable.Row) bool {
+		keySplit := strings.Split(r.Key(), ":")
+		if len(keySplit) <= keyIndex {
+			handleErr = fmt.Errorf("unexpected row key %v", r.Key())
+			return false
+		}
+		key, err := strconv.ParseUint(keySplit[keyIndex], 10, 64)
+		if err != nil {
+			handleErr = fmt.Errorf("error parsing row key %v: %w", r.Key(), err)
+			return false
+		}
+		key = maxKey - key
+
+		for _, ri := range r[family] {
+			validator, err := strconv.ParseUint(strings.TrimPrefix(ri.Column, family+":"), 10, 64)
+			if err != nil {
+				handleErr = fmt.Errorf("error parsing validator from column key %v: %w", ri.Column, err)
+				return false
+			}
+			if err := handle(key, validator, ri); err != nil {
+				handleErr = err
+				return false
+			}
+		}
+		return true
+	}, gcp_bigtable.RowFilter(filter))
+	if err != nil {
+		return fmt.Errorf("error reading v1 %v data of epoch %v: %w", family, epoch, err)
+	}
+	return handleErr
+}
+
+// VerifyValidatorHistoryV1V2Schema reads the data of a validator history family for an epoch range through the v1 and v2 code paths
+// and returns a description of every difference between them
+func (bigtable *Bigtable) VerifyValidatorHistoryV1V2Schema(family string, validators []uint64, startEpoch uint64, endEpoch uint64) ([]string, error) {
+	if len(validators) == 0 {
+		return []string{}, nil
+	}
+
+	var v1, v2 map[uint64]map[uint64]string
+	var unit string
+
+	switch family {
+	case VALIDATOR_BALANCES_FAMILY:
+		unit = "epoch"
+		normalize := func(data map[uint64][]*types.ValidatorBalance) map[uint64]map[uint64]string {
+			res := make(map[uint64]map[uint64]string, len(data))
+			for validator, balances := range data {
+				res[validator] = make(map[uint64]string, len(balances))
+				for _, b := range balances {
+					res[validator][b.Epoch] = fmt.Sprintf("balance=%d effective=%d", b.Balance, b.EffectiveBalance)
+				}
+			}
+			return res
+		}
+		data, err := bigtable.getValidatorBalanceHistoryV1(validators, startEpoch, endEpoch)
+		if err != nil {
+			return nil, err
+		}
+		v1 = normalize(data)
+		data, err = bigtable.getValidatorBalanceHistoryV2(validators, startEpoch, endEpoch)
+		if err != nil {
+			return nil, err
+		}
+		v2 = normalize(data)
+	case ATTESTATIONS_FAMILY:
+		unit = "slot"
+		normalize := func(data map[uint64][]*types.ValidatorAttestation) map[uint64]map[uint64]string {
+			res := make(map[uint64]map[uint64]string, len(data))
+			for validator, attestations := range data {
+				res[validator] = make(map[uint64]string, len(attestations))
+				for _, a := range attestations {
+					res[validator][a.AttesterSlot] = fmt.Sprintf("status=%d inclusion=%d", a.Status, a.InclusionSlot)
+				}
+			}
+			return res
+		}
+		data, err := bigtable.getValidatorAttestationHistoryV1(validators, startEpoch, endEpoch)
+		if err != nil {
+			return nil, err
+		}
+		v1 = normalize(data)
+		data, err = bigtable.getValidatorAttestationHistoryV2(validators, startEpoch, endEpoch)
+		if err != nil {
+			return nil, err
+		}
+		v2 = normalize(data)
+	case PROPOSALS_FAMILY:
+		unit = "slot"
+		normalize := func(data map[uint64][]*types.ValidatorProposal) map[uint64]map[uint64]string {
+			res := make(map[uint64]map[uint64]string, len(data))
+			for validator, proposals := range data {
+				res[validator] = make(map[uint64]string, len(proposals))
+				for _, p := range proposals {
+					res[validator][p.Slot] = fmt.Sprintf("status=%d", p.Status)
+				}
+			}
+			return res
+		}
+		data, err := bigtable.getValidatorProposalHistoryV1(validators, startEpoch, endEpoch)
+		if err != nil {
+			return nil, err
+		}
+		v1 = normalize(data)
+		data, err = bigtable.getValidatorProposalHistoryV2(validators, startEpoch, endEpoch)
+		if err != nil {
+			return nil, err
+		}
+		v2 = normalize(data)
+	case SYNC_COMMITTEES_FAMILY:
+		unit = "slot"
+		normalize := func(data map[uint64]map[uint64]*types.ValidatorSyncParticipation) map[uint64]map[uint64]string {
+			res := make(map[uint64]map[uint64]string, len(data))
+			for validator, participations := range data {
+				res[validator] = make(map[uint64]string, len(participations))
+				for slot, p := range participations {
+					res[validator][slot] = fmt.Sprintf("status=%d", p.Status)
+				}
+			}
+			return res
+		}
+		startSlot := startEpoch * utils.Config.Chain.ClConfig.SlotsPerEpoch
+		endSlot := (endEpoch+1)*utils.Config.Chain.ClConfig.SlotsPerEpoch - 1
+		data, err := bigtable.getValidatorSyncDutiesHistoryV1(validators, startSlot, endSlot)
+		if err != nil {
+			return nil, err
+		}
+		v1 = normalize(data)
+		data, err = bigtable.getValidatorSyncDutiesHistoryV2(validators, startSlot, endSlot)
+		if err != nil {
+			return nil, err
+		}
+		v2 = normalize(data)
+	default:
+		return nil, fmt.Errorf("unsupported family %v for v1 to v2 verification", family)
+	}
+
+	mismatches := []string{}
+	for _, validator := range validators {
+		keys := make(map[uint64]bool, len(v1[validator]))
+		for key := range v1[validator] {
+			keys[key] = true
+		}
+		for key := range v2[validator] {
+			keys[key] = true
+		}
+		sortedKeys := make([]uint64, 0, len(keys))
+		for key := range keys {
+			sortedKeys = append(sortedKeys, key)
+		}
+		sort.Slice(sortedKeys, func(i, j int) bool { return sortedKeys[i] < sortedKeys[j] })
+
+		for _, key := range sortedKeys {
+			v1Value, v1Found := v1[validator][key]
+			v2Value, v2Found := v2[validator][key]
+			switch {
+			case !v2Found:
+				mismatches = append(mismatches, fmt.Sprintf("%s validator %d %s %d: missing in v2, v1 has %s", family, validator, unit, key, v1Value))
+			case !v1Found:
+				mismatches = append(mismatches, fmt.Sprintf("%s validator %d %s %d: missing in v1, v2 has %s", family, validator, unit, key, v2Value))
+			case v1Value != v2Value:
+				mismatches = append(mismatches, fmt.Sprintf("%s validator %d %s %d: v1 has %s, v2 has %s", family, validator, unit, key, v1Value, v2Value))
+			}
+		}
+	}
+	return mismatches, nil
+}
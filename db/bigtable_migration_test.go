@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db/dbtest"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+)
+
+func TestMigrateValidatorHistoryV1V2Schema(t *testing.T) {
+	prevConfig, prevReader := utils.Config, ReaderDb
+	defer func() { utils.Config, ReaderDb = prevConfig, prevReader }()
+	utils.Config = &types.Config{}
+	utils.Config.Chain.ClConfig.SlotsPerEpoch = 32
+	// the v1 attestation history looks up orphaned slots, there are none
+	ReaderDb = (&dbtest.DB{Query: func(query string, args []driver.Value) (*dbtest.Rows, error) {
+		return &dbtest.Rows{Columns: []string{"slot"}}, nil
+	}}).Sqlx()
+
+	client, admin := newTestBigtable(t)
+	ctx := context.Background()
+
+	// the v1 beaconchain table is not part of the schema anymore
+	if err := admin.CreateTable(ctx, "beaconchain"); err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range V1V2MigrationFamilies {
+		if err := admin.CreateColumnFamily(ctx, "beaconchain", family); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bt := &Bigtable{
+		client:                  client,
+		tableBeaconchain:        client.Open("beaconchain"),
+		tableValidators:         client.Open("beaconchain_validators"),
+		tableValidatorsHistory:  client.Open("beaconchain_validators_history"),
+		chainId:                 "1",
+		LastAttestationCacheMux: &sync.Mutex{},
+	}
+
+	epoch := uint64(5)
+	slot := epoch * 32
+	apply := func(key string, family string, validator uint64, ts uint64, value []byte) {
+		mut := gcp_bigtable.NewMutation()
+		mut.Set(family, fmt.Sprintf("%d", validator), gcp_bigtable.Timestamp(ts), value)
+		if err := bt.tableBeaconchain.Apply(ctx, key, mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	balance := make([]byte, 16)
+	binary.LittleEndian.PutUint64(balance[0:8], 32_100_000_000)
+	binary.LittleEndian.PutUint64(balance[8:16], 32_000_000_000)
+	apply(fmt.Sprintf("1:e:b:%s", reversedPaddedEpochV1(epoch)), VALIDATOR_BALANCES_FAMILY, 1, 0, balance)
+	apply(fmt.Sprintf("1:e:b:%s", reversedPaddedEpochV1(epoch)), VALIDATOR_BALANCES_FAMILY, 2, 0, balance)
+
+	slotKey := func(slot uint64) string {
+		return fmt.Sprintf("1:e:%s:s:%s", reversedPaddedEpochV1(epoch), reversedPaddedSlotV1(slot))
+	}
+	apply(slotKey(slot), PROPOSALS_FAMILY, 1, (max_block_number_v1-slot)*1000, []byte{})
+	apply(slotKey(slot+1), PROPOSALS_FAMILY, 2, 0, []byte{})
+	apply(slotKey(slot+2), SYNC_COMMITTEES_FAMILY, 1, (max_block_number_v1-slot-2)*1000, []byte{})
+	apply(slotKey(slot+2), SYNC_COMMITTEES_FAMILY, 2, 0, []byte{})
+
+	// validator 1 missed its attestation, the one of validator 2 has been included twice (one cell version per inclusion)
+	apply(slotKey(slot+3), ATTESTATIONS_FAMILY, 1, 0, []byte{})
+	apply(slotKey(slot+3), ATTESTATIONS_FAMILY, 2, (max_block_number_v1-slot-4)*1000, []byte{})
+	apply(slotKey(slot+3), ATTESTATIONS_FAMILY, 2, (max_block_number_v1-slot-6)*1000, []byte{})
+
+	for _, family := range []string{VALIDATOR_BALANCES_FAMILY, ATTESTATIONS_FAMILY, PROPOSALS_FAMILY, SYNC_COMMITTEES_FAMILY} {
+		validators, err := bt.MigrateValidatorHistoryV1V2Schema(family, epoch, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(validators) != 2 || validators[0] != 1 || validators[1] != 2 {
+			t.Fatalf("unexpected validators %v for family %v", validators, family)
+		}
+
+		// nothing has been written in dry run mode
+		mismatches, err := bt.VerifyValidatorHistoryV1V2Schema(family, validators, epoch, epoch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(mismatches) != 2 {
+			t.Fatalf("expected 2 mismatches for family %v before the migration, got %v", family, mismatches)
+		}
+
+		_, err = bt.MigrateValidatorHistoryV1V2Schema(family, epoch, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mismatches, err = bt.VerifyValidatorHistoryV1V2Schema(family, validators, epoch, epoch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(mismatches) != 0 {
+			t.Fatalf("unexpected mismatches for family %v after the migration: %v", family, mismatches)
+		}
+	}
+
+	inclusions, err := bt.getValidatorAttestationInclusionsV2([]uint64{1, 2}, epoch, epoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	missed := inclusions[1][types.Slot(slot+3)]
+	if len(missed) != 1 || missed[0].Status != 0 {
+		t.Fatalf("expected a single missed attestation of validator 1, got %+v", missed)
+	}
+	included := inclusions[2][types.Slot(slot+3)]
+	if len(included) != 2 {
+		t.Fatalf("expected both inclusions of the attestation of validator 2, got %+v", included)
+	}
+	inclusionSlots := []uint64{included[0].InclusionSlot, included[1].InclusionSlot}
+	sort.Slice(inclusionSlots, func(i, j int) bool { return inclusionSlots[i] < inclusionSlots[j] })
+	if inclusionSlots[0] != slot+4 || inclusionSlots[1] != slot+6 {
+		t.Fatalf("unexpected inclusion slots %v of validator 2", inclusionSlots)
+	}
+
+	// a diverging v2 value is reported
+	err = bt.SaveValidatorBalances(epoch, []*types.Validator{{Index: 2, Balance: 1, EffectiveBalance: 32_000_000_000}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mismatches, err := bt.VerifyValidatorHistoryV1V2Schema(VALIDATOR_BALANCES_FAMILY, []uint64{1, 2}, epoch, epoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "vb validator 2 epoch 5: v1 has balance=32100000000 effective=32000000000, v2 has balance=1 effective=32000000000"
+	if len(mismatches) != 1 || mismatches[0] != expected {
+		t.Fatalf("unexpected mismatches %v", mismatches)
+	}
+}
@@ -0,0 +1,697 @@
+package db
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	RETENTION_RESOLUTION_HOURLY = "hourly"
+	RETENTION_RESOLUTION_DAILY  = "daily"
+
+	// validatorHistoryArchiveChunkSize is the number of validators stored per archive file
+	validatorHistoryArchiveChunkSize = 10000
+	retentionStateRefreshInterval    = time.Minute
+)
+
+// the retention state row stores per family the first epoch that has not been downsampled, archived or pruned yet
+func retentionPrunedColumn(family string) string {
+	return family + ":pruned"
+}
+
+func retentionArchivedColumn(family string) string {
+	return family + ":archived"
+}
+
+func retentionDownsampledColumn(family string, resolution string) string {
+	return family + ":downsampled:" + resolution
+}
+
+// retentionResolutionKey returns the row key segment of the aggregates of a resolution
+func retentionResolutionKey(resolution string) (string, error) {
+	switch resolution {
+	case "":
+		return "", nil
+	case RETENTION_RESOLUTION_HOURLY:
+		return "h", nil
+	case RETENTION_RESOLUTION_DAILY:
+		return "d", nil
+	}
+	return "", fmt.Errorf("unknown retention resolution %v", resolution)
+}
+
+// retentionBucket returns the aggregation bucket of an epoch, buckets never span multiple days
+func retentionBucket(epoch uint64, resolution string) uint64 {
+	epochsPerDay := utils.EpochsPerDay()
+	day := epoch / epochsPerDay
+	if resolution == RETENTION_RESOLUTION_HOURLY {
+		return day*24 + (epoch%epochsPerDay)*24/epochsPerDay
+	}
+	return day
+}
+
+func retentionPolicy(family string) types.ValidatorHistoryRetention {
+	if family == ATTESTATIONS_FAMILY {
+		return utils.Config.Bigtable.Retention.Attestations
+	}
+	return utils.Config.Bigtable.Retention.Balances
+}
+
+// getRetentionState returns the retention state, it is read from bigtable at most once per retentionStateRefreshInterval.
+// Concurrent refreshes share a single read and readers of a fresh state are not blocked by a refresh.
+func (bigtable *Bigtable) getRetentionState(forceRefresh bool) (map[string]uint64, error) {
+	bigtable.retentionMux.RLock()
+	state, updated := bigtable.retentionState, bigtable.retentionStateUpdated
+	bigtable.retentionMux.RUnlock()
+	if !forceRefresh && state != nil && time.Since(updated) < retentionStateRefreshInterval {
+		return state, nil
+	}
+
+	res, err, _ := bigtable.retentionStateGroup.Do("retention", func() (interface{}, error) {
+		return bigtable.refreshRetentionState()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.(map[string]uint64), nil
+}
+
+func (bigtable *Bigtable) refreshRetentionState() (map[string]uint64, error) {
+	bigtable.retentionMux.RLock()
+	version := bigtable.retentionStateVersion
+	bigtable.retentionMux.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	key := fmt.Sprintf("%s:retention", bigtable.chainId)
+	row, err := bigtable.tableValidatorsHistory.ReadRow(ctx, key, gcp_bigtable.RowFilter(gcp_bigtable.ChainFilters(gcp_bigtable.FamilyFilter(STATS_COLUMN_FAMILY), gcp_bigtable.LatestNFilter(1))))
+	if err != nil {
+		return nil, fmt.Errorf("error reading validator history retention state: %w", err)
+	}
+
+	state := make(map[string]uint64)
+	for _, ri := range row[STATS_COLUMN_FAMILY] {
+		if len(ri.Value) != 8 {
+			continue
+		}
+		state[strings.TrimPrefix(ri.Column, STATS_COLUMN_FAMILY+":")] = binary.LittleEndian.Uint64(ri.Value)
+	}
+
+	bigtable.retentionMux.Lock()
+	defer bigtable.retentionMux.Unlock()
+	if bigtable.retentionStateVersion != version {
+		// the state has been saved during the read, the row that has been read may miss the saved column
+		return bigtable.retentionState, nil
+	}
+	bigtable.retentionState = state
+	bigtable.retentionStateUpdated = time.Now()
+	return state, nil
+}
+
+func (bigtable *Bigtable) saveRetentionState(column string, epoch uint64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	value := make([]byte, 8)
+	binary.LittleEndian.PutUint64(value, epoch)
+	mut := gcp_bigtable.NewMutation()
+	mut.Set(STATS_COLUMN_FAMILY, column, gcp_bigtable.Timestamp(0), value)
+	err := bigtable.tableValidatorsHistory.Apply(ctx, fmt.Sprintf("%s:retention", bigtable.chainId), mut)
+	if err != nil {
+		return fmt.Errorf("error saving validator history retention state: %w", err)
+	}
+
+	// the cached state is shared with readers and therefore replaced instead of modified
+	bigtable.retentionMux.Lock()
+	defer bigtable.retentionMux.Unlock()
+	state := make(map[string]uint64, len(bigtable.retentionState)+1)
+	for k, v := range bigtable.retentionState {
+		state[k] = v
+	}
+	state[column] = epoch
+	bigtable.retentionState = state
+	bigtable.retentionStateVersion++
+	return nil
+}
+
+// prunedRange returns the end of the part of an epoch range whose raw history has been removed from bigtable by the retention policy
+func (bigtable *Bigtable) prunedRange(family string, startEpoch uint64, endEpoch uint64) (uint64, bool, error) {
+	state, err := bigtable.getRetentionState(false)
+	if err != nil {
+		return 0, false, err
+	}
+	pruned := state[retentionPrunedColumn(family)]
+	if startEpoch >= pruned {
+		return 0, false, nil
+	}
+	if endEpoch >= pruned {
+		return pruned - 1, true, nil
+	}
+	return endEpoch, true, nil
+}
+
+// isArchived returns true if the raw history of a family up to endEpoch is available in the archive
+func (bigtable *Bigtable) isArchived(family string, endEpoch uint64) (bool, error) {
+	if bigtable.archive == nil {
+		return false, nil
+	}
+	state, err := bigtable.getRetentionState(false)
+	if err != nil {
+		return false, err
+	}
+	return state[retentionArchivedColumn(family)] > endEpoch, nil
+}
+
+// ApplyValidatorHistoryRetention downsamples, archives and prunes the validator history of all complete days that are due according to the configured retention policies
+func (bigtable *Bigtable) ApplyValidatorHistoryRetention(now time.Time) error {
+	for _, family := range []string{VALIDATOR_BALANCES_FAMILY, ATTESTATIONS_FAMILY} {
+		err := bigtable.applyValidatorHistoryRetention(family, retentionPolicy(family), now)
+		if err != nil {
+			return fmt.Errorf("error applying retention policy of family %v: %w", family, err)
+		}
+	}
+	return nil
+}
+
+func (bigtable *Bigtable) applyValidatorHistoryRetention(family string, policy types.ValidatorHistoryRetention, now time.Time) error {
+	resolutionKey, err := retentionResolutionKey(policy.Resolution)
+	if err != nil {
+		return err
+	}
+	downsampling := resolutionKey != ""
+	archiving := policy.ArchiveAfterDays > 0
+	if !downsampling && !archiving {
+		return nil
+	}
+	if archiving && bigtable.archive == nil {
+		return fmt.Errorf("archiving requires the blob indexer s3 bucket to be configured")
+	}
+	if downsampling && archiving && policy.DownsampleAfterDays > policy.ArchiveAfterDays {
+		return fmt.Errorf("downsampleAfterDays must not be larger than archiveAfterDays as the raw history is pruned once it has been archived")
+	}
+
+	// raw history is pruned once it has been archived, or once it has been downsampled if archiving is disabled
+	pruneAfterDays := policy.DownsampleAfterDays
+	minDays := policy.DownsampleAfterDays
+	if archiving {
+		pruneAfterDays = policy.ArchiveAfterDays
+		if !downsampling {
+			minDays = policy.ArchiveAfterDays
+		}
+	}
+
+	state, err := bigtable.getRetentionState(true)
+	if err != nil {
+		return err
+	}
+
+	epochsPerDay := utils.EpochsPerDay()
+	currentDay := utils.TimeToDay(uint64(now.Unix()))
+
+	// the v1 schema is not covered, start with the first complete day stored in the v2 schema
+	firstDay := (bigtable.v2SchemaCutOffEpoch + epochsPerDay - 1) / epochsPerDay
+	if prunedDay := state[retentionPrunedColumn(family)] / epochsPerDay; prunedDay > firstDay {
+		firstDay = prunedDay
+	}
+
+	for day := firstDay; day+minDays < currentDay; day++ {
+		firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
+
+		downsample := downsampling && day+policy.DownsampleAfterDays < currentDay && state[retentionDownsampledColumn(family, policy.Resolution)] <= firstEpoch
+		archive := archiving && day+policy.ArchiveAfterDays < currentDay && state[retentionArchivedColumn(family)] <= firstEpoch
+
+		start := time.Now()
+		maxValidatorIndex, err := bigtable.getMaxValidatorindexForEpochV2(lastEpoch)
+		if err != nil {
+			return err
+		}
+
+		if downsample || archive {
+			var orphanedSlotsMap map[uint64]bool
+			if downsample && family == ATTESTATIONS_FAMILY {
+				slots := make([]uint64, 0, (lastEpoch-firstEpoch+1)*utils.Config.Chain.ClConfig.SlotsPerEpoch)
+				for slot := firstEpoch * utils.Config.Chain.ClConfig.SlotsPerEpoch; slot < (lastEpoch+1)*utils.Config.Chain.ClConfig.SlotsPerEpoch; slot++ {
+					slots = append(slots, slot)
+				}
+				orphanedSlotsMap, err = GetOrphanedSlotsMap(slots)
+				if err != nil {
+					return err
+				}
+			}
+
+			for chunk := uint64(0); chunk*validatorHistoryArchiveChunkSize <= maxValidatorIndex; chunk++ {
+				validators := validatorHistoryChunk(chunk)
+
+				var data []byte
+				switch family {
+				case VALIDATOR_BALANCES_FAMILY:
+					balances, err := bigtable.getValidatorBalanceHistoryV2(validators, firstEpoch, lastEpoch)
+					if err != nil {
+						return err
+					}
+					if downsample {
+						err = bigtable.saveValidatorBalanceAggregates(balances, policy.Resolution)
+						if err != nil {
+							return err
+						}
+					}
+					if archive {
+						data, err = encodeBalancesParquet(balances)
+						if err != nil {
+							return err
+						}
+					}
+				case ATTESTATIONS_FAMILY:
+					inclusions, err := bigtable.getValidatorAttestationInclusionsV2(validators, firstEpoch, lastEpoch)
+					if err != nil {
+						return err
+					}
+					if downsample {
+						err = bigtable.saveValidatorAttestationAggregates(inclusions, orphanedSlotsMap, policy.Resolution)
+						if err != nil {
+							return err
+						}
+					}
+					if archive {
+						data, err = encodeAttestationsParquet(inclusions)
+						if err != nil {
+							return err
+						}
+					}
+				default:
+					return fmt.Errorf("unsupported family %v", family)
+				}
+
+				if archive {
+					ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+					err = bigtable.archive.put(ctx, bigtable.archiveKey(family, day, chunk), data)
+					cancel()
+					if err != nil {
+						return fmt.Errorf("error archiving day %v chunk %v: %w", day, chunk, err)
+					}
+				}
+			}
+
+			if downsample {
+				err = bigtable.saveRetentionState(retentionDownsampledColumn(family, policy.Resolution), lastEpoch+1)
+				if err != nil {
+					return err
+				}
+			}
+			if archive {
+				err = bigtable.saveRetentionState(retentionArchivedColumn(family), lastEpoch+1)
+				if err != nil {
+					return err
+				}
+			}
+			state, err = bigtable.getRetentionState(false)
+			if err != nil {
+				return err
+			}
+		}
+
+		covered := state[retentionDownsampledColumn(family, policy.Resolution)] > lastEpoch
+		if archiving {
+			covered = state[retentionArchivedColumn(family)] > lastEpoch
+		}
+		prune := day+pruneAfterDays < currentDay && covered && state[retentionPrunedColumn(family)] <= firstEpoch
+		if prune {
+			for chunk := uint64(0); chunk*validatorHistoryArchiveChunkSize <= maxValidatorIndex; chunk++ {
+				err = bigtable.deleteValidatorHistoryRows(family, validatorHistoryChunk(chunk), firstEpoch, lastEpoch)
+				if err != nil {
+					return err
+				}
+			}
+			err = bigtable.saveRetentionState(retentionPrunedColumn(family), lastEpoch+1)
+			if err != nil {
+				return err
+			}
+			state, err = bigtable.getRetentionState(false)
+			if err != nil {
+				return err
+			}
+		}
+
+		if downsample || archive || prune {
+			logger.WithFields(logrus.Fields{"family": family, "day": day, "downsampled": downsample, "archived": archive, "pruned": prune}).Infof("applied retention policy to epochs %v - %v in %v", firstEpoch, lastEpoch, time.Since(start))
+		}
+	}
+	return nil
+}
+
+func validatorHistoryChunk(chunk uint64) []uint64 {
+	validators := make([]uint64, 0, validatorHistoryArchiveChunkSize)
+	for validator := chunk * validatorHistoryArchiveChunkSize; validator < (chunk+1)*validatorHistoryArchiveChunkSize; validator++ {
+		validators = append(validators, validator)
+	}
+	return validators
+}
+
+func (bigtable *Bigtable) deleteValidatorHistoryRows(family string, validators []uint64, startEpoch uint64, endEpoch uint64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+	defer cancel()
+
+	muts := types.NewBulkMutations(len(validators))
+	ranges := bigtable.getValidatorsEpochRanges(validators, family, startEpoch, endEpoch)
+	err := bigtable.tableValidatorsHistory.ReadRows(ctx, ranges, func(r gcp_bigtable.Row) bool {
+		mut := gcp_bigtable.NewMutation()
+		mut.DeleteRow()
+		muts.Add(r.Key(), mut)
+		return true
+	}, gcp_bigtable.RowFilter(gcp_bigtable.ChainFilters(gcp_bigtable.LatestNFilter(1), gcp_bigtable.StripValueFilter())))
+	if err != nil {
+		return fmt.Errorf("error reading %v rows to prune: %w", family, err)
+	}
+	return bigtable.WriteBulk(muts, bigtable.tableValidatorsHistory, MAX_BATCH_MUTATIONS)
+}
+
+// saveValidatorBalanceAggregates stores the last balance of every validator per bucket
+func (bigtable *Bigtable) saveValidatorBalanceAggregates(balances map[uint64][]*types.ValidatorBalance, resolution string) error {
+	resolutionKey, err := retentionResolutionKey(resolution)
+	if err != nil {
+		return err
+	}
+
+	muts := types.NewBulkMutations(len(balances))
+	for validator, history := range balances {
+		last := make(map[uint64]*types.ValidatorBalance)
+		for _, b := range history {
+			bucket := retentionBucket(b.Epoch, resolution)
+			if last[bucket] == nil || b.Epoch > last[bucket].Epoch {
+				last[bucket] = b
+			}
+		}
+		for bucket, b := range last {
+			value := make([]byte, 0, 24)
+			value = binary.LittleEndian.AppendUint64(value, b.Epoch)
+			value = binary.LittleEndian.AppendUint64(value, b.Balance)
+			value = binary.LittleEndian.AppendUint64(value, b.EffectiveBalance)
+
+			mut := gcp_bigtable.NewMutation()
+			mut.Set(VALIDATOR_BALANCES_FAMILY, "a", gcp_bigtable.Timestamp(0), value)
+			muts.Add(fmt.Sprintf("%s:%s:%s:%s:%s", bigtable.chainId, bigtable.validatorIndexToKey(validator), VALIDATOR_BALANCES_FAMILY, resolutionKey, bigtable.reversedPaddedEpoch(bucket)), mut)
+		}
+	}
+	return bigtable.WriteBulk(muts, bigtable.tableValidatorsHistory, MAX_BATCH_MUTATIONS)
+}
+
+// saveValidatorAttestationAggregates stores the number of executed and missed attestations of every validator per bucket
+func (bigtable *Bigtable) saveValidatorAttestationAggregates(inclusions map[types.ValidatorIndex]map[types.Slot][]*types.ValidatorAttestation, orphanedSlotsMap map[uint64]bool, resolution string) error {
+	resolutionKey, err := retentionResolutionKey(resolution)
+	if err != nil {
+		return err
+	}
+
+	muts := types.NewBulkMutations(len(inclusions))
+	for validator, attestations := range inclusions {
+		executed := make(map[uint64]uint64)
+		missed := make(map[uint64]uint64)
+		for attesterSlot, att := range attestations {
+			bucket := retentionBucket(utils.EpochOfSlot(uint64(attesterSlot)), resolution)
+			if attestationMissed(att, orphanedSlotsMap) {
+				missed[bucket]++
+			} else {
+				executed[bucket]++
+			}
+		}
+		for bucket := range mergeKeys(executed, missed) {
+			value := make([]byte, 0, 16)
+			value = binary.LittleEndian.AppendUint64(value, executed[bucket])
+			value = binary.LittleEndian.AppendUint64(value, missed[bucket])
+
+			mut := gcp_bigtable.NewMutation()
+			mut.Set(ATTESTATIONS_FAMILY, "a", gcp_bigtable.Timestamp(0), value)
+			muts.Add(fmt.Sprintf("%s:%s:%s:%s:%s", bigtable.chainId, bigtable.validatorIndexToKey(uint64(validator)), ATTESTATIONS_FAMILY, resolutionKey, bigtable.reversedPaddedEpoch(bucket)), mut)
+		}
+	}
+	return bigtable.WriteBulk(muts, bigtable.tableValidatorsHistory, MAX_BATCH_MUTATIONS)
+}
+
+func mergeKeys(a, b map[uint64]uint64) map[uint64]bool {
+	res := make(map[uint64]bool, len(a)+len(b))
+	for k := range a {
+		res[k] = true
+	}
+	for k := range b {
+		res[k] = true
+	}
+	return res
+}
+
+// attestationMissed returns true if none of the inclusions of an attestation is part of the canonical chain
+func attestationMissed(inclusions []*types.ValidatorAttestation, orphanedSlotsMap map[uint64]bool) bool {
+	for _, inclusion := range inclusions {
+		if inclusion.Status == 1 && !orphanedSlotsMap[inclusion.InclusionSlot] {
+			return false
+		}
+	}
+	return true
+}
+
+// readValidatorHistoryAggregates calls handle for every aggregate of the validators in the buckets of an epoch range
+func (bigtable *Bigtable) readValidatorHistoryAggregates(family string, validators []uint64, resolution string, startEpoch uint64, endEpoch uint64, handle func(validator uint64, value []byte)) error {
+	resolutionKey, err := retentionResolutionKey(resolution)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+	defer cancel()
+
+	batchSize := 1000
+	for i := 0; i < len(validators); i += batchSize {
+		upperBound := i + batchSize
+		if len(validators) < upperBound {
+			upperBound = len(validators)
+		}
+		ranges := bigtable.getValidatorsEpochRanges(validators[i:upperBound], family+":"+resolutionKey, retentionBucket(startEpoch, resolution), retentionBucket(endEpoch, resolution))
+		err := bigtable.tableValidatorsHistory.ReadRows(ctx, ranges, func(r gcp_bigtable.Row) bool {
+			validator, err := bigtable.validatorKeyToIndex(strings.Split(r.Key(), ":")[1])
+			if err != nil {
+				logger.Errorf("error parsing validator from row key %v: %v", r.Key(), err)
+				return false
+			}
+			for _, ri := range r[family] {
+				handle(validator, ri.Value)
+			}
+			return true
+		}, gcp_bigtable.RowFilter(gcp_bigtable.LatestNFilter(1)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readValidatorHistoryArchive calls handle for every archive file that holds data of the validators in an epoch range
+func (bigtable *Bigtable) readValidatorHistoryArchive(family string, validators []uint64, startEpoch uint64, endEpoch uint64, handle func(data []byte) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+	defer cancel()
+
+	chunks := make(map[uint64]bool)
+	for _, validator := range validators {
+		chunks[validator/validatorHistoryArchiveChunkSize] = true
+	}
+
+	handleMux := &sync.Mutex{}
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(10)
+	for day := startEpoch / utils.EpochsPerDay(); day <= endEpoch/utils.EpochsPerDay(); day++ {
+		for chunk := range chunks {
+			key := bigtable.archiveKey(family, day, chunk)
+			g.Go(func() error {
+				data, err := bigtable.archive.get(gCtx, key)
+				if errors.Is(err, errArchiveObjectNotFound) {
+					return nil
+				}
+				if err != nil {
+					return fmt.Errorf("error reading archive file %v: %w", key, err)
+				}
+				handleMux.Lock()
+				defer handleMux.Unlock()
+				return handle(data)
+			})
+		}
+	}
+	return g.Wait()
+}
+
+// getValidatorBalanceHistoryRetained returns the balance history of pruned epochs from the archive, or from the downsampled aggregates
+// with one balance per bucket if the epochs have not been archived
+func (bigtable *Bigtable) getValidatorBalanceHistoryRetained(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64][]*types.ValidatorBalance, error) {
+	res := make(map[uint64][]*types.ValidatorBalance, len(validators))
+
+	archived, err := bigtable.isArchived(VALIDATOR_BALANCES_FAMILY, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+	if archived {
+		validatorMap := make(map[uint64]bool, len(validators))
+		for _, validator := range validators {
+			validatorMap[validator] = true
+		}
+		err = bigtable.readValidatorHistoryArchive(VALIDATOR_BALANCES_FAMILY, validators, startEpoch, endEpoch, func(data []byte) error {
+			rows, err := decodeParquet[parquetBalance](data)
+			if err != nil {
+				return err
+			}
+			for _, row := range rows {
+				validator, epoch := uint64(row.Validator), uint64(row.Epoch)
+				if !validatorMap[validator] || epoch < startEpoch || epoch > endEpoch {
+					continue
+				}
+				res[validator] = append(res[validator], &types.ValidatorBalance{
+					Epoch:            epoch,
+					Balance:          uint64(row.Balance),
+					EffectiveBalance: uint64(row.EffectiveBalance),
+					Index:            validator,
+					PublicKey:        []byte{},
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, balances := range res {
+			sort.Slice(balances, func(i, j int) bool { return balances[i].Epoch > balances[j].Epoch })
+		}
+		return res, nil
+	}
+
+	resolution := utils.Config.Bigtable.Retention.Balances.Resolution
+	if resolution == "" {
+		return res, nil
+	}
+	err = bigtable.readValidatorHistoryAggregates(VALIDATOR_BALANCES_FAMILY, validators, resolution, startEpoch, endEpoch, func(validator uint64, value []byte) {
+		if len(value) != 24 {
+			return
+		}
+		res[validator] = append(res[validator], &types.ValidatorBalance{
+			Epoch:            binary.LittleEndian.Uint64(value[0:8]),
+			Balance:          binary.LittleEndian.Uint64(value[8:16]),
+			EffectiveBalance: binary.LittleEndian.Uint64(value[16:24]),
+			Index:            validator,
+			PublicKey:        []byte{},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// getValidatorAttestationInclusionsRetained returns the attestation inclusions of pruned epochs from the archive
+func (bigtable *Bigtable) getValidatorAttestationInclusionsRetained(validators []uint64, startEpoch uint64, endEpoch uint64) (map[types.ValidatorIndex]map[types.Slot][]*types.ValidatorAttestation, error) {
+	res := make(map[types.ValidatorIndex]map[types.Slot][]*types.ValidatorAttestation)
+
+	archived, err := bigtable.isArchived(ATTESTATIONS_FAMILY, endEpoch)
+	if err != nil || !archived {
+		return res, err
+	}
+
+	validatorMap := make(map[uint64]bool, len(validators))
+	for _, validator := range validators {
+		validatorMap[validator] = true
+	}
+	err = bigtable.readValidatorHistoryArchive(ATTESTATIONS_FAMILY, validators, startEpoch, endEpoch, func(data []byte) error {
+		rows, err := decodeParquet[parquetAttestation](data)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			validator, attesterSlot := types.ValidatorIndex(row.Validator), types.Slot(row.AttesterSlot)
+			epoch := utils.EpochOfSlot(uint64(attesterSlot))
+			if !validatorMap[uint64(validator)] || epoch < startEpoch || epoch > endEpoch {
+				continue
+			}
+			status := uint64(1)
+			if row.InclusionSlot == 0 {
+				status = 0
+			}
+			if res[validator] == nil {
+				res[validator] = make(map[types.Slot][]*types.ValidatorAttestation)
+			}
+			res[validator][attesterSlot] = append(res[validator][attesterSlot], &types.ValidatorAttestation{
+				InclusionSlot: uint64(row.InclusionSlot),
+				Status:        status,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// getValidatorMissedAttestationHistoryRetained returns the missed attestations of pruned epochs from the archive
+func (bigtable *Bigtable) getValidatorMissedAttestationHistoryRetained(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64]map[uint64]bool, error) {
+	inclusions, err := bigtable.getValidatorAttestationInclusionsRetained(validators, startEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+	if len(inclusions) == 0 {
+		return map[uint64]map[uint64]bool{}, nil
+	}
+
+	slots := []uint64{}
+	for slot := startEpoch * utils.Config.Chain.ClConfig.SlotsPerEpoch; slot < (endEpoch+1)*utils.Config.Chain.ClConfig.SlotsPerEpoch; slot++ {
+		slots = append(slots, slot)
+	}
+	orphanedSlotsMap, err := GetOrphanedSlotsMap(slots)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[uint64]map[uint64]bool)
+	for validator, attestations := range inclusions {
+		for attesterSlot, att := range attestations {
+			if !attestationMissed(att, orphanedSlotsMap) {
+				continue
+			}
+			if res[uint64(validator)] == nil {
+				res[uint64(validator)] = make(map[uint64]bool)
+			}
+			res[uint64(validator)][uint64(attesterSlot)] = true
+		}
+	}
+	return res, nil
+}
+
+// getValidatorMissedAttestationAggregates returns the number of missed attestations of pruned epochs that have only been downsampled,
+// the range is extended to the buckets containing its first and last epoch
+func (bigtable *Bigtable) getValidatorMissedAttestationAggregates(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64]uint64, error) {
+	res := make(map[uint64]uint64)
+
+	resolution := utils.Config.Bigtable.Retention.Attestations.Resolution
+	archived, err := bigtable.isArchived(ATTESTATIONS_FAMILY, endEpoch)
+	if err != nil || archived || resolution == "" {
+		return res, err
+	}
+
+	err = bigtable.readValidatorHistoryAggregates(ATTESTATIONS_FAMILY, validators, resolution, startEpoch, endEpoch, func(validator uint64, value []byte) {
+		if len(value) != 16 {
+			return
+		}
+		res[validator] += binary.LittleEndian.Uint64(value[8:16])
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// memoryValidatorHistoryArchive keeps the archive in memory
+type memoryValidatorHistoryArchive struct {
+	mux     sync.Mutex
+	objects map[string][]byte
+	gets    int
+}
+
+func (a *memoryValidatorHistoryArchive) put(ctx context.Context, key string, data []byte) error {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	if a.objects == nil {
+		a.objects = make(map[string][]byte)
+	}
+	a.objects[key] = data
+	return nil
+}
+
+func (a *memoryValidatorHistoryArchive) get(ctx context.Context, key string) ([]byte, error) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	a.gets++
+	data, ok := a.objects[key]
+	if !ok {
+		return nil, errArchiveObjectNotFound
+	}
+	return data, nil
+}
+
+func newTestRetentionBigtable(t *testing.T, policy types.ValidatorHistoryRetention) *Bigtable {
+	utils.Config = &types.Config{}
+	utils.Config.Chain.ClConfig.SlotsPerEpoch = 32
+	utils.Config.Chain.ClConfig.SecondsPerSlot = 12
+	utils.Config.Bigtable.Retention.Balances = policy
+
+	client, _ := newTestBigtable(t)
+	bt := &Bigtable{
+		client:                 client,
+		tableValidators:        client.Open("beaconchain_validators"),
+		tableValidatorsHistory: client.Open("beaconchain_validators_history"),
+		chainId:                "1",
+		retentionMux:           &sync.RWMutex{},
+		retentionStateGroup:    &singleflight.Group{},
+		archive:                newCachedValidatorHistoryArchive(&memoryValidatorHistoryArchive{}),
+	}
+
+	// two complete days of 225 epochs and the beginning of the third one
+	for epoch := uint64(0); epoch <= 460; epoch++ {
+		validators := []*types.Validator{}
+		for index := uint64(0); index < 3; index++ {
+			validators = append(validators, &types.Validator{Index: index, Balance: 32_000_000_000 + epoch, EffectiveBalance: 32_000_000_000})
+		}
+		if err := bt.SaveValidatorBalances(epoch, validators); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// genesis is at 0, so this is day 3 and the first two days are due
+	if err := bt.ApplyValidatorHistoryRetention(time.Unix(3*86400+10, 0)); err != nil {
+		t.Fatal(err)
+	}
+	return bt
+}
+
+func TestValidatorHistoryRetentionArchive(t *testing.T) {
+	bt := newTestRetentionBigtable(t, types.ValidatorHistoryRetention{Resolution: RETENTION_RESOLUTION_DAILY, DownsampleAfterDays: 1, ArchiveAfterDays: 1})
+	validators := []uint64{0, 1, 2}
+
+	raw, err := bt.getValidatorBalanceHistoryV2(validators, 0, 449)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) != 0 {
+		t.Fatalf("expected the first two days to be pruned, got data for %v validators", len(raw))
+	}
+
+	// the range spans the archive and the raw history still stored in bigtable
+	balances, err := bt.GetValidatorBalanceHistory(validators, 440, 455)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, validator := range validators {
+		if len(balances[validator]) != 16 {
+			t.Fatalf("expected 16 balances for validator %v, got %v", validator, len(balances[validator]))
+		}
+		for i, b := range balances[validator] {
+			epoch := uint64(455 - i)
+			if b.Epoch != epoch || b.Balance != 32_000_000_000+epoch || b.EffectiveBalance != 32_000_000_000 {
+				t.Fatalf("unexpected balance %+v for validator %v at position %v", b, validator, i)
+			}
+		}
+	}
+
+	// applying the policy again is a no-op
+	if err := bt.ApplyValidatorHistoryRetention(time.Unix(3*86400+10, 0)); err != nil {
+		t.Fatal(err)
+	}
+	balances, err = bt.GetValidatorBalanceHistory([]uint64{1}, 0, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances[1]) != 10 || balances[1][0].Epoch != 9 || balances[1][9].Balance != 32_000_000_000 {
+		t.Fatalf("unexpected archived balances %+v", balances[1])
+	}
+}
+
+func TestValidatorHistoryRetentionDownsample(t *testing.T) {
+	bt := newTestRetentionBigtable(t, types.ValidatorHistoryRetention{Resolution: RETENTION_RESOLUTION_DAILY, DownsampleAfterDays: 1})
+
+	// without an archive the pruned days are served from the daily aggregates
+	balances, err := bt.GetValidatorBalanceHistory([]uint64{2}, 0, 450)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances[2]) != 3 {
+		t.Fatalf("expected 3 balances, got %+v", balances[2])
+	}
+	for i, epoch := range []uint64{450, 449, 224} {
+		if balances[2][i].Epoch != epoch || balances[2][i].Balance != 32_000_000_000+epoch {
+			t.Fatalf("unexpected balance %+v at position %v", balances[2][i], i)
+		}
+	}
+}
+
+func TestCachedValidatorHistoryArchive(t *testing.T) {
+	ctx := context.Background()
+	files := &memoryValidatorHistoryArchive{}
+	archive := newCachedValidatorHistoryArchive(files)
+
+	_, err := archive.get(ctx, "day")
+	if !errors.Is(err, errArchiveObjectNotFound) {
+		t.Fatalf("error is %v, want %v", err, errArchiveObjectNotFound)
+	}
+	err = archive.put(ctx, "day", []byte("v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		data, err := archive.get(ctx, "day")
+		if err != nil || string(data) != "v1" {
+			t.Fatalf("got %q, %v, want v1", data, err)
+		}
+	}
+	if files.gets != 2 {
+		t.Errorf("archive has been read %v times, want 2 as missing files are not cached and stored files are", files.gets)
+	}
+
+	err = archive.put(ctx, "day", []byte("v2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := archive.get(ctx, "day")
+	if err != nil || string(data) != "v2" {
+		t.Errorf("got %q, %v after overwriting the file, want v2", data, err)
+	}
+}
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"cloud.google.com/go/bigtable/bttest"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// newTestBigtable starts an in memory bigtable server with the explorer schema
+func newTestBigtable(t *testing.T) (*gcp_bigtable.Client, *gcp_bigtable.AdminClient) {
+	server, err := bttest.NewServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+
+	conn, err := grpc.Dial(server.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	ctx := context.Background()
+	client, err := gcp_bigtable.NewClient(ctx, "test", "test", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin, err := gcp_bigtable.NewAdminClient(ctx, "test", "test", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := createBigtableSchema(ctx, admin); err != nil {
+		t.Fatal(err)
+	}
+	return client, admin
+}
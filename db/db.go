@@ -524,6 +524,66 @@ func SetSlotFinalizationAndStatus(slot uint64, finalized bool, status string, tx
 	return nil
 }
 
+type CanonicalBlockRow struct {
+	Slot      uint64 `db:"slot"`
+	BlockRoot []byte `db:"blockroot"`
+	Proposer  uint64 `db:"proposer"`
+}
+
+// GetNonFinalizedCanonicalBlocks returns all proposed and not yet finalized blocks, newest first
+func GetNonFinalizedCanonicalBlocks(tx *sqlx.Tx) ([]*CanonicalBlockRow, error) {
+	var blocks []*CanonicalBlockRow
+	err := tx.Select(&blocks, "SELECT slot, blockroot, proposer FROM blocks WHERE NOT finalized AND status = '1' ORDER BY slot DESC")
+
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving non finalized canonical blocks from the DB: %w", err)
+	}
+
+	return blocks, nil
+}
+
+// SaveReorg stores a detected chain reorganization
+func SaveReorg(reorg *types.Reorg, tx *sqlx.Tx) error {
+	_, err := tx.Exec(`
+		INSERT INTO reorgs (slot, depth, common_ancestor_slot, old_head_root, new_head_root, old_head_proposer, new_head_proposer, orphaned_slots, orphaned_proposers)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		reorg.Slot, reorg.Depth, reorg.CommonAncestorSlot, reorg.OldHeadRoot, reorg.NewHeadRoot, reorg.OldHeadProposer, reorg.NewHeadProposer, reorg.OrphanedSlots, reorg.OrphanedProposers)
+
+	if err != nil {
+		return fmt.Errorf("error saving reorg at slot %v: %w", reorg.Slot, err)
+	}
+
+	return nil
+}
+
+// GetReorgs returns the most recent chain reorganizations
+func GetReorgs(limit, offset uint64) ([]*types.Reorg, error) {
+	reorgs := []*types.Reorg{}
+	err := ReaderDb.Select(&reorgs, `
+		SELECT id, slot, depth, common_ancestor_slot, old_head_root, new_head_root, old_head_proposer, new_head_proposer, orphaned_slots, orphaned_proposers, detected_at
+		FROM reorgs
+		ORDER BY slot DESC, id DESC
+		LIMIT $1 OFFSET $2`, limit, offset)
+
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving reorgs: %w", err)
+	}
+
+	return reorgs, nil
+}
+
+// GetReorgCount returns the number of recorded chain reorganizations
+func GetReorgCount() (uint64, error) {
+	var count uint64
+	err := ReaderDb.Get(&count, "SELECT COUNT(*) FROM reorgs")
+
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving reorg count: %w", err)
+	}
+
+	return count, nil
+}
+
 type GetAllNonFinalizedSlotsRow struct {
 	Slot      uint64 `db:"slot"`
 	BlockRoot []byte `db:"blockroot"`
@@ -940,26 +1000,18 @@ func SaveValidators(epoch uint64, validators []*types.Validator, client rpc.Clie
 		return fmt.Errorf("error retrieving current validator state set: %v", err)
 	}
 
-	for ; ; time.Sleep(time.Second) { // wait till the last attestation in memory cache has been populated by the exporter
-		BigtableClient.LastAttestationCacheMux.Lock()
-		if BigtableClient.LastAttestationCache != nil {
-			BigtableClient.LastAttestationCacheMux.Unlock()
-			break
-		}
-		BigtableClient.LastAttestationCacheMux.Unlock()
+	for ; !BigtableClient.LastAttestationCacheInitialized(); time.Sleep(time.Second) { // wait till the last attestation in memory cache has been populated by the exporter
 		logger.Infof("waiting until LastAttestation in memory cache is available")
 	}
 
 	currentStateMap := make(map[uint64]*types.Validator, len(currentState))
 	latestBlock := uint64(0)
-	BigtableClient.LastAttestationCacheMux.Lock()
 	for _, v := range currentState {
-		if BigtableClient.LastAttestationCache[v.Index] > latestBlock {
-			latestBlock = BigtableClient.LastAttestationCache[v.Index]
+		if lastAttestationSlot := BigtableClient.CachedLastAttestationSlot(v.Index); lastAttestationSlot > latestBlock {
+			latestBlock = lastAttestationSlot
 		}
 		currentStateMap[v.Index] = v
 	}
-	BigtableClient.LastAttestationCacheMux.Unlock()
 
 	thresholdSlot := uint64(0)
 	if latestBlock >= 64 {
@@ -1048,9 +1100,7 @@ func SaveValidators(epoch uint64, validators []*types.Validator, client rpc.Clie
 			// WHEN EXCLUDED.activationepoch < %[1]d AND GREATEST(EXCLUDED.lastattestationslot, validators.lastattestationslot) < %[2]d THEN 'active_offline'
 			// ELSE 'active_online'
 			// END
-			BigtableClient.LastAttestationCacheMux.Lock()
-			offline := BigtableClient.LastAttestationCache[v.Index] < thresholdSlot
-			BigtableClient.LastAttestationCacheMux.Unlock()
+			offline := BigtableClient.CachedLastAttestationSlot(v.Index) < thresholdSlot
 
 			if v.ExitEpoch <= latestEpoch && v.Slashed {
 				v.Status = "slashed"
@@ -1297,6 +1347,33 @@ func saveBlocks(blocks map[uint64]map[string]*types.Block, tx *sqlx.Tx, forceSlo
 	}
 	defer stmtBLSChange.Close()
 
+	stmtDepositRequests, err := tx.Prepare(`
+		INSERT INTO blocks_deposit_requests (block_slot, block_root, request_index, pubkey, withdrawal_credentials, amount, signature, deposit_index)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (block_slot, block_root, request_index) DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	defer stmtDepositRequests.Close()
+
+	stmtWithdrawalRequests, err := tx.Prepare(`
+		INSERT INTO blocks_withdrawal_requests (block_slot, block_root, request_index, source_address, validator_pubkey, amount)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (block_slot, block_root, request_index) DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	defer stmtWithdrawalRequests.Close()
+
+	stmtConsolidationRequests, err := tx.Prepare(`
+		INSERT INTO blocks_consolidation_requests (block_slot, block_root, request_index, source_address, source_pubkey, target_pubkey)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (block_slot, block_root, request_index) DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	defer stmtConsolidationRequests.Close()
+
 	stmtProposerSlashing, err := tx.Prepare(`
 		INSERT INTO blocks_proposerslashings (block_slot, block_index, block_root, proposerindex, header1_slot, header1_parentroot, header1_stateroot, header1_bodyroot, header1_signature, header2_slot, header2_parentroot, header2_stateroot, header2_bodyroot, header2_signature)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
@@ -1552,6 +1629,27 @@ func saveBlocks(blocks map[uint64]map[string]*types.Block, tx *sqlx.Tx, forceSlo
 			blockLog.WithField("duration", time.Since(t)).Tracef("stmtBLSChange")
 			t = time.Now()
 
+			for i, r := range b.DepositRequests {
+				_, err := stmtDepositRequests.Exec(b.Slot, b.BlockRoot, i, r.Pubkey, r.WithdrawalCredentials, r.Amount, r.Signature, r.Index)
+				if err != nil {
+					return fmt.Errorf("error executing stmtDepositRequests for block %v index %v: %w", b.Slot, i, err)
+				}
+			}
+			for i, r := range b.WithdrawalRequests {
+				_, err := stmtWithdrawalRequests.Exec(b.Slot, b.BlockRoot, i, r.SourceAddress, r.ValidatorPubkey, r.Amount)
+				if err != nil {
+					return fmt.Errorf("error executing stmtWithdrawalRequests for block %v index %v: %w", b.Slot, i, err)
+				}
+			}
+			for i, r := range b.ConsolidationRequests {
+				_, err := stmtConsolidationRequests.Exec(b.Slot, b.BlockRoot, i, r.SourceAddress, r.SourcePubkey, r.TargetPubkey)
+				if err != nil {
+					return fmt.Errorf("error executing stmtConsolidationRequests for block %v index %v: %w", b.Slot, i, err)
+				}
+			}
+			blockLog.WithField("duration", time.Since(t)).Tracef("execution requests")
+			t = time.Now()
+
 			for i, as := range b.AttesterSlashings {
 				_, err := stmtAttesterSlashing.Exec(b.Slot, i, b.BlockRoot, pq.Array(as.Attestation1.AttestingIndices), as.Attestation1.Signature, as.Attestation1.Data.Slot, as.Attestation1.Data.CommitteeIndex, as.Attestation1.Data.BeaconBlockRoot, as.Attestation1.Data.Source.Epoch, as.Attestation1.Data.Source.Root, as.Attestation1.Data.Target.Epoch, as.Attestation1.Data.Target.Root, pq.Array(as.Attestation2.AttestingIndices), as.Attestation2.Signature, as.Attestation2.Data.Slot, as.Attestation2.Data.CommitteeIndex, as.Attestation2.Data.BeaconBlockRoot, as.Attestation2.Data.Source.Epoch, as.Attestation2.Data.Source.Root, as.Attestation2.Data.Target.Epoch, as.Attestation2.Data.Target.Root)
 				if err != nil {
@@ -2382,6 +2480,58 @@ func GetEpochWithdrawals(epoch uint64) ([]*types.WithdrawalsNotification, error)
 	return withdrawals, nil
 }
 
+// GetEpochExitRequests returns the full exits requested via the execution layer in the canonical blocks of an epoch
+func GetEpochExitRequests(epoch uint64) ([]*types.WithdrawalRequestData, error) {
+	requests := []*types.WithdrawalRequestData{}
+
+	err := ReaderDb.Select(&requests, `
+	SELECT
+		r.block_slot,
+		r.block_root,
+		r.request_index,
+		v.validatorindex,
+		r.source_address,
+		r.validator_pubkey,
+		r.amount
+	FROM blocks_withdrawal_requests r
+	INNER JOIN blocks b ON b.blockroot = r.block_root AND b.status = '1'
+	LEFT JOIN validators v ON v.pubkey = r.validator_pubkey
+	WHERE r.block_slot >= $1 AND r.block_slot < $2 AND r.amount = 0
+	ORDER BY r.block_slot, r.request_index`, epoch*utils.Config.Chain.ClConfig.SlotsPerEpoch, (epoch+1)*utils.Config.Chain.ClConfig.SlotsPerEpoch)
+	if err != nil {
+		return nil, fmt.Errorf("error getting epoch blocks_withdrawal_requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+// GetEpochConsolidationRequests returns the consolidation requests in the canonical blocks of an epoch
+func GetEpochConsolidationRequests(epoch uint64) ([]*types.ConsolidationRequestData, error) {
+	requests := []*types.ConsolidationRequestData{}
+
+	err := ReaderDb.Select(&requests, `
+	SELECT
+		r.block_slot,
+		r.block_root,
+		r.request_index,
+		r.source_address,
+		sv.validatorindex AS source_validatorindex,
+		r.source_pubkey,
+		tv.validatorindex AS target_validatorindex,
+		r.target_pubkey
+	FROM blocks_consolidation_requests r
+	INNER JOIN blocks b ON b.blockroot = r.block_root AND b.status = '1'
+	LEFT JOIN validators sv ON sv.pubkey = r.source_pubkey
+	LEFT JOIN validators tv ON tv.pubkey = r.target_pubkey
+	WHERE r.block_slot >= $1 AND r.block_slot < $2
+	ORDER BY r.block_slot, r.request_index`, epoch*utils.Config.Chain.ClConfig.SlotsPerEpoch, (epoch+1)*utils.Config.Chain.ClConfig.SlotsPerEpoch)
+	if err != nil {
+		return nil, fmt.Errorf("error getting epoch blocks_consolidation_requests: %w", err)
+	}
+
+	return requests, nil
+}
+
 func GetValidatorWithdrawals(validator uint64, limit uint64, offset uint64, orderBy string, orderDir string) ([]*types.Withdrawals, error) {
 	var withdrawals []*types.Withdrawals
 	if limit == 0 {
@@ -3003,6 +3153,137 @@ func GetValidatorsBLSChange(validators []uint64) ([]*types.ValidatorsBLSChange,
 	return change, nil
 }
 
+// GetSlotDepositRequests returns the execution layer deposit requests of the canonical block of the slot
+func GetSlotDepositRequests(slot uint64) ([]*types.DepositRequestData, error) {
+	requests := []*types.DepositRequestData{}
+
+	err := ReaderDb.Select(&requests, `
+	SELECT
+		r.block_slot,
+		r.block_root,
+		r.request_index,
+		v.validatorindex,
+		r.pubkey,
+		r.withdrawal_credentials,
+		r.amount,
+		r.signature,
+		r.deposit_index
+	FROM blocks_deposit_requests r
+	INNER JOIN blocks b ON b.blockroot = r.block_root AND b.status = '1'
+	LEFT JOIN validators v ON v.pubkey = r.pubkey
+	WHERE r.block_slot = $1
+	ORDER BY r.request_index`, slot)
+	if err != nil {
+		return nil, fmt.Errorf("error getting slot blocks_deposit_requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+// GetSlotWithdrawalRequests returns the execution layer withdrawal requests of the canonical block of the slot
+func GetSlotWithdrawalRequests(slot uint64) ([]*types.WithdrawalRequestData, error) {
+	requests := []*types.WithdrawalRequestData{}
+
+	err := ReaderDb.Select(&requests, `
+	SELECT
+		r.block_slot,
+		r.block_root,
+		r.request_index,
+		v.validatorindex,
+		r.source_address,
+		r.validator_pubkey,
+		r.amount
+	FROM blocks_withdrawal_requests r
+	INNER JOIN blocks b ON b.blockroot = r.block_root AND b.status = '1'
+	LEFT JOIN validators v ON v.pubkey = r.validator_pubkey
+	WHERE r.block_slot = $1
+	ORDER BY r.request_index`, slot)
+	if err != nil {
+		return nil, fmt.Errorf("error getting slot blocks_withdrawal_requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+// GetSlotConsolidationRequests returns the consolidation requests of the canonical block of the slot
+func GetSlotConsolidationRequests(slot uint64) ([]*types.ConsolidationRequestData, error) {
+	requests := []*types.ConsolidationRequestData{}
+
+	err := ReaderDb.Select(&requests, `
+	SELECT
+		r.block_slot,
+		r.block_root,
+		r.request_index,
+		r.source_address,
+		sv.validatorindex AS source_validatorindex,
+		r.source_pubkey,
+		tv.validatorindex AS target_validatorindex,
+		r.target_pubkey
+	FROM blocks_consolidation_requests r
+	INNER JOIN blocks b ON b.blockroot = r.block_root AND b.status = '1'
+	LEFT JOIN validators sv ON sv.pubkey = r.source_pubkey
+	LEFT JOIN validators tv ON tv.pubkey = r.target_pubkey
+	WHERE r.block_slot = $1
+	ORDER BY r.request_index`, slot)
+	if err != nil {
+		return nil, fmt.Errorf("error getting slot blocks_consolidation_requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+// GetValidatorWithdrawalRequests returns the execution layer withdrawal requests for a validator, most recent first
+func GetValidatorWithdrawalRequests(pubkey []byte, limit uint64) ([]*types.WithdrawalRequestData, error) {
+	requests := []*types.WithdrawalRequestData{}
+
+	err := ReaderDb.Select(&requests, `
+	SELECT
+		r.block_slot,
+		r.block_root,
+		r.request_index,
+		r.source_address,
+		r.validator_pubkey,
+		r.amount
+	FROM blocks_withdrawal_requests r
+	INNER JOIN blocks b ON b.blockroot = r.block_root AND b.status = '1'
+	WHERE r.validator_pubkey = $1
+	ORDER BY r.block_slot DESC, r.request_index DESC
+	LIMIT $2`, pubkey, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting validator blocks_withdrawal_requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+// GetValidatorConsolidationRequests returns the consolidation requests with the validator as source or target, most recent first
+func GetValidatorConsolidationRequests(pubkey []byte, limit uint64) ([]*types.ConsolidationRequestData, error) {
+	requests := []*types.ConsolidationRequestData{}
+
+	err := ReaderDb.Select(&requests, `
+	SELECT
+		r.block_slot,
+		r.block_root,
+		r.request_index,
+		r.source_address,
+		sv.validatorindex AS source_validatorindex,
+		r.source_pubkey,
+		tv.validatorindex AS target_validatorindex,
+		r.target_pubkey
+	FROM blocks_consolidation_requests r
+	INNER JOIN blocks b ON b.blockroot = r.block_root AND b.status = '1'
+	LEFT JOIN validators sv ON sv.pubkey = r.source_pubkey
+	LEFT JOIN validators tv ON tv.pubkey = r.target_pubkey
+	WHERE r.source_pubkey = $1 OR r.target_pubkey = $1
+	ORDER BY r.block_slot DESC, r.request_index DESC
+	LIMIT $2`, pubkey, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting validator blocks_consolidation_requests: %w", err)
+	}
+
+	return requests, nil
+}
+
 func GetWithdrawableValidatorCount(epoch uint64) (uint64, error) {
 	var count uint64
 	err := ReaderDb.Get(&count, `
@@ -3020,7 +3301,11 @@ func GetWithdrawableValidatorCount(epoch uint64) (uint64, error) {
         WHERE DAY = (SELECT COALESCE(MAX(day), 0) FROM validator_stats_status)) as stats 
 	ON stats.validatorindex = validators.validatorindex
 	WHERE 
-		validators.withdrawalcredentials LIKE '\x01' || '%'::bytea AND ((stats.end_effective_balance = $1 AND stats.end_balance > $1) OR (validators.withdrawableepoch <= $2 AND stats.end_balance > 0));`, utils.Config.Chain.ClConfig.MaxEffectiveBalance, epoch)
+		(
+			(validators.withdrawalcredentials LIKE '\x01' || '%'::bytea AND stats.end_effective_balance = $1 AND stats.end_balance > $1) OR
+			(validators.withdrawalcredentials LIKE '\x02' || '%'::bytea AND stats.end_effective_balance = $3 AND stats.end_balance > $3) OR
+			((validators.withdrawalcredentials LIKE '\x01' || '%'::bytea OR validators.withdrawalcredentials LIKE '\x02' || '%'::bytea) AND validators.withdrawableepoch <= $2 AND stats.end_balance > 0)
+		);`, utils.Config.Chain.ClConfig.MinActivationBalance, epoch, utils.Config.Chain.ClConfig.MaxEffectiveBalanceElectra)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, nil
@@ -3257,7 +3542,8 @@ func GetSyncParticipationBySlotRange(startSlot, endSlot uint64) (map[uint64]uint
 }
 
 // Should be used when retrieving data for a very large amount of validators (for the notifications process)
-func GetValidatorAttestationHistoryForNotifications(startEpoch uint64, endEpoch uint64) (map[types.Epoch]map[types.ValidatorIndex]bool, error) {
+// Only the validators of the shard are included.
+func GetValidatorAttestationHistoryForNotifications(startEpoch uint64, endEpoch uint64, shard types.NotificationShard) (map[types.Epoch]map[types.ValidatorIndex]bool, error) {
 	// first retrieve activation & exit epoch for all validators
 	activityData := []struct {
 		ValidatorIndex  types.ValidatorIndex
@@ -3265,7 +3551,7 @@ func GetValidatorAttestationHistoryForNotifications(startEpoch uint64, endEpoch
 		ExitEpoch       types.Epoch
 	}{}
 
-	err := ReaderDb.Select(&activityData, "SELECT validatorindex, activationepoch, exitepoch FROM validators ORDER BY validatorindex;")
+	err := ReaderDb.Select(&activityData, "SELECT validatorindex, activationepoch, exitepoch FROM validators WHERE $1 <= 1 OR validatorindex % $1 = $2 ORDER BY validatorindex;", shard.Count, shard.Index)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving activation & exit epoch for validators: %w", err)
 	}
@@ -3325,6 +3611,9 @@ func GetValidatorAttestationHistoryForNotifications(startEpoch uint64, endEpoch
 		}
 
 		for _, validator := range attestingValidators {
+			if !shard.ContainsValidator(uint64(validator)) {
+				continue
+			}
 			participation[types.ValidatorIndex(validator)] = true
 		}
 	}
@@ -0,0 +1,292 @@
+// Package dbtest provides a database/sql driver for tests that answers queries from memory instead of a database.
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DB is a database/sql connector that passes every statement to Query or Exec.
+// A nil handler fails the statement, the handlers are never called concurrently.
+type DB struct {
+	Query func(query string, args []driver.Value) (*Rows, error)
+	Exec  func(query string, args []driver.Value) (driver.Result, error)
+
+	mux sync.Mutex
+}
+
+// Sqlx returns a postgres flavored sqlx handle of the connector
+func (d *DB) Sqlx() *sqlx.DB {
+	return sqlx.NewDb(sql.OpenDB(d), "postgres")
+}
+
+func (d *DB) Connect(ctx context.Context) (driver.Conn, error) {
+	return &conn{db: d}, nil
+}
+
+func (d *DB) Open(name string) (driver.Conn, error) {
+	return &conn{db: d}, nil
+}
+
+func (d *DB) Driver() driver.Driver {
+	return d
+}
+
+// Rows is the result of a query
+type Rows struct {
+	Columns []string
+	Values  [][]driver.Value
+}
+
+type conn struct {
+	db *DB
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{db: c.db, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *conn) Commit() error {
+	return nil
+}
+
+func (c *conn) Rollback() error {
+	return nil
+}
+
+type stmt struct {
+	db    *DB
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mux.Lock()
+	defer s.db.mux.Unlock()
+	if s.db.Exec == nil {
+		return nil, fmt.Errorf("error unexpected statement: %v", s.query)
+	}
+	return s.db.Exec(s.query, args)
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mux.Lock()
+	defer s.db.mux.Unlock()
+	if s.db.Query == nil {
+		return nil, fmt.Errorf("error unexpected query: %v", s.query)
+	}
+	rows, err := s.db.Query(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &driverRows{columns: rows.Columns, values: rows.Values}, nil
+}
+
+type driverRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *driverRows) Columns() []string {
+	return r.columns
+}
+
+func (r *driverRows) Close() error {
+	return nil
+}
+
+func (r *driverRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+var (
+	aliasRE         = regexp.MustCompile(`(?is)^.*\s+as\s+("[^"]+"|\w+)$`)
+	implicitAliasRE = regexp.MustCompile(`(?s)^.*[\w)"]\s+("[^"]+"|\w+)$`)
+	functionRE      = regexp.MustCompile(`^([\w.]+)\s*\(`)
+	identifierRE    = regexp.MustCompile(`^("[^"]+"|[A-Za-z_]\w*)(\.("[^"]+"|\w+))*$`)
+)
+
+// SelectColumns returns the names of the columns postgres returns for the outermost select list of query
+func SelectColumns(query string) ([]string, error) {
+	selectList, err := outermostSelectList(stripComments(query))
+	if err != nil {
+		return nil, err
+	}
+
+	columns := []string{}
+	for _, item := range splitTopLevel(selectList, ',') {
+		item = strings.TrimSpace(item)
+		if match := aliasRE.FindStringSubmatch(item); match != nil {
+			columns = append(columns, unquoteIdentifier(match[1]))
+			continue
+		}
+		if match := implicitAliasRE.FindStringSubmatch(item); match != nil && !strings.EqualFold(match[1], "end") {
+			columns = append(columns, unquoteIdentifier(match[1]))
+			continue
+		}
+		if cast := splitTopLevelString(item, "::"); len(cast) > 1 {
+			item = strings.TrimSpace(cast[0])
+		}
+		switch {
+		case strings.HasSuffix(item, "*"):
+			return nil, fmt.Errorf("error can not resolve the columns of %q", item)
+		case identifierRE.MatchString(item):
+			parts := splitTopLevel(item, '.')
+			columns = append(columns, unquoteIdentifier(parts[len(parts)-1]))
+		case functionRE.MatchString(item):
+			name := functionRE.FindStringSubmatch(item)[1]
+			columns = append(columns, strings.ToLower(name[strings.LastIndex(name, ".")+1:]))
+		case len(item) >= 4 && strings.EqualFold(item[:4], "case"):
+			columns = append(columns, "case")
+		default:
+			columns = append(columns, "?column?")
+		}
+	}
+	return columns, nil
+}
+
+// outermostSelectList returns the part of query between the select and from keywords of the outermost query
+func outermostSelectList(query string) (string, error) {
+	start := -1
+	end := len(query)
+	depth := 0
+	for i := 0; i < len(query); i++ {
+		switch query[i] {
+		case '(':
+			depth++
+			continue
+		case ')':
+			depth--
+			continue
+		case '\'', '"':
+			next := strings.IndexByte(query[i+1:], query[i])
+			if next < 0 {
+				return "", fmt.Errorf("error unterminated quote in query")
+			}
+			i += next + 1
+			continue
+		}
+		if depth != 0 || (i > 0 && isIdentifierChar(query[i-1])) {
+			continue
+		}
+		if start < 0 && hasKeyword(query[i:], "select") {
+			start = i + len("select")
+			if rest := strings.TrimLeft(query[start:], " \t\r\n"); hasKeyword(rest, "distinct") {
+				start = len(query) - len(rest) + len("distinct")
+				if rest = strings.TrimLeft(query[start:], " \t\r\n"); hasKeyword(rest, "on") {
+					// skip the expressions of distinct on (...)
+					start = len(query) - len(rest) + strings.IndexByte(rest, ')') + 1
+				}
+			}
+			i = start - 1
+			continue
+		}
+		if start >= 0 && (hasKeyword(query[i:], "from") || hasKeyword(query[i:], "union") || hasKeyword(query[i:], "where")) {
+			end = i
+			break
+		}
+	}
+	if start < 0 {
+		return "", fmt.Errorf("error query has no select list")
+	}
+	return query[start:end], nil
+}
+
+// stripComments removes the line comments of query
+func stripComments(query string) string {
+	var b strings.Builder
+	for i := 0; i < len(query); i++ {
+		switch {
+		case query[i] == '\'' || query[i] == '"':
+			next := strings.IndexByte(query[i+1:], query[i])
+			if next < 0 {
+				return b.String() + query[i:]
+			}
+			b.WriteString(query[i : i+next+2])
+			i += next + 1
+		case strings.HasPrefix(query[i:], "--"):
+			next := strings.IndexByte(query[i:], '\n')
+			if next < 0 {
+				return b.String()
+			}
+			i += next - 1
+		default:
+			b.WriteByte(query[i])
+		}
+	}
+	return b.String()
+}
+
+func hasKeyword(s, keyword string) bool {
+	return len(s) >= len(keyword) && strings.EqualFold(s[:len(keyword)], keyword) && (len(s) == len(keyword) || !isIdentifierChar(s[len(keyword)]))
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func splitTopLevel(s string, sep byte) []string {
+	return splitTopLevelString(s, string(sep))
+}
+
+// splitTopLevelString splits s at every sep that is not enclosed in parentheses or quotes
+func splitTopLevelString(s, sep string) []string {
+	parts := []string{}
+	depth := 0
+	last := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '\'', '"':
+			if next := strings.IndexByte(s[i+1:], s[i]); next >= 0 {
+				i += next + 1
+			}
+		default:
+			if depth == 0 && strings.HasPrefix(s[i:], sep) {
+				parts = append(parts, s[last:i])
+				i += len(sep) - 1
+				last = i + 1
+			}
+		}
+	}
+	return append(parts, s[last:])
+}
+
+// unquoteIdentifier folds unquoted identifiers to lower case like postgres does
+func unquoteIdentifier(identifier string) string {
+	if strings.HasPrefix(identifier, `"`) {
+		return strings.Trim(identifier, `"`)
+	}
+	return strings.ToLower(identifier)
+}
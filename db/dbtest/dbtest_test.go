@@ -0,0 +1,35 @@
+package dbtest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectColumns(t *testing.T) {
+	tests := []struct {
+		query   string
+		columns []string
+	}{
+		{"SELECT id, email FROM users WHERE id = ANY($1)", []string{"id", "email"}},
+		{"select blocks.slot, b.Blockroot, COALESCE(withdrawalcount,0) as withdrawalcount from blocks b", []string{"slot", "blockroot", "withdrawalcount"}},
+		{"SELECT count(*), max(slot) AS \"maxSlot\", epoch::int, status epochstatus FROM epochs", []string{"count", "maxSlot", "epoch", "epochstatus"}},
+		{"SELECT CASE WHEN a > 0 THEN 'x, y' ELSE 'z' END AS kind, CASE WHEN a THEN 1 END FROM t", []string{"kind", "case"}},
+		{"WITH recent AS (SELECT slot FROM blocks) SELECT DISTINCT ON (slot) slot, (SELECT 1 FROM t) AS one FROM recent", []string{"slot", "one"}},
+		{"SELECT validatorindex -- the index, not the pubkey\n, 1 FROM validators", []string{"validatorindex", "?column?"}},
+	}
+	for _, tt := range tests {
+		columns, err := SelectColumns(tt.query)
+		if err != nil {
+			t.Errorf("error parsing %q: %v", tt.query, err)
+			continue
+		}
+		if !reflect.DeepEqual(columns, tt.columns) {
+			t.Errorf("columns of %q are %v, want %v", tt.query, columns, tt.columns)
+		}
+	}
+
+	_, err := SelectColumns("SELECT * FROM blocks")
+	if err == nil {
+		t.Errorf("expected an error for a star select list")
+	}
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+)
+
+const userExportColumns = `id, user_id, network, validators, start_date, end_date, datasets, format, status, created, started, completed, expires, attempts, files, error`
+
+// InsertUserExport queues a new export job and returns its id
+func InsertUserExport(export *types.UserExport) (uint64, error) {
+	var id uint64
+	err := FrontendWriterDB.Get(&id, `
+		INSERT INTO users_exports (user_id, network, validators, start_date, end_date, datasets, format)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`, export.UserID, export.Network, export.Validators, export.StartDate, export.EndDate, export.Datasets, export.Format)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting export of user %v: %w", export.UserID, err)
+	}
+	return id, nil
+}
+
+// GetUserExports returns the most recent export jobs of the user in the network
+func GetUserExports(userID uint64, network string, limit uint64) ([]*types.UserExport, error) {
+	exports := []*types.UserExport{}
+	err := FrontendReaderDB.Select(&exports, `
+		SELECT `+userExportColumns+`
+		FROM users_exports
+		WHERE user_id = $1 AND network = $2
+		ORDER BY id DESC
+		LIMIT $3`, userID, network, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting exports of user %v: %w", userID, err)
+	}
+	return exports, nil
+}
+
+// GetUserExport returns an export job of the user, nil if the user has no export with the id
+func GetUserExport(userID uint64, network string, id uint64) (*types.UserExport, error) {
+	export := &types.UserExport{}
+	err := FrontendWriterDB.Get(export, `
+		SELECT `+userExportColumns+`
+		FROM users_exports
+		WHERE id = $1 AND user_id = $2 AND network = $3`, id, userID, network)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting export %v of user %v: %w", id, userID, err)
+	}
+	return export, nil
+}
+
+// CountUserActiveExports returns the number of export jobs of the user that have not been completed or failed yet
+func CountUserActiveExports(userID uint64) (uint64, error) {
+	var count uint64
+	err := FrontendWriterDB.Get(&count, `SELECT COUNT(*) FROM users_exports WHERE user_id = $1 AND status IN ('pending', 'running')`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("error counting active exports of user %v: %w", userID, err)
+	}
+	return count, nil
+}
+
+// ClaimUserExport leases the oldest export job of the network that is pending or whose lease has expired, nil if there is none.
+// Jobs that have been attempted maxAttempts times are not claimed anymore.
+func ClaimUserExport(network, owner string, lease time.Duration, maxAttempts uint64) (*types.UserExport, error) {
+	export := &types.UserExport{}
+	err := FrontendWriterDB.Get(export, `
+		UPDATE users_exports
+		SET status = 'running', owner = $2, lease_until = NOW() + $3 * INTERVAL '1 second', attempts = attempts + 1, started = COALESCE(started, NOW())
+		WHERE id = (
+			SELECT id FROM users_exports
+			WHERE network = $1 AND (status = 'pending' OR (status = 'running' AND lease_until < NOW())) AND attempts < $4
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+userExportColumns, network, owner, lease.Seconds(), maxAttempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error claiming export: %w", err)
+	}
+	return export, nil
+}
+
+// RenewUserExportLease extends the lease of a running export job, returns false if the job is not owned by owner anymore
+func RenewUserExportLease(id uint64, owner string, lease time.Duration) (bool, error) {
+	res, err := FrontendWriterDB.Exec(`
+		UPDATE users_exports SET lease_until = NOW() + $3 * INTERVAL '1 second'
+		WHERE id = $1 AND owner = $2 AND status = 'running'`, id, owner, lease.Seconds())
+	if err != nil {
+		return false, fmt.Errorf("error renewing lease of export %v: %w", id, err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	return rowsAffected > 0, err
+}
+
+// CompleteUserExport stores the files of a running export job, returns false if the job is not owned by owner anymore
+func CompleteUserExport(id uint64, owner string, files types.UserExportFiles, expires time.Time) (bool, error) {
+	res, err := FrontendWriterDB.Exec(`
+		UPDATE users_exports SET status = 'completed', completed = NOW(), expires = $4, files = $3, error = NULL, owner = NULL, lease_until = NULL
+		WHERE id = $1 AND owner = $2 AND status = 'running'`, id, owner, files, expires)
+	if err != nil {
+		return false, fmt.Errorf("error completing export %v: %w", id, err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	return rowsAffected > 0, err
+}
+
+// FailUserExport releases a running export job after an error, it is retried unless final is set
+func FailUserExport(id uint64, owner string, exportErr error, final bool) error {
+	status := "pending"
+	if final {
+		status = "failed"
+	}
+	_, err := FrontendWriterDB.Exec(`
+		UPDATE users_exports SET status = $3, error = $4, owner = NULL, lease_until = NULL
+		WHERE id = $1 AND owner = $2 AND status = 'running'`, id, owner, status, exportErr.Error())
+	if err != nil {
+		return fmt.Errorf("error releasing export %v: %w", id, err)
+	}
+	return nil
+}
+
+// FailAbandonedUserExports marks the export jobs of the network as failed whose last allowed attempt has been abandoned by a crashed worker
+func FailAbandonedUserExports(network string, maxAttempts uint64) (int64, error) {
+	res, err := FrontendWriterDB.Exec(`
+		UPDATE users_exports SET status = 'failed', error = 'the export has been aborted', owner = NULL, lease_until = NULL
+		WHERE network = $1 AND status = 'running' AND lease_until < NOW() AND attempts >= $2`, network, maxAttempts)
+	if err != nil {
+		return 0, fmt.Errorf("error failing abandoned exports: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// GetExpiredUserExports returns the completed export jobs of the network whose files have expired
+func GetExpiredUserExports(network string, limit uint64) ([]*types.UserExport, error) {
+	exports := []*types.UserExport{}
+	err := FrontendWriterDB.Select(&exports, `
+		SELECT `+userExportColumns+`
+		FROM users_exports
+		WHERE network = $1 AND status = 'completed' AND expires < NOW()
+		ORDER BY expires
+		LIMIT $2`, network, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting expired exports: %w", err)
+	}
+	return exports, nil
+}
+
+// MarkUserExportExpired marks a completed export job as expired once its files have been deleted
+func MarkUserExportExpired(id uint64) error {
+	_, err := FrontendWriterDB.Exec(`UPDATE users_exports SET status = 'expired', files = NULL WHERE id = $1 AND status = 'completed'`, id)
+	if err != nil {
+		return fmt.Errorf("error marking export %v as expired: %w", id, err)
+	}
+	return nil
+}
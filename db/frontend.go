@@ -55,6 +55,73 @@ func GetUserEmailsByIds(ids []uint64) (map[uint64]string, error) {
 	return mailsByID, nil
 }
 
+// GetUserChatChannelsByIds returns the chats of the users, chat channels the user deactivated are omitted.
+func GetUserChatChannelsByIds(ids []uint64) (map[uint64][]types.UserChatChannel, error) {
+	chatsByID := map[uint64][]types.UserChatChannel{}
+	if len(ids) == 0 {
+		return chatsByID, nil
+	}
+	var rows []types.UserChatChannel
+	err := FrontendWriterDB.Select(&rows, `
+		SELECT c.user_id, c.channel, c.target
+		FROM users_chat_channels c
+		WHERE c.user_id = ANY($1) AND NOT EXISTS (
+			SELECT 1 FROM users_notification_channels n WHERE n.user_id = c.user_id AND n.channel = c.channel AND n.active = false
+		)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		chatsByID[r.UserID] = append(chatsByID[r.UserID], r)
+	}
+	return chatsByID, nil
+}
+
+// GetUserChatChannels returns the configured chats of a user.
+func GetUserChatChannels(userID uint64) ([]types.UserChatChannel, error) {
+	var chats []types.UserChatChannel
+	err := FrontendReaderDB.Select(&chats, "SELECT user_id, channel, target FROM users_chat_channels WHERE user_id = $1", userID)
+	return chats, err
+}
+
+// SaveUserChatChannel sets the chat a user receives the notifications of a chat channel in.
+func SaveUserChatChannel(chat types.UserChatChannel) error {
+	_, err := FrontendWriterDB.Exec(`
+		INSERT INTO users_chat_channels (user_id, channel, target) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, channel) DO UPDATE SET target = excluded.target, created = now()`, chat.UserID, chat.Channel, chat.Target)
+	return err
+}
+
+// DeleteUserChatChannel removes the chat of a chat channel of a user.
+func DeleteUserChatChannel(userID uint64, channel types.NotificationChannel) error {
+	_, err := FrontendWriterDB.Exec("DELETE FROM users_chat_channels WHERE user_id = $1 AND channel = $2", userID, channel)
+	return err
+}
+
+// GetUserNotificationPreferencesByIds returns the preferences of the channels of the users that are not delivered immediately or have quiet hours.
+func GetUserNotificationPreferencesByIds(ids []uint64) (map[uint64]map[types.NotificationChannel]*types.UserNotificationPreferences, error) {
+	prefsByID := map[uint64]map[types.NotificationChannel]*types.UserNotificationPreferences{}
+	if len(ids) == 0 {
+		return prefsByID, nil
+	}
+	var rows []*types.UserNotificationPreferences
+	err := FrontendWriterDB.Select(&rows, `
+		SELECT n.user_id, n.channel, n.delivery_mode, n.quiet_hours_start, n.quiet_hours_end, u.notifications_timezone
+		FROM users_notification_channels n
+		INNER JOIN users u ON u.id = n.user_id
+		WHERE n.user_id = ANY($1) AND n.active AND (n.delivery_mode != $2 OR n.quiet_hours_start IS NOT NULL)`, pq.Array(ids), types.ImmediateDeliveryMode)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		if prefsByID[r.UserID] == nil {
+			prefsByID[r.UserID] = map[types.NotificationChannel]*types.UserNotificationPreferences{}
+		}
+		prefsByID[r.UserID][r.Channel] = r
+	}
+	return prefsByID, nil
+}
+
 // DeleteUserByEmail deletes a user.
 func DeleteUserByEmail(email string) error {
 	_, err := FrontendWriterDB.Exec("DELETE FROM users WHERE email = $1", email)
@@ -300,7 +367,7 @@ func AddSubscription(userID uint64, network string, eventName types.EventName, e
 	nowEpoch := utils.TimeToEpoch(now)
 
 	var onConflictDo string = "NOTHING"
-	if strings.HasPrefix(string(eventName), "monitoring_") || eventName == types.RocketpoolCollateralMaxReached || eventName == types.RocketpoolCollateralMinReached || eventName == types.ValidatorIsOfflineEventName {
+	if strings.HasPrefix(string(eventName), "monitoring_") || eventName == types.RocketpoolCollateralMaxReached || eventName == types.RocketpoolCollateralMinReached || eventName == types.ValidatorIsOfflineEventName || eventName == types.ValidatorIncomeBelowNetworkEventName {
 		onConflictDo = "UPDATE SET event_threshold = $6"
 	}
 
@@ -323,7 +390,7 @@ func AddSubscriptionBatch(userID uint64, network string, eventName types.EventNa
 	nowEpoch := utils.TimeToEpoch(now)
 
 	var onConflictDo string = "NOTHING"
-	if strings.HasPrefix(string(eventName), "monitoring_") || eventName == types.RocketpoolCollateralMaxReached || eventName == types.RocketpoolCollateralMinReached || eventName == types.ValidatorIsOfflineEventName {
+	if strings.HasPrefix(string(eventName), "monitoring_") || eventName == types.RocketpoolCollateralMaxReached || eventName == types.RocketpoolCollateralMinReached || eventName == types.ValidatorIsOfflineEventName || eventName == types.ValidatorIncomeBelowNetworkEventName {
 		onConflictDo = "UPDATE SET event_threshold = $6"
 	}
 
@@ -607,7 +674,7 @@ func GetUserPushTokenByIds(ids []uint64) (map[uint64][]string, error) {
 	return pushByID, nil
 }
 
-func MobileDeviceSettingsUpdate(userID, deviceID uint64, notifyEnabled, active string) (*sql.Rows, error) {
+func MobileDeviceSettingsUpdate(userID, deviceID uint64, notifyEnabled, active string) ([]types.MobileSettingsData, error) {
 	var query = ""
 	var args []interface{}
 
@@ -628,10 +695,11 @@ func MobileDeviceSettingsUpdate(userID, deviceID uint64, notifyEnabled, active s
 		return nil, errors.New("no params for change provided")
 	}
 
-	rows, err := FrontendWriterDB.Query("UPDATE users_devices SET "+query+" WHERE user_id = $1 AND id = $2 RETURNING notify_enabled;",
+	settings := []types.MobileSettingsData{}
+	err := FrontendWriterDB.Select(&settings, "UPDATE users_devices SET "+query+" WHERE user_id = $1 AND id = $2 RETURNING notify_enabled;",
 		args...,
 	)
-	return rows, err
+	return settings, err
 }
 
 func MobileDeviceDelete(userID, deviceID uint64) error {
@@ -648,11 +716,12 @@ func addParamToQuery(query, param string) string {
 	return result
 }
 
-func MobileDeviceSettingsSelect(userID, deviceID uint64) (*sql.Rows, error) {
-	rows, err := FrontendWriterDB.Query("SELECT notify_enabled FROM users_devices WHERE user_id = $1 AND id = $2;",
+func MobileDeviceSettingsSelect(userID, deviceID uint64) ([]types.MobileSettingsData, error) {
+	settings := []types.MobileSettingsData{}
+	err := FrontendWriterDB.Select(&settings, "SELECT notify_enabled FROM users_devices WHERE user_id = $1 AND id = $2;",
 		userID, deviceID,
 	)
-	return rows, err
+	return settings, err
 }
 
 func NewTransaction() (*sql.Tx, error) {
@@ -733,14 +802,15 @@ func GetUserAPIKeyStatistics(apikey *string) (*types.ApiStatistics, error) {
 	return stats, nil
 }
 
-func GetSubsForEventFilter(eventName types.EventName) ([][]byte, map[string][]types.Subscription, error) {
+// GetSubsForEventFilter returns the subscriptions of the users in the shard to the event, mapped by event filter
+func GetSubsForEventFilter(eventName types.EventName, shard types.NotificationShard) ([][]byte, map[string][]types.Subscription, error) {
 	var subs []types.Subscription
 	subQuery := `
-		SELECT id, user_id, event_filter, last_sent_epoch, created_epoch, event_threshold, ENCODE(unsubscribe_hash, 'hex') as unsubscribe_hash, internal_state from users_subscriptions where event_name = $1
+		SELECT id, user_id, event_filter, last_sent_epoch, created_epoch, event_threshold, ENCODE(unsubscribe_hash, 'hex') as unsubscribe_hash, internal_state from users_subscriptions where event_name = $1 AND ($2 <= 1 OR user_id % $2 = $3)
 		`
 
 	subMap := make(map[string][]types.Subscription, 0)
-	err := FrontendWriterDB.Select(&subs, subQuery, utils.GetNetwork()+":"+string(eventName))
+	err := FrontendWriterDB.Select(&subs, subQuery, utils.GetNetwork()+":"+string(eventName), shard.Count, shard.Index)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add reorgs table';
+CREATE TABLE IF NOT EXISTS reorgs (
+    id                   SERIAL PRIMARY KEY,
+    slot                 INT NOT NULL,
+    depth                INT NOT NULL,
+    common_ancestor_slot INT NOT NULL,
+    old_head_root        BYTEA NOT NULL,
+    new_head_root        BYTEA NOT NULL,
+    old_head_proposer    INT NOT NULL,
+    new_head_proposer    INT NOT NULL,
+    orphaned_slots       INT[] NOT NULL,
+    orphaned_proposers   INT[] NOT NULL,
+    detected_at          TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_reorgs_slot ON reorgs (slot);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop reorgs table';
+DROP TABLE IF EXISTS reorgs;
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add electra execution request tables';
+CREATE TABLE IF NOT EXISTS
+    blocks_deposit_requests (
+        block_slot INT NOT NULL,
+        block_root bytea NOT NULL,
+        request_index INT NOT NULL,
+        pubkey bytea NOT NULL,
+        withdrawal_credentials bytea NOT NULL,
+        amount BIGINT NOT NULL,
+        -- in GWei
+        signature bytea NOT NULL,
+        deposit_index BIGINT NOT NULL,
+        PRIMARY KEY (block_slot, block_root, request_index)
+    );
+CREATE INDEX IF NOT EXISTS idx_blocks_deposit_requests_pubkey ON blocks_deposit_requests (pubkey);
+
+CREATE TABLE IF NOT EXISTS
+    blocks_withdrawal_requests (
+        block_slot INT NOT NULL,
+        block_root bytea NOT NULL,
+        request_index INT NOT NULL,
+        source_address bytea NOT NULL,
+        validator_pubkey bytea NOT NULL,
+        amount BIGINT NOT NULL,
+        -- in GWei, 0 requests a full exit
+        PRIMARY KEY (block_slot, block_root, request_index)
+    );
+CREATE INDEX IF NOT EXISTS idx_blocks_withdrawal_requests_validator_pubkey ON blocks_withdrawal_requests (validator_pubkey);
+
+CREATE TABLE IF NOT EXISTS
+    blocks_consolidation_requests (
+        block_slot INT NOT NULL,
+        block_root bytea NOT NULL,
+        request_index INT NOT NULL,
+        source_address bytea NOT NULL,
+        source_pubkey bytea NOT NULL,
+        target_pubkey bytea NOT NULL,
+        PRIMARY KEY (block_slot, block_root, request_index)
+    );
+CREATE INDEX IF NOT EXISTS idx_blocks_consolidation_requests_source_pubkey ON blocks_consolidation_requests (source_pubkey);
+CREATE INDEX IF NOT EXISTS idx_blocks_consolidation_requests_target_pubkey ON blocks_consolidation_requests (target_pubkey);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop electra execution request tables';
+DROP TABLE IF EXISTS blocks_consolidation_requests;
+DROP TABLE IF EXISTS blocks_withdrawal_requests;
+DROP TABLE IF EXISTS blocks_deposit_requests;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add webhook secrets and the webhook delivery log';
+ALTER TABLE users_webhooks ADD COLUMN IF NOT EXISTS secret CHARACTER VARYING(100);
+-- used to sign the payload, webhooks created before this migration are sent unsigned until a secret is generated
+
+CREATE TABLE IF NOT EXISTS
+    users_webhooks_deliveries (
+        id SERIAL PRIMARY KEY,
+        user_id INT NOT NULL,
+        webhook_id INT NOT NULL,
+        event_id CHARACTER VARYING(64) NOT NULL,
+        -- stable id of the event, sent to the receiver for deduplication
+        event_name CHARACTER VARYING(100) NOT NULL,
+        CONTENT jsonb NOT NULL,
+        created TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+        status CHARACTER VARYING(20) NOT NULL DEFAULT 'pending',
+        -- pending, delivered or failed
+        attempts INT NOT NULL DEFAULT 0,
+        next_attempt TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+        last_attempt TIMESTAMP WITHOUT TIME ZONE,
+        response_status INT,
+        response_body TEXT,
+        error TEXT,
+        UNIQUE (webhook_id, event_id),
+        FOREIGN KEY (user_id, webhook_id) REFERENCES users_webhooks (user_id, id) ON DELETE CASCADE
+    );
+CREATE INDEX IF NOT EXISTS idx_users_webhooks_deliveries_pending ON users_webhooks_deliveries (next_attempt) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_users_webhooks_deliveries_webhook ON users_webhooks_deliveries (user_id, webhook_id, created DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop webhook secrets and the webhook delivery log';
+DROP TABLE IF EXISTS users_webhooks_deliveries;
+ALTER TABLE users_webhooks DROP COLUMN IF EXISTS secret;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose NO TRANSACTION
+
+-- +goose Up
+
+-- +goose StatementBegin
+ALTER TYPE notification_channels ADD VALUE IF NOT EXISTS 'telegram';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TYPE notification_channels ADD VALUE IF NOT EXISTS 'slack';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TYPE notification_channels ADD VALUE IF NOT EXISTS 'matrix';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    users_chat_channels (
+        user_id INT NOT NULL,
+        channel notification_channels NOT NULL,
+        target CHARACTER VARYING(1024) NOT NULL,
+        -- telegram chat id, slack incoming webhook url or matrix room id
+        created TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+        PRIMARY KEY (user_id, channel)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+
+-- +goose StatementBegin
+-- values can not be removed from an enum, the telegram, slack and matrix channels stay in notification_channels
+DROP TABLE IF EXISTS users_chat_channels;
+-- +goose StatementEnd
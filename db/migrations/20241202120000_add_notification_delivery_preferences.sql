@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add notification delivery modes, quiet hours and the digest queue';
+ALTER TABLE users_notification_channels ADD COLUMN IF NOT EXISTS delivery_mode CHARACTER VARYING(20) NOT NULL DEFAULT 'immediate';
+-- immediate, hourly or daily
+ALTER TABLE users_notification_channels ADD COLUMN IF NOT EXISTS quiet_hours_start SMALLINT;
+ALTER TABLE users_notification_channels ADD COLUMN IF NOT EXISTS quiet_hours_end SMALLINT;
+-- hours of the day in the time zone of the user, quiet hours wrap around midnight if the start is after the end
+ALTER TABLE users ADD COLUMN IF NOT EXISTS notifications_timezone CHARACTER VARYING(64);
+-- IANA time zone used for digests and quiet hours, UTC if not set
+
+CREATE TABLE IF NOT EXISTS
+    notification_digest_queue (
+        id SERIAL PRIMARY KEY,
+        user_id INT NOT NULL,
+        channel notification_channels NOT NULL,
+        event_name CHARACTER VARYING(100) NOT NULL,
+        title TEXT NOT NULL,
+        info TEXT NOT NULL,
+        info_markdown TEXT NOT NULL,
+        created TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+        release_at TIMESTAMP WITHOUT TIME ZONE NOT NULL
+    );
+CREATE INDEX IF NOT EXISTS idx_notification_digest_queue_release_at ON notification_digest_queue (release_at);
+CREATE INDEX IF NOT EXISTS idx_notification_digest_queue_user_channel ON notification_digest_queue (user_id, channel);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop notification delivery modes, quiet hours and the digest queue';
+DROP TABLE IF EXISTS notification_digest_queue;
+ALTER TABLE users DROP COLUMN IF EXISTS notifications_timezone;
+ALTER TABLE users_notification_channels DROP COLUMN IF EXISTS quiet_hours_end;
+ALTER TABLE users_notification_channels DROP COLUMN IF EXISTS quiet_hours_start;
+ALTER TABLE users_notification_channels DROP COLUMN IF EXISTS delivery_mode;
+-- +goose StatementEnd
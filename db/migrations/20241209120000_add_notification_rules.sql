@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add notification rules';
+CREATE TABLE IF NOT EXISTS
+    users_notification_rules (
+        id SERIAL PRIMARY KEY,
+        user_id INT NOT NULL,
+        name CHARACTER VARYING(100) NOT NULL,
+        match CHARACTER VARYING(3) NOT NULL DEFAULT 'all',
+        -- all or any of the conditions must be fulfilled
+        conditions JSONB NOT NULL,
+        created TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+    );
+-- the subscription of a rule is the notification_rule event of the user with the id of the rule as event filter
+CREATE INDEX IF NOT EXISTS idx_users_notification_rules_user_id ON users_notification_rules (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop notification rules';
+DELETE FROM users_subscriptions WHERE event_name LIKE '%:notification_rule';
+DROP TABLE IF EXISTS users_notification_rules;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add notification history';
+CREATE TABLE IF NOT EXISTS
+    users_notification_history (
+        id BIGSERIAL PRIMARY KEY,
+        user_id INT NOT NULL,
+        subscription_id INT NOT NULL,
+        event_name CHARACTER VARYING(100) NOT NULL,
+        event_filter CHARACTER VARYING(254) NOT NULL DEFAULT '',
+        epoch INT NOT NULL,
+        title TEXT NOT NULL,
+        CONTENT TEXT NOT NULL,
+        created TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+        read_ts TIMESTAMP WITHOUT TIME ZONE,
+        acknowledged_ts TIMESTAMP WITHOUT TIME ZONE
+        -- acknowledged notifications have been handled by the user and are read as well
+    );
+CREATE INDEX IF NOT EXISTS idx_users_notification_history_user_id ON users_notification_history (user_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_users_notification_history_unread ON users_notification_history (user_id) WHERE read_ts IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_notification_history_created ON users_notification_history (created);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop notification history';
+DROP TABLE IF EXISTS users_notification_history;
+-- +goose StatementEnd
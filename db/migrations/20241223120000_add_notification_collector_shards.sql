@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add notification collector shards';
+CREATE TABLE IF NOT EXISTS
+    notification_collector_shards (
+        epoch INT NOT NULL,
+        shard INT NOT NULL,
+        shard_count INT NOT NULL,
+        owner TEXT,
+        lease_until TIMESTAMP WITHOUT TIME ZONE,
+        completed TIMESTAMP WITHOUT TIME ZONE,
+        PRIMARY KEY (epoch, shard)
+    );
+CREATE INDEX IF NOT EXISTS idx_notification_collector_shards_pending ON notification_collector_shards (epoch) WHERE completed IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop notification collector shards';
+DROP TABLE IF EXISTS notification_collector_shards;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add users exports';
+CREATE TABLE IF NOT EXISTS
+    users_exports (
+        id SERIAL PRIMARY KEY,
+        user_id INT NOT NULL,
+        network CHARACTER VARYING(20) NOT NULL,
+        validators INT[] NOT NULL,
+        start_date DATE NOT NULL,
+        end_date DATE NOT NULL,
+        datasets TEXT[] NOT NULL,
+        format CHARACTER VARYING(10) NOT NULL,
+        -- csv or parquet
+        status CHARACTER VARYING(20) NOT NULL DEFAULT 'pending',
+        -- pending, running, completed, failed or expired
+        created TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+        started TIMESTAMP WITHOUT TIME ZONE,
+        completed TIMESTAMP WITHOUT TIME ZONE,
+        expires TIMESTAMP WITHOUT TIME ZONE,
+        owner TEXT,
+        lease_until TIMESTAMP WITHOUT TIME ZONE,
+        attempts INT NOT NULL DEFAULT 0,
+        files jsonb,
+        error TEXT,
+        FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+    );
+CREATE INDEX IF NOT EXISTS idx_users_exports_pending ON users_exports (network, id) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS idx_users_exports_expires ON users_exports (expires) WHERE status = 'completed';
+CREATE INDEX IF NOT EXISTS idx_users_exports_user ON users_exports (user_id, id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop users exports';
+DROP TABLE IF EXISTS users_exports;
+-- +goose StatementEnd
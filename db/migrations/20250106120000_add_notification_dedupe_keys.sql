@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add dedupe keys to the notification queues and history';
+ALTER TABLE notification_queue ADD COLUMN IF NOT EXISTS dedupe_key TEXT UNIQUE;
+ALTER TABLE notification_digest_queue ADD COLUMN IF NOT EXISTS dedupe_key TEXT UNIQUE;
+ALTER TABLE users_notification_history ADD COLUMN IF NOT EXISTS dedupe_key TEXT UNIQUE;
+-- rows queued by the notification collector carry a key derived from the epoch and shard they were collected for,
+-- so a shard that is collected again after its instance crashed or lost its lease is not queued twice
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop dedupe keys from the notification queues and history';
+ALTER TABLE users_notification_history DROP COLUMN IF EXISTS dedupe_key;
+ALTER TABLE notification_digest_queue DROP COLUMN IF EXISTS dedupe_key;
+ALTER TABLE notification_queue DROP COLUMN IF EXISTS dedupe_key;
+-- +goose StatementEnd
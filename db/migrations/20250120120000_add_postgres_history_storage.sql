@@ -0,0 +1,177 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add postgres history storage';
+-- the validator history is hash partitioned by validator index like validator_stats
+CREATE TABLE IF NOT EXISTS
+    validator_history_balances (
+        validatorindex INT NOT NULL,
+        epoch INT NOT NULL,
+        balance BIGINT NOT NULL,
+        effectivebalance BIGINT NOT NULL,
+        PRIMARY KEY (validatorindex, epoch)
+    )
+PARTITION BY
+    hash (validatorindex);
+CREATE TABLE IF NOT EXISTS
+    validator_history_attestations (
+        validatorindex INT NOT NULL,
+        attesterslot INT NOT NULL,
+        -- 0 if the attestation has been missed
+        inclusionslot INT NOT NULL,
+        PRIMARY KEY (validatorindex, attesterslot, inclusionslot)
+    )
+PARTITION BY
+    hash (validatorindex);
+CREATE TABLE IF NOT EXISTS
+    validator_history_sync_duties (
+        validatorindex INT NOT NULL,
+        slot INT NOT NULL,
+        participated BOOLEAN NOT NULL,
+        PRIMARY KEY (validatorindex, slot)
+    )
+PARTITION BY
+    hash (validatorindex);
+CREATE TABLE IF NOT EXISTS
+    validator_history_income (
+        validatorindex INT NOT NULL,
+        epoch INT NOT NULL,
+        -- proto encoded ValidatorEpochIncome
+        details BYTEA NOT NULL,
+        PRIMARY KEY (validatorindex, epoch)
+    )
+PARTITION BY
+    hash (validatorindex);
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['validator_history_balances', 'validator_history_attestations', 'validator_history_sync_duties', 'validator_history_income'] LOOP
+        FOR i IN 0..15 LOOP
+            EXECUTE format('CREATE TABLE IF NOT EXISTS %1$s_%2$s PARTITION OF %1$s FOR VALUES WITH (MODULUS 16, REMAINDER %2$s)', t, i);
+        END LOOP;
+    END LOOP;
+END $$;
+CREATE TABLE IF NOT EXISTS
+    validator_history_proposals (
+        validatorindex INT NOT NULL,
+        slot INT NOT NULL,
+        -- false for assigned slots without a proposed block
+        proposed BOOLEAN NOT NULL,
+        PRIMARY KEY (validatorindex, slot)
+    );
+CREATE TABLE IF NOT EXISTS
+    validator_history_income_totals (
+        epoch INT NOT NULL PRIMARY KEY,
+        -- proto encoded sum of the ValidatorEpochIncome of all validators
+        details BYTEA NOT NULL
+    );
+CREATE TABLE IF NOT EXISTS
+    validator_history_highest_active_index (
+        epoch INT NOT NULL PRIMARY KEY,
+        validatorindex INT NOT NULL
+    );
+CREATE TABLE IF NOT EXISTS
+    validator_history_last_attestation_slots (
+        validatorindex INT NOT NULL PRIMARY KEY,
+        slot INT NOT NULL
+    );
+CREATE TABLE IF NOT EXISTS
+    machine_metrics_history (
+        user_id INT NOT NULL,
+        process TEXT NOT NULL,
+        machine TEXT NOT NULL,
+        ts TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+        data BYTEA NOT NULL
+    );
+-- one metric per machine and minute
+CREATE UNIQUE INDEX IF NOT EXISTS idx_machine_metrics_history_minute ON machine_metrics_history (user_id, process, machine, date_trunc('minute', ts));
+CREATE TABLE IF NOT EXISTS
+    gas_now_history (
+        ts TIMESTAMP WITHOUT TIME ZONE NOT NULL PRIMARY KEY,
+        slow NUMERIC NOT NULL,
+        standard NUMERIC NOT NULL,
+        rapid NUMERIC NOT NULL,
+        fast NUMERIC NOT NULL
+    );
+CREATE TABLE IF NOT EXISTS
+    eth1_history_signatures (
+        type TEXT NOT NULL,
+        hex TEXT NOT NULL,
+        signature TEXT NOT NULL,
+        PRIMARY KEY (type, hex)
+    );
+CREATE TABLE IF NOT EXISTS
+    eth1_history_blocks (
+        number BIGINT NOT NULL PRIMARY KEY,
+        hash BYTEA NOT NULL,
+        coinbase BYTEA NOT NULL,
+        time TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+        -- proto encoded Eth1Block
+        data BYTEA NOT NULL,
+        -- proto encoded Eth1BlockIndexed
+        indexed BYTEA NOT NULL
+    );
+CREATE INDEX IF NOT EXISTS idx_eth1_history_blocks_coinbase ON eth1_history_blocks (coinbase, number DESC);
+CREATE TABLE IF NOT EXISTS
+    eth1_history_transactions (
+        hash BYTEA NOT NULL PRIMARY KEY,
+        blocknumber BIGINT NOT NULL,
+        txindex INT NOT NULL,
+        -- proto encoded Eth1TransactionIndexed
+        data BYTEA NOT NULL,
+        -- proto encoded Eth1BlobTransactionIndexed of blob transactions
+        blobdata BYTEA
+    );
+CREATE INDEX IF NOT EXISTS idx_eth1_history_transactions_blocknumber ON eth1_history_transactions (blocknumber);
+CREATE TABLE IF NOT EXISTS
+    eth1_history_address_transactions (
+        address BYTEA NOT NULL,
+        blocknumber BIGINT NOT NULL,
+        txindex INT NOT NULL,
+        hash BYTEA NOT NULL,
+        PRIMARY KEY (address, blocknumber, txindex)
+    );
+CREATE INDEX IF NOT EXISTS idx_eth1_history_address_transactions_blocknumber ON eth1_history_address_transactions (blocknumber);
+CREATE TABLE IF NOT EXISTS
+    eth1_history_uncles (
+        blocknumber BIGINT NOT NULL,
+        uncleindex INT NOT NULL,
+        miner BYTEA NOT NULL,
+        -- proto encoded Eth1UncleIndexed
+        data BYTEA NOT NULL,
+        PRIMARY KEY (blocknumber, uncleindex)
+    );
+CREATE INDEX IF NOT EXISTS idx_eth1_history_uncles_miner ON eth1_history_uncles (miner, blocknumber DESC);
+CREATE TABLE IF NOT EXISTS
+    eth1_history_contract_updates (
+        address BYTEA NOT NULL,
+        blocknumber BIGINT NOT NULL,
+        txindex INT NOT NULL,
+        traceindex INT NOT NULL,
+        iscontract BOOLEAN NOT NULL,
+        success BOOLEAN NOT NULL,
+        PRIMARY KEY (address, blocknumber, txindex, traceindex)
+    );
+CREATE INDEX IF NOT EXISTS idx_eth1_history_contract_updates_blocknumber ON eth1_history_contract_updates (blocknumber);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop postgres history storage';
+DROP TABLE IF EXISTS eth1_history_contract_updates;
+DROP TABLE IF EXISTS eth1_history_uncles;
+DROP TABLE IF EXISTS eth1_history_address_transactions;
+DROP TABLE IF EXISTS eth1_history_transactions;
+DROP TABLE IF EXISTS eth1_history_blocks;
+DROP TABLE IF EXISTS eth1_history_signatures;
+DROP TABLE IF EXISTS gas_now_history;
+DROP TABLE IF EXISTS machine_metrics_history;
+DROP TABLE IF EXISTS validator_history_last_attestation_slots;
+DROP TABLE IF EXISTS validator_history_highest_active_index;
+DROP TABLE IF EXISTS validator_history_income_totals;
+DROP TABLE IF EXISTS validator_history_proposals;
+DROP TABLE IF EXISTS validator_history_income;
+DROP TABLE IF EXISTS validator_history_sync_duties;
+DROP TABLE IF EXISTS validator_history_attestations;
+DROP TABLE IF EXISTS validator_history_balances;
+-- +goose StatementEnd
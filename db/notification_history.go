@@ -0,0 +1,77 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/lib/pq"
+)
+
+// GetUserNotificationHistory returns up to limit notifications of the user with an id between sinceID and beforeID.
+// Without a sinceID the most recent notifications are returned first and the next page starts before the id of the last notification.
+// With a sinceID the oldest notifications after it are returned first, so a client can sync by passing the id of the last notification as sinceID of the next page.
+// A beforeID of 0 does not bound the ids. If unreadOnly or unacknowledgedOnly is set only the notifications that have not been read or acknowledged are returned.
+func GetUserNotificationHistory(userID uint64, unreadOnly, unacknowledgedOnly bool, beforeID, sinceID, limit uint64) ([]*types.UserNotificationHistoryEntry, error) {
+	order := "DESC"
+	if sinceID > 0 {
+		order = "ASC"
+	}
+
+	entries := []*types.UserNotificationHistoryEntry{}
+	err := FrontendReaderDB.Select(&entries, fmt.Sprintf(`
+		SELECT id, user_id, subscription_id, event_name, event_filter, epoch, title, content, created, read_ts, acknowledged_ts
+		FROM users_notification_history
+		WHERE user_id = $1 AND (NOT $2 OR read_ts IS NULL) AND (NOT $3 OR acknowledged_ts IS NULL) AND ($4 = 0 OR id < $4) AND id > $5
+		ORDER BY id %s
+		LIMIT $6`, order), userID, unreadOnly, unacknowledgedOnly, beforeID, sinceID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting notification history of user %v: %w", userID, err)
+	}
+	return entries, nil
+}
+
+// GetUserNotificationHistoryUnreadCount returns the number of notifications of the user that have not been read
+func GetUserNotificationHistoryUnreadCount(userID uint64) (uint64, error) {
+	var count uint64
+	err := FrontendReaderDB.Get(&count, `SELECT COUNT(*) FROM users_notification_history WHERE user_id = $1 AND read_ts IS NULL`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("error counting unread notifications of user %v: %w", userID, err)
+	}
+	return count, nil
+}
+
+// MarkUserNotificationHistoryRead marks the given notifications of the user as read, or all of them if all is set.
+// Returns the number of notifications that have been marked.
+func MarkUserNotificationHistoryRead(userID uint64, ids []uint64, all bool) (int64, error) {
+	res, err := FrontendWriterDB.Exec(`
+		UPDATE users_notification_history
+		SET read_ts = NOW()
+		WHERE user_id = $1 AND ($2 OR id = ANY($3)) AND read_ts IS NULL`, userID, all, pq.Array(ids))
+	if err != nil {
+		return 0, fmt.Errorf("error marking notifications of user %v as read: %w", userID, err)
+	}
+	return res.RowsAffected()
+}
+
+// AcknowledgeUserNotificationHistory marks the given notifications of the user as acknowledged and read, or all of them if all is set.
+// Returns the number of notifications that have been acknowledged.
+func AcknowledgeUserNotificationHistory(userID uint64, ids []uint64, all bool) (int64, error) {
+	res, err := FrontendWriterDB.Exec(`
+		UPDATE users_notification_history
+		SET acknowledged_ts = NOW(), read_ts = COALESCE(read_ts, NOW())
+		WHERE user_id = $1 AND ($2 OR id = ANY($3)) AND acknowledged_ts IS NULL`, userID, all, pq.Array(ids))
+	if err != nil {
+		return 0, fmt.Errorf("error acknowledging notifications of user %v: %w", userID, err)
+	}
+	return res.RowsAffected()
+}
+
+// DeleteNotificationHistoryBefore deletes the notifications of all users that have been created before the given time
+func DeleteNotificationHistoryBefore(ts time.Time) (int64, error) {
+	res, err := FrontendWriterDB.Exec(`DELETE FROM users_notification_history WHERE created < $1`, ts)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting notification history: %w", err)
+	}
+	return res.RowsAffected()
+}
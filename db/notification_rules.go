@@ -0,0 +1,146 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+)
+
+// ErrTooManyNotificationRules is returned if the user already has the maximum number of rules
+var ErrTooManyNotificationRules = errors.New("maximum number of notification rules reached")
+
+// GetUserNotificationRules returns the notification rules of the user
+func GetUserNotificationRules(userID uint64) ([]*types.NotificationRule, error) {
+	rules := []*types.NotificationRule{}
+	err := FrontendReaderDB.Select(&rules, `
+		SELECT r.id, r.user_id, r.name, r.match, r.conditions, r.created, us.last_sent_ts, us.id AS subscription_id
+		FROM users_notification_rules r
+		LEFT JOIN users_subscriptions us ON us.user_id = r.user_id AND us.event_name = $2 AND us.event_filter = r.id::text
+		WHERE r.user_id = $1
+		ORDER BY r.id`, userID, utils.GetNetwork()+":"+string(types.NotificationRuleEventName))
+	if err != nil {
+		return nil, fmt.Errorf("error getting notification rules of user %v: %w", userID, err)
+	}
+	return rules, nil
+}
+
+// AddUserNotificationRule saves a new rule of the user together with its subscription and returns the id of the rule
+func AddUserNotificationRule(rule *types.NotificationRule) (uint64, error) {
+	tx, err := FrontendWriterDB.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var count int
+	err = tx.Get(&count, `SELECT COUNT(*) FROM users_notification_rules WHERE user_id = $1`, rule.UserID)
+	if err != nil {
+		return 0, fmt.Errorf("error counting notification rules of user %v: %w", rule.UserID, err)
+	}
+	if count >= types.NotificationRuleMaxPerUser {
+		return 0, ErrTooManyNotificationRules
+	}
+
+	var id uint64
+	err = tx.Get(&id, `INSERT INTO users_notification_rules (user_id, name, match, conditions) VALUES ($1, $2, $3, $4) RETURNING id`,
+		rule.UserID, rule.Name, rule.Match, rule.Conditions)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting notification rule: %w", err)
+	}
+
+	now := time.Now()
+	_, err = tx.Exec(`
+		INSERT INTO users_subscriptions (user_id, event_name, event_filter, created_ts, created_epoch, event_threshold)
+		VALUES ($1, $2, $3, TO_TIMESTAMP($4), $5, 0)
+		ON CONFLICT (user_id, event_name, event_filter) DO NOTHING`,
+		rule.UserID, utils.GetNetwork()+":"+string(types.NotificationRuleEventName), strconv.FormatUint(id, 10), now.Unix(), utils.TimeToEpoch(now))
+	if err != nil {
+		return 0, fmt.Errorf("error inserting subscription of notification rule %v: %w", id, err)
+	}
+	return id, tx.Commit()
+}
+
+// UpdateUserNotificationRule replaces the name, the match and the conditions of a rule of the user.
+// The subscription is recreated if it has been removed, e.g. by the unsubscribe link of an email,
+// and its state is reset so the rule notifies again if it is fulfilled.
+// Returns sql.ErrNoRows if the user has no such rule.
+func UpdateUserNotificationRule(rule *types.NotificationRule) error {
+	tx, err := FrontendWriterDB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE users_notification_rules SET name = $3, match = $4, conditions = $5 WHERE user_id = $1 AND id = $2`,
+		rule.UserID, rule.ID, rule.Name, rule.Match, rule.Conditions)
+	if err != nil {
+		return fmt.Errorf("error updating notification rule %v: %w", rule.ID, err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error updating notification rule %v: %w", rule.ID, err)
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	now := time.Now()
+	_, err = tx.Exec(`
+		INSERT INTO users_subscriptions (user_id, event_name, event_filter, created_ts, created_epoch, event_threshold)
+		VALUES ($1, $2, $3, TO_TIMESTAMP($4), $5, 0)
+		ON CONFLICT (user_id, event_name, event_filter) DO UPDATE SET internal_state = NULL`,
+		rule.UserID, utils.GetNetwork()+":"+string(types.NotificationRuleEventName), strconv.FormatUint(rule.ID, 10), now.Unix(), utils.TimeToEpoch(now))
+	if err != nil {
+		return fmt.Errorf("error updating subscription of notification rule %v: %w", rule.ID, err)
+	}
+	return tx.Commit()
+}
+
+// DeleteUserNotificationRule deletes a rule of the user and its subscription.
+// Returns sql.ErrNoRows if the user has no such rule.
+func DeleteUserNotificationRule(userID, ruleID uint64) error {
+	tx, err := FrontendWriterDB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM users_notification_rules WHERE user_id = $1 AND id = $2`, userID, ruleID)
+	if err != nil {
+		return fmt.Errorf("error deleting notification rule %v: %w", ruleID, err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting notification rule %v: %w", ruleID, err)
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	_, err = tx.Exec(`DELETE FROM users_subscriptions WHERE user_id = $1 AND event_name = $2 AND event_filter = $3`,
+		userID, utils.GetNetwork()+":"+string(types.NotificationRuleEventName), strconv.FormatUint(ruleID, 10))
+	if err != nil {
+		return fmt.Errorf("error deleting subscription of notification rule %v: %w", ruleID, err)
+	}
+	return tx.Commit()
+}
+
+// GetUserValidatorGroups returns the validator tags of the user on the current network without the network prefix
+func GetUserValidatorGroups(userID uint64) ([]string, error) {
+	var tags []string
+	err := FrontendReaderDB.Select(&tags, `SELECT DISTINCT tag FROM users_validators_tags WHERE user_id = $1 AND tag LIKE $2 ORDER BY tag`, userID, utils.GetNetwork()+":%")
+	if err != nil {
+		return nil, fmt.Errorf("error getting validator tags of user %v: %w", userID, err)
+	}
+	groups := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		groups = append(groups, strings.TrimPrefix(tag, utils.GetNetwork()+":"))
+	}
+	return groups, nil
+}
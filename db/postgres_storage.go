@@ -0,0 +1,928 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	itypes "github.com/gobitfly/eth-rewards/types"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+)
+
+// machine metrics are kept as long as the bigtable machine metrics (see the max age of the machine_metrics table)
+const postgresMachineMetricsRetention = time.Hour * 24 * 31
+
+// PostgresStorage keeps the validator history, the eth1 index and the machine metrics in the explorer database.
+// It is meant for small networks and devnets that run the full explorer on a single box without bigtable.
+type PostgresStorage struct {
+	LastAttestationCache    map[uint64]uint64
+	LastAttestationCacheMux *sync.Mutex
+
+	chainId string
+}
+
+// InitPostgresStorage returns a storage on top of WriterDb and ReaderDb, which are accessed lazily as they might be initialized concurrently
+func InitPostgresStorage(chainId string) (*PostgresStorage, error) {
+	return &PostgresStorage{
+		LastAttestationCacheMux: &sync.Mutex{},
+		chainId:                 chainId,
+	}, nil
+}
+
+func (ps *PostgresStorage) Close() {
+}
+
+func (ps *PostgresStorage) SaveValidatorBalances(epoch uint64, validators []*types.Validator) error {
+	start := time.Now()
+
+	highestActiveIndex := uint64(0)
+	indices := make([]int64, 0, len(validators))
+	balances := make([]int64, 0, len(validators))
+	effectiveBalances := make([]int64, 0, len(validators))
+	for _, validator := range validators {
+		if validator.Balance > 0 && validator.Index > highestActiveIndex {
+			highestActiveIndex = validator.Index
+		}
+		indices = append(indices, int64(validator.Index))
+		balances = append(balances, int64(validator.Balance))
+		effectiveBalances = append(effectiveBalances, int64(validator.EffectiveBalance))
+	}
+
+	tx, err := WriterDb.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO validator_history_balances (validatorindex, epoch, balance, effectivebalance)
+		SELECT v, $1, b, e
+		FROM unnest($2::int[], $3::bigint[], $4::bigint[]) AS h(v, b, e)
+		ON CONFLICT (validatorindex, epoch) DO UPDATE SET balance = excluded.balance, effectivebalance = excluded.effectivebalance`,
+		epoch, pq.Int64Array(indices), pq.Int64Array(balances), pq.Int64Array(effectiveBalances))
+	if err != nil {
+		return fmt.Errorf("error saving validator balances of epoch %v: %w", epoch, err)
+	}
+
+	// store the highes active validator index for that epoch
+	_, err = tx.Exec(`
+		INSERT INTO validator_history_highest_active_index (epoch, validatorindex) VALUES ($1, $2)
+		ON CONFLICT (epoch) DO UPDATE SET validatorindex = excluded.validatorindex`,
+		epoch, highestActiveIndex)
+	if err != nil {
+		return fmt.Errorf("error saving highest active validator index of epoch %v: %w", epoch, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("exported %v validator balances to postgres in %v", len(validators), time.Since(start))
+	return nil
+}
+
+func (ps *PostgresStorage) SaveProposalAssignments(epoch uint64, assignments map[uint64]uint64) error {
+	start := time.Now()
+
+	validators := make([]int64, 0, len(assignments))
+	slots := make([]int64, 0, len(assignments))
+	for slot, validator := range assignments {
+		validators = append(validators, int64(validator))
+		slots = append(slots, int64(slot))
+	}
+
+	_, err := WriterDb.Exec(`
+		INSERT INTO validator_history_proposals (validatorindex, slot, proposed)
+		SELECT v, s, false
+		FROM unnest($1::int[], $2::int[]) AS h(v, s)
+		ON CONFLICT (validatorindex, slot) DO NOTHING`,
+		pq.Int64Array(validators), pq.Int64Array(slots))
+	if err != nil {
+		return fmt.Errorf("error saving proposal assignments of epoch %v: %w", epoch, err)
+	}
+
+	logger.Infof("exported proposal assignments to postgres in %v", time.Since(start))
+	return nil
+}
+
+// LastAttestationCacheInitialized returns true once the in memory last attestation slot cache has been populated
+func (ps *PostgresStorage) LastAttestationCacheInitialized() bool {
+	ps.LastAttestationCacheMux.Lock()
+	defer ps.LastAttestationCacheMux.Unlock()
+	return ps.LastAttestationCache != nil
+}
+
+// CachedLastAttestationSlot returns the last attestation slot of a validator from the in memory cache
+func (ps *PostgresStorage) CachedLastAttestationSlot(validator uint64) uint64 {
+	ps.LastAttestationCacheMux.Lock()
+	defer ps.LastAttestationCacheMux.Unlock()
+	return ps.LastAttestationCache[validator]
+}
+
+func (ps *PostgresStorage) SaveAttestationDuties(duties map[types.Slot]map[types.ValidatorIndex][]types.Slot) error {
+	// Initialize in memory last attestation cache lazily
+	ps.LastAttestationCacheMux.Lock()
+	defer ps.LastAttestationCacheMux.Unlock()
+	if ps.LastAttestationCache == nil {
+		t := time.Now()
+		var err error
+		ps.LastAttestationCache, err = ps.GetLastAttestationSlots([]uint64{})
+		if err != nil {
+			return err
+		}
+		logger.Infof("initialized in memory last attestation slot cache with %v validators in %v", len(ps.LastAttestationCache), time.Since(t))
+	}
+
+	start := time.Now()
+
+	validators := []int64{}
+	attesterSlots := []int64{}
+	inclusionSlots := []int64{}
+	lastAttestationSlots := make(map[uint64]uint64)
+	for attestedSlot, attestations := range duties {
+		for validator, inclusions := range attestations {
+			if len(inclusions) == 0 { // missed attestations are stored with an inclusion slot of 0
+				inclusions = append(inclusions, 0)
+			}
+			for _, inclusionSlot := range inclusions {
+				validators = append(validators, int64(validator))
+				attesterSlots = append(attesterSlots, int64(attestedSlot))
+				inclusionSlots = append(inclusionSlots, int64(inclusionSlot))
+
+				if inclusionSlot != 0 && uint64(attestedSlot) > ps.LastAttestationCache[uint64(validator)] && uint64(attestedSlot) > lastAttestationSlots[uint64(validator)] {
+					lastAttestationSlots[uint64(validator)] = uint64(attestedSlot)
+				}
+			}
+		}
+	}
+
+	tx, err := WriterDb.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO validator_history_attestations (validatorindex, attesterslot, inclusionslot)
+		SELECT v, a, i
+		FROM unnest($1::int[], $2::int[], $3::int[]) AS h(v, a, i)
+		ON CONFLICT (validatorindex, attesterslot, inclusionslot) DO NOTHING`,
+		pq.Int64Array(validators), pq.Int64Array(attesterSlots), pq.Int64Array(inclusionSlots))
+	if err != nil {
+		return fmt.Errorf("error writing attestation inclusion slots: %w", err)
+	}
+
+	if len(lastAttestationSlots) > 0 {
+		lastValidators := make([]int64, 0, len(lastAttestationSlots))
+		lastSlots := make([]int64, 0, len(lastAttestationSlots))
+		for validator, slot := range lastAttestationSlots {
+			lastValidators = append(lastValidators, int64(validator))
+			lastSlots = append(lastSlots, int64(slot))
+		}
+		err = saveLastAttestationSlots(tx, lastValidators, lastSlots)
+		if err != nil {
+			return fmt.Errorf("error writing last attestation slots: %w", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	for validator, slot := range lastAttestationSlots {
+		ps.LastAttestationCache[validator] = slot
+	}
+
+	logger.Infof("exported %v attestations to postgres in %v", len(validators), time.Since(start))
+	return nil
+}
+
+func saveLastAttestationSlots(db sqlExecer, validators, slots []int64) error {
+	_, err := db.Exec(`
+		INSERT INTO validator_history_last_attestation_slots (validatorindex, slot)
+		SELECT v, s
+		FROM unnest($1::int[], $2::int[]) AS h(v, s)
+		ON CONFLICT (validatorindex) DO UPDATE SET slot = GREATEST(validator_history_last_attestation_slots.slot, excluded.slot)`,
+		pq.Int64Array(validators), pq.Int64Array(slots))
+	return err
+}
+
+type sqlExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// This method is only to be used for migrating the last attestation slot and should not be used for any other purpose
+func (ps *PostgresStorage) SetLastAttestationSlot(validator uint64, lastAttestationSlot uint64) error {
+	return saveLastAttestationSlots(WriterDb, []int64{int64(validator)}, []int64{int64(lastAttestationSlot)})
+}
+
+func (ps *PostgresStorage) SaveProposal(block *types.Block) error {
+	if len(block.BlockRoot) != 32 { // skip dummy blocks
+		return nil
+	}
+
+	_, err := WriterDb.Exec(`
+		INSERT INTO validator_history_proposals (validatorindex, slot, proposed) VALUES ($1, $2, true)
+		ON CONFLICT (validatorindex, slot) DO UPDATE SET proposed = true`,
+		block.Proposer, block.Slot)
+	return err
+}
+
+func (ps *PostgresStorage) SaveSyncComitteeDuties(duties map[types.Slot]map[types.ValidatorIndex]bool) error {
+	start := time.Now()
+
+	if len(duties) == 0 {
+		logger.Infof("no sync duties to export")
+		return nil
+	}
+
+	validators := []int64{}
+	slots := []int64{}
+	participations := []bool{}
+	for slot, committee := range duties {
+		for validator, participated := range committee {
+			validators = append(validators, int64(validator))
+			slots = append(slots, int64(slot))
+			participations = append(participations, participated)
+		}
+	}
+
+	// a participation is never overwritten by a later export of the same slot that missed it
+	_, err := WriterDb.Exec(`
+		INSERT INTO validator_history_sync_duties (validatorindex, slot, participated)
+		SELECT v, s, p
+		FROM unnest($1::int[], $2::int[], $3::bool[]) AS h(v, s, p)
+		ON CONFLICT (validatorindex, slot) DO UPDATE SET participated = validator_history_sync_duties.participated OR excluded.participated`,
+		pq.Int64Array(validators), pq.Int64Array(slots), pq.BoolArray(participations))
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("exported %v sync committee duties to postgres in %v", len(validators), time.Since(start))
+	return nil
+}
+
+func (ps *PostgresStorage) GetLastAttestationSlots(validators []uint64) (map[uint64]uint64, error) {
+	rows := []struct {
+		ValidatorIndex uint64 `db:"validatorindex"`
+		Slot           uint64 `db:"slot"`
+	}{}
+
+	var err error
+	if len(validators) == 0 {
+		err = WriterDb.Select(&rows, `SELECT validatorindex, slot FROM validator_history_last_attestation_slots`)
+	} else {
+		err = WriterDb.Select(&rows, `SELECT validatorindex, slot FROM validator_history_last_attestation_slots WHERE validatorindex = ANY($1)`, pq.Array(validators))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[uint64]uint64, len(rows))
+	for _, row := range rows {
+		res[row.ValidatorIndex] = row.Slot
+	}
+	return res, nil
+}
+
+// GetMaxValidatorindexForEpoch returns the higest validatorindex with a balance at that epoch
+func (ps *PostgresStorage) GetMaxValidatorindexForEpoch(epoch uint64) (uint64, error) {
+	var validatorIndex uint64
+	err := ReaderDb.Get(&validatorIndex, `SELECT validatorindex FROM validator_history_highest_active_index WHERE epoch = $1`, epoch)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return validatorIndex, err
+}
+
+func (ps *PostgresStorage) GetValidatorBalanceHistory(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64][]*types.ValidatorBalance, error) {
+	tmr := time.AfterFunc(REPORT_TIMEOUT, func() {
+		logger.WithFields(logrus.Fields{
+			"validators_count": len(validators),
+			"startEpoch":       startEpoch,
+			"endEpoch":         endEpoch,
+		}).Warnf("%s call took longer than %v", utils.GetCurrentFuncName(), REPORT_TIMEOUT)
+	})
+	defer tmr.Stop()
+
+	if len(validators) == 0 {
+		return nil, fmt.Errorf("passing empty validator array is unsupported")
+	}
+
+	rows := []struct {
+		ValidatorIndex   uint64 `db:"validatorindex"`
+		Epoch            uint64 `db:"epoch"`
+		Balance          uint64 `db:"balance"`
+		EffectiveBalance uint64 `db:"effectivebalance"`
+	}{}
+	err := ReaderDb.Select(&rows, `
+		SELECT validatorindex, epoch, balance, effectivebalance
+		FROM validator_history_balances
+		WHERE validatorindex = ANY($1) AND epoch >= $2 AND epoch <= $3
+		ORDER BY validatorindex, epoch DESC`,
+		pq.Array(validators), startEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[uint64][]*types.ValidatorBalance, len(validators))
+	for _, row := range rows {
+		res[row.ValidatorIndex] = append(res[row.ValidatorIndex], &types.ValidatorBalance{
+			Epoch:            row.Epoch,
+			Balance:          row.Balance,
+			EffectiveBalance: row.EffectiveBalance,
+			Index:            row.ValidatorIndex,
+			PublicKey:        []byte{},
+		})
+	}
+	return res, nil
+}
+
+// getValidatorAttestationInclusions returns all stored inclusions of the attestations of the validators, missed attestations have a status of 0
+// the inclusions of an attestation are sorted by their inclusion slot with the missed one last, like in bigtable
+func (ps *PostgresStorage) getValidatorAttestationInclusions(validators []uint64, startEpoch uint64, endEpoch uint64) (map[types.ValidatorIndex]map[types.Slot][]*types.ValidatorAttestation, error) {
+	if len(validators) == 0 {
+		return nil, fmt.Errorf("passing empty validator array is unsupported")
+	}
+
+	rows := []struct {
+		ValidatorIndex uint64 `db:"validatorindex"`
+		AttesterSlot   uint64 `db:"attesterslot"`
+		InclusionSlot  uint64 `db:"inclusionslot"`
+	}{}
+	err := ReaderDb.Select(&rows, `
+		SELECT validatorindex, attesterslot, inclusionslot
+		FROM validator_history_attestations
+		WHERE validatorindex = ANY($1) AND attesterslot >= $2 AND attesterslot < $3
+		ORDER BY validatorindex, attesterslot, inclusionslot = 0, inclusionslot`,
+		pq.Array(validators), startEpoch*utils.Config.Chain.ClConfig.SlotsPerEpoch, (endEpoch+1)*utils.Config.Chain.ClConfig.SlotsPerEpoch)
+	if err != nil {
+		return nil, err
+	}
+
+	attestationsMap := make(map[types.ValidatorIndex]map[types.Slot][]*types.ValidatorAttestation)
+	for _, row := range rows {
+		status := uint64(1)
+		if row.InclusionSlot == 0 {
+			status = 0
+		}
+
+		validator := types.ValidatorIndex(row.ValidatorIndex)
+		if attestationsMap[validator] == nil {
+			attestationsMap[validator] = make(map[types.Slot][]*types.ValidatorAttestation)
+		}
+		attestationsMap[validator][types.Slot(row.AttesterSlot)] = append(attestationsMap[validator][types.Slot(row.AttesterSlot)], &types.ValidatorAttestation{
+			InclusionSlot: row.InclusionSlot,
+			Status:        status,
+		})
+	}
+	return attestationsMap, nil
+}
+
+func (ps *PostgresStorage) GetValidatorAttestationHistory(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64][]*types.ValidatorAttestation, error) {
+	tmr := time.AfterFunc(REPORT_TIMEOUT, func() {
+		logger.WithFields(logrus.Fields{
+			"validatorsCount": len(validators),
+			"startEpoch":      startEpoch,
+			"endEpoch":        endEpoch,
+		}).Warnf("%s call took longer than %v", utils.GetCurrentFuncName(), REPORT_TIMEOUT)
+	})
+	defer tmr.Stop()
+
+	attestationsMap, err := ps.getValidatorAttestationInclusions(validators, startEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+	return attestationHistoryFromInclusions(attestationsMap, startEpoch, endEpoch)
+}
+
+func (ps *PostgresStorage) GetValidatorMissedAttestationHistory(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64]map[uint64]bool, error) {
+	tmr := time.AfterFunc(REPORT_TIMEOUT, func() {
+		logger.WithFields(logrus.Fields{
+			"validatorsCount": len(validators),
+			"startEpoch":      startEpoch,
+			"endEpoch":        endEpoch,
+		}).Warnf("%s call took longer than %v", utils.GetCurrentFuncName(), REPORT_TIMEOUT)
+	})
+	defer tmr.Stop()
+
+	attestationsMap, err := ps.getValidatorAttestationInclusions(validators, startEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+
+	slots := []uint64{}
+	for slot := startEpoch * utils.Config.Chain.ClConfig.SlotsPerEpoch; slot < (endEpoch+1)*utils.Config.Chain.ClConfig.SlotsPerEpoch; slot++ {
+		slots = append(slots, slot)
+	}
+	orphanedSlotsMap, err := GetOrphanedSlotsMap(slots)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[uint64]map[uint64]bool)
+	for validator, attestations := range attestationsMap {
+		for attesterSlot, inclusions := range attestations {
+			// only if the attestation was not included in another slot we count it as missed
+			missed := true
+			for _, inclusion := range inclusions {
+				if inclusion.Status == 1 && !orphanedSlotsMap[inclusion.InclusionSlot] {
+					missed = false
+					break
+				}
+			}
+			if missed {
+				if res[uint64(validator)] == nil {
+					res[uint64(validator)] = make(map[uint64]bool)
+				}
+				res[uint64(validator)][uint64(attesterSlot)] = true
+			}
+		}
+	}
+	return res, nil
+}
+
+func (ps *PostgresStorage) GetValidatorSyncDutiesHistory(validators []uint64, startSlot uint64, endSlot uint64) (map[uint64]map[uint64]*types.ValidatorSyncParticipation, error) {
+	tmr := time.AfterFunc(REPORT_TIMEOUT, func() {
+		logger.WithFields(logrus.Fields{
+			"validatorsCount": len(validators),
+			"startSlot":       startSlot,
+			"endSlot":         endSlot,
+		}).Warnf("%s call took longer than %v", utils.GetCurrentFuncName(), REPORT_TIMEOUT)
+	})
+	defer tmr.Stop()
+
+	if len(validators) == 0 {
+		return nil, fmt.Errorf("passing empty validator array is unsupported")
+	}
+	if endSlot < startSlot { // handle overflows
+		startSlot = 0
+	}
+
+	rows := []struct {
+		ValidatorIndex uint64 `db:"validatorindex"`
+		Slot           uint64 `db:"slot"`
+		Participated   bool   `db:"participated"`
+	}{}
+	err := ReaderDb.Select(&rows, `
+		SELECT validatorindex, slot, participated
+		FROM validator_history_sync_duties
+		WHERE validatorindex = ANY($1) AND slot >= $2 AND slot <= $3`,
+		pq.Array(validators), startSlot, endSlot)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[uint64]map[uint64]*types.ValidatorSyncParticipation, len(validators))
+	for _, row := range rows {
+		status := uint64(0) // 0: missed
+		if row.Participated {
+			status = 1 // 1: participated
+		}
+		if res[row.ValidatorIndex] == nil {
+			res[row.ValidatorIndex] = make(map[uint64]*types.ValidatorSyncParticipation)
+		}
+		res[row.ValidatorIndex][row.Slot] = &types.ValidatorSyncParticipation{
+			Slot:   row.Slot,
+			Status: status,
+		}
+	}
+	return res, nil
+}
+
+// returns the validator attestation effectiveness in %
+func (ps *PostgresStorage) GetValidatorEffectiveness(validators []uint64, epoch uint64) ([]*types.ValidatorEffectiveness, error) {
+	end := epoch
+	start := uint64(0)
+	lookback := uint64(99)
+	if end > lookback {
+		start = end - lookback
+	}
+	data, err := ps.GetValidatorAttestationHistory(validators, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return validatorEffectivenessFromHistory(data), nil
+}
+
+func (ps *PostgresStorage) SaveValidatorIncomeDetails(epoch uint64, rewards map[uint64]*itypes.ValidatorEpochIncome) error {
+	start := time.Now()
+
+	total := &itypes.ValidatorEpochIncome{}
+
+	validators := make([]int64, 0, len(rewards))
+	details := make([][]byte, 0, len(rewards))
+	for i, rewardDetails := range rewards {
+		data, err := proto.Marshal(rewardDetails)
+		if err != nil {
+			return err
+		}
+		validators = append(validators, int64(i))
+		details = append(details, data)
+
+		addValidatorEpochIncome(total, rewardDetails)
+	}
+
+	sum, err := proto.Marshal(total)
+	if err != nil {
+		return err
+	}
+
+	tx, err := WriterDb.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO validator_history_income (validatorindex, epoch, details)
+		SELECT v, $1, d
+		FROM unnest($2::int[], $3::bytea[]) AS h(v, d)
+		ON CONFLICT (validatorindex, epoch) DO UPDATE SET details = excluded.details`,
+		epoch, pq.Int64Array(validators), pq.ByteaArray(details))
+	if err != nil {
+		return fmt.Errorf("error saving validator income details of epoch %v: %w", epoch, err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO validator_history_income_totals (epoch, details) VALUES ($1, $2)
+		ON CONFLICT (epoch) DO UPDATE SET details = excluded.details`,
+		epoch, sum)
+	if err != nil {
+		return fmt.Errorf("error saving total validator income details of epoch %v: %w", epoch, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("exported validator income details for epoch %v to postgres in %v", epoch, time.Since(start))
+	return nil
+}
+
+// GetValidatorIncomeDetailsHistory returns the validator income details
+// startEpoch & endEpoch are inclusive
+func (ps *PostgresStorage) GetValidatorIncomeDetailsHistory(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64]map[uint64]*itypes.ValidatorEpochIncome, error) {
+	tmr := time.AfterFunc(REPORT_TIMEOUT, func() {
+		logger.WithFields(logrus.Fields{
+			"validatorsCount": len(validators),
+			"startEpoch":      startEpoch,
+			"endEpoch":        endEpoch,
+		}).Warnf("%s call took longer than %v", utils.GetCurrentFuncName(), REPORT_TIMEOUT)
+	})
+	defer tmr.Stop()
+
+	if len(validators) == 0 {
+		return nil, fmt.Errorf("passing empty validator array is unsupported")
+	}
+	if startEpoch > endEpoch {
+		startEpoch = 0
+	}
+
+	rows := []struct {
+		ValidatorIndex uint64 `db:"validatorindex"`
+		Epoch          uint64 `db:"epoch"`
+		Details        []byte `db:"details"`
+	}{}
+	err := ReaderDb.Select(&rows, `
+		SELECT validatorindex, epoch, details
+		FROM validator_history_income
+		WHERE validatorindex = ANY($1) AND epoch >= $2 AND epoch <= $3`,
+		pq.Array(validators), startEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[uint64]map[uint64]*itypes.ValidatorEpochIncome, len(validators))
+	for _, row := range rows {
+		incomeDetails := &itypes.ValidatorEpochIncome{}
+		err = proto.Unmarshal(row.Details, incomeDetails)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding income data of validator %v at epoch %v: %w", row.ValidatorIndex, row.Epoch, err)
+		}
+		if res[row.ValidatorIndex] == nil {
+			res[row.ValidatorIndex] = make(map[uint64]*itypes.ValidatorEpochIncome)
+		}
+		res[row.ValidatorIndex][row.Epoch] = incomeDetails
+	}
+	return res, nil
+}
+
+func (ps *PostgresStorage) GetTotalValidatorIncomeDetailsHistory(startEpoch uint64, endEpoch uint64) (map[uint64]*itypes.ValidatorEpochIncome, error) {
+	tmr := time.AfterFunc(REPORT_TIMEOUT, func() {
+		logger.WithFields(logrus.Fields{
+			"startEpoch": startEpoch,
+			"endEpoch":   endEpoch,
+		}).Warnf("%s call took longer than %v", utils.GetCurrentFuncName(), REPORT_TIMEOUT)
+	})
+	defer tmr.Stop()
+
+	if startEpoch > endEpoch {
+		startEpoch = 0
+	}
+
+	rows := []struct {
+		Epoch   uint64 `db:"epoch"`
+		Details []byte `db:"details"`
+	}{}
+	err := ReaderDb.Select(&rows, `SELECT epoch, details FROM validator_history_income_totals WHERE epoch >= $1 AND epoch <= $2`, startEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[uint64]*itypes.ValidatorEpochIncome, len(rows))
+	for _, row := range rows {
+		incomeDetails := &itypes.ValidatorEpochIncome{}
+		err = proto.Unmarshal(row.Details, incomeDetails)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding total income data at epoch %v: %w", row.Epoch, err)
+		}
+		res[row.Epoch] = incomeDetails
+	}
+	return res, nil
+}
+
+// MigrateIncomeDataV1V2Schema only applies to the bigtable schema
+func (ps *PostgresStorage) MigrateIncomeDataV1V2Schema(epoch uint64) error {
+	return fmt.Errorf("migrating the income data schema is not supported by the postgres storage backend")
+}
+
+func (ps *PostgresStorage) SaveMachineMetric(process string, userID uint64, machine string, data []byte) error {
+	now := time.Now().UTC()
+
+	res, err := WriterDb.Exec(`
+		INSERT INTO machine_metrics_history (user_id, process, machine, ts, data) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT DO NOTHING`,
+		userID, process, machine, now, data)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("rate limit, last metric insert was less than 1 min ago")
+	}
+
+	_, err = WriterDb.Exec(`DELETE FROM machine_metrics_history WHERE user_id = $1 AND process = $2 AND machine = $3 AND ts < $4`,
+		userID, process, machine, now.Add(-postgresMachineMetricsRetention))
+	return err
+}
+
+// getMachineMetricNames returns the machines of a user that stored a metric in the last searchDepth minutes
+func (ps *PostgresStorage) getMachineMetricNames(userID uint64, searchDepth int) ([]string, error) {
+	names := []string{}
+	err := ReaderDb.Select(&names, `SELECT DISTINCT machine FROM machine_metrics_history WHERE user_id = $1 AND ts > $2`,
+		userID, time.Now().UTC().Add(time.Duration(searchDepth*-1)*time.Minute))
+	return names, err
+}
+
+func (ps *PostgresStorage) GetMachineMetricsMachineNames(userID uint64) ([]string, error) {
+	return ps.getMachineMetricNames(userID, 300)
+}
+
+func (ps *PostgresStorage) GetMachineMetricsMachineCount(userID uint64) (uint64, error) {
+	names, err := ps.getMachineMetricNames(userID, 15)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(len(names)), nil
+}
+
+func (ps *PostgresStorage) GetMachineMetricsNode(userID uint64, limit, offset int) ([]*types.MachineMetricNode, error) {
+	return getPostgresMachineMetrics(ps, "beaconnode", userID, limit, offset,
+		func(data []byte, machine string) *types.MachineMetricNode {
+			obj := &types.MachineMetricNode{}
+			err := proto.Unmarshal(data, obj)
+			if err != nil {
+				return nil
+			}
+			obj.Machine = &machine
+			return obj
+		},
+	)
+}
+
+func (ps *PostgresStorage) GetMachineMetricsValidator(userID uint64, limit, offset int) ([]*types.MachineMetricValidator, error) {
+	return getPostgresMachineMetrics(ps, "validator", userID, limit, offset,
+		func(data []byte, machine string) *types.MachineMetricValidator {
+			obj := &types.MachineMetricValidator{}
+			err := proto.Unmarshal(data, obj)
+			if err != nil {
+				return nil
+			}
+			obj.Machine = &machine
+			return obj
+		},
+	)
+}
+
+func (ps *PostgresStorage) GetMachineMetricsSystem(userID uint64, limit, offset int) ([]*types.MachineMetricSystem, error) {
+	return getPostgresMachineMetrics(ps, "system", userID, limit, offset,
+		func(data []byte, machine string) *types.MachineMetricSystem {
+			obj := &types.MachineMetricSystem{}
+			err := proto.Unmarshal(data, obj)
+			if err != nil {
+				return nil
+			}
+			obj.Machine = &machine
+			return obj
+		},
+	)
+}
+
+// getPostgresMachineMetrics returns the same selection of the latest metrics of every machine as getMachineMetrics does for bigtable
+func getPostgresMachineMetrics[T types.MachineMetricSystem | types.MachineMetricNode | types.MachineMetricValidator](ps *PostgresStorage, process string, userID uint64, limit, offset int, marshler func(data []byte, machine string) *T) ([]*T, error) {
+	res := make([]*T, 0)
+	if offset <= 0 {
+		offset = 1
+	}
+
+	rows := []struct {
+		Machine string `db:"machine"`
+		Data    []byte `db:"data"`
+	}{}
+	err := ReaderDb.Select(&rows, `
+		SELECT machine, data FROM (
+			SELECT machine, ts, data, row_number() OVER (PARTITION BY machine ORDER BY ts DESC) AS rn
+			FROM machine_metrics_history
+			WHERE user_id = $1 AND process = $2
+		) AS m
+		WHERE rn > $3 AND rn <= $4
+		ORDER BY machine, ts DESC`,
+		userID, process, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	gapSize := getMachineStatsGap(uint64(limit))
+	count := -1
+	for i, row := range rows {
+		if i == 0 || rows[i-1].Machine != row.Machine {
+			count = -1
+		}
+		count++
+		if count%gapSize != 0 {
+			continue
+		}
+
+		obj := marshler(row.Data, row.Machine)
+		if obj == nil {
+			break
+		}
+		res = append(res, obj)
+	}
+	return res, nil
+}
+
+func (ps *PostgresStorage) GetMachineRowKey(userID uint64, process string, machine string) string {
+	return fmt.Sprintf("%d:%s:%s", userID, process, machine)
+}
+
+// postgresMachineRowKeyParts splits a key returned by GetMachineRowKey into its user id, process and machine
+func postgresMachineRowKeyParts(rowKey string) (uint64, string, string, error) {
+	keySplit := strings.SplitN(rowKey, ":", 3)
+	if len(keySplit) != 3 {
+		return 0, "", "", fmt.Errorf("invalid machine metrics key %v", rowKey)
+	}
+	userID, err := strconv.ParseUint(keySplit[0], 10, 64)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("error parsing user id from machine metrics key %v: %w", rowKey, err)
+	}
+	return userID, keySplit[1], keySplit[2], nil
+}
+
+type postgresMachineMetric struct {
+	UserID  uint64    `db:"user_id"`
+	Machine string    `db:"machine"`
+	Ts      time.Time `db:"ts"`
+	Data    []byte    `db:"data"`
+	Rn      int       `db:"rn"`
+}
+
+// getLatestMachineMetrics returns the latest limit metrics of the machines of the keys, newest first
+func (ps *PostgresStorage) getLatestMachineMetrics(rowKeys []string, limit int) ([]postgresMachineMetric, error) {
+	userIDs := make([]int64, 0, len(rowKeys))
+	processes := make([]string, 0, len(rowKeys))
+	machines := make([]string, 0, len(rowKeys))
+	for _, rowKey := range rowKeys {
+		userID, process, machine, err := postgresMachineRowKeyParts(rowKey)
+		if err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, int64(userID))
+		processes = append(processes, process)
+		machines = append(machines, machine)
+	}
+
+	rows := []postgresMachineMetric{}
+	err := ReaderDb.Select(&rows, `
+		SELECT user_id, machine, ts, data, rn FROM (
+			SELECT m.user_id, m.machine, m.ts, m.data, row_number() OVER (PARTITION BY m.user_id, m.process, m.machine ORDER BY m.ts DESC) AS rn
+			FROM machine_metrics_history m
+			INNER JOIN unnest($1::int[], $2::text[], $3::text[]) AS k(user_id, process, machine)
+				ON m.user_id = k.user_id AND m.process = k.process AND m.machine = k.machine
+		) AS m
+		WHERE rn <= $4
+		ORDER BY user_id, machine, rn`,
+		pq.Int64Array(userIDs), pq.StringArray(processes), pq.StringArray(machines), limit)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Returns a map[userID]map[machineName]machineData
+// machineData contains the latest machine data in CurrentData
+// and 5 minute old data in fiveMinuteOldData (defined in limit)
+// as well as the insert timestamps of both
+func (ps *PostgresStorage) GetMachineMetricsForNotifications(rowKeys []string) (map[uint64]map[string]*types.MachineMetricSystemUser, error) {
+	tmr := time.AfterFunc(REPORT_TIMEOUT, func() {
+		logger.WithFields(logrus.Fields{
+			"rowKeys": rowKeys,
+		}).Warnf("%s call took longer than %v", utils.GetCurrentFuncName(), REPORT_TIMEOUT)
+	})
+	defer tmr.Stop()
+
+	limit := 5
+
+	rows, err := ps.getLatestMachineMetrics(rowKeys, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[uint64]map[string]*types.MachineMetricSystemUser) // userID -> machine -> data
+	for _, row := range rows {
+		obj := &types.MachineMetricSystem{}
+		err := proto.Unmarshal(row.Data, obj)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, found := res[row.UserID]; !found {
+			res[row.UserID] = make(map[string]*types.MachineMetricSystemUser)
+		}
+
+		if row.Rn == 1 {
+			res[row.UserID][row.Machine] = &types.MachineMetricSystemUser{
+				UserID:              row.UserID,
+				Machine:             row.Machine,
+				CurrentData:         obj,
+				CurrentDataInsertTs: row.Ts.Unix(),
+			}
+		} else if row.Rn == limit {
+			res[row.UserID][row.Machine].FiveMinuteOldData = obj
+			res[row.UserID][row.Machine].FiveMinuteOldDataInsertTs = row.Ts.Unix()
+		}
+	}
+	return res, nil
+}
+
+// Returns a map[userID]map[machineName]machineData of the beacon node process
+// machineData contains the latest beacon node data in CurrentData as well as its insert timestamp
+func (ps *PostgresStorage) GetMachineNodeMetricsForNotifications(rowKeys []string) (map[uint64]map[string]*types.MachineMetricNodeUser, error) {
+	tmr := time.AfterFunc(REPORT_TIMEOUT, func() {
+		logger.WithFields(logrus.Fields{
+			"rowKeys": rowKeys,
+		}).Warnf("%s call took longer than %v", utils.GetCurrentFuncName(), REPORT_TIMEOUT)
+	})
+	defer tmr.Stop()
+
+	rows, err := ps.getLatestMachineMetrics(rowKeys, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[uint64]map[string]*types.MachineMetricNodeUser) // userID -> machine -> data
+	for _, row := range rows {
+		obj := &types.MachineMetricNode{}
+		err := proto.Unmarshal(row.Data, obj)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, found := res[row.UserID]; !found {
+			res[row.UserID] = make(map[string]*types.MachineMetricNodeUser)
+		}
+		res[row.UserID][row.Machine] = &types.MachineMetricNodeUser{
+			UserID:              row.UserID,
+			Machine:             row.Machine,
+			CurrentData:         obj,
+			CurrentDataInsertTs: row.Ts.Unix(),
+		}
+	}
+	return res, nil
+}
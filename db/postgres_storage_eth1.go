@@ -0,0 +1,894 @@
+package db
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/cache"
+	"github.com/gobitfly/eth2-beaconchain-explorer/metrics"
+	"github.com/gobitfly/eth2-beaconchain-explorer/rpc"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+)
+
+// SaveBlock stores the block together with its indexed transactions, uncles and contract updates.
+// The postgres storage indexes blocks while saving them, so there is no separate data table to transform into.
+func (ps *PostgresStorage) SaveBlock(block *types.Eth1Block) error {
+	startTime := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("pg_save_block").Observe(time.Since(startTime).Seconds())
+	}()
+
+	encodedBlock, err := proto.Marshal(block)
+	if err != nil {
+		return err
+	}
+	encodedIndexed, err := proto.Marshal(indexedEth1Block(block))
+	if err != nil {
+		return err
+	}
+
+	tx, err := WriterDb.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// a block that is saved again replaces everything that has been indexed for its number
+	err = deleteEth1HistoryBlock(tx, block.GetNumber())
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT INTO eth1_history_blocks (number, hash, coinbase, time, data, indexed) VALUES ($1, $2, $3, $4, $5, $6)`,
+		block.GetNumber(), block.GetHash(), block.GetCoinbase(), block.GetTime().AsTime().UTC(), encodedBlock, encodedIndexed)
+	if err != nil {
+		return fmt.Errorf("error saving block %v: %w", block.GetNumber(), err)
+	}
+
+	hashes := make([][]byte, 0, len(block.GetTransactions()))
+	txIndices := make([]int64, 0, len(block.GetTransactions()))
+	txData := make([][]byte, 0, len(block.GetTransactions()))
+	blobData := make([][]byte, 0, len(block.GetTransactions()))
+	addresses := make([][]byte, 0, len(block.GetTransactions())*2)
+	addressTxIndices := make([]int64, 0, len(block.GetTransactions())*2)
+	addressHashes := make([][]byte, 0, len(block.GetTransactions())*2)
+	for i, t := range block.GetTransactions() {
+		indexedTx := indexedEth1Transaction(block, t)
+		b, err := proto.Marshal(indexedTx)
+		if err != nil {
+			return err
+		}
+		var blob []byte
+		if t.Type == 3 {
+			blob, err = proto.Marshal(indexedEth1BlobTransaction(block, t))
+			if err != nil {
+				return err
+			}
+		}
+		hashes = append(hashes, t.GetHash())
+		txIndices = append(txIndices, int64(i))
+		txData = append(txData, b)
+		blobData = append(blobData, blob)
+
+		for _, address := range [][]byte{indexedTx.From, indexedTx.To} {
+			if len(address) == 0 {
+				continue
+			}
+			addresses = append(addresses, address)
+			addressTxIndices = append(addressTxIndices, int64(i))
+			addressHashes = append(addressHashes, t.GetHash())
+		}
+	}
+
+	if len(hashes) > 0 {
+		_, err = tx.Exec(`
+			INSERT INTO eth1_history_transactions (hash, blocknumber, txindex, data, blobdata)
+			SELECT h, $1, i, d, b
+			FROM unnest($2::bytea[], $3::int[], $4::bytea[], $5::bytea[]) AS t(h, i, d, b)
+			ON CONFLICT (hash) DO UPDATE SET blocknumber = excluded.blocknumber, txindex = excluded.txindex, data = excluded.data, blobdata = excluded.blobdata`,
+			block.GetNumber(), pq.ByteaArray(hashes), pq.Int64Array(txIndices), pq.ByteaArray(txData), pq.ByteaArray(blobData))
+		if err != nil {
+			return fmt.Errorf("error saving transactions of block %v: %w", block.GetNumber(), err)
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO eth1_history_address_transactions (address, blocknumber, txindex, hash)
+			SELECT a, $1, i, h
+			FROM unnest($2::bytea[], $3::int[], $4::bytea[]) AS t(a, i, h)
+			ON CONFLICT (address, blocknumber, txindex) DO NOTHING`,
+			block.GetNumber(), pq.ByteaArray(addresses), pq.Int64Array(addressTxIndices), pq.ByteaArray(addressHashes))
+		if err != nil {
+			return fmt.Errorf("error saving address transactions of block %v: %w", block.GetNumber(), err)
+		}
+	}
+
+	for i, uncle := range block.GetUncles() {
+		b, err := proto.Marshal(indexedEth1Uncle(block, uncle))
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO eth1_history_uncles (blocknumber, uncleindex, miner, data) VALUES ($1, $2, $3, $4)`,
+			block.GetNumber(), i, uncle.GetCoinbase(), b)
+		if err != nil {
+			return fmt.Errorf("error saving uncle %v of block %v: %w", i, block.GetNumber(), err)
+		}
+	}
+
+	updates, err := blockContractUpdates(block)
+	if err != nil {
+		return err
+	}
+	for _, u := range updates {
+		_, err = tx.Exec(`
+			INSERT INTO eth1_history_contract_updates (address, blocknumber, txindex, traceindex, iscontract, success) VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (address, blocknumber, txindex, traceindex) DO UPDATE SET iscontract = excluded.iscontract, success = excluded.success`,
+			u.address, block.GetNumber(), u.txIdx, u.traceIdx, u.update.IsContract, u.update.Success)
+		if err != nil {
+			return fmt.Errorf("error saving contract updates of block %v: %w", block.GetNumber(), err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func deleteEth1HistoryBlock(tx *sqlx.Tx, number uint64) error {
+	for _, table := range []string{"eth1_history_transactions", "eth1_history_address_transactions", "eth1_history_uncles", "eth1_history_contract_updates"} {
+		_, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE blocknumber = $1`, table), number)
+		if err != nil {
+			return fmt.Errorf("error deleting block %v from %v: %w", number, table, err)
+		}
+	}
+	_, err := tx.Exec(`DELETE FROM eth1_history_blocks WHERE number = $1`, number)
+	if err != nil {
+		return fmt.Errorf("error deleting block %v: %w", number, err)
+	}
+	return nil
+}
+
+// DeleteBlock removes the block and everything that has been indexed for it, if the stored block has the given hash
+func (ps *PostgresStorage) DeleteBlock(blockNumber uint64, blockHash []byte) error {
+	tx, err := WriterDb.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var found bool
+	err = tx.Get(&found, `SELECT EXISTS (SELECT 1 FROM eth1_history_blocks WHERE number = $1 AND hash = $2)`, blockNumber, blockHash)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+
+	err = deleteEth1HistoryBlock(tx, blockNumber)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (ps *PostgresStorage) GetBlockFromBlocksTable(number uint64) (*types.Eth1Block, error) {
+	var data []byte
+	err := ReaderDb.Get(&data, `SELECT data FROM eth1_history_blocks WHERE number = $1`, number)
+	if err == sql.ErrNoRows {
+		logger.WithFields(logrus.Fields{"block": number}).Warnf("block not found in block table")
+		return nil, ErrBlockNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bc := &types.Eth1Block{}
+	err = proto.Unmarshal(data, bc)
+	if err != nil {
+		return nil, err
+	}
+	return bc, nil
+}
+
+func (ps *PostgresStorage) getLastBlock() (int, error) {
+	var lastBlock int
+	err := ReaderDb.Get(&lastBlock, `SELECT COALESCE(MAX(number), 0) FROM eth1_history_blocks`)
+	return lastBlock, err
+}
+
+func (ps *PostgresStorage) GetLastBlockInBlocksTable() (int, error) {
+	return ps.getLastBlock()
+}
+
+// GetLastBlockInDataTable returns the last block in the blocks table, as blocks are indexed while saving them
+func (ps *PostgresStorage) GetLastBlockInDataTable() (int, error) {
+	return ps.getLastBlock()
+}
+
+// SetLastBlockInBlocksTable is a no-op, the last block is read from the blocks table
+func (ps *PostgresStorage) SetLastBlockInBlocksTable(lastBlock int64) error {
+	return nil
+}
+
+// SetLastBlockInDataTable is a no-op, the last block is read from the blocks table
+func (ps *PostgresStorage) SetLastBlockInDataTable(lastBlock int64) error {
+	return nil
+}
+
+func unmarshalIndexedEth1Blocks(rows [][]byte) ([]*types.Eth1BlockIndexed, error) {
+	blocks := make([]*types.Eth1BlockIndexed, 0, len(rows))
+	for _, row := range rows {
+		block := &types.Eth1BlockIndexed{}
+		err := proto.Unmarshal(row, block)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+func (ps *PostgresStorage) GetMostRecentBlockFromDataTable() (*types.Eth1BlockIndexed, error) {
+	var data []byte
+	err := ReaderDb.Get(&data, `SELECT indexed FROM eth1_history_blocks ORDER BY number DESC LIMIT 1`)
+	if err == sql.ErrNoRows {
+		return &types.Eth1BlockIndexed{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	block := &types.Eth1BlockIndexed{}
+	err = proto.Unmarshal(data, block)
+	if err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// GetBlocksDescending gets a given amount of Eth1BlockIndexed starting at block start
+func (ps *PostgresStorage) GetBlocksDescending(start, limit uint64) ([]*types.Eth1BlockIndexed, error) {
+	if limit == 0 {
+		return nil, fmt.Errorf("error limit is set to 0 which would not fetch any blocks")
+	}
+
+	// clamp limit
+	if limit > start+1 {
+		limit = start + 1
+	}
+
+	rows := [][]byte{}
+	err := ReaderDb.Select(&rows, `SELECT indexed FROM eth1_history_blocks WHERE number <= $1 ORDER BY number DESC LIMIT $2`, start, limit)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalIndexedEth1Blocks(rows)
+}
+
+// GetFullBlocksDescending streams blocks ranging from high to low (both borders are inclusive) in descending order via a channel.
+func (ps *PostgresStorage) GetFullBlocksDescending(stream chan<- *types.Eth1Block, high, low uint64) error {
+	if high < low {
+		return fmt.Errorf("invalid block range provided (high: %v, low: %v)", high, low)
+	}
+
+	rows, err := ReaderDb.Query(`SELECT data FROM eth1_history_blocks WHERE number <= $1 AND number >= $2 ORDER BY number DESC`, high, low)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data []byte
+		err = rows.Scan(&data)
+		if err != nil {
+			return err
+		}
+		block := &types.Eth1Block{}
+		err = proto.Unmarshal(data, block)
+		if err != nil {
+			return err
+		}
+		stream <- block
+	}
+	return rows.Err()
+}
+
+func (ps *PostgresStorage) GetBlocksIndexedMultiple(blockNumbers []uint64, limit uint64) ([]*types.Eth1BlockIndexed, error) {
+	rows := [][]byte{}
+	err := ReaderDb.Select(&rows, `SELECT indexed FROM eth1_history_blocks WHERE number = ANY($1) ORDER BY number DESC LIMIT $2`, pq.Array(blockNumbers), limit)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalIndexedEth1Blocks(rows)
+}
+
+func (ps *PostgresStorage) GetIndexedEth1Transaction(txHash []byte) (*types.Eth1TransactionIndexed, error) {
+	var data []byte
+	err := ReaderDb.Get(&data, `SELECT data FROM eth1_history_transactions WHERE hash = $1`, txHash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	indexedTx := &types.Eth1TransactionIndexed{}
+	err = proto.Unmarshal(data, indexedTx)
+	if err != nil {
+		return nil, err
+	}
+	return indexedTx, nil
+}
+
+// parsePostgresPageToken parses a page token of the address tables, which is the position of the last returned row
+func parsePostgresPageToken(pageToken string) (int64, int64, error) {
+	if pageToken == "" {
+		return -1, -1, nil
+	}
+	parts := strings.Split(pageToken, ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid pageToken %v", pageToken)
+	}
+	blockNumber, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid pageToken %v: %w", pageToken, err)
+	}
+	index, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid pageToken %v: %w", pageToken, err)
+	}
+	return blockNumber, index, nil
+}
+
+type postgresAddressTransaction struct {
+	BlockNumber int64  `db:"blocknumber"`
+	TxIndex     int64  `db:"txindex"`
+	Data        []byte `db:"data"`
+}
+
+// getAddressTransactions returns a page of the transactions of an address, most recent first
+func (ps *PostgresStorage) getAddressTransactions(address []byte, pageToken string, blobsOnly bool) ([]postgresAddressTransaction, string, error) {
+	blockNumber, txIndex, err := parsePostgresPageToken(pageToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	column := "t.data"
+	if blobsOnly {
+		column = "t.blobdata"
+	}
+
+	rows := []postgresAddressTransaction{}
+	err = ReaderDb.Select(&rows, fmt.Sprintf(`
+		SELECT a.blocknumber, a.txindex, %[1]s AS data
+		FROM eth1_history_address_transactions a
+		INNER JOIN eth1_history_transactions t ON t.hash = a.hash
+		WHERE a.address = $1 AND ($2 < 0 OR (a.blocknumber, a.txindex) < ($2, $3)) AND %[1]s IS NOT NULL
+		ORDER BY a.blocknumber DESC, a.txindex DESC
+		LIMIT $4`, column),
+		address, blockNumber, txIndex, DefaultInfScrollRows)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token := ""
+	if len(rows) == DefaultInfScrollRows {
+		last := rows[len(rows)-1]
+		token = fmt.Sprintf("%d:%d", last.BlockNumber, last.TxIndex)
+	}
+	return rows, token, nil
+}
+
+func (ps *PostgresStorage) GetAddressTransactionsTableData(address []byte, pageToken string) (*types.DataTableResponse, error) {
+	tmr := time.AfterFunc(REPORT_TIMEOUT, func() {
+		logger.WithFields(logrus.Fields{
+			"address":   address,
+			"pageToken": pageToken,
+		}).Warnf("%s call took longer than %v", utils.GetCurrentFuncName(), REPORT_TIMEOUT)
+	})
+	defer tmr.Stop()
+
+	rows, token, err := ps.getAddressTransactions(address, pageToken, false)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*types.Eth1TransactionIndexed, 0, len(rows))
+	idxs := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		t := &types.Eth1TransactionIndexed{}
+		err = proto.Unmarshal(row.Data, t)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing Eth1TransactionIndexed data: %w", err)
+		}
+		transactions = append(transactions, t)
+		idxs = append(idxs, row.TxIndex)
+	}
+
+	tableData, err := addressTransactionsTableRows(ps, address, transactions, idxs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.DataTableResponse{
+		Data:        tableData,
+		PagingToken: token,
+	}, nil
+}
+
+func (ps *PostgresStorage) GetAddressBlobTableData(address []byte, pageToken string) (*types.DataTableResponse, error) {
+	tmr := time.AfterFunc(REPORT_TIMEOUT, func() {
+		logger.WithFields(logrus.Fields{
+			"address":   address,
+			"pageToken": pageToken,
+		}).Warnf("%s call took longer than %v", utils.GetCurrentFuncName(), REPORT_TIMEOUT)
+	})
+	defer tmr.Stop()
+
+	rows, token, err := ps.getAddressTransactions(address, pageToken, true)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*types.Eth1BlobTransactionIndexed, 0, len(rows))
+	for _, row := range rows {
+		t := &types.Eth1BlobTransactionIndexed{}
+		err = proto.Unmarshal(row.Data, t)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing Eth1BlobTransactionIndexed data: %w", err)
+		}
+		transactions = append(transactions, t)
+	}
+
+	tableData, err := addressBlobTableRows(ps, address, transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.DataTableResponse{
+		Data:        tableData,
+		PagingToken: token,
+	}, nil
+}
+
+func (ps *PostgresStorage) GetAddressBlocksMinedTableData(address string, pageToken string) (*types.DataTableResponse, error) {
+	blockNumber := int64(-1)
+	if pageToken != "" {
+		var err error
+		blockNumber, err = strconv.ParseInt(pageToken, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pageToken for function GetAddressBlocksMinedTableData: %s", pageToken)
+		}
+	}
+
+	rows := []struct {
+		Number  int64  `db:"number"`
+		Indexed []byte `db:"indexed"`
+	}{}
+	err := ReaderDb.Select(&rows, `
+		SELECT number, indexed
+		FROM eth1_history_blocks
+		WHERE coinbase = $1 AND ($2 < 0 OR number < $2)
+		ORDER BY number DESC
+		LIMIT $3`,
+		common.FromHex(address), blockNumber, DefaultInfScrollRows)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make([]*types.Eth1BlockIndexed, 0, len(rows))
+	for _, row := range rows {
+		b := &types.Eth1BlockIndexed{}
+		err = proto.Unmarshal(row.Indexed, b)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing Eth1BlockIndexed data: %w", err)
+		}
+		blocks = append(blocks, b)
+	}
+
+	token := ""
+	if len(rows) == DefaultInfScrollRows {
+		token = fmt.Sprintf("%d", rows[len(rows)-1].Number)
+	}
+
+	return &types.DataTableResponse{
+		Data:        blocksMinedTableRows(blocks),
+		PagingToken: token,
+	}, nil
+}
+
+func (ps *PostgresStorage) GetAddressUnclesMinedTableData(address string, pageToken string) (*types.DataTableResponse, error) {
+	blockNumber, uncleIndex, err := parsePostgresPageToken(pageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []struct {
+		BlockNumber int64  `db:"blocknumber"`
+		UncleIndex  int64  `db:"uncleindex"`
+		Data        []byte `db:"data"`
+	}{}
+	err = ReaderDb.Select(&rows, `
+		SELECT blocknumber, uncleindex, data
+		FROM eth1_history_uncles
+		WHERE miner = $1 AND ($2 < 0 OR (blocknumber, uncleindex) < ($2, $3))
+		ORDER BY blocknumber DESC, uncleindex DESC
+		LIMIT $4`,
+		common.FromHex(address), blockNumber, uncleIndex, DefaultInfScrollRows)
+	if err != nil {
+		return nil, err
+	}
+
+	uncles := make([]*types.Eth1UncleIndexed, 0, len(rows))
+	for _, row := range rows {
+		u := &types.Eth1UncleIndexed{}
+		err = proto.Unmarshal(row.Data, u)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing Eth1UncleIndexed data: %w", err)
+		}
+		uncles = append(uncles, u)
+	}
+
+	token := ""
+	if len(rows) == DefaultInfScrollRows {
+		last := rows[len(rows)-1]
+		token = fmt.Sprintf("%d:%d", last.BlockNumber, last.UncleIndex)
+	}
+
+	return &types.DataTableResponse{
+		Data:        unclesMinedTableRows(uncles),
+		PagingToken: token,
+	}, nil
+}
+
+// the postgres storage does not index internal transactions and token transfers
+func (ps *PostgresStorage) GetAddressInternalTableData(address []byte, pageToken string) (*types.DataTableResponse, error) {
+	return &types.DataTableResponse{Data: [][]interface{}{}}, nil
+}
+
+func (ps *PostgresStorage) GetAddressErc20TableData(address []byte, pageToken string) (*types.DataTableResponse, error) {
+	return &types.DataTableResponse{Data: [][]interface{}{}}, nil
+}
+
+func (ps *PostgresStorage) GetAddressErc721TableData(address []byte, pageToken string) (*types.DataTableResponse, error) {
+	return &types.DataTableResponse{Data: [][]interface{}{}}, nil
+}
+
+func (ps *PostgresStorage) GetAddressErc1155TableData(address []byte, pageToken string) (*types.DataTableResponse, error) {
+	return &types.DataTableResponse{Data: [][]interface{}{}}, nil
+}
+
+func (ps *PostgresStorage) GetTokenTransactionsTableData(token []byte, address []byte, pageToken string) (*types.DataTableResponse, error) {
+	return &types.DataTableResponse{Data: [][]interface{}{}}, nil
+}
+
+func (ps *PostgresStorage) GetArbitraryTokenTransfersForTransaction(transaction []byte) ([]*types.Transfer, error) {
+	return []*types.Transfer{}, nil
+}
+
+func (ps *PostgresStorage) GetInternalTransfersForTransaction(transaction []byte, from []byte, parityTrace []*rpc.ParityTraceResult, currency string) ([]types.ITransaction, error) {
+	names := make(map[string]string)
+	for _, trace := range parityTrace {
+		from, to, _, _ := trace.ConvertFields()
+		names[string(from)] = ""
+		names[string(to)] = ""
+	}
+
+	err := GetEnsNamesForAddress(names)
+	if err != nil {
+		return nil, err
+	}
+
+	return internalTransfersFromParityTrace(ps, names, parityTrace, currency)
+}
+
+// returns account state after the given execution state
+// -1 is latest (e.g. "txIdx" = -1 returns the contract state after execution of "block", "block" = -1 returns the state at chain head)
+func (ps *PostgresStorage) GetAddressContractInteractionsAt(requests []contractInteractionAtRequest) ([]types.ContractInteractionType, error) {
+	if len(requests) == 0 {
+		return make([]types.ContractInteractionType, 0), nil
+	}
+
+	addresses := make([][]byte, 0, len(requests))
+	for _, request := range requests {
+		addresses = append(addresses, common.FromHex(request.address))
+	}
+
+	rows := []struct {
+		Address     []byte `db:"address"`
+		BlockNumber uint64 `db:"blocknumber"`
+		TxIndex     uint64 `db:"txindex"`
+		TraceIndex  uint64 `db:"traceindex"`
+		IsContract  bool   `db:"iscontract"`
+		Success     bool   `db:"success"`
+	}{}
+	err := ReaderDb.Select(&rows, `
+		SELECT address, blocknumber, txindex, traceindex, iscontract, success
+		FROM eth1_history_contract_updates
+		WHERE address = ANY($1)`,
+		pq.ByteaArray(addresses))
+	if err != nil {
+		return nil, fmt.Errorf("error reading isContract histories from postgres: %w", err)
+	}
+
+	histories := make(map[string][]isContractInfo, len(requests))
+	for _, row := range rows {
+		ts, err := encodeIsContractUpdateTs(row.BlockNumber, row.TxIndex, row.TraceIndex)
+		if err != nil {
+			return nil, err
+		}
+		address := fmt.Sprintf("%x", row.Address)
+		histories[address] = append(histories[address], isContractInfo{
+			update: &types.IsContractUpdate{IsContract: row.IsContract, Success: row.Success},
+			ts:     ts,
+		})
+	}
+	for _, history := range histories {
+		sort.Slice(history, func(i, j int) bool {
+			return history[i].ts > history[j].ts
+		})
+	}
+
+	return contractInteractionsFromHistories(requests, histories)
+}
+
+// convenience function to get contract interaction status per transaction of a block
+func (ps *PostgresStorage) GetAddressContractInteractionsAtBlock(block *types.Eth1Block) ([]types.ContractInteractionType, error) {
+	return contractInteractionsAtBlock(ps, block)
+}
+
+// convenience function to get contract interaction status per subtransaction of a transaction
+func (ps *PostgresStorage) GetAddressContractInteractionsAtITransactions(itransactions []*types.Eth1InternalTransactionIndexed, idxs [][2]int64) ([][2]types.ContractInteractionType, error) {
+	return contractInteractionsAtITransactions(ps, itransactions, idxs)
+}
+
+// convenience function to get contract interaction status per parity trace
+func (ps *PostgresStorage) GetAddressContractInteractionsAtParityTraces(traces []*rpc.ParityTraceResult) ([][2]types.ContractInteractionType, error) {
+	return contractInteractionsAtParityTraces(ps, traces)
+}
+
+// convenience function to get contract interaction status per transaction
+func (ps *PostgresStorage) GetAddressContractInteractionsAtTransactions(transactions []*types.Eth1TransactionIndexed, idxs []int64) ([]types.ContractInteractionType, error) {
+	return contractInteractionsAtTransactions(ps, transactions, idxs)
+}
+
+// SearchForAddress returns the indexed addresses starting with the prefix
+func (ps *PostgresStorage) SearchForAddress(addressPrefix []byte, limit int) ([]*types.Eth1AddressSearchItem, error) {
+	addresses := [][]byte{}
+	err := ReaderDb.Select(&addresses, `
+		SELECT DISTINCT address
+		FROM eth1_history_address_transactions
+		WHERE address >= $1
+		ORDER BY address
+		LIMIT $2`,
+		addressPrefix, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(addresses))
+	for _, address := range addresses {
+		names[string(address)] = ""
+	}
+	err = GetEnsNamesForAddress(names)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]*types.Eth1AddressSearchItem, 0, len(addresses))
+	for _, address := range addresses {
+		if !bytes.HasPrefix(address, addressPrefix) {
+			break
+		}
+		data = append(data, &types.Eth1AddressSearchItem{
+			Address: fmt.Sprintf("%x", address),
+			Name:    names[string(address)],
+			Token:   "",
+		})
+	}
+	return data, nil
+}
+
+// GetMetadataForAddress returns the name of the address, the postgres storage does not index balances and tokens
+func (ps *PostgresStorage) GetMetadataForAddress(address []byte, offset uint64, limit uint64) (*types.Eth1AddressMetadata, error) {
+	name, err := ps.GetAddressName(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Eth1AddressMetadata{
+		Balances: []*types.Eth1AddressBalance{},
+		ERC20:    &types.ERC20Metadata{},
+		Name:     name,
+		EthBalance: &types.Eth1AddressBalance{
+			Metadata: &types.ERC20Metadata{},
+		},
+		ERC20TokenLimit: ECR20TokensPerAddressLimit,
+	}, nil
+}
+
+func (ps *PostgresStorage) GetBalanceForAddress(address []byte, token []byte) (*types.Eth1AddressBalance, error) {
+	return nil, nil
+}
+
+func (ps *PostgresStorage) GetContractMetadata(address []byte) (*types.ContractMetadata, error) {
+	return nil, nil
+}
+
+func (ps *PostgresStorage) GetERC20MetadataForAddress(address []byte) (*types.ERC20Metadata, error) {
+	if len(address) == 1 {
+		return &types.ERC20Metadata{
+			Decimals:    big.NewInt(18).Bytes(),
+			Symbol:      utils.Config.Frontend.ElCurrency,
+			TotalSupply: []byte{},
+		}, nil
+	}
+
+	cacheKey := fmt.Sprintf("%s:ERC20:%#x", ps.chainId, address)
+	if cached, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, time.Hour*1, new(types.ERC20Metadata)); err == nil {
+		return cached.(*types.ERC20Metadata), nil
+	}
+
+	return erc20MetadataViaRpc(address, cacheKey)
+}
+
+func (ps *PostgresStorage) GetAddressName(address []byte) (string, error) {
+	name, err := GetEnsNameForAddress(common.BytesToAddress(address))
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+	return name, nil
+}
+
+func (ps *PostgresStorage) GetAddressesNamesArMetadata(names *map[string]string, inputMetadata *map[string]*types.ERC20Metadata) (map[string]string, map[string]*types.ERC20Metadata, error) {
+	outputMetadata := make(map[string]*types.ERC20Metadata)
+
+	g := new(errgroup.Group)
+	g.SetLimit(25)
+	mux := sync.Mutex{}
+
+	if names != nil {
+		g.Go(func() error {
+			return GetEnsNamesForAddress(*names)
+		})
+	}
+
+	if inputMetadata != nil {
+		for address := range *inputMetadata {
+			address := address
+			g.Go(func() error {
+				metadata, err := ps.GetERC20MetadataForAddress([]byte(address))
+				if err != nil {
+					return err
+				}
+				mux.Lock()
+				outputMetadata[address] = metadata
+				mux.Unlock()
+				return nil
+			})
+		}
+	}
+
+	err := g.Wait()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return *names, outputMetadata, nil
+}
+
+func (ps *PostgresStorage) GetAddressLabel(id string, invoke_overwrite types.ContractInteractionType) string {
+	return addressLabel(id, invoke_overwrite)
+}
+
+// get a method label for its byte signature with defaults
+func (ps *PostgresStorage) GetMethodLabel(data []byte, interaction types.ContractInteractionType) string {
+	return methodLabel(ps, data, interaction)
+}
+
+// get an event label for its byte signature with defaults
+func (ps *PostgresStorage) GetEventLabel(id []byte) string {
+	return eventLabel(ps, id)
+}
+
+// Save a list of signatures
+func (ps *PostgresStorage) SaveSignatures(signatures []types.Signature, st types.SignatureType) error {
+	if len(signatures) == 0 {
+		return nil
+	}
+
+	hexes := make([]string, 0, len(signatures))
+	texts := make([]string, 0, len(signatures))
+	for _, sig := range signatures {
+		hexes = append(hexes, sig.Hex)
+		texts = append(texts, sig.Text)
+	}
+
+	_, err := WriterDb.Exec(`
+		INSERT INTO eth1_history_signatures (type, hex, signature)
+		SELECT $1, h, s
+		FROM unnest($2::text[], $3::text[]) AS t(h, s)
+		ON CONFLICT (type, hex) DO UPDATE SET signature = excluded.signature`,
+		getSignaturePrefix(st), pq.StringArray(hexes), pq.StringArray(texts))
+	return err
+}
+
+// get a signature by it's hex representation
+func (ps *PostgresStorage) GetSignature(hex string, st types.SignatureType) (*string, error) {
+	var signature string
+	err := ReaderDb.Get(&signature, `SELECT signature FROM eth1_history_signatures WHERE type = $1 AND hex = $2`, getSignaturePrefix(st), hex)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &signature, nil
+}
+
+func (ps *PostgresStorage) SaveGasNowHistory(slow, standard, rapid, fast *big.Int) error {
+	ts := time.Now().Truncate(time.Minute).UTC()
+
+	_, err := WriterDb.Exec(`
+		INSERT INTO gas_now_history (ts, slow, standard, rapid, fast) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (ts) DO UPDATE SET slow = excluded.slow, standard = excluded.standard, rapid = excluded.rapid, fast = excluded.fast`,
+		ts, slow.String(), standard.String(), rapid.String(), fast.String())
+	if err != nil {
+		return fmt.Errorf("error saving gas now history to postgres. err: %w", err)
+	}
+	return nil
+}
+
+// GetGasNowHistory returns the gas now history from ts (inclusive) back to pastTs (exclusive), most recent first
+func (ps *PostgresStorage) GetGasNowHistory(ts, pastTs time.Time) ([]types.GasNowHistory, error) {
+	rows := []struct {
+		Ts       time.Time `db:"ts"`
+		Slow     string    `db:"slow"`
+		Standard string    `db:"standard"`
+		Rapid    string    `db:"rapid"`
+		Fast     string    `db:"fast"`
+	}{}
+	err := ReaderDb.Select(&rows, `
+		SELECT ts, slow::TEXT AS slow, standard::TEXT AS standard, rapid::TEXT AS rapid, fast::TEXT AS fast
+		FROM gas_now_history
+		WHERE ts <= $1 AND ts > $2
+		ORDER BY ts DESC`,
+		ts.UTC(), pastTs.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("error getting gas now history from postgres, err: %w", err)
+	}
+
+	history := make([]types.GasNowHistory, 0, len(rows))
+	for _, row := range rows {
+		h := types.GasNowHistory{
+			Ts:       row.Ts,
+			Slow:     new(big.Int),
+			Standard: new(big.Int),
+			Rapid:    new(big.Int),
+			Fast:     new(big.Int),
+		}
+		for _, v := range []struct {
+			dst *big.Int
+			src string
+		}{{h.Slow, row.Slow}, {h.Standard, row.Standard}, {h.Rapid, row.Rapid}, {h.Fast, row.Fast}} {
+			if _, ok := v.dst.SetString(v.src, 10); !ok {
+				return nil, fmt.Errorf("error parsing gas now history value %v at %v", v.src, row.Ts)
+			}
+		}
+		history = append(history, h)
+	}
+	return history, nil
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"database/sql/driver"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db/dbtest"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+)
+
+func TestPostgresMachineRowKey(t *testing.T) {
+	ps := &PostgresStorage{}
+
+	// machine names may contain the separator
+	userID, process, machine, err := postgresMachineRowKeyParts(ps.GetMachineRowKey(7, "beaconnode", "host:1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userID != 7 || process != "beaconnode" || machine != "host:1" {
+		t.Errorf("got %v %v %v, want 7 beaconnode host:1", userID, process, machine)
+	}
+
+	if _, _, _, err := postgresMachineRowKeyParts("7:beaconnode"); err == nil {
+		t.Errorf("expected an error for a key without machine")
+	}
+	if _, _, _, err := postgresMachineRowKeyParts("x:beaconnode:host"); err == nil {
+		t.Errorf("expected an error for a key with an invalid user id")
+	}
+}
+
+func TestPostgresStorageGetValidatorBalanceHistory(t *testing.T) {
+	prevReader := ReaderDb
+	defer func() { ReaderDb = prevReader }()
+	ReaderDb = (&dbtest.DB{Query: func(query string, args []driver.Value) (*dbtest.Rows, error) {
+		columns, err := dbtest.SelectColumns(query)
+		if err != nil {
+			return nil, err
+		}
+		return &dbtest.Rows{Columns: columns, Values: [][]driver.Value{
+			{int64(1), int64(11), int64(32_100_000_000), int64(32_000_000_000)},
+			{int64(1), int64(10), int64(32_050_000_000), int64(32_000_000_000)},
+			{int64(2), int64(11), int64(31_900_000_000), int64(31_000_000_000)},
+		}}, nil
+	}}).Sqlx()
+
+	res, err := (&PostgresStorage{}).GetValidatorBalanceHistory([]uint64{1, 2}, 10, 11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res[1]) != 2 || len(res[2]) != 1 {
+		t.Fatalf("got %v balances for validator 1 and %v for validator 2, want 2 and 1", len(res[1]), len(res[2]))
+	}
+	if res[1][0].Epoch != 11 || res[1][1].Epoch != 10 {
+		t.Errorf("got epochs %v, %v for validator 1, want 11, 10", res[1][0].Epoch, res[1][1].Epoch)
+	}
+	if res[2][0].Index != 2 || res[2][0].Balance != 31_900_000_000 || res[2][0].EffectiveBalance != 31_000_000_000 {
+		t.Errorf("unexpected balance of validator 2: %+v", res[2][0])
+	}
+
+	if _, err := (&PostgresStorage{}).GetValidatorBalanceHistory([]uint64{}, 10, 11); err == nil {
+		t.Errorf("expected an error for an empty validator array")
+	}
+}
+
+func TestPostgresStorageGetGasNowHistory(t *testing.T) {
+	prevReader := ReaderDb
+	defer func() { ReaderDb = prevReader }()
+	ts := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ReaderDb = (&dbtest.DB{Query: func(query string, args []driver.Value) (*dbtest.Rows, error) {
+		columns, err := dbtest.SelectColumns(query)
+		if err != nil {
+			return nil, err
+		}
+		return &dbtest.Rows{Columns: columns, Values: [][]driver.Value{
+			{ts, "1000000000", "2000000000", "3000000000", "123456789012345678901234567890"},
+		}}, nil
+	}}).Sqlx()
+
+	history, err := (&PostgresStorage{}).GetGasNowHistory(ts, ts.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("got %v entries, want 1", len(history))
+	}
+	fast, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	if !history[0].Ts.Equal(ts) || history[0].Slow.Int64() != 1000000000 || history[0].Rapid.Int64() != 3000000000 || history[0].Fast.Cmp(fast) != 0 {
+		t.Errorf("unexpected gas now history %+v", history[0])
+	}
+}
+
+func TestPostgresStorageGetAddressContractInteractionsAt(t *testing.T) {
+	prevReader := ReaderDb
+	defer func() { ReaderDb = prevReader }()
+	contract := []byte{0xaa}
+	ReaderDb = (&dbtest.DB{Query: func(query string, args []driver.Value) (*dbtest.Rows, error) {
+		if !strings.Contains(query, "eth1_history_contract_updates") {
+			t.Errorf("unexpected query %v", query)
+		}
+		columns, err := dbtest.SelectColumns(query)
+		if err != nil {
+			return nil, err
+		}
+		// created in block 10 and destroyed in block 20, the rows are not sorted
+		return &dbtest.Rows{Columns: columns, Values: [][]driver.Value{
+			{contract, int64(20), int64(1), int64(0), false, true},
+			{contract, int64(10), int64(0), int64(0), true, true},
+		}}, nil
+	}}).Sqlx()
+
+	res, err := (&PostgresStorage{}).GetAddressContractInteractionsAt([]contractInteractionAtRequest{
+		{address: "aa", block: 5, txIdx: -1, traceIdx: -1},
+		{address: "aa", block: 10, txIdx: 0, traceIdx: -1},
+		{address: "aa", block: 15, txIdx: -1, traceIdx: -1},
+		{address: "aa", block: -1, txIdx: -1, traceIdx: -1},
+		{address: "bb", block: -1, txIdx: -1, traceIdx: -1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []types.ContractInteractionType{types.CONTRACT_NONE, types.CONTRACT_CREATION, types.CONTRACT_PRESENT, types.CONTRACT_DESTRUCTION, types.CONTRACT_NONE}
+	for i := range want {
+		if res[i] != want[i] {
+			t.Errorf("request %v: got %v, want %v", i, res[i], want[i])
+		}
+	}
+}
+
+func TestPostgresStorageGetValidatorAttestationInclusions(t *testing.T) {
+	prevConfig, prevReader := utils.Config, ReaderDb
+	defer func() { utils.Config, ReaderDb = prevConfig, prevReader }()
+	utils.Config = &types.Config{}
+	utils.Config.Chain.ClConfig.SlotsPerEpoch = 32
+	ReaderDb = (&dbtest.DB{Query: func(query string, args []driver.Value) (*dbtest.Rows, error) {
+		columns, err := dbtest.SelectColumns(query)
+		if err != nil {
+			return nil, err
+		}
+		if args[1] != int64(320) || args[2] != int64(384) {
+			t.Errorf("got slot range [%v, %v), want [320, 384)", args[1], args[2])
+		}
+		// validator 1 has been included twice, the missed attestation of validator 2 is stored with inclusion slot 0
+		return &dbtest.Rows{Columns: columns, Values: [][]driver.Value{
+			{int64(1), int64(330), int64(331)},
+			{int64(1), int64(330), int64(333)},
+			{int64(2), int64(330), int64(0)},
+		}}, nil
+	}}).Sqlx()
+
+	res, err := (&PostgresStorage{}).getValidatorAttestationInclusions([]uint64{1, 2}, 10, 11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res[1][330]) != 2 || res[1][330][0].InclusionSlot != 331 || res[1][330][0].Status != 1 {
+		t.Errorf("unexpected inclusions of validator 1: %+v", res[1][330])
+	}
+	if len(res[2][330]) != 1 || res[2][330][0].Status != 0 {
+		t.Errorf("unexpected inclusions of validator 2: %+v", res[2][330])
+	}
+}
@@ -845,13 +845,23 @@ func gatherValidatorDepositWithdrawals(day uint64, data []*types.ValidatorStatsT
 		DepositsAmount uint64 `db:"deposits_amount"`
 	}
 	resDeposits := make([]*resRowDeposits, 0, 1024)
+	// since electra deposits are also included as execution layer deposit requests, top ups of compounding validators must not count as income
 	depositsQry := `
-			select validators.validatorindex, count(*) AS deposits, sum(amount) AS deposits_amount
-			from blocks_deposits
-			inner join validators on blocks_deposits.publickey = validators.pubkey
-			inner join blocks on blocks_deposits.block_root = blocks.blockroot
-			where blocks.slot >= $1 and blocks.slot <= $2 and (blocks.status = '1' OR blocks.slot = 0) and blocks_deposits.valid_signature
-			group by validators.validatorindex`
+			select validatorindex, count(*) AS deposits, sum(amount) AS deposits_amount
+			from (
+				select validators.validatorindex, blocks_deposits.amount
+				from blocks_deposits
+				inner join validators on blocks_deposits.publickey = validators.pubkey
+				inner join blocks on blocks_deposits.block_root = blocks.blockroot
+				where blocks.slot >= $1 and blocks.slot <= $2 and (blocks.status = '1' OR blocks.slot = 0) and blocks_deposits.valid_signature
+				union all
+				select validators.validatorindex, blocks_deposit_requests.amount
+				from blocks_deposit_requests
+				inner join validators on blocks_deposit_requests.pubkey = validators.pubkey
+				inner join blocks on blocks_deposit_requests.block_root = blocks.blockroot
+				where blocks.slot >= $1 and blocks.slot <= $2 and blocks.status = '1'
+			) deposits
+			group by validatorindex`
 
 	err := WriterDb.Select(&resDeposits, depositsQry, firstSlot, lastSlot)
 	if err != nil {
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/rpc"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	itypes "github.com/gobitfly/eth-rewards/types"
+)
+
+// Storage is the interface of the history store used by the exporters, services and handlers.
+// It covers the validator history, the eth1 index and the machine metrics.
+// The default implementation is *Bigtable, small networks and devnets can use *PostgresStorage instead
+// to run the full explorer on a single box (see InitStorage).
+type Storage interface {
+	Close()
+
+	// validator history
+	SaveValidatorBalances(epoch uint64, validators []*types.Validator) error
+	SaveProposalAssignments(epoch uint64, assignments map[uint64]uint64) error
+	SaveAttestationDuties(duties map[types.Slot]map[types.ValidatorIndex][]types.Slot) error
+	SaveSyncComitteeDuties(duties map[types.Slot]map[types.ValidatorIndex]bool) error
+	SaveProposal(block *types.Block) error
+	SetLastAttestationSlot(validator uint64, lastAttestationSlot uint64) error
+	GetLastAttestationSlots(validators []uint64) (map[uint64]uint64, error)
+	LastAttestationCacheInitialized() bool
+	CachedLastAttestationSlot(validator uint64) uint64
+	GetMaxValidatorindexForEpoch(epoch uint64) (uint64, error)
+	GetValidatorBalanceHistory(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64][]*types.ValidatorBalance, error)
+	GetValidatorAttestationHistory(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64][]*types.ValidatorAttestation, error)
+	GetValidatorMissedAttestationHistory(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64]map[uint64]bool, error)
+	GetValidatorSyncDutiesHistory(validators []uint64, startSlot uint64, endSlot uint64) (map[uint64]map[uint64]*types.ValidatorSyncParticipation, error)
+	GetValidatorEffectiveness(validators []uint64, epoch uint64) ([]*types.ValidatorEffectiveness, error)
+	GetValidatorIncomeDetailsHistory(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64]map[uint64]*itypes.ValidatorEpochIncome, error)
+	SaveValidatorIncomeDetails(epoch uint64, rewards map[uint64]*itypes.ValidatorEpochIncome) error
+	GetTotalValidatorIncomeDetailsHistory(startEpoch uint64, endEpoch uint64) (map[uint64]*itypes.ValidatorEpochIncome, error)
+	MigrateIncomeDataV1V2Schema(epoch uint64) error
+
+	// eth1 index
+	SaveBlock(block *types.Eth1Block) error
+	SaveSignatures(signatures []types.Signature, st types.SignatureType) error
+	SaveGasNowHistory(slow, standard, rapid, fast *big.Int) error
+	GetGasNowHistory(ts, pastTs time.Time) ([]types.GasNowHistory, error)
+	GetLastBlockInBlocksTable() (int, error)
+	GetLastBlockInDataTable() (int, error)
+	GetMostRecentBlockFromDataTable() (*types.Eth1BlockIndexed, error)
+	GetBlockFromBlocksTable(number uint64) (*types.Eth1Block, error)
+	GetBlocksDescending(start, limit uint64) ([]*types.Eth1BlockIndexed, error)
+	GetFullBlocksDescending(stream chan<- *types.Eth1Block, high, low uint64) error
+	GetBlocksIndexedMultiple(blockNumbers []uint64, limit uint64) ([]*types.Eth1BlockIndexed, error)
+	GetIndexedEth1Transaction(txHash []byte) (*types.Eth1TransactionIndexed, error)
+	GetAddressTransactionsTableData(address []byte, pageToken string) (*types.DataTableResponse, error)
+	GetAddressInternalTableData(address []byte, pageToken string) (*types.DataTableResponse, error)
+	GetAddressBlobTableData(address []byte, pageToken string) (*types.DataTableResponse, error)
+	GetAddressBlocksMinedTableData(address string, pageToken string) (*types.DataTableResponse, error)
+	GetAddressUnclesMinedTableData(address string, pageToken string) (*types.DataTableResponse, error)
+	GetAddressErc20TableData(address []byte, pageToken string) (*types.DataTableResponse, error)
+	GetAddressErc721TableData(address []byte, pageToken string) (*types.DataTableResponse, error)
+	GetAddressErc1155TableData(address []byte, pageToken string) (*types.DataTableResponse, error)
+	GetTokenTransactionsTableData(token []byte, address []byte, pageToken string) (*types.DataTableResponse, error)
+	GetInternalTransfersForTransaction(transaction []byte, from []byte, parityTrace []*rpc.ParityTraceResult, currency string) ([]types.ITransaction, error)
+	GetArbitraryTokenTransfersForTransaction(transaction []byte) ([]*types.Transfer, error)
+	GetAddressContractInteractionsAtBlock(block *types.Eth1Block) ([]types.ContractInteractionType, error)
+	GetAddressContractInteractionsAtTransactions(transactions []*types.Eth1TransactionIndexed, idxs []int64) ([]types.ContractInteractionType, error)
+	GetAddressContractInteractionsAtITransactions(itransactions []*types.Eth1InternalTransactionIndexed, idxs [][2]int64) ([][2]types.ContractInteractionType, error)
+	GetAddressContractInteractionsAtParityTraces(traces []*rpc.ParityTraceResult) ([][2]types.ContractInteractionType, error)
+
+	// eth1 metadata
+	SearchForAddress(addressPrefix []byte, limit int) ([]*types.Eth1AddressSearchItem, error)
+	GetMetadataForAddress(address []byte, offset uint64, limit uint64) (*types.Eth1AddressMetadata, error)
+	GetBalanceForAddress(address []byte, token []byte) (*types.Eth1AddressBalance, error)
+	GetERC20MetadataForAddress(address []byte) (*types.ERC20Metadata, error)
+	GetContractMetadata(address []byte) (*types.ContractMetadata, error)
+	GetAddressName(address []byte) (string, error)
+	GetAddressLabel(id string, invoke_overwrite types.ContractInteractionType) string
+	GetAddressesNamesArMetadata(names *map[string]string, inputMetadata *map[string]*types.ERC20Metadata) (map[string]string, map[string]*types.ERC20Metadata, error)
+	GetMethodLabel(data []byte, interaction types.ContractInteractionType) string
+	GetEventLabel(id []byte) string
+
+	// machine metrics
+	SaveMachineMetric(process string, userID uint64, machine string, data []byte) error
+	GetMachineRowKey(userID uint64, process string, machine string) string
+	GetMachineMetricsMachineNames(userID uint64) ([]string, error)
+	GetMachineMetricsMachineCount(userID uint64) (uint64, error)
+	GetMachineMetricsNode(userID uint64, limit, offset int) ([]*types.MachineMetricNode, error)
+	GetMachineMetricsValidator(userID uint64, limit, offset int) ([]*types.MachineMetricValidator, error)
+	GetMachineMetricsSystem(userID uint64, limit, offset int) ([]*types.MachineMetricSystem, error)
+	GetMachineMetricsForNotifications(rowKeys []string) (map[uint64]map[string]*types.MachineMetricSystemUser, error)
+	GetMachineNodeMetricsForNotifications(rowKeys []string) (map[uint64]map[string]*types.MachineMetricNodeUser, error)
+}
+
+var _ Storage = (*Bigtable)(nil)
+var _ Storage = (*PostgresStorage)(nil)
+
+// InitStorage initializes the storage backend selected by bigtable.backend in the config and sets it as BigtableClient
+func InitStorage(project, instance, chainId, redisAddress string) (Storage, error) {
+	switch utils.Config.Bigtable.Backend {
+	case "", "bigtable":
+		bt, err := InitBigtable(project, instance, chainId, redisAddress)
+		if err != nil {
+			return nil, err
+		}
+		return bt, nil
+	case "postgres":
+		ps, err := InitPostgresStorage(chainId)
+		if err != nil {
+			return nil, err
+		}
+		BigtableClient = ps
+		return ps, nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", utils.Config.Bigtable.Backend)
+	}
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+)
+
+// GetUserWebhookDeliveries returns the most recent deliveries of a webhook of the user
+func GetUserWebhookDeliveries(userID, webhookID uint64, limit uint64) ([]*types.UserWebhookDelivery, error) {
+	deliveries := []*types.UserWebhookDelivery{}
+	err := FrontendReaderDB.Select(&deliveries, `
+		SELECT
+			id,
+			user_id,
+			webhook_id,
+			event_id,
+			event_name,
+			content,
+			created,
+			status,
+			attempts,
+			next_attempt,
+			last_attempt,
+			response_status,
+			response_body,
+			error
+		FROM users_webhooks_deliveries
+		WHERE user_id = $1 AND webhook_id = $2
+		ORDER BY created DESC, id DESC
+		LIMIT $3`, userID, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting deliveries of webhook %v: %w", webhookID, err)
+	}
+	return deliveries, nil
+}
+
+// ReplayUserWebhookDelivery schedules a delivery that is not pending anymore to be sent again immediately.
+// Returns sql.ErrNoRows if the user has no such delivery or if it is still pending.
+func ReplayUserWebhookDelivery(userID, webhookID, deliveryID uint64) error {
+	res, err := FrontendWriterDB.Exec(`
+		UPDATE users_webhooks_deliveries
+		SET status = $4, attempts = 0, next_attempt = now(), error = NULL
+		WHERE user_id = $1 AND webhook_id = $2 AND id = $3 AND status != $4`,
+		userID, webhookID, deliveryID, types.WebhookDeliveryPending)
+	if err != nil {
+		return fmt.Errorf("error replaying webhook delivery %v: %w", deliveryID, err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error replaying webhook delivery %v: %w", deliveryID, err)
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RotateUserWebhookSecret replaces the signing secret of a webhook and returns the new secret.
+// Returns sql.ErrNoRows if the user has no such webhook.
+func RotateUserWebhookSecret(userID, webhookID uint64) (string, error) {
+	secret, err := utils.GenerateWebhookSecret()
+	if err != nil {
+		return "", fmt.Errorf("error generating webhook secret: %w", err)
+	}
+	res, err := FrontendWriterDB.Exec(`UPDATE users_webhooks SET secret = $1 WHERE user_id = $2 AND id = $3`, secret, userID, webhookID)
+	if err != nil {
+		return "", fmt.Errorf("error updating secret of webhook %v: %w", webhookID, err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return "", fmt.Errorf("error updating secret of webhook %v: %w", webhookID, err)
+	}
+	if rowsAffected == 0 {
+		return "", sql.ErrNoRows
+	}
+	return secret, nil
+}
@@ -43,8 +43,9 @@ type gitAPIResponse struct {
 }
 
 type clientUpdateInfo struct {
-	Name string
-	Date time.Time
+	Name    string
+	Version string // tag of the release, e.g. v5.1.0
+	Date    time.Time
 }
 
 type EthClients struct {
@@ -185,7 +186,7 @@ func prepareEthClientData(repo string, name string, curTime time.Time) (string,
 		timeDiff := (curTime.Sub(rTime).Hours() / 24.0)
 
 		if timeDiff < 1 { // add recent releases for notification collector to be collected
-			update := clientUpdateInfo{Name: name, Date: rTime}
+			update := clientUpdateInfo{Name: name, Version: client.TagName, Date: rTime}
 			bannerClients = append(bannerClients, update)
 		}
 		return client.Name, utils.FormatTimestamp(rTime.Unix())
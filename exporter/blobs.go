@@ -16,9 +16,7 @@ import (
 	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
 	"github.com/gobitfly/eth2-beaconchain-explorer/version"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
-	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/coocood/freecache"
 	"github.com/sirupsen/logrus"
@@ -34,25 +32,7 @@ type BlobIndexer struct {
 }
 
 func NewBlobIndexer() (*BlobIndexer, error) {
-	s3Resolver := aws.EndpointResolverWithOptionsFunc(func(service, region string, options ...interface{}) (aws.Endpoint, error) {
-		return aws.Endpoint{
-			PartitionID:       "aws",
-			URL:               utils.Config.BlobIndexer.S3.Endpoint,
-			SigningRegion:     "us-east-2",
-			HostnameImmutable: true,
-		}, nil
-	})
-	s3Client := s3.NewFromConfig(aws.Config{
-		Region: "us-east-2",
-		Credentials: credentials.NewStaticCredentialsProvider(
-			utils.Config.BlobIndexer.S3.AccessKeyId,
-			utils.Config.BlobIndexer.S3.AccessKeySecret,
-			"",
-		),
-		EndpointResolverWithOptions: s3Resolver,
-	}, func(o *s3.Options) {
-		o.UsePathStyle = true
-	})
+	s3Client := utils.NewS3Client(utils.Config.BlobIndexer.S3.Endpoint, utils.Config.BlobIndexer.S3.AccessKeyId, utils.Config.BlobIndexer.S3.AccessKeySecret)
 	bi := &BlobIndexer{
 		S3Client:   s3Client,
 		runningMu:  &sync.Mutex{},
@@ -0,0 +1,128 @@
+package exporter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db"
+	"github.com/gobitfly/eth2-beaconchain-explorer/metrics"
+	"github.com/gobitfly/eth2-beaconchain-explorer/rpc"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+)
+
+// slotExporterEventTopics are the event stream topics the slot exporter reacts to
+var slotExporterEventTopics = []string{
+	rpc.EventTopicHead,
+	rpc.EventTopicBlock,
+	rpc.EventTopicChainReorg,
+	rpc.EventTopicFinalizedCheckpoint,
+	rpc.EventTopicAttestation,
+}
+
+// lastEventHeadSlot is the slot of the latest head or block event the slot exporter received
+var lastEventHeadSlot uint64
+
+// waitForNextSlotExporterRun blocks until the slot exporter should run again.
+// While the event stream is connected, new heads, blocks and finalized checkpoints trigger a run and chain reorgs
+// trigger a re-export of the affected slots. Attestations only trigger a run if they vote for a slot no head or block
+// event was received for. If the stream is unavailable the exporter falls back to polling.
+func waitForNextSlotExporterRun(client rpc.Client, stream *rpc.EventStream, lastRun time.Time, pollInterval time.Duration) {
+	if stream == nil || !stream.Connected() {
+		elapsed := time.Since(lastRun)
+		if elapsed < pollInterval {
+			time.Sleep(pollInterval - elapsed)
+		}
+		return
+	}
+
+	// safety net in case the stream silently stalls
+	timeout := time.NewTimer(pollInterval * 2)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case e := <-stream.Events:
+			if handleSlotExporterEvent(client, e) {
+				drainSlotExporterEvents(client, stream)
+				return
+			}
+		case <-timeout.C:
+			logger.Warnf("no event received from the beacon node within %v, running slot exporter", pollInterval*2)
+			return
+		}
+	}
+}
+
+// drainSlotExporterEvents handles all events that queued up while the exporter was busy, a single run covers all of them
+func drainSlotExporterEvents(client rpc.Client, stream *rpc.EventStream) {
+	for {
+		select {
+		case e := <-stream.Events:
+			handleSlotExporterEvent(client, e)
+		default:
+			return
+		}
+	}
+}
+
+// handleSlotExporterEvent processes a single event and reports whether it should trigger a slot exporter run
+func handleSlotExporterEvent(client rpc.Client, e *rpc.BeaconEvent) bool {
+	metrics.Counter.WithLabelValues("beacon_event_" + e.Topic).Inc()
+
+	switch e.Topic {
+	case rpc.EventTopicHead:
+		logger.Infof("received new head %v at slot %v", e.Head.Block, e.Head.Slot)
+		lastEventHeadSlot = max(lastEventHeadSlot, uint64(e.Head.Slot))
+		return true
+	case rpc.EventTopicBlock:
+		logger.Infof("received new block %v at slot %v", e.Block.Block, e.Block.Slot)
+		lastEventHeadSlot = max(lastEventHeadSlot, uint64(e.Block.Slot))
+		return true
+	case rpc.EventTopicAttestation:
+		// an attestation for a slot after the latest head event means the chain moved on without a head event reaching
+		// the exporter, either because the event got lost or because the slot is empty
+		slot := uint64(e.Attestation.Data.Slot)
+		if slot <= lastEventHeadSlot {
+			return false
+		}
+		logger.Warnf("received attestation for block %v at slot %v without a head event, latest head event was at slot %v", e.Attestation.Data.BeaconBlockRoot, slot, lastEventHeadSlot)
+		lastEventHeadSlot = slot
+		return true
+	case rpc.EventTopicFinalizedCheckpoint:
+		logger.Infof("received new finalized checkpoint %v at epoch %v", e.FinalizedCheckpoint.Block, e.FinalizedCheckpoint.Epoch)
+		return true
+	case rpc.EventTopicChainReorg:
+		logger.Warnf("received chain reorg of depth %v at slot %v (old head: %v, new head: %v)", e.ChainReorg.Depth, e.ChainReorg.Slot, e.ChainReorg.OldHeadBlock, e.ChainReorg.NewHeadBlock)
+		err := ExportReorgedSlots(client)
+		if err != nil {
+			utils.LogError(err, "error re-exporting reorged slots", 0)
+		}
+		return true
+	}
+	return false
+}
+
+// ExportReorgedSlots detects orphaned slots after a reorg and re-exports the affected slots
+func ExportReorgedSlots(client rpc.Client) error {
+	head, err := client.GetChainHead()
+	if err != nil {
+		return fmt.Errorf("error retrieving chain head: %w", err)
+	}
+
+	tx, err := db.WriterDb.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = trackReorgs(client, head, tx)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error committing tx: %w", err)
+	}
+	return nil
+}
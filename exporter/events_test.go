@@ -0,0 +1,37 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/rpc"
+)
+
+func TestHandleSlotExporterAttestationEvents(t *testing.T) {
+	prevSlot := lastEventHeadSlot
+	defer func() { lastEventHeadSlot = prevSlot }()
+	lastEventHeadSlot = 0
+
+	attestation := func(slot uint64) *rpc.BeaconEvent {
+		e := &rpc.BeaconEvent{Topic: rpc.EventTopicAttestation, Attestation: &rpc.Attestation{}}
+		err := json.Unmarshal([]byte(fmt.Sprintf(`{"data":{"slot":"%d"}}`, slot)), e.Attestation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+
+	if !handleSlotExporterEvent(nil, &rpc.BeaconEvent{Topic: rpc.EventTopicHead, Head: &rpc.StreamedHeadEventData{Slot: 10}}) {
+		t.Fatalf("expected a head event to trigger a run")
+	}
+	if handleSlotExporterEvent(nil, attestation(10)) {
+		t.Errorf("expected an attestation for the latest head not to trigger a run")
+	}
+	if !handleSlotExporterEvent(nil, attestation(11)) {
+		t.Errorf("expected an attestation for a slot without head event to trigger a run")
+	}
+	if handleSlotExporterEvent(nil, attestation(11)) {
+		t.Errorf("expected further attestations for the same slot not to trigger another run")
+	}
+}
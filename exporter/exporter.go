@@ -48,6 +48,12 @@ func Start(client rpc.Client) {
 		time.Sleep(time.Second * 10)
 	}
 
+	// subscribe to the node event stream if supported, the exporter falls back to polling while it is unavailable
+	var stream *rpc.EventStream
+	if subscriber, ok := client.(rpc.EventSubscriber); ok {
+		stream = subscriber.SubscribeEvents(slotExporterEventTopics...)
+	}
+
 	firstRun := true
 
 	minWaitTimeBetweenRuns := time.Second * time.Duration(utils.Config.Chain.ClConfig.SecondsPerSlot)
@@ -61,12 +67,9 @@ func Start(client rpc.Client) {
 		}
 
 		logrus.Info("update run completed")
-		elapsed := time.Since(start)
-		if elapsed < minWaitTimeBetweenRuns {
-			time.Sleep(minWaitTimeBetweenRuns - elapsed)
-		}
-
 		services.ReportStatus("slotExporter", "Running", nil)
+
+		waitForNextSlotExporterRun(client, stream, start, minWaitTimeBetweenRuns)
 	}
 }
 
@@ -0,0 +1,149 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db"
+	"github.com/gobitfly/eth2-beaconchain-explorer/metrics"
+	"github.com/gobitfly/eth2-beaconchain-explorer/rpc"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// trackReorgs checks whether the canonical blocks stored in the db are still part of the canonical chain of the node.
+// Starting at the newest stored block, it walks the chain of the node backwards following the parent roots until it
+// reaches a block that is stored as canonical. All stored blocks after this common ancestor have been orphaned, they
+// are handled like the orphaned slots found by RunSlotExporter and the reorg is recorded.
+func trackReorgs(client rpc.Client, head *types.ChainHead, tx *sqlx.Tx) error {
+	dbBlocks, err := db.GetNonFinalizedCanonicalBlocks(tx)
+	if err != nil {
+		return err
+	}
+	if len(dbBlocks) == 0 {
+		return nil
+	}
+
+	dbBlocksByRoot := make(map[string]*db.CanonicalBlockRow, len(dbBlocks))
+	for _, b := range dbBlocks {
+		dbBlocksByRoot[string(b.BlockRoot)] = b
+	}
+
+	newestDbBlock := dbBlocks[0]
+	header, err := client.GetBlockHeader(newestDbBlock.Slot)
+	if err != nil {
+		return fmt.Errorf("error retrieving block header for slot %v: %w", newestDbBlock.Slot, err)
+	}
+	if header != nil && bytes.Equal(utils.MustParseHex(header.Data.Root), newestDbBlock.BlockRoot) {
+		// the newest block we know about is still canonical, so are all of its ancestors
+		return nil
+	}
+
+	// walk back the chain of the node until we find the common ancestor
+	commonAncestorSlot := uint64(0)
+	canonicalRoots := make(map[uint64][]byte)
+	found := false
+	for slot := newestDbBlock.Slot; ; slot-- {
+		if slot <= head.FinalizedSlot || slot < dbBlocks[len(dbBlocks)-1].Slot {
+			// the finalized part of the chain can not be reorged
+			commonAncestorSlot = slot
+			found = true
+			break
+		}
+
+		header, err := client.GetBlockHeader(slot)
+		if err != nil {
+			return fmt.Errorf("error retrieving block header for slot %v: %w", slot, err)
+		}
+		if header == nil {
+			if slot == 0 {
+				break
+			}
+			continue
+		}
+
+		root := utils.MustParseHex(header.Data.Root)
+		if _, ok := dbBlocksByRoot[string(root)]; ok {
+			commonAncestorSlot = slot
+			found = true
+			break
+		}
+		canonicalRoots[slot] = root
+
+		if parent, ok := dbBlocksByRoot[string(utils.MustParseHex(header.Data.Header.Message.ParentRoot))]; ok {
+			commonAncestorSlot = parent.Slot
+			found = true
+			break
+		}
+		if slot == 0 {
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("unable to find common ancestor for reorg of slot %v", newestDbBlock.Slot)
+	}
+
+	reorg := &types.Reorg{
+		Slot:               head.HeadSlot,
+		CommonAncestorSlot: commonAncestorSlot,
+		OldHeadRoot:        newestDbBlock.BlockRoot,
+		NewHeadRoot:        head.HeadBlockRoot,
+		OldHeadProposer:    newestDbBlock.Proposer,
+		OrphanedSlots:      pq.Int64Array{},
+		OrphanedProposers:  pq.Int64Array{},
+	}
+
+	newHeadHeader, err := client.GetBlockHeader(head.HeadSlot)
+	if err != nil {
+		return fmt.Errorf("error retrieving block header for head slot %v: %w", head.HeadSlot, err)
+	}
+	if newHeadHeader != nil {
+		reorg.NewHeadProposer = uint64(newHeadHeader.Data.Header.Message.ProposerIndex)
+	}
+
+	exportedSlots := make(map[uint64]bool)
+	// dbBlocks is sorted newest first, iterate in chain order
+	for i := len(dbBlocks) - 1; i >= 0; i-- {
+		b := dbBlocks[i]
+		if b.Slot <= commonAncestorSlot {
+			continue
+		}
+		if bytes.Equal(canonicalRoots[b.Slot], b.BlockRoot) {
+			continue
+		}
+
+		logger.Infof("slot %v (0x%x) has been orphaned by a reorg", b.Slot, b.BlockRoot)
+		_, hasCanonicalBlock := canonicalRoots[b.Slot]
+		err := exportOrphanedSlot(client, b.Slot, false, hasCanonicalBlock, head, tx)
+		if err != nil {
+			return err
+		}
+		exportedSlots[b.Slot] = true
+		reorg.OrphanedSlots = append(reorg.OrphanedSlots, int64(b.Slot))
+		reorg.OrphanedProposers = append(reorg.OrphanedProposers, int64(b.Proposer))
+	}
+	reorg.Depth = uint64(len(reorg.OrphanedSlots))
+
+	if reorg.Depth == 0 {
+		return nil
+	}
+
+	// export the new canonical blocks of slots that were stored as missed
+	for slot := commonAncestorSlot + 1; slot <= newestDbBlock.Slot; slot++ {
+		if _, ok := canonicalRoots[slot]; !ok || exportedSlots[slot] {
+			continue
+		}
+		err := ExportSlot(client, slot, utils.EpochOfSlot(slot) == head.HeadEpoch, tx)
+		if err != nil {
+			return fmt.Errorf("error exporting slot %v after reorg: %w", slot, err)
+		}
+	}
+
+	logger.Warnf("detected reorg of depth %v at slot %v (common ancestor: %v, old head: 0x%x, new head: 0x%x)", reorg.Depth, reorg.Slot, reorg.CommonAncestorSlot, reorg.OldHeadRoot, reorg.NewHeadRoot)
+	metrics.Counter.WithLabelValues("exporter_reorgs").Inc()
+
+	return db.SaveReorg(reorg, tx)
+}
@@ -90,6 +90,12 @@ func RunSlotExporter(client rpc.Client, firstRun bool) error {
 		}
 	}
 
+	// check if any of the stored blocks has been orphaned by a reorg
+	err = trackReorgs(client, head, tx)
+	if err != nil {
+		return fmt.Errorf("error tracking reorgs: %w", err)
+	}
+
 	// check if any new slots have been added to the chain
 	if lastDbSlot != head.HeadSlot {
 		slotsExported := 0
@@ -147,21 +153,15 @@ func RunSlotExporter(client rpc.Client, firstRun bool) error {
 				}
 			} else if header == nil && len(dbSlot.BlockRoot) == 32 {
 				// slot has been orphaned, mark the slot as orphaned
-				logger.Infof("setting slot %v as finalized (orphaned)", dbSlot.Slot)
-				err := db.SetSlotFinalizationAndStatus(dbSlot.Slot, nodeSlotFinalized, "3", tx)
+				err := exportOrphanedSlot(client, dbSlot.Slot, nodeSlotFinalized, false, head, tx)
 				if err != nil {
-					return fmt.Errorf("error setting block %v as finalized (orphaned): %w", dbSlot.Slot, err)
+					return err
 				}
 			} else if header != nil && !bytes.Equal(utils.MustParseHex(header.Data.Root), dbSlot.BlockRoot) {
 				// we have a different block root for the slot in the db, mark the currently present one as orphaned and write the new one
-				logger.Infof("setting slot %v as orphaned and exporting new slot", dbSlot.Slot)
-				err := db.SetSlotFinalizationAndStatus(dbSlot.Slot, nodeSlotFinalized, "3", tx)
+				err := exportOrphanedSlot(client, dbSlot.Slot, nodeSlotFinalized, true, head, tx)
 				if err != nil {
-					return fmt.Errorf("error setting block %v as finalized (orphaned): %w", dbSlot.Slot, err)
-				}
-				err = ExportSlot(client, dbSlot.Slot, utils.EpochOfSlot(dbSlot.Slot) == head.HeadEpoch, tx)
-				if err != nil {
-					return fmt.Errorf("error exporting slot %v: %w", dbSlot.Slot, err)
+					return err
 				}
 			}
 
@@ -212,6 +212,30 @@ func RunSlotExporter(client rpc.Client, firstRun bool) error {
 
 }
 
+// exportOrphanedSlot marks the stored block of the slot as orphaned and exports the canonical block of the slot if the node has one.
+// The rows of the orphaned block are kept, they are told apart from the canonical ones by the status of their block.
+func exportOrphanedSlot(client rpc.Client, slot uint64, finalized bool, hasCanonicalBlock bool, head *types.ChainHead, tx *sqlx.Tx) error {
+	if !hasCanonicalBlock {
+		logger.Infof("setting slot %v as orphaned (finalized: %v)", slot, finalized)
+		err := db.SetSlotFinalizationAndStatus(slot, finalized, "3", tx)
+		if err != nil {
+			return fmt.Errorf("error setting block %v as orphaned: %w", slot, err)
+		}
+		return nil
+	}
+
+	logger.Infof("setting slot %v as orphaned and exporting new slot (finalized: %v)", slot, finalized)
+	err := db.SetSlotFinalizationAndStatus(slot, finalized, "3", tx)
+	if err != nil {
+		return fmt.Errorf("error setting block %v as orphaned: %w", slot, err)
+	}
+	err = ExportSlot(client, slot, utils.EpochOfSlot(slot) == head.HeadEpoch, tx)
+	if err != nil {
+		return fmt.Errorf("error exporting slot %v: %w", slot, err)
+	}
+	return nil
+}
+
 func ExportSlot(client rpc.Client, slot uint64, isHeadEpoch bool, tx *sqlx.Tx) error {
 
 	isFirstSlotOfEpoch := slot%utils.Config.Chain.ClConfig.SlotsPerEpoch == 0
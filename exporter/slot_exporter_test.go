@@ -0,0 +1,466 @@
+package exporter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db"
+	"github.com/gobitfly/eth2-beaconchain-explorer/rpc"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"gopkg.in/yaml.v3"
+)
+
+// The export tests replay beacon node responses recorded with `misc -command epoch-export -fixtures.record <dir>`.
+// TestReplayFixtures decodes the replayed responses without any database. TestExportEpochs additionally exports
+// them into a local postgres database and bigtable emulator and only runs if EXPORTER_TEST_CONFIG points to the
+// config file of such a local environment. All exported tables of the configured database are truncated by the tests.
+//
+// Every directory below testdata/fixtures (or EXPORTER_TEST_FIXTURES) is a test case, its case.json lists the
+// recorded epochs and the days to export the validator statistics for: {"epochs": [1234], "statistics_days": [1]}
+// If the case contains a chain.yml the recorded network was not the configured chain and the test case switches
+// to that chain config. The committed minimal-phase0, minimal-deneb and minimal-electra cases are not recorded from real
+// nodes but generated by cmd/exporter-fixtures, which serves a synthetic minimal preset chain and records it the same way.
+// Their chain configs shorten a day to two epochs so the statistics of day 0 can be exported from three epochs. The optional
+// expected counts of the case make sure the replay actually decodes the blob sidecars and execution requests of the fork:
+// {"expected": {"blobs": 2, "consolidation_requests": 1}}
+
+//go:generate go run ../cmd/exporter-fixtures -fork phase0 -dir testdata/fixtures/minimal-phase0
+//go:generate go run ../cmd/exporter-fixtures -fork deneb -dir testdata/fixtures/minimal-deneb
+//go:generate go run ../cmd/exporter-fixtures -fork electra -dir testdata/fixtures/minimal-electra
+
+type exportTestCase struct {
+	Epochs         []uint64           `json:"epochs"`
+	StatisticsDays []uint64           `json:"statistics_days"`
+	Expected       exportTestExpected `json:"expected"`
+}
+
+// exportTestExpected holds the number of fork specific block contents a test case has to replay
+type exportTestExpected struct {
+	Blobs                 int `json:"blobs"`
+	DepositRequests       int `json:"deposit_requests"`
+	WithdrawalRequests    int `json:"withdrawal_requests"`
+	ConsolidationRequests int `json:"consolidation_requests"`
+}
+
+var exportTestTables = []string{
+	"blocks",
+	"blocks_attestations",
+	"blocks_deposits",
+	"blocks_withdrawals",
+	"blocks_voluntaryexits",
+	"blocks_proposerslashings",
+	"blocks_attesterslashings",
+	"blocks_bls_change",
+	"blocks_blob_sidecars",
+	"blocks_deposit_requests",
+	"blocks_withdrawal_requests",
+	"blocks_consolidation_requests",
+	"epochs",
+	"validator_stats",
+	"validator_stats_status",
+	"validator_performance",
+}
+
+var exportTestSetup sync.Once
+
+// setupExportTest initializes config, database and bigtable for the export tests or skips the test if no local environment is configured
+func setupExportTest(t *testing.T) {
+	configPath := os.Getenv("EXPORTER_TEST_CONFIG")
+	if configPath == "" {
+		t.Skip("EXPORTER_TEST_CONFIG not set, skipping export test")
+	}
+
+	exportTestSetup.Do(func() {
+		cfg := &types.Config{}
+		err := utils.ReadConfig(cfg, configPath)
+		if err != nil {
+			t.Fatalf("error reading config file: %v", err)
+		}
+		utils.Config = cfg
+
+		db.MustInitDB(&types.DatabaseConfig{
+			Username: cfg.WriterDatabase.Username,
+			Password: cfg.WriterDatabase.Password,
+			Name:     cfg.WriterDatabase.Name,
+			Host:     cfg.WriterDatabase.Host,
+			Port:     cfg.WriterDatabase.Port,
+			SSL:      cfg.WriterDatabase.SSL,
+		}, &types.DatabaseConfig{
+			Username: cfg.ReaderDatabase.Username,
+			Password: cfg.ReaderDatabase.Password,
+			Name:     cfg.ReaderDatabase.Name,
+			Host:     cfg.ReaderDatabase.Host,
+			Port:     cfg.ReaderDatabase.Port,
+			SSL:      cfg.ReaderDatabase.SSL,
+		}, "pgx", "postgres")
+		err = db.ApplyEmbeddedDbSchema(-2)
+		if err != nil {
+			t.Fatalf("error applying db schema: %v", err)
+		}
+
+		_, err = db.InitBigtable(cfg.Bigtable.Project, cfg.Bigtable.Instance, strconv.FormatUint(cfg.Chain.ClConfig.DepositChainID, 10), cfg.RedisCacheEndpoint)
+		if err != nil {
+			t.Fatalf("error initializing bigtable: %v", err)
+		}
+	})
+	if db.WriterDb == nil || db.BigtableClient == nil {
+		t.Fatal("export test environment failed to initialize")
+	}
+
+	for _, table := range exportTestTables {
+		_, err := db.WriterDb.Exec(fmt.Sprintf("TRUNCATE %s", table))
+		if err != nil {
+			t.Fatalf("error truncating %v: %v", table, err)
+		}
+	}
+}
+
+// exportTestCases returns the directories of all recorded test cases
+func exportTestCases(t *testing.T) map[string]string {
+	t.Helper()
+
+	dir := os.Getenv("EXPORTER_TEST_FIXTURES")
+	if dir == "" {
+		dir = filepath.Join("testdata", "fixtures")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatalf("error reading fixture directory %v: %v", dir, err)
+	}
+
+	cases := make(map[string]string)
+	for _, e := range entries {
+		if e.IsDir() {
+			cases[e.Name()] = filepath.Join(dir, e.Name())
+		}
+	}
+	if len(cases) == 0 {
+		t.Fatalf("no recorded fixtures found in %v", dir)
+	}
+	return cases
+}
+
+// loadExportTestCase reads the case.json of the test case and switches to its chain config
+func loadExportTestCase(t *testing.T, dir string) *exportTestCase {
+	t.Helper()
+
+	caseData, err := os.ReadFile(filepath.Join(dir, "case.json"))
+	if err != nil {
+		t.Fatalf("error reading test case: %v", err)
+	}
+	tc := &exportTestCase{}
+	err = json.Unmarshal(caseData, tc)
+	if err != nil {
+		t.Fatalf("error parsing test case: %v", err)
+	}
+
+	chainData, err := os.ReadFile(filepath.Join(dir, "chain.yml"))
+	if os.IsNotExist(err) {
+		return tc
+	}
+	if err != nil {
+		t.Fatalf("error reading chain config of test case: %v", err)
+	}
+	chainConfig := types.ClChainConfig{}
+	err = yaml.Unmarshal(chainData, &chainConfig)
+	if err != nil {
+		t.Fatalf("error parsing chain config of test case: %v", err)
+	}
+
+	prevConfig := utils.Config
+	cfg := types.Config{}
+	if prevConfig != nil {
+		cfg = *prevConfig
+	}
+	cfg.Chain.ClConfig = chainConfig
+	utils.Config = &cfg
+	t.Cleanup(func() { utils.Config = prevConfig })
+
+	return tc
+}
+
+// TestReplayFixtures checks that the recorded fixtures replay offline and decode into consistent blocks and duties
+func TestReplayFixtures(t *testing.T) {
+	for name, dir := range exportTestCases(t) {
+		t.Run(name, func(t *testing.T) {
+			tc := loadExportTestCase(t, dir)
+			if utils.Config == nil {
+				t.Skip("test case has no chain.yml and no config is loaded")
+			}
+
+			client, err := rpc.NewFixtureClient(dir, rpc.FixtureModeReplay, "", new(big.Int).SetUint64(utils.Config.Chain.ClConfig.DepositChainID))
+			if err != nil {
+				t.Fatalf("error creating fixture client: %v", err)
+			}
+
+			replayed := exportTestExpected{}
+			for _, epoch := range tc.Epochs {
+				replayTestEpoch(t, client, epoch, &replayed)
+			}
+			if replayed != tc.Expected {
+				t.Errorf("replayed %+v, expected %+v", replayed, tc.Expected)
+			}
+		})
+	}
+}
+
+// replayTestEpoch retrieves all slots of the epoch from the fixtures and checks them against the recorded duties
+func replayTestEpoch(t *testing.T, client rpc.Client, epoch uint64, replayed *exportTestExpected) {
+	firstSlot := epoch * utils.Config.Chain.ClConfig.SlotsPerEpoch
+
+	var assignments *types.EpochAssignments
+	for slot := firstSlot; slot < firstSlot+utils.Config.Chain.ClConfig.SlotsPerEpoch; slot++ {
+		block, err := client.GetBlockBySlot(slot)
+		if err != nil {
+			t.Fatalf("error replaying slot %v: %v", slot, err)
+		}
+		if block.Slot != slot {
+			t.Errorf("slot %v: replayed block of slot %v", slot, block.Slot)
+		}
+
+		if slot == firstSlot {
+			if block.EpochAssignments == nil || len(block.EpochAssignments.AttestorAssignments) == 0 {
+				t.Fatalf("epoch %v: replayed without attestation assignments", epoch)
+			}
+			if len(block.Validators) == 0 {
+				t.Fatalf("epoch %v: replayed without validators", epoch)
+			}
+			assignments = block.EpochAssignments
+		}
+
+		if proposer, ok := assignments.ProposerAssignments[slot]; ok && block.Proposer != proposer {
+			t.Errorf("slot %v: replayed proposer %v, expected %v", slot, block.Proposer, proposer)
+		}
+		if block.Status == 0 {
+			if len(block.Attestations) != 0 {
+				t.Errorf("slot %v: missed slot with %v attestations", slot, len(block.Attestations))
+			}
+			continue
+		}
+
+		for i, a := range block.Attestations {
+			if len(a.Attesters) == 0 {
+				t.Errorf("slot %v: attestation %v resolved to no attesters", slot, i)
+			}
+			attestationAssignments, err := client.GetEpochAssignments(utils.EpochOfSlot(a.Data.Slot))
+			if err != nil {
+				t.Fatalf("error replaying assignments of attestation %v of slot %v: %v", i, slot, err)
+			}
+			committeeMembers := make(map[uint64]bool)
+			for key, validator := range attestationAssignments.AttestorAssignments {
+				if strings.HasPrefix(key, fmt.Sprintf("%d-", a.Data.Slot)) {
+					committeeMembers[validator] = true
+				}
+			}
+			for _, validator := range a.Attesters {
+				if len(block.AttestationDuties[types.ValidatorIndex(validator)]) == 0 {
+					t.Errorf("slot %v: attester %v of attestation %v has no attestation duty", slot, validator, i)
+				}
+				if !committeeMembers[validator] {
+					t.Errorf("slot %v: attester %v of attestation %v is not in a committee of slot %v", slot, validator, i, a.Data.Slot)
+				}
+			}
+		}
+
+		if len(block.BlobKZGCommitments) > 0 {
+			versionedHashes := make(map[string]bool)
+			if block.ExecutionPayload != nil {
+				for _, tx := range block.ExecutionPayload.Transactions {
+					for _, h := range tx.BlobVersionedHashes {
+						versionedHashes[string(h)] = true
+					}
+				}
+			}
+			for i, commitment := range block.BlobKZGCommitments {
+				if len(block.BlobKZGProofs[i]) != 48 {
+					t.Errorf("slot %v: blob %v replayed without kzg proof", slot, i)
+				}
+				versionedHash := sha256.Sum256(commitment)
+				versionedHash[0] = 0x01
+				if !versionedHashes[string(versionedHash[:])] {
+					t.Errorf("slot %v: blob %v is not referenced by a blob transaction", slot, i)
+				}
+			}
+		}
+		replayed.Blobs += len(block.BlobKZGCommitments)
+		replayed.DepositRequests += len(block.DepositRequests)
+		replayed.WithdrawalRequests += len(block.WithdrawalRequests)
+		replayed.ConsolidationRequests += len(block.ConsolidationRequests)
+	}
+
+	if epoch > 0 {
+		participation, err := client.GetValidatorParticipation(epoch - 1)
+		if err != nil {
+			t.Fatalf("error replaying participation of epoch %v: %v", epoch-1, err)
+		}
+		if participation.EligibleEther == 0 || participation.GlobalParticipationRate <= 0 || participation.GlobalParticipationRate > 1 {
+			t.Errorf("epoch %v: replayed invalid participation %+v", epoch-1, participation)
+		}
+	}
+}
+
+func TestExportEpochs(t *testing.T) {
+	setupExportTest(t)
+
+	for name, dir := range exportTestCases(t) {
+		t.Run(name, func(t *testing.T) {
+			setupExportTest(t)
+			tc := loadExportTestCase(t, dir)
+
+			client, err := rpc.NewFixtureClient(dir, rpc.FixtureModeReplay, "", new(big.Int).SetUint64(utils.Config.Chain.ClConfig.DepositChainID))
+			if err != nil {
+				t.Fatalf("error creating fixture client: %v", err)
+			}
+
+			for _, epoch := range tc.Epochs {
+				exportTestEpoch(t, client, epoch)
+			}
+			for _, day := range tc.StatisticsDays {
+				exportTestStatistics(t, client, day)
+			}
+		})
+	}
+}
+
+// exportTestStatistics exports the validator statistics of the day and checks the resulting rows against the replayed balances
+func exportTestStatistics(t *testing.T, client rpc.Client, day uint64) {
+	err := db.WriteValidatorStatisticsForDay(day, client)
+	if err != nil {
+		t.Fatalf("error exporting validator statistics for day %v: %v", day, err)
+	}
+
+	var exported bool
+	err = db.ReaderDb.Get(&exported, `SELECT status FROM validator_stats_status WHERE day = $1`, day)
+	if err != nil {
+		t.Fatalf("error retrieving export status of day %v: %v", day, err)
+	}
+	if !exported {
+		t.Errorf("day %v: not marked as exported", day)
+	}
+
+	_, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
+	state, err := client.GetValidatorState(lastEpoch)
+	if err != nil {
+		t.Fatalf("error replaying validator state of epoch %v: %v", lastEpoch, err)
+	}
+
+	rows := []struct {
+		ValidatorIndex uint64 `db:"validatorindex"`
+		EndBalance     uint64 `db:"end_balance"`
+	}{}
+	err = db.ReaderDb.Select(&rows, `SELECT validatorindex, COALESCE(end_balance, 0) AS end_balance FROM validator_stats WHERE day = $1 ORDER BY validatorindex`, day)
+	if err != nil {
+		t.Fatalf("error retrieving exported statistics of day %v: %v", day, err)
+	}
+	if len(rows) != len(state.Data) {
+		t.Fatalf("day %v: exported statistics of %v validators, expected %v", day, len(rows), len(state.Data))
+	}
+	for i, row := range rows {
+		if row.ValidatorIndex != uint64(state.Data[i].Index) {
+			t.Fatalf("day %v: exported statistics of validator %v, expected %v", day, row.ValidatorIndex, state.Data[i].Index)
+		}
+		if row.EndBalance != uint64(state.Data[i].Balance) {
+			t.Errorf("day %v: exported end balance %v for validator %v, expected %v", day, row.EndBalance, row.ValidatorIndex, state.Data[i].Balance)
+		}
+	}
+}
+
+// exportTestEpoch exports all slots of the epoch and checks the resulting rows against the replayed node data
+func exportTestEpoch(t *testing.T, client rpc.Client, epoch uint64) {
+	tx, err := db.WriterDb.Beginx()
+	if err != nil {
+		t.Fatalf("error starting tx: %v", err)
+	}
+	defer tx.Rollback()
+
+	firstSlot := epoch * utils.Config.Chain.ClConfig.SlotsPerEpoch
+	for slot := firstSlot; slot < firstSlot+utils.Config.Chain.ClConfig.SlotsPerEpoch; slot++ {
+		err := ExportSlot(client, slot, false, tx)
+		if err != nil {
+			t.Fatalf("error exporting slot %v: %v", slot, err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		t.Fatalf("error committing tx: %v", err)
+	}
+
+	for slot := firstSlot; slot < firstSlot+utils.Config.Chain.ClConfig.SlotsPerEpoch; slot++ {
+		block, err := client.GetBlockBySlot(slot)
+		if err != nil {
+			t.Fatalf("error replaying slot %v: %v", slot, err)
+		}
+
+		row := struct {
+			BlockRoot              []byte `db:"blockroot"`
+			Proposer               uint64 `db:"proposer"`
+			Status                 string `db:"status"`
+			AttestationsCount      int    `db:"attestationscount"`
+			DepositsCount          int    `db:"depositscount"`
+			VoluntaryExitsCount    int    `db:"voluntaryexitscount"`
+			ProposerSlashingsCount int    `db:"proposerslashingscount"`
+			AttesterSlashingsCount int    `db:"attesterslashingscount"`
+		}{}
+		err = db.ReaderDb.Get(&row, `
+			SELECT blockroot, proposer, status, attestationscount, depositscount, voluntaryexitscount, proposerslashingscount, attesterslashingscount
+			FROM blocks WHERE slot = $1`, slot)
+		if err != nil {
+			t.Fatalf("error retrieving exported block of slot %v: %v", slot, err)
+		}
+
+		if !bytes.Equal(row.BlockRoot, block.BlockRoot) {
+			t.Errorf("slot %v: exported block root %x, expected %x", slot, row.BlockRoot, block.BlockRoot)
+		}
+		if row.Proposer != block.Proposer {
+			t.Errorf("slot %v: exported proposer %v, expected %v", slot, row.Proposer, block.Proposer)
+		}
+		if row.Status != strconv.FormatUint(block.Status, 10) {
+			t.Errorf("slot %v: exported status %v, expected %v", slot, row.Status, block.Status)
+		}
+		if row.AttestationsCount != len(block.Attestations) {
+			t.Errorf("slot %v: exported %v attestations, expected %v", slot, row.AttestationsCount, len(block.Attestations))
+		}
+		if row.DepositsCount != len(block.Deposits) {
+			t.Errorf("slot %v: exported %v deposits, expected %v", slot, row.DepositsCount, len(block.Deposits))
+		}
+		if row.VoluntaryExitsCount != len(block.VoluntaryExits) {
+			t.Errorf("slot %v: exported %v voluntary exits, expected %v", slot, row.VoluntaryExitsCount, len(block.VoluntaryExits))
+		}
+		if row.ProposerSlashingsCount != len(block.ProposerSlashings) {
+			t.Errorf("slot %v: exported %v proposer slashings, expected %v", slot, row.ProposerSlashingsCount, len(block.ProposerSlashings))
+		}
+		if row.AttesterSlashingsCount != len(block.AttesterSlashings) {
+			t.Errorf("slot %v: exported %v attester slashings, expected %v", slot, row.AttesterSlashingsCount, len(block.AttesterSlashings))
+		}
+
+		var attestationRows int
+		err = db.ReaderDb.Get(&attestationRows, `SELECT COUNT(*) FROM blocks_attestations WHERE block_slot = $1`, slot)
+		if err != nil {
+			t.Fatalf("error counting exported attestations of slot %v: %v", slot, err)
+		}
+		if attestationRows != len(block.Attestations) {
+			t.Errorf("slot %v: exported %v attestation rows, expected %v", slot, attestationRows, len(block.Attestations))
+		}
+	}
+
+	var validatorsCount uint64
+	err = db.ReaderDb.Get(&validatorsCount, `SELECT validatorscount FROM epochs WHERE epoch = $1`, epoch)
+	if err != nil {
+		t.Fatalf("error retrieving exported epoch %v: %v", epoch, err)
+	}
+	if validatorsCount == 0 {
+		t.Errorf("epoch %v: exported without active validators", epoch)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	itypes "github.com/gobitfly/eth-rewards/types"
 	"github.com/gobitfly/eth2-beaconchain-explorer/db"
 	"github.com/gobitfly/eth2-beaconchain-explorer/exporter"
 	"github.com/gobitfly/eth2-beaconchain-explorer/price"
@@ -82,7 +83,7 @@ import (
 // @Tags Misc
 // @Description Health endpoint for monitoring if the explorer is in sync
 // @Produce  text/plain
-// @Success 200 {object} types.ApiResponse
+// @Success 200 {string} string "OK"
 // @Router /api/healthz [get]
 func ApiHealthz(w http.ResponseWriter, r *http.Request) {
 
@@ -156,7 +157,7 @@ func ApiHealthz(w http.ResponseWriter, r *http.Request) {
 // @Tags Misc
 // @Description Health endpoint for montitoring if the explorer-api
 // @Produce  text/plain
-// @Success 200 {object} types.ApiResponse
+// @Success 200 {string} string "OK"
 // @Router /api/healthz-loadbalancer [get]
 func ApiHealthzLoadbalancer(w http.ResponseWriter, r *http.Request) {
 
@@ -191,14 +192,15 @@ func ApiHealthzLoadbalancer(w http.ResponseWriter, r *http.Request) {
 // @Description See https://github.com/gobitfly/eth.store for further information.
 // @Produce json
 // @Param day path string true "The beaconchain-day (periods of <(24 * 60 * 60) // SlotsPerEpoch // SecondsPerSlot> epochs) to get the the ETH.STORE® for. Must be a number or the string 'latest'."
-// @Success 200 {object} types.ApiResponse
+// @Success 200 {object} types.ApiResponse{data=types.ApiEthStoreDayResponse}
+// @x-rows true
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/ethstore/{day} [get]
 func ApiEthStoreDay(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	var err error
-	var rows *sql.Rows
+	data := []*types.ApiEthStoreDayResponse{}
 	query := `
 		SELECT 
 			day, 
@@ -227,14 +229,14 @@ func ApiEthStoreDay(w http.ResponseWriter, r *http.Request) {
 
 	vars := mux.Vars(r)
 	if vars["day"] == "latest" {
-		rows, err = db.ReaderDb.Query(query + ` ORDER BY day DESC LIMIT 1;`)
+		err = db.ReaderDb.Select(&data, query+` ORDER BY day DESC LIMIT 1;`)
 	} else {
 		day, e := strconv.ParseInt(vars["day"], 10, 64)
 		if e != nil {
 			SendBadRequestResponse(w, r.URL.String(), "invalid day provided")
 			return
 		}
-		rows, err = db.ReaderDb.Query(query+` AND day = $1;`, day)
+		err = db.ReaderDb.Select(&data, query+` AND day = $1;`, day)
 	}
 
 	if err != nil {
@@ -242,20 +244,13 @@ func ApiEthStoreDay(w http.ResponseWriter, r *http.Request) {
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	addDayTime := func(dataEntryMap map[string]interface{}) error {
-		day, ok := dataEntryMap["day"].(int64)
-		if !ok {
-			return fmt.Errorf("error type asserting day as an int")
-		} else {
-			dataEntryMap["day_start"] = utils.DayToTime(day)
-			dataEntryMap["day_end"] = utils.DayToTime(day + 1)
-		}
-		return nil
+	for _, entry := range data {
+		entry.DayStart = utils.DayToTime(int64(entry.Day))
+		entry.DayEnd = utils.DayToTime(int64(entry.Day) + 1)
 	}
 
-	returnQueryResults(rows, w, r, addDayTime)
+	sendOKRowsResponse(w, r, data)
 }
 
 // ApiLatestState godoc
@@ -263,6 +258,7 @@ func ApiEthStoreDay(w http.ResponseWriter, r *http.Request) {
 // @Tags Network
 // @Description Returns information on the current state of the network
 // @Produce  json
+// @Success 200 {object} types.LatestState
 // @Failure 400 {object} types.ApiResponse "Failure"
 // @Failure 500 {object} types.ApiResponse "Server Error"
 // @Router /api/v1/latestState [get]
@@ -293,6 +289,7 @@ func ApiLatestState(w http.ResponseWriter, r *http.Request) {
 // @Produce  json
 // @Param  epoch path string true "Epoch number, the string latest or the string finalized"
 // @Success 200 {object} types.ApiResponse{data=types.APIEpochResponse} "Success"
+// @x-rows true
 // @Failure 400 {object} types.ApiResponse "Failure"
 // @Failure 500 {object} types.ApiResponse "Server Error"
 // @Router /api/v1/epoch/{epoch} [get]
@@ -326,7 +323,8 @@ func ApiEpoch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.ReaderDb.Query(`SELECT attestationscount, attesterslashingscount, averagevalidatorbalance, blockscount, depositscount, eligibleether, epoch, (epoch <= $2) AS finalized, globalparticipationrate, proposerslashingscount, rewards_exported, totalvalidatorbalance, validatorscount, voluntaryexitscount, votedether, COALESCE(withdrawalcount,0) as withdrawalcount, 
+	data := []*types.APIEpochResponse{}
+	err = db.ReaderDb.Select(&data, `SELECT attestationscount, attesterslashingscount, averagevalidatorbalance, blockscount, depositscount, eligibleether, epoch, (epoch <= $2) AS finalized, globalparticipationrate, proposerslashingscount, rewards_exported, totalvalidatorbalance, validatorscount, voluntaryexitscount, votedether, COALESCE(withdrawalcount,0) as withdrawalcount, 
 		(SELECT COUNT(*) FROM blocks WHERE epoch = $1 AND status = '0') as scheduledblocks,
 		(SELECT COUNT(*) FROM blocks WHERE epoch = $1 AND status = '1') as proposedblocks,
 		(SELECT COUNT(*) FROM blocks WHERE epoch = $1 AND status = '2') as missedblocks,
@@ -337,14 +335,12 @@ func ApiEpoch(w http.ResponseWriter, r *http.Request) {
 		sendServerErrorResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	addEpochTime := func(dataEntryMap map[string]interface{}) error {
-		dataEntryMap["ts"] = utils.EpochToTime(uint64(epoch))
-		return nil
+	for _, entry := range data {
+		entry.Ts = utils.EpochToTime(entry.Epoch)
 	}
 
-	returnQueryResults(rows, w, r, addEpochTime)
+	sendOKRowsResponse(w, r, data)
 }
 
 // ApiEpochSlots godoc
@@ -353,9 +349,10 @@ func ApiEpoch(w http.ResponseWriter, r *http.Request) {
 // @Description Returns all slots for a specified epoch
 // @Produce  json
 // @Param  epoch path string true "Epoch number, the string latest or string finalized"
-// @Success 200 {object} types.ApiResponse{data=[]types.APISlotResponse}
+// @Success 200 {object} types.ApiResponse{data=[]types.APIBlockResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/epoch/{epoch}/slots [get]
+// @Router /api/v1/epoch/{epoch}/blocks [get]
 func ApiEpochSlots(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	vars := mux.Vars(r)
@@ -384,14 +381,14 @@ func ApiEpochSlots(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.ReaderDb.Query("SELECT attestationscount, attesterslashingscount, blockroot, depositscount, epoch, eth1data_blockhash, eth1data_depositcount, eth1data_depositroot, exec_base_fee_per_gas, exec_block_hash, exec_block_number, exec_extra_data, exec_fee_recipient, exec_gas_limit, exec_gas_used, exec_logs_bloom, exec_parent_hash, exec_random, exec_receipts_root, exec_state_root, exec_timestamp, COALESCE(exec_transactions_count,0) as exec_transactions_count, graffiti, graffiti_text, parentroot, proposer, proposerslashingscount, randaoreveal, signature, slot, stateroot, status, syncaggregate_bits, syncaggregate_participation, syncaggregate_signature, voluntaryexitscount, COALESCE(withdrawalcount,0) as withdrawalcount FROM blocks WHERE epoch = $1 ORDER BY slot", epoch)
+	data := []*types.APIBlockResponse{}
+	err = db.ReaderDb.Select(&data, "SELECT attestationscount, attesterslashingscount, blockroot, depositscount, epoch, eth1data_blockhash, eth1data_depositcount, eth1data_depositroot, exec_base_fee_per_gas, exec_block_hash, exec_block_number, exec_extra_data, exec_fee_recipient, exec_gas_limit, exec_gas_used, exec_logs_bloom, exec_parent_hash, exec_random, exec_receipts_root, exec_state_root, exec_timestamp, COALESCE(exec_transactions_count,0) as exec_transactions_count, graffiti, graffiti_text, parentroot, proposer, proposerslashingscount, randaoreveal, signature, slot, stateroot, status, syncaggregate_bits, syncaggregate_participation, syncaggregate_signature, voluntaryexitscount, COALESCE(withdrawalcount,0) as withdrawalcount FROM blocks WHERE epoch = $1 ORDER BY slot", epoch)
 	if err != nil {
 		sendServerErrorResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	returnQueryResultsAsArray(rows, w, r)
+	sendOKArrayResponse(w, r, data)
 }
 
 // ApiSlots godoc
@@ -401,8 +398,10 @@ func ApiEpochSlots(w http.ResponseWriter, r *http.Request) {
 // @Produce  json
 // @Param  slotOrHash path string true "Slot or root hash or the string latest or head"
 // @Success 200 {object} types.ApiResponse{data=types.APISlotResponse}
+// @x-rows true
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/slot/{slotOrHash} [get]
+// @Router /api/v1/block/{slotOrHash} [get]
 func ApiSlots(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -445,7 +444,8 @@ func ApiSlots(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	rows, err := db.ReaderDb.Query(`
+	data := []*types.APISlotResponse{}
+	err := db.ReaderDb.Select(&data, `
 	SELECT
 		blocks.epoch,
 		blocks.slot,
@@ -497,9 +497,8 @@ func ApiSlots(w http.ResponseWriter, r *http.Request) {
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	returnQueryResults(rows, w, r)
+	sendOKRowsResponse(w, r, data)
 }
 
 // ApiSlotAttestations godoc
@@ -511,6 +510,7 @@ func ApiSlots(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} types.ApiResponse{data=[]types.APIAttestationResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/slot/{slot}/attestations [get]
+// @Router /api/v1/block/{slot}/attestations [get]
 func ApiSlotAttestations(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
@@ -537,15 +537,15 @@ func ApiSlotAttestations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.ReaderDb.Query("SELECT aggregationbits, beaconblockroot, block_index, block_root, block_slot, committeeindex, signature, slot, source_epoch, source_root, target_epoch, target_root, validators FROM blocks_attestations WHERE block_slot = $1 ORDER BY block_index", slot)
+	data := []*types.APIAttestationResponse{}
+	err = db.ReaderDb.Select(&data, "SELECT aggregationbits, beaconblockroot, block_index, block_root, block_slot, committeeindex, signature, slot, source_epoch, source_root, target_epoch, target_root, validators FROM blocks_attestations WHERE block_slot = $1 ORDER BY block_index", slot)
 	if err != nil {
 		logger.WithError(err).Error("could not retrieve db results")
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	returnQueryResultsAsArray(rows, w, r)
+	sendOKArrayResponse(w, r, data)
 }
 
 // ApiSlotAttesterSlashings godoc
@@ -557,6 +557,7 @@ func ApiSlotAttestations(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} types.ApiResponse{data=[]types.APIAttesterSlashingResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/slot/{slot}/attesterslashings [get]
+// @Router /api/v1/block/{slot}/attesterslashings [get]
 func ApiSlotAttesterSlashings(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -568,14 +569,14 @@ func ApiSlotAttesterSlashings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.ReaderDb.Query("SELECT attestation1_beaconblockroot, attestation1_index, attestation1_indices, attestation1_signature, attestation1_slot, attestation1_source_epoch, attestation1_source_root, attestation1_target_epoch, attestation1_target_root, attestation2_beaconblockroot, attestation2_index, attestation2_indices, attestation2_signature, attestation2_slot, attestation2_source_epoch, attestation2_source_root, attestation2_target_epoch, attestation2_target_root, block_index, block_root, block_slot FROM blocks_attesterslashings WHERE block_slot = $1 ORDER BY block_index DESC", slot)
+	data := []*types.APIAttesterSlashingResponse{}
+	err = db.ReaderDb.Select(&data, "SELECT attestation1_beaconblockroot, attestation1_index, attestation1_indices, attestation1_signature, attestation1_slot, attestation1_source_epoch, attestation1_source_root, attestation1_target_epoch, attestation1_target_root, attestation2_beaconblockroot, attestation2_index, attestation2_indices, attestation2_signature, attestation2_slot, attestation2_source_epoch, attestation2_source_root, attestation2_target_epoch, attestation2_target_root, block_index, block_root, block_slot FROM blocks_attesterslashings WHERE block_slot = $1 ORDER BY block_index DESC", slot)
 	if err != nil {
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	returnQueryResultsAsArray(rows, w, r)
+	sendOKArrayResponse(w, r, data)
 }
 
 // ApiSlotDeposits godoc
@@ -584,11 +585,12 @@ func ApiSlotAttesterSlashings(w http.ResponseWriter, r *http.Request) {
 // @Description Returns the deposits included in a specific block
 // @Produce  json
 // @Param  slot path string true "Block slot"
-// @Param  limit query string false "Limit the number of results"
-// @Param offset query string false "Offset the number of results"
-// @Success 200 {object} types.ApiResponse{[]APIAttestationResponse}
+// @Param  limit query int false "Limit the number of results"
+// @Param offset query int false "Offset the number of results"
+// @Success 200 {object} types.ApiResponse{data=[]types.APIDepositResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/slot/{slot}/deposits [get]
+// @Router /api/v1/block/{slot}/deposits [get]
 func ApiSlotDeposits(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -622,15 +624,15 @@ func ApiSlotDeposits(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.ReaderDb.Query("SELECT amount, block_index, block_root, block_slot, proof, publickey, signature, withdrawalcredentials FROM blocks_deposits WHERE block_slot = $1 ORDER BY block_index DESC limit $2 offset $3", slot, limit, offset)
+	data := []*types.APIDepositResponse{}
+	err = db.ReaderDb.Select(&data, "SELECT amount, block_index, block_root, block_slot, proof, publickey, signature, withdrawalcredentials FROM blocks_deposits WHERE block_slot = $1 ORDER BY block_index DESC limit $2 offset $3", slot, limit, offset)
 	if err != nil {
 		logger.WithError(err).Error("could not retrieve db results")
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	returnQueryResultsAsArray(rows, w, r)
+	sendOKArrayResponse(w, r, data)
 }
 
 // ApiSlotProposerSlashings godoc
@@ -642,6 +644,7 @@ func ApiSlotDeposits(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} types.ApiResponse{data=[]types.APIProposerSlashingResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/slot/{slot}/proposerslashings [get]
+// @Router /api/v1/block/{slot}/proposerslashings [get]
 func ApiSlotProposerSlashings(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
@@ -654,15 +657,55 @@ func ApiSlotProposerSlashings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.ReaderDb.Query("SELECT block_index, block_root, block_slot, header1_bodyroot, header1_parentroot, header1_signature, header1_slot, header1_stateroot, header2_bodyroot, header2_parentroot, header2_signature, header2_slot, header2_stateroot, proposerindex FROM blocks_proposerslashings WHERE block_slot = $1 ORDER BY block_index DESC", slot)
+	data := []*types.APIProposerSlashingResponse{}
+	err = db.ReaderDb.Select(&data, "SELECT block_index, block_root, block_slot, header1_bodyroot, header1_parentroot, header1_signature, header1_slot, header1_stateroot, header2_bodyroot, header2_parentroot, header2_signature, header2_slot, header2_stateroot, proposerindex FROM blocks_proposerslashings WHERE block_slot = $1 ORDER BY block_index DESC", slot)
+	if err != nil {
+		logger.WithError(err).Error("could not retrieve db results")
+		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	sendOKArrayResponse(w, r, data)
+}
+
+// ApiReorgs godoc
+// @Summary Get the most recent chain reorganizations
+// @Tags Slot
+// @Description Returns the chain reorganizations detected by the explorer, including their depth, the old and new heads and the proposers of the orphaned blocks
+// @Produce  json
+// @Param  limit query int false "Limit the number of results (maximum 100)"
+// @Param offset query int false "Offset the number of results"
+// @Success 200 {object} types.ApiResponse{data=[]types.APIReorgResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/reorgs [get]
+func ApiReorgs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	q := r.URL.Query()
+
+	offset, err := strconv.ParseInt(q.Get("offset"), 10, 64)
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	limit, err := strconv.ParseInt(q.Get("limit"), 10, 64)
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 100
+	}
+
+	data := []*types.APIReorgResponse{}
+	err = db.ReaderDb.Select(&data, `
+		SELECT slot, depth, common_ancestor_slot, old_head_root, new_head_root, old_head_proposer, new_head_proposer, orphaned_slots, orphaned_proposers, detected_at
+		FROM reorgs
+		ORDER BY slot DESC, id DESC
+		LIMIT $1 OFFSET $2`, limit, offset)
 	if err != nil {
 		logger.WithError(err).Error("could not retrieve db results")
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	returnQueryResultsAsArray(rows, w, r)
+	sendOKArrayResponse(w, r, data)
 }
 
 // ApiSlotVoluntaryExits godoc
@@ -674,6 +717,7 @@ func ApiSlotProposerSlashings(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} types.ApiResponse{data=[]types.APIVoluntaryExitResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/slot/{slot}/voluntaryexits [get]
+// @Router /api/v1/block/{slot}/voluntaryexits [get]
 func ApiSlotVoluntaryExits(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
@@ -686,15 +730,15 @@ func ApiSlotVoluntaryExits(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.ReaderDb.Query("SELECT block_slot, block_index, block_root, epoch, validatorindex, signature FROM blocks_voluntaryexits WHERE block_slot = $1 ORDER BY block_index DESC", slot)
+	data := []*types.APIVoluntaryExitResponse{}
+	err = db.ReaderDb.Select(&data, "SELECT block_slot, block_index, block_root, epoch, validatorindex, signature FROM blocks_voluntaryexits WHERE block_slot = $1 ORDER BY block_index DESC", slot)
 	if err != nil {
 		logger.WithError(err).Error("could not retrieve db results")
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	returnQueryResultsAsArray(rows, w, r)
+	sendOKArrayResponse(w, r, data)
 }
 
 // ApiSlotWithdrawals godoc
@@ -703,7 +747,8 @@ func ApiSlotVoluntaryExits(w http.ResponseWriter, r *http.Request) {
 // @Description Returns the withdrawals included in a specific slot
 // @Produce json
 // @Param slot path string true "Block slot"
-// @Success 200 {object} types.ApiResponse
+// @Success 200 {object} types.ApiResponse{data=types.APISlotWithdrawalResponse}
+// @x-rows true
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/slot/{slot}/withdrawals [get]
 func ApiSlotWithdrawals(w http.ResponseWriter, r *http.Request) {
@@ -716,14 +761,124 @@ func ApiSlotWithdrawals(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.ReaderDb.Query("SELECT block_slot, withdrawalindex, validatorindex, address, amount FROM blocks_withdrawals WHERE block_slot = $1 ORDER BY withdrawalindex", slot)
+	data := []*types.APISlotWithdrawalResponse{}
+	err = db.ReaderDb.Select(&data, "SELECT block_slot, withdrawalindex, validatorindex, address, amount FROM blocks_withdrawals WHERE block_slot = $1 ORDER BY withdrawalindex", slot)
 	if err != nil {
 		logger.WithError(err).Error("error getting blocks_withdrawals")
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
-	returnQueryResults(rows, w, r)
+
+	sendOKRowsResponse(w, r, data)
+}
+
+// ApiSlotDepositRequests godoc
+// @Summary Get the execution layer deposit requests included in a specific slot
+// @Tags Slot
+// @Description Returns the deposit requests (EIP-6110) included in a specific slot
+// @Produce json
+// @Param slot path string true "Block slot"
+// @Success 200 {object} types.ApiResponse{data=[]types.APIDepositRequestResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/slot/{slot}/depositrequests [get]
+func ApiSlotDepositRequests(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+
+	slot, err := strconv.ParseInt(vars["slot"], 10, 64)
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), "invalid block slot provided")
+		return
+	}
+
+	data := []*types.APIDepositRequestResponse{}
+	err = db.ReaderDb.Select(&data, `
+		SELECT r.block_slot, r.request_index, v.validatorindex, r.pubkey, r.withdrawal_credentials, r.amount, r.signature, r.deposit_index
+		FROM blocks_deposit_requests r
+		INNER JOIN blocks b ON b.blockroot = r.block_root AND b.status = '1'
+		LEFT JOIN validators v ON v.pubkey = r.pubkey
+		WHERE r.block_slot = $1
+		ORDER BY r.request_index`, slot)
+	if err != nil {
+		logger.WithError(err).Error("error getting blocks_deposit_requests")
+		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	sendOKArrayResponse(w, r, data)
+}
+
+// ApiSlotWithdrawalRequests godoc
+// @Summary Get the execution layer withdrawal requests included in a specific slot
+// @Tags Slot
+// @Description Returns the withdrawal requests (EIP-7002) included in a specific slot. An amount of 0 requests a full exit of the validator.
+// @Produce json
+// @Param slot path string true "Block slot"
+// @Success 200 {object} types.ApiResponse{data=[]types.APIWithdrawalRequestResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/slot/{slot}/withdrawalrequests [get]
+func ApiSlotWithdrawalRequests(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+
+	slot, err := strconv.ParseInt(vars["slot"], 10, 64)
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), "invalid block slot provided")
+		return
+	}
+
+	data := []*types.APIWithdrawalRequestResponse{}
+	err = db.ReaderDb.Select(&data, `
+		SELECT r.block_slot, r.request_index, v.validatorindex, r.validator_pubkey, r.source_address, r.amount
+		FROM blocks_withdrawal_requests r
+		INNER JOIN blocks b ON b.blockroot = r.block_root AND b.status = '1'
+		LEFT JOIN validators v ON v.pubkey = r.validator_pubkey
+		WHERE r.block_slot = $1
+		ORDER BY r.request_index`, slot)
+	if err != nil {
+		logger.WithError(err).Error("error getting blocks_withdrawal_requests")
+		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	sendOKArrayResponse(w, r, data)
+}
+
+// ApiSlotConsolidations godoc
+// @Summary Get the consolidation requests included in a specific slot
+// @Tags Slot
+// @Description Returns the consolidation requests (EIP-7251) included in a specific slot
+// @Produce json
+// @Param slot path string true "Block slot"
+// @Success 200 {object} types.ApiResponse{data=[]types.APIConsolidationRequestResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/slot/{slot}/consolidations [get]
+func ApiSlotConsolidations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+
+	slot, err := strconv.ParseInt(vars["slot"], 10, 64)
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), "invalid block slot provided")
+		return
+	}
+
+	data := []*types.APIConsolidationRequestResponse{}
+	err = db.ReaderDb.Select(&data, `
+		SELECT r.block_slot, r.request_index, r.source_address, sv.validatorindex AS source_validatorindex, r.source_pubkey, tv.validatorindex AS target_validatorindex, r.target_pubkey
+		FROM blocks_consolidation_requests r
+		INNER JOIN blocks b ON b.blockroot = r.block_root AND b.status = '1'
+		LEFT JOIN validators sv ON sv.pubkey = r.source_pubkey
+		LEFT JOIN validators tv ON tv.pubkey = r.target_pubkey
+		WHERE r.block_slot = $1
+		ORDER BY r.request_index`, slot)
+	if err != nil {
+		logger.WithError(err).Error("error getting blocks_consolidation_requests")
+		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	sendOKArrayResponse(w, r, data)
 }
 
 // ApiBlockVoluntaryExits godoc
@@ -736,6 +891,7 @@ func ApiSlotWithdrawals(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param period path string true "Period ('latest' for latest period or 'next' for next period in the future)"
 // @Success 200 {object} types.ApiResponse{data=types.APISyncCommitteeResponse}
+// @x-rows true
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/sync_committee/{period} [get]
 func ApiSyncCommittee(w http.ResponseWriter, r *http.Request) {
@@ -757,15 +913,15 @@ func ApiSyncCommittee(w http.ResponseWriter, r *http.Request) {
 
 	// Beware that we do not deduplicate here since a validator can be part multiple times of the same sync committee period
 	// and the order of the committeeindex is important, deduplicating it would mess up the order
-	rows, err := db.ReaderDb.Query(`SELECT period, GREATEST(period*$2, $3) AS start_epoch, ((period+1)*$2)-1 AS end_epoch, ARRAY_AGG(validatorindex ORDER BY committeeindex) AS validators FROM sync_committees WHERE period = $1 GROUP BY period`, period, utils.Config.Chain.ClConfig.EpochsPerSyncCommitteePeriod, utils.Config.Chain.ClConfig.AltairForkEpoch)
+	data := []*types.APISyncCommitteeResponse{}
+	err = db.ReaderDb.Select(&data, `SELECT period, GREATEST(period*$2, $3) AS start_epoch, ((period+1)*$2)-1 AS end_epoch, ARRAY_AGG(validatorindex ORDER BY committeeindex) AS validators FROM sync_committees WHERE period = $1 GROUP BY period`, period, utils.Config.Chain.ClConfig.EpochsPerSyncCommitteePeriod, utils.Config.Chain.ClConfig.AltairForkEpoch)
 	if err != nil {
 		logger.WithError(err).WithField("url", r.URL.String()).Errorf("error querying db")
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	returnQueryResults(rows, w, r)
+	sendOKRowsResponse(w, r, data)
 }
 
 // ApiValidatorQueue godoc
@@ -774,19 +930,20 @@ func ApiSyncCommittee(w http.ResponseWriter, r *http.Request) {
 // @Description Returns the current number of validators entering and exiting the beacon chain
 // @Produce  json
 // @Success 200 {object} types.ApiResponse{data=types.ApiValidatorQueueResponse}
+// @x-rows true
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/validators/queue [get]
 func ApiValidatorQueue(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	rows, err := db.ReaderDb.Query("SELECT e.validatorscount, q.entering_validators_count as beaconchain_entering, q.exiting_validators_count as beaconchain_exiting FROM epochs e, queue q ORDER BY e.epoch DESC, q.ts DESC LIMIT 1")
+	data := []*types.ApiValidatorQueueResponse{}
+	err := db.ReaderDb.Select(&data, "SELECT e.validatorscount, q.entering_validators_count as beaconchain_entering, q.exiting_validators_count as beaconchain_exiting FROM epochs e, queue q ORDER BY e.epoch DESC, q.ts DESC LIMIT 1")
 	if err != nil {
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	returnQueryResults(rows, w, r)
+	sendOKRowsResponse(w, r, data)
 }
 
 // ApiRocketpoolStats godoc
@@ -794,14 +951,13 @@ func ApiValidatorQueue(w http.ResponseWriter, r *http.Request) {
 // @Tags Rocketpool
 // @Produce  json
 // @Success 200 {object} types.ApiResponse{data=types.APIRocketpoolStatsResponse}
+// @x-rows true
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/rocketpool/stats [get]
 func ApiRocketpoolStats(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 
-	j := json.NewEncoder(w)
-
 	stats, err := getRocketpoolStats()
 
 	if err != nil {
@@ -809,7 +965,7 @@ func ApiRocketpoolStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	SendOKResponse(j, r.URL.String(), stats)
+	sendOKRowsResponse(w, r, stats)
 }
 
 // ApiRocketpoolValidators godoc
@@ -818,13 +974,13 @@ func ApiRocketpoolStats(w http.ResponseWriter, r *http.Request) {
 // @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
 // @Produce  json
 // @Success 200 {object} types.ApiResponse{data=types.ApiRocketpoolValidatorResponse}
+// @x-rows true
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/rocketpool/validator/{indexOrPubkey} [get]
 func ApiRocketpoolValidators(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 
-	j := json.NewEncoder(w)
 	vars := mux.Vars(r)
 	maxValidators := getUserPremium(r).MaxValidators
 
@@ -841,13 +997,18 @@ func ApiRocketpoolValidators(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	SendOKResponse(j, r.URL.String(), stats)
+	sendOKRowsResponse(w, r, stats)
 }
 
-/*
-Combined validator get, performance, attestation efficency, sync committee statistics, epoch, historic epoch and rpl
-Not public documented
-*/
+// ApiDashboard godoc
+// @Summary Get the app dashboard of up to 100 validators
+// @Tags Mobile
+// @Description Combined validator get, performance, attestation efficency, sync committee statistics, epoch, historic epoch and rpl
+// @Produce json
+// @Param request body types.DashboardRequest true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Success 200 {object} types.ApiResponse{data=DashboardResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/app/dashboard [post]
 func ApiDashboard(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -874,15 +1035,15 @@ func ApiDashboard(w http.ResponseWriter, r *http.Request) {
 
 	g, _ := errgroup.WithContext(context.Background())
 	g.SetLimit(5) // limit concurrency
-	var validatorsData []interface{}
+	var validatorsData []types.ApiDashboardValidatorResponse
 	var validatorEffectivenessData []*types.ValidatorEffectiveness
-	var rocketpoolData []interface{}
-	var rocketpoolStats []interface{}
-	var currentEpochData []interface{}
+	var rocketpoolData []types.ApiRocketpoolValidatorResponse
+	var rocketpoolStats []types.APIRocketpoolStatsResponse
+	var currentEpochData []types.ApiDashboardEpochResponse
 	var executionPerformance []types.ExecutionPerformanceResponse
-	var olderEpochData []interface{}
-	var currentSyncCommittee []interface{}
-	var nextSyncCommittee []interface{}
+	var olderEpochData []types.ApiDashboardEpochResponse
+	var currentSyncCommittee []types.APISyncCommitteeResponse
+	var nextSyncCommittee []types.APISyncCommitteeResponse
 	var syncCommitteeStats *SyncCommitteesInfo
 	var proposalLuckStats *types.ApiProposalLuckResponse
 
@@ -1044,8 +1205,9 @@ func ApiDashboard(w http.ResponseWriter, r *http.Request) {
 	SendOKResponse(j, r.URL.String(), []interface{}{data})
 }
 
-func getSyncCommitteeInfoForValidators(validators []uint64, period uint64) ([]interface{}, error) {
-	rows, err := db.ReaderDb.Query(`
+func getSyncCommitteeInfoForValidators(validators []uint64, period uint64) ([]types.APISyncCommitteeResponse, error) {
+	data := []types.APISyncCommitteeResponse{}
+	err := db.ReaderDb.Select(&data, `
 			WITH
 				data as (
 					SELECT 
@@ -1069,8 +1231,7 @@ func getSyncCommitteeInfoForValidators(validators []uint64, period uint64) ([]in
 	if err != nil {
 		return nil, fmt.Errorf("could not get sync committee for period %d: %w", period, err)
 	}
-	defer rows.Close()
-	return utils.SqlRowsToJSON(rows)
+	return data, nil
 }
 
 func getSyncCommitteeStatistics(validators []uint64, epoch uint64) (*SyncCommitteesInfo, error) {
@@ -1322,15 +1483,16 @@ type Cached struct {
 
 var rocketpoolStats atomic.Value
 
-func getRocketpoolStats() ([]interface{}, error) {
+func getRocketpoolStats() ([]types.APIRocketpoolStatsResponse, error) {
 	cached := rocketpoolStats.Load()
 	if cached != nil {
 		cachedObj := cached.(*Cached)
 		if cachedObj.Ts+10*60 > time.Now().Unix() { // cache for 30min
-			return cachedObj.Data.([]interface{}), nil
+			return cachedObj.Data.([]types.APIRocketpoolStatsResponse), nil
 		}
 	}
-	rows, err := db.ReaderDb.Query(`
+	data := []types.APIRocketpoolStatsResponse{}
+	err := db.ReaderDb.Select(&data, `
 		SELECT claim_interval_time, claim_interval_time_start, 
 		current_node_demand, TRUNC(current_node_fee::decimal, 10)::float as current_node_fee, effective_rpl_staked,
 		node_operator_rewards, TRUNC(reth_exchange_rate::decimal, 10)::float as reth_exchange_rate, reth_supply, rpl_price, total_eth_balance, total_eth_staking, 
@@ -1342,11 +1504,6 @@ func getRocketpoolStats() ([]interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	data, err := utils.SqlRowsToJSON(rows)
-	if err != nil {
-		return nil, err
-	}
 
 	rocketpoolStats.Store(&Cached{
 		Data: data,
@@ -1356,8 +1513,9 @@ func getRocketpoolStats() ([]interface{}, error) {
 	return data, nil
 }
 
-func getRocketpoolValidators(queryIndices []uint64) ([]interface{}, error) {
-	rows, err := db.ReaderDb.Query(`
+func getRocketpoolValidators(queryIndices []uint64) ([]types.ApiRocketpoolValidatorResponse, error) {
+	data := []types.ApiRocketpoolValidatorResponse{}
+	err := db.ReaderDb.Select(&data, `
 		SELECT
 			rplm.node_address      AS node_address,
 			rplm.address           AS minipool_address,
@@ -1391,14 +1549,14 @@ func getRocketpoolValidators(queryIndices []uint64) ([]interface{}, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error querying rocketpool minipools: %w", err)
 	}
-	defer rows.Close()
 
-	return utils.SqlRowsToJSON(rows)
+	return data, nil
 }
 
-func getGeneralValidatorInfoForAppDashboard(queryIndices []uint64) ([]interface{}, error) {
+func getGeneralValidatorInfoForAppDashboard(queryIndices []uint64) ([]types.ApiDashboardValidatorResponse, error) {
 	// we use MAX(validatorindex)+1 instead of COUNT(*) for querying the rank_count for performance-reasons
-	rows, err := db.ReaderDb.Query(`
+	data := []types.ApiDashboardValidatorResponse{}
+	err := db.ReaderDb.Select(&data, `
 	WITH maxValidatorIndex AS (
 		SELECT MAX(validatorindex)+1 as total_count
 		FROM validator_performance
@@ -1430,12 +1588,6 @@ func getGeneralValidatorInfoForAppDashboard(queryIndices []uint64) ([]interface{
 	if err != nil {
 		return nil, fmt.Errorf("error querying validators: %w", err)
 	}
-	defer rows.Close()
-
-	data, err := utils.SqlRowsToJSON(rows)
-	if err != nil {
-		return nil, fmt.Errorf("error converting validators to json: %w", err)
-	}
 
 	g := new(errgroup.Group)
 
@@ -1474,36 +1626,18 @@ func getGeneralValidatorInfoForAppDashboard(queryIndices []uint64) ([]interface{
 		return nil, fmt.Errorf("error in validator errgroup: %w", err)
 	}
 
-	for _, entry := range data {
-		eMap, ok := entry.(map[string]interface{})
-		if !ok {
-			logger.Errorf("error converting validator data to map[string]interface{}")
-			continue
-		}
+	for i := range data {
+		entry := &data[i]
+		entry.LastAttestationSlot = lastAttestationSlots[entry.ValidatorIndex]
 
-		validatorIndex, ok := eMap["validatorindex"].(int64)
-		if !ok {
-			logger.Errorf("error converting validatorindex to int64")
+		balance := balances[entry.ValidatorIndex]
+		if len(balance) == 0 {
 			continue
 		}
-		eMap["lastattestationslot"] = lastAttestationSlots[uint64(validatorIndex)]
-
-		for balanceIndex, balance := range balances {
-			if len(balance) == 0 {
-				continue
-			}
-
-			if !ok {
-				logger.Errorf("error converting validatorindex to int64")
-				continue
-			}
-			if int64(balanceIndex) == validatorIndex {
-				eMap["balance"] = balance[0].Balance
-				eMap["effectivebalance"] = balance[0].EffectiveBalance
-				eMap["performance1d"] = currentDayIncome[uint64(validatorIndex)]
-				eMap["performancetotal"] = eMap["performancetotal"].(int64) + currentDayIncome[uint64(validatorIndex)]
-			}
-		}
+		entry.Balance = &balance[0].Balance
+		entry.EffectiveBalance = &balance[0].EffectiveBalance
+		entry.Performance1d = currentDayIncome[entry.ValidatorIndex]
+		entry.PerformanceTotal += currentDayIncome[entry.ValidatorIndex]
 	}
 
 	return data, nil
@@ -1527,30 +1661,30 @@ type SyncCommitteesInfo struct {
 }
 
 type DashboardResponse struct {
-	Validators           interface{}                          `json:"validators"`
-	Effectiveness        interface{}                          `json:"effectiveness"`
-	CurrentEpoch         interface{}                          `json:"currentEpoch"`
-	OlderEpoch           interface{}                          `json:"olderEpoch"`
-	Rocketpool           interface{}                          `json:"rocketpool_validators"`
-	RocketpoolStats      interface{}                          `json:"rocketpool_network_stats"`
-	ExecutionPerformance []types.ExecutionPerformanceResponse `json:"execution_performance"`
-	CurrentSyncCommittee interface{}                          `json:"current_sync_committee"`
-	NextSyncCommittee    interface{}                          `json:"next_sync_committee"`
-	SyncCommitteesStats  *SyncCommitteesInfo                  `json:"sync_committees_stats"`
-	ProposalLuckStats    *types.ApiProposalLuckResponse       `json:"proposal_luck_stats"`
+	Validators           []types.ApiDashboardValidatorResponse  `json:"validators"`
+	Effectiveness        []*types.ValidatorEffectiveness        `json:"effectiveness"`
+	CurrentEpoch         []types.ApiDashboardEpochResponse      `json:"currentEpoch"`
+	OlderEpoch           []types.ApiDashboardEpochResponse      `json:"olderEpoch"`
+	Rocketpool           []types.ApiRocketpoolValidatorResponse `json:"rocketpool_validators"`
+	RocketpoolStats      []types.APIRocketpoolStatsResponse     `json:"rocketpool_network_stats"`
+	ExecutionPerformance []types.ExecutionPerformanceResponse   `json:"execution_performance"`
+	CurrentSyncCommittee []types.APISyncCommitteeResponse       `json:"current_sync_committee"`
+	NextSyncCommittee    []types.APISyncCommitteeResponse       `json:"next_sync_committee"`
+	SyncCommitteesStats  *SyncCommitteesInfo                    `json:"sync_committees_stats"`
+	ProposalLuckStats    *types.ApiProposalLuckResponse         `json:"proposal_luck_stats"`
 }
 
-func getEpoch(epoch int64) ([]interface{}, error) {
+func getEpoch(epoch int64) ([]types.ApiDashboardEpochResponse, error) {
 	latestFinalizedEpoch := services.LatestFinalizedEpoch()
-	rows, err := db.ReaderDb.Query(`SELECT attestationscount, attesterslashingscount, averagevalidatorbalance,
+	data := []types.ApiDashboardEpochResponse{}
+	err := db.ReaderDb.Select(&data, `SELECT attestationscount, attesterslashingscount, averagevalidatorbalance,
 	blockscount, depositscount, eligibleether, epoch, (epoch <= $2) AS finalized, TRUNC(globalparticipationrate::decimal, 10)::float as globalparticipationrate, proposerslashingscount,
 	totalvalidatorbalance, validatorscount, voluntaryexitscount, votedether
 	FROM epochs WHERE epoch = $1`, epoch, latestFinalizedEpoch)
 	if err != nil {
 		return nil, fmt.Errorf("error querying epoch: %w", err)
 	}
-	defer rows.Close()
-	return utils.SqlRowsToJSON(rows)
+	return data, nil
 }
 
 // ApiValidator godoc
@@ -1559,7 +1693,8 @@ func getEpoch(epoch int64) ([]interface{}, error) {
 // @Description Searching for too many validators based on their pubkeys will lead to a "URI too long" error
 // @Produce  json
 // @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
-// @Success 200 {object} types.ApiResponse{data=[]types.APIValidatorResponse}
+// @Success 200 {object} types.ApiResponse{data=ApiValidatorResponse}
+// @x-rows true
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/validator/{indexOrPubkey} [get]
 func ApiValidatorGet(w http.ResponseWriter, r *http.Request) {
@@ -1572,7 +1707,8 @@ func ApiValidatorGet(w http.ResponseWriter, r *http.Request) {
 // @Description This POST endpoint exists because the GET endpoint can lead to a "URI too long" error when searching for too many validators based on their pubkeys.
 // @Produce  json
 // @Param  indexOrPubkey body types.DashboardRequest true "Up to 100 validator indicesOrPubkeys, comma separated"
-// @Success 200 {object} types.ApiResponse{data=[]types.APIValidatorResponse}
+// @Success 200 {object} types.ApiResponse{data=ApiValidatorResponse}
+// @x-rows true
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/validator [post]
 func ApiValidatorPost(w http.ResponseWriter, r *http.Request) {
@@ -1693,6 +1829,12 @@ func getApiValidator(w http.ResponseWriter, r *http.Request) {
 
 	for _, validator := range data {
 		validator.Lastattestationslot = int64(lastAttestationSlots[uint64(validator.Validatorindex)])
+
+		credentials, err := hex.DecodeString(strings.TrimPrefix(validator.Withdrawalcredentials, "0x"))
+		if err != nil {
+			logger.Warnf("error decoding withdrawal credentials of validator %v: %v", validator.Validatorindex, err)
+		}
+		validator.WithdrawalCredentialsType = utils.WithdrawalCredentialsType(credentials)
 	}
 
 	j := json.NewEncoder(w)
@@ -1726,6 +1868,7 @@ type ApiValidatorResponse struct {
 	Validatorindex             int64  `json:"validatorindex"`
 	Withdrawableepoch          int64  `json:"withdrawableepoch"`
 	Withdrawalcredentials      string `json:"withdrawalcredentials"`
+	WithdrawalCredentialsType  string `json:"withdrawal_credentials_type"` // one of bls, execution, compounding or unknown
 	TotalWithdrawals           uint64 `json:"total_withdrawals" db:"total_withdrawals"`
 }
 
@@ -1734,8 +1877,8 @@ type ApiValidatorResponse struct {
 // @Tags Validator
 // @Produce  json
 // @Param  index path string true "Validator index"
-// @Param  end_day query string false "End day (default: latest day)"
-// @Param  start_day query string false "Start day (default: 0)"
+// @Param  end_day query int false "End day (default: latest day)"
+// @Param  start_day query int false "Start day (default: 0)"
 // @Success 200 {object} types.ApiResponse{data=[]types.ApiValidatorDailyStatsResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/validator/stats/{index} [get]
@@ -1784,7 +1927,8 @@ func ApiValidatorDailyStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.ReaderDb.Query(`
+	data := []*types.ApiValidatorDailyStatsResponse{}
+	err = db.ReaderDb.Select(&data, `
 		SELECT 
 		validatorindex,
 		day,
@@ -1815,32 +1959,25 @@ func ApiValidatorDailyStats(w http.ResponseWriter, r *http.Request) {
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	addDayTime := func(dataEntryMap map[string]interface{}) error {
-		day, ok := dataEntryMap["day"].(int64)
-		if !ok {
-			return fmt.Errorf("error type asserting day as an int")
-		} else {
-			dataEntryMap["day_start"] = utils.DayToTime(day)
-			dataEntryMap["day_end"] = utils.DayToTime(day + 1)
-		}
-		return nil
+	for _, entry := range data {
+		entry.DayStart = utils.DayToTime(int64(entry.Day))
+		entry.DayEnd = utils.DayToTime(int64(entry.Day) + 1)
 	}
 
-	returnQueryResultsAsArray(rows, w, r, addDayTime)
+	sendOKArrayResponse(w, r, data)
 }
 
 // ApiValidatorByEth1Address godoc
 // @Summary Get all validators that belong to an eth1 address
 // @Tags Validator
 // @Produce  json
-// @Param  eth1address path string true "Eth1 address from which the validator deposits were sent". It can also be a valid ENS name.
-// @Param limit query string false "Limit the number of results (default: 2000)"
-// @Param offset query string false "Offset the results (default: 0)"
+// @Param  address path string true "Eth1 address from which the validator deposits were sent". It can also be a valid ENS name.
+// @Param limit query int false "Limit the number of results (default: 2000)"
+// @Param offset query int false "Offset the results (default: 0)"
 // @Success 200 {object} types.ApiResponse{data=[]types.ApiValidatorEth1Response}
 // @Failure 400 {object} types.ApiResponse
-// @Router /api/v1/validator/eth1/{eth1address} [get]
+// @Router /api/v1/validator/eth1/{address} [get]
 func ApiValidatorByEth1Address(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
@@ -1874,14 +2011,14 @@ func ApiValidatorByEth1Address(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.ReaderDb.Query("SELECT publickey, validatorindex, valid_signature FROM eth1_deposits LEFT JOIN validators ON eth1_deposits.publickey = validators.pubkey WHERE from_address = $1 GROUP BY publickey, validatorindex, valid_signature ORDER BY validatorindex OFFSET $2 LIMIT $3;", eth1Address, offset, limit)
+	data := []*types.ApiValidatorEth1Response{}
+	err = db.ReaderDb.Select(&data, "SELECT publickey, validatorindex, valid_signature FROM eth1_deposits LEFT JOIN validators ON eth1_deposits.publickey = validators.pubkey WHERE from_address = $1 GROUP BY publickey, validatorindex, valid_signature ORDER BY validatorindex OFFSET $2 LIMIT $3;", eth1Address, offset, limit)
 	if err != nil {
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	returnQueryResultsAsArray(rows, w, r)
+	sendOKArrayResponse(w, r, data)
 }
 
 // ApiValidator godoc
@@ -1926,52 +2063,7 @@ func ApiValidatorIncomeDetailsHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	responseData := make([]*types.ApiValidatorIncomeHistoryResponse, 0, uint64(len(history))*limit)
-
-	epochsPerWeek := utils.EpochsPerDay() * 7
-	for validatorIndex, epochs := range history {
-		for epoch, income := range epochs {
-			epochAtStartOfTheWeek := (epoch / epochsPerWeek) * epochsPerWeek
-
-			txFeeRewardWei := ""
-			if len(income.TxFeeRewardWei) > 0 {
-				txFeeRewardWei = new(big.Int).SetBytes(income.TxFeeRewardWei).String()
-			}
-
-			responseIncome := &types.ApiValidatorIncomeHistory{
-				AttestationSourceReward:            income.AttestationSourceReward,
-				AttestationSourcePenalty:           income.AttestationSourcePenalty,
-				AttestationTargetReward:            income.AttestationTargetReward,
-				AttestationTargetPenalty:           income.AttestationTargetPenalty,
-				AttestationHeadReward:              income.AttestationHeadReward,
-				FinalityDelayPenalty:               income.FinalityDelayPenalty,
-				ProposerSlashingInclusionReward:    income.ProposerSlashingInclusionReward,
-				ProposerAttestationInclusionReward: income.ProposerAttestationInclusionReward,
-				ProposerSyncInclusionReward:        income.ProposerSyncInclusionReward,
-				SyncCommitteeReward:                income.SyncCommitteeReward,
-				SyncCommitteePenalty:               income.SyncCommitteePenalty,
-				SlashingReward:                     income.SlashingReward,
-				SlashingPenalty:                    income.SlashingPenalty,
-				TxFeeRewardWei:                     txFeeRewardWei,
-				ProposalsMissed:                    income.ProposalsMissed}
-
-			responseData = append(responseData, &types.ApiValidatorIncomeHistoryResponse{
-				Income:         responseIncome,
-				Epoch:          epoch,
-				ValidatorIndex: validatorIndex,
-				Week:           epoch / epochsPerWeek,
-				WeekStart:      utils.EpochToTime(epochAtStartOfTheWeek),
-				WeekEnd:        utils.EpochToTime(epochAtStartOfTheWeek + epochsPerWeek),
-			})
-		}
-	}
-
-	sort.Slice(responseData, func(i, j int) bool {
-		if responseData[i].Epoch != responseData[j].Epoch {
-			return responseData[i].Epoch > responseData[j].Epoch
-		}
-		return responseData[i].ValidatorIndex < responseData[j].ValidatorIndex
-	})
+	responseData := apiValidatorIncomeDetailsHistory(history)
 
 	response := &types.ApiResponse{}
 	response.Status = "OK"
@@ -2189,30 +2281,7 @@ func ApiValidatorBalanceHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	responseData := make([]*types.ApiValidatorBalanceHistoryResponse, 0, len(history)*101)
-
-	epochsPerWeek := utils.EpochsPerDay() * 7
-	for validatorIndex, balances := range history {
-		for _, balance := range balances {
-			epochAtStartOfTheWeek := (balance.Epoch / epochsPerWeek) * epochsPerWeek
-			responseData = append(responseData, &types.ApiValidatorBalanceHistoryResponse{
-				Balance:          balance.Balance,
-				EffectiveBalance: balance.EffectiveBalance,
-				Epoch:            balance.Epoch,
-				Validatorindex:   validatorIndex,
-				Week:             balance.Epoch / epochsPerWeek,
-				WeekStart:        utils.EpochToTime(epochAtStartOfTheWeek),
-				WeekEnd:          utils.EpochToTime(epochAtStartOfTheWeek + epochsPerWeek),
-			})
-		}
-	}
-
-	sort.Slice(responseData, func(i, j int) bool {
-		if responseData[i].Epoch != responseData[j].Epoch {
-			return responseData[i].Epoch > responseData[j].Epoch
-		}
-		return responseData[i].Validatorindex < responseData[j].Validatorindex
-	})
+	responseData := apiValidatorBalanceHistory(history)
 
 	response := &types.ApiResponse{}
 	response.Status = "OK"
@@ -2260,35 +2329,150 @@ func getBalanceHistoryQueryParameters(q url.Values) (uint64, uint64, error) {
 	return latestEpoch, limit, nil
 }
 
-// ApiValidatorPerformance godoc
-// @Summary Get the current consensus reward performance of up to 100 validators
-// @Tags Validator
-// @Produce  json
-// @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
-// @Success 200 {object} types.ApiResponse{data=[]types.ApiValidatorPerformanceResponse}
-// @Failure 400 {object} types.ApiResponse
-// @Router /api/v1/validator/{indexOrPubkey}/performance [get]
-func ApiValidatorPerformance(w http.ResponseWriter, r *http.Request) {
+// apiValidatorIncomeDetailsHistory converts the income details history of validators to api responses, ordered by epoch descending and validator index ascending
+func apiValidatorIncomeDetailsHistory(history map[uint64]map[uint64]*itypes.ValidatorEpochIncome) []*types.ApiValidatorIncomeHistoryResponse {
+	responseData := make([]*types.ApiValidatorIncomeHistoryResponse, 0, len(history)*100)
 
-	w.Header().Set("Content-Type", "application/json")
+	epochsPerWeek := utils.EpochsPerDay() * 7
+	for validatorIndex, epochs := range history {
+		for epoch, income := range epochs {
+			epochAtStartOfTheWeek := (epoch / epochsPerWeek) * epochsPerWeek
 
-	vars := mux.Vars(r)
-	maxValidators := getUserPremium(r).MaxValidators
+			txFeeRewardWei := ""
+			if len(income.TxFeeRewardWei) > 0 {
+				txFeeRewardWei = new(big.Int).SetBytes(income.TxFeeRewardWei).String()
+			}
 
-	queryIndices, err := parseApiValidatorParamToIndices(vars["indexOrPubkey"], maxValidators)
-	if err != nil {
-		SendBadRequestResponse(w, r.URL.String(), err.Error())
-		return
+			responseIncome := &types.ApiValidatorIncomeHistory{
+				AttestationSourceReward:            income.AttestationSourceReward,
+				AttestationSourcePenalty:           income.AttestationSourcePenalty,
+				AttestationTargetReward:            income.AttestationTargetReward,
+				AttestationTargetPenalty:           income.AttestationTargetPenalty,
+				AttestationHeadReward:              income.AttestationHeadReward,
+				FinalityDelayPenalty:               income.FinalityDelayPenalty,
+				ProposerSlashingInclusionReward:    income.ProposerSlashingInclusionReward,
+				ProposerAttestationInclusionReward: income.ProposerAttestationInclusionReward,
+				ProposerSyncInclusionReward:        income.ProposerSyncInclusionReward,
+				SyncCommitteeReward:                income.SyncCommitteeReward,
+				SyncCommitteePenalty:               income.SyncCommitteePenalty,
+				SlashingReward:                     income.SlashingReward,
+				SlashingPenalty:                    income.SlashingPenalty,
+				TxFeeRewardWei:                     txFeeRewardWei,
+				ProposalsMissed:                    income.ProposalsMissed}
+
+			responseData = append(responseData, &types.ApiValidatorIncomeHistoryResponse{
+				Income:         responseIncome,
+				Epoch:          epoch,
+				ValidatorIndex: validatorIndex,
+				Week:           epoch / epochsPerWeek,
+				WeekStart:      utils.EpochToTime(epochAtStartOfTheWeek),
+				WeekEnd:        utils.EpochToTime(epochAtStartOfTheWeek + epochsPerWeek),
+			})
+		}
 	}
 
-	rows, err := db.ReaderDb.Query(`
-	SELECT 
-		validators.validatorindex, 
-		COALESCE(validator_performance.cl_performance_1d, 0) AS performance1d, 
-		COALESCE(validator_performance.cl_performance_7d, 0) AS performance7d, 
-		COALESCE(validator_performance.cl_performance_31d, 0) AS performance31d, 
-		COALESCE(validator_performance.cl_performance_365d, 0) AS performance365d, 
-		COALESCE(validator_performance.cl_performance_total, 0) AS performanceTotal, 
+	sort.Slice(responseData, func(i, j int) bool {
+		if responseData[i].Epoch != responseData[j].Epoch {
+			return responseData[i].Epoch > responseData[j].Epoch
+		}
+		return responseData[i].ValidatorIndex < responseData[j].ValidatorIndex
+	})
+
+	return responseData
+}
+
+// apiValidatorBalanceHistory converts the balance history of validators to api responses, ordered by epoch descending and validator index ascending
+func apiValidatorBalanceHistory(history map[uint64][]*types.ValidatorBalance) []*types.ApiValidatorBalanceHistoryResponse {
+	responseData := make([]*types.ApiValidatorBalanceHistoryResponse, 0, len(history)*100)
+
+	epochsPerWeek := utils.EpochsPerDay() * 7
+	for validatorIndex, balances := range history {
+		for _, balance := range balances {
+			epochAtStartOfTheWeek := (balance.Epoch / epochsPerWeek) * epochsPerWeek
+			responseData = append(responseData, &types.ApiValidatorBalanceHistoryResponse{
+				Balance:          balance.Balance,
+				EffectiveBalance: balance.EffectiveBalance,
+				Epoch:            balance.Epoch,
+				Validatorindex:   validatorIndex,
+				Week:             balance.Epoch / epochsPerWeek,
+				WeekStart:        utils.EpochToTime(epochAtStartOfTheWeek),
+				WeekEnd:          utils.EpochToTime(epochAtStartOfTheWeek + epochsPerWeek),
+			})
+		}
+	}
+
+	sort.Slice(responseData, func(i, j int) bool {
+		if responseData[i].Epoch != responseData[j].Epoch {
+			return responseData[i].Epoch > responseData[j].Epoch
+		}
+		return responseData[i].Validatorindex < responseData[j].Validatorindex
+	})
+
+	return responseData
+}
+
+// apiValidatorAttestations converts the attestation history of validators to api responses, ordered by epoch descending and validator index ascending
+func apiValidatorAttestations(history map[uint64][]*types.ValidatorAttestation) []*types.ApiValidatorAttestationsResponse {
+	responseData := make([]*types.ApiValidatorAttestationsResponse, 0, len(history)*100)
+
+	epochsPerWeek := utils.EpochsPerDay() * 7
+	for validatorIndex, balances := range history {
+		for _, attestation := range balances {
+			epochAtStartOfTheWeek := (attestation.Epoch / epochsPerWeek) * epochsPerWeek
+			responseData = append(responseData, &types.ApiValidatorAttestationsResponse{
+				AttesterSlot:   attestation.AttesterSlot,
+				CommitteeIndex: 0,
+				Epoch:          attestation.Epoch,
+				InclusionSlot:  attestation.InclusionSlot,
+				Status:         attestation.Status,
+				ValidatorIndex: validatorIndex,
+				Week:           attestation.Epoch / epochsPerWeek,
+				WeekStart:      utils.EpochToTime(epochAtStartOfTheWeek),
+				WeekEnd:        utils.EpochToTime(epochAtStartOfTheWeek + epochsPerWeek),
+			})
+		}
+	}
+
+	sort.Slice(responseData, func(i, j int) bool {
+		if responseData[i].Epoch != responseData[j].Epoch {
+			return responseData[i].Epoch > responseData[j].Epoch
+		}
+		return responseData[i].ValidatorIndex < responseData[j].ValidatorIndex
+	})
+
+	return responseData
+}
+
+// ApiValidatorPerformance godoc
+// @Summary Get the current consensus reward performance of up to 100 validators
+// @Tags Validator
+// @Produce  json
+// @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Success 200 {object} types.ApiResponse{data=[]types.ApiValidatorCurrentPerformanceResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/validator/{indexOrPubkey}/performance [get]
+func ApiValidatorPerformance(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	maxValidators := getUserPremium(r).MaxValidators
+
+	queryIndices, err := parseApiValidatorParamToIndices(vars["indexOrPubkey"], maxValidators)
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), err.Error())
+		return
+	}
+
+	data := []*types.ApiValidatorCurrentPerformanceResponse{}
+	err = db.ReaderDb.Select(&data, `
+	SELECT 
+		validators.validatorindex, 
+		COALESCE(validator_performance.cl_performance_1d, 0) AS performance1d, 
+		COALESCE(validator_performance.cl_performance_7d, 0) AS performance7d, 
+		COALESCE(validator_performance.cl_performance_31d, 0) AS performance31d, 
+		COALESCE(validator_performance.cl_performance_365d, 0) AS performance365d, 
+		COALESCE(validator_performance.cl_performance_total, 0) AS performanceTotal, 
 		COALESCE(validator_performance.rank7d, 0) AS rank7d
 	FROM validators 
 	LEFT JOIN validator_performance ON 
@@ -2299,13 +2483,6 @@ func ApiValidatorPerformance(w http.ResponseWriter, r *http.Request) {
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
-
-	data, err := utils.SqlRowsToJSON(rows)
-	if err != nil {
-		SendBadRequestResponse(w, r.URL.String(), "could not parse db results")
-		return
-	}
 
 	currentDayIncome, err := db.GetCurrentDayClIncome(queryIndices)
 	if err != nil {
@@ -2323,53 +2500,29 @@ func ApiValidatorPerformance(w http.ResponseWriter, r *http.Request) {
 	// create a map to easily check if a validator is part of data
 	validatorIndexMap := make(map[uint64]bool)
 	for _, entry := range data {
-		eMap, ok := entry.(map[string]interface{})
-		if !ok {
-			logger.Errorf("error converting validator data to map[string]interface{}")
-			continue
-		}
-
-		validatorIndex, ok := eMap["validatorindex"].(int64)
-		if !ok {
-			logger.Errorf("error converting validatorindex to int64")
-			continue
-		}
-
-		validatorIndexMap[uint64(validatorIndex)] = true
+		validatorIndexMap[entry.Validatorindex] = true
 	}
 
 	// check for recently activated validators that have no performance data yet but already generate income
 	for incomeValidatorIndex := range currentDayIncome {
 		_, ok := validatorIndexMap[incomeValidatorIndex]
 		if !ok {
-			// validator not found in data, add minimum set of data
-			data = append(data, map[string]interface{}{
-				"validatorindex":   int64(incomeValidatorIndex),
-				"performancetotal": int64(0), // has to exist and will be updated below
-			})
+			// validator not found in data, the performance will be set below
+			entry := &types.ApiValidatorCurrentPerformanceResponse{}
+			entry.Validatorindex = incomeValidatorIndex
+			data = append(data, entry)
 		}
 	}
 
 	for _, entry := range data {
-		eMap, ok := entry.(map[string]interface{})
-		if !ok {
-			logger.Errorf("error converting validator data to map[string]interface{}")
-			continue
+		if balances := latestBalances[entry.Validatorindex]; len(balances) > 0 {
+			entry.Balance = balances[0].Balance
 		}
-
-		validatorIndex, ok := eMap["validatorindex"].(int64)
-		if !ok {
-			logger.Errorf("error converting validatorindex to int64")
-			continue
-		}
-
-		eMap["balance"] = latestBalances[uint64(validatorIndex)][0].Balance
-		eMap["performancetoday"] = currentDayIncome[uint64(validatorIndex)]
-		eMap["performancetotal"] = eMap["performancetotal"].(int64) + currentDayIncome[uint64(validatorIndex)]
+		entry.PerformanceToday = currentDayIncome[entry.Validatorindex]
+		entry.PerformanceTotal += currentDayIncome[entry.Validatorindex]
 	}
 
-	j := json.NewEncoder(w)
-	SendOKResponse(j, r.URL.String(), []any{data})
+	sendOKArrayResponse(w, r, data)
 }
 
 // ApiValidatorExecutionPerformance godoc
@@ -2377,7 +2530,7 @@ func ApiValidatorPerformance(w http.ResponseWriter, r *http.Request) {
 // @Tags Validator
 // @Produce  json
 // @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
-// @Success 200 {object} types.ApiResponse{data=[]types.ApiValidatorExecutionPerformanceResponse}
+// @Success 200 {object} types.ApiResponse{data=[]types.ExecutionPerformanceResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/validator/{indexOrPubkey}/execution/performance [get]
 func ApiValidatorExecutionPerformance(w http.ResponseWriter, r *http.Request) {
@@ -2408,7 +2561,7 @@ func ApiValidatorExecutionPerformance(w http.ResponseWriter, r *http.Request) {
 // @Tags Validator
 // @Produce  json
 // @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
-// @Success 200 {object} types.ApiResponse
+// @Success 200 {object} types.ApiResponse{data=[]types.ValidatorEffectiveness}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/validator/{indexOrPubkey}/attestationeffectiveness [get]
 func ApiValidatorAttestationEffectiveness(w http.ResponseWriter, r *http.Request) {
@@ -2450,7 +2603,7 @@ func ApiValidatorAttestationEffectiveness(w http.ResponseWriter, r *http.Request
 // @Tags Validator
 // @Produce  json
 // @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
-// @Success 200 {object} types.ApiResponse
+// @Success 200 {object} types.ApiResponse{data=[]types.ValidatorEffectiveness}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/validator/{indexOrPubkey}/attestationefficiency [get]
 func ApiValidatorAttestationEfficiency(w http.ResponseWriter, r *http.Request) {
@@ -2516,7 +2669,8 @@ func ApiValidatorLeaderboard(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 
-	rows, err := db.ReaderDb.Query(`
+	data := []*types.ApiValidatorPerformanceResponse{}
+	err := db.ReaderDb.Select(&data, `
 			SELECT 
 				balance, 
 				COALESCE(validator_performance.cl_performance_1d, 0) AS performance1d, 
@@ -2532,9 +2686,8 @@ func ApiValidatorLeaderboard(w http.ResponseWriter, r *http.Request) {
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	returnQueryResultsAsArray(rows, w, r)
+	sendOKArrayResponse(w, r, data)
 }
 
 // ApiValidatorDeposits godoc
@@ -2557,7 +2710,8 @@ func ApiValidatorDeposits(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.ReaderDb.Query(
+	data := []*types.ApiValidatorDepositsResponse{}
+	err = db.ReaderDb.Select(&data,
 		`SELECT amount, block_number, block_ts, from_address, merkletree_index, publickey, removed, signature, tx_hash, tx_index, tx_input, valid_signature, withdrawal_credentials FROM eth1_deposits 
 		WHERE publickey = ANY($1)`, pubkeys,
 	)
@@ -2566,9 +2720,8 @@ func ApiValidatorDeposits(w http.ResponseWriter, r *http.Request) {
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	returnQueryResultsAsArray(rows, w, r)
+	sendOKArrayResponse(w, r, data)
 }
 
 // ApiValidatorAttestations godoc
@@ -2576,7 +2729,7 @@ func ApiValidatorDeposits(w http.ResponseWriter, r *http.Request) {
 // @Tags Validator
 // @Produce  json
 // @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
-// @Success 200 {object} types.ApiResponse{[]types.ApiValidatorAttestationsResponse}
+// @Success 200 {object} types.ApiResponse{data=[]types.ApiValidatorAttestationsResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/validator/{indexOrPubkey}/attestations [get]
 func ApiValidatorAttestations(w http.ResponseWriter, r *http.Request) {
@@ -2599,32 +2752,7 @@ func ApiValidatorAttestations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	responseData := make([]*types.ApiValidatorAttestationsResponse, 0, len(history)*100)
-
-	epochsPerWeek := utils.EpochsPerDay() * 7
-	for validatorIndex, balances := range history {
-		for _, attestation := range balances {
-			epochAtStartOfTheWeek := (attestation.Epoch / epochsPerWeek) * epochsPerWeek
-			responseData = append(responseData, &types.ApiValidatorAttestationsResponse{
-				AttesterSlot:   attestation.AttesterSlot,
-				CommitteeIndex: 0,
-				Epoch:          attestation.Epoch,
-				InclusionSlot:  attestation.InclusionSlot,
-				Status:         attestation.Status,
-				ValidatorIndex: validatorIndex,
-				Week:           attestation.Epoch / epochsPerWeek,
-				WeekStart:      utils.EpochToTime(epochAtStartOfTheWeek),
-				WeekEnd:        utils.EpochToTime(epochAtStartOfTheWeek + epochsPerWeek),
-			})
-		}
-	}
-
-	sort.Slice(responseData, func(i, j int) bool {
-		if responseData[i].Epoch != responseData[j].Epoch {
-			return responseData[i].Epoch > responseData[j].Epoch
-		}
-		return responseData[i].ValidatorIndex < responseData[j].ValidatorIndex
-	})
+	responseData := apiValidatorAttestations(history)
 
 	response := &types.ApiResponse{}
 	response.Status = "OK"
@@ -2644,8 +2772,8 @@ func ApiValidatorAttestations(w http.ResponseWriter, r *http.Request) {
 // @Tags Validator
 // @Produce  json
 // @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
-// @Param  epoch query string false "Page the result by epoch"
-// @Success 200 {object} types.ApiResponse{data=[]types.ApiValidatorProposalsResponse}
+// @Param  epoch query int false "Page the result by epoch"
+// @Success 200 {object} types.ApiResponse{data=[]types.APIBlockResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/validator/{indexOrPubkey}/proposals [get]
 func ApiValidatorProposals(w http.ResponseWriter, r *http.Request) {
@@ -2677,8 +2805,24 @@ func ApiValidatorProposals(w http.ResponseWriter, r *http.Request) {
 		epochQuery = 100
 	}
 
-	rows, err := db.ReaderDb.Query(`
-	SELECT 
+	data := []*types.APIBlockResponse{}
+	err = db.ReaderDb.Select(&data, `
+	SELECT `+apiValidatorProposalsColumns+`
+	FROM blocks as b 
+	LEFT JOIN validators ON validators.validatorindex = b.proposer 
+	WHERE (proposer = ANY($1)) and epoch <= $2 AND epoch >= $3 
+	ORDER BY proposer, epoch desc, slot desc`, pq.Array(queryIndices), epochQuery, epochQuery-100)
+	if err != nil {
+		logger.Errorf("could not retrieve db results: %v", err)
+		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	sendOKArrayResponse(w, r, data)
+}
+
+// apiValidatorProposalsColumns are the columns of the blocks table b that are returned by the validator proposals endpoints
+const apiValidatorProposalsColumns = `
 		b.epoch,
 		b.slot,
 		b.blockroot,
@@ -2704,7 +2848,7 @@ func ApiValidatorProposals(w http.ResponseWriter, r *http.Request) {
 		b.exec_receipts_root,
 		b.exec_state_root,
 		b.exec_timestamp,
-		b.exec_transactions_count,
+		COALESCE(b.exec_transactions_count, 0) AS exec_transactions_count,
 		b.graffiti,
 		b.graffiti_text,
 		b.proposer,
@@ -2714,19 +2858,8 @@ func ApiValidatorProposals(w http.ResponseWriter, r *http.Request) {
 		b.syncaggregate_bits,
 		b.syncaggregate_participation,
 		b.syncaggregate_signature,
-		b.voluntaryexitscount
-	FROM blocks as b 
-	LEFT JOIN validators ON validators.validatorindex = b.proposer 
-	WHERE (proposer = ANY($1)) and epoch <= $2 AND epoch >= $3 
-	ORDER BY proposer, epoch desc, slot desc`, pq.Array(queryIndices), epochQuery, epochQuery-100)
-	if err != nil {
-		logger.Errorf("could not retrieve db results: %v", err)
-		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
-		return
-	}
-
-	returnQueryResultsAsArray(rows, w, r)
-}
+		b.voluntaryexitscount,
+		COALESCE(b.withdrawalcount, 0) AS withdrawalcount`
 
 // ApiGraffitiwall godoc
 // @Summary Get the most recent pixels that have been painted.
@@ -2741,10 +2874,10 @@ func ApiValidatorProposals(w http.ResponseWriter, r *http.Request) {
 // @Param starty query int false "Start Y offset" default(0)
 // @Param endx query int false "End X limit" default(999)
 // @Param endy query int false "End Y limit" default(999)
-// @Param startSlot query string false "Start slot to query (end slot - 10000 if empty)"
-// @Param slot query string false "End slot to query"
+// @Param startSlot query int false "Start slot to query (end slot - 10000 if empty)"
+// @Param slot query int false "End slot to query"
 // @Param summarize query bool false "Only return end state of each pixel" default(true)
-// @Success 200 {object} types.ApiResponse
+// @Success 200 {object} types.ApiResponse{data=[]types.ApiGraffitiwallResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/graffitiwall [get]
 func ApiGraffitiwall(w http.ResponseWriter, r *http.Request) {
@@ -2815,7 +2948,8 @@ func ApiGraffitiwall(w http.ResponseWriter, r *http.Request) {
 		summarize_query = "DISTINCT ON (x, y) "
 	}
 
-	rows, err := db.ReaderDb.Query(`
+	data := []*types.ApiGraffitiwallResponse{}
+	err := db.ReaderDb.Select(&data, `
 	SELECT `+summarize_query+`
 		x,
 		y,
@@ -2832,17 +2966,16 @@ func ApiGraffitiwall(w http.ResponseWriter, r *http.Request) {
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	returnQueryResultsAsArray(rows, w, r)
+	sendOKArrayResponse(w, r, data)
 }
 
 // ApiChart godoc
 // @Summary Returns charts from the page https://beaconcha.in/charts as PNG
 // @Tags Misc
-// @Produce  json
+// @Produce  png
 // @Param  chart path string true "Chart name (see https://github.com/gobitfly/eth2-beaconchain-explorer/blob/master/services/charts_updater.go#L20 for all available names)"
-// @Success 200 {object} types.ApiResponse
+// @Success 200 {file} png
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/chart/{chart} [get]
 func ApiChart(w http.ResponseWriter, r *http.Request) {
@@ -3052,12 +3185,12 @@ func getDeviceNameFromUA(userAgent string) string {
 // @Summary Register or update your mobile notification token
 // @Tags User
 // @Produce  json
-// @Param token body string true "Your device`s firebase notification token"
+// @Param token formData string true "Your device`s firebase notification token"
 // @Success 200 {object} types.ApiResponse
 // @Failure 400 {object} types.ApiResponse
 // @Failure 500 {object} types.ApiResponse
 // @Security ApiKeyAuth
-// @Router /api/v1/mobile/notify/register [post]
+// @Router /api/v1/user/mobile/notify/register [post]
 func MobileNotificationUpdatePOST(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
@@ -3075,6 +3208,18 @@ func MobileNotificationUpdatePOST(w http.ResponseWriter, r *http.Request) {
 	OKResponse(w, r)
 }
 
+// RegisterEthpoolSubscription godoc
+// @Summary Register an ethpool subscription
+// @Tags User
+// @Produce json
+// @Param package formData string true "Name of the package"
+// @Param user_id formData string true "Ethpool user id"
+// @Param signature formData string true "Signature of the package and the user id"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/ethpool [post]
 func RegisterEthpoolSubscription(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -3133,6 +3278,16 @@ func hmacSign(data string) string {
 	return sha
 }
 
+// RegisterMobileSubscriptions godoc
+// @Summary Register a subscription purchased in the mobile app
+// @Tags User
+// @Produce json
+// @Param request body types.MobileSubscription true "The purchased subscription"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/subscription/register [post]
 func RegisterMobileSubscriptions(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -3269,6 +3424,14 @@ func GetUserPremiumByPackage(pkg string) PremiumUser {
 	return result
 }
 
+// GetMobileWidgetStatsPost godoc
+// @Summary Get the mobile widget data of up to 100 validators
+// @Tags Mobile
+// @Produce json
+// @Param request body types.DashboardRequest true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Success 200 {object} types.ApiResponse{data=types.WidgetResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/dashboard/widget [post]
 func GetMobileWidgetStatsPost(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	decoder := json.NewDecoder(r.Body)
@@ -3283,6 +3446,14 @@ func GetMobileWidgetStatsPost(w http.ResponseWriter, r *http.Request) {
 	GetMobileWidgetStats(w, r, parsedBody.IndicesOrPubKey)
 }
 
+// GetMobileWidgetStatsGet godoc
+// @Summary Get the mobile widget data of up to 100 validators
+// @Tags Mobile
+// @Produce json
+// @Param indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Success 200 {object} types.ApiResponse{data=types.WidgetResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/validator/{indexOrPubkey}/widget [get]
 func GetMobileWidgetStatsGet(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	vars := mux.Vars(r)
@@ -3303,12 +3474,11 @@ func GetMobileWidgetStats(w http.ResponseWriter, r *http.Request, indexOrPubkey
 	}
 
 	g, _ := errgroup.WithContext(context.Background())
-	var rocketpoolStats []any
-	var efficiencyRows *sql.Rows
-	var validatorRows *sql.Rows
+	var rocketpoolStats []types.APIRocketpoolStatsResponse
+	validatorData := []types.ApiWidgetValidatorResponse{}
 
 	g.Go(func() error {
-		validatorRows, err = db.ReaderDb.Query(
+		return db.ReaderDb.Select(&validatorData,
 			`SELECT 
 					validators.pubkey, 
 					slashed, 
@@ -3332,32 +3502,20 @@ func GetMobileWidgetStats(w http.ResponseWriter, r *http.Request, indexOrPubkey
 				WHERE validator_performance.validatorindex = ANY($1) ORDER BY validator_performance.validatorindex`,
 			pq.Array(queryIndices),
 		)
-		return err
 	})
 
 	g.Go(func() error {
+		var err error
 		rocketpoolStats, err = getRocketpoolStats()
 		return err
 	})
 
 	err = g.Wait()
-	if validatorRows != nil {
-		defer validatorRows.Close()
-	}
-	if efficiencyRows != nil {
-		defer efficiencyRows.Close()
-	}
 	if err != nil {
 		SendBadRequestResponse(w, r.URL.String(), err.Error())
 		return
 	}
 
-	generalData, err := utils.SqlRowsToJSON(validatorRows)
-	if err != nil {
-		SendBadRequestResponse(w, r.URL.String(), "could not parse db results")
-		return
-	}
-
 	balances, err := db.BigtableClient.GetValidatorBalanceHistory(queryIndices, uint64(epoch), uint64(epoch))
 	if err != nil {
 		SendBadRequestResponse(w, r.URL.String(), "error retrieving validator balance data")
@@ -3376,33 +3534,17 @@ func GetMobileWidgetStats(w http.ResponseWriter, r *http.Request, indexOrPubkey
 		return
 	}
 
-	for _, entry := range generalData {
-		eMap, ok := entry.(map[string]interface{})
-		if !ok {
-			logger.Errorf("error converting validator data to map[string]interface{}")
-			continue
-		}
-
-		validatorIndex, ok := eMap["validatorindex"].(int64)
+	for i := range validatorData {
+		entry := &validatorData[i]
+		entry.LastAttestationSlot = lastAttestationSlots[entry.ValidatorIndex]
 
-		if !ok {
-			logger.Errorf("error converting validatorindex to int64")
+		balance := balances[entry.ValidatorIndex]
+		if len(balance) == 0 {
 			continue
 		}
-
-		eMap["lastattestationslot"] = lastAttestationSlots[uint64(validatorIndex)]
-
-		for balanceIndex, balance := range balances {
-			if len(balance) == 0 {
-				continue
-			}
-
-			if int64(balanceIndex) == validatorIndex {
-				eMap["effectivebalance"] = balance[0].EffectiveBalance
-				eMap["performance1d"] = currentDayIncome[uint64(validatorIndex)]
-				eMap["performancetotal"] = eMap["performancetotal"].(int64) + currentDayIncome[uint64(validatorIndex)]
-			}
-		}
+		entry.EffectiveBalance = &balance[0].EffectiveBalance
+		entry.Performance1d = currentDayIncome[entry.ValidatorIndex]
+		entry.PerformanceTotal += currentDayIncome[entry.ValidatorIndex]
 	}
 
 	efficiencyData, err := getValidatorEffectiveness(services.LatestEpoch()-1, queryIndices)
@@ -3413,7 +3555,7 @@ func GetMobileWidgetStats(w http.ResponseWriter, r *http.Request, indexOrPubkey
 
 	data := &types.WidgetResponse{
 		Eff:             efficiencyData,
-		Validator:       generalData,
+		Validator:       validatorData,
 		Epoch:           epoch,
 		RocketpoolStats: rocketpoolStats,
 	}
@@ -3427,6 +3569,7 @@ func GetMobileWidgetStats(w http.ResponseWriter, r *http.Request, indexOrPubkey
 // @Tags User
 // @Produce json
 // @Success 200 {object} types.ApiResponse{data=types.MobileSettingsData}
+// @x-rows true
 // @Failure 400 {object} types.ApiResponse
 // @Failure 500 {object} types.ApiResponse
 // @Security ApiKeyAuth
@@ -3437,23 +3580,22 @@ func MobileDeviceSettings(w http.ResponseWriter, r *http.Request) {
 
 	claims := getAuthClaims(r)
 
-	rows, err := db.MobileDeviceSettingsSelect(claims.UserID, claims.DeviceID)
+	settings, err := db.MobileDeviceSettingsSelect(claims.UserID, claims.DeviceID)
 	if err != nil {
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
 
-	defer rows.Close()
-
-	returnQueryResults(rows, w, r)
+	sendOKRowsResponse(w, r, settings)
 }
 
 // MobileDeviceSettingsPOST godoc
 // @Summary Changing your devices mobile settings
 // @Tags User
 // @Produce json
-// @Param notify_enabled body bool true "Whether to enable mobile notifications for this device or not"
+// @Param notify_enabled formData bool true "Whether to enable mobile notifications for this device or not"
 // @Success 200 {object} types.ApiResponse{data=types.MobileSettingsData}
+// @x-rows true
 // @Failure 400 {object} types.ApiResponse
 // @Failure 500 {object} types.ApiResponse
 // @Security ApiKeyAuth
@@ -3489,23 +3631,22 @@ func MobileDeviceSettingsPOST(w http.ResponseWriter, r *http.Request) {
 		userID = claims.UserID
 	}
 
-	rows, err := db.MobileDeviceSettingsUpdate(userID, userDeviceID, notifyEnabled, active)
+	settings, err := db.MobileDeviceSettingsUpdate(userID, userDeviceID, notifyEnabled, active)
 	if err != nil {
 		logger.Errorf("could not retrieve db results err: %v", err)
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
 
-	defer rows.Close()
-
-	returnQueryResults(rows, w, r)
+	sendOKRowsResponse(w, r, settings)
 }
 
 // MobileTagedValidators godoc
 // @Summary Get all your tagged validators
 // @Tags User
 // @Produce json
-// @Success 200 {object} types.ApiResponse{data=[]types.MinimalTaggedValidators}
+// @Success 200 {object} types.ApiResponse{data=types.MinimalTaggedValidators}
+// @x-rows true
 // @Failure 400 {object} types.ApiResponse
 // @Failure 500 {object} types.ApiResponse
 // @Security ApiKeyAuth
@@ -3542,6 +3683,385 @@ func MobileTagedValidators(w http.ResponseWriter, r *http.Request) {
 	SendOKResponse(j, r.URL.String(), data)
 }
 
+// ApiUserWebhookDeliveries godoc
+// @Summary Get the most recent deliveries of one of your webhooks
+// @Tags User
+// @Produce json
+// @Param webhookID path int true "Webhook ID"
+// @Param limit query int false "Number of deliveries, default 100, max 1000" default(100)
+// @Success 200 {object} types.ApiResponse{data=[]types.UserWebhookDelivery}
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/webhooks/{webhookID}/deliveries [get]
+func ApiUserWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+	claims := getAuthClaims(r)
+
+	webhookID, err := strconv.ParseUint(mux.Vars(r)["webhookID"], 10, 64)
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), "invalid webhook id provided")
+		return
+	}
+	limit := parseUintWithDefault(r.URL.Query().Get("limit"), 100)
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	deliveries, err := db.GetUserWebhookDeliveries(claims.UserID, webhookID, limit)
+	if err != nil {
+		logger.WithError(err).Errorf("error retrieving webhook deliveries")
+		sendServerErrorResponse(w, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	// always respond with an array, SendOKResponse unwraps single results
+	err = j.Encode(&types.ApiResponse{
+		Status: "OK",
+		Data:   deliveries,
+	})
+	if err != nil {
+		logger.Errorf("error serializing json data for API %v route: %v", r.URL.String(), err)
+	}
+}
+
+// ApiUserWebhookDeliveryReplay godoc
+// @Summary Send a failed or delivered webhook delivery again, the payload and the event id are unchanged
+// @Tags User
+// @Produce json
+// @Param webhookID path int true "Webhook ID"
+// @Param deliveryID path int true "Delivery ID"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/webhooks/{webhookID}/deliveries/{deliveryID}/replay [post]
+func ApiUserWebhookDeliveryReplay(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+	claims := getAuthClaims(r)
+
+	vars := mux.Vars(r)
+	webhookID, err := strconv.ParseUint(vars["webhookID"], 10, 64)
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), "invalid webhook id provided")
+		return
+	}
+	deliveryID, err := strconv.ParseUint(vars["deliveryID"], 10, 64)
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), "invalid delivery id provided")
+		return
+	}
+
+	err = db.ReplayUserWebhookDelivery(claims.UserID, webhookID, deliveryID)
+	if err == sql.ErrNoRows {
+		SendBadRequestResponse(w, r.URL.String(), "the delivery does not exist or is still pending")
+		return
+	}
+	if err != nil {
+		logger.WithError(err).Errorf("error replaying webhook delivery")
+		sendServerErrorResponse(w, r.URL.String(), "could not replay the delivery")
+		return
+	}
+
+	SendOKResponse(j, r.URL.String(), nil)
+}
+
+// ApiUserNotificationRules godoc
+// @Summary Get your notification rules
+// @Tags User
+// @Produce json
+// @Success 200 {object} types.ApiResponse{data=[]types.NotificationRule}
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/notifications/rules [get]
+func ApiUserNotificationRules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+	claims := getAuthClaims(r)
+
+	rules, err := db.GetUserNotificationRules(claims.UserID)
+	if err != nil {
+		logger.WithError(err).Errorf("error retrieving notification rules")
+		sendServerErrorResponse(w, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	// always respond with an array, SendOKResponse unwraps single results
+	err = j.Encode(&types.ApiResponse{
+		Status: "OK",
+		Data:   rules,
+	})
+	if err != nil {
+		logger.Errorf("error serializing json data for API %v route: %v", r.URL.String(), err)
+	}
+}
+
+// ApiUserNotificationRuleAdd godoc
+// @Summary Add a notification rule that notifies you when all or any of its conditions are fulfilled
+// @Description Conditions compare a metric to a value with one of the operators >, >=, < or <=.
+// @Description The metrics validators_offline and attestations_missed are evaluated on a validator group (a tag of your validators, default watchlist) over the given number of epochs (1-32),
+// @Description the metrics machine_cpu_load, machine_memory_usage and machine_disk_usage on one of your monitored machines. Percentages range from 0 to 100.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param rule body types.NotificationRule true "The rule, e.g. {\"name\":\"node down\",\"match\":\"all\",\"conditions\":[{\"metric\":\"validators_offline\",\"group\":\"watchlist\",\"epochs\":2,\"operator\":\">=\",\"value\":3}]}"
+// @Success 200 {object} types.ApiResponse{data=types.NotificationRule}
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/notifications/rules [post]
+func ApiUserNotificationRuleAdd(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+	claims := getAuthClaims(r)
+
+	rule := &types.NotificationRule{}
+	err := json.NewDecoder(r.Body).Decode(rule)
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), "could not read body")
+		return
+	}
+	err = rule.Validate()
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), err.Error())
+		return
+	}
+	rule.UserID = claims.UserID
+
+	rule.ID, err = db.AddUserNotificationRule(rule)
+	if err == db.ErrTooManyNotificationRules {
+		SendBadRequestResponse(w, r.URL.String(), fmt.Sprintf("you can have at most %v notification rules", types.NotificationRuleMaxPerUser))
+		return
+	}
+	if err != nil {
+		logger.WithError(err).Errorf("error adding notification rule")
+		sendServerErrorResponse(w, r.URL.String(), "could not add the rule")
+		return
+	}
+	rule.Created = time.Now()
+
+	SendOKResponse(j, r.URL.String(), []interface{}{rule})
+}
+
+// ApiUserNotificationRuleUpdate godoc
+// @Summary Replace the name, the match and the conditions of one of your notification rules
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param ruleID path int true "Rule ID"
+// @Param rule body types.NotificationRule true "The rule, see adding a rule"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/notifications/rules/{ruleID}/update [post]
+func ApiUserNotificationRuleUpdate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+	claims := getAuthClaims(r)
+
+	ruleID, err := strconv.ParseUint(mux.Vars(r)["ruleID"], 10, 64)
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), "invalid rule id provided")
+		return
+	}
+	rule := &types.NotificationRule{}
+	err = json.NewDecoder(r.Body).Decode(rule)
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), "could not read body")
+		return
+	}
+	err = rule.Validate()
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), err.Error())
+		return
+	}
+	rule.ID = ruleID
+	rule.UserID = claims.UserID
+
+	err = db.UpdateUserNotificationRule(rule)
+	if err == sql.ErrNoRows {
+		SendBadRequestResponse(w, r.URL.String(), "the rule does not exist")
+		return
+	}
+	if err != nil {
+		logger.WithError(err).Errorf("error updating notification rule")
+		sendServerErrorResponse(w, r.URL.String(), "could not update the rule")
+		return
+	}
+
+	SendOKResponse(j, r.URL.String(), nil)
+}
+
+// ApiUserNotificationRuleDelete godoc
+// @Summary Delete one of your notification rules
+// @Tags User
+// @Produce json
+// @Param ruleID path int true "Rule ID"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/notifications/rules/{ruleID}/delete [post]
+func ApiUserNotificationRuleDelete(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+	claims := getAuthClaims(r)
+
+	ruleID, err := strconv.ParseUint(mux.Vars(r)["ruleID"], 10, 64)
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), "invalid rule id provided")
+		return
+	}
+
+	err = db.DeleteUserNotificationRule(claims.UserID, ruleID)
+	if err == sql.ErrNoRows {
+		SendBadRequestResponse(w, r.URL.String(), "the rule does not exist")
+		return
+	}
+	if err != nil {
+		logger.WithError(err).Errorf("error deleting notification rule")
+		sendServerErrorResponse(w, r.URL.String(), "could not delete the rule")
+		return
+	}
+
+	SendOKResponse(j, r.URL.String(), nil)
+}
+
+// ApiUserNotificationHistory godoc
+// @Summary Get the notifications that have been sent to you, the most recent first
+// @Description Use the read and acknowledged state to sync which notifications have been seen and handled between your devices and tools.
+// @Description Page through older notifications by passing the id of the last notification of a page as before_id.
+// @Description Fetch the notifications you have not seen yet by passing the id of the most recent notification you have as since_id, these are returned oldest first so the id of the last notification of a page is the since_id of the next page.
+// @Tags User
+// @Produce json
+// @Param limit query int false "Number of notifications, default 100, max 1000" default(100)
+// @Param before_id query int false "Only return notifications with a lower id"
+// @Param since_id query int false "Only return notifications with a higher id, oldest first"
+// @Param unread query bool false "Only return notifications that have not been read"
+// @Param unacknowledged query bool false "Only return notifications that have not been acknowledged"
+// @Success 200 {object} types.ApiResponse{data=[]types.UserNotificationHistoryEntry}
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/notifications/history [get]
+func ApiUserNotificationHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+	claims := getAuthClaims(r)
+	q := r.URL.Query()
+
+	limit := parseUintWithDefault(q.Get("limit"), 100)
+	if limit > 1000 {
+		limit = 1000
+	}
+	beforeID := parseUintWithDefault(q.Get("before_id"), 0)
+	sinceID := parseUintWithDefault(q.Get("since_id"), 0)
+	unreadOnly := q.Get("unread") == "true"
+	unacknowledgedOnly := q.Get("unacknowledged") == "true"
+
+	entries, err := db.GetUserNotificationHistory(claims.UserID, unreadOnly, unacknowledgedOnly, beforeID, sinceID, limit)
+	if err != nil {
+		logger.WithError(err).Errorf("error retrieving notification history")
+		sendServerErrorResponse(w, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	// always respond with an array, SendOKResponse unwraps single results
+	err = j.Encode(&types.ApiResponse{
+		Status: "OK",
+		Data:   entries,
+	})
+	if err != nil {
+		logger.Errorf("error serializing json data for API %v route: %v", r.URL.String(), err)
+	}
+}
+
+// ApiUserNotificationHistoryUnread godoc
+// @Summary Get the number of notifications that you have not read
+// @Tags User
+// @Produce json
+// @Success 200 {object} types.ApiResponse{data=types.NotificationHistoryUnreadResponse}
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/notifications/history/unread [get]
+func ApiUserNotificationHistoryUnread(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+	claims := getAuthClaims(r)
+
+	count, err := db.GetUserNotificationHistoryUnreadCount(claims.UserID)
+	if err != nil {
+		logger.WithError(err).Errorf("error counting unread notifications")
+		sendServerErrorResponse(w, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	SendOKResponse(j, r.URL.String(), []interface{}{types.NotificationHistoryUnreadResponse{Unread: count}})
+}
+
+// ApiUserNotificationHistoryRead godoc
+// @Summary Mark notifications of your notification history as read
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body types.NotificationHistoryUpdateRequest true "The ids of the notifications, or all to mark all notifications"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/notifications/history/read [post]
+func ApiUserNotificationHistoryRead(w http.ResponseWriter, r *http.Request) {
+	updateUserNotificationHistory(w, r, db.MarkUserNotificationHistoryRead)
+}
+
+// ApiUserNotificationHistoryAcknowledge godoc
+// @Summary Acknowledge notifications of your notification history, acknowledged notifications are read as well
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body types.NotificationHistoryUpdateRequest true "The ids of the notifications, or all to acknowledge all notifications"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/notifications/history/acknowledge [post]
+func ApiUserNotificationHistoryAcknowledge(w http.ResponseWriter, r *http.Request) {
+	updateUserNotificationHistory(w, r, db.AcknowledgeUserNotificationHistory)
+}
+
+func updateUserNotificationHistory(w http.ResponseWriter, r *http.Request, update func(userID uint64, ids []uint64, all bool) (int64, error)) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+	claims := getAuthClaims(r)
+
+	req := &types.NotificationHistoryUpdateRequest{}
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), "could not read body")
+		return
+	}
+	if !req.All && len(req.IDs) == 0 {
+		SendBadRequestResponse(w, r.URL.String(), "no notifications selected, provide ids or all")
+		return
+	}
+	if len(req.IDs) > 1000 {
+		SendBadRequestResponse(w, r.URL.String(), "at most 1000 ids can be provided")
+		return
+	}
+
+	_, err = update(claims.UserID, req.IDs, req.All)
+	if err != nil {
+		logger.WithError(err).Errorf("error updating notification history")
+		sendServerErrorResponse(w, r.URL.String(), "could not update the notifications")
+		return
+	}
+
+	SendOKResponse(j, r.URL.String(), nil)
+}
+
 func parseUintWithDefault(input string, defaultValue uint64) uint64 {
 	result, error := strconv.ParseUint(input, 10, 64)
 	if error != nil {
@@ -3556,10 +4076,11 @@ func parseUintWithDefault(input string, defaultValue uint64) uint64 {
 // @Produce json
 // @Param offset path int false "Data offset, default 0" default(0)
 // @Param limit path int false "Data limit, default 180 (~3h)." default(180)
-// @Success 200 {object} types.ApiResponse{data=[]types.StatsDataStruct}
+// @Success 200 {object} types.ApiResponse{data=types.StatsDataStruct}
 // @Failure 400 {object} types.ApiResponse
 // @Failure 500 {object} types.ApiResponse
 // @Security ApiKeyAuth
+// @Router /api/v1/user/stats [get]
 // @Router /api/v1/user/stats/{offset}/{limit} [get]
 func ClientStats(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -3629,6 +4150,16 @@ func ClientStatsPostNew(w http.ResponseWriter, r *http.Request) {
 	clientStatsPost(w, r, apiKey, machine)
 }
 
+// ClientStatsPostOld godoc
+// @Summary Deprecated, use /api/v1/client/metrics
+// @Tags User
+// @Produce json
+// @Param apiKey path string true "User API key"
+// @Param machine path string false "Name of the machine"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/stats/{apiKey} [post]
+// @Router /api/v1/stats/{apiKey}/{machine} [post]
 func ClientStatsPostOld(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 
@@ -3879,7 +4410,7 @@ func ApiWithdrawalCredentialsValidators(w http.ResponseWriter, r *http.Request)
 // @Description Returns the proposal luck of a validator or a list of validators
 // @Produce json
 // @Param validators query string true "Provide a comma separated list of validator indices or pubkeys"
-// @Success 200 {object} types.ApiResponse{data=[]types.ApiProposalLuckResponse}
+// @Success 200 {object} types.ApiResponse{data=types.ApiProposalLuckResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Failure 500 {object} types.ApiResponse
 // @Router /api/v1/validators/proposalLuck [get]
@@ -3999,6 +4530,13 @@ func DecodeMapStructure(input interface{}, output interface{}) error {
 
 // TODO Replace app code to work with new income balance dashboard
 // Meanwhile keep old code from Feb 2021 to be app compatible
+// @Summary Get the balance chart of a set of validators as [timestamp, validator count, balance, effective balance]
+// @Tags Dashboard
+// @Produce json
+// @Param validators query string true "Comma separated list of validator indices or pubkeys"
+// @Success 200 {array} []number
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/dashboard/data/balance [get]
 func APIDashboardDataBalance(w http.ResponseWriter, r *http.Request) {
 	currency := GetCurrency(r)
 
@@ -4077,81 +4615,60 @@ func APIDashboardDataBalance(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func getAuthClaims(r *http.Request) *utils.CustomClaims {
-	middleWare := gorillacontext.Get(r, utils.MobileAuthorizedKey)
-	if middleWare == nil {
-		return utils.GetAuthorizationClaims(r)
+// getApiUserByKeyOrToken returns the id and premium package of the user of a request, either by its access token or its api key
+func getApiUserByKeyOrToken(r *http.Request) (uint64, string, error) {
+	claims := utils.GetAuthorizationClaims(r)
+	if claims != nil {
+		return claims.UserID, claims.Package, nil
 	}
 
-	claims := gorillacontext.Get(r, utils.ClaimsContextKey)
-	if claims == nil {
-		return nil
+	apiKey := r.URL.Query().Get("apikey")
+	if apiKey == "" {
+		apiKey = r.Header.Get("apikey")
 	}
-	return claims.(*utils.CustomClaims)
-}
-
-// Saves the result of a query converted to JSON in the response writer.
-// An arbitrary amount of functions adjustQueryEntriesFuncs can be added to adjust the JSON response.
-func returnQueryResults(rows *sql.Rows, w http.ResponseWriter, r *http.Request, adjustQueryEntriesFuncs ...func(map[string]interface{}) error) {
-	j := json.NewEncoder(w)
-	data, err := utils.SqlRowsToJSON(rows)
-	if err != nil {
-		SendBadRequestResponse(w, r.URL.String(), "could not parse db results")
-		return
+	if apiKey == "" {
+		apiKey = r.Header.Get("X-API-KEY")
 	}
-
-	err = adjustQueryResults(data, adjustQueryEntriesFuncs...)
-	if err != nil {
-		SendBadRequestResponse(w, r.URL.String(), "could not adjust query results")
-		return
+	if apiKey == "" {
+		return 0, "", fmt.Errorf("an api key is required")
 	}
 
-	SendOKResponse(j, r.URL.String(), data)
-}
-
-// Saves the result of a query converted to JSON in the response writer as an array.
-// An arbitrary amount of functions adjustQueryEntriesFuncs can be added to adjust the JSON response.
-func returnQueryResultsAsArray(rows *sql.Rows, w http.ResponseWriter, r *http.Request, adjustQueryEntriesFuncs ...func(map[string]interface{}) error) {
-	data, err := utils.SqlRowsToJSON(rows)
-
+	user, err := db.GetUserIdByApiKey(apiKey)
 	if err != nil {
-		SendBadRequestResponse(w, r.URL.String(), "could not parse db results")
-		return
+		return 0, "", fmt.Errorf("no user found with api key")
 	}
+	return user.ID, user.Product.String, nil
+}
 
-	err = adjustQueryResults(data, adjustQueryEntriesFuncs...)
-	if err != nil {
-		SendBadRequestResponse(w, r.URL.String(), "could not adjust query results")
-		return
+func getAuthClaims(r *http.Request) *utils.CustomClaims {
+	middleWare := gorillacontext.Get(r, utils.MobileAuthorizedKey)
+	if middleWare == nil {
+		return utils.GetAuthorizationClaims(r)
 	}
 
-	response := &types.ApiResponse{
-		Status: "OK",
-		Data:   data,
+	claims := gorillacontext.Get(r, utils.ClaimsContextKey)
+	if claims == nil {
+		return nil
 	}
+	return claims.(*utils.CustomClaims)
+}
 
-	err = json.NewEncoder(w).Encode(response)
-
-	if err != nil {
-		sendServerErrorResponse(w, r.URL.String(), "could not serialize data results")
-		logger.Errorf("error serializing json data for API %v route: %v", r.URL.String(), err)
+// sendOKRowsResponse writes the rows of a query as data of an OK response.
+// A single row is written as object, any other amount of rows as array.
+func sendOKRowsResponse[T any](w http.ResponseWriter, r *http.Request, rows []T) {
+	data := make([]interface{}, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, row)
 	}
+	SendOKResponse(json.NewEncoder(w), r.URL.String(), data)
 }
 
-func adjustQueryResults(data []interface{}, adjustQueryEntriesFuncs ...func(map[string]interface{}) error) error {
-	for _, dataEntry := range data {
-		dataEntryMap, ok := dataEntry.(map[string]interface{})
-		if !ok {
-			return fmt.Errorf("error type asserting query results as a map")
-		} else {
-			for _, f := range adjustQueryEntriesFuncs {
-				if err := f(dataEntryMap); err != nil {
-					return err
-				}
-			}
-		}
+// sendOKArrayResponse writes the rows of a query as data of an OK response, the data is always an array.
+func sendOKArrayResponse[T any](w http.ResponseWriter, r *http.Request, rows []T) {
+	if rows == nil {
+		rows = []T{}
 	}
-	return nil
+	SendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{rows})
 }
 
 func SendBadRequestResponse(w http.ResponseWriter, route, message string) {
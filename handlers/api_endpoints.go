@@ -0,0 +1,117 @@
+// Code generated by cmd/api-endpoints from the swag annotations of the handlers. DO NOT EDIT.
+
+package handlers
+
+import (
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+	"github.com/graphql-go/graphql"
+)
+
+var apiEndpoints = []apiEndpoint{
+	{Method: "GET", Path: "/api/healthz", Tag: "Misc", Summary: "Health of the explorer", Shape: apiShapeNone},
+	{Method: "GET", Path: "/api/healthz-loadbalancer", Tag: "Misc", Summary: "Health of the explorer-api regarding having a healthy connection to the database", Shape: apiShapeNone},
+	{Method: "POST", Path: "/api/v1/app/dashboard", Tag: "Mobile", Summary: "Get the app dashboard of up to 100 validators", Body: types.DashboardRequest{}, Response: DashboardResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/block/{slotOrHash}", Tag: "Slot", Summary: "Get a slot by its slot number or root hash. Alternatively get the latest slot or the slot containing the head block.", Response: types.APISlotResponse{}, Shape: apiShapeRows},
+	{Method: "GET", Path: "/api/v1/block/{slot}/attestations", Tag: "Slot", Summary: "Get the attestations included in a specific slot", Response: []types.APIAttestationResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/block/{slot}/attesterslashings", Tag: "Slot", Summary: "Get the attester slashings included in a specific slot", Response: []types.APIAttesterSlashingResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/block/{slot}/deposits", Tag: "Slot", Summary: "Get the deposits included in a specific block", Query: []apiParam{{Name: "limit", Description: "Limit the number of results", Type: "integer"}, {Name: "offset", Description: "Offset the number of results", Type: "integer"}}, Response: []types.APIDepositResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/block/{slot}/proposerslashings", Tag: "Slot", Summary: "Get the proposer slashings included in a specific slot", Response: []types.APIProposerSlashingResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/block/{slot}/voluntaryexits", Tag: "Slot", Summary: "Get the voluntary exits included in a specific slot", Response: []types.APIVoluntaryExitResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/chart/{chart}", Tag: "Misc", Summary: "Returns charts from the page https://beaconcha.in/charts as PNG", Shape: apiShapePNG},
+	{Method: "POST", Path: "/api/v1/client/metrics", Tag: "User", Summary: "Used in eth2 clients to submit stats to your beaconcha.in account. This data can be accessed by the app or the user stats api call.", Query: []apiParam{{Name: "apikey", Description: "User API key, can be found on https://beaconcha.in/user/settings", Type: "string", Required: true}, {Name: "machine", Description: "Name your device if you have multiple devices you want to monitor", Type: "string"}}, Shape: apiShapeNoData},
+	{Method: "GET", Path: "/api/v1/dashboard/data/allbalances", Tag: "Dashboard", Summary: "Get the consensus and execution income chart of a set of validators", Query: []apiParam{{Name: "validators", Description: "Comma separated list of validator indices or pubkeys", Type: "string", Required: true}, {Name: "days", Description: "Number of days", Type: "integer"}}, Response: dashboardBalanceCombinedResponse{}, Shape: apiShapeRaw},
+	{Method: "GET", Path: "/api/v1/dashboard/data/balance", Tag: "Dashboard", Summary: "Get the balance chart of a set of validators as [timestamp, validator count, balance, effective balance]", Query: []apiParam{{Name: "validators", Description: "Comma separated list of validator indices or pubkeys", Type: "string", Required: true}}, Response: [][]float64{}, Shape: apiShapeRaw},
+	{Method: "GET", Path: "/api/v1/dashboard/data/balances", Tag: "Dashboard", Summary: "Get the income chart of a set of validators", Query: []apiParam{{Name: "validators", Description: "Comma separated list of validator indices or pubkeys", Type: "string", Required: true}}, Response: []types.ChartDataPoint{}, Shape: apiShapeRaw},
+	{Method: "GET", Path: "/api/v1/dashboard/data/proposals", Tag: "Dashboard", Summary: "Get the proposals of a set of validators as [timestamp, status]", Query: []apiParam{{Name: "validators", Description: "Comma separated list of validator indices or pubkeys", Type: "string", Required: true}}, Response: [][]int64{}, Shape: apiShapeRaw},
+	{Method: "POST", Path: "/api/v1/dashboard/widget", Tag: "Mobile", Summary: "Get the mobile widget data of up to 100 validators", Body: types.DashboardRequest{}, Response: types.WidgetResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/ens/lookup/{domain}", Tag: "Ens", Summary: "Get the address for an ens name and vice versa", Response: types.EnsDomainResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/epoch/{epoch}", Tag: "Epoch", Summary: "Get epoch by number, latest, finalized", Response: types.APIEpochResponse{}, Shape: apiShapeRows},
+	{Method: "GET", Path: "/api/v1/epoch/{epoch}/blocks", Tag: "Epoch", Summary: "Get epoch blocks by epoch number, latest or finalized", Response: []types.APIBlockResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/epoch/{epoch}/slots", Tag: "Epoch", Summary: "Get epoch blocks by epoch number, latest or finalized", Response: []types.APIBlockResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/eth1deposit/{txhash}", Tag: "Execution", Summary: "Get an eth1 deposit by its eth1 transaction hash", Response: types.ApiValidatorDepositsResponse{}, Shape: apiShapeRows},
+	{Method: "GET", Path: "/api/v1/ethstore/{day}", Tag: "ETH.STORE®", Summary: "Get ETH.STORE® reference rate for a specified beaconchain-day or the latest day", Response: types.ApiEthStoreDayResponse{}, Shape: apiShapeRows},
+	{Method: "GET", Path: "/api/v1/execution/address/{address}", Tag: "Execution", Summary: "Gets information about an Ethereum address.", Query: []apiParam{{Name: "token", Description: "filter for a specific token by providing a ethereum token contract address", Type: "string"}}, Response: types.ApiEth1AddressResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/execution/address/{address}/erc20tokens", Tag: "Execution", Summary: "Returns the ERC20 token balances for a given Ethereum address.", Query: []apiParam{{Name: "offset", Description: "data offset", Type: "integer"}, {Name: "limit", Description: "data limit (ranging from 1 to 200)", Type: "integer"}}, Response: []types.ApiEth1AddressERC20TokenResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/execution/block/{blockNumber}", Tag: "Execution", Summary: "Get execution blocks", Response: []types.ExecutionBlockApiResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/execution/gasnow", Tag: "Execution", Summary: "Gets the current estimation for gas prices in GWei.", Response: types.GasNowPageData{}, Shape: apiShapeRaw},
+	{Method: "GET", Path: "/api/v1/execution/{addressIndexOrPubkey}/produced", Tag: "Execution", Summary: "Get proposed or mined blocks", Query: []apiParam{{Name: "offset", Description: "Offset", Type: "integer"}, {Name: "limit", Description: "Limit the amount of entries you wish to receive (Maximum: 100)", Type: "integer"}, {Name: "sort", Description: "Sort via the block number either by 'asc' or 'desc'", Type: "string"}}, Response: []types.ExecutionBlockApiResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/exports", Tag: "Exports", Summary: "Get your most recent exports", Query: []apiParam{{Name: "apikey", Description: "User api key, may also be sent as apikey or X-API-KEY header", Type: "string"}}, Response: []types.ApiExportJob{}, Shape: apiShapeData, Auth: true},
+	{Method: "POST", Path: "/api/v1/exports", Tag: "Exports", Summary: "Submit an export of the history of a set of validators", Query: []apiParam{{Name: "apikey", Description: "User api key, may also be sent as apikey or X-API-KEY header", Type: "string"}}, Body: types.ApiExportRequest{}, Response: types.ApiExportJob{}, Shape: apiShapeData, Auth: true},
+	{Method: "GET", Path: "/api/v1/exports/{id}", Tag: "Exports", Summary: "Get the state of an export, completed exports include download links of their files", Query: []apiParam{{Name: "apikey", Description: "User api key, may also be sent as apikey or X-API-KEY header", Type: "string"}}, Response: types.ApiExportJob{}, Shape: apiShapeData, Auth: true},
+	{Method: "GET", Path: "/api/v1/graffitiwall", Tag: "Misc", Summary: "Get the most recent pixels that have been painted.", Query: []apiParam{{Name: "startx", Description: "Start X offset", Type: "integer"}, {Name: "starty", Description: "Start Y offset", Type: "integer"}, {Name: "endx", Description: "End X limit", Type: "integer"}, {Name: "endy", Description: "End Y limit", Type: "integer"}, {Name: "startSlot", Description: "Start slot to query (end slot - 10000 if empty)", Type: "integer"}, {Name: "slot", Description: "End slot to query", Type: "integer"}, {Name: "summarize", Description: "Only return end state of each pixel", Type: "boolean"}}, Response: []types.ApiGraffitiwallResponse{}, Shape: apiShapeData},
+	{Method: "POST", Path: "/api/v1/graphql", Tag: "GraphQL", Summary: "Query consensus and execution layer data with graphql", Body: graphqlRequest{}, Response: graphql.Result{}, Shape: apiShapeRaw},
+	{Method: "GET", Path: "/api/v1/latestState", Tag: "Network", Summary: "Get the latest state of the network", Response: types.LatestState{}, Shape: apiShapeRaw},
+	{Method: "GET", Path: "/api/v1/openapi.json", Tag: "Misc", Summary: "Get the OpenAPI 3 document of the api", Response: openAPIDocument{}, Shape: apiShapeRaw},
+	{Method: "GET", Path: "/api/v1/reorgs", Tag: "Slot", Summary: "Get the most recent chain reorganizations", Query: []apiParam{{Name: "limit", Description: "Limit the number of results (maximum 100)", Type: "integer"}, {Name: "offset", Description: "Offset the number of results", Type: "integer"}}, Response: []types.APIReorgResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/rocketpool/stats", Tag: "Rocketpool", Summary: "Get global rocketpool network statistics", Response: types.APIRocketpoolStatsResponse{}, Shape: apiShapeRows},
+	{Method: "GET", Path: "/api/v1/rocketpool/validator/{indexOrPubkey}", Tag: "Rocketpool", Summary: "Get rocketpool specific data for given validators", Response: types.ApiRocketpoolValidatorResponse{}, Shape: apiShapeRows},
+	{Method: "GET", Path: "/api/v1/slot/{slotOrHash}", Tag: "Slot", Summary: "Get a slot by its slot number or root hash. Alternatively get the latest slot or the slot containing the head block.", Response: types.APISlotResponse{}, Shape: apiShapeRows},
+	{Method: "GET", Path: "/api/v1/slot/{slot}/attestations", Tag: "Slot", Summary: "Get the attestations included in a specific slot", Response: []types.APIAttestationResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/slot/{slot}/attesterslashings", Tag: "Slot", Summary: "Get the attester slashings included in a specific slot", Response: []types.APIAttesterSlashingResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/slot/{slot}/consolidations", Tag: "Slot", Summary: "Get the consolidation requests included in a specific slot", Response: []types.APIConsolidationRequestResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/slot/{slot}/depositrequests", Tag: "Slot", Summary: "Get the execution layer deposit requests included in a specific slot", Response: []types.APIDepositRequestResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/slot/{slot}/deposits", Tag: "Slot", Summary: "Get the deposits included in a specific block", Query: []apiParam{{Name: "limit", Description: "Limit the number of results", Type: "integer"}, {Name: "offset", Description: "Offset the number of results", Type: "integer"}}, Response: []types.APIDepositResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/slot/{slot}/proposerslashings", Tag: "Slot", Summary: "Get the proposer slashings included in a specific slot", Response: []types.APIProposerSlashingResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/slot/{slot}/voluntaryexits", Tag: "Slot", Summary: "Get the voluntary exits included in a specific slot", Response: []types.APIVoluntaryExitResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/slot/{slot}/withdrawalrequests", Tag: "Slot", Summary: "Get the execution layer withdrawal requests included in a specific slot", Response: []types.APIWithdrawalRequestResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/slot/{slot}/withdrawals", Tag: "Slot", Summary: "Get the withdrawals included in a specific slot", Response: types.APISlotWithdrawalResponse{}, Shape: apiShapeRows},
+	{Method: "POST", Path: "/api/v1/stats/{apiKey}", Tag: "User", Summary: "Deprecated, use /api/v1/client/metrics", Shape: apiShapeNoData},
+	{Method: "POST", Path: "/api/v1/stats/{apiKey}/{machine}", Tag: "User", Summary: "Deprecated, use /api/v1/client/metrics", Shape: apiShapeNoData},
+	{Method: "GET", Path: "/api/v1/stream", Tag: "Stream", Summary: "Stream live chain events over a websocket", Query: []apiParam{{Name: "apikey", Description: "api key of the user, may also be given as apikey or X-API-KEY header", Type: "string"}}, Shape: apiShapeNone},
+	{Method: "POST", Path: "/api/v1/stripe/webhook", Tag: "Misc", Summary: "Receive the events of the stripe webhook service", Shape: apiShapeNone},
+	{Method: "GET", Path: "/api/v1/sync_committee/{period}", Tag: "SyncCommittee", Summary: "Get the sync-committee for a sync-period", Response: types.APISyncCommitteeResponse{}, Shape: apiShapeRows},
+	{Method: "POST", Path: "/api/v1/user/dashboard/remove", Tag: "User", Summary: "unsubscribes a user from a specific validator via index from both watchlist and notification events", Body: []string{}, Shape: apiShapeNoData, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/dashboard/save", Tag: "User", Summary: "subscribes a user to get notifications from a specific validator via index", Body: []string{}, Shape: apiShapeNoData, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/ethpool", Tag: "User", Summary: "Register an ethpool subscription", Shape: apiShapeNoData, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/mobile/notify/register", Tag: "User", Summary: "Register or update your mobile notification token", Shape: apiShapeNoData, Auth: true},
+	{Method: "GET", Path: "/api/v1/user/mobile/settings", Tag: "User", Summary: "Get your device settings, currently only whether to enable mobile notifcations or not", Response: types.MobileSettingsData{}, Shape: apiShapeRows, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/mobile/settings", Tag: "User", Summary: "Changing your devices mobile settings", Response: types.MobileSettingsData{}, Shape: apiShapeRows, Auth: true},
+	{Method: "GET", Path: "/api/v1/user/notifications", Tag: "User", Summary: "Get a set of events a user is subscribed to", Body: types.UsersNotificationsRequest{}, Response: []types.Subscription{}, Shape: apiShapeData, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/notifications", Tag: "User", Summary: "Get a set of events a user is subscribed to", Body: types.UsersNotificationsRequest{}, Response: []types.Subscription{}, Shape: apiShapeData, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/notifications/bundled/subscribe", Tag: "User", Summary: "Subscribe to multiple events", Shape: apiShapeNoData, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/notifications/bundled/unsubscribe", Tag: "User", Summary: "Unsubscribe from multiple events", Shape: apiShapeNoData, Auth: true},
+	{Method: "GET", Path: "/api/v1/user/notifications/history", Tag: "User", Summary: "Get the notifications that have been sent to you, the most recent first", Query: []apiParam{{Name: "limit", Description: "Number of notifications, default 100, max 1000", Type: "integer"}, {Name: "before_id", Description: "Only return notifications with a lower id", Type: "integer"}, {Name: "since_id", Description: "Only return notifications with a higher id, oldest first", Type: "integer"}, {Name: "unread", Description: "Only return notifications that have not been read", Type: "boolean"}, {Name: "unacknowledged", Description: "Only return notifications that have not been acknowledged", Type: "boolean"}}, Response: []types.UserNotificationHistoryEntry{}, Shape: apiShapeData, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/notifications/history/acknowledge", Tag: "User", Summary: "Acknowledge notifications of your notification history, acknowledged notifications are read as well", Body: types.NotificationHistoryUpdateRequest{}, Shape: apiShapeNoData, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/notifications/history/read", Tag: "User", Summary: "Mark notifications of your notification history as read", Body: types.NotificationHistoryUpdateRequest{}, Shape: apiShapeNoData, Auth: true},
+	{Method: "GET", Path: "/api/v1/user/notifications/history/unread", Tag: "User", Summary: "Get the number of notifications that you have not read", Response: types.NotificationHistoryUnreadResponse{}, Shape: apiShapeData, Auth: true},
+	{Method: "GET", Path: "/api/v1/user/notifications/rules", Tag: "User", Summary: "Get your notification rules", Response: []types.NotificationRule{}, Shape: apiShapeData, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/notifications/rules", Tag: "User", Summary: "Add a notification rule that notifies you when all or any of its conditions are fulfilled", Body: types.NotificationRule{}, Response: types.NotificationRule{}, Shape: apiShapeData, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/notifications/rules/{ruleID}/delete", Tag: "User", Summary: "Delete one of your notification rules", Shape: apiShapeNoData, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/notifications/rules/{ruleID}/update", Tag: "User", Summary: "Replace the name, the match and the conditions of one of your notification rules", Body: types.NotificationRule{}, Shape: apiShapeNoData, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/notifications/subscribe", Tag: "User", Summary: "Subscribe to an event", Query: []apiParam{{Name: "event", Description: "Name of the event", Type: "string", Required: true}, {Name: "filter", Description: "Filter of the event, e.g. a validator pubkey", Type: "string"}, {Name: "threshold", Description: "Threshold of the event", Type: "number"}}, Shape: apiShapeNoData, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/notifications/unsubscribe", Tag: "User", Summary: "Unsubscribe from an event", Query: []apiParam{{Name: "event", Description: "Name of the event", Type: "string", Required: true}, {Name: "filter", Description: "Filter of the event, e.g. a validator pubkey", Type: "string"}}, Shape: apiShapeNoData, Auth: true},
+	{Method: "GET", Path: "/api/v1/user/stats", Tag: "User", Summary: "Get your client submitted stats", Response: types.StatsDataStruct{}, Shape: apiShapeData, Auth: true},
+	{Method: "GET", Path: "/api/v1/user/stats/{offset}/{limit}", Tag: "User", Summary: "Get your client submitted stats", Response: types.StatsDataStruct{}, Shape: apiShapeData, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/subscription/register", Tag: "User", Summary: "Register a subscription purchased in the mobile app", Body: types.MobileSubscription{}, Shape: apiShapeNoData, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/token", Tag: "User", Summary: "Exchange your oauth code for an access token or refresh your access token", Response: utils.OAuthResponse{}, Shape: apiShapeRaw},
+	{Method: "GET", Path: "/api/v1/user/validator/saved", Tag: "User", Summary: "Get all your tagged validators", Response: types.MinimalTaggedValidators{}, Shape: apiShapeRows, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/validator/{pubkey}/add", Tag: "User", Summary: "subscribes a user to get notifications from a specific validator", Shape: apiShapeNoData, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/validator/{pubkey}/remove", Tag: "User", Summary: "unsubscribes a user from a specific validator", Shape: apiShapeNoData, Auth: true},
+	{Method: "GET", Path: "/api/v1/user/webhooks/{webhookID}/deliveries", Tag: "User", Summary: "Get the most recent deliveries of one of your webhooks", Query: []apiParam{{Name: "limit", Description: "Number of deliveries, default 100, max 1000", Type: "integer"}}, Response: []types.UserWebhookDelivery{}, Shape: apiShapeData, Auth: true},
+	{Method: "POST", Path: "/api/v1/user/webhooks/{webhookID}/deliveries/{deliveryID}/replay", Tag: "User", Summary: "Send a failed or delivered webhook delivery again, the payload and the event id are unchanged", Shape: apiShapeNoData, Auth: true},
+	{Method: "POST", Path: "/api/v1/validator", Tag: "Validator", Summary: "Get up to 100 validators", Body: types.DashboardRequest{}, Response: ApiValidatorResponse{}, Shape: apiShapeRows},
+	{Method: "GET", Path: "/api/v1/validator/eth1/{address}", Tag: "Validator", Summary: "Get all validators that belong to an eth1 address", Query: []apiParam{{Name: "limit", Description: "Limit the number of results (default: 2000)", Type: "integer"}, {Name: "offset", Description: "Offset the results (default: 0)", Type: "integer"}}, Response: []types.ApiValidatorEth1Response{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/validator/leaderboard", Tag: "Validator", Summary: "Get the current top 100 performing validators (using the income over the last 7 days)", Response: []types.ApiValidatorPerformanceResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/validator/stats/{index}", Tag: "Validator", Summary: "Get the daily validator stats by the validator index", Query: []apiParam{{Name: "end_day", Description: "End day (default: latest day)", Type: "integer"}, {Name: "start_day", Description: "Start day (default: 0)", Type: "integer"}}, Response: []types.ApiValidatorDailyStatsResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/validator/withdrawalCredentials/{withdrawalCredentialsOrEth1address}", Tag: "Validator", Summary: "Get validator indexes and pubkeys of a withdrawal credential or eth1 address", Query: []apiParam{{Name: "limit", Description: "Limit the number of results, maximum: 200", Type: "integer"}, {Name: "offset", Description: "Offset the number of results", Type: "integer"}}, Response: []types.ApiWithdrawalCredentialsResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/validator/{indexOrPubkey}", Tag: "Validator", Summary: "Get up to 100 validators", Response: ApiValidatorResponse{}, Shape: apiShapeRows},
+	{Method: "GET", Path: "/api/v1/validator/{indexOrPubkey}/attestationeffectiveness", Tag: "Validator", Summary: "DEPRECIATED - USE /attestationefficiency (Get the current performance of up to 100 validators)", Response: []types.ValidatorEffectiveness{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/validator/{indexOrPubkey}/attestationefficiency", Tag: "Validator", Summary: "Get the current performance of up to 100 validators", Response: []types.ValidatorEffectiveness{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/validator/{indexOrPubkey}/attestations", Tag: "Validator", Summary: "Get all attestations during the last 100 epochs for up to 100 validators", Response: []types.ApiValidatorAttestationsResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/validator/{indexOrPubkey}/balancehistory", Tag: "Validator", Summary: "Get the balance history of up to 100 validators", Query: []apiParam{{Name: "latest_epoch", Description: "The latest epoch to consider in the query", Type: "integer"}, {Name: "offset", Description: "Number of items to skip", Type: "integer"}, {Name: "limit", Description: "Maximum number of items to return, up to 100", Type: "integer"}}, Response: []types.ApiValidatorBalanceHistoryResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/validator/{indexOrPubkey}/blsChange", Tag: "Validator", Summary: "Gets the BLS withdrawal address change for up to 100 validators", Response: []types.ApiValidatorBlsChangeResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/validator/{indexOrPubkey}/deposits", Tag: "Validator", Summary: "Get all eth1 deposits for up to 100 validators", Response: []types.ApiValidatorDepositsResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/validator/{indexOrPubkey}/execution/performance", Tag: "Validator", Summary: "Get the current execution reward performance of up to 100 validators. If block was produced via mev relayer, this endpoint will use the relayer data as block reward instead of the normal block reward.", Response: []types.ExecutionPerformanceResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/validator/{indexOrPubkey}/incomedetailhistory", Tag: "Validator", Summary: "Get the income detail history of up to 100 validators", Query: []apiParam{{Name: "latest_epoch", Description: "The latest epoch to consider in the query", Type: "integer"}, {Name: "offset", Description: "Number of items to skip", Type: "integer"}, {Name: "limit", Description: "Maximum number of items to return, up to 100", Type: "integer"}}, Response: []types.ApiValidatorIncomeHistoryResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/validator/{indexOrPubkey}/performance", Tag: "Validator", Summary: "Get the current consensus reward performance of up to 100 validators", Response: []types.ApiValidatorCurrentPerformanceResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/validator/{indexOrPubkey}/proposals", Tag: "Validator", Summary: "Get all proposed blocks during the last 100 epochs for up to 100 validators. Optionally set the epoch query parameter to look back further.", Query: []apiParam{{Name: "epoch", Description: "Page the result by epoch", Type: "integer"}}, Response: []types.APIBlockResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/validator/{indexOrPubkey}/widget", Tag: "Mobile", Summary: "Get the mobile widget data of up to 100 validators", Response: types.WidgetResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/validator/{indexOrPubkey}/withdrawals", Tag: "Validator", Summary: "Get the withdrawal history of up to 100 validators for the last 100 epochs. To receive older withdrawals modify the epoch paraum", Query: []apiParam{{Name: "epoch", Description: "the start epoch for the withdrawal history (default: latest epoch)", Type: "integer"}}, Response: []types.ApiValidatorWithdrawalResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/validators/proposalLuck", Tag: "Validator", Summary: "Get the proposal luck of a validator or a list of validators", Query: []apiParam{{Name: "validators", Description: "Provide a comma separated list of validator indices or pubkeys", Type: "string", Required: true}}, Response: types.ApiProposalLuckResponse{}, Shape: apiShapeData},
+	{Method: "GET", Path: "/api/v1/validators/queue", Tag: "Validator", Summary: "Get the current validator queue", Response: types.ApiValidatorQueueResponse{}, Shape: apiShapeRows},
+	{Method: "GET", Path: "/api/v2/validator/{indexOrPubkey}/attestations", Tag: "Validator", Summary: "Get the attestation history of up to 100 validators, paginated by epoch", Query: []apiParam{{Name: "cursor", Description: "Cursor of the next page, returned in the paging object of the previous page", Type: "string"}, {Name: "limit", Description: "Number of items per page, up to 1000", Type: "integer"}}, Response: []types.ApiValidatorAttestationsResponse{}, Shape: apiShapePage},
+	{Method: "GET", Path: "/api/v2/validator/{indexOrPubkey}/balancehistory", Tag: "Validator", Summary: "Get the balance history of up to 100 validators, paginated by epoch", Query: []apiParam{{Name: "cursor", Description: "Cursor of the next page, returned in the paging object of the previous page", Type: "string"}, {Name: "limit", Description: "Number of items per page, up to 1000", Type: "integer"}}, Response: []types.ApiValidatorBalanceHistoryResponse{}, Shape: apiShapePage},
+	{Method: "GET", Path: "/api/v2/validator/{indexOrPubkey}/incomedetailhistory", Tag: "Validator", Summary: "Get the income detail history of up to 100 validators, paginated by epoch", Query: []apiParam{{Name: "cursor", Description: "Cursor of the next page, returned in the paging object of the previous page", Type: "string"}, {Name: "limit", Description: "Number of items per page, up to 1000", Type: "integer"}}, Response: []types.ApiValidatorIncomeHistoryResponse{}, Shape: apiShapePage},
+	{Method: "GET", Path: "/api/v2/validator/{indexOrPubkey}/proposals", Tag: "Validator", Summary: "Get the proposed blocks of up to 100 validators, paginated by slot", Query: []apiParam{{Name: "cursor", Description: "Cursor of the next page, returned in the paging object of the previous page", Type: "string"}, {Name: "limit", Description: "Number of items per page, up to 1000", Type: "integer"}}, Response: []types.APIBlockResponse{}, Shape: apiShapePage},
+}
@@ -29,7 +29,8 @@ import (
 // @Tags Execution
 // @Produce  json
 // @Param  txhash path string true "Eth1 transaction hash"
-// @Success 200 {object} types.ApiResponse
+// @Success 200 {object} types.ApiResponse{data=types.ApiValidatorDepositsResponse}
+// @x-rows true
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/eth1deposit/{txhash} [get]
 func ApiEth1Deposit(w http.ResponseWriter, r *http.Request) {
@@ -44,14 +45,14 @@ func ApiEth1Deposit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.ReaderDb.Query("SELECT amount, block_number, block_ts, from_address, merkletree_index, publickey, removed, signature, tx_hash, tx_index, tx_input, valid_signature, withdrawal_credentials FROM eth1_deposits WHERE tx_hash = $1", eth1TxHash)
+	data := []*types.ApiValidatorDepositsResponse{}
+	err = db.ReaderDb.Select(&data, "SELECT amount, block_number, block_ts, from_address, merkletree_index, publickey, removed, signature, tx_hash, tx_index, tx_input, valid_signature, withdrawal_credentials FROM eth1_deposits WHERE tx_hash = $1", eth1TxHash)
 	if err != nil {
 		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
-	defer rows.Close()
 
-	returnQueryResults(rows, w, r)
+	sendOKRowsResponse(w, r, data)
 }
 
 // ApiETH1ExecBlocks godoc
@@ -60,7 +61,7 @@ func ApiEth1Deposit(w http.ResponseWriter, r *http.Request) {
 // @Description Get execution blocks by execution block number
 // @Produce json
 // @Param blockNumber path string true "Provide one or more execution block numbers. Coma separated up to max 100. "
-// @Success 200 {object} types.ApiResponse
+// @Success 200 {object} types.ApiResponse{data=[]types.ExecutionBlockApiResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/execution/block/{blockNumber} [get]
 func ApiETH1ExecBlocks(w http.ResponseWriter, r *http.Request) {
@@ -121,7 +122,7 @@ func ApiETH1ExecBlocks(w http.ResponseWriter, r *http.Request) {
 // @Param offset query int false "Offset" default(0)
 // @Param limit query int false "Limit the amount of entries you wish to receive (Maximum: 100)" default(10) maximum(100)
 // @Param sort query string false "Sort via the block number either by 'asc' or 'desc'" default(desc)
-// @Success 200 {object} types.ApiResponse
+// @Success 200 {object} types.ApiResponse{data=[]types.ExecutionBlockApiResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/execution/{addressIndexOrPubkey}/produced [get]
 func ApiETH1AccountProducedBlocks(w http.ResponseWriter, r *http.Request) {
@@ -250,7 +251,7 @@ func ApiETH1AccountProducedBlocks(w http.ResponseWriter, r *http.Request) {
 // @Tags Execution
 // @Description The response is split into four estimated inclusion speeds rapid (15 seconds), fast (1 minute), standard (3 minutes) and slow (> 10 minutes).
 // @Produce json
-// @Success 200 {object} types.ApiResponse
+// @Success 200 {object} types.GasNowPageData
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/execution/gasnow [get]
 func ApiEth1GasNowData(w http.ResponseWriter, r *http.Request) {
@@ -284,7 +285,7 @@ func ApiEth1GasNowData(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param address path string true "provide an Ethereum address consists of an optional 0x prefix followed by 40 hexadecimal characters". It can also be a valid ENS name.
 // @Param token query string false "filter for a specific token by providing a ethereum token contract address"
-// @Success 200 {object} types.ApiResponse
+// @Success 200 {object} types.ApiResponse{data=types.ApiEth1AddressResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/execution/address/{address} [get]
 func ApiEth1Address(w http.ResponseWriter, r *http.Request) {
@@ -348,7 +349,7 @@ func ApiEth1Address(w http.ResponseWriter, r *http.Request) {
 // @Param address path string true "provide an Ethereum address consists of an optional 0x prefix followed by 40 hexadecimal characters". It can also be a valid ENS name.
 // @Param offset query int false "data offset" default(0)
 // @Param limit query int false "data limit (ranging from 1 to 200)" default(200)
-// @Success 200 {object} types.ApiResponse
+// @Success 200 {object} types.ApiResponse{data=[]types.ApiEth1AddressERC20TokenResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/execution/address/{address}/erc20tokens [get]
 func ApiEth1AddressERC20Tokens(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db"
+	"github.com/gobitfly/eth2-beaconchain-explorer/services"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+const (
+	apiExportMaxActive  = 3 // pending or running exports per user
+	apiExportListLimit  = 100
+	apiExportDateFormat = "2006-01-02"
+)
+
+// ApiExportCreate godoc
+// @Summary Submit an export of the history of a set of validators
+// @Description Select the validators by a list of indices or pubkeys, a withdrawal address or your saved dashboard and choose the datasets (balances, income_details, attestations, proposals, withdrawals) and a format (csv or parquet).
+// @Description The export is written in the background, poll /api/v1/exports/{id} for its download links. Files are deleted after some days.
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Param request body types.ApiExportRequest true "The validators, days and datasets of the export"
+// @Param apikey query string false "User api key, may also be sent as apikey or X-API-KEY header"
+// @Success 200 {object} types.ApiResponse{data=types.ApiExportJob}
+// @Failure 400 {object} types.ApiResponse
+// @Failure 401 {object} types.ApiResponse
+// @Failure 429 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/exports [post]
+func ApiExportCreate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	if !services.ExportsEnabled() {
+		sendErrorWithCodeResponse(w, r.URL.String(), "exports are not available", http.StatusServiceUnavailable)
+		return
+	}
+	userID, pkg, err := getApiUserByKeyOrToken(r)
+	if err != nil {
+		sendErrorWithCodeResponse(w, r.URL.String(), err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	req := &types.ApiExportRequest{}
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), "could not read body")
+		return
+	}
+	export, err := parseApiExportRequest(req, time.Now())
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), err.Error())
+		return
+	}
+
+	validators, invalid, err := resolveApiExportValidators(req, userID, int(GetUserPremiumByPackage(pkg).MaxValidators))
+	if err != nil {
+		logger.WithError(err).Errorf("error resolving validators of export")
+		sendServerErrorResponse(w, r.URL.String(), "could not retrieve db results")
+		return
+	}
+	if invalid != "" {
+		SendBadRequestResponse(w, r.URL.String(), invalid)
+		return
+	}
+	export.UserID = userID
+	export.Network = utils.GetNetwork()
+	export.Validators = make(pq.Int64Array, 0, len(validators))
+	for _, validator := range validators {
+		export.Validators = append(export.Validators, int64(validator))
+	}
+
+	active, err := db.CountUserActiveExports(userID)
+	if err != nil {
+		logger.WithError(err).Errorf("error counting active exports")
+		sendServerErrorResponse(w, r.URL.String(), "could not retrieve db results")
+		return
+	}
+	if active >= apiExportMaxActive {
+		sendErrorWithCodeResponse(w, r.URL.String(), fmt.Sprintf("only %d exports can be pending at a time", apiExportMaxActive), http.StatusTooManyRequests)
+		return
+	}
+
+	export.ID, err = db.InsertUserExport(export)
+	if err != nil {
+		logger.WithError(err).Errorf("error inserting export")
+		sendServerErrorResponse(w, r.URL.String(), "could not create the export")
+		return
+	}
+	export.Status = "pending"
+	export.Created = time.Now()
+
+	job, err := getApiExportJob(r, export)
+	if err != nil {
+		logger.WithError(err).Errorf("error creating export response")
+		sendServerErrorResponse(w, r.URL.String(), "could not create the export")
+		return
+	}
+	SendOKResponse(j, r.URL.String(), []interface{}{job})
+}
+
+// ApiExports godoc
+// @Summary Get your most recent exports
+// @Tags Exports
+// @Produce json
+// @Param apikey query string false "User api key, may also be sent as apikey or X-API-KEY header"
+// @Success 200 {object} types.ApiResponse{data=[]types.ApiExportJob}
+// @Failure 401 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/exports [get]
+func ApiExports(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, _, err := getApiUserByKeyOrToken(r)
+	if err != nil {
+		sendErrorWithCodeResponse(w, r.URL.String(), err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	exports, err := db.GetUserExports(userID, utils.GetNetwork(), apiExportListLimit)
+	if err != nil {
+		logger.WithError(err).Errorf("error retrieving exports")
+		sendServerErrorResponse(w, r.URL.String(), "could not retrieve db results")
+		return
+	}
+	jobs := make([]*types.ApiExportJob, 0, len(exports))
+	for _, export := range exports {
+		job, err := getApiExportJob(r, export)
+		if err != nil {
+			logger.WithError(err).Errorf("error creating export response")
+			sendServerErrorResponse(w, r.URL.String(), "could not create download links")
+			return
+		}
+		jobs = append(jobs, job)
+	}
+	sendOKArrayResponse(w, r, jobs)
+}
+
+// ApiExport godoc
+// @Summary Get the state of an export, completed exports include download links of their files
+// @Tags Exports
+// @Produce json
+// @Param id path int true "Id of the export"
+// @Param apikey query string false "User api key, may also be sent as apikey or X-API-KEY header"
+// @Success 200 {object} types.ApiResponse{data=types.ApiExportJob}
+// @Failure 400 {object} types.ApiResponse
+// @Failure 401 {object} types.ApiResponse
+// @Failure 404 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/exports/{id} [get]
+func ApiExport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j := json.NewEncoder(w)
+
+	userID, _, err := getApiUserByKeyOrToken(r)
+	if err != nil {
+		sendErrorWithCodeResponse(w, r.URL.String(), err.Error(), http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), "invalid export id")
+		return
+	}
+
+	export, err := db.GetUserExport(userID, utils.GetNetwork(), id)
+	if err != nil {
+		logger.WithError(err).Errorf("error retrieving export")
+		sendServerErrorResponse(w, r.URL.String(), "could not retrieve db results")
+		return
+	}
+	if export == nil {
+		sendErrorWithCodeResponse(w, r.URL.String(), "export not found", http.StatusNotFound)
+		return
+	}
+
+	job, err := getApiExportJob(r, export)
+	if err != nil {
+		logger.WithError(err).Errorf("error creating export response")
+		sendServerErrorResponse(w, r.URL.String(), "could not create download links")
+		return
+	}
+	SendOKResponse(j, r.URL.String(), []interface{}{job})
+}
+
+// parseApiExportRequest validates the days, datasets and format of an export request, the validators are resolved separately
+func parseApiExportRequest(req *types.ApiExportRequest, now time.Time) (*types.UserExport, error) {
+	sources := 0
+	for _, selected := range []bool{req.Validators != "", req.WithdrawalAddress != "", req.Dashboard} {
+		if selected {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return nil, fmt.Errorf("select the validators by exactly one of validators, withdrawal_address or dashboard")
+	}
+
+	startDate, err := time.Parse(apiExportDateFormat, req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start_date, expected YYYY-MM-DD")
+	}
+	endDate, err := time.Parse(apiExportDateFormat, req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end_date, expected YYYY-MM-DD")
+	}
+	genesis := time.Unix(int64(utils.Config.Chain.GenesisTimestamp), 0).UTC().Truncate(utils.Day)
+	if startDate.Before(genesis) {
+		return nil, fmt.Errorf("start_date must not be before the genesis on %v", genesis.Format(apiExportDateFormat))
+	}
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("end_date must not be before start_date")
+	}
+	if endDate.After(now) {
+		return nil, fmt.Errorf("end_date must not be in the future")
+	}
+	if days := uint64(endDate.Sub(startDate)/utils.Day) + 1; days > services.ExportMaxDays() {
+		return nil, fmt.Errorf("an export can span at most %d days", services.ExportMaxDays())
+	}
+
+	if len(req.Datasets) == 0 {
+		return nil, fmt.Errorf("no datasets selected")
+	}
+	datasets := pq.StringArray{}
+	selected := map[string]bool{}
+	for _, dataset := range req.Datasets {
+		if !services.IsExportDataset(dataset) {
+			return nil, fmt.Errorf("invalid dataset: %v", dataset)
+		}
+		if !selected[dataset] {
+			datasets = append(datasets, dataset)
+			selected[dataset] = true
+		}
+	}
+
+	format := req.Format
+	if format == "" {
+		format = services.ExportFormatCSV
+	}
+	if format != services.ExportFormatCSV && format != services.ExportFormatParquet {
+		return nil, fmt.Errorf("invalid format: %v", format)
+	}
+
+	return &types.UserExport{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Datasets:  datasets,
+		Format:    format,
+	}, nil
+}
+
+// resolveApiExportValidators returns the indices of the validators selected by an export request.
+// If the selection is invalid or too large a message for the user is returned instead.
+func resolveApiExportValidators(req *types.ApiExportRequest, userID uint64, maxValidators int) ([]uint64, string, error) {
+	var indices []uint64
+	switch {
+	case req.Validators != "":
+		var err error
+		indices, err = parseApiValidatorParamToIndices(req.Validators, maxValidators)
+		if err != nil {
+			return nil, err.Error(), nil
+		}
+	case req.WithdrawalAddress != "":
+		credentialsOrAddress := strings.ToLower(ReplaceEnsNameWithAddress(req.WithdrawalAddress))
+		if !utils.IsValidEth1Address(credentialsOrAddress) && !utils.IsValidWithdrawalCredentials(credentialsOrAddress) {
+			return nil, "invalid withdrawal credentials or eth1 address provided", nil
+		}
+		credentials := [][]byte{common.FromHex(credentialsOrAddress)}
+		if utils.IsValidEth1Address(credentialsOrAddress) {
+			// validators of an address may have eth1 or compounding withdrawal credentials
+			eth1Credentials, err := utils.AddressToWithdrawalCredentials(credentials[0])
+			if err != nil {
+				return nil, "", err
+			}
+			compoundingCredentials := append([]byte{0x02}, eth1Credentials[1:]...)
+			credentials = [][]byte{eth1Credentials, compoundingCredentials}
+		}
+		err := db.ReaderDb.Select(&indices, `
+			SELECT validatorindex
+			FROM validators
+			WHERE withdrawalcredentials = ANY($1)
+			ORDER BY validatorindex
+			LIMIT $2`, pq.ByteaArray(credentials), maxValidators+1)
+		if err != nil {
+			return nil, "", fmt.Errorf("error retrieving validators of withdrawal credentials: %w", err)
+		}
+	case req.Dashboard:
+		pubkeys := pq.ByteaArray{}
+		err := db.FrontendWriterDB.Select(&pubkeys, `
+			SELECT validator_publickey
+			FROM users_validators_tags
+			WHERE user_id = $1 AND tag = $2`, userID, utils.GetNetwork()+":"+string(types.ValidatorTagsWatchlist))
+		if err != nil {
+			return nil, "", fmt.Errorf("error retrieving dashboard validators: %w", err)
+		}
+		if len(pubkeys) > maxValidators {
+			return nil, fmt.Sprintf("only a maximum of %d validators can be exported", maxValidators), nil
+		}
+		err = db.ReaderDb.Select(&indices, `SELECT validatorindex FROM validators WHERE pubkey = ANY($1) ORDER BY validatorindex`, pubkeys)
+		if err != nil {
+			return nil, "", fmt.Errorf("error retrieving indices of dashboard validators: %w", err)
+		}
+	}
+
+	if len(indices) == 0 {
+		return nil, "no validators found", nil
+	}
+	if len(indices) > maxValidators {
+		return nil, fmt.Sprintf("only a maximum of %d validators can be exported", maxValidators), nil
+	}
+	return indices, "", nil
+}
+
+// getApiExportJob returns the api representation of an export, including download links of the files of a completed export
+func getApiExportJob(r *http.Request, export *types.UserExport) (*types.ApiExportJob, error) {
+	job := &types.ApiExportJob{
+		ID:         export.ID,
+		Status:     export.Status,
+		Validators: make([]uint64, 0, len(export.Validators)),
+		StartDate:  export.StartDate.Format(apiExportDateFormat),
+		EndDate:    export.EndDate.Format(apiExportDateFormat),
+		Datasets:   export.Datasets,
+		Format:     export.Format,
+		Created:    export.Created,
+		Completed:  export.Completed,
+		Expires:    export.Expires,
+		Files:      []*types.ApiExportFile{},
+	}
+	for _, validator := range export.Validators {
+		job.Validators = append(job.Validators, uint64(validator))
+	}
+	if export.Error != nil && export.Status != "completed" {
+		job.Error = *export.Error
+	}
+
+	if export.Status != "completed" || (export.Expires != nil && export.Expires.Before(time.Now())) {
+		return job, nil
+	}
+	for _, file := range export.Files {
+		link, linkExpires, err := services.ExportDownloadLink(r.Context(), export, file)
+		if err != nil {
+			return nil, err
+		}
+		job.Files = append(job.Files, &types.ApiExportFile{
+			Dataset:     file.Dataset,
+			Rows:        file.Rows,
+			Size:        file.Size,
+			DownloadURL: link,
+			LinkExpires: linkExpires,
+		})
+	}
+	return job, nil
+}
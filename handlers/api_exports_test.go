@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+)
+
+func TestParseApiExportRequest(t *testing.T) {
+	prevConfig := utils.Config
+	defer func() { utils.Config = prevConfig }()
+	utils.Config = &types.Config{}
+	utils.Config.Chain.GenesisTimestamp = 1606824023
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	valid := func() *types.ApiExportRequest {
+		return &types.ApiExportRequest{Validators: "1,2", StartDate: "2024-05-01", EndDate: "2024-05-31", Datasets: []string{"balances", "withdrawals", "balances"}}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(req *types.ApiExportRequest)
+		err    string
+	}{
+		{"valid", func(req *types.ApiExportRequest) {}, ""},
+		{"no validators", func(req *types.ApiExportRequest) { req.Validators = "" }, "select the validators by exactly one of validators, withdrawal_address or dashboard"},
+		{"two sources", func(req *types.ApiExportRequest) { req.Dashboard = true }, "select the validators by exactly one of validators, withdrawal_address or dashboard"},
+		{"invalid date", func(req *types.ApiExportRequest) { req.StartDate = "01.05.2024" }, "invalid start_date, expected YYYY-MM-DD"},
+		{"before genesis", func(req *types.ApiExportRequest) { req.StartDate = "2020-11-30" }, "start_date must not be before the genesis on 2020-12-01"},
+		{"reversed", func(req *types.ApiExportRequest) { req.EndDate = "2024-04-30" }, "end_date must not be before start_date"},
+		{"future", func(req *types.ApiExportRequest) { req.StartDate, req.EndDate = "2024-06-10", "2024-06-16" }, "end_date must not be in the future"},
+		{"too long", func(req *types.ApiExportRequest) { req.EndDate = "2024-06-01" }, "an export can span at most 31 days"},
+		{"no datasets", func(req *types.ApiExportRequest) { req.Datasets = nil }, "no datasets selected"},
+		{"invalid dataset", func(req *types.ApiExportRequest) { req.Datasets = []string{"blocks"} }, "invalid dataset: blocks"},
+		{"invalid format", func(req *types.ApiExportRequest) { req.Format = "xlsx" }, "invalid format: xlsx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(req)
+			export, err := parseApiExportRequest(req, now)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("error is %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if export.Format != "csv" || len(export.Datasets) != 2 || !export.EndDate.Equal(time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("unexpected export %+v", export)
+			}
+		})
+	}
+}
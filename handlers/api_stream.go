@@ -0,0 +1,484 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db"
+	"github.com/gobitfly/eth2-beaconchain-explorer/ratelimit"
+	"github.com/gobitfly/eth2-beaconchain-explorer/services"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+	"github.com/gorilla/websocket"
+	"github.com/lib/pq"
+)
+
+const (
+	apiStreamMaxConnections = 5 // per user
+	apiStreamEventBuffer    = 256
+	apiStreamMaxMessageSize = 64 * 1024
+	apiStreamWriteTimeout   = time.Second * 10
+	apiStreamPongTimeout    = time.Second * 60
+	apiStreamPingInterval   = apiStreamPongTimeout * 9 / 10
+)
+
+const (
+	apiStreamTopicSlots            = "slots"
+	apiStreamTopicFinalizedEpochs  = "finalized_epochs"
+	apiStreamTopicValidatorDuties  = "validator_duties"
+	apiStreamTopicValidatorResults = "validator_results"
+	apiStreamTopicGas              = "gas"
+
+	apiStreamTopicSubscriptions = "subscriptions" // reply to the commands of a client
+	apiStreamTopicError         = "error"
+)
+
+var apiStreamTopics = map[string]bool{
+	apiStreamTopicSlots:            true,
+	apiStreamTopicFinalizedEpochs:  true,
+	apiStreamTopicValidatorDuties:  true,
+	apiStreamTopicValidatorResults: true,
+	apiStreamTopicGas:              true,
+}
+
+var errApiStreamRateLimited = errors.New("rate limit exceeded")
+
+var apiStreamUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	// clients are authenticated by their api key and not by cookies, so cross origin connections are fine
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+var apiStreamConnectionsMu = &sync.Mutex{}
+var apiStreamConnections = map[uint64]int{} // open connections by user id, guarded by apiStreamConnectionsMu
+
+// apiStream is a websocket connection of the streaming api, its subscriptions are only accessed by the writing goroutine
+type apiStream struct {
+	conn          *websocket.Conn
+	r             *http.Request
+	maxValidators int
+
+	topics     map[string]bool
+	validators map[uint64]bool
+
+	lastDutyEpoch uint64          // proposal duties of epochs up to this one have been sent
+	syncDuties    map[string]bool // sent sync committee duties by validator and period
+}
+
+// ApiStream godoc
+// @Summary Stream live chain events over a websocket
+// @Description Upgrades the connection to a websocket that pushes new slots, finalized epochs, duties and results of watched validators and gas price updates.
+// @Description Clients send {"action": "subscribe", "topics": ["slots", "validator_results"], "validators": "1,2,0x..."} or {"action": "unsubscribe", "topics": [...]} and receive {"topic": "...", "data": ...} messages.
+// @Description Available topics are slots, finalized_epochs, validator_duties, validator_results and gas. Every command and every pushed message counts against the rate limits of the api key.
+// @Tags Stream
+// @Param apikey query string false "api key of the user, may also be given as apikey or X-API-KEY header"
+// @Success 101
+// @Failure 401 {object} types.ApiResponse
+// @Failure 429 {object} types.ApiResponse
+// @Router /api/v1/stream [get]
+func ApiStream(w http.ResponseWriter, r *http.Request) {
+	userID, pkg, err := getApiUserByKeyOrToken(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		sendErrorWithCodeResponse(w, r.URL.String(), err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if !acquireApiStreamConnection(userID) {
+		w.Header().Set("Content-Type", "application/json")
+		sendErrorWithCodeResponse(w, r.URL.String(), fmt.Sprintf("only a maximum of %d concurrent stream connections are allowed", apiStreamMaxConnections), http.StatusTooManyRequests)
+		return
+	}
+	defer releaseApiStreamConnection(userID)
+
+	conn, err := apiStreamUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader already responded with an error
+		logger.Warnf("error upgrading stream connection: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// the deadlines of the http server do not apply to hijacked connections
+	err = conn.NetConn().SetDeadline(time.Time{})
+	if err != nil {
+		logger.Warnf("error clearing deadlines of stream connection: %v", err)
+		return
+	}
+
+	stream := &apiStream{
+		conn:          conn,
+		r:             r,
+		maxValidators: int(GetUserPremiumByPackage(pkg).MaxValidators),
+		topics:        map[string]bool{},
+		validators:    map[uint64]bool{},
+		syncDuties:    map[string]bool{},
+	}
+	err = stream.run()
+	if err != nil && !errors.Is(err, errApiStreamRateLimited) && !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+		logger.Warnf("error streaming to user %v: %v", userID, err)
+	}
+}
+
+func acquireApiStreamConnection(userID uint64) bool {
+	apiStreamConnectionsMu.Lock()
+	defer apiStreamConnectionsMu.Unlock()
+	if apiStreamConnections[userID] >= apiStreamMaxConnections {
+		return false
+	}
+	apiStreamConnections[userID]++
+	return true
+}
+
+func releaseApiStreamConnection(userID uint64) {
+	apiStreamConnectionsMu.Lock()
+	defer apiStreamConnectionsMu.Unlock()
+	apiStreamConnections[userID]--
+	if apiStreamConnections[userID] <= 0 {
+		delete(apiStreamConnections, userID)
+	}
+}
+
+// run reads the commands of the client and writes the chain events of its subscriptions until the connection fails or is closed
+func (s *apiStream) run() error {
+	events, unsubscribe := services.SubscribeChainEvents(apiStreamEventBuffer)
+	defer unsubscribe()
+
+	requests := make(chan *types.ApiStreamRequest)
+	readErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go s.read(requests, readErr, done)
+
+	ping := time.NewTicker(apiStreamPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case err := <-readErr:
+			return err
+		case req := <-requests:
+			err := s.handleRequest(req)
+			if err != nil {
+				return err
+			}
+		case event := <-events:
+			err := s.handleEvent(event)
+			if err != nil {
+				return err
+			}
+		case <-ping.C:
+			err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(apiStreamWriteTimeout))
+			if err != nil {
+				return fmt.Errorf("error sending ping: %w", err)
+			}
+		}
+	}
+}
+
+// read passes the commands of the client to the writing goroutine until done is closed, malformed commands are passed as nil
+func (s *apiStream) read(requests chan<- *types.ApiStreamRequest, readErr chan<- error, done <-chan struct{}) {
+	s.conn.SetReadLimit(apiStreamMaxMessageSize)
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(apiStreamPongTimeout))
+	})
+
+	for {
+		err := s.conn.SetReadDeadline(time.Now().Add(apiStreamPongTimeout))
+		if err != nil {
+			readErr <- err
+			return
+		}
+		_, msg, err := s.conn.ReadMessage()
+		if err != nil {
+			readErr <- err
+			return
+		}
+		req := &types.ApiStreamRequest{}
+		err = json.Unmarshal(msg, req)
+		if err != nil {
+			req = nil
+		}
+		select {
+		case requests <- req:
+		case <-done:
+			return
+		}
+	}
+}
+
+func (s *apiStream) handleRequest(req *types.ApiStreamRequest) error {
+	if req == nil {
+		return s.send(apiStreamTopicError, nil, "invalid message, expected a json command")
+	}
+
+	for _, topic := range req.Topics {
+		if !apiStreamTopics[topic] {
+			return s.send(apiStreamTopicError, nil, fmt.Sprintf("invalid topic: %v", topic))
+		}
+	}
+
+	switch req.Action {
+	case "subscribe":
+		if req.Validators != "" {
+			indices, err := parseApiValidatorParamToIndices(req.Validators, s.maxValidators)
+			if err != nil {
+				return s.send(apiStreamTopicError, nil, err.Error())
+			}
+			s.validators = make(map[uint64]bool, len(indices))
+			for _, index := range indices {
+				s.validators[index] = true
+			}
+		}
+		newDuties := false
+		for _, topic := range req.Topics {
+			if (topic == apiStreamTopicValidatorDuties || topic == apiStreamTopicValidatorResults) && len(s.validators) == 0 {
+				return s.send(apiStreamTopicError, nil, fmt.Sprintf("topic %v requires validators", topic))
+			}
+			newDuties = newDuties || (topic == apiStreamTopicValidatorDuties && !s.topics[topic])
+		}
+		for _, topic := range req.Topics {
+			s.topics[topic] = true
+		}
+		err := s.sendSubscriptions()
+		if err != nil {
+			return err
+		}
+		if newDuties || (req.Validators != "" && s.topics[apiStreamTopicValidatorDuties]) {
+			// send the duties that are already known instead of waiting for the next epoch
+			s.lastDutyEpoch = 0
+			s.syncDuties = map[string]bool{}
+			return s.sendValidatorDuties(services.LatestEpoch())
+		}
+		return nil
+	case "unsubscribe":
+		for _, topic := range req.Topics {
+			delete(s.topics, topic)
+		}
+		if !s.topics[apiStreamTopicValidatorDuties] && !s.topics[apiStreamTopicValidatorResults] {
+			s.validators = map[uint64]bool{}
+		}
+		return s.sendSubscriptions()
+	default:
+		return s.send(apiStreamTopicError, nil, fmt.Sprintf("invalid action: %v", req.Action))
+	}
+}
+
+func (s *apiStream) sendSubscriptions() error {
+	subscriptions := &types.ApiStreamSubscriptions{
+		Topics:     make([]string, 0, len(s.topics)),
+		Validators: make([]uint64, 0, len(s.validators)),
+	}
+	for topic := range s.topics {
+		subscriptions.Topics = append(subscriptions.Topics, topic)
+	}
+	for validator := range s.validators {
+		subscriptions.Validators = append(subscriptions.Validators, validator)
+	}
+	sort.Strings(subscriptions.Topics)
+	sort.Slice(subscriptions.Validators, func(i, j int) bool { return subscriptions.Validators[i] < subscriptions.Validators[j] })
+	return s.send(apiStreamTopicSubscriptions, subscriptions, "")
+}
+
+func (s *apiStream) handleEvent(event *services.ChainEvent) error {
+	switch event.Topic {
+	case services.ChainEventSlot:
+		if s.topics[apiStreamTopicSlots] {
+			err := s.send(apiStreamTopicSlots, event.Data, "")
+			if err != nil {
+				return err
+			}
+		}
+		slot := event.Data.(*types.ApiStreamSlot)
+		if s.topics[apiStreamTopicValidatorResults] && s.validators[slot.Proposer] {
+			status, err := strconv.ParseUint(slot.Status, 10, 64)
+			if err != nil {
+				return s.sendInternalError(apiStreamTopicValidatorResults, fmt.Errorf("error parsing status %v of slot %v: %w", slot.Status, slot.Slot, err))
+			}
+			return s.send(apiStreamTopicValidatorResults, &types.ApiStreamValidatorResult{
+				Validator: slot.Proposer,
+				Duty:      "proposal",
+				Slot:      slot.Slot,
+				Epoch:     slot.Epoch,
+				Status:    status,
+			}, "")
+		}
+	case services.ChainEventEpoch:
+		epoch := event.Data.(*types.ApiStreamEpoch).Epoch
+		if s.topics[apiStreamTopicValidatorDuties] {
+			err := s.sendValidatorDuties(epoch)
+			if err != nil {
+				return err
+			}
+		}
+		if s.topics[apiStreamTopicValidatorResults] && epoch > 0 {
+			return s.sendAttestationResults(epoch - 1)
+		}
+	case services.ChainEventFinalizedEpoch:
+		if s.topics[apiStreamTopicFinalizedEpochs] {
+			return s.send(apiStreamTopicFinalizedEpochs, event.Data, "")
+		}
+	case services.ChainEventGas:
+		if s.topics[apiStreamTopicGas] {
+			return s.send(apiStreamTopicGas, event.Data, "")
+		}
+	}
+	return nil
+}
+
+// sendValidatorDuties sends the scheduled proposals and the sync committee duties of the current and next period of the watched validators that have not been sent yet
+func (s *apiStream) sendValidatorDuties(epoch uint64) error {
+	validators := s.watchedValidators()
+
+	fromEpoch := s.lastDutyEpoch + 1
+	if epoch > fromEpoch {
+		fromEpoch = epoch
+	}
+	proposals := []*types.ApiStreamValidatorDuty{}
+	err := db.ReaderDb.Select(&proposals, `
+		SELECT proposer AS validator, slot, epoch
+		FROM blocks
+		WHERE status = '0' AND proposer = ANY($1) AND epoch >= $2
+		ORDER BY slot`, pq.Array(validators), fromEpoch)
+	if err != nil {
+		return s.sendInternalError(apiStreamTopicValidatorDuties, fmt.Errorf("error retrieving scheduled proposals of stream validators: %w", err))
+	}
+	for _, proposal := range proposals {
+		proposal.Duty = "proposal"
+		err = s.send(apiStreamTopicValidatorDuties, proposal, "")
+		if err != nil {
+			return err
+		}
+		if proposal.Epoch > s.lastDutyEpoch {
+			s.lastDutyEpoch = proposal.Epoch
+		}
+	}
+
+	if epoch < utils.Config.Chain.ClConfig.AltairForkEpoch {
+		return nil
+	}
+	period := utils.SyncPeriodOfEpoch(epoch)
+	syncDuties := []*types.ApiStreamValidatorDuty{}
+	err = db.ReaderDb.Select(&syncDuties, `
+		SELECT DISTINCT validatorindex AS validator, period
+		FROM sync_committees
+		WHERE period IN ($1, $2) AND validatorindex = ANY($3)
+		ORDER BY period, validatorindex`, period, period+1, pq.Array(validators))
+	if err != nil {
+		return s.sendInternalError(apiStreamTopicValidatorDuties, fmt.Errorf("error retrieving sync committee duties of stream validators: %w", err))
+	}
+	for key := range s.syncDuties {
+		var validator, dutyPeriod uint64
+		_, err := fmt.Sscanf(key, "%d:%d", &validator, &dutyPeriod)
+		if err != nil || dutyPeriod < period {
+			delete(s.syncDuties, key)
+		}
+	}
+	for _, duty := range syncDuties {
+		key := fmt.Sprintf("%d:%d", duty.Validator, duty.Period)
+		if s.syncDuties[key] {
+			continue
+		}
+		duty.Duty = "sync"
+		duty.StartEpoch = utils.FirstEpochOfSyncPeriod(duty.Period)
+		duty.EndEpoch = utils.FirstEpochOfSyncPeriod(duty.Period+1) - 1
+		err = s.send(apiStreamTopicValidatorDuties, duty, "")
+		if err != nil {
+			return err
+		}
+		s.syncDuties[key] = true
+	}
+	return nil
+}
+
+// sendAttestationResults sends the attestations of the watched validators in the given epoch
+func (s *apiStream) sendAttestationResults(epoch uint64) error {
+	if db.BigtableClient == nil {
+		return nil
+	}
+	history, err := db.BigtableClient.GetValidatorAttestationHistory(s.watchedValidators(), epoch, epoch)
+	if err != nil {
+		return s.sendInternalError(apiStreamTopicValidatorResults, fmt.Errorf("error retrieving attestations of stream validators in epoch %v: %w", epoch, err))
+	}
+
+	results := []*types.ApiStreamValidatorResult{}
+	for validator, attestations := range history {
+		for _, attestation := range attestations {
+			if attestation.Epoch != epoch {
+				continue
+			}
+			results = append(results, &types.ApiStreamValidatorResult{
+				Validator:     validator,
+				Duty:          "attestation",
+				Slot:          attestation.AttesterSlot,
+				Epoch:         attestation.Epoch,
+				Status:        attestation.Status,
+				InclusionSlot: attestation.InclusionSlot,
+			})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Validator < results[j].Validator })
+	for _, result := range results {
+		err = s.send(apiStreamTopicValidatorResults, result, "")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *apiStream) watchedValidators() []uint64 {
+	validators := make([]uint64, 0, len(s.validators))
+	for validator := range s.validators {
+		validators = append(validators, validator)
+	}
+	return validators
+}
+
+// sendInternalError logs err and tells the client that the data of topic could not be retrieved.
+// The connection stays open, duties that have not been sent are sent with the next epoch.
+func (s *apiStream) sendInternalError(topic string, err error) error {
+	logger.Errorf("error streaming %v: %v", topic, err)
+	return s.send(apiStreamTopicError, nil, fmt.Sprintf("internal error retrieving %v", topic))
+}
+
+// send charges the message against the rate limits of the api key and writes it to the client.
+// If the rate limit is exceeded, the client is told so and the connection is closed.
+func (s *apiStream) send(topic string, data interface{}, errorMessage string) error {
+	allowed, err := ratelimit.LimitRequest(s.r)
+	if err != nil {
+		logger.Warnf("error rate limiting stream message: %v", err)
+	}
+	if !allowed {
+		msg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, errApiStreamRateLimited.Error())
+		err = s.write(&types.ApiStreamMessage{Topic: apiStreamTopicError, Error: errApiStreamRateLimited.Error()})
+		if err == nil {
+			err = s.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(apiStreamWriteTimeout))
+		}
+		if err != nil {
+			return fmt.Errorf("error closing rate limited stream: %w", err)
+		}
+		return errApiStreamRateLimited
+	}
+
+	return s.write(&types.ApiStreamMessage{Topic: topic, Data: data, Error: errorMessage})
+}
+
+func (s *apiStream) write(msg *types.ApiStreamMessage) error {
+	err := s.conn.SetWriteDeadline(time.Now().Add(apiStreamWriteTimeout))
+	if err != nil {
+		return err
+	}
+	err = s.conn.WriteJSON(msg)
+	if err != nil {
+		return fmt.Errorf("error writing %v stream message: %w", msg.Topic, err)
+	}
+	return nil
+}
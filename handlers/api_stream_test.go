@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db"
+	"github.com/gobitfly/eth2-beaconchain-explorer/db/dbtest"
+	"github.com/gobitfly/eth2-beaconchain-explorer/ratelimit"
+	"github.com/gobitfly/eth2-beaconchain-explorer/services"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+	"github.com/gorilla/websocket"
+)
+
+// dialApiStream starts a stream without authentication, its messages are charged against the fallback rate limiter of ratelimit as redis is not available.
+// setup is called with the stream before it is run, the stream is closed if it fails.
+func dialApiStream(t *testing.T, apiKey string, setup func(s *apiStream) error) *websocket.Conn {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := apiStreamUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		stream := &apiStream{conn: conn, r: r, maxValidators: 100, topics: map[string]bool{}, validators: map[uint64]bool{}, syncDuties: map[string]bool{}}
+		if setup != nil && setup(stream) != nil {
+			return
+		}
+		_ = stream.run()
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/v1/stream?apikey="+apiKey, nil)
+	if err != nil {
+		t.Fatalf("error dialing stream: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	err = conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	if err != nil {
+		t.Fatalf("error setting read deadline: %v", err)
+	}
+	return conn
+}
+
+func TestApiStreamSubscriptions(t *testing.T) {
+	conn := dialApiStream(t, "stream-subscriptions", nil)
+
+	tests := []struct {
+		name    string
+		request string
+		topic   string
+		want    string
+	}{
+		{"subscribe", `{"action": "subscribe", "topics": ["gas", "slots"]}`, apiStreamTopicSubscriptions, `{"topics":["gas","slots"],"validators":[]}`},
+		{"unsubscribe", `{"action": "unsubscribe", "topics": ["slots"]}`, apiStreamTopicSubscriptions, `{"topics":["gas"],"validators":[]}`},
+		{"invalid topic", `{"action": "subscribe", "topics": ["blocks"]}`, apiStreamTopicError, "invalid topic: blocks"},
+		{"missing validators", `{"action": "subscribe", "topics": ["validator_results"]}`, apiStreamTopicError, "topic validator_results requires validators"},
+		{"invalid action", `{"action": "list"}`, apiStreamTopicError, "invalid action: list"},
+		{"invalid json", `subscribe`, apiStreamTopicError, "invalid message, expected a json command"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := conn.WriteMessage(websocket.TextMessage, []byte(tt.request))
+			if err != nil {
+				t.Fatalf("error writing request: %v", err)
+			}
+			msg := &struct {
+				types.ApiStreamMessage
+				Data json.RawMessage `json:"data"`
+			}{}
+			err = conn.ReadJSON(msg)
+			if err != nil {
+				t.Fatalf("error reading reply: %v", err)
+			}
+			if msg.Topic != tt.topic {
+				t.Fatalf("topic is %v, want %v", msg.Topic, tt.topic)
+			}
+			got := msg.Error
+			if tt.topic == apiStreamTopicSubscriptions {
+				got = string(msg.Data)
+			}
+			if got != tt.want {
+				t.Errorf("reply is %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApiStreamInternalError(t *testing.T) {
+	prevConfig, prevReader := utils.Config, db.ReaderDb
+	defer func() { utils.Config, db.ReaderDb = prevConfig, prevReader }()
+	utils.Config = &types.Config{}
+	utils.Config.Chain.ClConfig.SlotsPerEpoch = 32
+	db.ReaderDb = (&dbtest.DB{Query: func(query string, args []driver.Value) (*dbtest.Rows, error) {
+		return nil, fmt.Errorf("connection refused")
+	}}).Sqlx()
+
+	conn := dialApiStream(t, "stream-internal-error", func(s *apiStream) error {
+		s.topics[apiStreamTopicValidatorDuties] = true
+		s.validators[1] = true
+		return s.handleEvent(&services.ChainEvent{Topic: services.ChainEventEpoch, Data: &types.ApiStreamEpoch{Epoch: 10}})
+	})
+
+	replies := []struct {
+		request string
+		topic   string
+		error   string
+	}{
+		{"", apiStreamTopicError, "internal error retrieving validator_duties"},
+		{`{"action": "subscribe", "topics": ["gas"]}`, apiStreamTopicSubscriptions, ""},
+	}
+	for _, reply := range replies {
+		if reply.request != "" {
+			err := conn.WriteMessage(websocket.TextMessage, []byte(reply.request))
+			if err != nil {
+				t.Fatalf("error writing request: %v", err)
+			}
+		}
+		msg := &types.ApiStreamMessage{}
+		err := conn.ReadJSON(msg)
+		if err != nil {
+			t.Fatalf("error reading reply, the stream has been closed: %v", err)
+		}
+		if msg.Topic != reply.topic || msg.Error != reply.error {
+			t.Fatalf("reply is %v %q, want %v %q", msg.Topic, msg.Error, reply.topic, reply.error)
+		}
+	}
+}
+
+func TestApiStreamRateLimit(t *testing.T) {
+	conn := dialApiStream(t, "stream-ratelimit", nil)
+
+	for i := 0; i <= ratelimit.FallbackRateLimitBurst; i++ {
+		err := conn.WriteMessage(websocket.TextMessage, []byte(`{"action": "subscribe", "topics": ["gas"]}`))
+		if err != nil {
+			t.Fatalf("error writing request: %v", err)
+		}
+	}
+
+	for {
+		msg := &types.ApiStreamMessage{}
+		err := conn.ReadJSON(msg)
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+				t.Errorf("connection has been closed with %v, want a policy violation", err)
+			}
+			return
+		}
+		if msg.Topic == apiStreamTopicError && msg.Error != errApiStreamRateLimited.Error() {
+			t.Errorf("unexpected error message: %v", msg.Error)
+		}
+	}
+}
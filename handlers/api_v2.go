@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db"
+	"github.com/gobitfly/eth2-beaconchain-explorer/services"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+const apiV2DefaultLimit = 100
+const apiV2MaxLimit = 1000
+
+// apiV2MaxEpochsPerPage limits the epochs that are scanned for a single page. If the validators have no data within
+// that range the page contains less items than requested, the next page continues where the scan stopped.
+const apiV2MaxEpochsPerPage = 1000
+
+// apiCursor is the position of the last item of a page, the next page starts with the item after it.
+// Epoch based history is ordered by epoch descending and validator index ascending, proposals by slot and block root descending.
+type apiCursor struct {
+	Epoch     uint64 `json:"e,omitempty"`
+	Validator uint64 `json:"v,omitempty"`
+	Slot      uint64 `json:"s,omitempty"`
+	Root      string `json:"r,omitempty"`
+	// Query binds the cursor to the endpoint and validators it has been created for
+	Query string `json:"q"`
+}
+
+// apiV2Page holds the pagination parameters of an api v2 request
+type apiV2Page struct {
+	validators []uint64
+	limit      int
+	cursor     *apiCursor
+	queryKey   string
+}
+
+// apiV2QueryKey identifies an endpoint and set of validators independent of the order the validators are passed in
+func apiV2QueryKey(endpoint string, validators []uint64) string {
+	sorted := make([]uint64, len(validators))
+	copy(sorted, validators)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	h := fnv.New64a()
+	h.Write([]byte(endpoint))
+	for _, validator := range sorted {
+		h.Write(binary.BigEndian.AppendUint64(nil, validator))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func encodeApiCursor(cursor *apiCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("error encoding cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeApiCursor(s string, queryKey string) (*apiCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor parameter")
+	}
+	cursor := &apiCursor{}
+	err = json.Unmarshal(data, cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor parameter")
+	}
+	if cursor.Query != queryKey {
+		return nil, fmt.Errorf("cursor parameter does not belong to this request")
+	}
+	return cursor, nil
+}
+
+func getApiV2Page(r *http.Request, endpoint string) (*apiV2Page, error) {
+	q := r.URL.Query()
+
+	page := &apiV2Page{limit: apiV2DefaultLimit}
+	if q.Has("limit") {
+		limit, err := strconv.Atoi(q.Get("limit"))
+		if err != nil || limit < 1 || limit > apiV2MaxLimit {
+			return nil, fmt.Errorf("invalid limit parameter, it has to be between 1 and %v", apiV2MaxLimit)
+		}
+		page.limit = limit
+	}
+
+	validators, err := parseApiValidatorParamToIndices(mux.Vars(r)["indexOrPubkey"], getUserPremium(r).MaxValidators)
+	if err != nil {
+		return nil, err
+	}
+	if len(validators) == 0 {
+		return nil, fmt.Errorf("no validators provided")
+	}
+	page.validators = validators
+	page.queryKey = apiV2QueryKey(endpoint, validators)
+
+	if q.Get("cursor") != "" {
+		page.cursor, err = decodeApiCursor(q.Get("cursor"), page.queryKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// sendApiV2Page writes a page of data, next is nil if there are no more pages
+func sendApiV2Page[T any](w http.ResponseWriter, r *http.Request, page *apiV2Page, data []T, next *apiCursor) {
+	response := &types.ApiPaginatedResponse{Status: "OK", Data: data}
+
+	if next != nil {
+		next.Query = page.queryKey
+		cursor, err := encodeApiCursor(next)
+		if err != nil {
+			logger.WithError(err).Error("error encoding api v2 cursor")
+			sendServerErrorResponse(w, r.URL.String(), "could not create cursor")
+			return
+		}
+
+		nextURL := *r.URL
+		q := nextURL.Query()
+		q.Set("cursor", cursor)
+		q.Set("limit", strconv.Itoa(page.limit))
+		nextURL.RawQuery = q.Encode()
+		response.Paging = &types.ApiPaging{NextCursor: cursor, Next: nextURL.RequestURI()}
+	}
+
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		sendServerErrorResponse(w, r.URL.String(), "could not serialize data results")
+		return
+	}
+}
+
+// paginateValidatorEpochs reads a page of epoch based validator history, starting at latestEpoch or after the cursor of the page.
+// fetch has to return the history of the validators of the page within the epoch range, ordered by epoch descending and validator index ascending.
+// The range scanned per fetch starts at the number of epochs needed to fill the page and is doubled for validators with sparse history.
+func paginateValidatorEpochs[T any](page *apiV2Page, latestEpoch uint64, fetch func(startEpoch, endEpoch uint64) ([]T, error), key func(T) (epoch, validator uint64)) ([]T, *apiCursor, error) {
+	endEpoch := latestEpoch
+	if page.cursor != nil && page.cursor.Epoch < endEpoch {
+		endEpoch = page.cursor.Epoch
+	}
+
+	data := make([]T, 0, page.limit+1)
+	window := uint64(page.limit/len(page.validators)) + 1
+	scanned := uint64(0)
+	for {
+		if window > apiV2MaxEpochsPerPage-scanned {
+			window = apiV2MaxEpochsPerPage - scanned
+		}
+		startEpoch := uint64(0)
+		if endEpoch >= window {
+			startEpoch = endEpoch - window + 1
+		}
+
+		rows, err := fetch(startEpoch, endEpoch)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, row := range rows {
+			epoch, validator := key(row)
+			if page.cursor != nil && (epoch > page.cursor.Epoch || epoch == page.cursor.Epoch && validator <= page.cursor.Validator) {
+				continue
+			}
+			data = append(data, row)
+		}
+
+		if len(data) > page.limit {
+			data = data[:page.limit]
+			epoch, validator := key(data[len(data)-1])
+			return data, &apiCursor{Epoch: epoch, Validator: validator}, nil
+		}
+		if startEpoch == 0 {
+			return data, nil, nil
+		}
+
+		scanned += endEpoch - startEpoch + 1
+		if scanned >= apiV2MaxEpochsPerPage {
+			// every item at or after startEpoch is part of this page, continue with the epoch before it
+			return data, &apiCursor{Epoch: startEpoch, Validator: math.MaxUint64}, nil
+		}
+		endEpoch = startEpoch - 1
+		window *= 2
+	}
+}
+
+// ApiV2ValidatorAttestations godoc
+// @Summary Get the attestation history of up to 100 validators, paginated by epoch
+// @Tags Validator
+// @Produce  json
+// @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Param  cursor query string false "Cursor of the next page, returned in the paging object of the previous page"
+// @Param  limit query int false "Number of items per page, up to 1000"
+// @Success 200 {object} types.ApiPaginatedResponse{data=[]types.ApiValidatorAttestationsResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v2/validator/{indexOrPubkey}/attestations [get]
+func ApiV2ValidatorAttestations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	page, err := getApiV2Page(r, "attestations")
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), err.Error())
+		return
+	}
+
+	data, next, err := paginateValidatorEpochs(page, services.LatestEpoch(), func(startEpoch, endEpoch uint64) ([]*types.ApiValidatorAttestationsResponse, error) {
+		history, err := db.BigtableClient.GetValidatorAttestationHistory(page.validators, startEpoch, endEpoch)
+		if err != nil {
+			return nil, err
+		}
+		return apiValidatorAttestations(history), nil
+	}, func(row *types.ApiValidatorAttestationsResponse) (uint64, uint64) {
+		return row.Epoch, row.ValidatorIndex
+	})
+	if err != nil {
+		logger.WithError(err).Error("error retrieving validator attestation history")
+		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	sendApiV2Page(w, r, page, data, next)
+}
+
+// ApiV2ValidatorBalanceHistory godoc
+// @Summary Get the balance history of up to 100 validators, paginated by epoch
+// @Tags Validator
+// @Produce  json
+// @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Param  cursor query string false "Cursor of the next page, returned in the paging object of the previous page"
+// @Param  limit query int false "Number of items per page, up to 1000"
+// @Success 200 {object} types.ApiPaginatedResponse{data=[]types.ApiValidatorBalanceHistoryResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v2/validator/{indexOrPubkey}/balancehistory [get]
+func ApiV2ValidatorBalanceHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	page, err := getApiV2Page(r, "balancehistory")
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), err.Error())
+		return
+	}
+
+	data, next, err := paginateValidatorEpochs(page, services.LatestEpoch(), func(startEpoch, endEpoch uint64) ([]*types.ApiValidatorBalanceHistoryResponse, error) {
+		history, err := db.BigtableClient.GetValidatorBalanceHistory(page.validators, startEpoch, endEpoch)
+		if err != nil {
+			return nil, err
+		}
+		return apiValidatorBalanceHistory(history), nil
+	}, func(row *types.ApiValidatorBalanceHistoryResponse) (uint64, uint64) {
+		return row.Epoch, row.Validatorindex
+	})
+	if err != nil {
+		logger.WithError(err).Error("error retrieving validator balance history")
+		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	sendApiV2Page(w, r, page, data, next)
+}
+
+// ApiV2ValidatorIncomeDetailsHistory godoc
+// @Summary Get the income detail history of up to 100 validators, paginated by epoch
+// @Tags Validator
+// @Produce  json
+// @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Param  cursor query string false "Cursor of the next page, returned in the paging object of the previous page"
+// @Param  limit query int false "Number of items per page, up to 1000"
+// @Success 200 {object} types.ApiPaginatedResponse{data=[]types.ApiValidatorIncomeHistoryResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v2/validator/{indexOrPubkey}/incomedetailhistory [get]
+func ApiV2ValidatorIncomeDetailsHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	page, err := getApiV2Page(r, "incomedetailhistory")
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), err.Error())
+		return
+	}
+
+	data, next, err := paginateValidatorEpochs(page, services.LatestFinalizedEpoch(), func(startEpoch, endEpoch uint64) ([]*types.ApiValidatorIncomeHistoryResponse, error) {
+		history, err := db.BigtableClient.GetValidatorIncomeDetailsHistory(page.validators, startEpoch, endEpoch)
+		if err != nil {
+			return nil, err
+		}
+		return apiValidatorIncomeDetailsHistory(history), nil
+	}, func(row *types.ApiValidatorIncomeHistoryResponse) (uint64, uint64) {
+		return row.Epoch, row.ValidatorIndex
+	})
+	if err != nil {
+		logger.WithError(err).Error("error retrieving validator income details history")
+		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	sendApiV2Page(w, r, page, data, next)
+}
+
+// ApiV2ValidatorProposals godoc
+// @Summary Get the proposed blocks of up to 100 validators, paginated by slot
+// @Tags Validator
+// @Produce  json
+// @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Param  cursor query string false "Cursor of the next page, returned in the paging object of the previous page"
+// @Param  limit query int false "Number of items per page, up to 1000"
+// @Success 200 {object} types.ApiPaginatedResponse{data=[]types.APIBlockResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v2/validator/{indexOrPubkey}/proposals [get]
+func ApiV2ValidatorProposals(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	page, err := getApiV2Page(r, "proposals")
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), err.Error())
+		return
+	}
+
+	// orphaned blocks share their slot with the canonical block, the block root keeps the order unique
+	beforeSlot := uint64(db.MaxSqlInteger)
+	beforeRoot := []byte{}
+	if page.cursor != nil {
+		beforeSlot = page.cursor.Slot
+		beforeRoot, err = hex.DecodeString(page.cursor.Root)
+		if err != nil {
+			SendBadRequestResponse(w, r.URL.String(), "invalid cursor parameter")
+			return
+		}
+	}
+
+	data := []*types.APIBlockResponse{}
+	err = db.ReaderDb.Select(&data, `
+	SELECT `+apiValidatorProposalsColumns+`
+	FROM blocks as b
+	WHERE b.proposer = ANY($1) AND (b.slot, b.blockroot) < ($2, $3)
+	ORDER BY b.slot DESC, b.blockroot DESC
+	LIMIT $4`, pq.Array(page.validators), beforeSlot, beforeRoot, page.limit+1)
+	if err != nil {
+		logger.WithError(err).Error("error retrieving validator proposals")
+		SendBadRequestResponse(w, r.URL.String(), "could not retrieve db results")
+		return
+	}
+
+	var next *apiCursor
+	if len(data) > page.limit {
+		data = data[:page.limit]
+		last := data[len(data)-1]
+		next = &apiCursor{Slot: last.Slot, Root: hex.EncodeToString(last.Blockroot)}
+	}
+
+	sendApiV2Page(w, r, page, data, next)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+)
+
+type testEpochItem struct {
+	epoch     uint64
+	validator uint64
+}
+
+func TestPaginateValidatorEpochs(t *testing.T) {
+	tests := []struct {
+		name        string
+		latestEpoch uint64
+		limit       int
+		validators  []uint64
+		// hasData reports whether a validator has history in an epoch
+		hasData func(validator, epoch uint64) bool
+	}{
+		{"dense", 50, 7, []uint64{1, 2, 3}, func(validator, epoch uint64) bool { return true }},
+		{"sparse validator", 50, 4, []uint64{1, 2, 3}, func(validator, epoch uint64) bool { return validator != 3 || epoch%7 == 0 }},
+		{"activated late", 80, 10, []uint64{5, 9}, func(validator, epoch uint64) bool { return validator == 5 || epoch >= 60 }},
+		{"single item per page", 20, 1, []uint64{1, 2}, func(validator, epoch uint64) bool { return true }},
+		{"beyond the scan limit", 5000, 100, []uint64{1}, func(validator, epoch uint64) bool { return epoch == 0 || epoch == 2500 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want []testEpochItem
+			for epoch := int(tt.latestEpoch); epoch >= 0; epoch-- {
+				for _, validator := range tt.validators {
+					if tt.hasData(validator, uint64(epoch)) {
+						want = append(want, testEpochItem{epoch: uint64(epoch), validator: validator})
+					}
+				}
+			}
+
+			fetch := func(startEpoch, endEpoch uint64) ([]testEpochItem, error) {
+				if startEpoch > endEpoch || endEpoch > tt.latestEpoch {
+					return nil, fmt.Errorf("invalid range %v-%v", startEpoch, endEpoch)
+				}
+				if endEpoch-startEpoch+1 > apiV2MaxEpochsPerPage {
+					return nil, fmt.Errorf("range %v-%v exceeds the scan limit", startEpoch, endEpoch)
+				}
+				var rows []testEpochItem
+				for _, item := range want {
+					if item.epoch >= startEpoch && item.epoch <= endEpoch {
+						rows = append(rows, item)
+					}
+				}
+				return rows, nil
+			}
+			key := func(item testEpochItem) (uint64, uint64) {
+				return item.epoch, item.validator
+			}
+
+			page := &apiV2Page{validators: tt.validators, limit: tt.limit}
+			var got []testEpochItem
+			for pages := 0; ; pages++ {
+				if pages > len(want)+10 {
+					t.Fatalf("pagination does not terminate")
+				}
+				data, next, err := paginateValidatorEpochs(page, tt.latestEpoch, fetch, key)
+				if err != nil {
+					t.Fatalf("error paginating: %v", err)
+				}
+				if len(data) > tt.limit {
+					t.Fatalf("page has %v items, limit is %v", len(data), tt.limit)
+				}
+				if next != nil && len(data) < tt.limit && next.Validator != ^uint64(0) {
+					t.Errorf("page has %v items but is not the last page", len(data))
+				}
+				got = append(got, data...)
+				if next == nil {
+					break
+				}
+
+				// pass the cursor through its encoded form like a client would
+				next.Query = "query"
+				encoded, err := encodeApiCursor(next)
+				if err != nil {
+					t.Fatalf("error encoding cursor: %v", err)
+				}
+				page.cursor, err = decodeApiCursor(encoded, "query")
+				if err != nil {
+					t.Fatalf("error decoding cursor: %v", err)
+				}
+			}
+
+			if len(got) != len(want) {
+				t.Fatalf("got %v items, want %v", len(got), len(want))
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("item %v is %+v, want %+v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestApiV2Cursor(t *testing.T) {
+	key := apiV2QueryKey("balancehistory", []uint64{3, 1, 2})
+	if key != apiV2QueryKey("balancehistory", []uint64{1, 2, 3}) {
+		t.Errorf("query key depends on the order of the validators")
+	}
+	if key == apiV2QueryKey("attestations", []uint64{1, 2, 3}) || key == apiV2QueryKey("balancehistory", []uint64{1, 2}) {
+		t.Errorf("query key does not identify the endpoint and validators")
+	}
+
+	encoded, err := encodeApiCursor(&apiCursor{Epoch: 10, Validator: 2, Query: key})
+	if err != nil {
+		t.Fatalf("error encoding cursor: %v", err)
+	}
+	_, err = decodeApiCursor(encoded, apiV2QueryKey("balancehistory", []uint64{1, 2}))
+	if err == nil {
+		t.Errorf("expected an error for a cursor of other validators")
+	}
+	for _, invalid := range []string{"not base64!", "bm90IGpzb24"} {
+		_, err = decodeApiCursor(invalid, key)
+		if err == nil {
+			t.Errorf("expected an error for cursor %q", invalid)
+		}
+	}
+
+	cursor, err := decodeApiCursor(encoded, key)
+	if err != nil {
+		t.Fatalf("error decoding cursor: %v", err)
+	}
+	if cursor.Epoch != 10 || cursor.Validator != 2 {
+		t.Errorf("unexpected cursor %+v", cursor)
+	}
+}
@@ -0,0 +1,359 @@
+// Package apidoc generates the api endpoint registry of the handlers package from the swag annotations of its handlers.
+//
+// Every @Router annotation becomes an endpoint, it takes its tag, summary, query parameters, request body, response and
+// authorization from the @Tags, @Summary, @Param, @Success and @Security annotations of the same handler. The response is read
+// from the @Success 200 annotation:
+//
+//	{object} types.ApiResponse                     the data of the response is null
+//	{object} types.ApiResponse{data=T}             the data of the response is T, with @x-rows true it is T if exactly one row has been found and an array of T otherwise
+//	{object} types.ApiPaginatedResponse{data=[]T}  the response is a page of T
+//	{object} T or {array} T                        T or []T is written without a wrapper
+//	{file} png                                     the response is a png image
+//
+// A handler without @Success 200 annotation or with a {string} response does not specify its response body.
+package apidoc
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Endpoint is an api route documented by a handler
+type Endpoint struct {
+	Method   string
+	Path     string
+	Handler  string
+	Tag      string
+	Summary  string
+	Query    []Param
+	Body     string
+	Response string
+	Shape    string
+	Auth     bool
+}
+
+// Param is a query parameter of an endpoint
+type Param struct {
+	Name        string
+	Description string
+	Type        string
+	Required    bool
+}
+
+var (
+	routerRE  = regexp.MustCompile(`^(/\S*)\s+\[(\w+)\]`)
+	paramRE   = regexp.MustCompile(`^(\S+)\s+(\w+)\s+([\S.]+)\s+(\w+)\s+"([^"]+)"`)
+	successRE = regexp.MustCompile(`^(\d+)(?:\s+\{(\w+)\}\s+(\S+))?`)
+	dataRE    = regexp.MustCompile(`^([\w.]+)\{data=(\S+)\}$`)
+	typeRE    = regexp.MustCompile(`^(\[\])*(?:(\w+)\.)?(\w+)$`)
+)
+
+var paramTypes = map[string]string{
+	"string":  "string",
+	"int":     "integer",
+	"integer": "integer",
+	"number":  "number",
+	"bool":    "boolean",
+	"boolean": "boolean",
+}
+
+// go types of the primitive types of swag
+var primitiveTypes = map[string]string{
+	"string":  "string",
+	"integer": "int64",
+	"number":  "float64",
+	"boolean": "bool",
+}
+
+// Parse returns the endpoints of the handlers in dir and the import paths of the packages their types are declared in
+func Parse(dir string) ([]Endpoint, map[string]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(files)
+
+	endpoints := []Endpoint{}
+	imports := map[string]string{}
+	fset := token.NewFileSet()
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing %v: %w", path, err)
+		}
+		fileImports := map[string]string{}
+		for _, spec := range file.Imports {
+			importPath, _ := strconv.Unquote(spec.Path.Value)
+			name := importPath[strings.LastIndex(importPath, "/")+1:]
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			fileImports[name] = importPath
+		}
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Doc == nil || fn.Recv != nil {
+				continue
+			}
+			handlerEndpoints, err := parseHandler(fn)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error in the annotations of %v at %v: %w", fn.Name.Name, fset.Position(fn.Pos()), err)
+			}
+			for _, endpoint := range handlerEndpoints {
+				for _, t := range []string{endpoint.Body, endpoint.Response} {
+					for _, match := range regexp.MustCompile(`(\w+)\.`).FindAllStringSubmatch(t, -1) {
+						if fileImports[match[1]] == "" {
+							return nil, nil, fmt.Errorf("error package %v of %v %v is not imported by %v", match[1], endpoint.Method, endpoint.Path, path)
+						}
+						imports[match[1]] = fileImports[match[1]]
+					}
+				}
+			}
+			endpoints = append(endpoints, handlerEndpoints...)
+		}
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Path != endpoints[j].Path {
+			return endpoints[i].Path < endpoints[j].Path
+		}
+		return endpoints[i].Method < endpoints[j].Method
+	})
+	for i := 1; i < len(endpoints); i++ {
+		if endpoints[i].Path == endpoints[i-1].Path && endpoints[i].Method == endpoints[i-1].Method {
+			return nil, nil, fmt.Errorf("error %v %v is documented by %v and %v", endpoints[i].Method, endpoints[i].Path, endpoints[i-1].Handler, endpoints[i].Handler)
+		}
+	}
+	return endpoints, imports, nil
+}
+
+// parseHandler returns an endpoint for every @Router annotation of fn
+func parseHandler(fn *ast.FuncDecl) ([]Endpoint, error) {
+	endpoint := Endpoint{Handler: fn.Name.Name, Shape: "apiShapeNone"}
+	routes := [][2]string{}
+	rows := false
+	for _, comment := range fn.Doc.List {
+		line := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+		attribute, value, _ := strings.Cut(line, " ")
+		value = strings.TrimSpace(value)
+
+		switch strings.ToLower(attribute) {
+		case "@router":
+			match := routerRE.FindStringSubmatch(value)
+			if match == nil {
+				return nil, fmt.Errorf("error invalid @Router %q", value)
+			}
+			routes = append(routes, [2]string{strings.ToUpper(match[2]), match[1]})
+		case "@summary":
+			endpoint.Summary = value
+		case "@tags":
+			endpoint.Tag = strings.TrimSpace(strings.Split(value, ",")[0])
+		case "@security":
+			endpoint.Auth = endpoint.Auth || strings.HasPrefix(value, "ApiKeyAuth")
+		case "@x-rows":
+			rows = value == "true"
+		case "@param":
+			match := paramRE.FindStringSubmatch(value)
+			if match == nil {
+				return nil, fmt.Errorf("error invalid @Param %q", value)
+			}
+			switch match[2] {
+			case "query":
+				paramType := paramTypes[match[3]]
+				if paramType == "" {
+					return nil, fmt.Errorf("error unsupported type of query parameter %v", match[1])
+				}
+				endpoint.Query = append(endpoint.Query, Param{Name: match[1], Description: match[5], Type: paramType, Required: match[4] == "true"})
+			case "body":
+				if endpoint.Body != "" {
+					return nil, fmt.Errorf("error more than one body parameter")
+				}
+				body, err := goType(match[3])
+				if err != nil {
+					return nil, err
+				}
+				endpoint.Body = body
+			}
+		case "@success":
+			match := successRE.FindStringSubmatch(value)
+			if match == nil {
+				return nil, fmt.Errorf("error invalid @Success %q", value)
+			}
+			if match[1] != "200" || match[2] == "" {
+				continue
+			}
+			err := endpoint.parseResponse(match[2], match[3])
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if rows {
+		if endpoint.Shape != "apiShapeData" {
+			return nil, fmt.Errorf("error @x-rows requires a types.ApiResponse{data=T} response")
+		}
+		endpoint.Shape = "apiShapeRows"
+	}
+
+	endpoints := make([]Endpoint, 0, len(routes))
+	for _, route := range routes {
+		e := endpoint
+		e.Method, e.Path = route[0], route[1]
+		if e.Tag == "" || e.Summary == "" {
+			return nil, fmt.Errorf("error %v %v has no @Tags or @Summary", e.Method, e.Path)
+		}
+		endpoints = append(endpoints, e)
+	}
+	return endpoints, nil
+}
+
+func (e *Endpoint) parseResponse(kind, t string) error {
+	var err error
+	switch kind {
+	case "file":
+		e.Shape = "apiShapePNG"
+		return nil
+	case "string":
+		return nil
+	case "array":
+		e.Shape = "apiShapeRaw"
+		e.Response, err = goType("[]" + t)
+		return err
+	case "object":
+	default:
+		return fmt.Errorf("error unsupported response kind {%v}", kind)
+	}
+
+	wrapper, data := t, ""
+	if match := dataRE.FindStringSubmatch(t); match != nil {
+		wrapper, data = match[1], match[2]
+	} else if strings.Contains(t, "{") {
+		return fmt.Errorf("error invalid response type %v", t)
+	}
+	switch {
+	case wrapper == "types.ApiResponse" && data == "":
+		e.Shape = "apiShapeNoData"
+		return nil
+	case wrapper == "types.ApiResponse":
+		e.Shape = "apiShapeData"
+	case wrapper == "types.ApiPaginatedResponse" && strings.HasPrefix(data, "[]"):
+		e.Shape = "apiShapePage"
+	case data != "":
+		return fmt.Errorf("error unsupported response wrapper %v", wrapper)
+	default:
+		e.Shape = "apiShapeRaw"
+		data = t
+	}
+	e.Response, err = goType(data)
+	return err
+}
+
+// goType turns a swag type into the go type it stands for
+func goType(t string) (string, error) {
+	match := typeRE.FindStringSubmatch(t)
+	if match == nil {
+		return "", fmt.Errorf("error unsupported type %v", t)
+	}
+	if match[2] == "" && primitiveTypes[match[3]] != "" {
+		return strings.TrimSuffix(t, match[3]) + primitiveTypes[match[3]], nil
+	}
+	return t, nil
+}
+
+// zeroValue returns an expression of the zero value of the go type t
+func zeroValue(t string) string {
+	switch t {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	case "int", "int64", "uint64", "float64":
+		return t + "(0)"
+	}
+	return t + "{}"
+}
+
+// Generate returns the source of the api endpoint registry of the handlers in dir
+func Generate(dir string) ([]byte, error) {
+	endpoints, imports, err := Parse(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by cmd/api-endpoints from the swag annotations of the handlers. DO NOT EDIT.\n\n")
+	b.WriteString("package handlers\n\n")
+	if len(imports) > 0 {
+		paths := make([]string, 0, len(imports))
+		for _, path := range imports {
+			paths = append(paths, strconv.Quote(path))
+		}
+		sort.Strings(paths)
+		fmt.Fprintf(&b, "import (\n%v\n)\n\n", strings.Join(paths, "\n"))
+	}
+
+	b.WriteString("var apiEndpoints = []apiEndpoint{\n")
+	for _, e := range endpoints {
+		fields := []string{
+			"Method: " + strconv.Quote(e.Method),
+			"Path: " + strconv.Quote(e.Path),
+			"Tag: " + strconv.Quote(e.Tag),
+			"Summary: " + strconv.Quote(e.Summary),
+		}
+		if len(e.Query) > 0 {
+			params := []string{}
+			for _, p := range e.Query {
+				param := fmt.Sprintf("{Name: %q, Description: %q, Type: %q", p.Name, p.Description, p.Type)
+				if p.Required {
+					param += ", Required: true"
+				}
+				params = append(params, param+"}")
+			}
+			fields = append(fields, "Query: []apiParam{"+strings.Join(params, ", ")+"}")
+		}
+		if e.Body != "" {
+			fields = append(fields, "Body: "+zeroValue(e.Body))
+		}
+		if e.Response != "" {
+			fields = append(fields, "Response: "+zeroValue(e.Response))
+		}
+		fields = append(fields, "Shape: "+e.Shape)
+		if e.Auth {
+			fields = append(fields, "Auth: true")
+		}
+		fmt.Fprintf(&b, "\t{%v},\n", strings.Join(fields, ", "))
+	}
+	b.WriteString("}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error formatting generated source: %w", err)
+	}
+	return src, nil
+}
+
+// Write generates the api endpoint registry of the handlers in dir and writes it to out
+func Write(dir, out string) error {
+	src, err := Generate(dir)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
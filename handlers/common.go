@@ -136,7 +136,13 @@ func GetValidatorEarnings(validators []uint64, currency string) (*types.Validato
 	clElPrice := price.GetPrice(utils.Config.Frontend.ClCurrency, utils.Config.Frontend.ElCurrency)
 
 	if totalDeposits == 0 {
-		totalDeposits = utils.Config.Chain.ClConfig.MaxEffectiveBalance * uint64(len(validators))
+		// compounding validators can hold more than the activation balance, so their actual effective balance is used
+		for _, v := range balancesMap {
+			totalDeposits += v.EffectiveBalance
+		}
+	}
+	if totalDeposits == 0 {
+		totalDeposits = utils.Config.Chain.ClConfig.MinActivationBalance * uint64(len(validators))
 	}
 
 	clApr7d := income.ClIncomeWei7d.DivRound(decimal.NewFromInt(1e9), 18).DivRound(decimal.NewFromInt(int64(totalDeposits)), 18).Mul(decimal.NewFromInt(365)).Div(decimal.NewFromInt(7)).InexactFloat64()
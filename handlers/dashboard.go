@@ -336,7 +336,7 @@ func getNextWithdrawalRow(queryValidators []uint64, currency string) ([][]interf
 			FROM validators
 			WHERE
 				activationepoch <= $1 AND exitepoch > $1 AND
-				withdrawalcredentials LIKE '\x01' || '%'::bytea AND
+				(withdrawalcredentials LIKE '\x01' || '%'::bytea OR withdrawalcredentials LIKE '\x02' || '%'::bytea) AND
 				validatorindex = ANY($2)
 			ORDER BY validatorindex ASC`, epoch, pq.Array(queryValidators))
 
@@ -372,7 +372,7 @@ func getNextWithdrawalRow(queryValidators []uint64, currency string) ([][]interf
 		}
 
 		if (balance[0].Balance > 0 && v.WithdrawableEpoch <= epoch) ||
-			(balance[0].EffectiveBalance == utils.Config.Chain.ClConfig.MaxEffectiveBalance && balance[0].Balance > utils.Config.Chain.ClConfig.MaxEffectiveBalance) {
+			utils.IsPartiallyWithdrawable(v.WithdrawalCredentials, balance[0].EffectiveBalance, balance[0].Balance) {
 			// this validator is eligible for withdrawal, check if it is the next one
 			if nextValidator == nil || v.Index > *stats.LatestValidatorWithdrawalIndex {
 				nextValidator = v
@@ -426,10 +426,10 @@ func getNextWithdrawalRow(queryValidators []uint64, currency string) ([][]interf
 		withdrawalAmount = nextValidator.Balance
 	} else {
 		// partial withdrawal
-		withdrawalAmount = nextValidator.Balance - utils.Config.Chain.ClConfig.MaxEffectiveBalance
+		withdrawalAmount = nextValidator.Balance - utils.MaxEffectiveBalanceForCredentials(nextValidator.WithdrawalCredentials)
 	}
 
-	if lastWithdrawnEpoch == epoch || nextValidator.Balance < utils.Config.Chain.ClConfig.MaxEffectiveBalance {
+	if lastWithdrawnEpoch == epoch || nextValidator.Balance < utils.MaxEffectiveBalanceForCredentials(nextValidator.WithdrawalCredentials) {
 		withdrawalAmount = 0
 	}
 
@@ -446,7 +446,15 @@ func getNextWithdrawalRow(queryValidators []uint64, currency string) ([][]interf
 	return nextData, nil
 }
 
-// Dashboard Chart that combines balance data and
+// DashboardDataBalanceCombined godoc
+// @Summary Get the consensus and execution income chart of a set of validators
+// @Tags Dashboard
+// @Produce json
+// @Param validators query string true "Comma separated list of validator indices or pubkeys"
+// @Param days query int false "Number of days"
+// @Success 200 {object} dashboardBalanceCombinedResponse
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/dashboard/data/allbalances [get]
 func DashboardDataBalanceCombined(w http.ResponseWriter, r *http.Request) {
 	var lowerBoundDay uint64
 	param := r.URL.Query().Get("days")
@@ -504,10 +512,7 @@ func DashboardDataBalanceCombined(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var response struct {
-		ConsensusChartData []*types.ChartDataPoint `json:"consensusChartData"`
-		ExecutionChartData []*types.ChartDataPoint `json:"executionChartData"`
-	}
+	var response dashboardBalanceCombinedResponse
 	response.ConsensusChartData = incomeHistoryChartData
 	response.ExecutionChartData = executionChartData
 
@@ -519,7 +524,19 @@ func DashboardDataBalanceCombined(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+type dashboardBalanceCombinedResponse struct {
+	ConsensusChartData []*types.ChartDataPoint `json:"consensusChartData"`
+	ExecutionChartData []*types.ChartDataPoint `json:"executionChartData"`
+}
+
 // DashboardDataBalance retrieves the income history of a set of validators
+// @Summary Get the income chart of a set of validators
+// @Tags Dashboard
+// @Produce json
+// @Param validators query string true "Comma separated list of validator indices or pubkeys"
+// @Success 200 {array} types.ChartDataPoint
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/dashboard/data/balances [get]
 func DashboardDataBalance(w http.ResponseWriter, r *http.Request) {
 	currency := GetCurrency(r)
 	errFieldMap := map[string]interface{}{"route": r.URL.String()}
@@ -554,6 +571,14 @@ func DashboardDataBalance(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// DashboardDataProposals godoc
+// @Summary Get the proposals of a set of validators as [timestamp, status]
+// @Tags Dashboard
+// @Produce json
+// @Param validators query string true "Comma separated list of validator indices or pubkeys"
+// @Success 200 {array} []integer
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/dashboard/data/proposals [get]
 func DashboardDataProposals(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
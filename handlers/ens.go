@@ -24,7 +24,7 @@ import (
 // @Description Returns and object with the ens name and address - if found.
 // @Produce  json
 // @Param domain path string true "domain can either be an ens name or an etherum address"
-// @Success 200 {object} types.ApiResponse
+// @Success 200 {object} types.ApiResponse{data=types.EnsDomainResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/ens/lookup/{domain} [get]
 func ResolveEnsDomain(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,457 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+const (
+	graphqlMaxRequestSize = 1 << 20
+	graphqlMaxDepth       = 12
+	// graphqlMaxComplexity is the maximum estimated number of objects a query may resolve
+	graphqlMaxComplexity = 100000
+	// graphqlComplexityPerWeight is the complexity of a query that costs as much as a regular api request
+	graphqlComplexityPerWeight = 1000
+	graphqlDefaultListLimit    = 10
+	graphqlMaxListLimit        = 100
+	// graphqlRelaysPerBlock is the estimated number of relays that delivered a block
+	graphqlRelaysPerBlock = 5
+	// graphqlTransactionsPerBlock is the estimated number of transactions of a block
+	graphqlTransactionsPerBlock = 300
+	// graphqlRelays is the estimated number of known relays
+	graphqlRelays = 20
+)
+
+// graphqlListSizes estimate the number of items of list fields from their arguments, the complexity of the selection of a list field is multiplied with its size
+var graphqlListSizes = map[string]func(args map[string]interface{}) int{
+	"Query.validators": func(args map[string]interface{}) int {
+		size := 0
+		for _, arg := range []string{"indices", "pubkeys"} {
+			if list, ok := args[arg].([]interface{}); ok {
+				size += len(list)
+			}
+		}
+		return size
+	},
+	"Query.relays":          func(args map[string]interface{}) int { return graphqlRelays },
+	"Validator.proposals":   graphqlLimit,
+	"Validator.withdrawals": graphqlLimit,
+	"Epoch.slots":           func(args map[string]interface{}) int { return int(utils.Config.Chain.ClConfig.SlotsPerEpoch) },
+	"Slot.withdrawals": func(args map[string]interface{}) int {
+		return int(utils.Config.Chain.ClConfig.MaxWithdrawalsPerPayload)
+	},
+	"ExecutionBlock.relays":       func(args map[string]interface{}) int { return graphqlRelaysPerBlock },
+	"ExecutionBlock.transactions": func(args map[string]interface{}) int { return graphqlTransactionsPerBlock },
+}
+
+var graphqlSchemaOnce sync.Once
+var graphqlSchema graphql.Schema
+var graphqlSchemaErr error
+
+func getGraphqlSchema() (*graphql.Schema, error) {
+	graphqlSchemaOnce.Do(func() {
+		graphqlSchema, graphqlSchemaErr = newGraphqlSchema()
+	})
+	return &graphqlSchema, graphqlSchemaErr
+}
+
+type graphqlRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// readGraphqlRequest reads the graphql request from the body of r, the body is restored so that it can be read again
+func readGraphqlRequest(r *http.Request) (*graphqlRequest, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, graphqlMaxRequestSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading request body: %w", err)
+	}
+	if len(body) > graphqlMaxRequestSize {
+		return nil, fmt.Errorf("request body exceeds the maximum size of %v bytes", graphqlMaxRequestSize)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	req := &graphqlRequest{}
+	err = json.Unmarshal(body, req)
+	if err != nil {
+		return nil, fmt.Errorf("invalid graphql request: %w", err)
+	}
+	if req.Query == "" {
+		return nil, fmt.Errorf("missing query")
+	}
+	return req, nil
+}
+
+func parseGraphqlQuery(query string) (*ast.Document, error) {
+	return parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(query), Name: "GraphQL request"})})
+}
+
+// ApiGraphQL godoc
+// @Summary Query consensus and execution layer data with graphql
+// @Tags GraphQL
+// @Description Executes a graphql query over validators, slots, epochs, execution blocks, transactions, withdrawals and relays.
+// @Description The estimated complexity of a query is limited and the request is charged one call per started 1000 objects of complexity.
+// @Accept json
+// @Produce json
+// @Param request body graphqlRequest true "Query, variables and operation name"
+// @Success 200 {object} graphql.Result
+// @Failure 400 {object} graphql.Result
+// @Router /api/v1/graphql [post]
+func ApiGraphQL(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	schema, err := getGraphqlSchema()
+	if err != nil {
+		logger.Errorf("error creating graphql schema: %v", err)
+		sendServerErrorResponse(w, r.URL.String(), "graphql is not available")
+		return
+	}
+
+	req, err := readGraphqlRequest(r)
+	if err != nil {
+		SendBadRequestResponse(w, r.URL.String(), err.Error())
+		return
+	}
+
+	doc, err := parseGraphqlQuery(req.Query)
+	if err != nil {
+		sendGraphqlErrors(w, r, gqlerrors.FormatErrors(err))
+		return
+	}
+	validation := graphql.ValidateDocument(schema, doc, nil)
+	if !validation.IsValid {
+		sendGraphqlErrors(w, r, validation.Errors)
+		return
+	}
+	complexity, err := graphqlComplexity(schema, doc, req.OperationName, req.Variables)
+	if err != nil {
+		sendGraphqlErrors(w, r, gqlerrors.FormatErrors(err))
+		return
+	}
+	if complexity > graphqlMaxComplexity {
+		sendGraphqlErrors(w, r, gqlerrors.FormatErrors(fmt.Errorf("query complexity of %v exceeds the maximum of %v", complexity, graphqlMaxComplexity)))
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), graphqlContextKey{}, newGraphqlLoaders(getUserPremium(r).MaxValidators))
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        *schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		logger.Errorf("error serializing json data for API %v route: %v", r.URL, err)
+	}
+}
+
+func sendGraphqlErrors(w http.ResponseWriter, r *http.Request, errs []gqlerrors.FormattedError) {
+	w.WriteHeader(http.StatusBadRequest)
+	err := json.NewEncoder(w).Encode(&graphql.Result{Errors: errs})
+	if err != nil {
+		logger.Errorf("error serializing json data for API %v route: %v", r.URL, err)
+	}
+}
+
+// ApiGraphQLWeight returns the factor the ratelimit weight of a graphql request is multiplied with, it is derived from the complexity of the query
+func ApiGraphQLWeight(r *http.Request) (int64, error) {
+	schema, err := getGraphqlSchema()
+	if err != nil {
+		return 0, err
+	}
+	req, err := readGraphqlRequest(r)
+	if err != nil {
+		return 0, err
+	}
+	doc, err := parseGraphqlQuery(req.Query)
+	if err != nil {
+		return 0, err
+	}
+	complexity, err := graphqlComplexity(schema, doc, req.OperationName, req.Variables)
+	if err != nil {
+		return 0, err
+	}
+	if complexity > graphqlMaxComplexity {
+		// the query is rejected
+		return 1, nil
+	}
+	return int64((complexity + graphqlComplexityPerWeight - 1) / graphqlComplexityPerWeight), nil
+}
+
+// graphqlComplexity estimates the number of objects that are resolved by the operation of a query, the selection of list fields is multiplied with their estimated size
+func graphqlComplexity(schema *graphql.Schema, doc *ast.Document, operationName string, variables map[string]interface{}) (int, error) {
+	c := &graphqlComplexityCalculator{
+		schema:    schema,
+		variables: variables,
+		fragments: map[string]*ast.FragmentDefinition{},
+	}
+
+	var operation *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		switch definition := definition.(type) {
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				if operation != nil {
+					return 0, fmt.Errorf("the operation name is required for queries with multiple operations")
+				}
+				operation = definition
+			}
+		case *ast.FragmentDefinition:
+			c.fragments[definition.Name.Value] = definition
+		}
+	}
+	if operation == nil {
+		return 0, fmt.Errorf("unknown operation %v", operationName)
+	}
+	if operation.Operation != ast.OperationTypeQuery {
+		return 0, fmt.Errorf("only queries are supported")
+	}
+	return c.selectionSet(schema.QueryType(), operation.SelectionSet, 0)
+}
+
+type graphqlComplexityCalculator struct {
+	schema    *graphql.Schema
+	variables map[string]interface{}
+	fragments map[string]*ast.FragmentDefinition
+}
+
+func (c *graphqlComplexityCalculator) selectionSet(parent *graphql.Object, selectionSet *ast.SelectionSet, depth int) (int, error) {
+	if selectionSet == nil {
+		return 0, nil
+	}
+	if depth > graphqlMaxDepth {
+		return 0, fmt.Errorf("query exceeds the maximum depth of %v", graphqlMaxDepth)
+	}
+
+	complexity := 0
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			field, ok := parent.Fields()[selection.Name.Value]
+			if !ok {
+				// introspection fields, unknown fields are rejected by the validation
+				continue
+			}
+			object, ok := graphql.GetNamed(field.Type).(*graphql.Object)
+			if !ok {
+				// scalars do not add to the complexity of their object
+				continue
+			}
+			children, err := c.selectionSet(object, selection.SelectionSet, depth+1)
+			if err != nil {
+				return 0, err
+			}
+			size := 1
+			if listSize, ok := graphqlListSizes[parent.Name()+"."+field.Name]; ok {
+				size = listSize(c.arguments(field, selection))
+			}
+			complexity += size * (1 + children)
+		case *ast.InlineFragment:
+			children, err := c.selectionSet(c.typeCondition(parent, selection.TypeCondition), selection.SelectionSet, depth+1)
+			if err != nil {
+				return 0, err
+			}
+			complexity += children
+		case *ast.FragmentSpread:
+			fragment, ok := c.fragments[selection.Name.Value]
+			if !ok {
+				continue
+			}
+			children, err := c.selectionSet(c.typeCondition(parent, fragment.TypeCondition), fragment.SelectionSet, depth+1)
+			if err != nil {
+				return 0, err
+			}
+			complexity += children
+		}
+
+		if complexity > graphqlMaxComplexity {
+			// stop early, so that the complexity of deeply nested lists can not overflow
+			return graphqlMaxComplexity + 1, nil
+		}
+	}
+	return complexity, nil
+}
+
+func (c *graphqlComplexityCalculator) typeCondition(parent *graphql.Object, condition *ast.Named) *graphql.Object {
+	if condition == nil {
+		return parent
+	}
+	if object, ok := c.schema.Type(condition.Name.Value).(*graphql.Object); ok {
+		return object
+	}
+	return parent
+}
+
+// arguments returns the arguments of a field with their default values
+func (c *graphqlComplexityCalculator) arguments(field *graphql.FieldDefinition, selection *ast.Field) map[string]interface{} {
+	args := map[string]interface{}{}
+	for _, arg := range field.Args {
+		if arg.DefaultValue != nil {
+			args[arg.Name()] = arg.DefaultValue
+		}
+	}
+	for _, arg := range selection.Arguments {
+		if value := c.value(arg.Value); value != nil {
+			args[arg.Name.Value] = value
+		}
+	}
+	return args
+}
+
+func (c *graphqlComplexityCalculator) value(value ast.Value) interface{} {
+	switch value := value.(type) {
+	case *ast.Variable:
+		return c.variables[value.Name.Value]
+	case *ast.IntValue:
+		n, err := strconv.Atoi(value.Value)
+		if err != nil {
+			return nil
+		}
+		return n
+	case *ast.StringValue:
+		return value.Value
+	case *ast.ListValue:
+		values := make([]interface{}, 0, len(value.Values))
+		for _, v := range value.Values {
+			values = append(values, c.value(v))
+		}
+		return values
+	}
+	return nil
+}
+
+type graphqlContextKey struct{}
+
+// graphqlLoaders are the loaders of a single graphql request
+type graphqlLoaders struct {
+	maxValidators        int
+	validators           *graphqlLoader[uint64, *graphqlValidator]
+	balances             *graphqlLoader[uint64, *types.ValidatorBalance]
+	proposals            *graphqlLoader[graphqlRangeKey, []*types.APIBlockResponse]
+	validatorWithdrawals *graphqlLoader[graphqlRangeKey, []*graphqlWithdrawal]
+	slots                *graphqlLoader[uint64, *types.APIBlockResponse]
+	epochSlots           *graphqlLoader[uint64, []*types.APIBlockResponse]
+	slotWithdrawals      *graphqlLoader[string, []*graphqlWithdrawal]
+	executionBlocks      *graphqlLoader[uint64, *types.ExecutionBlockApiResponse]
+	transactions         *graphqlLoader[uint64, []*graphqlTransaction]
+	relays               *graphqlLoader[string, []*graphqlRelay]
+}
+
+func newGraphqlLoaders(maxValidators int) *graphqlLoaders {
+	return &graphqlLoaders{
+		maxValidators:        maxValidators,
+		validators:           newGraphqlLoader("validators", fetchGraphqlValidators),
+		balances:             newGraphqlLoader("validator balances", fetchGraphqlBalances),
+		proposals:            newGraphqlLoader("proposals", fetchGraphqlProposals),
+		validatorWithdrawals: newGraphqlLoader("withdrawals", fetchGraphqlValidatorWithdrawals),
+		slots:                newGraphqlLoader("slots", fetchGraphqlSlots),
+		epochSlots:           newGraphqlLoader("slots", fetchGraphqlEpochSlots),
+		slotWithdrawals:      newGraphqlLoader("withdrawals", fetchGraphqlSlotWithdrawals),
+		executionBlocks:      newGraphqlLoader("execution blocks", fetchGraphqlExecutionBlocks),
+		transactions:         newGraphqlLoader("transactions", fetchGraphqlTransactions),
+		relays:               newGraphqlLoader("relays", fetchGraphqlRelays),
+	}
+}
+
+func graphqlLoadersFrom(p graphql.ResolveParams) *graphqlLoaders {
+	return p.Context.Value(graphqlContextKey{}).(*graphqlLoaders)
+}
+
+// graphqlLoadError logs err and returns the error that is reported to the client
+func graphqlLoadError(name string, err error) error {
+	logger.Errorf("error retrieving %v for graphql query: %v", name, err)
+	return fmt.Errorf("could not retrieve %v", name)
+}
+
+// graphqlLoader batches the keys that are requested while a level of a query is resolved, they are fetched at once when the first of their values is needed
+type graphqlLoader[K comparable, V any] struct {
+	name    string
+	fetch   func(keys []K) (map[K]V, error)
+	mu      sync.Mutex
+	pending map[K]bool
+	fetched map[K]bool
+	values  map[K]V
+	errs    map[K]error
+}
+
+func newGraphqlLoader[K comparable, V any](name string, fetch func(keys []K) (map[K]V, error)) *graphqlLoader[K, V] {
+	return &graphqlLoader[K, V]{
+		name:    name,
+		fetch:   fetch,
+		pending: map[K]bool{},
+		fetched: map[K]bool{},
+		values:  map[K]V{},
+		errs:    map[K]error{},
+	}
+}
+
+// load queues key and returns a thunk that resolves to its value, or to null if there is no value
+func (l *graphqlLoader[K, V]) load(key K) func() (interface{}, error) {
+	return l.loadField(key, func(value V) interface{} { return value })
+}
+
+// loadField queues key and returns a thunk that resolves to a field of its value, or to null if there is no value
+func (l *graphqlLoader[K, V]) loadField(key K, field func(value V) interface{}) func() (interface{}, error) {
+	l.mu.Lock()
+	if !l.fetched[key] {
+		l.pending[key] = true
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		value, ok, err := l.get(key)
+		if err != nil || !ok {
+			return nil, err
+		}
+		return field(value), nil
+	}
+}
+
+// get returns the value of key, the pending keys are fetched first
+func (l *graphqlLoader[K, V]) get(key K) (V, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.pending) > 0 {
+		keys := make([]K, 0, len(l.pending))
+		for k := range l.pending {
+			keys = append(keys, k)
+			l.fetched[k] = true
+		}
+		l.pending = map[K]bool{}
+
+		values, err := l.fetch(keys)
+		if err != nil {
+			err = graphqlLoadError(l.name, err)
+		}
+		for _, k := range keys {
+			if err != nil {
+				l.errs[k] = err
+			} else if value, ok := values[k]; ok {
+				l.values[k] = value
+			}
+		}
+	}
+
+	value, ok := l.values[key]
+	return value, ok, l.errs[key]
+}
@@ -0,0 +1,960 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db"
+	"github.com/gobitfly/eth2-beaconchain-explorer/services"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/lib/pq"
+)
+
+// graphqlLong is an unsigned 64 bit integer, the builtin Int of graphql is limited to 32 bits which is too small for balances in gwei and far future epochs
+var graphqlLong = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Long",
+	Description: "Unsigned 64 bit integer, values above 2^53 can not be represented exactly by some json parsers",
+	Serialize: func(value interface{}) interface{} {
+		v := reflect.Indirect(reflect.ValueOf(value))
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return v.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return v.Uint()
+		}
+		return nil
+	},
+	ParseValue: parseGraphqlLong,
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		switch v := valueAST.(type) {
+		case *ast.IntValue:
+			return parseGraphqlLong(v.Value)
+		case *ast.StringValue:
+			return parseGraphqlLong(v.Value)
+		}
+		return nil
+	},
+})
+
+func parseGraphqlLong(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil
+		}
+		return n
+	case json.Number:
+		return parseGraphqlLong(v.String())
+	case float64:
+		if v < 0 || v != math.Trunc(v) || v >= math.MaxUint64 {
+			return nil
+		}
+		return uint64(v)
+	case int:
+		if v < 0 {
+			return nil
+		}
+		return uint64(v)
+	case int64:
+		if v < 0 {
+			return nil
+		}
+		return uint64(v)
+	case uint64:
+		return v
+	}
+	return nil
+}
+
+// graphqlBigInt is an arbitrary precision integer, it is serialized as a decimal string
+var graphqlBigInt = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "BigInt",
+	Description: "Arbitrary precision integer as a decimal string, e.g. an amount in wei",
+	Serialize: func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case *big.Int:
+			return v.String()
+		case []byte:
+			return new(big.Int).SetBytes(v).String()
+		}
+		return nil
+	},
+	ParseValue: func(value interface{}) interface{} {
+		s, ok := value.(string)
+		if !ok {
+			return nil
+		}
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return nil
+		}
+		return n
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		v, ok := valueAST.(*ast.StringValue)
+		if !ok {
+			return nil
+		}
+		n, ok := new(big.Int).SetString(v.Value, 10)
+		if !ok {
+			return nil
+		}
+		return n
+	},
+})
+
+// graphqlBytes is a byte string like a hash, a pubkey or an address, it is serialized as 0x prefixed hex
+var graphqlBytes = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Bytes",
+	Description: "0x prefixed hex encoded byte string",
+	Serialize: func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case []byte:
+			return fmt.Sprintf("0x%x", v)
+		case types.HexBytes:
+			return fmt.Sprintf("0x%x", []byte(v))
+		case string:
+			return v
+		}
+		return nil
+	},
+	ParseValue: parseGraphqlBytes,
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		v, ok := valueAST.(*ast.StringValue)
+		if !ok {
+			return nil
+		}
+		return parseGraphqlBytes(v.Value)
+	},
+})
+
+func parseGraphqlBytes(value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		return nil
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// graphqlOptionalBytes returns nil for empty byte strings, so that they are resolved to null instead of 0x
+func graphqlOptionalBytes(b []byte) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+	return b
+}
+
+// graphqlField returns a field that is resolved by get from a source of type T
+func graphqlField[T any](typ graphql.Output, description string, get func(source T) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type:        typ,
+		Description: description,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			source, ok := p.Source.(T)
+			if !ok {
+				return nil, fmt.Errorf("unexpected source %T of field %v", p.Source, p.Info.FieldName)
+			}
+			return get(source), nil
+		},
+	}
+}
+
+// graphqlRangeArgs are the arguments of the list fields of a validator
+var graphqlRangeArgs = graphql.FieldConfigArgument{
+	"fromEpoch": &graphql.ArgumentConfig{Type: graphqlLong, Description: "First epoch of the range, inclusive"},
+	"toEpoch":   &graphql.ArgumentConfig{Type: graphqlLong, Description: "Last epoch of the range, inclusive"},
+	"limit":     &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: graphqlDefaultListLimit, Description: fmt.Sprintf("Maximum number of items per validator, at most %v", graphqlMaxListLimit)},
+}
+
+// graphqlRangeKey identifies the items of a validator in an epoch range
+type graphqlRangeKey struct {
+	Validator uint64
+	FromEpoch uint64
+	ToEpoch   uint64
+	Limit     int
+}
+
+func newGraphqlRangeKey(validator uint64, args map[string]interface{}) graphqlRangeKey {
+	key := graphqlRangeKey{Validator: validator, ToEpoch: math.MaxInt64, Limit: graphqlLimit(args)}
+	if from, ok := args["fromEpoch"].(uint64); ok {
+		key.FromEpoch = from
+	}
+	if to, ok := args["toEpoch"].(uint64); ok && to < math.MaxInt64 {
+		key.ToEpoch = to
+	}
+	return key
+}
+
+// graphqlLimit returns the limit argument of a list field
+func graphqlLimit(args map[string]interface{}) int {
+	limit, ok := graphqlInt(args["limit"])
+	if !ok {
+		return graphqlDefaultListLimit
+	}
+	if limit < 0 {
+		return 0
+	}
+	if limit > graphqlMaxListLimit {
+		return graphqlMaxListLimit
+	}
+	return limit
+}
+
+func graphqlInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	case uint64:
+		if v > math.MaxInt32 {
+			return math.MaxInt32, true
+		}
+		return int(v), true
+	}
+	return 0, false
+}
+
+// graphqlValidator is a row of the validators table
+type graphqlValidator struct {
+	Index                      uint64 `db:"validatorindex"`
+	Pubkey                     []byte `db:"pubkey"`
+	WithdrawableEpoch          uint64 `db:"withdrawableepoch"`
+	WithdrawalCredentials      []byte `db:"withdrawalcredentials"`
+	Slashed                    bool   `db:"slashed"`
+	ActivationEligibilityEpoch uint64 `db:"activationeligibilityepoch"`
+	ActivationEpoch            uint64 `db:"activationepoch"`
+	ExitEpoch                  uint64 `db:"exitepoch"`
+	Status                     string `db:"status"`
+}
+
+// graphqlTransaction is an execution layer transaction, fields that are not indexed are nil for transactions that are looked up by their hash
+type graphqlTransaction struct {
+	Hash         []byte
+	BlockNumber  uint64
+	From         []byte
+	To           []byte
+	Value        []byte
+	Nonce        *uint64
+	GasPrice     []byte
+	GasUsed      *uint64
+	Fee          *big.Int
+	Success      bool
+	ErrorMessage string
+}
+
+// graphqlRelay is a mev relay, the fields of the delivered payload are only set if the relay is resolved for an execution block
+type graphqlRelay struct {
+	TagID                string          `db:"tag_id"`
+	PublicLink           *string         `db:"public_link"`
+	IsCensoring          *bool           `db:"is_censoring"`
+	IsEthical            *bool           `db:"is_ethical"`
+	ExecBlockHash        []byte          `db:"exec_block_hash"`
+	BuilderPubkey        []byte          `db:"builder_pubkey"`
+	ProposerFeeRecipient []byte          `db:"proposer_fee_recipient"`
+	Value                types.WeiString `db:"value"`
+}
+
+// graphqlWithdrawal is a withdrawal of the blocks_withdrawals table
+type graphqlWithdrawal struct {
+	types.APISlotWithdrawalResponse
+	BlockRoot []byte `db:"block_root"`
+}
+
+// newGraphqlSchema returns the schema of the graphql api
+func newGraphqlSchema() (graphql.Schema, error) {
+	var validatorType, slotType, epochType, executionBlockType, transactionType, withdrawalType, relayType *graphql.Object
+
+	validatorType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Validator",
+		Description: "A consensus layer validator",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"index":                      graphqlField(graphql.NewNonNull(graphqlLong), "Index of the validator", func(v *graphqlValidator) interface{} { return v.Index }),
+				"pubkey":                     graphqlField(graphql.NewNonNull(graphqlBytes), "Public key of the validator", func(v *graphqlValidator) interface{} { return v.Pubkey }),
+				"withdrawalCredentials":      graphqlField(graphql.NewNonNull(graphqlBytes), "Withdrawal credentials of the validator", func(v *graphqlValidator) interface{} { return v.WithdrawalCredentials }),
+				"withdrawalCredentialsType":  graphqlField(graphql.NewNonNull(graphql.String), "Type of the withdrawal credentials, one of bls, execution, compounding or unknown", func(v *graphqlValidator) interface{} { return utils.WithdrawalCredentialsType(v.WithdrawalCredentials) }),
+				"slashed":                    graphqlField(graphql.NewNonNull(graphql.Boolean), "Whether the validator has been slashed", func(v *graphqlValidator) interface{} { return v.Slashed }),
+				"status":                     graphqlField(graphql.NewNonNull(graphql.String), "Status of the validator", func(v *graphqlValidator) interface{} { return v.Status }),
+				"activationEligibilityEpoch": graphqlField(graphql.NewNonNull(graphqlLong), "Epoch the validator became eligible for activation", func(v *graphqlValidator) interface{} { return v.ActivationEligibilityEpoch }),
+				"activationEpoch":            graphqlField(graphql.NewNonNull(graphqlLong), "Epoch the validator has been activated", func(v *graphqlValidator) interface{} { return v.ActivationEpoch }),
+				"exitEpoch":                  graphqlField(graphql.NewNonNull(graphqlLong), "Epoch the validator exits", func(v *graphqlValidator) interface{} { return v.ExitEpoch }),
+				"withdrawableEpoch":          graphqlField(graphql.NewNonNull(graphqlLong), "Epoch the balance of the validator becomes withdrawable", func(v *graphqlValidator) interface{} { return v.WithdrawableEpoch }),
+				"balance": {
+					Type:        graphqlLong,
+					Description: "Balance of the validator in the latest epoch in gwei",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return graphqlLoadersFrom(p).balances.loadField(p.Source.(*graphqlValidator).Index, func(b *types.ValidatorBalance) interface{} { return b.Balance }), nil
+					},
+				},
+				"effectiveBalance": {
+					Type:        graphqlLong,
+					Description: "Effective balance of the validator in the latest epoch in gwei",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return graphqlLoadersFrom(p).balances.loadField(p.Source.(*graphqlValidator).Index, func(b *types.ValidatorBalance) interface{} { return b.EffectiveBalance }), nil
+					},
+				},
+				"proposals": {
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(slotType))),
+					Description: "Slots the validator has been scheduled to propose a block in, ordered by slot descending",
+					Args:        graphqlRangeArgs,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return graphqlLoadersFrom(p).proposals.load(newGraphqlRangeKey(p.Source.(*graphqlValidator).Index, p.Args)), nil
+					},
+				},
+				"withdrawals": {
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(withdrawalType))),
+					Description: "Withdrawals of the validator, ordered by slot descending",
+					Args:        graphqlRangeArgs,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return graphqlLoadersFrom(p).validatorWithdrawals.load(newGraphqlRangeKey(p.Source.(*graphqlValidator).Index, p.Args)), nil
+					},
+				},
+			}
+		}),
+	})
+
+	slotType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Slot",
+		Description: "A consensus layer slot and its block",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"slot":                       graphqlField(graphql.NewNonNull(graphqlLong), "Number of the slot", func(b *types.APIBlockResponse) interface{} { return b.Slot }),
+				"epoch":                      graphqlField(graphql.NewNonNull(graphqlLong), "Epoch of the slot", func(b *types.APIBlockResponse) interface{} { return b.Epoch }),
+				"status":                     graphqlField(graphql.NewNonNull(graphql.String), "Status of the block, 0 = scheduled, 1 = proposed, 2 = missed, 3 = orphaned", func(b *types.APIBlockResponse) interface{} { return b.Status }),
+				"blockRoot":                  graphqlField(graphql.NewNonNull(graphqlBytes), "Root of the block", func(b *types.APIBlockResponse) interface{} { return b.Blockroot }),
+				"parentRoot":                 graphqlField(graphql.NewNonNull(graphqlBytes), "Root of the parent block", func(b *types.APIBlockResponse) interface{} { return b.Parentroot }),
+				"stateRoot":                  graphqlField(graphql.NewNonNull(graphqlBytes), "State root of the block", func(b *types.APIBlockResponse) interface{} { return b.Stateroot }),
+				"proposerIndex":              graphqlField(graphql.NewNonNull(graphqlLong), "Index of the proposer", func(b *types.APIBlockResponse) interface{} { return b.Proposer }),
+				"graffiti":                   graphqlField(graphql.NewNonNull(graphqlBytes), "Graffiti of the block", func(b *types.APIBlockResponse) interface{} { return b.Graffiti }),
+				"graffitiText":               graphqlField(graphql.String, "Graffiti of the block as text", func(b *types.APIBlockResponse) interface{} { return b.GraffitiText }),
+				"attestationsCount":          graphqlField(graphql.NewNonNull(graphqlLong), "Number of attestations in the block", func(b *types.APIBlockResponse) interface{} { return b.Attestationscount }),
+				"depositsCount":              graphqlField(graphql.NewNonNull(graphqlLong), "Number of deposits in the block", func(b *types.APIBlockResponse) interface{} { return b.Depositscount }),
+				"voluntaryExitsCount":        graphqlField(graphql.NewNonNull(graphqlLong), "Number of voluntary exits in the block", func(b *types.APIBlockResponse) interface{} { return b.Voluntaryexitscount }),
+				"proposerSlashingsCount":     graphqlField(graphql.NewNonNull(graphqlLong), "Number of proposer slashings in the block", func(b *types.APIBlockResponse) interface{} { return b.Proposerslashingscount }),
+				"attesterSlashingsCount":     graphqlField(graphql.NewNonNull(graphqlLong), "Number of attester slashings in the block", func(b *types.APIBlockResponse) interface{} { return b.Attesterslashingscount }),
+				"withdrawalCount":            graphqlField(graphql.NewNonNull(graphqlLong), "Number of withdrawals in the block", func(b *types.APIBlockResponse) interface{} { return b.WithdrawalCount }),
+				"syncAggregateParticipation": graphqlField(graphql.NewNonNull(graphql.Float), "Participation rate of the sync committee", func(b *types.APIBlockResponse) interface{} { return b.SyncaggregateParticipation }),
+				"executionBlockNumber":       graphqlField(graphqlLong, "Number of the execution payload", func(b *types.APIBlockResponse) interface{} { return b.ExecBlockNumber }),
+				"executionBlockHash":         graphqlField(graphqlBytes, "Hash of the execution payload", func(b *types.APIBlockResponse) interface{} { return graphqlOptionalBytes(b.ExecBlockHash) }),
+				"feeRecipient":               graphqlField(graphqlBytes, "Fee recipient of the execution payload", func(b *types.APIBlockResponse) interface{} { return graphqlOptionalBytes(b.ExecFeeRecipient) }),
+				"proposer": {
+					Type:        validatorType,
+					Description: "Proposer of the slot",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return graphqlLoadersFrom(p).validators.load(p.Source.(*types.APIBlockResponse).Proposer), nil
+					},
+				},
+				"executionBlock": {
+					Type:        executionBlockType,
+					Description: "Execution payload of the block",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						block := p.Source.(*types.APIBlockResponse)
+						if block.ExecBlockNumber == nil || *block.ExecBlockNumber == 0 || block.Status != "1" {
+							return nil, nil
+						}
+						return graphqlLoadersFrom(p).executionBlocks.load(*block.ExecBlockNumber), nil
+					},
+				},
+				"withdrawals": {
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(withdrawalType))),
+					Description: "Withdrawals of the block",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return graphqlLoadersFrom(p).slotWithdrawals.load(string(p.Source.(*types.APIBlockResponse).Blockroot)), nil
+					},
+				},
+			}
+		}),
+	})
+
+	epochType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Epoch",
+		Description: "A consensus layer epoch",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"epoch":                   graphqlField(graphql.NewNonNull(graphqlLong), "Number of the epoch", func(e *types.APIEpochResponse) interface{} { return e.Epoch }),
+				"finalized":               graphqlField(graphql.NewNonNull(graphql.Boolean), "Whether the epoch is finalized", func(e *types.APIEpochResponse) interface{} { return e.Finalized }),
+				"validatorsCount":         graphqlField(graphql.NewNonNull(graphqlLong), "Number of active validators", func(e *types.APIEpochResponse) interface{} { return e.ValidatorsCount }),
+				"totalValidatorBalance":   graphqlField(graphql.NewNonNull(graphqlLong), "Total balance of the validators in gwei", func(e *types.APIEpochResponse) interface{} { return e.TotalValidatorBalance }),
+				"averageValidatorBalance": graphqlField(graphql.NewNonNull(graphqlLong), "Average balance of the validators in gwei", func(e *types.APIEpochResponse) interface{} { return e.AverageValidatorBalance }),
+				"eligibleEther":           graphqlField(graphqlLong, "Ether eligible to vote in gwei", func(e *types.APIEpochResponse) interface{} { return e.EligibleEther }),
+				"votedEther":              graphqlField(graphqlLong, "Ether that voted in gwei", func(e *types.APIEpochResponse) interface{} { return e.VotedEther }),
+				"globalParticipationRate": graphqlField(graphql.Float, "Participation rate of the epoch", func(e *types.APIEpochResponse) interface{} { return e.GlobalParticipationRate }),
+				"attestationsCount":       graphqlField(graphql.NewNonNull(graphqlLong), "Number of attestations", func(e *types.APIEpochResponse) interface{} { return e.AttestationsCount }),
+				"depositsCount":           graphqlField(graphql.NewNonNull(graphqlLong), "Number of deposits", func(e *types.APIEpochResponse) interface{} { return e.DepositsCount }),
+				"voluntaryExitsCount":     graphqlField(graphql.NewNonNull(graphqlLong), "Number of voluntary exits", func(e *types.APIEpochResponse) interface{} { return e.VoluntaryExitsCount }),
+				"proposerSlashingsCount":  graphqlField(graphql.NewNonNull(graphqlLong), "Number of proposer slashings", func(e *types.APIEpochResponse) interface{} { return e.ProposerSlashingsCount }),
+				"attesterSlashingsCount":  graphqlField(graphql.NewNonNull(graphqlLong), "Number of attester slashings", func(e *types.APIEpochResponse) interface{} { return e.AttesterSlashingsCount }),
+				"withdrawalCount":         graphqlField(graphql.NewNonNull(graphqlLong), "Number of withdrawals", func(e *types.APIEpochResponse) interface{} { return e.WithdrawalCount }),
+				"scheduledBlocks":         graphqlField(graphql.NewNonNull(graphqlLong), "Number of scheduled blocks", func(e *types.APIEpochResponse) interface{} { return e.ScheduledBlocks }),
+				"proposedBlocks":          graphqlField(graphql.NewNonNull(graphqlLong), "Number of proposed blocks", func(e *types.APIEpochResponse) interface{} { return e.ProposedBlocks }),
+				"missedBlocks":            graphqlField(graphql.NewNonNull(graphqlLong), "Number of missed blocks", func(e *types.APIEpochResponse) interface{} { return e.MissedBlocks }),
+				"orphanedBlocks":          graphqlField(graphql.NewNonNull(graphqlLong), "Number of orphaned blocks", func(e *types.APIEpochResponse) interface{} { return e.OrphanedBlocks }),
+				"slots": {
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(slotType))),
+					Description: "Slots of the epoch, ordered by slot",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return graphqlLoadersFrom(p).epochSlots.load(p.Source.(*types.APIEpochResponse).Epoch), nil
+					},
+				},
+			}
+		}),
+	})
+
+	executionBlockType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "ExecutionBlock",
+		Description: "An execution layer block",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"number":             graphqlField(graphql.NewNonNull(graphqlLong), "Number of the block", func(b *types.ExecutionBlockApiResponse) interface{} { return b.BlockNumber }),
+				"hash":               graphqlField(graphql.NewNonNull(graphqlBytes), "Hash of the block", func(b *types.ExecutionBlockApiResponse) interface{} { return b.Hash }),
+				"parentHash":         graphqlField(graphql.NewNonNull(graphqlBytes), "Hash of the parent block", func(b *types.ExecutionBlockApiResponse) interface{} { return b.ParentHash }),
+				"timestamp":          graphqlField(graphql.NewNonNull(graphqlLong), "Unix timestamp of the block", func(b *types.ExecutionBlockApiResponse) interface{} { return b.Timestamp }),
+				"feeRecipient":       graphqlField(graphql.NewNonNull(graphqlBytes), "Fee recipient of the block", func(b *types.ExecutionBlockApiResponse) interface{} { return b.FeeRecipient }),
+				"gasLimit":           graphqlField(graphql.NewNonNull(graphqlLong), "Gas limit of the block", func(b *types.ExecutionBlockApiResponse) interface{} { return b.GasLimit }),
+				"gasUsed":            graphqlField(graphql.NewNonNull(graphqlLong), "Gas used by the block", func(b *types.ExecutionBlockApiResponse) interface{} { return b.GasUsed }),
+				"baseFee":            graphqlField(graphql.NewNonNull(graphqlBigInt), "Base fee per gas in wei", func(b *types.ExecutionBlockApiResponse) interface{} { return b.BaseFee }),
+				"transactionsCount":  graphqlField(graphql.NewNonNull(graphqlLong), "Number of transactions in the block", func(b *types.ExecutionBlockApiResponse) interface{} { return b.TxCount }),
+				"blockReward":        graphqlField(graphql.NewNonNull(graphqlBigInt), "Priority fees and block reward paid to the fee recipient in wei", func(b *types.ExecutionBlockApiResponse) interface{} { return b.BlockReward }),
+				"mevReward":          graphqlField(graphql.NewNonNull(graphqlBigInt), "Reward the relay reported for the block in wei, 0 if the block has not been delivered by a relay", func(b *types.ExecutionBlockApiResponse) interface{} { return b.BlockMevReward }),
+				"producerReward":     graphqlField(graphql.NewNonNull(graphqlBigInt), "Reward of the proposer in wei, the mev reward if the block has been delivered by a relay and the block reward otherwise", func(b *types.ExecutionBlockApiResponse) interface{} { return b.FeeRecipientReward }),
+				"consensusAlgorithm": graphqlField(graphql.NewNonNull(graphql.String), "Consensus algorithm of the block, pos or pow", func(b *types.ExecutionBlockApiResponse) interface{} { return b.ConsensusAlgorithm }),
+				"slot": {
+					Type:        slotType,
+					Description: "Slot of the block that contains the block as execution payload",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						block := p.Source.(*types.ExecutionBlockApiResponse)
+						if block.PoSData == nil {
+							return nil, nil
+						}
+						return graphqlLoadersFrom(p).slots.load(block.PoSData.Slot), nil
+					},
+				},
+				"relays": {
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(relayType))),
+					Description: "Relays that delivered the block",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return graphqlLoadersFrom(p).relays.load(p.Source.(*types.ExecutionBlockApiResponse).Hash), nil
+					},
+				},
+				"transactions": {
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(transactionType))),
+					Description: "Transactions of the block",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return graphqlLoadersFrom(p).transactions.load(p.Source.(*types.ExecutionBlockApiResponse).BlockNumber), nil
+					},
+				},
+			}
+		}),
+	})
+
+	transactionType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Transaction",
+		Description: "An execution layer transaction",
+		Fields: graphql.Fields{
+			"hash":         graphqlField(graphql.NewNonNull(graphqlBytes), "Hash of the transaction", func(tx *graphqlTransaction) interface{} { return tx.Hash }),
+			"blockNumber":  graphqlField(graphql.NewNonNull(graphqlLong), "Number of the block of the transaction", func(tx *graphqlTransaction) interface{} { return tx.BlockNumber }),
+			"from":         graphqlField(graphql.NewNonNull(graphqlBytes), "Sender of the transaction", func(tx *graphqlTransaction) interface{} { return tx.From }),
+			"to":           graphqlField(graphqlBytes, "Recipient of the transaction, null for contract creations", func(tx *graphqlTransaction) interface{} { return graphqlOptionalBytes(tx.To) }),
+			"value":        graphqlField(graphql.NewNonNull(graphqlBigInt), "Value of the transaction in wei", func(tx *graphqlTransaction) interface{} { return tx.Value }),
+			"nonce":        graphqlField(graphqlLong, "Nonce of the transaction, only set for transactions of a block", func(tx *graphqlTransaction) interface{} { return tx.Nonce }),
+			"gasPrice":     graphqlField(graphql.NewNonNull(graphqlBigInt), "Effective gas price of the transaction in wei", func(tx *graphqlTransaction) interface{} { return tx.GasPrice }),
+			"gasUsed":      graphqlField(graphqlLong, "Gas used by the transaction, only set for transactions of a block", func(tx *graphqlTransaction) interface{} { return tx.GasUsed }),
+			"fee":          graphqlField(graphql.NewNonNull(graphqlBigInt), "Fee of the transaction in wei", func(tx *graphqlTransaction) interface{} { return tx.Fee }),
+			"success":      graphqlField(graphql.NewNonNull(graphql.Boolean), "Whether the transaction has been executed successfully", func(tx *graphqlTransaction) interface{} { return tx.Success }),
+			"errorMessage": graphqlField(graphql.String, "Error of a failed transaction", func(tx *graphqlTransaction) interface{} { return graphqlOptionalString(tx.ErrorMessage) }),
+		},
+	})
+
+	withdrawalType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Withdrawal",
+		Description: "A withdrawal from the consensus to the execution layer",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"index":          graphqlField(graphql.NewNonNull(graphqlLong), "Index of the withdrawal", func(w *graphqlWithdrawal) interface{} { return w.Index }),
+				"slot":           graphqlField(graphql.NewNonNull(graphqlLong), "Slot of the block that contains the withdrawal", func(w *graphqlWithdrawal) interface{} { return w.BlockSlot }),
+				"validatorIndex": graphqlField(graphql.NewNonNull(graphqlLong), "Index of the withdrawn validator", func(w *graphqlWithdrawal) interface{} { return w.ValidatorIndex }),
+				"address":        graphqlField(graphql.NewNonNull(graphqlBytes), "Address the withdrawal is sent to", func(w *graphqlWithdrawal) interface{} { return w.Address }),
+				"amount":         graphqlField(graphql.NewNonNull(graphqlLong), "Amount of the withdrawal in gwei", func(w *graphqlWithdrawal) interface{} { return w.Amount }),
+				"validator": {
+					Type:        validatorType,
+					Description: "Withdrawn validator",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return graphqlLoadersFrom(p).validators.load(p.Source.(*graphqlWithdrawal).ValidatorIndex), nil
+					},
+				},
+			}
+		}),
+	})
+
+	relayType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Relay",
+		Description: "A mev relay",
+		Fields: graphql.Fields{
+			"tagId":                graphqlField(graphql.NewNonNull(graphql.String), "Id of the relay", func(r *graphqlRelay) interface{} { return r.TagID }),
+			"publicLink":           graphqlField(graphql.String, "Public link of the relay", func(r *graphqlRelay) interface{} { return r.PublicLink }),
+			"isCensoring":          graphqlField(graphql.Boolean, "Whether the relay censors transactions", func(r *graphqlRelay) interface{} { return r.IsCensoring }),
+			"isEthical":            graphqlField(graphql.Boolean, "Whether the relay filters unethical transactions", func(r *graphqlRelay) interface{} { return r.IsEthical }),
+			"builderPubkey":        graphqlField(graphqlBytes, "Public key of the builder of the delivered block", func(r *graphqlRelay) interface{} { return graphqlOptionalBytes(r.BuilderPubkey) }),
+			"proposerFeeRecipient": graphqlField(graphqlBytes, "Fee recipient of the proposer of the delivered block", func(r *graphqlRelay) interface{} { return graphqlOptionalBytes(r.ProposerFeeRecipient) }),
+			"value": graphqlField(graphqlBigInt, "Value of the delivered block in wei", func(r *graphqlRelay) interface{} {
+				if len(r.ExecBlockHash) == 0 {
+					return nil
+				}
+				return r.Value.BigInt()
+			}),
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"validators": {
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(validatorType))),
+				Description: "Validators by their indices and pubkeys, ordered by index",
+				Args: graphql.FieldConfigArgument{
+					"indices": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphqlLong))},
+					"pubkeys": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphqlBytes))},
+				},
+				Resolve: resolveGraphqlValidators,
+			},
+			"validator": {
+				Type:        validatorType,
+				Description: "Validator by its index or pubkey",
+				Args: graphql.FieldConfigArgument{
+					"index":  &graphql.ArgumentConfig{Type: graphqlLong},
+					"pubkey": &graphql.ArgumentConfig{Type: graphqlBytes},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if index, ok := p.Args["index"].(uint64); ok {
+						return graphqlLoadersFrom(p).validators.load(index), nil
+					}
+					pubkey, ok := p.Args["pubkey"].([]byte)
+					if !ok {
+						return nil, fmt.Errorf("either index or pubkey has to be set")
+					}
+					indices, err := resolveIndices([][]byte{pubkey})
+					if err != nil {
+						return nil, graphqlLoadError("validators", err)
+					}
+					if len(indices) == 0 {
+						return nil, nil
+					}
+					return graphqlLoadersFrom(p).validators.load(indices[0]), nil
+				},
+			},
+			"epoch": {
+				Type:        epochType,
+				Description: "Epoch by its number",
+				Args: graphql.FieldConfigArgument{
+					"epoch": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphqlLong)},
+				},
+				Resolve: resolveGraphqlEpoch,
+			},
+			"slot": {
+				Type:        slotType,
+				Description: "Slot by its number, orphaned blocks of the slot are not returned",
+				Args: graphql.FieldConfigArgument{
+					"slot": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphqlLong)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return graphqlLoadersFrom(p).slots.load(p.Args["slot"].(uint64)), nil
+				},
+			},
+			"executionBlock": {
+				Type:        executionBlockType,
+				Description: "Execution layer block by its number",
+				Args: graphql.FieldConfigArgument{
+					"number": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphqlLong)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return graphqlLoadersFrom(p).executionBlocks.load(p.Args["number"].(uint64)), nil
+				},
+			},
+			"transaction": {
+				Type:        transactionType,
+				Description: "Execution layer transaction by its hash",
+				Args: graphql.FieldConfigArgument{
+					"hash": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphqlBytes)},
+				},
+				Resolve: resolveGraphqlTransaction,
+			},
+			"relays": {
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(relayType))),
+				Description: "Known mev relays",
+				Resolve:     resolveGraphqlRelays,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+func graphqlOptionalString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func resolveGraphqlValidators(p graphql.ResolveParams) (interface{}, error) {
+	loaders := graphqlLoadersFrom(p)
+
+	indices := []uint64{}
+	if list, ok := p.Args["indices"].([]interface{}); ok {
+		for _, index := range list {
+			indices = append(indices, index.(uint64))
+		}
+	}
+	pubkeys := [][]byte{}
+	if list, ok := p.Args["pubkeys"].([]interface{}); ok {
+		for _, pubkey := range list {
+			pubkeys = append(pubkeys, pubkey.([]byte))
+		}
+	}
+	if len(indices)+len(pubkeys) > loaders.maxValidators {
+		return nil, fmt.Errorf("only a maximum of %v validators are allowed", loaders.maxValidators)
+	}
+	if len(pubkeys) > 0 {
+		indicesFromPubkeys, err := resolveIndices(pubkeys)
+		if err != nil {
+			return nil, graphqlLoadError("validators", err)
+		}
+		indices = append(indices, indicesFromPubkeys...)
+	}
+	indices = utils.SortedUniqueUint64(indices)
+
+	for _, index := range indices {
+		loaders.validators.load(index)
+	}
+	return func() (interface{}, error) {
+		validators := make([]*graphqlValidator, 0, len(indices))
+		for _, index := range indices {
+			validator, ok, err := loaders.validators.get(index)
+			if err != nil {
+				return nil, err
+			}
+			if ok && validator != nil {
+				validators = append(validators, validator)
+			}
+		}
+		return validators, nil
+	}, nil
+}
+
+func resolveGraphqlEpoch(p graphql.ResolveParams) (interface{}, error) {
+	data := []*types.APIEpochResponse{}
+	err := db.ReaderDb.Select(&data, `SELECT attestationscount, attesterslashingscount, averagevalidatorbalance, blockscount, depositscount, eligibleether, epoch, (epoch <= $2) AS finalized, globalparticipationrate, proposerslashingscount, rewards_exported, totalvalidatorbalance, validatorscount, voluntaryexitscount, votedether, COALESCE(withdrawalcount,0) as withdrawalcount,
+		(SELECT COUNT(*) FROM blocks WHERE epoch = $1 AND status = '0') as scheduledblocks,
+		(SELECT COUNT(*) FROM blocks WHERE epoch = $1 AND status = '1') as proposedblocks,
+		(SELECT COUNT(*) FROM blocks WHERE epoch = $1 AND status = '2') as missedblocks,
+		(SELECT COUNT(*) FROM blocks WHERE epoch = $1 AND status = '3') as orphanedblocks
+		FROM epochs WHERE epoch = $1`, p.Args["epoch"].(uint64), services.LatestFinalizedEpoch())
+	if err != nil {
+		return nil, graphqlLoadError("epochs", err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return data[0], nil
+}
+
+func resolveGraphqlTransaction(p graphql.ResolveParams) (interface{}, error) {
+	tx, err := db.BigtableClient.GetIndexedEth1Transaction(p.Args["hash"].([]byte))
+	if err != nil {
+		return nil, graphqlLoadError("transactions", err)
+	}
+	if tx == nil {
+		return nil, nil
+	}
+	return &graphqlTransaction{
+		Hash:         tx.GetHash(),
+		BlockNumber:  tx.GetBlockNumber(),
+		From:         tx.GetFrom(),
+		To:           tx.GetTo(),
+		Value:        tx.GetValue(),
+		GasPrice:     tx.GetGasPrice(),
+		Fee:          new(big.Int).SetBytes(tx.GetTxFee()),
+		Success:      tx.GetErrorMsg() == "",
+		ErrorMessage: tx.GetErrorMsg(),
+	}, nil
+}
+
+func resolveGraphqlRelays(p graphql.ResolveParams) (interface{}, error) {
+	relays := []*graphqlRelay{}
+	err := db.ReaderDb.Select(&relays, `
+		SELECT DISTINCT ON (tag_id) tag_id, public_link, is_censoring, is_ethical
+		FROM relays
+		ORDER BY tag_id, endpoint`)
+	if err != nil {
+		return nil, graphqlLoadError("relays", err)
+	}
+	return relays, nil
+}
+
+// graphqlRangeGroups groups range keys by their range, so that the items of all validators of a group can be selected with a single query
+func graphqlRangeGroups(keys []graphqlRangeKey) map[graphqlRangeKey][]uint64 {
+	groups := map[graphqlRangeKey][]uint64{}
+	for _, key := range keys {
+		group := key
+		group.Validator = 0
+		groups[group] = append(groups[group], key.Validator)
+	}
+	return groups
+}
+
+func fetchGraphqlValidators(indices []uint64) (map[uint64]*graphqlValidator, error) {
+	data := []*graphqlValidator{}
+	err := db.ReaderDb.Select(&data, `
+		SELECT validatorindex, pubkey, withdrawableepoch, withdrawalcredentials, slashed, activationeligibilityepoch, activationepoch, exitepoch, status
+		FROM validators
+		WHERE validatorindex = ANY($1)`, pq.Array(indices))
+	if err != nil {
+		return nil, err
+	}
+	validators := make(map[uint64]*graphqlValidator, len(data))
+	for _, validator := range data {
+		validators[validator.Index] = validator
+	}
+	return validators, nil
+}
+
+func fetchGraphqlBalances(indices []uint64) (map[uint64]*types.ValidatorBalance, error) {
+	latestEpoch := services.LatestEpoch()
+	history, err := db.BigtableClient.GetValidatorBalanceHistory(indices, latestEpoch, latestEpoch)
+	if err != nil {
+		return nil, err
+	}
+	balances := make(map[uint64]*types.ValidatorBalance, len(history))
+	for index, balance := range history {
+		if len(balance) > 0 {
+			balances[index] = balance[0]
+		}
+	}
+	return balances, nil
+}
+
+func fetchGraphqlProposals(keys []graphqlRangeKey) (map[graphqlRangeKey][]*types.APIBlockResponse, error) {
+	proposals := map[graphqlRangeKey][]*types.APIBlockResponse{}
+	for group, validators := range graphqlRangeGroups(keys) {
+		data := []*types.APIBlockResponse{}
+		err := db.ReaderDb.Select(&data, `
+			SELECT `+apiValidatorProposalsColumns+`
+			FROM (
+				SELECT *, row_number() OVER (PARTITION BY proposer ORDER BY slot DESC) AS proposal
+				FROM blocks
+				WHERE proposer = ANY($1) AND epoch >= $2 AND epoch <= $3
+			) b
+			WHERE b.proposal <= $4
+			ORDER BY b.proposer, b.slot DESC`, pq.Array(validators), group.FromEpoch, group.ToEpoch, group.Limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, block := range data {
+			key := group
+			key.Validator = block.Proposer
+			proposals[key] = append(proposals[key], block)
+		}
+	}
+	return proposals, nil
+}
+
+func fetchGraphqlValidatorWithdrawals(keys []graphqlRangeKey) (map[graphqlRangeKey][]*graphqlWithdrawal, error) {
+	withdrawals := map[graphqlRangeKey][]*graphqlWithdrawal{}
+	for group, validators := range graphqlRangeGroups(keys) {
+		data := []*graphqlWithdrawal{}
+		err := db.ReaderDb.Select(&data, `
+			SELECT block_slot, block_root, withdrawalindex, validatorindex, address, amount
+			FROM (
+				SELECT w.*, row_number() OVER (PARTITION BY w.validatorindex ORDER BY w.block_slot DESC, w.withdrawalindex DESC) AS withdrawal
+				FROM blocks_withdrawals w
+				INNER JOIN blocks b ON b.blockroot = w.block_root AND b.status = '1'
+				WHERE w.validatorindex = ANY($1) AND w.block_slot >= $2 AND w.block_slot <= $3
+			) w
+			WHERE w.withdrawal <= $4
+			ORDER BY w.validatorindex, w.block_slot DESC, w.withdrawalindex DESC`,
+			pq.Array(validators), graphqlFirstSlot(group.FromEpoch), graphqlLastSlot(group.ToEpoch), group.Limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, withdrawal := range data {
+			key := group
+			key.Validator = withdrawal.ValidatorIndex
+			withdrawals[key] = append(withdrawals[key], withdrawal)
+		}
+	}
+	return withdrawals, nil
+}
+
+// graphqlFirstSlot returns the first slot of an epoch, capped to the range of the bigint columns
+func graphqlFirstSlot(epoch uint64) uint64 {
+	if epoch > math.MaxInt64/utils.Config.Chain.ClConfig.SlotsPerEpoch {
+		return math.MaxInt64
+	}
+	return epoch * utils.Config.Chain.ClConfig.SlotsPerEpoch
+}
+
+// graphqlLastSlot returns the last slot of an epoch, capped to the range of the bigint columns
+func graphqlLastSlot(epoch uint64) uint64 {
+	if epoch >= math.MaxInt64/utils.Config.Chain.ClConfig.SlotsPerEpoch {
+		return math.MaxInt64
+	}
+	return (epoch+1)*utils.Config.Chain.ClConfig.SlotsPerEpoch - 1
+}
+
+func fetchGraphqlSlots(slots []uint64) (map[uint64]*types.APIBlockResponse, error) {
+	data := []*types.APIBlockResponse{}
+	err := db.ReaderDb.Select(&data, `
+		SELECT `+apiValidatorProposalsColumns+`
+		FROM blocks b
+		WHERE b.slot = ANY($1) AND b.status <> '3'`, pq.Array(slots))
+	if err != nil {
+		return nil, err
+	}
+	blocks := make(map[uint64]*types.APIBlockResponse, len(data))
+	for _, block := range data {
+		blocks[block.Slot] = block
+	}
+	return blocks, nil
+}
+
+func fetchGraphqlEpochSlots(epochs []uint64) (map[uint64][]*types.APIBlockResponse, error) {
+	data := []*types.APIBlockResponse{}
+	err := db.ReaderDb.Select(&data, `
+		SELECT `+apiValidatorProposalsColumns+`
+		FROM blocks b
+		WHERE b.epoch = ANY($1)
+		ORDER BY b.slot`, pq.Array(epochs))
+	if err != nil {
+		return nil, err
+	}
+	blocks := map[uint64][]*types.APIBlockResponse{}
+	for _, block := range data {
+		blocks[block.Epoch] = append(blocks[block.Epoch], block)
+	}
+	return blocks, nil
+}
+
+// fetchGraphqlSlotWithdrawals returns the withdrawals of blocks, the keys are the block roots
+func fetchGraphqlSlotWithdrawals(roots []string) (map[string][]*graphqlWithdrawal, error) {
+	rootBytes := make([][]byte, 0, len(roots))
+	for _, root := range roots {
+		rootBytes = append(rootBytes, []byte(root))
+	}
+	data := []*graphqlWithdrawal{}
+	err := db.ReaderDb.Select(&data, `
+		SELECT block_slot, block_root, withdrawalindex, validatorindex, address, amount
+		FROM blocks_withdrawals
+		WHERE block_root = ANY($1)
+		ORDER BY withdrawalindex`, pq.ByteaArray(rootBytes))
+	if err != nil {
+		return nil, err
+	}
+	withdrawals := map[string][]*graphqlWithdrawal{}
+	for _, withdrawal := range data {
+		withdrawals[string(withdrawal.BlockRoot)] = append(withdrawals[string(withdrawal.BlockRoot)], withdrawal)
+	}
+	return withdrawals, nil
+}
+
+func fetchGraphqlExecutionBlocks(numbers []uint64) (map[uint64]*types.ExecutionBlockApiResponse, error) {
+	blocks, err := db.BigtableClient.GetBlocksIndexedMultiple(numbers, uint64(len(numbers)))
+	if err != nil {
+		return nil, err
+	}
+	relaysData, err := db.GetRelayDataForIndexedBlocks(blocks)
+	if err != nil {
+		return nil, err
+	}
+	_, posData, err := findExecBlockNumbersByExecBlockNumber(numbers, 0, uint64(len(numbers)))
+	if err != nil {
+		return nil, err
+	}
+
+	data := formatBlocksForApiResponse(blocks, relaysData, posData, nil)
+	executionBlocks := make(map[uint64]*types.ExecutionBlockApiResponse, len(data))
+	for i := range data {
+		executionBlocks[data[i].BlockNumber] = &data[i]
+	}
+	return executionBlocks, nil
+}
+
+func fetchGraphqlTransactions(numbers []uint64) (map[uint64][]*graphqlTransaction, error) {
+	transactions := make(map[uint64][]*graphqlTransaction, len(numbers))
+	for _, number := range numbers {
+		block, err := db.BigtableClient.GetBlockFromBlocksTable(number)
+		if err != nil {
+			return nil, fmt.Errorf("error getting block %v: %w", number, err)
+		}
+		if block == nil {
+			continue
+		}
+		for _, tx := range block.GetTransactions() {
+			nonce, gasUsed := tx.GetNonce(), tx.GetGasUsed()
+			gasPrice := new(big.Int).SetBytes(tx.GetGasPrice())
+			transactions[number] = append(transactions[number], &graphqlTransaction{
+				Hash:         tx.GetHash(),
+				BlockNumber:  number,
+				From:         tx.GetFrom(),
+				To:           tx.GetTo(),
+				Value:        tx.GetValue(),
+				Nonce:        &nonce,
+				GasPrice:     tx.GetGasPrice(),
+				GasUsed:      &gasUsed,
+				Fee:          gasPrice.Mul(gasPrice, new(big.Int).SetUint64(gasUsed)),
+				Success:      tx.GetStatus() == 1,
+				ErrorMessage: tx.GetErrorMsg(),
+			})
+		}
+	}
+	return transactions, nil
+}
+
+// fetchGraphqlRelays returns the relays that delivered execution blocks, the keys are the 0x prefixed hex hashes of the blocks
+func fetchGraphqlRelays(hashes []string) (map[string][]*graphqlRelay, error) {
+	hashBytes := make([][]byte, 0, len(hashes))
+	for _, hash := range hashes {
+		b, err := hex.DecodeString(strings.TrimPrefix(hash, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("error decoding block hash %v: %w", hash, err)
+		}
+		hashBytes = append(hashBytes, b)
+	}
+	data := []*graphqlRelay{}
+	err := db.ReaderDb.Select(&data, `
+		SELECT rb.tag_id, rb.exec_block_hash, rb.builder_pubkey, rb.proposer_fee_recipient, rb.value, r.public_link, r.is_censoring, r.is_ethical
+		FROM relays_blocks rb
+		LEFT JOIN (
+			SELECT DISTINCT ON (tag_id) tag_id, public_link, is_censoring, is_ethical
+			FROM relays
+			ORDER BY tag_id, endpoint
+		) r ON r.tag_id = rb.tag_id
+		WHERE rb.exec_block_hash = ANY($1)
+		ORDER BY rb.tag_id`, pq.ByteaArray(hashBytes))
+	if err != nil {
+		return nil, err
+	}
+	relays := map[string][]*graphqlRelay{}
+	for _, relay := range data {
+		hash := fmt.Sprintf("0x%x", relay.ExecBlockHash)
+		relays[hash] = append(relays[hash], relay)
+	}
+	return relays, nil
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+	"github.com/graphql-go/graphql"
+)
+
+func TestGraphqlComplexity(t *testing.T) {
+	prevConfig := utils.Config
+	defer func() { utils.Config = prevConfig }()
+	utils.Config = &types.Config{}
+	utils.Config.Chain.ClConfig.SlotsPerEpoch = 32
+	utils.Config.Chain.ClConfig.MaxWithdrawalsPerPayload = 16
+
+	schema, err := getGraphqlSchema()
+	if err != nil {
+		t.Fatalf("error creating schema: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		variables  map[string]interface{}
+		complexity int
+	}{
+		{"scalars", `{ slot(slot: 1) { slot epoch } }`, nil, 1},
+		{"nested object", `{ slot(slot: 1) { proposer { index } executionBlock { number } } }`, nil, 3},
+		{"epoch slots", `{ epoch(epoch: 1) { slots { slot } } }`, nil, 1 + 32},
+		{"validators", `{ validators(indices: [1, 2, 3]) { index } }`, nil, 3},
+		{"default limit", `{ validators(indices: [1, 2]) { proposals { slot } } }`, nil, 2 * (1 + 10)},
+		{
+			"variables and fragments",
+			`query q($v: [Long!], $l: Int) { validators(indices: $v) { ...proposals } } fragment proposals on Validator { proposals(limit: $l) { executionBlock { relays { tagId } } } }`,
+			map[string]interface{}{"v": []interface{}{1.0, 2.0}, "l": 3.0},
+			2 * (1 + 3*(1+1*(1+5))),
+		},
+		{"capped limit", `{ validator(index: 1) { withdrawals(limit: 100000) { amount } } }`, nil, 1 + 100},
+		{"exceeding", `{ epoch(epoch: 1) { slots { withdrawals { validator { proposals(limit: 100) { proposer { proposals(limit: 100) { slot } } } } } } } }`, nil, graphqlMaxComplexity + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseGraphqlQuery(tt.query)
+			if err != nil {
+				t.Fatalf("error parsing query: %v", err)
+			}
+			validation := graphql.ValidateDocument(schema, doc, nil)
+			if !validation.IsValid {
+				t.Fatalf("invalid query: %v", validation.Errors)
+			}
+			complexity, err := graphqlComplexity(schema, doc, "", tt.variables)
+			if err != nil {
+				t.Fatalf("error calculating complexity: %v", err)
+			}
+			if complexity != tt.complexity {
+				t.Errorf("complexity is %v, want %v", complexity, tt.complexity)
+			}
+		})
+	}
+}
+
+func TestApiGraphQLWeight(t *testing.T) {
+	body := `{"query": "{ validators(indices: [1, 2, 3, 4, 5]) { proposals(limit: 100) { slot } } }"}`
+	r := httptest.NewRequest("POST", "/api/v1/graphql", strings.NewReader(body))
+
+	weight, err := ApiGraphQLWeight(r)
+	if err != nil {
+		t.Fatalf("error weighing request: %v", err)
+	}
+	// 5 * (1 + 100) = 505 objects
+	if weight != 1 {
+		t.Errorf("weight is %v, want 1", weight)
+	}
+
+	restored, err := io.ReadAll(r.Body)
+	if err != nil || string(restored) != body {
+		t.Errorf("request body has not been restored")
+	}
+
+	r = httptest.NewRequest("POST", "/api/v1/graphql", strings.NewReader(`{"query": "{ validators(indices: [`+strings.Repeat("1, ", 49)+`1]) { withdrawals(limit: 100) { amount } } }"}`))
+	weight, err = ApiGraphQLWeight(r)
+	if err != nil {
+		t.Fatalf("error weighing request: %v", err)
+	}
+	// 50 * (1 + 100) = 5050 objects
+	if weight != 6 {
+		t.Errorf("weight is %v, want 6", weight)
+	}
+
+	_, err = ApiGraphQLWeight(httptest.NewRequest("POST", "/api/v1/graphql", strings.NewReader(`{"query": "{"}`)))
+	if err == nil {
+		t.Errorf("expected an error for an invalid query")
+	}
+}
@@ -205,6 +205,21 @@ func handleEventSubscriptions(w http.ResponseWriter, r *http.Request, user *type
 	for _, ev := range types.AddWatchlistEvents {
 		events[ev.Event] = r.FormValue(string(ev.Event)) == "on"
 	}
+
+	thresholds := map[types.EventName]float64{types.ValidatorIncomeBelowNetworkEventName: types.ValidatorIncomeBelowNetworkThresholdDefault}
+	if percent := r.FormValue(string(types.ValidatorIncomeBelowNetworkEventName) + "_threshold"); events[types.ValidatorIncomeBelowNetworkEventName] && percent != "" && getUserPremium(r).NotificationThresholds {
+		threshold, err := strconv.ParseFloat(percent, 64)
+		if err == nil {
+			threshold, err = incomeBelowNetworkThreshold(threshold)
+		}
+		if err != nil {
+			utils.SetFlash(w, r, authSessionName, "Error: The income threshold has to be a percentage of the network income between 1 and 99.")
+			http.Redirect(w, r, "/user/notifications", http.StatusSeeOther)
+			return err
+		}
+		thresholds[types.ValidatorIncomeBelowNetworkEventName] = threshold
+	}
+
 	for n, a := range events {
 		eventName := n
 		active := a
@@ -216,7 +231,7 @@ func handleEventSubscriptions(w http.ResponseWriter, r *http.Request, user *type
 			}
 			logger.Infof("eventName: %v, active: %v", eventName, active)
 			if active {
-				err := db.AddSubscriptionBatch(user.UserID, utils.GetNetwork(), eventName, pubKeyStrings, 0)
+				err := db.AddSubscriptionBatch(user.UserID, utils.GetNetwork(), eventName, pubKeyStrings, thresholds[eventName])
 				if err != nil {
 					logger.WithError(err).Errorf("error adding subscription for user: %v", user.UserID)
 					return err
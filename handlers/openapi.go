@@ -0,0 +1,494 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+)
+
+// apiResponseShape describes how the response type of an api endpoint is written to the response body
+type apiResponseShape int
+
+const (
+	// the response is wrapped in types.ApiResponse and data is the response type
+	apiShapeData apiResponseShape = iota
+	// the response is wrapped in types.ApiResponse, data is the response type if exactly one row has been found and an array of it otherwise
+	apiShapeRows
+	// the response is wrapped in types.ApiResponse and data is always null
+	apiShapeNoData
+	// the response type is written without the types.ApiResponse wrapper
+	apiShapeRaw
+	// the response is a page of types.ApiPaginatedResponse and data is the response type, which has to be a slice
+	apiShapePage
+	// the response is a png image
+	apiShapePNG
+	// the response body is not specified
+	apiShapeNone
+)
+
+type apiParam struct {
+	Name        string
+	Description string
+	Type        string
+	Required    bool
+}
+
+//go:generate go run ../cmd/api-endpoints -root ..
+
+// apiEndpoint documents a route of the api, every route that is registered for the api router has to be listed in apiEndpoints.
+// apiEndpoints is generated from the swag annotations of the handlers by cmd/api-endpoints, see package apidoc.
+type apiEndpoint struct {
+	Method   string
+	Path     string
+	Tag      string
+	Summary  string
+	Query    []apiParam
+	Body     interface{}
+	Response interface{}
+	Shape    apiResponseShape
+	Auth     bool
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*openAPISchema         `json:"schemas"`
+	SecuritySchemes map[string]*openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+type openAPIOperation struct {
+	Tags        []string                    `json:"tags,omitempty"`
+	Summary     string                      `json:"summary,omitempty"`
+	OperationID string                      `json:"operationId"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+	Security    []map[string][]string       `json:"security,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                         `json:"required"`
+	Content  map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	MinItems             *int                      `json:"minItems,omitempty"`
+	MaxItems             *int                      `json:"maxItems,omitempty"`
+	AllOf                []*openAPISchema          `json:"allOf,omitempty"`
+	OneOf                []*openAPISchema          `json:"oneOf,omitempty"`
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	bigIntType     = reflect.TypeOf(big.Int{})
+	decimalType    = reflect.TypeOf(decimal.Decimal{})
+	hexBytesType   = reflect.TypeOf(types.HexBytes{})
+	numericIntType = reflect.TypeOf(types.NumericInt{})
+	unixTimeType   = reflect.TypeOf(types.UnixTime{})
+	int64ArrayType = reflect.TypeOf(pq.Int64Array{})
+	marshalerType  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+var openAPIPathParamRegex = regexp.MustCompile(`{([^}]+)}`)
+
+// openAPIGenerator derives the schemas of the api responses from their go types the same way encoding/json encodes them.
+// Named structs are added as component and referenced, the name of a component has to be unique.
+type openAPIGenerator struct {
+	schemas map[string]*openAPISchema
+	types   map[string]reflect.Type
+}
+
+func (g *openAPIGenerator) schemaOf(t reflect.Type) (*openAPISchema, error) {
+	switch t {
+	case timeType:
+		return &openAPISchema{Type: "string", Format: "date-time"}, nil
+	case bigIntType, numericIntType:
+		return &openAPISchema{Type: "integer", Nullable: t == numericIntType}, nil
+	case decimalType:
+		return &openAPISchema{Type: "string"}, nil
+	case hexBytesType:
+		return &openAPISchema{Type: "string", Pattern: "^0x[0-9a-f]*$", Nullable: true}, nil
+	case unixTimeType:
+		return &openAPISchema{Type: "integer", Format: "int64"}, nil
+	case int64ArrayType:
+		return &openAPISchema{Type: "array", Items: &openAPISchema{Type: "integer", Format: "int64"}, Nullable: true}, nil
+	}
+	if t.Kind() == reflect.Pointer {
+		return g.nullable(t.Elem())
+	}
+	if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+		return nil, fmt.Errorf("error no schema for type %v, it implements json.Marshaler", t)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64"}, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &openAPISchema{Type: "integer", Format: "int32"}, nil
+	case reflect.Float32:
+		return &openAPISchema{Type: "number", Format: "float"}, nil
+	case reflect.Float64:
+		return &openAPISchema{Type: "number", Format: "double"}, nil
+	case reflect.String:
+		return &openAPISchema{Type: "string"}, nil
+	case reflect.Interface:
+		return &openAPISchema{}, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &openAPISchema{Type: "string", Format: "byte", Nullable: true}, nil
+		}
+		items, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &openAPISchema{Type: "array", Items: items, Nullable: true}, nil
+	case reflect.Array:
+		items, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		length := t.Len()
+		return &openAPISchema{Type: "array", Items: items, MinItems: &length, MaxItems: &length}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String && t.Key().Kind() != reflect.Uint64 && t.Key().Kind() != reflect.Int64 {
+			return nil, fmt.Errorf("error no schema for map key of type %v", t)
+		}
+		values, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &openAPISchema{Type: "object", AdditionalProperties: values, Nullable: true}, nil
+	case reflect.Struct:
+		return g.structSchema(t)
+	}
+	return nil, fmt.Errorf("error no schema for type %v", t)
+}
+
+// nullable returns the schema of t that also allows null, references can not be extended and are wrapped in allOf
+func (g *openAPIGenerator) nullable(t reflect.Type) (*openAPISchema, error) {
+	schema, err := g.schemaOf(t)
+	if err != nil {
+		return nil, err
+	}
+	if schema.Ref != "" {
+		return &openAPISchema{AllOf: []*openAPISchema{schema}, Nullable: true}, nil
+	}
+	if schema.Type == "" && len(schema.OneOf) == 0 {
+		// the empty schema already allows null
+		return schema, nil
+	}
+	schema.Nullable = true
+	return schema, nil
+}
+
+func (g *openAPIGenerator) structSchema(t reflect.Type) (*openAPISchema, error) {
+	name := t.Name()
+	if name != "" {
+		if existing, found := g.types[name]; found {
+			if existing != t {
+				return nil, fmt.Errorf("error schema name %v is used by %v and %v", name, existing, t)
+			}
+			return &openAPISchema{Ref: "#/components/schemas/" + name}, nil
+		}
+		// register the name before resolving the fields to support recursive types
+		g.types[name] = t
+	}
+
+	schema := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+	err := g.addStructFields(schema, t)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(schema.Required)
+
+	if name == "" {
+		return schema, nil
+	}
+	g.schemas[name] = schema
+	return &openAPISchema{Ref: "#/components/schemas/" + name}, nil
+}
+
+func (g *openAPIGenerator) addStructFields(schema *openAPISchema, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				err := g.addStructFields(schema, embedded)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSchema, err := g.schemaOf(field.Type)
+		if err != nil {
+			return fmt.Errorf("error in field %v of %v: %w", field.Name, t, err)
+		}
+		if strings.Contains(opts, "string") {
+			fieldSchema = &openAPISchema{Type: "string"}
+		}
+		schema.Properties[name] = fieldSchema
+		if !strings.Contains(opts, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return nil
+}
+
+// responseSchema returns the schema of the response body of an endpoint, nil if there is no json body
+func (g *openAPIGenerator) responseSchema(endpoint apiEndpoint) (*openAPISchema, error) {
+	envelope := func(data *openAPISchema) *openAPISchema {
+		return &openAPISchema{
+			Type:       "object",
+			Properties: map[string]*openAPISchema{"status": {Type: "string"}, "data": data},
+			Required:   []string{"data", "status"},
+		}
+	}
+
+	switch endpoint.Shape {
+	case apiShapeNoData:
+		return envelope(&openAPISchema{Nullable: true}), nil
+	case apiShapePNG, apiShapeNone:
+		return nil, nil
+	}
+
+	data, err := g.schemaOf(reflect.TypeOf(endpoint.Response))
+	if err != nil {
+		return nil, err
+	}
+	// our handlers never write a nil slice as data
+	if data.Type == "array" {
+		data.Nullable = false
+	}
+
+	switch endpoint.Shape {
+	case apiShapePage:
+		if data.Type != "array" {
+			return nil, fmt.Errorf("error the response of a paginated endpoint has to be a slice")
+		}
+		paging, err := g.schemaOf(reflect.TypeOf(&types.ApiPaging{}))
+		if err != nil {
+			return nil, err
+		}
+		page := envelope(data)
+		page.Properties["paging"] = paging
+		page.Required = append(page.Required, "paging")
+		return page, nil
+	case apiShapeRows:
+		return envelope(&openAPISchema{OneOf: []*openAPISchema{data, {Type: "array", Items: data}}}), nil
+	case apiShapeRaw:
+		return data, nil
+	}
+	return envelope(data), nil
+}
+
+func (g *openAPIGenerator) operation(endpoint apiEndpoint) (*openAPIOperation, error) {
+	op := &openAPIOperation{
+		Tags:        []string{endpoint.Tag},
+		Summary:     endpoint.Summary,
+		OperationID: openAPIOperationID(endpoint),
+		Responses:   map[string]*openAPIResponse{},
+	}
+
+	for _, match := range openAPIPathParamRegex.FindAllStringSubmatch(endpoint.Path, -1) {
+		op.Parameters = append(op.Parameters, &openAPIParameter{Name: match[1], In: "path", Required: true, Schema: &openAPISchema{Type: "string"}})
+	}
+	for _, param := range endpoint.Query {
+		paramType := param.Type
+		if paramType == "" {
+			paramType = "string"
+		}
+		op.Parameters = append(op.Parameters, &openAPIParameter{Name: param.Name, In: "query", Description: param.Description, Required: param.Required, Schema: &openAPISchema{Type: paramType}})
+	}
+
+	if endpoint.Body != nil {
+		body, err := g.schemaOf(reflect.TypeOf(endpoint.Body))
+		if err != nil {
+			return nil, fmt.Errorf("error generating request body schema: %w", err)
+		}
+		op.RequestBody = &openAPIRequestBody{Required: true, Content: map[string]*openAPIMediaType{"application/json": {Schema: body}}}
+	}
+
+	response, err := g.responseSchema(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("error generating response schema: %w", err)
+	}
+	switch {
+	case endpoint.Shape == apiShapePNG:
+		op.Responses["200"] = &openAPIResponse{Description: "OK", Content: map[string]*openAPIMediaType{"image/png": {Schema: &openAPISchema{Type: "string", Format: "binary"}}}}
+	case response != nil:
+		op.Responses["200"] = &openAPIResponse{Description: "OK", Content: map[string]*openAPIMediaType{"application/json": {Schema: response}}}
+	default:
+		op.Responses["200"] = &openAPIResponse{Description: "OK"}
+	}
+
+	errorResponse := map[string]*openAPIMediaType{"application/json": {Schema: &openAPISchema{Ref: "#/components/schemas/ApiResponse"}}}
+	op.Responses["400"] = &openAPIResponse{Description: "Bad Request", Content: errorResponse}
+	op.Responses["500"] = &openAPIResponse{Description: "Internal Server Error", Content: errorResponse}
+
+	if endpoint.Auth {
+		op.Security = []map[string][]string{{"bearerAuth": {}}}
+		op.Responses["401"] = &openAPIResponse{Description: "Unauthorized"}
+	}
+	return op, nil
+}
+
+// openAPIOperationID turns the method and path of an endpoint into a unique identifier, e.g. getSlotSlotAttestations
+func openAPIOperationID(endpoint apiEndpoint) string {
+	id := strings.ToLower(endpoint.Method)
+	path := strings.TrimPrefix(endpoint.Path, "/api")
+	path = strings.TrimPrefix(path, "/v1")
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '{' || r == '}' || r == '_' || r == '.'
+	}) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
+
+// generateOpenAPIDocument builds the OpenAPI 3 document of all apiEndpoints
+func generateOpenAPIDocument() (*openAPIDocument, error) {
+	g := &openAPIGenerator{schemas: map[string]*openAPISchema{}, types: map[string]reflect.Type{}}
+
+	doc := &openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    openAPIInfo{Title: "beaconcha.in explorer api", Version: "v1"},
+		Paths:   map[string]map[string]*openAPIOperation{},
+		Components: openAPIComponents{
+			SecuritySchemes: map[string]*openAPISecurityScheme{"bearerAuth": {Type: "http", Scheme: "bearer"}},
+		},
+	}
+
+	_, err := g.schemaOf(reflect.TypeOf(types.ApiResponse{}))
+	if err != nil {
+		return nil, err
+	}
+
+	operationIDs := map[string]bool{}
+	for _, endpoint := range apiEndpoints {
+		op, err := g.operation(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("error generating openapi operation for %v %v: %w", endpoint.Method, endpoint.Path, err)
+		}
+		if operationIDs[op.OperationID] {
+			return nil, fmt.Errorf("error duplicate openapi operation id %v", op.OperationID)
+		}
+		operationIDs[op.OperationID] = true
+
+		if doc.Paths[endpoint.Path] == nil {
+			doc.Paths[endpoint.Path] = map[string]*openAPIOperation{}
+		}
+		doc.Paths[endpoint.Path][strings.ToLower(endpoint.Method)] = op
+	}
+	doc.Components.Schemas = g.schemas
+
+	return doc, nil
+}
+
+var openAPISpec struct {
+	once sync.Once
+	data []byte
+	err  error
+}
+
+// ApiOpenAPISpec godoc
+// @Summary Get the OpenAPI 3 document of the api
+// @Tags Misc
+// @Produce json
+// @Success 200 {object} openAPIDocument
+// @Failure 500 {object} types.ApiResponse
+// @Router /api/v1/openapi.json [get]
+func ApiOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	openAPISpec.once.Do(func() {
+		doc, err := generateOpenAPIDocument()
+		if err != nil {
+			openAPISpec.err = err
+			return
+		}
+		openAPISpec.data, openAPISpec.err = json.Marshal(doc)
+	})
+	if openAPISpec.err != nil {
+		logger.WithError(openAPISpec.err).Error("error generating openapi document")
+		sendServerErrorResponse(w, r.URL.String(), "could not generate openapi document")
+		return
+	}
+
+	_, err := w.Write(openAPISpec.data)
+	if err != nil {
+		logger.WithError(err).Error("error writing openapi document")
+	}
+}
@@ -0,0 +1,466 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db"
+	"github.com/gobitfly/eth2-beaconchain-explorer/db/dbtest"
+	"github.com/gobitfly/eth2-beaconchain-explorer/handlers/apidoc"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+)
+
+var updateOpenAPISpec = flag.Bool("update", false, "update the openapi document in testdata/openapi.json")
+
+func TestOpenAPIDocument(t *testing.T) {
+	doc, err := generateOpenAPIDocument()
+	if err != nil {
+		t.Fatalf("error generating openapi document: %v", err)
+	}
+	got, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		t.Fatalf("error encoding openapi document: %v", err)
+	}
+	got = append(got, '\n')
+
+	path := "testdata/openapi.json"
+	if *updateOpenAPISpec {
+		err = os.WriteFile(path, got, 0644)
+		if err != nil {
+			t.Fatalf("error updating %v: %v", path, err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error reading %v, run the test with -update to create it: %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("the openapi document differs from %v, run the test with -update if the change is intended", path)
+	}
+}
+
+// TestOpenAPIEndpoints makes sure that api_endpoints.go is up to date with the swag annotations of the handlers
+func TestOpenAPIEndpoints(t *testing.T) {
+	got, err := apidoc.Generate(".")
+	if err != nil {
+		t.Fatalf("error generating api endpoints: %v", err)
+	}
+	want, err := os.ReadFile("api_endpoints.go")
+	if err != nil {
+		t.Fatalf("error reading api_endpoints.go: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("api_endpoints.go differs from the swag annotations of the handlers, run go generate ./handlers")
+	}
+}
+
+// TestOpenAPIRoutes makes sure that every api route registered in cmd/explorer is documented and that no documented route is missing
+func TestOpenAPIRoutes(t *testing.T) {
+	// the main router only serves the health endpoints below /api
+	prefixes := map[string]string{
+		"router":          "",
+		"apiV1Router":     "/api/v1",
+		"apiV1AuthRouter": "/api/v1/user",
+		"apiV2Router":     "/api/v2",
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "../cmd/explorer/main.go", nil, 0)
+	if err != nil {
+		t.Fatalf("error parsing cmd/explorer/main.go: %v", err)
+	}
+
+	registered := map[string]bool{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		methods, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		methodsSel, ok := methods.Fun.(*ast.SelectorExpr)
+		if !ok || methodsSel.Sel.Name != "Methods" {
+			return true
+		}
+		handleFunc, ok := methodsSel.X.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		handleFuncSel, ok := handleFunc.Fun.(*ast.SelectorExpr)
+		if !ok || handleFuncSel.Sel.Name != "HandleFunc" {
+			return true
+		}
+		router, ok := handleFuncSel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		prefix, ok := prefixes[router.Name]
+		if !ok {
+			return true
+		}
+		pathLit, ok := handleFunc.Args[0].(*ast.BasicLit)
+		if !ok {
+			t.Errorf("%v: route path is not a string literal", fset.Position(handleFunc.Pos()))
+			return true
+		}
+		path, _ := strconv.Unquote(pathLit.Value)
+		if router.Name == "router" && !strings.HasPrefix(path, "/api/") {
+			return true
+		}
+		for _, arg := range methods.Args {
+			methodLit, ok := arg.(*ast.BasicLit)
+			if !ok {
+				t.Errorf("%v: route method is not a string literal", fset.Position(arg.Pos()))
+				continue
+			}
+			method, _ := strconv.Unquote(methodLit.Value)
+			if method == "OPTIONS" || method == "HEAD" {
+				continue
+			}
+			registered[method+" "+prefix+path] = true
+		}
+		return true
+	})
+	if len(registered) == 0 {
+		t.Fatalf("no api routes found in cmd/explorer/main.go")
+	}
+
+	documented := map[string]bool{}
+	for _, endpoint := range apiEndpoints {
+		documented[endpoint.Method+" "+endpoint.Path] = true
+	}
+	for route := range registered {
+		if !documented[route] {
+			t.Errorf("route %v is not documented in apiEndpoints", route)
+		}
+	}
+	for route := range documented {
+		if !registered[route] {
+			t.Errorf("documented route %v is not registered in cmd/explorer/main.go", route)
+		}
+	}
+}
+
+// TestOpenAPIResponses runs handlers against a fake database and validates their responses against the generated document
+func TestOpenAPIResponses(t *testing.T) {
+	prevConfig, prevReader := utils.Config, db.ReaderDb
+	defer func() { utils.Config, db.ReaderDb = prevConfig, prevReader }()
+	utils.Config = &types.Config{}
+	utils.Config.Chain.GenesisTimestamp = 1606824023
+	utils.Config.Chain.ClConfig.SlotsPerEpoch = 32
+	utils.Config.Chain.ClConfig.SecondsPerSlot = 12
+	utils.Config.Chain.ClConfig.EpochsPerSyncCommitteePeriod = 256
+
+	doc, err := generateOpenAPIDocument()
+	if err != nil {
+		t.Fatalf("error generating openapi document: %v", err)
+	}
+
+	fake := &fakeApiDB{}
+	db.ReaderDb = (&dbtest.DB{Query: fake.query}).Sqlx()
+
+	pubkey := "0x" + strings.Repeat("ab", 48)
+	tests := []struct {
+		path    string
+		url     string
+		handler http.HandlerFunc
+		row     interface{}
+	}{
+		{"/api/v1/ethstore/{day}", "/api/v1/ethstore/100", ApiEthStoreDay, types.ApiEthStoreDayResponse{}},
+		{"/api/v1/slot/{slotOrHash}", "/api/v1/slot/100", ApiSlots, types.APISlotResponse{}},
+		{"/api/v1/slot/{slot}/deposits", "/api/v1/slot/100/deposits", ApiSlotDeposits, types.APIDepositResponse{}},
+		{"/api/v1/slot/{slot}/attesterslashings", "/api/v1/slot/100/attesterslashings", ApiSlotAttesterSlashings, types.APIAttesterSlashingResponse{}},
+		{"/api/v1/slot/{slot}/proposerslashings", "/api/v1/slot/100/proposerslashings", ApiSlotProposerSlashings, types.APIProposerSlashingResponse{}},
+		{"/api/v1/reorgs", "/api/v1/reorgs?limit=10&offset=0", ApiReorgs, types.APIReorgResponse{}},
+		{"/api/v1/slot/{slot}/voluntaryexits", "/api/v1/slot/100/voluntaryexits", ApiSlotVoluntaryExits, types.APIVoluntaryExitResponse{}},
+		{"/api/v1/slot/{slot}/withdrawals", "/api/v1/slot/100/withdrawals", ApiSlotWithdrawals, types.APISlotWithdrawalResponse{}},
+		{"/api/v1/slot/{slot}/depositrequests", "/api/v1/slot/100/depositrequests", ApiSlotDepositRequests, types.APIDepositRequestResponse{}},
+		{"/api/v1/slot/{slot}/withdrawalrequests", "/api/v1/slot/100/withdrawalrequests", ApiSlotWithdrawalRequests, types.APIWithdrawalRequestResponse{}},
+		{"/api/v1/slot/{slot}/consolidations", "/api/v1/slot/100/consolidations", ApiSlotConsolidations, types.APIConsolidationRequestResponse{}},
+		{"/api/v1/sync_committee/{period}", "/api/v1/sync_committee/10", ApiSyncCommittee, types.APISyncCommitteeResponse{}},
+		{"/api/v1/validators/queue", "/api/v1/validators/queue", ApiValidatorQueue, types.ApiValidatorQueueResponse{}},
+		{"/api/v1/validator/eth1/{address}", "/api/v1/validator/eth1/0x" + strings.Repeat("ab", 20), ApiValidatorByEth1Address, types.ApiValidatorEth1Response{}},
+		{"/api/v1/validator/leaderboard", "/api/v1/validator/leaderboard", ApiValidatorLeaderboard, types.ApiValidatorPerformanceResponse{}},
+		{"/api/v1/validator/{indexOrPubkey}/deposits", "/api/v1/validator/" + pubkey + "/deposits", ApiValidatorDeposits, types.ApiValidatorDepositsResponse{}},
+		{"/api/v1/validator/{indexOrPubkey}/proposals", "/api/v1/validator/1,2/proposals?epoch=100", ApiValidatorProposals, types.APIBlockResponse{}},
+		{"/api/v2/validator/{indexOrPubkey}/proposals", "/api/v2/validator/1,2/proposals", ApiV2ValidatorProposals, types.APIBlockResponse{}},
+		{"/api/v2/validator/{indexOrPubkey}/proposals", "/api/v2/validator/1,2/proposals?limit=1", ApiV2ValidatorProposals, types.APIBlockResponse{}},
+		{"/api/v1/graffitiwall", "/api/v1/graffitiwall?slot=100", ApiGraffitiwall, types.ApiGraffitiwallResponse{}},
+		{"/api/v1/eth1deposit/{txhash}", "/api/v1/eth1deposit/0x" + strings.Repeat("ab", 32), ApiEth1Deposit, types.ApiValidatorDepositsResponse{}},
+	}
+
+	for _, tt := range tests {
+		op := doc.Paths[tt.path]["get"]
+		if op == nil {
+			t.Errorf("%v is not documented", tt.path)
+			continue
+		}
+		schema := op.Responses["200"].Content["application/json"].Schema
+
+		router := mux.NewRouter()
+		router.HandleFunc(tt.path, tt.handler).Methods("GET")
+
+		for name, rows := range map[string][]bool{"rows": {false, true}, "row": {false}, "nulls": {true}, "empty": {}} {
+			t.Run(tt.path+"/"+name, func(t *testing.T) {
+				fake.rows = reflect.TypeOf(tt.row)
+				fake.nulls = rows
+
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, httptest.NewRequest("GET", tt.url, nil))
+				if rec.Code != http.StatusOK {
+					t.Fatalf("unexpected status code %v: %v", rec.Code, rec.Body.String())
+				}
+
+				dec := json.NewDecoder(rec.Body)
+				dec.UseNumber()
+				var body interface{}
+				err := dec.Decode(&body)
+				if err != nil {
+					t.Fatalf("error decoding response: %v", err)
+				}
+				err = validateOpenAPISchema(doc, schema, body, "$")
+				if err != nil {
+					t.Errorf("response does not match the openapi document: %v\n%v", err, rec.Body.String())
+				}
+			})
+		}
+	}
+}
+
+func validateOpenAPISchema(doc *openAPIDocument, schema *openAPISchema, value interface{}, path string) error {
+	if schema.Ref != "" {
+		resolved := doc.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+		if resolved == nil {
+			return fmt.Errorf("%v: unknown reference %v", path, schema.Ref)
+		}
+		return validateOpenAPISchema(doc, resolved, value, path)
+	}
+	if value == nil {
+		if schema.Nullable || (schema.Type == "" && len(schema.AllOf) == 0 && len(schema.OneOf) == 0) {
+			return nil
+		}
+		return fmt.Errorf("%v: null is not allowed", path)
+	}
+	for _, sub := range schema.AllOf {
+		err := validateOpenAPISchema(doc, sub, value, path)
+		if err != nil {
+			return err
+		}
+	}
+	if len(schema.OneOf) > 0 {
+		matches := 0
+		var errs []string
+		for _, sub := range schema.OneOf {
+			err := validateOpenAPISchema(doc, sub, value, path)
+			if err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+			matches++
+		}
+		if matches != 1 {
+			return fmt.Errorf("%v: value matches %v schemas of oneOf: %v", path, matches, strings.Join(errs, "; "))
+		}
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%v: expected object, got %T", path, value)
+		}
+		for _, name := range schema.Required {
+			if _, found := obj[name]; !found {
+				return fmt.Errorf("%v: missing required property %v", path, name)
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			propertySchema := schema.Properties[key]
+			if propertySchema == nil {
+				propertySchema = schema.AdditionalProperties
+			}
+			if propertySchema == nil {
+				return fmt.Errorf("%v: unknown property %v", path, key)
+			}
+			err := validateOpenAPISchema(doc, propertySchema, obj[key], path+"."+key)
+			if err != nil {
+				return err
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%v: expected array, got %T", path, value)
+		}
+		if schema.MinItems != nil && len(arr) < *schema.MinItems || schema.MaxItems != nil && len(arr) > *schema.MaxItems {
+			return fmt.Errorf("%v: unexpected number of items %v", path, len(arr))
+		}
+		for i, item := range arr {
+			err := validateOpenAPISchema(doc, schema.Items, item, fmt.Sprintf("%v[%v]", path, i))
+			if err != nil {
+				return err
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%v: expected string, got %T", path, value)
+		}
+		if schema.Pattern != "" && !regexp.MustCompile(schema.Pattern).MatchString(s) {
+			return fmt.Errorf("%v: %q does not match %v", path, s, schema.Pattern)
+		}
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok || strings.ContainsAny(n.String(), ".eE") {
+			return fmt.Errorf("%v: expected integer, got %v", path, value)
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return fmt.Errorf("%v: expected number, got %T", path, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%v: expected boolean, got %T", path, value)
+		}
+	}
+	return nil
+}
+
+// fakeApiDB answers every query with rows of the columns of its select list, the values are typed by the field of the
+// response type a column is scanned into. Columns without a field and fields without a column fail the query.
+// For every entry of nulls one row is returned, nullable columns of that row are null if the entry is true.
+type fakeApiDB struct {
+	rows  reflect.Type
+	nulls []bool
+}
+
+func (f *fakeApiDB) query(query string, args []driver.Value) (*dbtest.Rows, error) {
+	if strings.HasPrefix(strings.TrimSpace(query), "SELECT blockroot FROM blocks WHERE slot") {
+		return &dbtest.Rows{Columns: []string{"blockroot"}, Values: [][]driver.Value{{bytes.Repeat([]byte{0xab}, 32)}}}, nil
+	}
+
+	columns, err := dbtest.SelectColumns(query)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]reflect.Type{}
+	fakeApiFields(f.rows, fields)
+	for _, column := range columns {
+		if fields[column] == nil {
+			return nil, fmt.Errorf("column %v of the query is not a field of %v", column, f.rows)
+		}
+	}
+	for field := range fields {
+		if !utils.SliceContains(columns, field) {
+			return nil, fmt.Errorf("field %v of %v is not selected by the query", field, f.rows)
+		}
+	}
+
+	rows := &dbtest.Rows{Columns: columns}
+	for _, null := range f.nulls {
+		values := make([]driver.Value, 0, len(columns))
+		for _, column := range columns {
+			values = append(values, fakeApiValue(fields[column], null))
+		}
+		rows.Values = append(rows.Values, values)
+	}
+	return rows, nil
+}
+
+// fakeApiFields adds the columns sqlx maps to the fields of t and their types to fields
+func fakeApiFields(t reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("db")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			fakeApiFields(field.Type, fields)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+}
+
+func fakeApiValue(t reflect.Type, null bool) driver.Value {
+	switch t {
+	case hexBytesType:
+		if null {
+			return []byte{}
+		}
+		return []byte{0xab, 0xcd}
+	case numericIntType:
+		if null {
+			return nil
+		}
+		return []byte("123456789012345678901234567890.5")
+	case int64ArrayType:
+		if null {
+			return nil
+		}
+		return []byte("{1,2}")
+	case timeType, unixTimeType:
+		return time.Unix(1606824023, 0).UTC()
+	case decimalType:
+		return []byte(decimal.NewFromFloat(1.5).String())
+	case reflect.TypeOf(pq.ByteaArray{}):
+		if null {
+			return nil
+		}
+		return []byte(`{"\\xabcd"}`)
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		if null {
+			return nil
+		}
+		return fakeApiValue(t.Elem(), null)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			if null {
+				return nil
+			}
+			return []byte{0xab, 0xcd}
+		}
+	case reflect.Bool:
+		return true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(1)
+	case reflect.Float32, reflect.Float64:
+		return float64(1.5)
+	case reflect.String:
+		return "text"
+	}
+	panic(fmt.Sprintf("no fake value for columns of type %v", t))
+}
@@ -213,6 +213,11 @@ func createMenuItems(active string, isMain bool) []types.MainMenuItem {
 							Path:  "/slots",
 							Icon:  "fa-cube",
 						},
+						{
+							Label: "Reorgs",
+							Path:  "/reorgs",
+							Icon:  "fa-code-branch",
+						},
 					},
 				}, {
 					Links: []types.NavigationLink{
@@ -480,6 +485,11 @@ func createMenuItemsGnosis(active string, isMain bool) []types.MainMenuItem {
 							Path:  "/slots",
 							Icon:  "fa-cube",
 						},
+						{
+							Label: "Reorgs",
+							Path:  "/reorgs",
+							Icon:  "fa-code-branch",
+						},
 					},
 				}, {
 					Links: []types.NavigationLink{
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db"
+	"github.com/gobitfly/eth2-beaconchain-explorer/templates"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+)
+
+// Reorgs will return the chain reorganizations detected by the exporter using a go template
+func Reorgs(w http.ResponseWriter, r *http.Request) {
+	templateFiles := append(layoutTemplateFiles, "reorgs.html")
+	var reorgsTemplate = templates.GetTemplate(templateFiles...)
+
+	w.Header().Set("Content-Type", "text/html")
+
+	data := InitPageData(w, r, "blockchain", "/reorgs", "Reorgs", templateFiles)
+
+	if handleTemplateError(w, r, "reorgs.go", "Reorgs", "", reorgsTemplate.ExecuteTemplate(w, "layout", data)) != nil {
+		return // an error has occurred and was processed
+	}
+}
+
+// ReorgsData will return the detected chain reorganizations in json
+func ReorgsData(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	q := r.URL.Query()
+
+	draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
+	if err != nil {
+		logger.Warnf("error converting datatables draw parameter from string to int: %v", err)
+		http.Error(w, "Error: Missing or invalid parameter draw", http.StatusBadRequest)
+		return
+	}
+	start, err := strconv.ParseUint(q.Get("start"), 10, 64)
+	if err != nil {
+		logger.Warnf("error converting datatables start parameter from string to int: %v", err)
+		http.Error(w, "Error: Missing or invalid parameter start", http.StatusBadRequest)
+		return
+	}
+	length, err := strconv.ParseUint(q.Get("length"), 10, 64)
+	if err != nil {
+		logger.Warnf("error converting datatables length parameter from string to int: %v", err)
+		http.Error(w, "Error: Missing or invalid parameter length", http.StatusBadRequest)
+		return
+	}
+	if length > 100 {
+		length = 100
+	}
+
+	reorgs, err := db.GetReorgs(length, start)
+	if err != nil {
+		logger.Errorf("error retrieving reorgs from the database: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	validatorsForNameSearch := []uint64{}
+	for _, reorg := range reorgs {
+		validatorsForNameSearch = append(validatorsForNameSearch, reorg.OldHeadProposer, reorg.NewHeadProposer)
+		for _, p := range reorg.OrphanedProposers {
+			validatorsForNameSearch = append(validatorsForNameSearch, uint64(p))
+		}
+	}
+	validatorNames, err := db.GetValidatorNames(validatorsForNameSearch)
+	if err != nil {
+		logger.Errorf("error retrieving validator names from the database: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	tableData := make([][]interface{}, 0, len(reorgs))
+	for _, reorg := range reorgs {
+		orphanedSlots := make([]string, 0, len(reorg.OrphanedSlots))
+		for _, slot := range reorg.OrphanedSlots {
+			orphanedSlots = append(orphanedSlots, string(utils.FormatBlockSlot(uint64(slot))))
+		}
+		orphanedProposers := make([]uint64, 0, len(reorg.OrphanedProposers))
+		for _, p := range reorg.OrphanedProposers {
+			orphanedProposers = append(orphanedProposers, uint64(p))
+		}
+
+		tableData = append(tableData, []interface{}{
+			utils.FormatBlockSlot(reorg.Slot),
+			utils.FormatTimestamp(reorg.DetectedAt.Unix()),
+			reorg.Depth,
+			utils.FormatBlockRoot(reorg.OldHeadRoot),
+			utils.FormatValidatorWithName(reorg.OldHeadProposer, validatorNames[reorg.OldHeadProposer]),
+			utils.FormatBlockRoot(reorg.NewHeadRoot),
+			utils.FormatValidatorWithName(reorg.NewHeadProposer, validatorNames[reorg.NewHeadProposer]),
+			template.HTML(strings.Join(orphanedSlots, ", ")),
+			formatValidatorsWithName(orphanedProposers, validatorNames),
+		})
+	}
+
+	records, err := db.GetReorgCount()
+	if err != nil {
+		logger.Errorf("error retrieving reorg count: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	data := &types.DataTableResponse{
+		Draw:            draw,
+		RecordsTotal:    records,
+		RecordsFiltered: records,
+		Data:            tableData,
+	}
+
+	err = json.NewEncoder(w).Encode(data)
+	if err != nil {
+		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func formatValidatorsWithName(validators []uint64, nameMap map[uint64]string) template.HTML {
+	vals := make([]string, 0, len(validators))
+	for _, v := range validators {
+		vals = append(vals, string(utils.FormatValidatorWithName(v, nameMap[v])))
+	}
+	return template.HTML(strings.Join(vals, ", "))
+}
@@ -247,7 +247,10 @@ func GetSlotPageData(blockSlot uint64) (*types.BlockPageData, error) {
 			jsonb_agg(tags.metadata) as tags,
 			COALESCE(not 'invalid-relay-reward'=ANY(array_agg(tags.id)), true) as is_valid_mev,
 			COALESCE(validator_names.name, '') AS name,
-			(SELECT count(*) from blocks_bls_change where block_slot = $1) as bls_change_count
+			(SELECT count(*) from blocks_bls_change where block_slot = $1) as bls_change_count,
+			(SELECT count(*) from blocks_deposit_requests where block_slot = $1 AND block_root = blocks.blockroot) as deposit_requests_count,
+			(SELECT count(*) from blocks_withdrawal_requests where block_slot = $1 AND block_root = blocks.blockroot) as withdrawal_requests_count,
+			(SELECT count(*) from blocks_consolidation_requests where block_slot = $1 AND block_root = blocks.blockroot) as consolidation_requests_count
 		FROM blocks 
 		LEFT JOIN validators ON blocks.proposer = validators.validatorindex
 		LEFT JOIN validator_names ON validators.pubkey = validator_names.publickey
@@ -844,6 +847,115 @@ func SlotBlsChangeData(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func SlotDepositRequestsData(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	currency := GetCurrency(r)
+	slot, err := strconv.ParseUint(vars["slot"], 10, 64)
+	if err != nil || slot > math.MaxInt32 {
+		logger.Warnf("error parsing slot url parameter %v: %v", vars["slot"], err)
+		http.Error(w, "Error: Invalid parameter slot.", http.StatusBadRequest)
+		return
+	}
+	requests, err := db.GetSlotDepositRequests(slot)
+	if err != nil {
+		logger.Errorf("error retrieving deposit requests for slot %v, err: %v", slot, err)
+	}
+
+	tableData := make([][]interface{}, 0, len(requests))
+	for _, d := range requests {
+		tableData = append(tableData, []interface{}{
+			template.HTML(fmt.Sprintf("%v", d.DepositIndex)),
+			formatRequestValidator(d.ValidatorIndex, d.Pubkey),
+			utils.FormatWithdawalCredentials(d.WithdrawalCredentials, true),
+			utils.FormatClCurrency(d.Amount, currency, 6, true, false, false, true),
+			utils.FormatHashWithCopy(d.Signature),
+		})
+	}
+
+	writeSlotRequestsData(w, r, tableData)
+}
+
+func SlotWithdrawalRequestsData(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	currency := GetCurrency(r)
+	slot, err := strconv.ParseUint(vars["slot"], 10, 64)
+	if err != nil || slot > math.MaxInt32 {
+		logger.Warnf("error parsing slot url parameter %v: %v", vars["slot"], err)
+		http.Error(w, "Error: Invalid parameter slot.", http.StatusBadRequest)
+		return
+	}
+	requests, err := db.GetSlotWithdrawalRequests(slot)
+	if err != nil {
+		logger.Errorf("error retrieving withdrawal requests for slot %v, err: %v", slot, err)
+	}
+
+	tableData := make([][]interface{}, 0, len(requests))
+	for _, req := range requests {
+		amount := template.HTML("Full Exit")
+		if req.Amount > 0 {
+			amount = utils.FormatClCurrency(req.Amount, currency, 6, true, false, false, true)
+		}
+		tableData = append(tableData, []interface{}{
+			formatRequestValidator(req.ValidatorIndex, req.ValidatorPubkey),
+			utils.FormatAddress(req.SourceAddress, nil, "", false, false, true),
+			amount,
+		})
+	}
+
+	writeSlotRequestsData(w, r, tableData)
+}
+
+func SlotConsolidationRequestsData(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	slot, err := strconv.ParseUint(vars["slot"], 10, 64)
+	if err != nil || slot > math.MaxInt32 {
+		logger.Warnf("error parsing slot url parameter %v: %v", vars["slot"], err)
+		http.Error(w, "Error: Invalid parameter slot.", http.StatusBadRequest)
+		return
+	}
+	requests, err := db.GetSlotConsolidationRequests(slot)
+	if err != nil {
+		logger.Errorf("error retrieving consolidation requests for slot %v, err: %v", slot, err)
+	}
+
+	tableData := make([][]interface{}, 0, len(requests))
+	for _, c := range requests {
+		tableData = append(tableData, []interface{}{
+			formatRequestValidator(c.SourceValidatorIndex, c.SourcePubkey),
+			formatRequestValidator(c.TargetValidatorIndex, c.TargetPubkey),
+			utils.FormatAddress(c.SourceAddress, nil, "", false, false, true),
+		})
+	}
+
+	writeSlotRequestsData(w, r, tableData)
+}
+
+// formatRequestValidator links the validator of an execution layer request by index or, if it has none yet, by public key
+func formatRequestValidator(index *uint64, pubkey []byte) template.HTML {
+	if index == nil {
+		return utils.FormatPublicKey(pubkey)
+	}
+	return utils.FormatValidator(*index)
+}
+
+func writeSlotRequestsData(w http.ResponseWriter, r *http.Request, tableData [][]interface{}) {
+	data := &types.DataTableResponse{
+		Draw:         1,
+		RecordsTotal: uint64(len(tableData)),
+		Data:         tableData,
+	}
+
+	err := json.NewEncoder(w).Encode(data)
+	if err != nil {
+		logger.Errorf("error encoding json response for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
 // ToWei converts the big.Int wei to its gwei string representation.
 func ToWei(wei *big.Int) string {
 	return wei.String()
@@ -288,6 +288,9 @@ func StripeCustomerPortal(w http.ResponseWriter, r *http.Request) {
 }
 
 // StripeWebhook receive events from stripe webhook service
+// @Summary Receive the events of the stripe webhook service
+// @Tags Misc
+// @Router /api/v1/stripe/webhook [post]
 func StripeWebhook(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
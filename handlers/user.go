@@ -15,6 +15,7 @@ import (
 
 	"github.com/gobitfly/eth2-beaconchain-explorer/db"
 	"github.com/gobitfly/eth2-beaconchain-explorer/mail"
+	"github.com/gobitfly/eth2-beaconchain-explorer/notify"
 	"github.com/gobitfly/eth2-beaconchain-explorer/ratelimit"
 	"github.com/gobitfly/eth2-beaconchain-explorer/services"
 	"github.com/gobitfly/eth2-beaconchain-explorer/templates"
@@ -479,17 +480,25 @@ func UserNotificationsCenter(w http.ResponseWriter, r *http.Request) {
 		if sub.EventName == utils.GetNetwork()+":"+string(types.ValidatorIsOfflineEventName) ||
 			sub.EventName == utils.GetNetwork()+":"+string(types.ValidatorMissedProposalEventName) ||
 			sub.EventName == utils.GetNetwork()+":"+string(types.ValidatorExecutedProposalEventName) ||
+			sub.EventName == utils.GetNetwork()+":"+string(types.ValidatorUpcomingProposalEventName) ||
 			sub.EventName == utils.GetNetwork()+":"+string(types.ValidatorGotSlashedEventName) ||
 			sub.EventName == utils.GetNetwork()+":"+string(types.SyncCommitteeSoon) ||
 			sub.EventName == utils.GetNetwork()+":"+string(types.ValidatorMissedAttestationEventName) ||
-			sub.EventName == utils.GetNetwork()+":"+string(types.ValidatorReceivedWithdrawalEventName) {
+			sub.EventName == utils.GetNetwork()+":"+string(types.ValidatorReceivedWithdrawalEventName) ||
+			sub.EventName == utils.GetNetwork()+":"+string(types.ValidatorConsolidatedEventName) ||
+			sub.EventName == utils.GetNetwork()+":"+string(types.ValidatorExecutionLayerExitEventName) ||
+			sub.EventName == utils.GetNetwork()+":"+string(types.ValidatorIncomeBelowNetworkEventName) {
 			typeCount.Validator++
 		} else if sub.EventName == string(types.MonitoringMachineOfflineEventName) ||
 			sub.EventName == string(types.MonitoringMachineDiskAlmostFullEventName) ||
 			sub.EventName == string(types.MonitoringMachineCpuLoadEventName) ||
 			sub.EventName == string(types.MonitoringMachineMemoryUsageEventName) ||
 			sub.EventName == string(types.MonitoringMachineSwitchedToETH2FallbackEventName) ||
-			sub.EventName == string(types.MonitoringMachineSwitchedToETH1FallbackEventName) {
+			sub.EventName == string(types.MonitoringMachineSwitchedToETH1FallbackEventName) ||
+			sub.EventName == string(types.MonitoringMachineBeaconNodeNotSyncedEventName) ||
+			sub.EventName == string(types.MonitoringMachineExecutionBehindEventName) ||
+			sub.EventName == string(types.MonitoringMachinePeerCountLowEventName) ||
+			sub.EventName == string(types.MonitoringMachineClientOutdatedEventName) {
 			typeCount.Monitoring++
 		} else if sub.EventName == utils.GetNetwork()+":"+string(types.NetworkSlashingEventName) ||
 			sub.EventName == utils.GetNetwork()+":"+string(types.NetworkValidatorActivationQueueFullEventName) ||
@@ -609,7 +618,10 @@ func UserNotificationsCenter(w http.ResponseWriter, r *http.Request) {
 	err = db.FrontendReaderDB.Select(&notificationChannels, `
 		SELECT
 			channel,
-			active
+			active,
+			delivery_mode,
+			quiet_hours_start,
+			quiet_hours_end
 		FROM
 			users_notification_channels
 		WHERE
@@ -637,23 +649,59 @@ func UserNotificationsCenter(w http.ResponseWriter, r *http.Request) {
 
 	if !email {
 		notificationChannels = append(notificationChannels, types.UserNotificationChannels{
-			Channel: types.EmailNotificationChannel,
-			Active:  true,
+			Channel:      types.EmailNotificationChannel,
+			Active:       true,
+			DeliveryMode: types.ImmediateDeliveryMode,
 		})
 	}
 	if !push {
 		notificationChannels = append(notificationChannels, types.UserNotificationChannels{
-			Channel: types.PushNotificationChannel,
-			Active:  true,
+			Channel:      types.PushNotificationChannel,
+			Active:       true,
+			DeliveryMode: types.ImmediateDeliveryMode,
 		})
 	}
 	if !webhook {
 		notificationChannels = append(notificationChannels, types.UserNotificationChannels{
-			Channel: types.WebhookNotificationChannel,
-			Active:  true,
+			Channel:      types.WebhookNotificationChannel,
+			Active:       true,
+			DeliveryMode: types.ImmediateDeliveryMode,
 		})
 	}
 
+	chats, err := db.GetUserChatChannels(user.UserID)
+	if err != nil {
+		logger.Errorf("error retrieving chat channels for user %v: %v ", user.UserID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	for _, chat := range chats {
+		exists := false
+		for _, ch := range notificationChannels {
+			if ch.Channel == chat.Channel {
+				exists = true
+			}
+		}
+		if !exists {
+			notificationChannels = append(notificationChannels, types.UserNotificationChannels{
+				Channel:      chat.Channel,
+				Active:       true,
+				DeliveryMode: types.ImmediateDeliveryMode,
+			})
+		}
+	}
+	for i := range notificationChannels {
+		notificationChannels[i].SupportsDigest = types.IsDigestNotificationChannel(notificationChannels[i].Channel)
+	}
+
+	var notificationsTimezone sql.NullString
+	err = db.FrontendReaderDB.Get(&notificationsTimezone, `SELECT notifications_timezone FROM users WHERE id = $1`, user.UserID)
+	if err != nil {
+		logger.Errorf("error retrieving notifications timezone for user %v: %v ", user.UserID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
 	events := make([]types.EventNameCheckbox, 0)
 	for _, ev := range types.AddWatchlistEvents {
 		events = append(events, types.EventNameCheckbox{
@@ -692,9 +740,16 @@ func UserNotificationsCenter(w http.ResponseWriter, r *http.Request) {
 		Events:    events,
 	}
 
+	hours := make([]int, 24)
+	for i := range hours {
+		hours[i] = i
+	}
 	userNotificationsCenterData.NotificationChannelsModal = types.NotificationChannelsModal{
 		CsrfField:            csrf.TemplateField(r),
 		NotificationChannels: notificationChannels,
+		Timezone:             notificationsTimezone.String,
+		DeliveryModes:        types.NotificationDeliveryModes,
+		Hours:                hours,
 	}
 	userNotificationsCenterData.NetworkEventModal = types.NetworkEventModal{
 		CsrfField: csrf.TemplateField(r),
@@ -1355,9 +1410,9 @@ func UserConfirmUpdateEmail(w http.ResponseWriter, r *http.Request) {
 // @Summary  subscribes a user to get notifications from a specific validator
 // @Tags User
 // @Produce  json
-// @Param pubKey query string true "Public Key of validator you want to subscribe to"
-// @Param balance_decreases body string false "Submit \"on\" to enable notifications for this event"
-// @Param validator_slashed body string false "Submit \"on\" to enable notifications for this event"
+// @Param pubkey path string true "Public Key of validator you want to subscribe to"
+// @Param balance_decreases formData string false "Submit \"on\" to enable notifications for this event"
+// @Param validator_slashed formData string false "Submit \"on\" to enable notifications for this event"
 // @Success 200 {object} types.ApiResponse
 // @Failure 400 {object} types.ApiResponse
 // @Failure 500 {object} types.ApiResponse
@@ -1589,7 +1644,7 @@ func UserDashboardWatchlistRemove(w http.ResponseWriter, r *http.Request) {
 // @Summary  unsubscribes a user from a specific validator
 // @Tags User
 // @Produce  json
-// @Param pubKey query string true "Public Key of validator you want to subscribe to"
+// @Param pubkey path string true "Public Key of validator you want to unsubscribe from"
 // @Success 200 {object} types.ApiResponse
 // @Failure 400 {object} types.ApiResponse
 // @Failure 500 {object} types.ApiResponse
@@ -1637,6 +1692,18 @@ func UserValidatorWatchlistRemove(w http.ResponseWriter, r *http.Request) {
 	RedirectOrJSONOKResponse(w, r, "/validator/"+pubKey, http.StatusSeeOther)
 }
 
+// UserNotificationsSubscribe godoc
+// @Summary Subscribe to an event
+// @Tags User
+// @Produce json
+// @Param event query string true "Name of the event"
+// @Param filter query string false "Filter of the event, e.g. a validator pubkey"
+// @Param threshold query number false "Threshold of the event"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/notifications/subscribe [post]
 func UserNotificationsSubscribe(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
 	event := q.Get("event")
@@ -1650,6 +1717,16 @@ func UserNotificationsSubscribe(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// MultipleUsersNotificationsSubscribe godoc
+// @Summary Subscribe to multiple events
+// @Tags User
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/notifications/bundled/subscribe [post]
 func MultipleUsersNotificationsSubscribe(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -1803,11 +1880,26 @@ func internUserNotificationsSubscribe(event, filter string, threshold float64, w
 			threshold = 0.6
 		} else if eventName == types.MonitoringMachineMemoryUsageEventName {
 			threshold = 0.8
+		} else if eventName == types.MonitoringMachineBeaconNodeNotSyncedEventName {
+			threshold = types.MachineBeaconNodeSyncLagSlotsDefault
+		} else if eventName == types.MonitoringMachineExecutionBehindEventName {
+			threshold = types.MachineExecutionLagBlocksDefault
+		} else if eventName == types.MonitoringMachinePeerCountLowEventName {
+			threshold = types.MachinePeerCountDefault
 		} else if eventName == types.ValidatorIsOfflineEventName {
 			threshold = 3
+		} else if eventName == types.ValidatorIncomeBelowNetworkEventName {
+			threshold = 0 // the default threshold
 		}
 		// rocketpool thresholds are free
 	}
+	if eventName == types.ValidatorIncomeBelowNetworkEventName {
+		threshold, err = incomeBelowNetworkThreshold(threshold)
+		if err != nil {
+			ErrorOrJSONResponse(w, r, err.Error(), http.StatusBadRequest)
+			return false
+		}
+	}
 
 	filterLen := len(filter)
 	if filterLen == 0 && !strings.HasPrefix(string(eventName), "monitoring_") && !strings.HasPrefix(string(eventName), "rocketpool_") { // no filter = add all my watched validators
@@ -1898,6 +1990,28 @@ func internUserNotificationsSubscribe(event, filter string, threshold float64, w
 	return true
 }
 
+// incomeBelowNetworkThreshold converts the percentage of the network income a user entered for the income below network event
+// into the share stored as threshold of the subscription, 80 means 80%. No percentage selects the default threshold.
+func incomeBelowNetworkThreshold(percent float64) (float64, error) {
+	if percent == 0 {
+		return types.ValidatorIncomeBelowNetworkThresholdDefault, nil
+	}
+	if percent < 1 || percent > 99 {
+		return 0, fmt.Errorf("invalid threshold %v, the income threshold is a percentage of the network income between 1 and 99", percent)
+	}
+	return percent / 100, nil
+}
+
+// MultipleUsersNotificationsUnsubscribe godoc
+// @Summary Unsubscribe from multiple events
+// @Tags User
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/notifications/bundled/unsubscribe [post]
 func MultipleUsersNotificationsUnsubscribe(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -2044,6 +2158,17 @@ func internUserNotificationsUnsubscribe(event, filter string, w http.ResponseWri
 	return true
 }
 
+// UserNotificationsUnsubscribe godoc
+// @Summary Unsubscribe from an event
+// @Tags User
+// @Produce json
+// @Param event query string true "Name of the event"
+// @Param filter query string false "Filter of the event, e.g. a validator pubkey"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/notifications/unsubscribe [post]
 func UserNotificationsUnsubscribe(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
 	user := getUser(r)
@@ -2253,6 +2378,7 @@ type UsersNotificationsRequest struct {
 // @Failure 500 {object} types.ApiResponse
 // @Security ApiKeyAuth
 // @Router /api/v1/user/notifications [post]
+// @Router /api/v1/user/notifications [get]
 func UserNotificationsSubscribed(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	j := json.NewEncoder(w)
@@ -2453,7 +2579,8 @@ func NotificationWebhookPage(w http.ResponseWriter, r *http.Request) {
 			event_names,
 			destination,
 			request,
-			response
+			response,
+			secret
 		FROM users_webhooks
 		WHERE user_id = $1;
 	`, user.UserID)
@@ -2494,6 +2621,11 @@ func NotificationWebhookPage(w http.ResponseWriter, r *http.Request) {
 			EventName:  types.ValidatorExecutedProposalEventName,
 			Active:     utils.ElementExists(wh.EventNames, string(types.ValidatorExecutedProposalEventName)),
 		})
+		events = append(events, types.EventNameCheckbox{
+			EventLabel: "Proposal Upcoming",
+			EventName:  types.ValidatorUpcomingProposalEventName,
+			Active:     utils.ElementExists(wh.EventNames, string(types.ValidatorUpcomingProposalEventName)),
+		})
 		events = append(events, types.EventNameCheckbox{
 			EventLabel: "Withdrawal",
 			EventName:  types.ValidatorReceivedWithdrawalEventName,
@@ -2529,6 +2661,11 @@ func NotificationWebhookPage(w http.ResponseWriter, r *http.Request) {
 			EventName:  types.MonitoringMachineCpuLoadEventName,
 			Active:     utils.ElementExists(wh.EventNames, string(types.MonitoringMachineCpuLoadEventName)),
 		})
+		events = append(events, types.EventNameCheckbox{
+			EventLabel: "Notification Rule",
+			EventName:  types.NotificationRuleEventName,
+			Active:     utils.ElementExists(wh.EventNames, string(types.NotificationRuleEventName)),
+		})
 
 		isDiscord := false
 
@@ -2570,6 +2707,7 @@ func NotificationWebhookPage(w http.ResponseWriter, r *http.Request) {
 			LastSent:     ls,
 			Events:       events,
 			Discord:      isDiscord,
+			Signed:       wh.Secret.Valid && wh.Secret.String != "",
 			CsrfField:    csrf.TemplateField(r),
 			WebhookError: whErr,
 		})
@@ -2595,6 +2733,10 @@ func NotificationWebhookPage(w http.ResponseWriter, r *http.Request) {
 		EventLabel: "Proposal Submitted",
 		EventName:  types.ValidatorExecutedProposalEventName,
 	})
+	events = append(events, types.EventNameCheckbox{
+		EventLabel: "Proposal Upcoming",
+		EventName:  types.ValidatorUpcomingProposalEventName,
+	})
 	events = append(events, types.EventNameCheckbox{
 		EventLabel: "Withdrawal",
 		EventName:  types.ValidatorReceivedWithdrawalEventName,
@@ -2623,6 +2765,10 @@ func NotificationWebhookPage(w http.ResponseWriter, r *http.Request) {
 		EventLabel: "Machine CPU",
 		EventName:  types.MonitoringMachineCpuLoadEventName,
 	})
+	events = append(events, types.EventNameCheckbox{
+		EventLabel: "Notification Rule",
+		EventName:  types.NotificationRuleEventName,
+	})
 
 	pageData.Events = events
 
@@ -2663,6 +2809,7 @@ func UsersAddWebhook(w http.ResponseWriter, r *http.Request) {
 	validatorIsOffline := r.FormValue(string(types.ValidatorIsOfflineEventName)) == "on"
 	validatorProposalMissed := r.FormValue(string(types.ValidatorMissedProposalEventName)) == "on"
 	validatorProposalSubmitted := r.FormValue(string(types.ValidatorExecutedProposalEventName)) == "on"
+	validatorProposalUpcoming := r.FormValue(string(types.ValidatorUpcomingProposalEventName)) == "on"
 	validatorReceivedWithdrawal := r.FormValue(string(types.ValidatorReceivedWithdrawalEventName)) == "on"
 	validatorGotSlashed := r.FormValue(string(types.ValidatorGotSlashedEventName)) == "on"
 	validatorSyncCommiteeSoon := r.FormValue(string(types.SyncCommitteeSoon)) == "on"
@@ -2670,6 +2817,7 @@ func UsersAddWebhook(w http.ResponseWriter, r *http.Request) {
 	monitoringMachineOffline := r.FormValue(string(types.MonitoringMachineOfflineEventName)) == "on"
 	monitoringHddAlmostfull := r.FormValue(string(types.MonitoringMachineDiskAlmostFullEventName)) == "on"
 	monitoringCpuLoad := r.FormValue(string(types.MonitoringMachineCpuLoadEventName)) == "on"
+	notificationRule := r.FormValue(string(types.NotificationRuleEventName)) == "on"
 	discord := r.FormValue("discord") == "on"
 
 	if discord {
@@ -2683,6 +2831,7 @@ func UsersAddWebhook(w http.ResponseWriter, r *http.Request) {
 	events[string(types.ValidatorIsOfflineEventName)] = validatorIsOffline
 	events[string(types.ValidatorMissedProposalEventName)] = validatorProposalMissed
 	events[string(types.ValidatorExecutedProposalEventName)] = validatorProposalSubmitted
+	events[string(types.ValidatorUpcomingProposalEventName)] = validatorProposalUpcoming
 	events[string(types.ValidatorReceivedWithdrawalEventName)] = validatorReceivedWithdrawal
 	events[string(types.ValidatorGotSlashedEventName)] = validatorGotSlashed
 	events[string(types.SyncCommitteeSoon)] = validatorSyncCommiteeSoon
@@ -2690,6 +2839,7 @@ func UsersAddWebhook(w http.ResponseWriter, r *http.Request) {
 	events[string(types.MonitoringMachineOfflineEventName)] = monitoringMachineOffline
 	events[string(types.MonitoringMachineDiskAlmostFullEventName)] = monitoringHddAlmostfull
 	events[string(types.MonitoringMachineCpuLoadEventName)] = monitoringCpuLoad
+	events[string(types.NotificationRuleEventName)] = notificationRule
 
 	eventNames := make([]string, 0)
 
@@ -2755,7 +2905,20 @@ func UsersAddWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = tx.Exec(`INSERT INTO users_webhooks (user_id, url, event_names, destination) VALUES ($1, $2, $3, $4)`, user.UserID, urlForm, pq.StringArray(eventNames), destination)
+	// discord does not verify signatures, so only plain webhooks get a signing secret
+	secret := sql.NullString{}
+	if destination == "webhook" {
+		secret.String, err = utils.GenerateWebhookSecret()
+		if err != nil {
+			logger.WithError(err).Errorf("error generating webhook secret")
+			utils.SetFlash(w, r, authSessionName, "Error: Something went wrong adding your webhook, please try again in a bit.")
+			http.Redirect(w, r, "/user/webhooks", http.StatusSeeOther)
+			return
+		}
+		secret.Valid = true
+	}
+
+	_, err = tx.Exec(`INSERT INTO users_webhooks (user_id, url, event_names, destination, secret) VALUES ($1, $2, $3, $4, $5)`, user.UserID, urlForm, pq.StringArray(eventNames), destination, secret)
 	if err != nil {
 		logger.WithError(err).Errorf("error inserting a new webhook for user")
 		utils.SetFlash(w, r, authSessionName, "Error: Something went wrong adding your webhook, please try again in a bit.")
@@ -2769,9 +2932,16 @@ func UsersAddWebhook(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/user/webhooks", http.StatusSeeOther)
 		return
 	}
+	if secret.Valid {
+		utils.SetFlash(w, r, authSessionName, webhookSecretFlash(secret.String))
+	}
 	http.Redirect(w, r, "/user/webhooks", http.StatusSeeOther)
 }
 
+func webhookSecretFlash(secret string) string {
+	return fmt.Sprintf("The signing secret of your webhook is <code>%v</code><br>Store it now, it will not be shown again. Use it to verify the <code>X-Webhook-Signature</code> header of the requests we send.", secret)
+}
+
 func UsersEditWebhook(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
 	user := getUser(r)
@@ -2798,6 +2968,7 @@ func UsersEditWebhook(w http.ResponseWriter, r *http.Request) {
 	validatorIsOffline := r.FormValue(string(types.ValidatorIsOfflineEventName)) == "on"
 	validatorProposalMissed := r.FormValue(string(types.ValidatorMissedProposalEventName)) == "on"
 	validatorProposalSubmitted := r.FormValue(string(types.ValidatorExecutedProposalEventName)) == "on"
+	validatorProposalUpcoming := r.FormValue(string(types.ValidatorUpcomingProposalEventName)) == "on"
 	validatorReceivedWithdrawal := r.FormValue(string(types.ValidatorReceivedWithdrawalEventName)) == "on"
 	validatorGotSlashed := r.FormValue(string(types.ValidatorGotSlashedEventName)) == "on"
 	validatorSyncCommiteeSoon := r.FormValue(string(types.SyncCommitteeSoon)) == "on"
@@ -2805,6 +2976,7 @@ func UsersEditWebhook(w http.ResponseWriter, r *http.Request) {
 	monitoringMachineOffline := r.FormValue(string(types.MonitoringMachineOfflineEventName)) == "on"
 	monitoringHddAlmostfull := r.FormValue(string(types.MonitoringMachineDiskAlmostFullEventName)) == "on"
 	monitoringCpuLoad := r.FormValue(string(types.MonitoringMachineCpuLoadEventName)) == "on"
+	notificationRule := r.FormValue(string(types.NotificationRuleEventName)) == "on"
 	discord := r.FormValue("discord") == "on"
 
 	if discord {
@@ -2818,6 +2990,7 @@ func UsersEditWebhook(w http.ResponseWriter, r *http.Request) {
 	events[string(types.ValidatorIsOfflineEventName)] = validatorIsOffline
 	events[string(types.ValidatorMissedProposalEventName)] = validatorProposalMissed
 	events[string(types.ValidatorExecutedProposalEventName)] = validatorProposalSubmitted
+	events[string(types.ValidatorUpcomingProposalEventName)] = validatorProposalUpcoming
 	events[string(types.ValidatorReceivedWithdrawalEventName)] = validatorReceivedWithdrawal
 	events[string(types.ValidatorGotSlashedEventName)] = validatorGotSlashed
 	events[string(types.SyncCommitteeSoon)] = validatorSyncCommiteeSoon
@@ -2825,6 +2998,7 @@ func UsersEditWebhook(w http.ResponseWriter, r *http.Request) {
 	events[string(types.MonitoringMachineOfflineEventName)] = monitoringMachineOffline
 	events[string(types.MonitoringMachineDiskAlmostFullEventName)] = monitoringHddAlmostfull
 	events[string(types.MonitoringMachineCpuLoadEventName)] = monitoringCpuLoad
+	events[string(types.NotificationRuleEventName)] = notificationRule
 
 	eventNames := make([]string, 0)
 
@@ -2912,6 +3086,128 @@ func UsersDeleteWebhook(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/user/webhooks", http.StatusSeeOther)
 }
 
+// UsersRotateWebhookSecret replaces the signing secret of a webhook and shows the new secret once
+func UsersRotateWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+	user := getUser(r)
+
+	webhookID, err := strconv.ParseUint(mux.Vars(r)["webhookID"], 10, 64)
+	if err != nil {
+		utils.SetFlash(w, r, authSessionName, "Error: Invalid webhook.")
+		http.Redirect(w, r, "/user/webhooks", http.StatusSeeOther)
+		return
+	}
+
+	secret, err := db.RotateUserWebhookSecret(user.UserID, webhookID)
+	if err == sql.ErrNoRows {
+		utils.SetFlash(w, r, authSessionName, "Error: Invalid webhook.")
+		http.Redirect(w, r, "/user/webhooks", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		logger.WithError(err).Errorf("error rotating webhook secret for user")
+		utils.SetFlash(w, r, authSessionName, "Error: Something went wrong generating a new secret for your webhook, please try again in a bit.")
+		http.Redirect(w, r, "/user/webhooks", http.StatusSeeOther)
+		return
+	}
+
+	utils.SetFlash(w, r, authSessionName, webhookSecretFlash(secret))
+	http.Redirect(w, r, "/user/webhooks", http.StatusSeeOther)
+}
+
+// NotificationWebhookDeliveriesPage shows the delivery log of a webhook
+func NotificationWebhookDeliveriesPage(w http.ResponseWriter, r *http.Request) {
+	templateFiles := append(layoutTemplateFiles, "user/webhook_deliveries.html")
+	var deliveriesTemplate = templates.GetTemplate(templateFiles...)
+
+	w.Header().Set("Content-Type", "text/html")
+	user := getUser(r)
+
+	webhookID, err := strconv.ParseUint(mux.Vars(r)["webhookID"], 10, 64)
+	if err != nil {
+		utils.SetFlash(w, r, authSessionName, "Error: Invalid webhook.")
+		http.Redirect(w, r, "/user/webhooks", http.StatusSeeOther)
+		return
+	}
+
+	data := InitPageData(w, r, "webhook", "/webhook", "Webhook deliveries", templateFiles)
+	pageData := types.WebhookDeliveriesPageData{
+		WebhookID: webhookID,
+		CsrfField: csrf.TemplateField(r),
+	}
+
+	err = db.FrontendReaderDB.Get(&pageData.Url, `SELECT url FROM users_webhooks WHERE user_id = $1 AND id = $2`, user.UserID, webhookID)
+	if err == sql.ErrNoRows {
+		utils.SetFlash(w, r, authSessionName, "Error: Invalid webhook.")
+		http.Redirect(w, r, "/user/webhooks", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		logger.Errorf("error querying for webhook for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	deliveries, err := db.GetUserWebhookDeliveries(user.UserID, webhookID, 100)
+	if err != nil {
+		logger.Errorf("error querying for webhook deliveries for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	for _, d := range deliveries {
+		payload, err := json.Marshal(d.Content)
+		if err != nil {
+			logger.WithError(err).Errorf("error marshalling payload of webhook delivery %v", d.ID)
+		}
+		pageData.Deliveries = append(pageData.Deliveries, types.WebhookDeliveryRow{UserWebhookDelivery: d, Payload: string(payload)})
+	}
+
+	pageData.Flashes = utils.GetFlashes(w, r, authSessionName)
+	data.Data = pageData
+
+	if handleTemplateError(w, r, "user.go", "NotificationWebhookDeliveriesPage", "", deliveriesTemplate.ExecuteTemplate(w, "layout", data)) != nil {
+		return // an error has occurred and was processed
+	}
+}
+
+// UsersReplayWebhookDelivery schedules a delivery of the delivery log to be sent again
+func UsersReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+	user := getUser(r)
+
+	vars := mux.Vars(r)
+	redirect := fmt.Sprintf("/user/webhooks/%v/deliveries", vars["webhookID"])
+
+	webhookID, err := strconv.ParseUint(vars["webhookID"], 10, 64)
+	if err != nil {
+		utils.SetFlash(w, r, authSessionName, "Error: Invalid webhook.")
+		http.Redirect(w, r, "/user/webhooks", http.StatusSeeOther)
+		return
+	}
+	deliveryID, err := strconv.ParseUint(vars["deliveryID"], 10, 64)
+	if err != nil {
+		utils.SetFlash(w, r, authSessionName, "Error: Invalid delivery.")
+		http.Redirect(w, r, redirect, http.StatusSeeOther)
+		return
+	}
+
+	err = db.ReplayUserWebhookDelivery(user.UserID, webhookID, deliveryID)
+	if err == sql.ErrNoRows {
+		utils.SetFlash(w, r, authSessionName, "Error: The delivery does not exist or is still pending.")
+		http.Redirect(w, r, redirect, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		logger.WithError(err).Errorf("error replaying webhook delivery for user")
+		utils.SetFlash(w, r, authSessionName, "Error: Something went wrong replaying the delivery, please try again in a bit.")
+		http.Redirect(w, r, redirect, http.StatusSeeOther)
+		return
+	}
+
+	utils.SetFlash(w, r, authSessionName, "The delivery has been scheduled and will be sent again shortly.")
+	http.Redirect(w, r, redirect, http.StatusSeeOther)
+}
+
 // UsersNotificationChannel
 // Accepts form encoded values channel and active to set the global notification settings for a user
 func UsersNotificationChannels(w http.ResponseWriter, r *http.Request) {
@@ -2924,10 +3220,6 @@ func UsersNotificationChannels(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	channelEmail := r.FormValue(string(types.EmailNotificationChannel))
-	channelPush := r.FormValue(string(types.PushNotificationChannel))
-	channelWebhook := r.FormValue(string(types.WebhookNotificationChannel))
-
 	tx, err := db.FrontendWriterDB.Beginx()
 	if err != nil {
 		logger.WithError(err).Error("error beginning transaction")
@@ -2936,33 +3228,373 @@ func UsersNotificationChannels(w http.ResponseWriter, r *http.Request) {
 	}
 	defer tx.Rollback()
 
-	_, err = tx.Exec(`INSERT INTO users_notification_channels (user_id, channel, active) VALUES ($1, $2, $3) ON CONFLICT (user_id, channel) DO UPDATE SET active = $3`, user.UserID, types.EmailNotificationChannel, channelEmail == "on")
+	channels := []types.NotificationChannel{types.EmailNotificationChannel, types.PushNotificationChannel, types.WebhookNotificationChannel}
+	// chat channels are only part of the form once they have been set up
+	chats, err := db.GetUserChatChannels(user.UserID)
 	if err != nil {
-		logger.WithError(err).Error("error updating users_notification_channels")
+		logger.WithError(err).Error("error getting chat channels")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	for _, chat := range chats {
+		channels = append(channels, chat.Channel)
+	}
+
+	for _, channel := range channels {
+		active := r.FormValue(string(channel)) == "on"
+		if !types.IsDigestNotificationChannel(channel) {
+			_, err = tx.Exec(`INSERT INTO users_notification_channels (user_id, channel, active) VALUES ($1, $2, $3) ON CONFLICT (user_id, channel) DO UPDATE SET active = $3`, user.UserID, channel, active)
+			if err != nil {
+				logger.WithError(err).Error("error updating users_notification_channels")
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			continue
+		}
+
+		mode, quietHoursStart, quietHoursEnd := parseNotificationDeliveryPreferences(r, channel)
+		_, err = tx.Exec(`
+			INSERT INTO users_notification_channels (user_id, channel, active, delivery_mode, quiet_hours_start, quiet_hours_end) VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (user_id, channel) DO UPDATE SET active = $3, delivery_mode = $4, quiet_hours_start = $5, quiet_hours_end = $6`,
+			user.UserID, channel, active, mode, quietHoursStart, quietHoursEnd)
+		if err != nil {
+			logger.WithError(err).Error("error updating users_notification_channels")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if mode == types.ImmediateDeliveryMode && !quietHoursStart.Valid {
+			// notifications held under the previous preferences go out with the next digest run
+			_, err = tx.Exec(`UPDATE notification_digest_queue SET release_at = now() WHERE user_id = $1 AND channel = $2 AND release_at > now()`, user.UserID, channel)
+			if err != nil {
+				logger.WithError(err).Error("error releasing held notifications")
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	timezone := strings.TrimSpace(r.FormValue("timezone"))
+	if _, err := time.LoadLocation(timezone); err != nil || strings.EqualFold(timezone, "local") {
+		utils.SetFlash(w, r, authSessionName, "Error: Unknown time zone, please use a time zone like Europe/Berlin or America/New_York.")
+	} else {
+		_, err = tx.Exec(`UPDATE users SET notifications_timezone = NULLIF($1, '') WHERE id = $2`, timezone, user.UserID)
+		if err != nil {
+			logger.WithError(err).Error("error updating notifications timezone")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.WithError(err).Error("error committing transaction")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
-	_, err = tx.Exec(`INSERT INTO users_notification_channels (user_id, channel, active) VALUES ($1, $2, $3) ON CONFLICT (user_id, channel) DO UPDATE SET active = $3`, user.UserID, types.PushNotificationChannel, channelPush == "on")
+
+	http.Redirect(w, r, "/user/notifications", http.StatusSeeOther)
+}
+
+// parseNotificationDeliveryPreferences reads the delivery mode and the quiet hours of a channel from the notification channels form,
+// unknown modes fall back to immediate delivery and quiet hours are only set if both hours are valid and differ
+func parseNotificationDeliveryPreferences(r *http.Request, channel types.NotificationChannel) (types.NotificationDeliveryMode, sql.NullInt16, sql.NullInt16) {
+	mode := types.NotificationDeliveryMode(r.FormValue("mode-" + string(channel)))
+	if _, ok := types.NotificationDeliveryModeLabels[mode]; !ok {
+		mode = types.ImmediateDeliveryMode
+	}
+
+	start, errStart := strconv.ParseInt(r.FormValue("quiet-start-"+string(channel)), 10, 16)
+	end, errEnd := strconv.ParseInt(r.FormValue("quiet-end-"+string(channel)), 10, 16)
+	if errStart != nil || errEnd != nil || start < 0 || start > 23 || end < 0 || end > 23 || start == end {
+		return mode, sql.NullInt16{}, sql.NullInt16{}
+	}
+	return mode, sql.NullInt16{Int16: int16(start), Valid: true}, sql.NullInt16{Int16: int16(end), Valid: true}
+}
+
+// UserNotificationChannelsPage renders the setup of the telegram, slack and matrix notification channels
+func UserNotificationChannelsPage(w http.ResponseWriter, r *http.Request) {
+	templateFiles := append(layoutTemplateFiles, "user/notification_channels.html")
+	var channelsTemplate = templates.GetTemplate(templateFiles...)
+
+	w.Header().Set("Content-Type", "text/html")
+	user := getUser(r)
+
+	data := InitPageData(w, r, "user", "/user/notifications/channels", "Notification Channels", templateFiles)
+
+	chats, err := db.GetUserChatChannels(user.UserID)
 	if err != nil {
-		logger.WithError(err).Error("error updating users_notification_channels")
+		logger.Errorf("error retrieving chat channels for user %v: %v ", user.UserID, err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
-	_, err = tx.Exec(`INSERT INTO users_notification_channels (user_id, channel, active) VALUES ($1, $2, $3) ON CONFLICT (user_id, channel) DO UPDATE SET active = $3`, user.UserID, types.WebhookNotificationChannel, channelWebhook == "on")
+	targets := make(map[types.NotificationChannel]string, len(chats))
+	for _, chat := range chats {
+		targets[chat.Channel] = chat.Target
+	}
+
+	telegramBot := utils.Config.Notifications.Telegram.BotName
+	matrixUser := utils.Config.Notifications.Matrix.UserID
+	pageData := types.NotificationChannelsPageData{
+		CsrfField: csrf.TemplateField(r),
+		Channels: []types.ChatChannelSetup{
+			{
+				Channel:      types.TelegramNotificationChannel,
+				Label:        "Telegram",
+				Available:    utils.Config.Notifications.Telegram.BotToken != "",
+				Target:       targets[types.TelegramNotificationChannel],
+				Placeholder:  "Chat ID, e.g. 123456789 or @yourchannel",
+				Instructions: template.HTML(fmt.Sprintf(`Open a chat with <a href="https://t.me/%[1]s" target="_blank" rel="noopener noreferrer">@%[1]s</a> and press start, or add the bot to a group or channel. Then enter the id of the chat, for a private chat this is your numeric telegram user id.`, template.HTMLEscapeString(telegramBot))),
+			},
+			{
+				Channel:      types.SlackNotificationChannel,
+				Label:        "Slack",
+				Available:    true,
+				Target:       targets[types.SlackNotificationChannel],
+				Placeholder:  "https://hooks.slack.com/services/...",
+				Instructions: template.HTML(`Create an <a href="https://api.slack.com/messaging/webhooks" target="_blank" rel="noopener noreferrer">incoming webhook</a> for the slack channel you want to be notified in and enter its url.`),
+			},
+			{
+				Channel:      types.MatrixNotificationChannel,
+				Label:        "Matrix",
+				Available:    utils.Config.Notifications.Matrix.Homeserver != "" && utils.Config.Notifications.Matrix.AccessToken != "",
+				Target:       targets[types.MatrixNotificationChannel],
+				Placeholder:  "!roomid:matrix.org or #alias:matrix.org",
+				Instructions: template.HTML(fmt.Sprintf(`Invite <code>%s</code> into the room you want to be notified in and enter the id or the alias of the room.`, template.HTMLEscapeString(matrixUser))),
+			},
+		},
+	}
+	pageData.Flashes = utils.GetFlashes(w, r, authSessionName)
+	data.Data = pageData
+
+	if handleTemplateError(w, r, "user.go", "UserNotificationChannelsPage", "", channelsTemplate.ExecuteTemplate(w, "layout", data)) != nil {
+		return // an error has occurred and was processed
+	}
+}
+
+// UserNotificationChannelSetup sets the chat of a chat channel after a test message has been delivered to it
+func UserNotificationChannelSetup(w http.ResponseWriter, r *http.Request) {
+	user := getUser(r)
+
+	channel, err := types.GetNotificationChannel(mux.Vars(r)["channel"])
+	if err != nil || !types.IsChatNotificationChannel(channel) {
+		utils.SetFlash(w, r, authSessionName, "Error: Invalid notification channel.")
+		http.Redirect(w, r, "/user/notifications/channels", http.StatusSeeOther)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		utils.LogError(err, "error parsing form", 0)
+		utils.SetFlash(w, r, authSessionName, "Error: Something went wrong setting up the channel, please try again in a bit.")
+		http.Redirect(w, r, "/user/notifications/channels", http.StatusSeeOther)
+		return
+	}
+	target := strings.TrimSpace(r.FormValue("target"))
+
+	switch channel {
+	case types.TelegramNotificationChannel:
+		if !notify.IsValidTelegramChatID(target) {
+			utils.SetFlash(w, r, authSessionName, "Error: The telegram chat id is invalid.")
+			http.Redirect(w, r, "/user/notifications/channels", http.StatusSeeOther)
+			return
+		}
+	case types.SlackNotificationChannel:
+		if !notify.IsValidSlackWebhookURL(target) {
+			utils.SetFlash(w, r, authSessionName, "Error: The url is not a slack incoming webhook.")
+			http.Redirect(w, r, "/user/notifications/channels", http.StatusSeeOther)
+			return
+		}
+	case types.MatrixNotificationChannel:
+		if !notify.IsValidMatrixRoom(target) {
+			utils.SetFlash(w, r, authSessionName, "Error: The matrix room id or alias is invalid.")
+			http.Redirect(w, r, "/user/notifications/channels", http.StatusSeeOther)
+			return
+		}
+		target, err = notify.JoinMatrixRoom(target)
+		if err != nil {
+			logger.WithError(err).Warnf("error joining matrix room of user %v", user.UserID)
+			utils.SetFlash(w, r, authSessionName, fmt.Sprintf("Error: Could not join the matrix room, make sure %v has been invited.", template.HTMLEscapeString(utils.Config.Notifications.Matrix.UserID)))
+			http.Redirect(w, r, "/user/notifications/channels", http.StatusSeeOther)
+			return
+		}
+	}
+
+	chat := types.UserChatChannel{UserID: user.UserID, Channel: channel, Target: target}
+	testMessage := notify.FormatChatMessage(channel, "Notifications enabled", fmt.Sprintf("You will receive your [%[1]v notifications](https://%[1]v/user/notifications) in this chat.", utils.Config.Frontend.SiteDomain))
+	err = notify.SendChatMessage(chat, testMessage, "setup-"+utils.RandomString(16))
+	if err != nil {
+		logger.WithError(err).Warnf("error sending %v test message of user %v", channel, user.UserID)
+		utils.SetFlash(w, r, authSessionName, fmt.Sprintf("Error: Could not send a test message: %v", template.HTMLEscapeString(err.Error())))
+		http.Redirect(w, r, "/user/notifications/channels", http.StatusSeeOther)
+		return
+	}
+
+	err = db.SaveUserChatChannel(chat)
+	if err != nil {
+		logger.WithError(err).Errorf("error saving %v chat of user %v", channel, user.UserID)
+		utils.SetFlash(w, r, authSessionName, "Error: Something went wrong setting up the channel, please try again in a bit.")
+		http.Redirect(w, r, "/user/notifications/channels", http.StatusSeeOther)
+		return
+	}
+	_, err = db.FrontendWriterDB.Exec(`INSERT INTO users_notification_channels (user_id, channel, active) VALUES ($1, $2, true) ON CONFLICT (user_id, channel) DO UPDATE SET active = true`, user.UserID, channel)
 	if err != nil {
 		logger.WithError(err).Error("error updating users_notification_channels")
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+
+	utils.SetFlash(w, r, authSessionName, "A test message has been sent, your notifications will be delivered to this chat from now on.")
+	http.Redirect(w, r, "/user/notifications/channels", http.StatusSeeOther)
+}
+
+// UserNotificationChannelRemove removes the chat of a chat channel
+func UserNotificationChannelRemove(w http.ResponseWriter, r *http.Request) {
+	user := getUser(r)
+
+	channel, err := types.GetNotificationChannel(mux.Vars(r)["channel"])
+	if err != nil || !types.IsChatNotificationChannel(channel) {
+		utils.SetFlash(w, r, authSessionName, "Error: Invalid notification channel.")
+		http.Redirect(w, r, "/user/notifications/channels", http.StatusSeeOther)
 		return
 	}
 
-	err = tx.Commit()
+	err = db.DeleteUserChatChannel(user.UserID, channel)
 	if err != nil {
-		logger.WithError(err).Error("error committing transaction")
+		logger.WithError(err).Errorf("error removing %v chat of user %v", channel, user.UserID)
+		utils.SetFlash(w, r, authSessionName, "Error: Something went wrong removing the channel, please try again in a bit.")
+		http.Redirect(w, r, "/user/notifications/channels", http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/user/notifications/channels", http.StatusSeeOther)
+}
+
+// UserNotificationRulesPage renders the notification rules of the user
+func UserNotificationRulesPage(w http.ResponseWriter, r *http.Request) {
+	templateFiles := append(layoutTemplateFiles, "user/notification_rules.html")
+	var rulesTemplate = templates.GetTemplate(templateFiles...)
+
+	w.Header().Set("Content-Type", "text/html")
+	user := getUser(r)
+
+	data := InitPageData(w, r, "user", "/user/notifications/rules", "Notification Rules", templateFiles)
+
+	rules, err := db.GetUserNotificationRules(user.UserID)
+	if err != nil {
+		logger.Errorf("error retrieving notification rules for user %v: %v ", user.UserID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	groups, err := db.GetUserValidatorGroups(user.UserID)
+	if err != nil {
+		logger.Errorf("error retrieving validator groups for user %v: %v ", user.UserID, err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+	if !utils.SliceContains(groups, "watchlist") {
+		groups = append([]string{"watchlist"}, groups...)
+	}
 
-	http.Redirect(w, r, "/user/notifications", http.StatusSeeOther)
+	pageData := types.NotificationRulesPageData{
+		Rules:        rules,
+		Groups:       groups,
+		Metrics:      types.NotificationRuleMetrics,
+		MetricLabels: types.NotificationRuleMetricLabels,
+		Operators:    types.NotificationRuleOperators,
+		MaxEpochs:    types.NotificationRuleMaxEpochs,
+		CsrfField:    csrf.TemplateField(r),
+	}
+	for i := 0; i < types.NotificationRuleMaxConditions; i++ {
+		pageData.ConditionRows = append(pageData.ConditionRows, i)
+	}
+	pageData.Flashes = utils.GetFlashes(w, r, authSessionName)
+	data.Data = pageData
+
+	if handleTemplateError(w, r, "user.go", "UserNotificationRulesPage", "", rulesTemplate.ExecuteTemplate(w, "layout", data)) != nil {
+		return // an error has occurred and was processed
+	}
+}
+
+// UserNotificationRuleAdd adds a notification rule, the rows of the form without a metric are ignored
+func UserNotificationRuleAdd(w http.ResponseWriter, r *http.Request) {
+	user := getUser(r)
+
+	err := r.ParseForm()
+	if err != nil {
+		utils.LogError(err, "error parsing form", 0)
+		utils.SetFlash(w, r, authSessionName, "Error: Something went wrong adding the rule, please try again in a bit.")
+		http.Redirect(w, r, "/user/notifications/rules", http.StatusSeeOther)
+		return
+	}
+
+	rule := &types.NotificationRule{
+		UserID: user.UserID,
+		Name:   r.FormValue("name"),
+		Match:  r.FormValue("match"),
+	}
+	for i := 0; i < types.NotificationRuleMaxConditions; i++ {
+		metric := r.FormValue(fmt.Sprintf("metric-%d", i))
+		if metric == "" {
+			continue
+		}
+		epochs, _ := strconv.ParseUint(r.FormValue(fmt.Sprintf("epochs-%d", i)), 10, 64)
+		value, err := strconv.ParseFloat(strings.TrimSpace(r.FormValue(fmt.Sprintf("value-%d", i))), 64)
+		if err != nil {
+			utils.SetFlash(w, r, authSessionName, fmt.Sprintf("Error: The value of condition %d is not a number.", i+1))
+			http.Redirect(w, r, "/user/notifications/rules", http.StatusSeeOther)
+			return
+		}
+		rule.Conditions = append(rule.Conditions, types.NotificationRuleCondition{
+			Metric:   types.NotificationRuleMetric(metric),
+			Group:    r.FormValue(fmt.Sprintf("group-%d", i)),
+			Machine:  strings.TrimSpace(r.FormValue(fmt.Sprintf("machine-%d", i))),
+			Epochs:   epochs,
+			Operator: r.FormValue(fmt.Sprintf("operator-%d", i)),
+			Value:    value,
+		})
+	}
+
+	err = rule.Validate()
+	if err != nil {
+		utils.SetFlash(w, r, authSessionName, "Error: "+template.HTMLEscapeString(err.Error()))
+		http.Redirect(w, r, "/user/notifications/rules", http.StatusSeeOther)
+		return
+	}
+
+	_, err = db.AddUserNotificationRule(rule)
+	if err == db.ErrTooManyNotificationRules {
+		utils.SetFlash(w, r, authSessionName, fmt.Sprintf("Error: You can have at most %v notification rules.", types.NotificationRuleMaxPerUser))
+		http.Redirect(w, r, "/user/notifications/rules", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		logger.WithError(err).Errorf("error adding notification rule of user %v", user.UserID)
+		utils.SetFlash(w, r, authSessionName, "Error: Something went wrong adding the rule, please try again in a bit.")
+		http.Redirect(w, r, "/user/notifications/rules", http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/user/notifications/rules", http.StatusSeeOther)
+}
+
+// UserNotificationRuleDelete deletes a notification rule
+func UserNotificationRuleDelete(w http.ResponseWriter, r *http.Request) {
+	user := getUser(r)
+
+	ruleID, err := strconv.ParseUint(mux.Vars(r)["ruleID"], 10, 64)
+	if err != nil {
+		utils.SetFlash(w, r, authSessionName, "Error: Invalid notification rule.")
+		http.Redirect(w, r, "/user/notifications/rules", http.StatusSeeOther)
+		return
+	}
+
+	err = db.DeleteUserNotificationRule(user.UserID, ruleID)
+	if err != nil && err != sql.ErrNoRows {
+		logger.WithError(err).Errorf("error deleting notification rule %v of user %v", ruleID, user.UserID)
+		utils.SetFlash(w, r, authSessionName, "Error: Something went wrong deleting the rule, please try again in a bit.")
+		http.Redirect(w, r, "/user/notifications/rules", http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/user/notifications/rules", http.StatusSeeOther)
 }
 
 // UserSettings renders the user-template
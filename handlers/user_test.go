@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+)
+
+func TestIncomeBelowNetworkThreshold(t *testing.T) {
+	tests := []struct {
+		percent float64
+		want    float64
+		wantErr bool
+	}{
+		{0, types.ValidatorIncomeBelowNetworkThresholdDefault, false},
+		{80, 0.8, false},
+		{1, 0.01, false},
+		{99, 0.99, false},
+		{0.8, 0, true},
+		{100, 0, true},
+		{-5, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := incomeBelowNetworkThreshold(tt.percent)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("incomeBelowNetworkThreshold(%v) = %v, %v, want %v (error %v)", tt.percent, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -89,6 +89,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 	validatorPageData := types.ValidatorPageData{}
 
 	validatorPageData.CappellaHasHappened = latestEpoch >= (utils.Config.Chain.ClConfig.CappellaForkEpoch)
+	validatorPageData.ElectraHasHappened = latestEpoch >= (utils.Config.Chain.ClConfig.ElectraForkEpoch)
 	futureProposalEpoch := uint64(0)
 	futureSyncDutyEpoch := uint64(0)
 
@@ -435,7 +436,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 			validatorPageData.EffectiveBalance = vbalance.EffectiveBalance
 		}
 
-		if bytes.Equal(validatorPageData.WithdrawCredentials[:1], []byte{0x01}) {
+		if utils.HasExecutionWithdrawalCredentials(validatorPageData.WithdrawCredentials) {
 			// validators can have 0x01 credentials even before the cappella fork
 			validatorPageData.IsWithdrawableAddress = true
 		}
@@ -466,9 +467,20 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 				validatorPageData.IsWithdrawableAddress = true
 			}
 
+			if validatorPageData.ElectraHasHappened {
+				validatorPageData.WithdrawalRequests, err = db.GetValidatorWithdrawalRequests(validatorPageData.PublicKey, 10)
+				if err != nil {
+					return fmt.Errorf("error getting validator withdrawal requests from db: %w", err)
+				}
+				validatorPageData.ConsolidationRequests, err = db.GetValidatorConsolidationRequests(validatorPageData.PublicKey, 10)
+				if err != nil {
+					return fmt.Errorf("error getting validator consolidation requests from db: %w", err)
+				}
+			}
+
 			// only calculate the expected next withdrawal if the validator is eligible
 			isFullWithdrawal := validatorPageData.CurrentBalance > 0 && validatorPageData.WithdrawableEpoch <= validatorPageData.Epoch
-			isPartialWithdrawal := validatorPageData.EffectiveBalance == utils.Config.Chain.ClConfig.MaxEffectiveBalance && validatorPageData.CurrentBalance > utils.Config.Chain.ClConfig.MaxEffectiveBalance
+			isPartialWithdrawal := utils.IsPartiallyWithdrawable(validatorPageData.WithdrawCredentials, validatorPageData.EffectiveBalance, validatorPageData.CurrentBalance)
 			if stats != nil && stats.LatestValidatorWithdrawalIndex != nil && stats.TotalValidatorCount != nil && validatorPageData.IsWithdrawableAddress && (isFullWithdrawal || isPartialWithdrawal) {
 				distance, err := GetWithdrawableCountFromCursor(validatorPageData.Epoch, validatorPageData.Index, *stats.LatestValidatorWithdrawalIndex)
 				if err != nil {
@@ -498,7 +510,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 					if isFullWithdrawal {
 						withdrawalAmount = validatorPageData.CurrentBalance
 					} else {
-						withdrawalAmount = validatorPageData.CurrentBalance - utils.Config.Chain.ClConfig.MaxEffectiveBalance
+						withdrawalAmount = validatorPageData.CurrentBalance - utils.MaxEffectiveBalanceForCredentials(validatorPageData.WithdrawCredentials)
 					}
 
 					if latestEpoch == lastWithdrawalsEpoch {
@@ -21,45 +21,43 @@ type MailTemplate struct {
 	Domain string
 }
 
-// SendMail sends an email to the given address with the given message.
-// It will use smtp if configured otherwise it will use gunmail if configured.
+// SendHTMLMail renders the message with the mail layout and sends it to the given address.
+// It uses the configured mail transport, see GetTransport.
 func SendHTMLMail(to, subject string, msg types.Email, attachment []types.EmailAttachment) error {
-	var renderer = templates.GetTemplate("mail/layout.html")
+	transport, err := GetTransport()
+	if err != nil {
+		utils.LogError(err, "error sending reset-email: invalid config for mail-service", 0)
+		return nil
+	}
 
-	var err error
 	var body bytes.Buffer
-
-	if utils.Config.Frontend.Mail.SMTP.User != "" {
-		headers := "MIME-version: 1.0;\nContent-Type: text/html;"
-		body.Write([]byte(fmt.Sprintf("To: %s\r\nSubject: %s\r\n%s\r\n", to, subject, headers)))
-		renderer.Execute(&body, MailTemplate{Mail: msg, Domain: utils.Config.Frontend.SiteDomain})
-
-		fmt.Println("Email Attachments will not work with SMTP server")
-		err = SendMailSMTP(to, body.Bytes())
-	} else if utils.Config.Frontend.Mail.Mailgun.PrivateKey != "" {
-		_ = renderer.ExecuteTemplate(&body, "layout", MailTemplate{Mail: msg, Domain: utils.Config.Frontend.SiteDomain})
-		content := body.String()
-		err = SendMailMailgun(to, subject, content, createTextMessage(msg), attachment)
-	} else {
-		utils.LogError(nil, "error sending reset-email: invalid config for mail-service", 0)
-		err = nil
+	err = templates.GetTemplate("mail/layout.html").ExecuteTemplate(&body, "layout", MailTemplate{Mail: msg, Domain: utils.Config.Frontend.SiteDomain})
+	if err != nil {
+		return fmt.Errorf("error rendering mail template: %w", err)
 	}
-	return err
+
+	return transport.Send(&Message{
+		To:          to,
+		Subject:     subject,
+		HTML:        body.String(),
+		Text:        createTextMessage(msg),
+		Attachments: attachment,
+	})
 }
 
-// SendMail sends an email to the given address with the given message.
-// It will use smtp if configured otherwise it will use gunmail if configured.
+// SendTextMail sends a plain text email to the given address with the given message.
+// It uses the configured mail transport, see GetTransport.
 func SendTextMail(to, subject, msg string, attachment []types.EmailAttachment) error {
-	var err error
-	if utils.Config.Frontend.Mail.SMTP.User != "" {
-		fmt.Println("Email Attachments will not work with SMTP server")
-		err = SendTextMailSMTP(to, subject, msg)
-	} else if utils.Config.Frontend.Mail.Mailgun.PrivateKey != "" {
-		err = SendTextMailMailgun(to, subject, msg, attachment)
-	} else {
-		err = fmt.Errorf("invalid config for mail-service")
+	transport, err := GetTransport()
+	if err != nil {
+		return err
 	}
-	return err
+	return transport.Send(&Message{
+		To:          to,
+		Subject:     subject,
+		Text:        msg,
+		Attachments: attachment,
+	})
 }
 
 func createTextMessage(msg types.Email) string {
@@ -0,0 +1,150 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+)
+
+// FileTransport writes every mail as a MIME message into a maildir instead of sending it.
+// It is meant for development, the mails can be opened with any mail client that reads maildirs.
+type FileTransport struct {
+	dir string
+}
+
+var fileTransportCounter uint64
+
+func NewFileTransport(dir string) *FileTransport {
+	return &FileTransport{dir: dir}
+}
+
+// Send writes the mail to the tmp directory of the maildir first and moves it to the new directory
+// once it is complete, so readers never see partially written mails.
+func (t *FileTransport) Send(msg *Message) error {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		err := os.MkdirAll(filepath.Join(t.dir, sub), 0o755)
+		if err != nil {
+			return fmt.Errorf("error creating maildir %v: %w", t.dir, err)
+		}
+	}
+
+	now := time.Now()
+	raw, err := msg.Bytes(fmt.Sprintf("noreply@%s", utils.Config.Frontend.SiteDomain), now)
+	if err != nil {
+		return err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+	name := fmt.Sprintf("%d.M%dP%dQ%d.%s", now.Unix(), now.Nanosecond()/1000, os.Getpid(), atomic.AddUint64(&fileTransportCounter, 1), hostname)
+
+	tmpPath := filepath.Join(t.dir, "tmp", name)
+	err = os.WriteFile(tmpPath, raw, 0o644)
+	if err != nil {
+		return fmt.Errorf("error writing mail to %v: %w", tmpPath, err)
+	}
+	err = os.Rename(tmpPath, filepath.Join(t.dir, "new", name))
+	if err != nil {
+		return fmt.Errorf("error moving mail %v to the new directory: %w", tmpPath, err)
+	}
+	return nil
+}
+
+// Bytes encodes the mail as a MIME message. The text and the html version are sent as
+// multipart/alternative, attachments are appended as base64 encoded parts.
+func (msg *Message) Bytes(from string, date time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	mixed := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mixed.Boundary())
+
+	var alternativeBuf bytes.Buffer
+	alternative := multipart.NewWriter(&alternativeBuf)
+	err := writeQuotedPrintablePart(alternative, "text/plain; charset=utf-8", msg.Text)
+	if err != nil {
+		return nil, err
+	}
+	if msg.HTML != "" {
+		err = writeQuotedPrintablePart(alternative, "text/html; charset=utf-8", msg.HTML)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = alternative.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error encoding mail: %w", err)
+	}
+
+	part, err := mixed.CreatePart(textproto.MIMEHeader{"Content-Type": {"multipart/alternative; boundary=" + alternative.Boundary()}})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding mail: %w", err)
+	}
+	_, err = part.Write(alternativeBuf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error encoding mail: %w", err)
+	}
+
+	for _, att := range msg.Attachments {
+		contentType := mime.TypeByExtension(filepath.Ext(att.Name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": att.Name})},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error encoding attachment %v: %w", att.Name, err)
+		}
+		encoded := base64.StdEncoding.EncodeToString(att.Attachment)
+		for len(encoded) > 76 {
+			fmt.Fprintf(part, "%s\r\n", encoded[:76])
+			encoded = encoded[76:]
+		}
+		fmt.Fprintf(part, "%s\r\n", encoded)
+	}
+
+	err = mixed.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error encoding mail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintablePart(w *multipart.Writer, contentType, content string) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding mail: %w", err)
+	}
+	qp := quotedprintable.NewWriter(part)
+	_, err = qp.Write([]byte(content))
+	if err != nil {
+		return fmt.Errorf("error encoding mail: %w", err)
+	}
+	err = qp.Close()
+	if err != nil {
+		return fmt.Errorf("error encoding mail: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+)
+
+func TestFileTransport(t *testing.T) {
+	utils.Config = &types.Config{}
+	utils.Config.Frontend.SiteDomain = "beaconcha.in"
+
+	dir := t.TempDir()
+	transport := NewFileTransport(dir)
+	pdf := bytes.Repeat([]byte("%PDF-1.4 income history"), 10)
+	err := transport.Send(&Message{
+		To:          "user@example.com",
+		Subject:     "beaconcha.in: Validator Is Offline – Übersicht",
+		HTML:        "<p>Validator <a href=\"https://beaconcha.in/validator/1\">1</a> is offline.</p>",
+		Text:        "Validator 1 is offline.",
+		Attachments: []types.EmailAttachment{{Name: "income_history.pdf", Attachment: pdf}},
+	})
+	if err != nil {
+		t.Fatalf("error sending mail: %v", err)
+	}
+
+	files, err := os.ReadDir(filepath.Join(dir, "new"))
+	if err != nil {
+		t.Fatalf("error reading maildir: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("got %v mails in the maildir, want 1", len(files))
+	}
+	if tmp, _ := os.ReadDir(filepath.Join(dir, "tmp")); len(tmp) != 0 {
+		t.Errorf("got %v mails left in the tmp directory, want 0", len(tmp))
+	}
+
+	f, err := os.Open(filepath.Join(dir, "new", files[0].Name()))
+	if err != nil {
+		t.Fatalf("error opening mail: %v", err)
+	}
+	defer f.Close()
+	msg, err := mail.ReadMessage(f)
+	if err != nil {
+		t.Fatalf("error parsing mail: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "beaconcha.in: Validator Is Offline – Übersicht" {
+		t.Errorf("got subject %q, %v", subject, err)
+	}
+	if to := msg.Header.Get("To"); to != "user@example.com" {
+		t.Errorf("got recipient %q, want user@example.com", to)
+	}
+
+	parts := map[string]string{}
+	readMultipart(t, msg.Header.Get("Content-Type"), msg.Body, parts)
+	if got := parts["text/plain"]; got != "Validator 1 is offline." {
+		t.Errorf("got text part %q", got)
+	}
+	if got := parts["text/html"]; !strings.Contains(got, `<a href="https://beaconcha.in/validator/1">1</a>`) {
+		t.Errorf("got html part %q", got)
+	}
+	if got := parts["income_history.pdf"]; got != string(pdf) {
+		t.Errorf("got attachment %q, want %q", got, pdf)
+	}
+}
+
+// readMultipart collects the decoded parts of a mail by content type, attachments by file name
+func readMultipart(t *testing.T, contentType string, body io.Reader, parts map[string]string) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatalf("error parsing content type %q: %v", contentType, err)
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		t.Fatalf("got content type %q, want a multipart type", mediaType)
+	}
+	r := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := r.NextRawPart()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatalf("error reading part: %v", err)
+		}
+		partType := part.Header.Get("Content-Type")
+		if strings.HasPrefix(partType, "multipart/") {
+			readMultipart(t, partType, part, parts)
+			continue
+		}
+		var reader io.Reader = part
+		switch part.Header.Get("Content-Transfer-Encoding") {
+		case "quoted-printable":
+			reader = quotedprintable.NewReader(part)
+		case "base64":
+			reader = base64.NewDecoder(base64.StdEncoding, part)
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("error decoding part: %v", err)
+		}
+		key, _, _ := mime.ParseMediaType(partType)
+		if name := part.FileName(); name != "" {
+			key = name
+		}
+		parts[key] = string(content)
+	}
+}
+
+func TestGetTransport(t *testing.T) {
+	utils.Config = &types.Config{}
+	if _, err := GetTransport(); err == nil {
+		t.Errorf("expected an error without configured transport")
+	}
+
+	utils.Config.Frontend.Mail.Mailgun.PrivateKey = "key"
+	if transport, err := GetTransport(); err != nil {
+		t.Errorf("error getting mailgun transport: %v", err)
+	} else if _, ok := transport.(*mailgunTransport); !ok {
+		t.Errorf("got transport %T, want mailgun", transport)
+	}
+
+	utils.Config.Frontend.Mail.Transport = "file"
+	if _, err := GetTransport(); err == nil {
+		t.Errorf("expected an error for the file transport without directory")
+	}
+	utils.Config.Frontend.Mail.File.Directory = t.TempDir()
+	if transport, err := GetTransport(); err != nil {
+		t.Errorf("error getting file transport: %v", err)
+	} else if _, ok := transport.(*FileTransport); !ok {
+		t.Errorf("got transport %T, want file", transport)
+	}
+
+	memory := NewMemoryTransport()
+	SetTransport(memory)
+	defer SetTransport(nil)
+	err := SendTextMail("user@example.com", "Payment Plan Change", "Your plan changed.", nil)
+	if err != nil {
+		t.Fatalf("error sending mail: %v", err)
+	}
+	messages := memory.Messages()
+	if len(messages) != 1 || messages[0].Text != "Your plan changed." || messages[0].HTML != "" {
+		t.Errorf("got messages %+v, want the plain text mail", messages)
+	}
+}
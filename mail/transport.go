@@ -0,0 +1,125 @@
+package mail
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Message is a rendered email as it is handed to a Transport.
+// HTML is empty for plain text mails.
+type Message struct {
+	To          string
+	Subject     string
+	HTML        string
+	Text        string
+	Attachments []types.EmailAttachment
+}
+
+// Transport delivers rendered emails.
+type Transport interface {
+	Send(msg *Message) error
+}
+
+var transportOverride Transport
+var transportOverrideMux = &sync.RWMutex{}
+
+// SetTransport makes all mails go through the given transport instead of the configured one.
+// Passing nil restores the configured transport.
+func SetTransport(transport Transport) {
+	transportOverrideMux.Lock()
+	defer transportOverrideMux.Unlock()
+	transportOverride = transport
+}
+
+// GetTransport returns the transport set with SetTransport or otherwise the one selected by
+// Frontend.Mail.Transport. If no transport is selected smtp is used if a smtp user is configured
+// and mailgun if a mailgun key is configured.
+func GetTransport() (Transport, error) {
+	transportOverrideMux.RLock()
+	defer transportOverrideMux.RUnlock()
+	if transportOverride != nil {
+		return transportOverride, nil
+	}
+
+	cfg := utils.Config.Frontend.Mail
+	switch cfg.Transport {
+	case "smtp":
+		return &smtpTransport{}, nil
+	case "mailgun":
+		return &mailgunTransport{}, nil
+	case "file":
+		if cfg.File.Directory == "" {
+			return nil, fmt.Errorf("invalid config for mail-service: no directory configured for the file transport")
+		}
+		return NewFileTransport(cfg.File.Directory), nil
+	case "":
+		if cfg.SMTP.User != "" {
+			return &smtpTransport{}, nil
+		} else if cfg.Mailgun.PrivateKey != "" {
+			return &mailgunTransport{}, nil
+		}
+		return nil, fmt.Errorf("invalid config for mail-service")
+	}
+	return nil, fmt.Errorf("invalid config for mail-service: unknown transport %q", cfg.Transport)
+}
+
+type smtpTransport struct{}
+
+func (t *smtpTransport) Send(msg *Message) error {
+	if len(msg.Attachments) > 0 {
+		logrus.Warn("Email Attachments will not work with SMTP server")
+	}
+	if msg.HTML == "" {
+		return SendTextMailSMTP(msg.To, msg.Subject, msg.Text)
+	}
+	headers := "MIME-version: 1.0;\nContent-Type: text/html;"
+	body := fmt.Sprintf("To: %s\r\nSubject: %s\r\n%s\r\n%s", msg.To, msg.Subject, headers, msg.HTML)
+	return SendMailSMTP(msg.To, []byte(body))
+}
+
+type mailgunTransport struct{}
+
+func (t *mailgunTransport) Send(msg *Message) error {
+	if msg.HTML == "" {
+		return SendTextMailMailgun(msg.To, msg.Subject, msg.Text, msg.Attachments)
+	}
+	return SendMailMailgun(msg.To, msg.Subject, msg.HTML, msg.Text, msg.Attachments)
+}
+
+// MemoryTransport keeps all sent mails in memory, it is meant to be used in tests.
+type MemoryTransport struct {
+	mux      sync.Mutex
+	messages []*Message
+}
+
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{}
+}
+
+func (t *MemoryTransport) Send(msg *Message) error {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.messages = append(t.messages, msg)
+	return nil
+}
+
+// Messages returns the mails sent so far in the order they have been sent.
+func (t *MemoryTransport) Messages() []*Message {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	messages := make([]*Message, len(t.messages))
+	copy(messages, t.messages)
+	return messages
+}
+
+// Reset drops all mails sent so far.
+func (t *MemoryTransport) Reset() {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.messages = nil
+}
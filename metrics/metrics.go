@@ -1,7 +1,10 @@
 package metrics
 
 import (
+	"bufio"
 	"database/sql"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/pprof"
 	"regexp"
@@ -144,6 +147,17 @@ func (r *responseWriterDelegator) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Hijack lets the handler take over the connection, e.g. to upgrade it to a websocket
+func (r *responseWriterDelegator) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	r.status = http.StatusSwitchingProtocols
+	r.wroteHeader = true
+	return hijacker.Hijack()
+}
+
 // Serve serves prometheus metrics on the given address under /metrics
 func Serve(addr string) error {
 	router := http.NewServeMux()
@@ -0,0 +1,229 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+)
+
+var chatClient = &http.Client{Timeout: time.Second * 10}
+
+var markdownLinkRegex = regexp.MustCompile(`^\[((?:[^\]\\]|\\.)*)\]\(([^)\s]+)\)`)
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "|", `\|`, ">", `\>`)
+
+// EscapeMarkdown escapes user supplied text so that it is shown verbatim when inserted into the markdown info of a notification
+func EscapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// chatLimits are the batching limits of a chat channel, the length is measured on the formatted message
+type chatLimits struct {
+	maxLength        int
+	maxNotifications int
+	separator        string
+	formatSeparator  string
+}
+
+var chatChannelLimits = map[types.NotificationChannel]chatLimits{
+	// telegram rejects messages longer than 4096 characters
+	types.TelegramNotificationChannel: {maxLength: 4096, maxNotifications: 20, separator: "\n\n", formatSeparator: "\n\n"},
+	// slack truncates messages longer than 4000 characters
+	types.SlackNotificationChannel: {maxLength: 4000, maxNotifications: 20, separator: "\n\n", formatSeparator: "\n\n"},
+	// matrix events must not exceed 65KB
+	types.MatrixNotificationChannel: {maxLength: 16000, maxNotifications: 50, separator: "\n\n", formatSeparator: "<br><br>"},
+}
+
+// ChatRateLimitError is returned if the chat service rejected a message because of its rate limit
+type ChatRateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ChatRateLimitError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %v", e.RetryAfter)
+}
+
+// FormatChatMessage formats the title and the markdown info of a notification for a chat channel
+func FormatChatMessage(channel types.NotificationChannel, title, markdown string) types.ChatMessage {
+	msg := types.ChatMessage{
+		Body: title + "\n" + convertMarkdown(markdown, func(s string) string { return s }, func(text, url string) string { return text + " (" + url + ")" }),
+	}
+	switch channel {
+	case types.TelegramNotificationChannel:
+		msg.Formatted = "<b>" + html.EscapeString(title) + "</b>\n" + markdownToHTML(markdown)
+	case types.SlackNotificationChannel:
+		msg.Formatted = "*" + slackEscape(title) + "*\n" + markdownToSlack(markdown)
+	case types.MatrixNotificationChannel:
+		msg.Formatted = "<b>" + html.EscapeString(title) + "</b><br>" + markdownToHTML(markdown)
+	default:
+		msg.Formatted = msg.Body
+	}
+	return msg
+}
+
+// markdownToHTML converts the links of a markdown text into html anchors and escapes everything else
+func markdownToHTML(markdown string) string {
+	return convertMarkdown(markdown, html.EscapeString, func(text, url string) string {
+		return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), html.EscapeString(text))
+	})
+}
+
+// markdownToSlack converts the links of a markdown text into slack mrkdwn links and escapes everything else
+func markdownToSlack(markdown string) string {
+	return convertMarkdown(markdown, slackEscape, func(text, url string) string {
+		return fmt.Sprintf("<%s|%s>", url, slackEscape(text))
+	})
+}
+
+// convertMarkdown converts the links and the text of a markdown text, backslash escaped characters are taken literally
+func convertMarkdown(markdown string, text func(string) string, link func(text, url string) string) string {
+	var b, plain strings.Builder
+	for i := 0; i < len(markdown); i++ {
+		switch markdown[i] {
+		case '\\':
+			if i+1 < len(markdown) {
+				i++
+			}
+			plain.WriteByte(markdown[i])
+		case '[':
+			m := markdownLinkRegex.FindStringSubmatchIndex(markdown[i:])
+			if m == nil {
+				plain.WriteByte('[')
+				continue
+			}
+			b.WriteString(text(plain.String()))
+			plain.Reset()
+			b.WriteString(link(unescapeMarkdown(markdown[i+m[2]:i+m[3]]), markdown[i+m[4]:i+m[5]]))
+			i += m[1] - 1
+		default:
+			plain.WriteByte(markdown[i])
+		}
+	}
+	b.WriteString(text(plain.String()))
+	return b.String()
+}
+
+var markdownUnescapeRegex = regexp.MustCompile(`\\(.)`)
+
+func unescapeMarkdown(s string) string {
+	return markdownUnescapeRegex.ReplaceAllString(s, "$1")
+}
+
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// BatchChatMessages joins the messages of a chat channel into as few messages as the limits of the channel allow
+func BatchChatMessages(channel types.NotificationChannel, messages []types.ChatMessage) []types.ChatMessage {
+	limits, ok := chatChannelLimits[channel]
+	if !ok {
+		return messages
+	}
+
+	batches := []types.ChatMessage{}
+	count := 0
+	for _, m := range messages {
+		if count > 0 {
+			current := &batches[len(batches)-1]
+			if count < limits.maxNotifications && len(current.Formatted)+len(limits.formatSeparator)+len(m.Formatted) <= limits.maxLength {
+				current.Body += limits.separator + m.Body
+				current.Formatted += limits.formatSeparator + m.Formatted
+				count++
+				continue
+			}
+		}
+		batches = append(batches, m)
+		count = 1
+	}
+	return batches
+}
+
+// SendChatMessage sends a message to the chat of a user, txnID identifies the message for services that deduplicate retried requests
+func SendChatMessage(chat types.UserChatChannel, message types.ChatMessage, txnID string) error {
+	switch chat.Channel {
+	case types.TelegramNotificationChannel:
+		return SendTelegramMessage(chat.Target, message)
+	case types.SlackNotificationChannel:
+		return SendSlackMessage(chat.Target, message)
+	case types.MatrixNotificationChannel:
+		return SendMatrixMessage(chat.Target, message, txnID)
+	}
+	return fmt.Errorf("unsupported chat channel %v", chat.Channel)
+}
+
+// doChatRequest sends a json request to a chat service and decodes the json response into res if it is not nil
+func doChatRequest(method, url string, header http.Header, body, res interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("error marshalling request: %w", err)
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := chatClient.Do(req)
+	if err != nil {
+		// the url contains the telegram bot token, so it must not end up in the error
+		var urlErr *neturl.Error
+		if errors.As(err, &urlErr) {
+			return fmt.Errorf("error sending request: %w", urlErr.Err)
+		}
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return fmt.Errorf("error reading response: %w", err)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &ChatRateLimitError{RetryAfter: chatRetryAfter(resp.Header, respBody)}
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %v: %s", resp.Status, respBody)
+	}
+	if res != nil {
+		err = json.Unmarshal(respBody, res)
+		if err != nil {
+			return fmt.Errorf("error decoding response: %w", err)
+		}
+	}
+	return nil
+}
+
+// chatRetryAfter reads the delay a rate limited request can be retried after from the Retry-After header,
+// the telegram parameters or the matrix error of the response
+func chatRetryAfter(header http.Header, body []byte) time.Duration {
+	if retryAfter, err := strconv.Atoi(header.Get("Retry-After")); err == nil {
+		return time.Duration(retryAfter) * time.Second
+	}
+	res := struct {
+		Parameters struct {
+			RetryAfter int64 `json:"retry_after"`
+		} `json:"parameters"`
+		RetryAfterMs int64 `json:"retry_after_ms"`
+	}{}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return 0
+	}
+	if res.Parameters.RetryAfter > 0 {
+		return time.Duration(res.Parameters.RetryAfter) * time.Second
+	}
+	return time.Duration(res.RetryAfterMs) * time.Millisecond
+}
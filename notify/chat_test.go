@@ -0,0 +1,111 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+)
+
+func TestFormatChatMessage(t *testing.T) {
+	markdown := "Validator [1 <a>](https://beaconcha.in/validator/1) missed an attestation"
+
+	tests := []struct {
+		channel   types.NotificationChannel
+		formatted string
+	}{
+		{types.TelegramNotificationChannel, "<b>Missed &amp; Late</b>\nValidator <a href=\"https://beaconcha.in/validator/1\">1 &lt;a&gt;</a> missed an attestation"},
+		{types.SlackNotificationChannel, "*Missed &amp; Late*\nValidator <https://beaconcha.in/validator/1|1 &lt;a&gt;> missed an attestation"},
+		{types.MatrixNotificationChannel, "<b>Missed &amp; Late</b><br>Validator <a href=\"https://beaconcha.in/validator/1\">1 &lt;a&gt;</a> missed an attestation"},
+	}
+	for _, tt := range tests {
+		msg := FormatChatMessage(tt.channel, "Missed & Late", markdown)
+		if msg.Formatted != tt.formatted {
+			t.Errorf("%v: got formatted %q, want %q", tt.channel, msg.Formatted, tt.formatted)
+		}
+		if want := "Missed & Late\nValidator 1 <a> (https://beaconcha.in/validator/1) missed an attestation"; msg.Body != want {
+			t.Errorf("%v: got body %q, want %q", tt.channel, msg.Body, want)
+		}
+	}
+}
+
+func TestBatchChatMessages(t *testing.T) {
+	messages := make([]types.ChatMessage, 45)
+	for i := range messages {
+		messages[i] = types.ChatMessage{Body: "x", Formatted: "x"}
+	}
+	batches := BatchChatMessages(types.TelegramNotificationChannel, messages)
+	if len(batches) != 3 {
+		t.Fatalf("got %v batches, want 3", len(batches))
+	}
+	if got := strings.Count(batches[0].Formatted, "x"); got != 20 {
+		t.Errorf("got %v notifications in the first batch, want 20", got)
+	}
+
+	long := types.ChatMessage{Body: "x", Formatted: strings.Repeat("x", 3000)}
+	batches = BatchChatMessages(types.TelegramNotificationChannel, []types.ChatMessage{long, long})
+	if len(batches) != 2 {
+		t.Errorf("got %v batches for messages exceeding the length limit, want 2", len(batches))
+	}
+}
+
+func TestSendTelegramMessage(t *testing.T) {
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bottoken/sendMessage" {
+			t.Errorf("unexpected path %v", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		if body["chat_id"] == "@limited" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"ok":false,"parameters":{"retry_after":7}}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	prevURL, prevConfig := telegramAPIURL, utils.Config
+	defer func() { telegramAPIURL, utils.Config = prevURL, prevConfig }()
+	telegramAPIURL = srv.URL
+	utils.Config = &types.Config{}
+	utils.Config.Notifications.Telegram.BotToken = "token"
+
+	err := SendTelegramMessage("123", types.ChatMessage{Formatted: "<b>hi</b>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body["text"] != "<b>hi</b>" || body["parse_mode"] != "HTML" {
+		t.Errorf("unexpected request body %v", body)
+	}
+
+	err = SendTelegramMessage("@limited", types.ChatMessage{Formatted: "hi"})
+	var rateLimitErr *ChatRateLimitError
+	if !errors.As(err, &rateLimitErr) || rateLimitErr.RetryAfter != 7*time.Second {
+		t.Errorf("got error %v, want a rate limit error with a delay of 7s", err)
+	}
+}
+
+func TestFormatChatMessageEscapedMarkdown(t *testing.T) {
+	markdown := "Rule [rules](https://beaconcha.in/rules) " + EscapeMarkdown(`[x](https://evil.example) \o/`) + " matched"
+
+	msg := FormatChatMessage(types.TelegramNotificationChannel, "Rule", markdown)
+	if want := "<b>Rule</b>\nRule <a href=\"https://beaconcha.in/rules\">rules</a> [x](https://evil.example) \\o/ matched"; msg.Formatted != want {
+		t.Errorf("got formatted %q, want %q", msg.Formatted, want)
+	}
+	if want := "Rule\nRule rules (https://beaconcha.in/rules) [x](https://evil.example) \\o/ matched"; msg.Body != want {
+		t.Errorf("got body %q, want %q", msg.Body, want)
+	}
+	msg = FormatChatMessage(types.SlackNotificationChannel, "Rule", markdown)
+	if want := "*Rule*\nRule <https://beaconcha.in/rules|rules> [x](https://evil.example) \\o/ matched"; msg.Formatted != want {
+		t.Errorf("got formatted %q, want %q", msg.Formatted, want)
+	}
+}
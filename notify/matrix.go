@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+)
+
+var matrixRoomRegex = regexp.MustCompile(`^[!#][^:\s]+:[^\s]+$`)
+
+// IsValidMatrixRoom checks whether the room is a matrix room id or room alias
+func IsValidMatrixRoom(room string) bool {
+	return matrixRoomRegex.MatchString(room)
+}
+
+func matrixRequest(method, path string, body, res interface{}) error {
+	homeserver := strings.TrimSuffix(utils.Config.Notifications.Matrix.Homeserver, "/")
+	token := utils.Config.Notifications.Matrix.AccessToken
+	if homeserver == "" || token == "" {
+		return fmt.Errorf("matrix homeserver or access token not configured")
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+	return doChatRequest(method, homeserver+path, header, body, res)
+}
+
+// JoinMatrixRoom joins the configured matrix account into a room it has been invited to and returns the id of the room
+func JoinMatrixRoom(room string) (string, error) {
+	res := struct {
+		RoomID string `json:"room_id"`
+	}{}
+	err := matrixRequest(http.MethodPost, "/_matrix/client/v3/join/"+url.PathEscape(room), map[string]interface{}{}, &res)
+	if err != nil {
+		return "", err
+	}
+	return res.RoomID, nil
+}
+
+// SendMatrixMessage sends the message as a notice to a room, the homeserver ignores retried requests with the same txnID
+func SendMatrixMessage(roomID string, message types.ChatMessage, txnID string) error {
+	return matrixRequest(http.MethodPut, fmt.Sprintf("/_matrix/client/v3/rooms/%s/send/m.room.message/%s", url.PathEscape(roomID), url.PathEscape(txnID)), map[string]interface{}{
+		"msgtype":        "m.notice",
+		"body":           message.Body,
+		"format":         "org.matrix.custom.html",
+		"formatted_body": message.Formatted,
+	}, nil)
+}
@@ -0,0 +1,23 @@
+package notify
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+)
+
+// IsValidSlackWebhookURL checks whether the url is a slack incoming webhook
+func IsValidSlackWebhookURL(url string) bool {
+	return utils.IsValidUrl(url) && strings.HasPrefix(url, "https://hooks.slack.com/")
+}
+
+// SendSlackMessage posts the mrkdwn formatted message to a slack incoming webhook
+func SendSlackMessage(webhookURL string, message types.ChatMessage) error {
+	return doChatRequest(http.MethodPost, webhookURL, nil, map[string]interface{}{
+		"text":         message.Formatted,
+		"mrkdwn":       true,
+		"unfurl_links": false,
+	}, nil)
+}
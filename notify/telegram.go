@@ -0,0 +1,34 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+)
+
+var telegramAPIURL = "https://api.telegram.org"
+
+// a chat is either addressed by its numeric id or, for public channels, by its username
+var telegramChatIDRegex = regexp.MustCompile(`^(-?[0-9]{1,20}|@[A-Za-z0-9_]{5,32})$`)
+
+// IsValidTelegramChatID checks whether the chat id has a format telegram accepts
+func IsValidTelegramChatID(chatID string) bool {
+	return telegramChatIDRegex.MatchString(chatID)
+}
+
+// SendTelegramMessage sends the html formatted message to a chat using the configured bot
+func SendTelegramMessage(chatID string, message types.ChatMessage) error {
+	token := utils.Config.Notifications.Telegram.BotToken
+	if token == "" {
+		return fmt.Errorf("telegram bot token not configured")
+	}
+	return doChatRequest(http.MethodPost, fmt.Sprintf("%s/bot%s/sendMessage", telegramAPIURL, token), nil, map[string]interface{}{
+		"chat_id":                  chatID,
+		"text":                     message.Formatted,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}, nil)
+}
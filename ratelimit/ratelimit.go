@@ -1,6 +1,7 @@
 package ratelimit
 
 import (
+	"bufio"
 	"context"
 	"database/sql"
 	"fmt"
@@ -86,8 +87,14 @@ var weightsMu = &sync.RWMutex{}
 var weights = map[string]int64{}  // guarded by weightsMu
 var buckets = map[string]string{} // guarded by weightsMu
 
+var requestWeighersMu = &sync.RWMutex{}
+var requestWeighers = map[string]RequestWeigher{} // guarded by requestWeighersMu, key: path template of the route
+
 var logger = logrus.StandardLogger().WithField("module", "ratelimit")
 
+// RequestWeigher returns the factor the weight of a route is multiplied with for a request, it is used for routes whose cost depends on the request itself
+type RequestWeigher func(r *http.Request) (int64, error)
+
 type DbEntry struct {
 	Date     time.Time
 	UserId   int64
@@ -176,6 +183,17 @@ func (r *responseWriterDelegator) Status() int {
 	return r.status
 }
 
+// Hijack lets the handler take over the connection, e.g. to upgrade it to a websocket
+func (r *responseWriterDelegator) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	r.status = http.StatusSwitchingProtocols
+	r.wroteHeader = true
+	return hijacker.Hijack()
+}
+
 var DefaultRequestFilter = func(req *http.Request) bool {
 	if req.Method == http.MethodOptions {
 		return false
@@ -346,6 +364,27 @@ func HttpMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// LimitRequest counts another call of the route of r against the rate limits of its api key, like HttpMiddleware does for every request.
+// It is used to charge the messages of long lived connections like websockets and returns false if the rate limit is exceeded.
+func LimitRequest(r *http.Request) (bool, error) {
+	if !redisIsHealthy.Load() {
+		metrics.Counter.WithLabelValues("ratelimit_fallback").Inc()
+		return fallbackRateLimiter.Allow(r), nil
+	}
+
+	rl, err := rateLimitRequest(r)
+	if err != nil {
+		// just allow the call if there is a problem with getting the rate limit
+		metrics.Errors.WithLabelValues("ratelimit_rateLimitRequest").Inc()
+		return true, err
+	}
+	if rl.BlockRequest {
+		metrics.Counter.WithLabelValues("ratelimit_block").Inc()
+		return false, postRateLimit(rl, http.StatusTooManyRequests)
+	}
+	return true, nil
+}
+
 // updateWeights gets the weights and buckets from postgres and updates the weights and buckets maps.
 func updateWeights(firstRun bool) error {
 	start := time.Now()
@@ -949,9 +988,29 @@ func getWeight(r *http.Request) (cost int64, identifier, bucket string) {
 	if !bucketOk {
 		bucket = defaultBucket
 	}
+
+	requestWeighersMu.RLock()
+	weigher, weigherOk := requestWeighers[route]
+	requestWeighersMu.RUnlock()
+	if weigherOk {
+		factor, err := weigher(r)
+		if err != nil {
+			// the request is invalid and will be rejected by the handler, so it is only charged with the weight of the route
+			logger.WithError(err).Debugf("error weighing request of route %v", route)
+		} else if factor > 1 {
+			weight *= factor
+		}
+	}
 	return weight, route, bucket
 }
 
+// SetRequestWeigher sets the weigher of the route with the given path template, the weight of every request of the route is multiplied with the factor returned by the weigher
+func SetRequestWeigher(route string, weigher RequestWeigher) {
+	requestWeighersMu.Lock()
+	defer requestWeighersMu.Unlock()
+	requestWeighers[route] = weigher
+}
+
 func getRoute(r *http.Request) string {
 	route := mux.CurrentRoute(r)
 	pathTpl, err := route.GetPathTemplate()
@@ -1024,23 +1083,27 @@ func NewFallbackRateLimiter() *FallbackRateLimiter {
 }
 
 func (rl *FallbackRateLimiter) Handle(w http.ResponseWriter, r *http.Request, next func(writer http.ResponseWriter, request *http.Request)) {
-	key, _ := getKey(r)
-	rl.mu.Lock()
-	if _, found := rl.clients[key]; !found {
-		rl.clients[key] = &FallbackRateLimiterClient{limiter: rate.NewLimiter(FallbackRateLimitSecond, FallbackRateLimitBurst)}
-	}
-	rl.clients[key].lastSeen = time.Now()
-	if !rl.clients[key].limiter.Allow() {
-		rl.mu.Unlock()
+	if !rl.Allow(r) {
 		w.Header().Set(HeaderRateLimitLimit, strconv.FormatInt(FallbackRateLimitSecond, 10))
 		w.Header().Set(HeaderRateLimitReset, strconv.FormatInt(1, 10))
 		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
 		return
 	}
-	rl.mu.Unlock()
 	next(w, r)
 }
 
+// Allow reports whether the key of r is within the fallback rate limit and counts the request
+func (rl *FallbackRateLimiter) Allow(r *http.Request) bool {
+	key, _ := getKey(r)
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if _, found := rl.clients[key]; !found {
+		rl.clients[key] = &FallbackRateLimiterClient{limiter: rate.NewLimiter(FallbackRateLimitSecond, FallbackRateLimitBurst)}
+	}
+	rl.clients[key].lastSeen = time.Now()
+	return rl.clients[key].limiter.Allow()
+}
+
 func DBGetUserApiRateLimit(userId int64) (*RateLimit, error) {
 	rl := &RateLimit{}
 	err := db.FrontendWriterDB.Get(rl, `
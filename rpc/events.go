@@ -0,0 +1,186 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	"github.com/donovanhide/eventsource"
+)
+
+// Topics of the standard beacon node event stream, see https://ethereum.github.io/beacon-APIs/#/Events/eventstream
+const (
+	EventTopicHead                = "head"
+	EventTopicBlock               = "block"
+	EventTopicChainReorg          = "chain_reorg"
+	EventTopicFinalizedCheckpoint = "finalized_checkpoint"
+	EventTopicAttestation         = "attestation"
+)
+
+const (
+	eventStreamMinBackoff = time.Second
+	eventStreamMaxBackoff = time.Minute
+)
+
+// BeaconEvent holds a single event received from the beacon node event stream, only the field matching the topic is set
+type BeaconEvent struct {
+	Topic               string
+	Head                *StreamedHeadEventData
+	Block               *StreamedBlockEventData
+	ChainReorg          *StreamedChainReorgEventData
+	FinalizedCheckpoint *StreamedFinalizedCheckpointEventData
+	Attestation         *Attestation
+}
+
+// EventStream delivers the events of a beacon node event stream subscription
+type EventStream struct {
+	Events    chan *BeaconEvent
+	connected func() bool
+}
+
+// Connected reports whether the underlying stream is currently connected to a node
+func (s *EventStream) Connected() bool {
+	return s.connected()
+}
+
+// SubscribeEvents subscribes to the given topics of the node event stream.
+// The subscription reconnects with an exponential backoff whenever the stream drops.
+func (lc *LighthouseClient) SubscribeEvents(topics ...string) *EventStream {
+	connected := &atomic.Bool{}
+	stream := &EventStream{
+		Events:    make(chan *BeaconEvent, 1024),
+		connected: connected.Load,
+	}
+
+	go func() {
+		backoff := eventStreamMinBackoff
+		for {
+			err := lc.consumeEvents(topics, stream.Events, connected)
+			if connected.Swap(false) {
+				// the stream was established before it dropped, start over with the minimal backoff
+				backoff = eventStreamMinBackoff
+			}
+			utils.LogError(err, fmt.Sprintf("beacon node event stream of %v disconnected, reconnecting in %v", lc.endpoint, backoff), 0)
+
+			time.Sleep(backoff)
+			backoff = min(backoff*2, eventStreamMaxBackoff)
+		}
+	}()
+
+	return stream
+}
+
+// consumeEvents reads the event stream until the first error occurs
+func (lc *LighthouseClient) consumeEvents(topics []string, events chan *BeaconEvent, connected *atomic.Bool) error {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/eth/v1/events?topics=%s", lc.endpoint, strings.Join(topics, ",")), nil)
+	if err != nil {
+		return fmt.Errorf("error initializing event sse request: %w", err)
+	}
+	// disable gzip compression for sse
+	req.Header.Set("accept-encoding", "identity")
+
+	stream, err := eventsource.SubscribeWithRequest("", req)
+	if err != nil {
+		return fmt.Errorf("error subscribing to event stream: %w", err)
+	}
+	defer stream.Close()
+	connected.Store(true)
+	logger.Infof("subscribed to event stream of %v for topics %v", lc.endpoint, topics)
+
+	for {
+		select {
+		case err := <-stream.Errors:
+			return err
+		case e, ok := <-stream.Events:
+			if !ok {
+				return fmt.Errorf("event stream closed")
+			}
+			event, err := parseBeaconEvent(e.Event(), []byte(e.Data()))
+			if err != nil {
+				logger.Warnf("failed to decode %v event: %v", e.Event(), err)
+				continue
+			}
+
+			events <- event
+		}
+	}
+}
+
+func parseBeaconEvent(topic string, data []byte) (*BeaconEvent, error) {
+	event := &BeaconEvent{Topic: topic}
+
+	var err error
+	switch topic {
+	case EventTopicHead:
+		event.Head = &StreamedHeadEventData{}
+		err = json.Unmarshal(data, event.Head)
+	case EventTopicBlock:
+		event.Block = &StreamedBlockEventData{}
+		err = json.Unmarshal(data, event.Block)
+	case EventTopicChainReorg:
+		event.ChainReorg = &StreamedChainReorgEventData{}
+		err = json.Unmarshal(data, event.ChainReorg)
+	case EventTopicFinalizedCheckpoint:
+		event.FinalizedCheckpoint = &StreamedFinalizedCheckpointEventData{}
+		err = json.Unmarshal(data, event.FinalizedCheckpoint)
+	case EventTopicAttestation:
+		event.Attestation = &Attestation{}
+		err = json.Unmarshal(data, event.Attestation)
+	default:
+		return nil, fmt.Errorf("unknown event topic %v", topic)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// eventKey identifies an event independently of the node it was received from
+func (e *BeaconEvent) eventKey() string {
+	switch e.Topic {
+	case EventTopicHead:
+		return e.Topic + e.Head.Block
+	case EventTopicBlock:
+		return e.Topic + e.Block.Block
+	case EventTopicChainReorg:
+		return e.Topic + e.ChainReorg.OldHeadBlock + e.ChainReorg.NewHeadBlock
+	case EventTopicFinalizedCheckpoint:
+		return e.Topic + e.FinalizedCheckpoint.Block
+	case EventTopicAttestation:
+		return e.Topic + e.Attestation.Signature
+	}
+	return e.Topic
+}
+
+type StreamedHeadEventData struct {
+	Slot                      uint64Str `json:"slot"`
+	Block                     string    `json:"block"`
+	State                     string    `json:"state"`
+	EpochTransition           bool      `json:"epoch_transition"`
+	PreviousDutyDependentRoot string    `json:"previous_duty_dependent_root"`
+	CurrentDutyDependentRoot  string    `json:"current_duty_dependent_root"`
+	ExecutionOptimistic       bool      `json:"execution_optimistic"`
+}
+
+type StreamedChainReorgEventData struct {
+	Slot                uint64Str `json:"slot"`
+	Depth               uint64Str `json:"depth"`
+	OldHeadBlock        string    `json:"old_head_block"`
+	NewHeadBlock        string    `json:"new_head_block"`
+	OldHeadState        string    `json:"old_head_state"`
+	NewHeadState        string    `json:"new_head_state"`
+	Epoch               uint64Str `json:"epoch"`
+	ExecutionOptimistic bool      `json:"execution_optimistic"`
+}
+
+type StreamedFinalizedCheckpointEventData struct {
+	Block               string    `json:"block"`
+	State               string    `json:"state"`
+	Epoch               uint64Str `json:"epoch"`
+	ExecutionOptimistic bool      `json:"execution_optimistic"`
+}
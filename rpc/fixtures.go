@@ -0,0 +1,201 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+)
+
+// FixtureMode selects whether a FixtureClient talks to a live node or replays recorded responses
+type FixtureMode int
+
+const (
+	// FixtureModeRecord forwards all requests to the node and stores the responses in the fixture directory
+	FixtureModeRecord FixtureMode = iota
+	// FixtureModeReplay answers all requests from the fixture directory without any network access
+	FixtureModeReplay
+)
+
+// fixtureReplayEndpoint is used as node endpoint while replaying, requests never leave the process
+const fixtureReplayEndpoint = "http://fixtures.invalid"
+
+// FixtureClient is a Lighthouse client that records the raw beacon api responses of a node to a directory
+// or replays them from there, allowing the consumers of the rpc.Client interface to be tested offline
+type FixtureClient struct {
+	*LighthouseClient
+	mode FixtureMode
+}
+
+// NewFixtureClient is used to create a new fixture client for the given directory.
+// The endpoint is only used for recording, in replay mode it may be empty.
+func NewFixtureClient(dir string, mode FixtureMode, endpoint string, chainID *big.Int) (*FixtureClient, error) {
+	transport := &fixtureTransport{
+		dir:  dir,
+		mode: mode,
+		next: http.DefaultTransport,
+	}
+
+	switch mode {
+	case FixtureModeRecord:
+		if endpoint == "" {
+			return nil, fmt.Errorf("no endpoint provided for recording fixtures")
+		}
+		err := os.MkdirAll(dir, 0o755)
+		if err != nil {
+			return nil, fmt.Errorf("error creating fixture directory %v: %w", dir, err)
+		}
+	case FixtureModeReplay:
+		if _, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("error opening fixture directory %v: %w", dir, err)
+		}
+		endpoint = fixtureReplayEndpoint
+	default:
+		return nil, fmt.Errorf("unknown fixture mode %v", mode)
+	}
+
+	client, err := NewLighthouseClient(endpoint, chainID)
+	if err != nil {
+		return nil, err
+	}
+	client.transport = transport
+
+	return &FixtureClient{LighthouseClient: client, mode: mode}, nil
+}
+
+// SubscribeEvents returns a stream that never connects while replaying, consumers fall back to polling
+func (fc *FixtureClient) SubscribeEvents(topics ...string) *EventStream {
+	if fc.mode == FixtureModeReplay {
+		return &EventStream{
+			Events:    make(chan *BeaconEvent),
+			connected: func() bool { return false },
+		}
+	}
+	return fc.LighthouseClient.SubscribeEvents(topics...)
+}
+
+// GetNewBlockChan returns a channel that never receives a block while replaying
+func (fc *FixtureClient) GetNewBlockChan() chan *types.Block {
+	if fc.mode == FixtureModeReplay {
+		return make(chan *types.Block)
+	}
+	return fc.LighthouseClient.GetNewBlockChan()
+}
+
+// fixtureMeta holds the parts of a recorded response besides the body
+type fixtureMeta struct {
+	StatusCode       int    `json:"status_code"`
+	ContentType      string `json:"content_type"`
+	ConsensusVersion string `json:"consensus_version,omitempty"`
+}
+
+// fixtureTransport is a http.RoundTripper that records or replays the responses of the beacon node
+type fixtureTransport struct {
+	dir  string
+	mode FixtureMode
+	next http.RoundTripper
+}
+
+func (t *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := filepath.Join(t.dir, fixtureName(req))
+
+	if t.mode == FixtureModeReplay {
+		return t.replay(req, path)
+	}
+	return t.record(req, path)
+}
+
+func (t *fixtureTransport) record(req *http.Request, path string) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response of %v: %w", req.URL, err)
+	}
+
+	meta, err := json.Marshal(&fixtureMeta{
+		StatusCode:       resp.StatusCode,
+		ContentType:      resp.Header.Get("Content-Type"),
+		ConsensusVersion: resp.Header.Get("Eth-Consensus-Version"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("error creating fixture directory for %v: %w", req.URL, err)
+	}
+	err = os.WriteFile(path, body, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error writing fixture for %v: %w", req.URL, err)
+	}
+	err = os.WriteFile(path+".meta", meta, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error writing fixture metadata for %v: %w", req.URL, err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func (t *fixtureTransport) replay(req *http.Request, path string) (*http.Response, error) {
+	metaData, err := os.ReadFile(path + ".meta")
+	if err != nil {
+		return nil, fmt.Errorf("no fixture recorded for %v %v: %w", req.Method, req.URL.RequestURI(), err)
+	}
+	meta := &fixtureMeta{}
+	err = json.Unmarshal(metaData, meta)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing fixture metadata for %v: %w", req.URL.RequestURI(), err)
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading fixture for %v: %w", req.URL.RequestURI(), err)
+	}
+
+	header := http.Header{}
+	if meta.ContentType != "" {
+		header.Set("Content-Type", meta.ContentType)
+	}
+	if meta.ConsensusVersion != "" {
+		header.Set("Eth-Consensus-Version", meta.ConsensusVersion)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", meta.StatusCode, http.StatusText(meta.StatusCode)),
+		StatusCode:    meta.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// fixtureName maps a request to its fixture file, relative to the fixture directory.
+// The path of the request is kept as directory structure so recorded fixtures can easily be inspected.
+func fixtureName(req *http.Request) string {
+	name := strings.Trim(req.URL.Path, "/")
+	if req.URL.RawQuery != "" {
+		name += "@" + url.QueryEscape(req.URL.RawQuery)
+	}
+	if strings.Contains(req.Header.Get("Accept"), "application/octet-stream") {
+		return filepath.FromSlash(name + ".ssz")
+	}
+	return filepath.FromSlash(name + ".json")
+}
@@ -0,0 +1,56 @@
+package rpc
+
+import (
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testHeaderResponse = `{"execution_optimistic":false,"finalized":true,"data":{"root":"0x4d611d5b93fdab69013a7f0a2f961caca0c853f87cfe9595fe50038163079360","canonical":true,"header":{"message":{"slot":"1","proposer_index":"17","parent_root":"0xcf8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2","state_root":"0x2b3c1c2ba4c7cb00d1ec5bd9b4e3cd0a1e0bd2ce4e2a5bd9b4e3cd0a1e0bd2ce","body_root":"0x6f8e0d4e9587369b2301d0790347320302cc0943d5a1884560367e8208d920f2"},"signature":"0x1b66ac1fb663c9bc59509846d6ec05345bd908eda73e670af888da41af171505cc411d61252fb6cb3fa0017b679f8bb2305b26a285fa2737f175668d0dff91cc1b66ac1fb663c9bc59509846d6ec05345bd908eda73e670af888da41af171505"}}}`
+
+func TestFixtureClientRecordReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/eth/v1/beacon/headers/1" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(testHeaderResponse))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+
+	dir := t.TempDir()
+	recorder, err := NewFixtureClient(dir, FixtureModeRecord, srv.URL, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("error creating recording client: %v", err)
+	}
+	recorded, err := recorder.GetBlockHeader(1)
+	if err != nil {
+		t.Fatalf("error recording header: %v", err)
+	}
+	missed, err := recorder.GetBlockHeader(2)
+	if err != nil || missed != nil {
+		t.Fatalf("expected missed slot to be recorded as not found, got %v, %v", missed, err)
+	}
+	srv.Close()
+
+	replayer, err := NewFixtureClient(dir, FixtureModeReplay, "", big.NewInt(1))
+	if err != nil {
+		t.Fatalf("error creating replaying client: %v", err)
+	}
+	replayed, err := replayer.GetBlockHeader(1)
+	if err != nil {
+		t.Fatalf("error replaying header: %v", err)
+	}
+	if replayed.Data.Root != recorded.Data.Root || replayed.Data.Header.Message.ProposerIndex != recorded.Data.Header.Message.ProposerIndex || !replayed.Finalized {
+		t.Errorf("replayed header %+v does not match recorded header %+v", replayed.Data, recorded.Data)
+	}
+	missed, err = replayer.GetBlockHeader(2)
+	if err != nil || missed != nil {
+		t.Errorf("expected missed slot to be replayed as not found, got %v, %v", missed, err)
+	}
+	_, err = replayer.GetBlockHeader(3)
+	if err == nil {
+		t.Errorf("expected an error for a request without recorded fixture")
+	}
+}
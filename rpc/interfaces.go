@@ -23,6 +23,11 @@ type Client interface {
 	GetBlockHeader(slot uint64) (*StandardBeaconHeaderResponse, error)
 }
 
+// EventSubscriber is implemented by clients that can stream events from the beacon node
+type EventSubscriber interface {
+	SubscribeEvents(topics ...string) *EventStream
+}
+
 type Eth1Client interface {
 	GetBlock(number uint64) (*types.Eth1Block, *types.GetBlockTimings, error)
 	GetLatestEth1BlockNumber() (uint64, error)
@@ -13,12 +13,12 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gobitfly/eth2-beaconchain-explorer/types"
 	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
 
-	"github.com/donovanhide/eventsource"
 	gtypes "github.com/ethereum/go-ethereum/core/types"
 	"golang.org/x/sync/errgroup"
 
@@ -37,6 +37,8 @@ type LighthouseClient struct {
 	slotsCache          *lru.Cache
 	slotsCacheMux       *sync.Mutex
 	signer              gtypes.Signer
+	sszDisabled         atomic.Bool
+	transport           http.RoundTripper
 }
 
 // NewLighthouseClient is used to create a new Lighthouse client
@@ -50,51 +52,31 @@ func NewLighthouseClient(endpoint string, chainID *big.Int) (*LighthouseClient,
 	}
 	client.assignmentsCache, _ = lru.New(10)
 	client.slotsCache, _ = lru.New(128) // cache at most 128 slots
+	if utils.Config != nil {
+		client.sszDisabled.Store(utils.Config.Indexer.Node.DisableSSZ)
+	}
 
 	return client, nil
 }
 
 func (lc *LighthouseClient) GetNewBlockChan() chan *types.Block {
 	blkCh := make(chan *types.Block, 10)
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/eth/v1/events?topics=head", lc.endpoint), nil)
-	if err != nil {
-		logger.Fatal(err, "error initializing event sse request", 0)
-	}
-	// disable gzip compression for sse
-	req.Header.Set("accept-encoding", "identity")
+	stream := lc.SubscribeEvents(EventTopicHead)
 
 	go func() {
-		stream, err := eventsource.SubscribeWithRequest("", req)
-
-		if err != nil {
-			utils.LogFatal(err, "getting eventsource stream error", 0)
-		}
-		defer stream.Close()
-
-		for {
-			select {
-			// It is important to register to Errors, otherwise the stream does not reconnect if the connection was lost
-			case err := <-stream.Errors:
-				utils.LogError(err, "Lighthouse connection error (will automatically retry to connect)", 0)
-			case e := <-stream.Events:
-				// logger.Infof("retrieved %v via event stream", e.Data())
-				var parsed StreamedBlockEventData
-				err = json.Unmarshal([]byte(e.Data()), &parsed)
-				if err != nil {
-					logger.Warnf("failed to decode block event: %v", err)
-					continue
-				}
+		for e := range stream.Events {
+			// logger.Infof("retrieved %v via event stream", e.Head)
+			slot := uint64(e.Head.Slot)
 
-				logger.Infof("retrieving data for slot %v", parsed.Slot)
-				block, err := lc.GetBlockBySlot(uint64(parsed.Slot))
-				if err != nil {
-					logger.Warnf("failed to fetch block for slot %d: %v", uint64(parsed.Slot), err)
-					continue
-				}
-				logger.Infof("retrieved block for slot %v", parsed.Slot)
-				// logger.Infof("pushing block %v", blk.Slot)
-				blkCh <- block
+			logger.Infof("retrieving data for slot %v", slot)
+			block, err := lc.GetBlockBySlot(slot)
+			if err != nil {
+				logger.Warnf("failed to fetch block for slot %d: %v", slot, err)
+				continue
 			}
+			logger.Infof("retrieved block for slot %v", slot)
+			// logger.Infof("pushing block %v", blk.Slot)
+			blkCh <- block
 		}
 	}()
 	return blkCh
@@ -154,6 +136,26 @@ func (lc *LighthouseClient) GetChainHead() (*types.ChainHead, error) {
 	}, nil
 }
 
+// GetSyncStatus gets the sync status of the node from the standard syncing endpoint
+func (lc *LighthouseClient) GetSyncStatus() (*StandardSyncingResponse, error) {
+	resp, err := lc.get(fmt.Sprintf("%s/eth/v1/node/syncing", lc.endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving sync status: %w", err)
+	}
+
+	var parsed StandardSyncingResponse
+	err = json.Unmarshal(resp, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing sync status: %w", err)
+	}
+	return &parsed, nil
+}
+
+// Endpoint returns the base url of the node the client talks to
+func (lc *LighthouseClient) Endpoint() string {
+	return lc.endpoint
+}
+
 func (lc *LighthouseClient) GetValidatorQueue() (*types.ValidatorQueue, error) {
 	// pre-filter the status, to return much less validators, thus much faster!
 	validatorsResp, err := lc.get(fmt.Sprintf("%s/eth/v1/beacon/states/head/validators?status=pending_queued,active_exiting,active_slashed", lc.endpoint))
@@ -294,6 +296,14 @@ func (lc *LighthouseClient) GetEpochProposerAssignments(epoch uint64) (*Standard
 }
 
 func (lc *LighthouseClient) GetValidatorState(epoch uint64) (*StandardValidatorsResponse, error) {
+	if lc.useSSZ() {
+		validators, err := lc.getValidatorsSSZ(epoch)
+		if err == nil {
+			return validatorsResponseFromValidators(validators), nil
+		}
+		lc.handleSSZError(fmt.Sprintf("state of epoch %v", epoch), err)
+	}
+
 	validatorsResp, err := lc.get(fmt.Sprintf("%s/eth/v1/beacon/states/%d/validators", lc.endpoint, epoch*utils.Config.Chain.ClConfig.SlotsPerEpoch))
 	if err != nil && epoch == 0 {
 		validatorsResp, err = lc.get(fmt.Sprintf("%s/eth/v1/beacon/states/%v/validators", lc.endpoint, "genesis"))
@@ -335,36 +345,9 @@ func (lc *LighthouseClient) GetEpochData(epoch uint64, skipHistoricBalances bool
 		data.Finalized = false
 	}
 
-	validatorsResp, err := lc.get(fmt.Sprintf("%s/eth/v1/beacon/states/%d/validators", lc.endpoint, epoch*utils.Config.Chain.ClConfig.SlotsPerEpoch))
-	if err != nil && epoch == 0 {
-		validatorsResp, err = lc.get(fmt.Sprintf("%s/eth/v1/beacon/states/%v/validators", lc.endpoint, "genesis"))
-		if err != nil {
-			return nil, fmt.Errorf("error retrieving validators for genesis: %w", err)
-		}
-	} else if err != nil {
-		return nil, fmt.Errorf("error retrieving validators for epoch %v: %w", epoch, err)
-	}
-
-	var parsedValidators StandardValidatorsResponse
-	err = json.Unmarshal(validatorsResp, &parsedValidators)
+	data.Validators, err = lc.getValidators(epoch)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing epoch validators: %w", err)
-	}
-
-	for _, validator := range parsedValidators.Data {
-		data.Validators = append(data.Validators, &types.Validator{
-			Index:                      uint64(validator.Index),
-			PublicKey:                  utils.MustParseHex(validator.Validator.Pubkey),
-			WithdrawalCredentials:      utils.MustParseHex(validator.Validator.WithdrawalCredentials),
-			Balance:                    uint64(validator.Balance),
-			EffectiveBalance:           uint64(validator.Validator.EffectiveBalance),
-			Slashed:                    validator.Validator.Slashed,
-			ActivationEligibilityEpoch: uint64(validator.Validator.ActivationEligibilityEpoch),
-			ActivationEpoch:            uint64(validator.Validator.ActivationEpoch),
-			ExitEpoch:                  uint64(validator.Validator.ExitEpoch),
-			WithdrawableEpoch:          uint64(validator.Validator.WithdrawableEpoch),
-			Status:                     validator.Status,
-		})
+		return nil, err
 	}
 
 	logger.Printf("retrieved data for %v validators for epoch %v", len(data.Validators), epoch)
@@ -630,19 +613,12 @@ func (lc *LighthouseClient) GetBlockByBlockroot(blockroot []byte) (*types.Block,
 
 	slot := uint64(parsedHeaders.Data.Header.Message.Slot)
 
-	resp, err := lc.get(fmt.Sprintf("%s/eth/v2/beacon/blocks/%s", lc.endpoint, parsedHeaders.Data.Root))
+	parsedResponse, err := lc.getBlock(parsedHeaders.Data.Root, slot)
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving block data at slot %v: %w", slot, err)
-	}
-
-	var parsedResponse StandardV2BlockResponse
-	err = json.Unmarshal(resp, &parsedResponse)
-	if err != nil {
-		logger.Errorf("error parsing block data at slot %v: %v", parsedHeaders.Data.Header.Message.Slot, err)
-		return nil, fmt.Errorf("error parsing block-response at slot %v: %w", slot, err)
+		return nil, err
 	}
 
-	return lc.blockFromResponse(&parsedHeaders, &parsedResponse)
+	return lc.blockFromResponse(&parsedHeaders, parsedResponse)
 }
 
 // GetBlockHeader will get the block header by slot from Lighthouse RPC api
@@ -758,37 +734,9 @@ func (lc *LighthouseClient) GetBlockBySlot(slot uint64) (*types.Block, error) {
 
 				block.EpochAssignments = assignments
 
-				validatorsResp, err := lc.get(fmt.Sprintf("%s/eth/v1/beacon/states/%d/validators", lc.endpoint, epoch*utils.Config.Chain.ClConfig.SlotsPerEpoch))
-				if err != nil && epoch == 0 {
-					validatorsResp, err = lc.get(fmt.Sprintf("%s/eth/v1/beacon/states/%v/validators", lc.endpoint, "genesis"))
-					if err != nil {
-						return nil, fmt.Errorf("error retrieving validators for genesis: %w", err)
-					}
-				} else if err != nil {
-					return nil, fmt.Errorf("error retrieving validators for epoch %v: %w", epoch, err)
-				}
-
-				var parsedValidators StandardValidatorsResponse
-				err = json.Unmarshal(validatorsResp, &parsedValidators)
+				block.Validators, err = lc.getValidators(epoch)
 				if err != nil {
-					return nil, fmt.Errorf("error parsing epoch validators: %w", err)
-				}
-
-				block.Validators = make([]*types.Validator, 0, len(parsedValidators.Data))
-				for _, validator := range parsedValidators.Data {
-					block.Validators = append(block.Validators, &types.Validator{
-						Index:                      uint64(validator.Index),
-						PublicKey:                  utils.MustParseHex(validator.Validator.Pubkey),
-						WithdrawalCredentials:      utils.MustParseHex(validator.Validator.WithdrawalCredentials),
-						Balance:                    uint64(validator.Balance),
-						EffectiveBalance:           uint64(validator.Validator.EffectiveBalance),
-						Slashed:                    validator.Validator.Slashed,
-						ActivationEligibilityEpoch: uint64(validator.Validator.ActivationEligibilityEpoch),
-						ActivationEpoch:            uint64(validator.Validator.ActivationEpoch),
-						ExitEpoch:                  uint64(validator.Validator.ExitEpoch),
-						WithdrawableEpoch:          uint64(validator.Validator.WithdrawableEpoch),
-						Status:                     validator.Status,
-					})
+					return nil, err
 				}
 			}
 
@@ -819,19 +767,12 @@ func (lc *LighthouseClient) GetBlockBySlot(slot uint64) (*types.Block, error) {
 	}
 	lc.slotsCacheMux.Unlock()
 
-	resp, err := lc.get(fmt.Sprintf("%s/eth/v2/beacon/blocks/%s", lc.endpoint, parsedHeaders.Data.Root))
-	if err != nil && slot == 0 {
-		return nil, fmt.Errorf("error retrieving block data at slot %v: %w", slot, err)
-	}
-
-	var parsedResponse StandardV2BlockResponse
-	err = json.Unmarshal(resp, &parsedResponse)
+	parsedResponse, err := lc.getBlock(parsedHeaders.Data.Root, slot)
 	if err != nil {
-		logger.Errorf("error parsing block data at slot %v: %v", slot, err)
-		return nil, fmt.Errorf("error parsing block-response at slot %v: %w", slot, err)
+		return nil, err
 	}
 
-	block, err := lc.blockFromResponse(parsedHeaders, &parsedResponse)
+	block, err := lc.blockFromResponse(parsedHeaders, parsedResponse)
 	if err != nil {
 		return nil, err
 	}
@@ -844,37 +785,9 @@ func (lc *LighthouseClient) GetBlockBySlot(slot uint64) (*types.Block, error) {
 			return nil, err
 		}
 
-		validatorsResp, err := lc.get(fmt.Sprintf("%s/eth/v1/beacon/states/%d/validators", lc.endpoint, epoch*utils.Config.Chain.ClConfig.SlotsPerEpoch))
-		if err != nil && epoch == 0 {
-			validatorsResp, err = lc.get(fmt.Sprintf("%s/eth/v1/beacon/states/%v/validators", lc.endpoint, "genesis"))
-			if err != nil {
-				return nil, fmt.Errorf("error retrieving validators for genesis: %w", err)
-			}
-		} else if err != nil {
-			return nil, fmt.Errorf("error retrieving validators for epoch %v: %w", epoch, err)
-		}
-
-		var parsedValidators StandardValidatorsResponse
-		err = json.Unmarshal(validatorsResp, &parsedValidators)
+		block.Validators, err = lc.getValidators(epoch)
 		if err != nil {
-			return nil, fmt.Errorf("error parsing epoch validators: %w", err)
-		}
-
-		block.Validators = make([]*types.Validator, 0, len(parsedValidators.Data))
-		for _, validator := range parsedValidators.Data {
-			block.Validators = append(block.Validators, &types.Validator{
-				Index:                      uint64(validator.Index),
-				PublicKey:                  utils.MustParseHex(validator.Validator.Pubkey),
-				WithdrawalCredentials:      utils.MustParseHex(validator.Validator.WithdrawalCredentials),
-				Balance:                    uint64(validator.Balance),
-				EffectiveBalance:           uint64(validator.Validator.EffectiveBalance),
-				Slashed:                    validator.Validator.Slashed,
-				ActivationEligibilityEpoch: uint64(validator.Validator.ActivationEligibilityEpoch),
-				ActivationEpoch:            uint64(validator.Validator.ActivationEpoch),
-				ExitEpoch:                  uint64(validator.Validator.ExitEpoch),
-				WithdrawableEpoch:          uint64(validator.Validator.WithdrawableEpoch),
-				Status:                     validator.Status,
-			})
+			return nil, err
 		}
 	}
 
@@ -1125,9 +1038,18 @@ func (lc *LighthouseClient) blockFromResponse(parsedHeaders *StandardBeaconHeade
 			return nil, fmt.Errorf("error receiving epoch assignment for epoch %v: %w", a.Data.Slot/utils.Config.Chain.ClConfig.SlotsPerEpoch, err)
 		}
 
+		var committeeBits []byte
+		if attestation.CommitteeBits != "" {
+			committeeBits = utils.MustParseHex(attestation.CommitteeBits)
+		}
+		memberKeys, err := attestationMemberKeys(a.Data.Slot, a.Data.CommitteeIndex, committeeBits, aggregationBits.Len(), assignments)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving attesters of attestation %v of block at slot %v: %w", i, block.Slot, err)
+		}
+
 		for i := uint64(0); i < aggregationBits.Len(); i++ {
 			if aggregationBits.BitAt(i) {
-				validator, found := assignments.AttestorAssignments[utils.FormatAttestorAssignmentKey(a.Data.Slot, a.Data.CommitteeIndex, i)]
+				validator, found := assignments.AttestorAssignments[memberKeys[i]]
 				if !found { // This should never happen!
 					validator = 0
 					logger.Errorf("error retrieving assigned validator for attestation %v of block %v for slot %v committee index %v member index %v", i, block.Slot, a.Data.Slot, a.Data.CommitteeIndex, i)
@@ -1176,9 +1098,75 @@ func (lc *LighthouseClient) blockFromResponse(parsedHeaders *StandardBeaconHeade
 		}
 	}
 
+	if requests := parsedBlock.Message.Body.ExecutionRequests; requests != nil {
+		block.DepositRequests = make([]*types.DepositRequest, len(requests.Deposits))
+		for i, r := range requests.Deposits {
+			block.DepositRequests[i] = &types.DepositRequest{
+				Pubkey:                r.Pubkey,
+				WithdrawalCredentials: r.WithdrawalCredentials,
+				Amount:                uint64(r.Amount),
+				Signature:             r.Signature,
+				Index:                 uint64(r.Index),
+			}
+		}
+
+		block.WithdrawalRequests = make([]*types.WithdrawalRequest, len(requests.Withdrawals))
+		for i, r := range requests.Withdrawals {
+			block.WithdrawalRequests[i] = &types.WithdrawalRequest{
+				SourceAddress:   r.SourceAddress,
+				ValidatorPubkey: r.ValidatorPubkey,
+				Amount:          uint64(r.Amount),
+			}
+		}
+
+		block.ConsolidationRequests = make([]*types.ConsolidationRequest, len(requests.Consolidations))
+		for i, r := range requests.Consolidations {
+			block.ConsolidationRequests[i] = &types.ConsolidationRequest{
+				SourceAddress: r.SourceAddress,
+				SourcePubkey:  r.SourcePubkey,
+				TargetPubkey:  r.TargetPubkey,
+			}
+		}
+	}
+
 	return block, nil
 }
 
+// attestationMemberKeys returns the attestor assignment key for each position of the aggregation bits of an attestation.
+// Since electra (EIP-7549) data.index is always 0 and the aggregation bits cover the members of all committees set in
+// committee_bits, ordered by committee index
+func attestationMemberKeys(slot, committeeIndex uint64, committeeBits []byte, bitsLen uint64, assignments *types.EpochAssignments) ([]string, error) {
+	keys := make([]string, 0, bitsLen)
+	if len(committeeBits) == 0 {
+		for i := uint64(0); i < bitsLen; i++ {
+			keys = append(keys, utils.FormatAttestorAssignmentKey(slot, committeeIndex, i))
+		}
+		return keys, nil
+	}
+
+	for c := 0; c < len(committeeBits)*8; c++ {
+		if !utils.BitAtVector(committeeBits, c) {
+			continue
+		}
+		size := uint64(0)
+		for {
+			key := utils.FormatAttestorAssignmentKey(slot, uint64(c), size)
+			if _, found := assignments.AttestorAssignments[key]; !found {
+				break
+			}
+			keys = append(keys, key)
+			size++
+		}
+		if size == 0 {
+			return nil, fmt.Errorf("no assignments for committee %v at slot %v", c, slot)
+		}
+	}
+	if uint64(len(keys)) != bitsLen {
+		return nil, fmt.Errorf("aggregation bits length does not match the size of the attesting committees: %v != %v", bitsLen, len(keys))
+	}
+	return keys, nil
+}
+
 func syncCommitteeParticipation(bits []byte) float64 {
 	participating := 0
 	for i := 0; i < int(utils.Config.Chain.ClConfig.SyncCommitteeSize); i++ {
@@ -1294,7 +1282,7 @@ var errNotFound = errors.New("not found 404")
 func (lc *LighthouseClient) get(url string) ([]byte, error) {
 	// t0 := time.Now()
 	// defer func() { fmt.Println(url, time.Since(t0)) }()
-	client := &http.Client{Timeout: time.Minute * 2}
+	client := &http.Client{Timeout: time.Minute * 2, Transport: lc.transport}
 	resp, err := client.Get(url)
 	if err != nil {
 		return nil, err
@@ -1479,57 +1467,36 @@ type ProposerSlashing struct {
 
 type AttesterSlashing struct {
 	Attestation1 struct {
-		AttestingIndices []uint64Str `json:"attesting_indices"`
-		Signature        string      `json:"signature"`
-		Data             struct {
-			Slot            uint64Str `json:"slot"`
-			Index           uint64Str `json:"index"`
-			BeaconBlockRoot string    `json:"beacon_block_root"`
-			Source          struct {
-				Epoch uint64Str `json:"epoch"`
-				Root  string    `json:"root"`
-			} `json:"source"`
-			Target struct {
-				Epoch uint64Str `json:"epoch"`
-				Root  string    `json:"root"`
-			} `json:"target"`
-		} `json:"data"`
+		AttestingIndices []uint64Str     `json:"attesting_indices"`
+		Signature        string          `json:"signature"`
+		Data             AttestationData `json:"data"`
 	} `json:"attestation_1"`
 	Attestation2 struct {
-		AttestingIndices []uint64Str `json:"attesting_indices"`
-		Signature        string      `json:"signature"`
-		Data             struct {
-			Slot            uint64Str `json:"slot"`
-			Index           uint64Str `json:"index"`
-			BeaconBlockRoot string    `json:"beacon_block_root"`
-			Source          struct {
-				Epoch uint64Str `json:"epoch"`
-				Root  string    `json:"root"`
-			} `json:"source"`
-			Target struct {
-				Epoch uint64Str `json:"epoch"`
-				Root  string    `json:"root"`
-			} `json:"target"`
-		} `json:"data"`
+		AttestingIndices []uint64Str     `json:"attesting_indices"`
+		Signature        string          `json:"signature"`
+		Data             AttestationData `json:"data"`
 	} `json:"attestation_2"`
 }
 
 type Attestation struct {
-	AggregationBits string `json:"aggregation_bits"`
-	Signature       string `json:"signature"`
-	Data            struct {
-		Slot            uint64Str `json:"slot"`
-		Index           uint64Str `json:"index"`
-		BeaconBlockRoot string    `json:"beacon_block_root"`
-		Source          struct {
-			Epoch uint64Str `json:"epoch"`
-			Root  string    `json:"root"`
-		} `json:"source"`
-		Target struct {
-			Epoch uint64Str `json:"epoch"`
-			Root  string    `json:"root"`
-		} `json:"target"`
-	} `json:"data"`
+	AggregationBits string          `json:"aggregation_bits"`
+	CommitteeBits   string          `json:"committee_bits,omitempty"` // present only after electra
+	Signature       string          `json:"signature"`
+	Data            AttestationData `json:"data"`
+}
+
+type AttestationData struct {
+	Slot            uint64Str `json:"slot"`
+	Index           uint64Str `json:"index"`
+	BeaconBlockRoot string    `json:"beacon_block_root"`
+	Source          struct {
+		Epoch uint64Str `json:"epoch"`
+		Root  string    `json:"root"`
+	} `json:"source"`
+	Target struct {
+		Epoch uint64Str `json:"epoch"`
+		Root  string    `json:"root"`
+	} `json:"target"`
 }
 
 type Deposit struct {
@@ -1601,6 +1568,32 @@ type SignedBLSToExecutionChange struct {
 	Signature bytesHexStr `json:"signature"`
 }
 
+type DepositRequest struct {
+	Pubkey                bytesHexStr `json:"pubkey"`
+	WithdrawalCredentials bytesHexStr `json:"withdrawal_credentials"`
+	Amount                uint64Str   `json:"amount"`
+	Signature             bytesHexStr `json:"signature"`
+	Index                 uint64Str   `json:"index"`
+}
+
+type WithdrawalRequest struct {
+	SourceAddress   bytesHexStr `json:"source_address"`
+	ValidatorPubkey bytesHexStr `json:"validator_pubkey"`
+	Amount          uint64Str   `json:"amount"`
+}
+
+type ConsolidationRequest struct {
+	SourceAddress bytesHexStr `json:"source_address"`
+	SourcePubkey  bytesHexStr `json:"source_pubkey"`
+	TargetPubkey  bytesHexStr `json:"target_pubkey"`
+}
+
+type ExecutionRequests struct {
+	Deposits       []*DepositRequest       `json:"deposits"`
+	Withdrawals    []*WithdrawalRequest    `json:"withdrawals"`
+	Consolidations []*ConsolidationRequest `json:"consolidations"`
+}
+
 type AnySignedBlock struct {
 	Message struct {
 		Slot          uint64Str `json:"slot"`
@@ -1628,6 +1621,9 @@ type AnySignedBlock struct {
 
 			// present only after deneb
 			BlobKZGCommitments []bytesHexStr `json:"blob_kzg_commitments"`
+
+			// present only after electra
+			ExecutionRequests *ExecutionRequests `json:"execution_requests,omitempty"`
 		} `json:"body"`
 	} `json:"message"`
 	Signature bytesHexStr `json:"signature"`
@@ -1669,6 +1665,8 @@ type StandardValidatorsResponse struct {
 type StandardSyncingResponse struct {
 	Data struct {
 		IsSyncing    bool      `json:"is_syncing"`
+		IsOptimistic bool      `json:"is_optimistic"`
+		ElOffline    bool      `json:"el_offline"`
 		HeadSlot     uint64Str `json:"head_slot"`
 		SyncDistance uint64Str `json:"sync_distance"`
 	} `json:"data"`
@@ -0,0 +1,81 @@
+package rpc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+)
+
+func TestBlockFromResponseElectraAttestations(t *testing.T) {
+	prevConfig := utils.Config
+	defer func() { utils.Config = prevConfig }()
+	utils.Config = &types.Config{}
+	utils.Config.Chain.ClConfig.SlotsPerEpoch = 32
+
+	lc, err := NewLighthouseClient("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// committee 0: 10, 11, 12; committee 1: 20, 21; committee 2: 30, 31
+	assignments := &types.EpochAssignments{
+		ProposerAssignments: map[uint64]uint64{1: 17},
+		AttestorAssignments: map[string]uint64{},
+	}
+	for committee, members := range [][]uint64{{10, 11, 12}, {20, 21}, {30, 31}} {
+		for i, validator := range members {
+			assignments.AttestorAssignments[utils.FormatAttestorAssignmentKey(0, uint64(committee), uint64(i))] = validator
+		}
+	}
+	lc.assignmentsCache.Add(uint64(0), assignments)
+
+	var header StandardBeaconHeaderResponse
+	err = json.Unmarshal([]byte(testHeaderResponse), &header)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		attestation string
+		attesters   []uint64
+		err         bool
+	}{
+		// members 0 and 2 of committee 0 and member 1 of committee 2, the aggregation bits span 3 + 2 members
+		{"electra multi committee", `{"aggregation_bits":"0x35","committee_bits":"0x0500000000000000","data":{"slot":"0","index":"0"}}`, []uint64{10, 12, 31}, false},
+		{"electra single committee", `{"aggregation_bits":"0x06","committee_bits":"0x0200000000000000","data":{"slot":"0","index":"0"}}`, []uint64{21}, false},
+		{"electra bits length mismatch", `{"aggregation_bits":"0x1f","committee_bits":"0x0500000000000000","data":{"slot":"0","index":"0"}}`, nil, true},
+		{"electra unknown committee", `{"aggregation_bits":"0x07","committee_bits":"0x0800000000000000","data":{"slot":"0","index":"0"}}`, nil, true},
+		{"phase0", `{"aggregation_bits":"0x06","data":{"slot":"0","index":"1"}}`, []uint64{21}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var res StandardV2BlockResponse
+			err := json.Unmarshal([]byte(`{"version":"electra","data":{"message":{"slot":"1","proposer_index":"17","body":{"attestations":[`+tt.attestation+`]}}}}`), &res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			block, err := lc.blockFromResponse(&header, &res)
+			if tt.err {
+				if err == nil {
+					t.Errorf("expected an error, got attesters %v", block.Attestations[0].Attesters)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(block.Attestations[0].Attesters, tt.attesters) {
+				t.Errorf("attesters are %v, want %v", block.Attestations[0].Attesters, tt.attesters)
+			}
+			for _, validator := range tt.attesters {
+				if len(block.AttestationDuties[types.ValidatorIndex(validator)]) != 1 {
+					t.Errorf("missing attestation duty of validator %v", validator)
+				}
+			}
+		})
+	}
+}
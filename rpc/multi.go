@@ -0,0 +1,408 @@
+package rpc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	lru "github.com/hashicorp/golang-lru"
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrNoHealthyNode is returned if none of the configured beacon nodes is healthy and synced
+var ErrNoHealthyNode = errors.New("no healthy beacon node available")
+
+// ErrFinalityMismatch is returned if the configured beacon nodes disagree on the finalized checkpoint
+var ErrFinalityMismatch = errors.New("beacon nodes disagree on the finalized checkpoint")
+
+// maxConsecutiveNodeFailures is the number of consecutive failed requests after which a node is not used anymore until
+// the next health check, so a single slow or failed request does not take the preferred node out of rotation
+const maxConsecutiveNodeFailures = 3
+
+// MultiClient wraps several Lighthouse clients and routes every call to a healthy, synced node
+type MultiClient struct {
+	clients         []*LighthouseClient
+	healthy         []bool
+	failures        []int // consecutive failed requests per node
+	preferred       int
+	healthMux       *sync.RWMutex
+	maxSyncDistance uint64
+}
+
+// NewMultiClient is used to create a new client that fails over between the given beacon node endpoints.
+// Nodes whose sync distance exceeds maxSyncDistance slots are considered unhealthy.
+func NewMultiClient(endpoints []string, chainID *big.Int, maxSyncDistance uint64) (*MultiClient, error) {
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no beacon node endpoints provided")
+	}
+
+	mc := &MultiClient{
+		clients:         make([]*LighthouseClient, 0, len(endpoints)),
+		healthy:         make([]bool, len(endpoints)),
+		failures:        make([]int, len(endpoints)),
+		healthMux:       &sync.RWMutex{},
+		maxSyncDistance: maxSyncDistance,
+	}
+	for _, endpoint := range endpoints {
+		client, err := NewLighthouseClient(endpoint, chainID)
+		if err != nil {
+			return nil, fmt.Errorf("error creating client for endpoint %v: %w", endpoint, err)
+		}
+		mc.clients = append(mc.clients, client)
+	}
+
+	mc.checkHealth()
+	go mc.healthChecker()
+
+	return mc, nil
+}
+
+func (mc *MultiClient) healthChecker() {
+	for {
+		time.Sleep(time.Second * time.Duration(utils.Config.Chain.ClConfig.SecondsPerSlot))
+		mc.checkHealth()
+	}
+}
+
+// checkHealth queries the sync status of all nodes and updates the list of usable nodes
+func (mc *MultiClient) checkHealth() {
+	healthy := make([]bool, len(mc.clients))
+
+	wg := &sync.WaitGroup{}
+	for i, client := range mc.clients {
+		wg.Add(1)
+		go func(i int, client *LighthouseClient) {
+			defer wg.Done()
+			status, err := client.GetSyncStatus()
+			if err != nil {
+				logger.Warnf("beacon node %v is unavailable: %v", client.Endpoint(), err)
+				return
+			}
+			if status.Data.IsSyncing || status.Data.ElOffline || uint64(status.Data.SyncDistance) > mc.maxSyncDistance {
+				logger.Warnf("beacon node %v is not synced (syncing: %v, el offline: %v, sync distance: %v)", client.Endpoint(), status.Data.IsSyncing, status.Data.ElOffline, status.Data.SyncDistance)
+				return
+			}
+			healthy[i] = true
+		}(i, client)
+	}
+	wg.Wait()
+
+	mc.healthMux.Lock()
+	defer mc.healthMux.Unlock()
+	mc.healthy = healthy
+	for i := range mc.failures {
+		mc.failures[i] = 0
+	}
+	if !mc.healthy[mc.preferred] {
+		for i, h := range mc.healthy {
+			if h {
+				logger.Infof("switching primary beacon node from %v to %v", mc.clients[mc.preferred].Endpoint(), mc.clients[i].Endpoint())
+				mc.preferred = i
+				break
+			}
+		}
+	}
+}
+
+// healthyClients returns all currently healthy clients, starting with the preferred one
+func (mc *MultiClient) healthyClients() []*LighthouseClient {
+	mc.healthMux.RLock()
+	defer mc.healthMux.RUnlock()
+
+	clients := make([]*LighthouseClient, 0, len(mc.clients))
+	for i := 0; i < len(mc.clients); i++ {
+		idx := (mc.preferred + i) % len(mc.clients)
+		if mc.healthy[idx] {
+			clients = append(clients, mc.clients[idx])
+		}
+	}
+	return clients
+}
+
+// recordFailure counts a failed request of the node and stops using it once it failed maxConsecutiveNodeFailures times in a row
+func (mc *MultiClient) recordFailure(client *LighthouseClient) {
+	mc.healthMux.Lock()
+	defer mc.healthMux.Unlock()
+	for i, c := range mc.clients {
+		if c == client {
+			mc.failures[i]++
+			if mc.failures[i] >= maxConsecutiveNodeFailures && mc.healthy[i] {
+				logger.Warnf("beacon node %v failed %v consecutive requests, not using it until the next health check", client.Endpoint(), mc.failures[i])
+				mc.healthy[i] = false
+			}
+		}
+	}
+}
+
+// recordSuccess resets the failed requests of the node
+func (mc *MultiClient) recordSuccess(client *LighthouseClient) {
+	mc.healthMux.Lock()
+	defer mc.healthMux.Unlock()
+	for i, c := range mc.clients {
+		if c == client {
+			mc.failures[i] = 0
+		}
+	}
+}
+
+// do executes f against the healthy nodes until the first one succeeds
+func (mc *MultiClient) do(f func(client *LighthouseClient) error) error {
+	clients := mc.healthyClients()
+	if len(clients) == 0 {
+		return ErrNoHealthyNode
+	}
+
+	var err error
+	for _, client := range clients {
+		err = f(client)
+		if err == nil || errors.Is(err, errNotFound) {
+			mc.recordSuccess(client)
+			return err
+		}
+		logger.Warnf("request to beacon node %v failed, trying next node: %v", client.Endpoint(), err)
+		mc.recordFailure(client)
+	}
+	return err
+}
+
+// GetChainHead gets the chain head from all healthy nodes and verifies that they agree on the finalized checkpoint
+func (mc *MultiClient) GetChainHead() (*types.ChainHead, error) {
+	clients := mc.healthyClients()
+	if len(clients) == 0 {
+		return nil, ErrNoHealthyNode
+	}
+
+	heads := make([]*types.ChainHead, len(clients))
+	g := &errgroup.Group{}
+	for i, client := range clients {
+		i, client := i, client
+		g.Go(func() error {
+			head, err := client.GetChainHead()
+			if err != nil {
+				logger.Warnf("error retrieving chain head from beacon node %v: %v", client.Endpoint(), err)
+				mc.recordFailure(client)
+				return nil
+			}
+			mc.recordSuccess(client)
+			heads[i] = head
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	// the nodes may have finalized different epochs, all of them have to agree on the root of the lowest finalized checkpoint
+	var result, lowest *types.ChainHead
+	for _, head := range heads {
+		if head == nil {
+			continue
+		}
+		if result == nil {
+			result = head
+		}
+		if lowest == nil || head.FinalizedEpoch < lowest.FinalizedEpoch {
+			lowest = head
+		}
+	}
+	if result == nil {
+		return nil, ErrNoHealthyNode
+	}
+
+	for i, head := range heads {
+		if head == nil || head == lowest || lowest.FinalizedSlot == 0 {
+			continue
+		}
+		root := head.FinalizedBlockRoot
+		if head.FinalizedEpoch != lowest.FinalizedEpoch {
+			var err error
+			root, err = checkpointRoot(clients[i], lowest.FinalizedSlot)
+			if err != nil {
+				return nil, fmt.Errorf("error retrieving checkpoint root at slot %v from beacon node %v: %w", lowest.FinalizedSlot, clients[i].Endpoint(), err)
+			}
+		}
+		if !bytes.Equal(root, lowest.FinalizedBlockRoot) {
+			return nil, fmt.Errorf("%w: node %v reports root %#x for finalized epoch %v, expected %#x", ErrFinalityMismatch, clients[i].Endpoint(), root, lowest.FinalizedEpoch, lowest.FinalizedBlockRoot)
+		}
+	}
+
+	// prefer the most conservative view on finality, but never report a head the preferred node does not know about
+	res := *result
+	res.FinalizedEpoch = lowest.FinalizedEpoch
+	res.FinalizedSlot = lowest.FinalizedSlot
+	res.FinalizedBlockRoot = lowest.FinalizedBlockRoot
+	return &res, nil
+}
+
+// checkpointRoot returns the root of the checkpoint at the given epoch boundary slot, which is the root of the latest block at or before the slot
+func checkpointRoot(client *LighthouseClient, slot uint64) ([]byte, error) {
+	for s := slot; ; s-- {
+		header, err := client.GetBlockHeader(s)
+		if err != nil {
+			return nil, err
+		}
+		if header != nil {
+			return utils.MustParseHex(header.Data.Root), nil
+		}
+		if s == 0 {
+			return nil, fmt.Errorf("no block found at or before slot %v", slot)
+		}
+	}
+}
+
+func (mc *MultiClient) GetEpochData(epoch uint64, skipHistoricBalances bool) (*types.EpochData, error) {
+	var data *types.EpochData
+	err := mc.do(func(client *LighthouseClient) error {
+		var err error
+		data, err = client.GetEpochData(epoch, skipHistoricBalances)
+		return err
+	})
+	return data, err
+}
+
+func (mc *MultiClient) GetValidatorQueue() (*types.ValidatorQueue, error) {
+	var queue *types.ValidatorQueue
+	err := mc.do(func(client *LighthouseClient) error {
+		var err error
+		queue, err = client.GetValidatorQueue()
+		return err
+	})
+	return queue, err
+}
+
+func (mc *MultiClient) GetEpochAssignments(epoch uint64) (*types.EpochAssignments, error) {
+	var assignments *types.EpochAssignments
+	err := mc.do(func(client *LighthouseClient) error {
+		var err error
+		assignments, err = client.GetEpochAssignments(epoch)
+		return err
+	})
+	return assignments, err
+}
+
+func (mc *MultiClient) GetBlockBySlot(slot uint64) (*types.Block, error) {
+	var block *types.Block
+	err := mc.do(func(client *LighthouseClient) error {
+		var err error
+		block, err = client.GetBlockBySlot(slot)
+		return err
+	})
+	return block, err
+}
+
+func (mc *MultiClient) GetValidatorParticipation(epoch uint64) (*types.ValidatorParticipation, error) {
+	var participation *types.ValidatorParticipation
+	err := mc.do(func(client *LighthouseClient) error {
+		var err error
+		participation, err = client.GetValidatorParticipation(epoch)
+		return err
+	})
+	return participation, err
+}
+
+// GetNewBlockChan subscribes to the block stream of every configured node and forwards each block only once
+func (mc *MultiClient) GetNewBlockChan() chan *types.Block {
+	blkCh := make(chan *types.Block, 10)
+	seen, _ := lru.New(128)
+	seenMux := &sync.Mutex{}
+
+	for _, client := range mc.clients {
+		go func(ch chan *types.Block) {
+			for block := range ch {
+				seenMux.Lock()
+				key := fmt.Sprintf("%x", block.BlockRoot)
+				if seen.Contains(key) {
+					seenMux.Unlock()
+					continue
+				}
+				seen.Add(key, true)
+				seenMux.Unlock()
+				blkCh <- block
+			}
+		}(client.GetNewBlockChan())
+	}
+	return blkCh
+}
+
+func (mc *MultiClient) GetSyncCommittee(stateID string, epoch uint64) (*StandardSyncCommittee, error) {
+	var committee *StandardSyncCommittee
+	err := mc.do(func(client *LighthouseClient) error {
+		var err error
+		committee, err = client.GetSyncCommittee(stateID, epoch)
+		return err
+	})
+	return committee, err
+}
+
+func (mc *MultiClient) GetBalancesForEpoch(epoch int64) (map[uint64]uint64, error) {
+	var balances map[uint64]uint64
+	err := mc.do(func(client *LighthouseClient) error {
+		var err error
+		balances, err = client.GetBalancesForEpoch(epoch)
+		return err
+	})
+	return balances, err
+}
+
+func (mc *MultiClient) GetValidatorState(epoch uint64) (*StandardValidatorsResponse, error) {
+	var validators *StandardValidatorsResponse
+	err := mc.do(func(client *LighthouseClient) error {
+		var err error
+		validators, err = client.GetValidatorState(epoch)
+		return err
+	})
+	return validators, err
+}
+
+func (mc *MultiClient) GetBlockHeader(slot uint64) (*StandardBeaconHeaderResponse, error) {
+	var header *StandardBeaconHeaderResponse
+	err := mc.do(func(client *LighthouseClient) error {
+		var err error
+		header, err = client.GetBlockHeader(slot)
+		return err
+	})
+	return header, err
+}
+
+// SubscribeEvents subscribes to the event streams of all configured nodes and forwards each event only once
+func (mc *MultiClient) SubscribeEvents(topics ...string) *EventStream {
+	streams := make([]*EventStream, 0, len(mc.clients))
+	for _, client := range mc.clients {
+		streams = append(streams, client.SubscribeEvents(topics...))
+	}
+
+	merged := &EventStream{
+		Events: make(chan *BeaconEvent, 1024),
+		connected: func() bool {
+			for _, s := range streams {
+				if s.Connected() {
+					return true
+				}
+			}
+			return false
+		},
+	}
+
+	seen, _ := lru.New(4096)
+	seenMux := &sync.Mutex{}
+	for _, stream := range streams {
+		go func(stream *EventStream) {
+			for e := range stream.Events {
+				seenMux.Lock()
+				key := e.eventKey()
+				if seen.Contains(key) {
+					seenMux.Unlock()
+					continue
+				}
+				seen.Add(key, true)
+				seenMux.Unlock()
+				merged.Events <- e
+			}
+		}(stream)
+	}
+	return merged
+}
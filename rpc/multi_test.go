@@ -0,0 +1,212 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+)
+
+// testNode is a beacon node serving the endpoints used by GetChainHead
+type testNode struct {
+	headSlot       uint64
+	finalizedEpoch uint64 // epoch of the finalized checkpoint
+	blockRoots     map[uint64]string
+	unavailable    bool
+}
+
+func testBlockRoot(b byte) string {
+	return fmt.Sprintf("0x%064x", b)
+}
+
+func (n *testNode) serve(t *testing.T) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n.unavailable {
+			http.Error(w, "unavailable", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/eth/v1/beacon/headers/head":
+			fmt.Fprintf(w, `{"data":{"root":"%v","header":{"message":{"slot":"%v","state_root":"0x01"}}}}`, n.blockRoots[n.headSlot], n.headSlot)
+		case strings.HasSuffix(r.URL.Path, "/finality_checkpoints"):
+			root := n.blockRoots[n.finalizedEpoch*32]
+			fmt.Fprintf(w, `{"data":{"previous_justified":{"epoch":"%v","root":"%v"},"current_justified":{"epoch":"%v","root":"%v"},"finalized":{"epoch":"%v","root":"%v"}}}`,
+				n.finalizedEpoch, root, n.finalizedEpoch+1, root, n.finalizedEpoch, root)
+		case strings.HasPrefix(r.URL.Path, "/eth/v1/beacon/headers/"):
+			var slot uint64
+			fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/eth/v1/beacon/headers/"), "%d", &slot)
+			root, ok := n.blockRoots[slot]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprintf(w, `{"data":{"root":"%v","header":{"message":{"slot":"%v"}}}}`, root, slot)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func newTestMultiClient(t *testing.T, nodes ...*testNode) *MultiClient {
+	mc := &MultiClient{
+		healthy:   make([]bool, len(nodes)),
+		failures:  make([]int, len(nodes)),
+		healthMux: &sync.RWMutex{},
+	}
+	for i, n := range nodes {
+		client, err := NewLighthouseClient(n.serve(t), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mc.clients = append(mc.clients, client)
+		mc.healthy[i] = true
+	}
+	return mc
+}
+
+func TestMultiClientGetChainHead(t *testing.T) {
+	prevConfig := utils.Config
+	defer func() { utils.Config = prevConfig }()
+	utils.Config = &types.Config{}
+	utils.Config.Chain.ClConfig.SlotsPerEpoch = 32
+
+	// both nodes share the chain up to slot 352, the epoch 11 checkpoint
+	chain := func(extra map[uint64]string) map[uint64]string {
+		roots := map[uint64]string{320: testBlockRoot(10), 352: testBlockRoot(11), 360: testBlockRoot(12)}
+		for slot, root := range extra {
+			roots[slot] = root
+		}
+		return roots
+	}
+
+	tests := []struct {
+		name           string
+		nodes          []*testNode
+		finalizedEpoch uint64
+		headSlot       uint64
+		err            error
+	}{
+		{
+			"same finalized checkpoint",
+			[]*testNode{{headSlot: 360, finalizedEpoch: 11, blockRoots: chain(nil)}, {headSlot: 360, finalizedEpoch: 11, blockRoots: chain(nil)}},
+			10, 360, nil,
+		},
+		{
+			"conflicting root at the same epoch",
+			[]*testNode{{headSlot: 360, finalizedEpoch: 11, blockRoots: chain(nil)}, {headSlot: 360, finalizedEpoch: 11, blockRoots: chain(map[uint64]string{352: testBlockRoot(99)})}},
+			0, 0, ErrFinalityMismatch,
+		},
+		{
+			"node ahead agrees on the lower checkpoint",
+			[]*testNode{{headSlot: 360, finalizedEpoch: 10, blockRoots: chain(nil)}, {headSlot: 360, finalizedEpoch: 11, blockRoots: chain(nil)}},
+			9, 360, nil,
+		},
+		{
+			"preferred node ahead agrees on the lower checkpoint",
+			[]*testNode{{headSlot: 360, finalizedEpoch: 11, blockRoots: chain(nil)}, {headSlot: 352, finalizedEpoch: 10, blockRoots: chain(nil)}},
+			9, 360, nil,
+		},
+		{
+			"node ahead finalized a different chain",
+			[]*testNode{{headSlot: 360, finalizedEpoch: 10, blockRoots: chain(nil)}, {headSlot: 360, finalizedEpoch: 11, blockRoots: chain(map[uint64]string{320: testBlockRoot(98)})}},
+			0, 0, ErrFinalityMismatch,
+		},
+		{
+			"node ahead missed the checkpoint slot",
+			[]*testNode{
+				{headSlot: 360, finalizedEpoch: 10, blockRoots: map[uint64]string{320: testBlockRoot(10), 360: testBlockRoot(12)}},
+				{headSlot: 360, finalizedEpoch: 11, blockRoots: map[uint64]string{318: testBlockRoot(10), 352: testBlockRoot(11), 360: testBlockRoot(12)}},
+			},
+			9, 360, nil,
+		},
+		{
+			"unavailable node is skipped",
+			[]*testNode{{unavailable: true}, {headSlot: 360, finalizedEpoch: 11, blockRoots: chain(nil)}},
+			10, 360, nil,
+		},
+		{
+			"no node available",
+			[]*testNode{{unavailable: true}, {unavailable: true}},
+			0, 0, ErrNoHealthyNode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := newTestMultiClient(t, tt.nodes...)
+			head, err := mc.GetChainHead()
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("error is %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if head.FinalizedEpoch != tt.finalizedEpoch {
+				t.Errorf("finalized epoch is %v, want %v", head.FinalizedEpoch, tt.finalizedEpoch)
+			}
+			if head.HeadSlot != tt.headSlot {
+				t.Errorf("head slot is %v, want %v", head.HeadSlot, tt.headSlot)
+			}
+			if tt.nodes[0].unavailable && mc.failures[0] != 1 {
+				t.Errorf("failure of the unavailable node was not recorded")
+			}
+		})
+	}
+}
+
+func TestMultiClientFailover(t *testing.T) {
+	prevConfig := utils.Config
+	defer func() { utils.Config = prevConfig }()
+	utils.Config = &types.Config{}
+	utils.Config.Chain.ClConfig.SlotsPerEpoch = 32
+
+	preferred := &testNode{headSlot: 360, blockRoots: map[uint64]string{360: testBlockRoot(12)}}
+	fallback := &testNode{headSlot: 360, blockRoots: map[uint64]string{360: testBlockRoot(12)}}
+	mc := newTestMultiClient(t, preferred, fallback)
+
+	request := func() string {
+		var endpoint string
+		err := mc.do(func(client *LighthouseClient) error {
+			_, err := client.GetChainHead()
+			if err == nil {
+				endpoint = client.Endpoint()
+			}
+			return err
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return endpoint
+	}
+
+	// a failed request is retried on the fallback without taking the preferred node out of rotation
+	preferred.unavailable = true
+	if endpoint := request(); endpoint != mc.clients[1].Endpoint() {
+		t.Fatalf("request was served by %v, want the fallback", endpoint)
+	}
+	preferred.unavailable = false
+	if endpoint := request(); endpoint != mc.clients[0].Endpoint() {
+		t.Fatalf("request was served by %v, want the preferred node", endpoint)
+	}
+
+	// the preferred node is only skipped after consecutive failures
+	preferred.unavailable = true
+	for i := 0; i < maxConsecutiveNodeFailures; i++ {
+		request()
+	}
+	if clients := mc.healthyClients(); len(clients) != 1 || clients[0] != mc.clients[1] {
+		t.Fatalf("preferred node was not taken out of rotation after %v consecutive failures", maxConsecutiveNodeFailures)
+	}
+}
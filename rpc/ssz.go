@@ -0,0 +1,783 @@
+package rpc
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// errSSZUnsupported is returned if the node does not serve the requested resource ssz encoded
+var errSSZUnsupported = errors.New("ssz encoding not supported by node")
+
+// errSSZUnknownFork is returned if a resource was served ssz encoded for a fork the ssz decoder does not know yet
+var errSSZUnknownFork = errors.New("ssz encoding of fork not supported")
+
+const farFutureEpoch = uint64(18446744073709551615)
+
+// openSSZ requests the ssz encoded resource at url and returns the response body together with the fork version reported by the node,
+// the caller has to close the body
+func (lc *LighthouseClient) openSSZ(url string) (io.ReadCloser, string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", "application/octet-stream")
+
+	client := &http.Client{Timeout: time.Minute * 5, Transport: lc.transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, "", errNotFound
+	case http.StatusNotAcceptable, http.StatusUnsupportedMediaType:
+		resp.Body.Close()
+		return nil, "", errSSZUnsupported
+	default:
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, "", fmt.Errorf("url: %v, error-response: %s", url, data)
+	}
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/octet-stream") {
+		// the node ignored the accept header and answered with json
+		resp.Body.Close()
+		return nil, "", errSSZUnsupported
+	}
+
+	return resp.Body, strings.ToLower(resp.Header.Get("Eth-Consensus-Version")), nil
+}
+
+// getSSZ retrieves the ssz encoded resource at url and returns it together with the fork version reported by the node
+func (lc *LighthouseClient) getSSZ(url string) ([]byte, string, error) {
+	body, version, err := lc.openSSZ(url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, version, nil
+}
+
+// useSSZ reports whether requests to the node should be attempted ssz encoded
+func (lc *LighthouseClient) useSSZ() bool {
+	return !lc.sszDisabled.Load()
+}
+
+// handleSSZError decides what to do after an ssz request failed, the caller always falls back to json
+func (lc *LighthouseClient) handleSSZError(resource string, err error) {
+	if errors.Is(err, errSSZUnsupported) {
+		logger.Warnf("beacon node %v does not support ssz encoded responses, falling back to json", lc.endpoint)
+		lc.sszDisabled.Store(true)
+		return
+	}
+	if errors.Is(err, errNotFound) {
+		return
+	}
+	logger.Warnf("error retrieving ssz encoded %v, falling back to json: %v", resource, err)
+}
+
+// sszDecodableForks are the forks the ssz block decoder knows, blocks of newer forks are requested json encoded right away
+// instead of downloading and discarding the ssz encoding first
+var sszDecodableForks = map[string]bool{
+	"phase0":    true,
+	"altair":    true,
+	"bellatrix": true,
+	"capella":   true,
+	"deneb":     true,
+	"electra":   true,
+}
+
+// forkVersionAtEpoch returns the name of the fork active at the given epoch, used if the node omits the Eth-Consensus-Version header
+func forkVersionAtEpoch(epoch uint64) string {
+	cfg := utils.Config.Chain.ClConfig
+	switch {
+	case epoch >= cfg.ElectraForkEpoch:
+		return "electra"
+	case epoch >= cfg.DenebForkEpoch:
+		return "deneb"
+	case epoch >= cfg.CappellaForkEpoch:
+		return "capella"
+	case epoch >= cfg.BellatrixForkEpoch:
+		return "bellatrix"
+	case epoch >= cfg.AltairForkEpoch:
+		return "altair"
+	}
+	return "phase0"
+}
+
+// getBlock retrieves the signed block with the given id, ssz encoded if the node supports it and json encoded otherwise
+func (lc *LighthouseClient) getBlock(blockID string, slot uint64) (*StandardV2BlockResponse, error) {
+	if lc.useSSZ() && sszDecodableForks[forkVersionAtEpoch(utils.EpochOfSlot(slot))] {
+		block, err := lc.getBlockSSZ(blockID, slot)
+		if err == nil {
+			return block, nil
+		}
+		lc.handleSSZError(fmt.Sprintf("block %v", blockID), err)
+	}
+
+	resp, err := lc.get(fmt.Sprintf("%s/eth/v2/beacon/blocks/%s", lc.endpoint, blockID))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving block data at slot %v: %w", slot, err)
+	}
+
+	var parsedResponse StandardV2BlockResponse
+	err = json.Unmarshal(resp, &parsedResponse)
+	if err != nil {
+		logger.Errorf("error parsing block data at slot %v: %v", slot, err)
+		return nil, fmt.Errorf("error parsing block-response at slot %v: %w", slot, err)
+	}
+	return &parsedResponse, nil
+}
+
+func (lc *LighthouseClient) getBlockSSZ(blockID string, slot uint64) (*StandardV2BlockResponse, error) {
+	data, version, err := lc.getSSZ(fmt.Sprintf("%s/eth/v2/beacon/blocks/%s", lc.endpoint, blockID))
+	if err != nil {
+		return nil, err
+	}
+	if version == "" {
+		version = forkVersionAtEpoch(utils.EpochOfSlot(slot))
+	}
+
+	block := &spec.VersionedSignedBeaconBlock{}
+	switch version {
+	case "phase0":
+		block.Version = spec.DataVersionPhase0
+		block.Phase0 = &phase0.SignedBeaconBlock{}
+		err = block.Phase0.UnmarshalSSZ(data)
+	case "altair":
+		block.Version = spec.DataVersionAltair
+		block.Altair = &altair.SignedBeaconBlock{}
+		err = block.Altair.UnmarshalSSZ(data)
+	case "bellatrix":
+		block.Version = spec.DataVersionBellatrix
+		block.Bellatrix = &bellatrix.SignedBeaconBlock{}
+		err = block.Bellatrix.UnmarshalSSZ(data)
+	case "capella":
+		block.Version = spec.DataVersionCapella
+		block.Capella = &capella.SignedBeaconBlock{}
+		err = block.Capella.UnmarshalSSZ(data)
+	case "deneb":
+		block.Version = spec.DataVersionDeneb
+		block.Deneb = &deneb.SignedBeaconBlock{}
+		err = block.Deneb.UnmarshalSSZ(data)
+	case "electra":
+		block.Version = spec.DataVersionElectra
+		block.Electra = &electra.SignedBeaconBlock{}
+		err = block.Electra.UnmarshalSSZ(data)
+	default:
+		return nil, fmt.Errorf("%w: unknown block version %v", errSSZUnknownFork, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decoding ssz block at slot %v: %w", slot, err)
+	}
+
+	return blockResponseFromSSZ(version, block)
+}
+
+// blockResponseFromSSZ converts a decoded ssz block into the structure used for the json encoded block responses
+func blockResponseFromSSZ(version string, block *spec.VersionedSignedBeaconBlock) (*StandardV2BlockResponse, error) {
+	res := &StandardV2BlockResponse{Version: version}
+	b := &res.Data
+
+	slot, err := block.Slot()
+	if err != nil {
+		return nil, err
+	}
+	proposer, err := block.ProposerIndex()
+	if err != nil {
+		return nil, err
+	}
+	parentRoot, err := block.ParentRoot()
+	if err != nil {
+		return nil, err
+	}
+	stateRoot, err := block.StateRoot()
+	if err != nil {
+		return nil, err
+	}
+	randaoReveal, err := block.RandaoReveal()
+	if err != nil {
+		return nil, err
+	}
+	graffiti, err := block.Graffiti()
+	if err != nil {
+		return nil, err
+	}
+	eth1Data, err := block.ETH1Data()
+	if err != nil {
+		return nil, err
+	}
+	proposerSlashings, err := block.ProposerSlashings()
+	if err != nil {
+		return nil, err
+	}
+	attesterSlashings, err := block.AttesterSlashings()
+	if err != nil {
+		return nil, err
+	}
+	attestations, err := block.Attestations()
+	if err != nil {
+		return nil, err
+	}
+	deposits, err := block.Deposits()
+	if err != nil {
+		return nil, err
+	}
+	voluntaryExits, err := block.VoluntaryExits()
+	if err != nil {
+		return nil, err
+	}
+
+	b.Message.Slot = uint64Str(slot)
+	b.Message.ProposerIndex = uint64Str(proposer)
+	b.Message.ParentRoot = hexStr(parentRoot[:])
+	b.Message.StateRoot = hexStr(stateRoot[:])
+	b.Message.Body.RandaoReveal = hexStr(randaoReveal[:])
+	b.Message.Body.Graffiti = hexStr(graffiti[:])
+	b.Message.Body.Eth1Data = Eth1Data{
+		DepositRoot:  hexStr(eth1Data.DepositRoot[:]),
+		DepositCount: uint64Str(eth1Data.DepositCount),
+		BlockHash:    hexStr(eth1Data.BlockHash),
+	}
+
+	b.Message.Body.ProposerSlashings = make([]ProposerSlashing, len(proposerSlashings))
+	for i, s := range proposerSlashings {
+		ps := &b.Message.Body.ProposerSlashings[i]
+		ps.SignedHeader1.Message.Slot = uint64Str(s.SignedHeader1.Message.Slot)
+		ps.SignedHeader1.Message.ProposerIndex = uint64Str(s.SignedHeader1.Message.ProposerIndex)
+		ps.SignedHeader1.Message.ParentRoot = hexStr(s.SignedHeader1.Message.ParentRoot[:])
+		ps.SignedHeader1.Message.StateRoot = hexStr(s.SignedHeader1.Message.StateRoot[:])
+		ps.SignedHeader1.Message.BodyRoot = hexStr(s.SignedHeader1.Message.BodyRoot[:])
+		ps.SignedHeader1.Signature = hexStr(s.SignedHeader1.Signature[:])
+		ps.SignedHeader2.Message.Slot = uint64Str(s.SignedHeader2.Message.Slot)
+		ps.SignedHeader2.Message.ProposerIndex = uint64Str(s.SignedHeader2.Message.ProposerIndex)
+		ps.SignedHeader2.Message.ParentRoot = hexStr(s.SignedHeader2.Message.ParentRoot[:])
+		ps.SignedHeader2.Message.StateRoot = hexStr(s.SignedHeader2.Message.StateRoot[:])
+		ps.SignedHeader2.Message.BodyRoot = hexStr(s.SignedHeader2.Message.BodyRoot[:])
+		ps.SignedHeader2.Signature = hexStr(s.SignedHeader2.Signature[:])
+	}
+
+	b.Message.Body.AttesterSlashings = make([]AttesterSlashing, len(attesterSlashings))
+	for i, s := range attesterSlashings {
+		as := &b.Message.Body.AttesterSlashings[i]
+		attestation1, err := s.Attestation1()
+		if err != nil {
+			return nil, err
+		}
+		attestation2, err := s.Attestation2()
+		if err != nil {
+			return nil, err
+		}
+		as.Attestation1.AttestingIndices, as.Attestation1.Signature, err = indexedAttestationFromSSZ(attestation1, &as.Attestation1.Data)
+		if err != nil {
+			return nil, err
+		}
+		as.Attestation2.AttestingIndices, as.Attestation2.Signature, err = indexedAttestationFromSSZ(attestation2, &as.Attestation2.Data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	b.Message.Body.Attestations = make([]Attestation, len(attestations))
+	for i, a := range attestations {
+		att := &b.Message.Body.Attestations[i]
+		aggregationBits, err := a.AggregationBits()
+		if err != nil {
+			return nil, err
+		}
+		signature, err := a.Signature()
+		if err != nil {
+			return nil, err
+		}
+		data, err := a.Data()
+		if err != nil {
+			return nil, err
+		}
+		att.AggregationBits = hexStr(aggregationBits)
+		att.Signature = hexStr(signature[:])
+		attestationDataFromSSZ(data, &att.Data)
+		if block.Version >= spec.DataVersionElectra {
+			committeeBits, err := a.CommitteeBits()
+			if err != nil {
+				return nil, err
+			}
+			att.CommitteeBits = hexStr(committeeBits)
+		}
+	}
+
+	b.Message.Body.Deposits = make([]Deposit, len(deposits))
+	for i, d := range deposits {
+		dep := &b.Message.Body.Deposits[i]
+		dep.Proof = make([]string, len(d.Proof))
+		for j, p := range d.Proof {
+			dep.Proof[j] = hexStr(p)
+		}
+		dep.Data.Pubkey = hexStr(d.Data.PublicKey[:])
+		dep.Data.WithdrawalCredentials = hexStr(d.Data.WithdrawalCredentials)
+		dep.Data.Amount = uint64Str(d.Data.Amount)
+		dep.Data.Signature = hexStr(d.Data.Signature[:])
+	}
+
+	b.Message.Body.VoluntaryExits = make([]VoluntaryExit, len(voluntaryExits))
+	for i, e := range voluntaryExits {
+		exit := &b.Message.Body.VoluntaryExits[i]
+		exit.Message.Epoch = uint64Str(e.Message.Epoch)
+		exit.Message.ValidatorIndex = uint64Str(e.Message.ValidatorIndex)
+		exit.Signature = hexStr(e.Signature[:])
+	}
+
+	if block.Version >= spec.DataVersionAltair {
+		agg, err := block.SyncAggregate()
+		if err != nil {
+			return nil, err
+		}
+		b.Message.Body.SyncAggregate = &SyncAggregate{
+			SyncCommitteeBits:      hexStr(agg.SyncCommitteeBits),
+			SyncCommitteeSignature: hexStr(agg.SyncCommitteeSignature[:]),
+		}
+	}
+
+	if block.Version >= spec.DataVersionCapella {
+		changes, err := block.BLSToExecutionChanges()
+		if err != nil {
+			return nil, err
+		}
+		b.Message.Body.SignedBLSToExecutionChange = make([]*SignedBLSToExecutionChange, len(changes))
+		for i, c := range changes {
+			change := &SignedBLSToExecutionChange{}
+			change.Message.ValidatorIndex = uint64Str(c.Message.ValidatorIndex)
+			change.Message.FromBlsPubkey = c.Message.FromBLSPubkey[:]
+			change.Message.ToExecutionAddress = c.Message.ToExecutionAddress[:]
+			change.Signature = c.Signature[:]
+			b.Message.Body.SignedBLSToExecutionChange[i] = change
+		}
+	}
+
+	if block.Version >= spec.DataVersionElectra {
+		requests, err := block.ExecutionRequests()
+		if err != nil {
+			return nil, err
+		}
+		b.Message.Body.ExecutionRequests = executionRequestsFromSSZ(requests)
+	}
+
+	if block.Version >= spec.DataVersionDeneb {
+		commitments, err := block.BlobKZGCommitments()
+		if err != nil {
+			return nil, err
+		}
+		b.Message.Body.BlobKZGCommitments = make([]bytesHexStr, len(commitments))
+		for i, c := range commitments {
+			b.Message.Body.BlobKZGCommitments[i] = c[:]
+		}
+	}
+
+	switch block.Version {
+	case spec.DataVersionPhase0:
+		b.Signature = block.Phase0.Signature[:]
+	case spec.DataVersionAltair:
+		b.Signature = block.Altair.Signature[:]
+	case spec.DataVersionBellatrix:
+		b.Signature = block.Bellatrix.Signature[:]
+		b.Message.Body.ExecutionPayload = bellatrixPayloadFromSSZ(block.Bellatrix.Message.Body.ExecutionPayload)
+	case spec.DataVersionCapella:
+		b.Signature = block.Capella.Signature[:]
+		b.Message.Body.ExecutionPayload = capellaPayloadFromSSZ(block.Capella.Message.Body.ExecutionPayload)
+	case spec.DataVersionDeneb:
+		b.Signature = block.Deneb.Signature[:]
+		b.Message.Body.ExecutionPayload = denebPayloadFromSSZ(block.Deneb.Message.Body.ExecutionPayload)
+	case spec.DataVersionElectra:
+		b.Signature = block.Electra.Signature[:]
+		b.Message.Body.ExecutionPayload = denebPayloadFromSSZ(block.Electra.Message.Body.ExecutionPayload)
+	}
+
+	return res, nil
+}
+
+// attestationDataFromSSZ converts the data of a decoded ssz attestation into the structure used for the json encoded attestations
+func attestationDataFromSSZ(d *phase0.AttestationData, data *AttestationData) {
+	data.Slot = uint64Str(d.Slot)
+	data.Index = uint64Str(d.Index)
+	data.BeaconBlockRoot = hexStr(d.BeaconBlockRoot[:])
+	data.Source.Epoch = uint64Str(d.Source.Epoch)
+	data.Source.Root = hexStr(d.Source.Root[:])
+	data.Target.Epoch = uint64Str(d.Target.Epoch)
+	data.Target.Root = hexStr(d.Target.Root[:])
+}
+
+// indexedAttestationFromSSZ converts a decoded ssz indexed attestation of an attester slashing, it returns the attesting indices and the signature
+func indexedAttestationFromSSZ(a *spec.VersionedIndexedAttestation, data *AttestationData) ([]uint64Str, string, error) {
+	indices, err := a.AttestingIndices()
+	if err != nil {
+		return nil, "", err
+	}
+	signature, err := a.Signature()
+	if err != nil {
+		return nil, "", err
+	}
+	d, err := a.Data()
+	if err != nil {
+		return nil, "", err
+	}
+	res := make([]uint64Str, len(indices))
+	for i, idx := range indices {
+		res[i] = uint64Str(idx)
+	}
+	attestationDataFromSSZ(d, data)
+	return res, hexStr(signature[:]), nil
+}
+
+func executionRequestsFromSSZ(r *electra.ExecutionRequests) *ExecutionRequests {
+	if r == nil {
+		return nil
+	}
+	res := &ExecutionRequests{
+		Deposits:       make([]*DepositRequest, len(r.Deposits)),
+		Withdrawals:    make([]*WithdrawalRequest, len(r.Withdrawals)),
+		Consolidations: make([]*ConsolidationRequest, len(r.Consolidations)),
+	}
+	for i, d := range r.Deposits {
+		res.Deposits[i] = &DepositRequest{
+			Pubkey:                d.Pubkey[:],
+			WithdrawalCredentials: d.WithdrawalCredentials,
+			Amount:                uint64Str(d.Amount),
+			Signature:             d.Signature[:],
+			Index:                 uint64Str(d.Index),
+		}
+	}
+	for i, w := range r.Withdrawals {
+		res.Withdrawals[i] = &WithdrawalRequest{
+			SourceAddress:   w.SourceAddress[:],
+			ValidatorPubkey: w.ValidatorPubkey[:],
+			Amount:          uint64Str(w.Amount),
+		}
+	}
+	for i, c := range r.Consolidations {
+		res.Consolidations[i] = &ConsolidationRequest{
+			SourceAddress: c.SourceAddress[:],
+			SourcePubkey:  c.SourcePubkey[:],
+			TargetPubkey:  c.TargetPubkey[:],
+		}
+	}
+	return res
+}
+
+func bellatrixPayloadFromSSZ(p *bellatrix.ExecutionPayload) *ExecutionPayload {
+	if p == nil {
+		return nil
+	}
+	return &ExecutionPayload{
+		ParentHash:    p.ParentHash[:],
+		FeeRecipient:  p.FeeRecipient[:],
+		StateRoot:     p.StateRoot[:],
+		ReceiptsRoot:  p.ReceiptsRoot[:],
+		LogsBloom:     p.LogsBloom[:],
+		PrevRandao:    p.PrevRandao[:],
+		BlockNumber:   uint64Str(p.BlockNumber),
+		GasLimit:      uint64Str(p.GasLimit),
+		GasUsed:       uint64Str(p.GasUsed),
+		Timestamp:     uint64Str(p.Timestamp),
+		ExtraData:     p.ExtraData,
+		BaseFeePerGas: uint64Str(littleEndianToUint64(p.BaseFeePerGas[:])),
+		BlockHash:     p.BlockHash[:],
+		Transactions:  transactionsFromSSZ(p.Transactions),
+	}
+}
+
+func capellaPayloadFromSSZ(p *capella.ExecutionPayload) *ExecutionPayload {
+	if p == nil {
+		return nil
+	}
+	return &ExecutionPayload{
+		ParentHash:    p.ParentHash[:],
+		FeeRecipient:  p.FeeRecipient[:],
+		StateRoot:     p.StateRoot[:],
+		ReceiptsRoot:  p.ReceiptsRoot[:],
+		LogsBloom:     p.LogsBloom[:],
+		PrevRandao:    p.PrevRandao[:],
+		BlockNumber:   uint64Str(p.BlockNumber),
+		GasLimit:      uint64Str(p.GasLimit),
+		GasUsed:       uint64Str(p.GasUsed),
+		Timestamp:     uint64Str(p.Timestamp),
+		ExtraData:     p.ExtraData,
+		BaseFeePerGas: uint64Str(littleEndianToUint64(p.BaseFeePerGas[:])),
+		BlockHash:     p.BlockHash[:],
+		Transactions:  transactionsFromSSZ(p.Transactions),
+		Withdrawals:   withdrawalsFromSSZ(p.Withdrawals),
+	}
+}
+
+func denebPayloadFromSSZ(p *deneb.ExecutionPayload) *ExecutionPayload {
+	if p == nil {
+		return nil
+	}
+	payload := &ExecutionPayload{
+		ParentHash:    p.ParentHash[:],
+		FeeRecipient:  p.FeeRecipient[:],
+		StateRoot:     p.StateRoot[:],
+		ReceiptsRoot:  p.ReceiptsRoot[:],
+		LogsBloom:     p.LogsBloom[:],
+		PrevRandao:    p.PrevRandao[:],
+		BlockNumber:   uint64Str(p.BlockNumber),
+		GasLimit:      uint64Str(p.GasLimit),
+		GasUsed:       uint64Str(p.GasUsed),
+		Timestamp:     uint64Str(p.Timestamp),
+		ExtraData:     p.ExtraData,
+		BlockHash:     p.BlockHash[:],
+		Transactions:  transactionsFromSSZ(p.Transactions),
+		Withdrawals:   withdrawalsFromSSZ(p.Withdrawals),
+		BlobGasUsed:   uint64Str(p.BlobGasUsed),
+		ExcessBlobGas: uint64Str(p.ExcessBlobGas),
+	}
+	if p.BaseFeePerGas != nil {
+		payload.BaseFeePerGas = uint64Str(p.BaseFeePerGas.Uint64())
+	}
+	return payload
+}
+
+func transactionsFromSSZ(txs []bellatrix.Transaction) []bytesHexStr {
+	res := make([]bytesHexStr, len(txs))
+	for i, tx := range txs {
+		res[i] = bytesHexStr(tx)
+	}
+	return res
+}
+
+func withdrawalsFromSSZ(withdrawals []*capella.Withdrawal) []WithdrawalPayload {
+	res := make([]WithdrawalPayload, len(withdrawals))
+	for i, w := range withdrawals {
+		res[i] = WithdrawalPayload{
+			Index:          uint64Str(w.Index),
+			ValidatorIndex: uint64Str(w.ValidatorIndex),
+			Address:        w.Address[:],
+			Amount:         uint64Str(w.Amount),
+		}
+	}
+	return res
+}
+
+// littleEndianToUint64 converts a little endian encoded uint256 (as used for the base fee in ssz) to an uint64
+func littleEndianToUint64(b []byte) uint64 {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be).Uint64()
+}
+
+func hexStr(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
+
+// getValidators retrieves all validators at the first slot of the given epoch,
+// decoded from the ssz encoded state if the node supports it and from the json validator list otherwise
+func (lc *LighthouseClient) getValidators(epoch uint64) ([]*types.Validator, error) {
+	if lc.useSSZ() {
+		validators, err := lc.getValidatorsSSZ(epoch)
+		if err == nil {
+			return validators, nil
+		}
+		lc.handleSSZError(fmt.Sprintf("state of epoch %v", epoch), err)
+	}
+
+	validatorsResp, err := lc.get(fmt.Sprintf("%s/eth/v1/beacon/states/%d/validators", lc.endpoint, epoch*utils.Config.Chain.ClConfig.SlotsPerEpoch))
+	if err != nil && epoch == 0 {
+		validatorsResp, err = lc.get(fmt.Sprintf("%s/eth/v1/beacon/states/%v/validators", lc.endpoint, "genesis"))
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving validators for genesis: %w", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("error retrieving validators for epoch %v: %w", epoch, err)
+	}
+
+	var parsedValidators StandardValidatorsResponse
+	err = json.Unmarshal(validatorsResp, &parsedValidators)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing epoch validators: %w", err)
+	}
+
+	validators := make([]*types.Validator, 0, len(parsedValidators.Data))
+	for _, validator := range parsedValidators.Data {
+		validators = append(validators, &types.Validator{
+			Index:                      uint64(validator.Index),
+			PublicKey:                  utils.MustParseHex(validator.Validator.Pubkey),
+			WithdrawalCredentials:      utils.MustParseHex(validator.Validator.WithdrawalCredentials),
+			Balance:                    uint64(validator.Balance),
+			EffectiveBalance:           uint64(validator.Validator.EffectiveBalance),
+			Slashed:                    validator.Validator.Slashed,
+			ActivationEligibilityEpoch: uint64(validator.Validator.ActivationEligibilityEpoch),
+			ActivationEpoch:            uint64(validator.Validator.ActivationEpoch),
+			ExitEpoch:                  uint64(validator.Validator.ExitEpoch),
+			WithdrawableEpoch:          uint64(validator.Validator.WithdrawableEpoch),
+			Status:                     validator.Status,
+		})
+	}
+	return validators, nil
+}
+
+func (lc *LighthouseClient) getValidatorsSSZ(epoch uint64) ([]*types.Validator, error) {
+	body, _, err := lc.openSSZ(fmt.Sprintf("%s/eth/v2/debug/beacon/states/%d", lc.endpoint, epoch*utils.Config.Chain.ClConfig.SlotsPerEpoch))
+	if err != nil && epoch == 0 {
+		body, _, err = lc.openSSZ(fmt.Sprintf("%s/eth/v2/debug/beacon/states/%v", lc.endpoint, "genesis"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	validators, err := decodeStateValidators(bufio.NewReaderSize(body, 1<<20), epoch)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding ssz state for epoch %v: %w", epoch, err)
+	}
+	return validators, nil
+}
+
+const (
+	sszValidatorSize = 121
+	sszBalanceSize   = 8
+)
+
+// decodeStateValidators decodes the validators and their balances from a ssz encoded beacon state while it is read.
+// A mainnet state is several hundred MB large, so instead of decoding the whole state only the fields up to the
+// validators and balances offsets are read and the validators are decoded one by one from the stream.
+// The layout of the state up to the balances is the same for all forks from phase0 to electra.
+func decodeStateValidators(r io.Reader, epoch uint64) ([]*types.Validator, error) {
+	// genesis_time, genesis_validators_root, slot, fork, latest_block_header, block_roots, state_roots,
+	// historical_roots (offset), eth1_data, eth1_data_votes (offset), eth1_deposit_index
+	validatorsOffsetPos := int64(8 + 32 + 8 + 16 + 112 + 2*32*utils.Config.Chain.ClConfig.SlotsPerHistoricalRoot + 4 + 72 + 4 + 8)
+
+	_, err := io.CopyN(io.Discard, r, validatorsOffsetPos)
+	if err != nil {
+		return nil, fmt.Errorf("error reading fixed fields: %w", err)
+	}
+	offsets := make([]byte, 8)
+	_, err = io.ReadFull(r, offsets)
+	if err != nil {
+		return nil, fmt.Errorf("error reading validators offsets: %w", err)
+	}
+	validatorsOffset := int64(binary.LittleEndian.Uint32(offsets[0:4]))
+	balancesOffset := int64(binary.LittleEndian.Uint32(offsets[4:8]))
+	pos := validatorsOffsetPos + 8
+	if validatorsOffset < pos || balancesOffset < validatorsOffset || (balancesOffset-validatorsOffset)%sszValidatorSize != 0 {
+		return nil, fmt.Errorf("invalid validators offsets %v and %v", validatorsOffset, balancesOffset)
+	}
+
+	_, err = io.CopyN(io.Discard, r, validatorsOffset-pos)
+	if err != nil {
+		return nil, fmt.Errorf("error reading up to the validators: %w", err)
+	}
+
+	count := (balancesOffset - validatorsOffset) / sszValidatorSize
+	validators := make([]*types.Validator, count)
+	buf := make([]byte, sszValidatorSize)
+	for i := range validators {
+		_, err = io.ReadFull(r, buf)
+		if err != nil {
+			return nil, fmt.Errorf("error reading validator %v: %w", i, err)
+		}
+		v := &phase0.Validator{}
+		err = v.UnmarshalSSZ(buf)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding validator %v: %w", i, err)
+		}
+		validators[i] = &types.Validator{
+			Index:                      uint64(i),
+			PublicKey:                  v.PublicKey[:],
+			WithdrawalCredentials:      v.WithdrawalCredentials,
+			EffectiveBalance:           uint64(v.EffectiveBalance),
+			Slashed:                    v.Slashed,
+			ActivationEligibilityEpoch: uint64(v.ActivationEligibilityEpoch),
+			ActivationEpoch:            uint64(v.ActivationEpoch),
+			ExitEpoch:                  uint64(v.ExitEpoch),
+			WithdrawableEpoch:          uint64(v.WithdrawableEpoch),
+		}
+	}
+
+	// the balances list directly follows the validators and has one entry per validator
+	buf = buf[:sszBalanceSize]
+	for i, v := range validators {
+		_, err = io.ReadFull(r, buf)
+		if err != nil {
+			return nil, fmt.Errorf("error reading balance %v: %w", i, err)
+		}
+		v.Balance = binary.LittleEndian.Uint64(buf)
+		v.Status = validatorStatus(v, epoch)
+	}
+	return validators, nil
+}
+
+// validatorsResponseFromValidators converts validators decoded from an ssz state into the validators endpoint response
+func validatorsResponseFromValidators(validators []*types.Validator) *StandardValidatorsResponse {
+	res := &StandardValidatorsResponse{Data: make([]StandardValidatorEntry, len(validators))}
+	for i, v := range validators {
+		entry := &res.Data[i]
+		entry.Index = uint64Str(v.Index)
+		entry.Balance = uint64Str(v.Balance)
+		entry.Status = v.Status
+		entry.Validator.Pubkey = hexStr(v.PublicKey)
+		entry.Validator.WithdrawalCredentials = hexStr(v.WithdrawalCredentials)
+		entry.Validator.EffectiveBalance = uint64Str(v.EffectiveBalance)
+		entry.Validator.Slashed = v.Slashed
+		entry.Validator.ActivationEligibilityEpoch = uint64Str(v.ActivationEligibilityEpoch)
+		entry.Validator.ActivationEpoch = uint64Str(v.ActivationEpoch)
+		entry.Validator.ExitEpoch = uint64Str(v.ExitEpoch)
+		entry.Validator.WithdrawableEpoch = uint64Str(v.WithdrawableEpoch)
+	}
+	return res
+}
+
+// validatorStatus derives the status of a validator at the given epoch as reported by the validators endpoint,
+// see https://hackmd.io/ofFJ5gOmQpu1jjHilHbdQQ
+func validatorStatus(v *types.Validator, epoch uint64) string {
+	switch {
+	case v.ActivationEpoch > epoch:
+		if v.ActivationEligibilityEpoch == farFutureEpoch {
+			return "pending_initialized"
+		}
+		return "pending_queued"
+	case v.ExitEpoch > epoch:
+		if v.ExitEpoch == farFutureEpoch {
+			return "active_ongoing"
+		}
+		if v.Slashed {
+			return "active_slashed"
+		}
+		return "active_exiting"
+	case v.WithdrawableEpoch > epoch:
+		if v.Slashed {
+			return "exited_slashed"
+		}
+		return "exited_unslashed"
+	case v.Balance != 0:
+		return "withdrawal_possible"
+	}
+	return "withdrawal_done"
+}
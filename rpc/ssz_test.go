@@ -0,0 +1,432 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/holiman/uint256"
+	"github.com/prysmaticlabs/go-bitfield"
+)
+
+func TestGetBlockSkipsSSZForUnknownForks(t *testing.T) {
+	prevConfig := utils.Config
+	defer func() { utils.Config = prevConfig }()
+	utils.Config = &types.Config{}
+	utils.Config.Chain.ClConfig.SlotsPerEpoch = 32
+	utils.Config.Chain.ClConfig.DenebForkEpoch = 10
+	utils.Config.Chain.ClConfig.ElectraForkEpoch = 20
+	// pretend the decoder does not know electra yet, as it will be the case for the next fork
+	delete(sszDecodableForks, "electra")
+	defer func() { sszDecodableForks["electra"] = true }()
+
+	sszRequests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.Header.Get("Accept"), "application/octet-stream") {
+			sszRequests++
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version":"electra","data":{"message":{"slot":"640"}}}`))
+	}))
+	defer srv.Close()
+
+	lc, err := NewLighthouseClient(srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = lc.getBlock("640", 640)
+	if err != nil {
+		t.Fatalf("error retrieving electra block: %v", err)
+	}
+	if sszRequests != 0 {
+		t.Errorf("requested the electra block ssz encoded")
+	}
+
+	_, err = lc.getBlock("320", 320)
+	if err != nil {
+		t.Fatalf("error retrieving deneb block: %v", err)
+	}
+	if sszRequests != 1 {
+		t.Errorf("did not request the deneb block ssz encoded")
+	}
+}
+
+// fill returns n bytes of the given value, used to build distinguishable test data
+func fill(n int, b byte) []byte {
+	return bytes.Repeat([]byte{b}, n)
+}
+
+func testRoot(b byte) (r phase0.Root) {
+	copy(r[:], fill(32, b))
+	return r
+}
+
+func testSignature(b byte) (s phase0.BLSSignature) {
+	copy(s[:], fill(96, b))
+	return s
+}
+
+func testPubkey(b byte) (p phase0.BLSPubKey) {
+	copy(p[:], fill(48, b))
+	return p
+}
+
+func testAttestationData(slot phase0.Slot) *phase0.AttestationData {
+	return &phase0.AttestationData{
+		Slot:            slot,
+		Index:           2,
+		BeaconBlockRoot: testRoot(0x11),
+		Source:          &phase0.Checkpoint{Epoch: 3, Root: testRoot(0x12)},
+		Target:          &phase0.Checkpoint{Epoch: 4, Root: testRoot(0x13)},
+	}
+}
+
+func testBlockHeader(slot phase0.Slot) *phase0.SignedBeaconBlockHeader {
+	return &phase0.SignedBeaconBlockHeader{
+		Message: &phase0.BeaconBlockHeader{
+			Slot:          slot,
+			ProposerIndex: 7,
+			ParentRoot:    testRoot(0x21),
+			StateRoot:     testRoot(0x22),
+			BodyRoot:      testRoot(0x23),
+		},
+		Signature: testSignature(0x24),
+	}
+}
+
+func testDenebBlock() *deneb.SignedBeaconBlock {
+	proof := make([][]byte, 33)
+	for i := range proof {
+		proof[i] = fill(32, byte(i))
+	}
+	syncBits := bitfield.NewBitvector512()
+	syncBits.SetBitAt(3, true)
+	aggregationBits := bitfield.NewBitlist(8)
+	aggregationBits.SetBitAt(1, true)
+
+	return &deneb.SignedBeaconBlock{
+		Message: &deneb.BeaconBlock{
+			Slot:          325,
+			ProposerIndex: 7,
+			ParentRoot:    testRoot(0x01),
+			StateRoot:     testRoot(0x02),
+			Body: &deneb.BeaconBlockBody{
+				RANDAOReveal: testSignature(0x03),
+				ETH1Data:     &phase0.ETH1Data{DepositRoot: testRoot(0x04), DepositCount: 12, BlockHash: fill(32, 0x05)},
+				Graffiti:     testRoot(0x06),
+				ProposerSlashings: []*phase0.ProposerSlashing{
+					{SignedHeader1: testBlockHeader(300), SignedHeader2: testBlockHeader(300)},
+				},
+				AttesterSlashings: []*phase0.AttesterSlashing{
+					{
+						Attestation1: &phase0.IndexedAttestation{AttestingIndices: []uint64{1, 2}, Data: testAttestationData(310), Signature: testSignature(0x31)},
+						Attestation2: &phase0.IndexedAttestation{AttestingIndices: []uint64{2, 3}, Data: testAttestationData(310), Signature: testSignature(0x32)},
+					},
+				},
+				Attestations: []*phase0.Attestation{
+					{AggregationBits: aggregationBits, Data: testAttestationData(324), Signature: testSignature(0x33)},
+				},
+				Deposits: []*phase0.Deposit{
+					{Proof: proof, Data: &phase0.DepositData{PublicKey: testPubkey(0x41), WithdrawalCredentials: fill(32, 0x42), Amount: 32000000000, Signature: testSignature(0x43)}},
+				},
+				VoluntaryExits: []*phase0.SignedVoluntaryExit{
+					{Message: &phase0.VoluntaryExit{Epoch: 9, ValidatorIndex: 5}, Signature: testSignature(0x51)},
+				},
+				SyncAggregate: &altair.SyncAggregate{SyncCommitteeBits: syncBits, SyncCommitteeSignature: testSignature(0x61)},
+				ExecutionPayload: &deneb.ExecutionPayload{
+					ParentHash:    phase0.Hash32(testRoot(0x71)),
+					FeeRecipient:  bellatrix.ExecutionAddress{0x72},
+					StateRoot:     testRoot(0x73),
+					ReceiptsRoot:  testRoot(0x74),
+					PrevRandao:    testRoot(0x75),
+					BlockNumber:   1000,
+					GasLimit:      30000000,
+					GasUsed:       21000,
+					Timestamp:     1700000000,
+					ExtraData:     []byte("test"),
+					BaseFeePerGas: uint256.NewInt(7),
+					BlockHash:     phase0.Hash32(testRoot(0x76)),
+					Transactions:  []bellatrix.Transaction{fill(10, 0x77)},
+					Withdrawals: []*capella.Withdrawal{
+						{Index: 1, ValidatorIndex: 2, Address: bellatrix.ExecutionAddress{0x78}, Amount: 1234},
+					},
+					BlobGasUsed:   131072,
+					ExcessBlobGas: 0,
+				},
+				BLSToExecutionChanges: []*capella.SignedBLSToExecutionChange{
+					{Message: &capella.BLSToExecutionChange{ValidatorIndex: 4, FromBLSPubkey: testPubkey(0x81), ToExecutionAddress: bellatrix.ExecutionAddress{0x82}}, Signature: testSignature(0x83)},
+				},
+				BlobKZGCommitments: []deneb.KZGCommitment{{0x91}},
+			},
+		},
+		Signature: testSignature(0x99),
+	}
+}
+
+func testElectraBlock() *electra.SignedBeaconBlock {
+	denebBlock := testDenebBlock()
+	body := denebBlock.Message.Body
+	aggregationBits := bitfield.NewBitlist(16)
+	aggregationBits.SetBitAt(1, true)
+	aggregationBits.SetBitAt(9, true)
+	committeeBits := bitfield.NewBitvector64()
+	committeeBits.SetBitAt(0, true)
+	committeeBits.SetBitAt(2, true)
+	data := testAttestationData(324)
+	data.Index = 0
+
+	return &electra.SignedBeaconBlock{
+		Message: &electra.BeaconBlock{
+			Slot:          denebBlock.Message.Slot,
+			ProposerIndex: denebBlock.Message.ProposerIndex,
+			ParentRoot:    denebBlock.Message.ParentRoot,
+			StateRoot:     denebBlock.Message.StateRoot,
+			Body: &electra.BeaconBlockBody{
+				RANDAOReveal:      body.RANDAOReveal,
+				ETH1Data:          body.ETH1Data,
+				Graffiti:          body.Graffiti,
+				ProposerSlashings: body.ProposerSlashings,
+				AttesterSlashings: []*electra.AttesterSlashing{
+					{
+						Attestation1: &electra.IndexedAttestation{AttestingIndices: []uint64{1, 2}, Data: testAttestationData(310), Signature: testSignature(0x31)},
+						Attestation2: &electra.IndexedAttestation{AttestingIndices: []uint64{2, 3}, Data: testAttestationData(310), Signature: testSignature(0x32)},
+					},
+				},
+				Attestations: []*electra.Attestation{
+					{AggregationBits: aggregationBits, Data: data, Signature: testSignature(0x33), CommitteeBits: committeeBits},
+				},
+				Deposits:              body.Deposits,
+				VoluntaryExits:        body.VoluntaryExits,
+				SyncAggregate:         body.SyncAggregate,
+				ExecutionPayload:      body.ExecutionPayload,
+				BLSToExecutionChanges: body.BLSToExecutionChanges,
+				BlobKZGCommitments:    body.BlobKZGCommitments,
+				ExecutionRequests: &electra.ExecutionRequests{
+					Deposits: []*electra.DepositRequest{
+						{Pubkey: testPubkey(0xa1), WithdrawalCredentials: fill(32, 0xa2), Amount: 32000000000, Signature: testSignature(0xa3), Index: 17},
+					},
+					Withdrawals: []*electra.WithdrawalRequest{
+						{SourceAddress: bellatrix.ExecutionAddress{0xb1}, ValidatorPubkey: testPubkey(0xb2), Amount: 1000},
+					},
+					Consolidations: []*electra.ConsolidationRequest{
+						{SourceAddress: bellatrix.ExecutionAddress{0xc1}, SourcePubkey: testPubkey(0xc2), TargetPubkey: testPubkey(0xc3)},
+					},
+				},
+			},
+		},
+		Signature: denebBlock.Signature,
+	}
+}
+
+// TestGetBlockSSZRoundTrip checks that a block decoded from its ssz encoding matches the same block decoded from its json encoding
+func TestGetBlockSSZRoundTrip(t *testing.T) {
+	prevConfig := utils.Config
+	defer func() { utils.Config = prevConfig }()
+	utils.Config = &types.Config{}
+	utils.Config.Chain.ClConfig.SlotsPerEpoch = 32
+	utils.Config.Chain.ClConfig.ElectraForkEpoch = farFutureEpoch
+
+	blocks := map[string]interface {
+		MarshalSSZ() ([]byte, error)
+	}{
+		"deneb":   testDenebBlock(),
+		"electra": testElectraBlock(),
+	}
+	for version, block := range blocks {
+		t.Run(version, func(t *testing.T) {
+			sszData, err := block.MarshalSSZ()
+			if err != nil {
+				t.Fatal(err)
+			}
+			jsonBlock, err := json.Marshal(block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			jsonData := []byte(`{"version":"` + version + `","data":` + string(jsonBlock) + `}`)
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.Header.Get("Accept"), "application/octet-stream") {
+					w.Header().Set("Content-Type", "application/octet-stream")
+					w.Header().Set("Eth-Consensus-Version", version)
+					_, _ = w.Write(sszData)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write(jsonData)
+			}))
+			defer srv.Close()
+
+			lc, err := NewLighthouseClient(srv.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fromSSZ, err := lc.getBlockSSZ("325", 325)
+			if err != nil {
+				t.Fatalf("error decoding ssz block: %v", err)
+			}
+			lc.sszDisabled.Store(true)
+			fromJSON, err := lc.getBlock("325", 325)
+			if err != nil {
+				t.Fatalf("error decoding json block: %v", err)
+			}
+
+			if !reflect.DeepEqual(fromSSZ, fromJSON) {
+				sszOut, _ := json.MarshalIndent(fromSSZ, "", "  ")
+				jsonOut, _ := json.MarshalIndent(fromJSON, "", "  ")
+				t.Errorf("ssz decoded block does not match json decoded block\nssz:\n%s\njson:\n%s", sszOut, jsonOut)
+			}
+			if version == "electra" {
+				body := fromSSZ.Data.Message.Body
+				if body.Attestations[0].CommitteeBits != "0x0500000000000000" {
+					t.Errorf("got committee bits %v, want 0x0500000000000000", body.Attestations[0].CommitteeBits)
+				}
+				if body.ExecutionRequests == nil || len(body.ExecutionRequests.Deposits) != 1 || len(body.ExecutionRequests.Withdrawals) != 1 || len(body.ExecutionRequests.Consolidations) != 1 {
+					t.Errorf("unexpected execution requests %+v", body.ExecutionRequests)
+				}
+			}
+		})
+	}
+}
+
+func testValidators() ([]*phase0.Validator, []phase0.Gwei) {
+	validators := make([]*phase0.Validator, 5)
+	balances := make([]phase0.Gwei, 5)
+	for i := range validators {
+		validators[i] = &phase0.Validator{
+			PublicKey:                  testPubkey(byte(i + 1)),
+			WithdrawalCredentials:      fill(32, byte(i+10)),
+			EffectiveBalance:           phase0.Gwei(32000000000 - i),
+			ActivationEligibilityEpoch: 0,
+			ActivationEpoch:            phase0.Epoch(i),
+			ExitEpoch:                  phase0.Epoch(farFutureEpoch),
+			WithdrawableEpoch:          phase0.Epoch(farFutureEpoch),
+		}
+		balances[i] = phase0.Gwei(32000000000 + i)
+	}
+	validators[3].Slashed = true
+	validators[3].ExitEpoch = 2
+	validators[3].WithdrawableEpoch = 10
+	validators[4].ActivationEligibilityEpoch = phase0.Epoch(farFutureEpoch)
+	validators[4].ActivationEpoch = phase0.Epoch(farFutureEpoch)
+	return validators, balances
+}
+
+func testRoots(n int) []phase0.Root {
+	return make([]phase0.Root, n)
+}
+
+// TestDecodeStateValidators checks that the validators decoded from a streamed ssz state match the validators of the encoded state
+func TestDecodeStateValidators(t *testing.T) {
+	prevConfig := utils.Config
+	defer func() { utils.Config = prevConfig }()
+	utils.Config = &types.Config{}
+	utils.Config.Chain.ClConfig.SlotsPerHistoricalRoot = 8192
+
+	validators, balances := testValidators()
+	fork := &phase0.Fork{}
+	header := &phase0.BeaconBlockHeader{}
+	eth1Data := &phase0.ETH1Data{BlockHash: fill(32, 0)}
+	checkpoint := &phase0.Checkpoint{}
+	// the historical roots and eth1 data votes are stored in front of the validators
+	historicalRoots := []phase0.Root{testRoot(1), testRoot(2)}
+	eth1DataVotes := []*phase0.ETH1Data{eth1Data, eth1Data, eth1Data}
+
+	phase0State := &phase0.BeaconState{
+		Slot:                        96,
+		Fork:                        fork,
+		LatestBlockHeader:           header,
+		BlockRoots:                  testRoots(8192),
+		StateRoots:                  testRoots(8192),
+		HistoricalRoots:             historicalRoots,
+		ETH1Data:                    eth1Data,
+		ETH1DataVotes:               eth1DataVotes,
+		Validators:                  validators,
+		Balances:                    balances,
+		RANDAOMixes:                 testRoots(65536),
+		Slashings:                   make([]phase0.Gwei, 8192),
+		JustificationBits:           bitfield.NewBitvector4(),
+		PreviousJustifiedCheckpoint: checkpoint,
+		CurrentJustifiedCheckpoint:  checkpoint,
+		FinalizedCheckpoint:         checkpoint,
+	}
+	syncCommittee := &altair.SyncCommittee{Pubkeys: make([]phase0.BLSPubKey, 512)}
+	denebState := &deneb.BeaconState{
+		Slot:                         96,
+		Fork:                         fork,
+		LatestBlockHeader:            header,
+		BlockRoots:                   testRoots(8192),
+		StateRoots:                   testRoots(8192),
+		HistoricalRoots:              historicalRoots,
+		ETH1Data:                     eth1Data,
+		ETH1DataVotes:                eth1DataVotes,
+		Validators:                   validators,
+		Balances:                     balances,
+		RANDAOMixes:                  testRoots(65536),
+		Slashings:                    make([]phase0.Gwei, 8192),
+		PreviousEpochParticipation:   make([]altair.ParticipationFlags, len(validators)),
+		CurrentEpochParticipation:    make([]altair.ParticipationFlags, len(validators)),
+		JustificationBits:            bitfield.NewBitvector4(),
+		PreviousJustifiedCheckpoint:  checkpoint,
+		CurrentJustifiedCheckpoint:   checkpoint,
+		FinalizedCheckpoint:          checkpoint,
+		InactivityScores:             make([]uint64, len(validators)),
+		CurrentSyncCommittee:         syncCommittee,
+		NextSyncCommittee:            syncCommittee,
+		LatestExecutionPayloadHeader: &deneb.ExecutionPayloadHeader{BaseFeePerGas: uint256.NewInt(0)},
+		HistoricalSummaries:          []*capella.HistoricalSummary{{}},
+	}
+
+	states := map[string]interface{ MarshalSSZ() ([]byte, error) }{
+		"phase0": phase0State,
+		"deneb":  denebState,
+	}
+	for name, state := range states {
+		t.Run(name, func(t *testing.T) {
+			data, err := state.MarshalSSZ()
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := decodeStateValidators(bytes.NewReader(data), 3)
+			if err != nil {
+				t.Fatalf("error decoding validators: %v", err)
+			}
+			if len(decoded) != len(validators) {
+				t.Fatalf("decoded %v validators, expected %v", len(decoded), len(validators))
+			}
+			expectedStatus := []string{"active_ongoing", "active_ongoing", "active_ongoing", "exited_slashed", "pending_initialized"}
+			for i, v := range validators {
+				d := decoded[i]
+				if d.Index != uint64(i) || !bytes.Equal(d.PublicKey, v.PublicKey[:]) || !bytes.Equal(d.WithdrawalCredentials, v.WithdrawalCredentials) ||
+					d.EffectiveBalance != uint64(v.EffectiveBalance) || d.Balance != uint64(balances[i]) || d.Slashed != v.Slashed ||
+					d.ActivationEligibilityEpoch != uint64(v.ActivationEligibilityEpoch) || d.ActivationEpoch != uint64(v.ActivationEpoch) ||
+					d.ExitEpoch != uint64(v.ExitEpoch) || d.WithdrawableEpoch != uint64(v.WithdrawableEpoch) {
+					t.Errorf("validator %v decoded as %+v, expected %+v with balance %v", i, d, v, balances[i])
+				}
+				if d.Status != expectedStatus[i] {
+					t.Errorf("validator %v has status %v, expected %v", i, d.Status, expectedStatus[i])
+				}
+			}
+
+			_, err = decodeStateValidators(bytes.NewReader(data[:len(data)/2]), 3)
+			if err == nil {
+				t.Errorf("expected an error decoding a truncated state")
+			}
+		})
+	}
+}
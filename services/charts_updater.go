@@ -612,11 +612,19 @@ func balanceDistributionChartData() (*types.GenericChartData, error) {
 	}
 
 	currentBalances := make([]float64, 0, len(validators.Data))
+	maxBalance := float64(0)
 	for _, entry := range validators.Data {
-		currentBalances = append(currentBalances, float64(entry.Balance)/1e9)
+		balance := float64(entry.Balance) / 1e9
+		currentBalances = append(currentBalances, balance)
+		maxBalance = math.Max(maxBalance, balance)
 	}
 
-	bins := int(math.Sqrt(float64(len(currentBalances)))) + 1
+	// compounding validators stretch the range up to the electra max effective balance, keep the bins at most one increment wide
+	increment := float64(utils.Config.Chain.ClConfig.EffectiveBalanceIncrement) / 1e9
+	if increment == 0 {
+		increment = 1
+	}
+	bins := int(math.Max(math.Sqrt(float64(len(currentBalances))), math.Ceil(maxBalance/increment))) + 1
 	hist := histogram.Hist(bins, currentBalances)
 
 	seriesData := make([][]float64, len(hist.Buckets))
@@ -661,19 +669,22 @@ func effectiveBalanceDistributionChartData() (*types.GenericChartData, error) {
 		return nil, fmt.Errorf("GetValidatorState returned empty validator set for epoch %v", epoch)
 	}
 
-	effectiveBalances := make([]float64, 0, len(validators.Data))
-
+	// effective balances are multiples of the effective balance increment (up to the electra max effective balance for compounding validators),
+	// so every possible value gets its own bucket instead of a histogram over the whole range
+	counts := make(map[uint64]uint64)
 	for _, entry := range validators.Data {
-		effectiveBalances = append(effectiveBalances, float64(entry.Validator.EffectiveBalance)/1e9)
+		counts[uint64(entry.Validator.EffectiveBalance)]++
 	}
 
-	bins := int(math.Sqrt(float64(len(effectiveBalances)))) + 1
-	hist := histogram.Hist(bins, effectiveBalances)
-
-	seriesData := make([][]float64, len(hist.Buckets))
+	effectiveBalances := make([]uint64, 0, len(counts))
+	for effectiveBalance := range counts {
+		effectiveBalances = append(effectiveBalances, effectiveBalance)
+	}
+	sort.Slice(effectiveBalances, func(i, j int) bool { return effectiveBalances[i] < effectiveBalances[j] })
 
-	for i, row := range hist.Buckets {
-		seriesData[i] = []float64{row.Max, float64(row.Count)}
+	seriesData := make([][]float64, 0, len(effectiveBalances))
+	for _, effectiveBalance := range effectiveBalances {
+		seriesData = append(seriesData, []float64{float64(effectiveBalance) / 1e9, float64(counts[effectiveBalance])})
 	}
 
 	chartData := &types.GenericChartData{
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+)
+
+type ChainEventTopic string
+
+const (
+	ChainEventSlot           ChainEventTopic = "slot"            // data is a *types.ApiStreamSlot, published again if the status of the slot changes
+	ChainEventEpoch          ChainEventTopic = "epoch"           // data is a *types.ApiStreamEpoch of the latest exported epoch
+	ChainEventFinalizedEpoch ChainEventTopic = "finalized_epoch" // data is a *types.ApiStreamEpoch of the latest finalized epoch
+	ChainEventGas            ChainEventTopic = "gas"             // data is a *types.ApiStreamGas
+)
+
+// ChainEvent is an event of the chain that is published by the updaters of the services package
+type ChainEvent struct {
+	Topic ChainEventTopic
+	Data  interface{}
+}
+
+// chainEventSlotWindow is the number of recent slots whose status is watched for changes
+const chainEventSlotWindow = 64
+
+var chainEventSubscribersMu = &sync.RWMutex{}
+var chainEventSubscribers = map[chan *ChainEvent]bool{} // guarded by chainEventSubscribersMu
+
+// SubscribeChainEvents returns a channel that receives the chain events of this instance and a function that ends the subscription.
+// Events are dropped for subscribers that do not keep up with their buffer.
+func SubscribeChainEvents(buffer int) (<-chan *ChainEvent, func()) {
+	ch := make(chan *ChainEvent, buffer)
+	chainEventSubscribersMu.Lock()
+	chainEventSubscribers[ch] = true
+	chainEventSubscribersMu.Unlock()
+
+	return ch, func() {
+		chainEventSubscribersMu.Lock()
+		delete(chainEventSubscribers, ch)
+		chainEventSubscribersMu.Unlock()
+	}
+}
+
+func publishChainEvent(topic ChainEventTopic, data interface{}) {
+	event := &ChainEvent{Topic: topic, Data: data}
+	chainEventSubscribersMu.RLock()
+	defer chainEventSubscribersMu.RUnlock()
+	for ch := range chainEventSubscribers {
+		select {
+		case ch <- event:
+		default:
+			logger.Warnf("dropping %v chain event for a slow subscriber", topic)
+		}
+	}
+}
+
+func publishGasEvent(data *types.GasNowPageData) {
+	publishChainEvent(ChainEventGas, &types.ApiStreamGas{
+		Rapid:     data.Data.Rapid,
+		Fast:      data.Data.Fast,
+		Standard:  data.Data.Standard,
+		Slow:      data.Data.Slow,
+		Timestamp: data.Data.Timestamp,
+	})
+}
+
+// chainEventsUpdater publishes the slots and epochs that have been exported since its last run
+func chainEventsUpdater(wg *sync.WaitGroup) {
+	firstRun := true
+	published := map[string]string{} // status of the recent published blocks by slot and block root
+	var lastEpoch, lastFinalizedEpoch uint64
+
+	for {
+		err := publishSlotEvents(published, !firstRun)
+		if err != nil {
+			logger.Errorf("error publishing slot events: %v", err)
+		}
+
+		epoch := LatestEpoch()
+		if epoch > lastEpoch {
+			if !firstRun {
+				publishChainEvent(ChainEventEpoch, &types.ApiStreamEpoch{Epoch: epoch})
+			}
+			lastEpoch = epoch
+		}
+		finalizedEpoch := LatestFinalizedEpoch()
+		if finalizedEpoch > lastFinalizedEpoch {
+			if !firstRun {
+				publishChainEvent(ChainEventFinalizedEpoch, &types.ApiStreamEpoch{Epoch: finalizedEpoch})
+			}
+			lastFinalizedEpoch = finalizedEpoch
+		}
+
+		if firstRun {
+			logger.Info("initialized chain events updater")
+			wg.Done()
+			firstRun = false
+		}
+		ReportStatus("chainEventsUpdater", "Running", nil)
+		time.Sleep(time.Second)
+	}
+}
+
+// publishSlotEvents publishes the recent blocks that are new or whose status changed, published holds the status of the already published blocks
+func publishSlotEvents(published map[string]string, publish bool) error {
+	latestSlot := LatestSlot()
+	firstSlot := uint64(0)
+	if latestSlot > chainEventSlotWindow {
+		firstSlot = latestSlot - chainEventSlotWindow
+	}
+
+	blocks := []*types.ApiStreamSlot{}
+	err := db.WriterDb.Select(&blocks, `
+		SELECT slot, epoch, proposer, blockroot, status, exec_block_number
+		FROM blocks
+		WHERE slot > $1 AND slot <= $2 AND status <> '0'
+		ORDER BY slot, status`, firstSlot, latestSlot)
+	if err != nil {
+		return fmt.Errorf("error retrieving recent blocks: %w", err)
+	}
+
+	current := make(map[string]bool, len(blocks))
+	for _, block := range blocks {
+		key := fmt.Sprintf("%d:%x", block.Slot, []byte(block.BlockRoot))
+		current[key] = true
+		if published[key] == block.Status {
+			continue
+		}
+		published[key] = block.Status
+		if publish {
+			publishChainEvent(ChainEventSlot, block)
+		}
+	}
+	for key := range published {
+		if !current[key] {
+			delete(published, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,663 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/lib/pq"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// The export worker claims the pending jobs of the users_exports table with a lease, like the notification collector
+// claims its shards, so any number of instances can run it. For every dataset of a job it streams the history of the
+// validators day by day into a csv or parquet file and uploads it to the exports bucket. Jobs of crashed workers are
+// retried once their lease has expired, the object keys only depend on the job so a retry overwrites partial uploads.
+// Users download the files through presigned links that are created whenever they request the job.
+
+const (
+	exportLease               = time.Minute * 5
+	exportMaxAttempts         = 3
+	exportMaxDaysDefault      = 31
+	exportRetentionDefault    = time.Hour * 24 * 7
+	exportLinkValidityDefault = time.Hour
+	exportCleanupInterval     = time.Minute * 10
+)
+
+const (
+	ExportFormatCSV     = "csv"
+	ExportFormatParquet = "parquet"
+)
+
+const (
+	ExportDatasetBalances      = "balances"
+	ExportDatasetIncomeDetails = "income_details"
+	ExportDatasetAttestations  = "attestations"
+	ExportDatasetProposals     = "proposals"
+	ExportDatasetWithdrawals   = "withdrawals"
+)
+
+// exportDataset is the parquet schema of the rows of a dataset and the function that retrieves them for a range of epochs
+type exportDataset struct {
+	schema interface{}
+	rows   func(validators []uint64, startEpoch, endEpoch uint64) ([]interface{}, error)
+}
+
+var exportDatasets = map[string]exportDataset{
+	ExportDatasetBalances:      {new(exportBalanceRow), getExportBalanceRows},
+	ExportDatasetIncomeDetails: {new(exportIncomeDetailsRow), getExportIncomeDetailsRows},
+	ExportDatasetAttestations:  {new(exportAttestationRow), getExportAttestationRows},
+	ExportDatasetProposals:     {new(exportProposalRow), getExportProposalRows},
+	ExportDatasetWithdrawals:   {new(exportWithdrawalRow), getExportWithdrawalRows},
+}
+
+// IsExportDataset returns whether dataset can be exported
+func IsExportDataset(dataset string) bool {
+	_, ok := exportDatasets[dataset]
+	return ok
+}
+
+// ExportsEnabled returns whether an exports bucket has been configured
+func ExportsEnabled() bool {
+	return utils.Config.Exports.S3.Bucket != ""
+}
+
+// ExportMaxDays returns the maximum number of days of an export
+func ExportMaxDays() uint64 {
+	if utils.Config.Exports.MaxDays > 0 {
+		return utils.Config.Exports.MaxDays
+	}
+	return exportMaxDaysDefault
+}
+
+func exportRetention() time.Duration {
+	if utils.Config.Exports.Retention > 0 {
+		return utils.Config.Exports.Retention
+	}
+	return exportRetentionDefault
+}
+
+func exportLinkValidity() time.Duration {
+	if utils.Config.Exports.LinkValidity > 0 {
+		return utils.Config.Exports.LinkValidity
+	}
+	return exportLinkValidityDefault
+}
+
+// exportStore holds the files of the exports
+type exportStore interface {
+	put(ctx context.Context, key string, body io.ReadSeeker, size int64) error
+	delete(ctx context.Context, key string) error
+	link(ctx context.Context, key string, validity time.Duration) (string, error)
+}
+
+var exportStoreMu = &sync.Mutex{}
+var exportStoreInstance exportStore // guarded by exportStoreMu, created on first use
+
+func getExportStore() exportStore {
+	exportStoreMu.Lock()
+	defer exportStoreMu.Unlock()
+	if exportStoreInstance == nil {
+		exportStoreInstance = newS3ExportStore()
+	}
+	return exportStoreInstance
+}
+
+// s3ExportStore stores the exports in the configured s3 compatible bucket
+type s3ExportStore struct {
+	client *s3.Client
+	bucket string
+}
+
+func newS3ExportStore() *s3ExportStore {
+	client := utils.NewS3Client(utils.Config.Exports.S3.Endpoint, utils.Config.Exports.S3.AccessKeyId, utils.Config.Exports.S3.AccessKeySecret)
+	return &s3ExportStore{client: client, bucket: utils.Config.Exports.S3.Bucket}
+}
+
+func (s *s3ExportStore) put(ctx context.Context, key string, body io.ReadSeeker, size int64) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        &s.bucket,
+		Key:           &key,
+		Body:          body,
+		ContentLength: size,
+	})
+	return err
+}
+
+func (s *s3ExportStore) delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: &s.bucket,
+		Key:    &key,
+	})
+	return err
+}
+
+func (s *s3ExportStore) link(ctx context.Context, key string, validity time.Duration) (string, error) {
+	req, err := s3.NewPresignClient(s.client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: &s.bucket,
+		Key:    &key,
+	}, s3.WithPresignExpires(validity))
+	if err != nil {
+		return "", err
+	}
+	return req.URL, nil
+}
+
+// ExportDownloadLink returns a presigned download link of a file of a completed export and the time it expires, links never outlive the export
+func ExportDownloadLink(ctx context.Context, export *types.UserExport, file *types.UserExportFile) (string, time.Time, error) {
+	validity := exportLinkValidity()
+	if export.Expires != nil && time.Until(*export.Expires) < validity {
+		validity = time.Until(*export.Expires)
+	}
+	if validity <= 0 {
+		return "", time.Time{}, fmt.Errorf("export %v has expired", export.ID)
+	}
+	link, err := getExportStore().link(ctx, file.Key, validity)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error creating download link of %v: %w", file.Key, err)
+	}
+	return link, time.Now().Add(validity), nil
+}
+
+// exportWorker processes the export jobs of the network and deletes the files of expired exports
+func exportWorker() {
+	logger.Infof("starting export worker")
+	lastCleanup := time.Time{}
+	for {
+		if time.Since(lastCleanup) > exportCleanupInterval {
+			err := cleanupExports()
+			if err != nil {
+				logger.WithError(err).Error("error cleaning up exports")
+			}
+			lastCleanup = time.Now()
+		}
+
+		processed, err := processNextExport()
+		if err != nil {
+			logger.WithError(err).Error("error processing export")
+		}
+		ReportStatus("exportWorker", "Running", nil)
+		if !processed {
+			time.Sleep(time.Second * 10)
+		}
+	}
+}
+
+// processNextExport claims and processes a pending export job, returns false if there was none
+func processNextExport() (bool, error) {
+	export, err := db.ClaimUserExport(utils.GetNetwork(), instanceID, exportLease, exportMaxAttempts)
+	if err != nil {
+		return false, err
+	}
+	if export == nil {
+		return false, nil
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithCancel(context.Background())
+	stopRenewal := renewExportLease(export.ID, cancel)
+	files, err := writeExport(ctx, getExportStore(), export)
+	stopRenewal()
+	cancel()
+	if err != nil {
+		err = fmt.Errorf("error writing export %v: %w", export.ID, err)
+		// users only see that the export failed, the details are logged
+		failErr := db.FailUserExport(export.ID, instanceID, fmt.Errorf("the export could not be written"), export.Attempts >= exportMaxAttempts)
+		if failErr != nil {
+			logger.WithError(failErr).Errorf("error releasing export %v", export.ID)
+		}
+		return true, err
+	}
+
+	completed, err := db.CompleteUserExport(export.ID, instanceID, files, time.Now().Add(exportRetention()))
+	if err != nil {
+		return true, err
+	}
+	if !completed {
+		logger.Warnf("export %v has been taken over by another instance", export.ID)
+		return true, nil
+	}
+	logger.WithField("duration", time.Since(start)).Infof("completed export %v of %v validators", export.ID, len(export.Validators))
+	return true, nil
+}
+
+// renewExportLease extends the lease of the export job until the returned function is called, cancel is called if the lease is lost
+func renewExportLease(id uint64, cancel func()) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(exportLease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				owned, err := db.RenewUserExportLease(id, instanceID, exportLease)
+				if err != nil {
+					logger.WithError(err).Errorf("error renewing lease of export %v", id)
+					continue
+				}
+				if !owned {
+					logger.Warnf("lease of export %v has been taken over by another instance", id)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// writeExport writes and uploads a file for every dataset of the export
+func writeExport(ctx context.Context, store exportStore, export *types.UserExport) (types.UserExportFiles, error) {
+	validators := make([]uint64, 0, len(export.Validators))
+	for _, validator := range export.Validators {
+		validators = append(validators, uint64(validator))
+	}
+	startEpoch, endEpoch := exportEpochRange(export.StartDate, export.EndDate)
+
+	files := make(types.UserExportFiles, 0, len(export.Datasets))
+	for _, dataset := range export.Datasets {
+		key := fmt.Sprintf("exports/%s/%d/%s.%s", export.Network, export.ID, dataset, export.Format)
+		file, err := writeExportFile(ctx, store, key, dataset, export.Format, validators, startEpoch, endEpoch)
+		if err != nil {
+			return nil, fmt.Errorf("error writing %v: %w", dataset, err)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// exportEpochRange returns the first and last epoch of the days from start to end
+func exportEpochRange(start, end time.Time) (uint64, uint64) {
+	startEpoch := uint64(utils.TimeToEpoch(start))
+	endEpoch := uint64(utils.TimeToEpoch(end.AddDate(0, 0, 1).Add(-time.Second)))
+	return startEpoch, endEpoch
+}
+
+// writeExportFile writes the rows of the dataset one day at a time into a temporary file and uploads it under key
+func writeExportFile(ctx context.Context, store exportStore, key, dataset, format string, validators []uint64, startEpoch, endEpoch uint64) (*types.UserExportFile, error) {
+	source, ok := exportDatasets[dataset]
+	if !ok {
+		return nil, fmt.Errorf("unknown dataset %v", dataset)
+	}
+
+	tmp, err := os.CreateTemp("", "export-*."+format)
+	if err != nil {
+		return nil, fmt.Errorf("error creating temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	w, err := newExportWriter(format, tmp, source.schema)
+	if err != nil {
+		return nil, err
+	}
+
+	epochsPerDay := uint64(utils.Day.Seconds()) / (utils.Config.Chain.ClConfig.SecondsPerSlot * utils.Config.Chain.ClConfig.SlotsPerEpoch)
+	rowCount := uint64(0)
+	for from := startEpoch; from <= endEpoch; from += epochsPerDay {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		to := from + epochsPerDay - 1
+		if to > endEpoch {
+			to = endEpoch
+		}
+		rows, err := source.rows(validators, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving epochs %v to %v: %w", from, to, err)
+		}
+		for _, row := range rows {
+			err = w.write(row)
+			if err != nil {
+				return nil, err
+			}
+		}
+		rowCount += uint64(len(rows))
+	}
+	err = w.close()
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("error getting size of %v: %w", key, err)
+	}
+	_, err = tmp.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, fmt.Errorf("error rewinding %v: %w", key, err)
+	}
+	err = store.put(ctx, key, tmp, size)
+	if err != nil {
+		return nil, fmt.Errorf("error uploading %v: %w", key, err)
+	}
+	return &types.UserExportFile{Dataset: dataset, Key: key, Rows: rowCount, Size: size}, nil
+}
+
+// cleanupExports deletes the files of expired exports and fails the exports that have been abandoned by crashed workers
+func cleanupExports() error {
+	_, err := db.FailAbandonedUserExports(utils.GetNetwork(), exportMaxAttempts)
+	if err != nil {
+		return err
+	}
+
+	exports, err := db.GetExpiredUserExports(utils.GetNetwork(), 100)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+	defer cancel()
+	for _, export := range exports {
+		for _, file := range export.Files {
+			err = getExportStore().delete(ctx, file.Key)
+			if err != nil {
+				return fmt.Errorf("error deleting %v: %w", file.Key, err)
+			}
+		}
+		err = db.MarkUserExportExpired(export.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportWriter writes the rows of a dataset in the format of an export
+type exportWriter interface {
+	write(row interface{}) error
+	close() error
+}
+
+func newExportWriter(format string, w io.Writer, schema interface{}) (exportWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		return newCSVExportWriter(w, schema), nil
+	case ExportFormatParquet:
+		pw, err := writer.NewParquetWriterFromWriter(w, schema, 4)
+		if err != nil {
+			return nil, fmt.Errorf("error creating parquet writer: %w", err)
+		}
+		pw.CompressionType = parquet.CompressionCodec_SNAPPY
+		return &parquetExportWriter{pw: pw}, nil
+	default:
+		return nil, fmt.Errorf("unknown export format %v", format)
+	}
+}
+
+type parquetExportWriter struct {
+	pw *writer.ParquetWriter
+}
+
+func (w *parquetExportWriter) write(row interface{}) error {
+	err := w.pw.Write(row)
+	if err != nil {
+		return fmt.Errorf("error writing parquet row: %w", err)
+	}
+	return nil
+}
+
+func (w *parquetExportWriter) close() error {
+	err := w.pw.WriteStop()
+	if err != nil {
+		return fmt.Errorf("error finishing parquet file: %w", err)
+	}
+	return nil
+}
+
+// csvExportWriter writes rows as csv, the columns are named like the columns of the parquet schema of the row
+type csvExportWriter struct {
+	w      *csv.Writer
+	header []string
+	record []string
+}
+
+func newCSVExportWriter(w io.Writer, schema interface{}) *csvExportWriter {
+	t := reflect.TypeOf(schema).Elem()
+	header := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		for _, part := range strings.Split(t.Field(i).Tag.Get("parquet"), ",") {
+			name, found := strings.CutPrefix(strings.TrimSpace(part), "name=")
+			if found {
+				header = append(header, name)
+			}
+		}
+	}
+	return &csvExportWriter{w: csv.NewWriter(w), header: header, record: make([]string, len(header))}
+}
+
+func (w *csvExportWriter) write(row interface{}) error {
+	if w.header != nil {
+		err := w.w.Write(w.header)
+		if err != nil {
+			return fmt.Errorf("error writing csv header: %w", err)
+		}
+		w.header = nil
+	}
+
+	v := reflect.ValueOf(row).Elem()
+	for i := range w.record {
+		switch field := v.Field(i); field.Kind() {
+		case reflect.Int64:
+			w.record[i] = strconv.FormatInt(field.Int(), 10)
+		case reflect.String:
+			w.record[i] = field.String()
+		default:
+			return fmt.Errorf("unsupported csv column type %v", field.Kind())
+		}
+	}
+	err := w.w.Write(w.record)
+	if err != nil {
+		return fmt.Errorf("error writing csv row: %w", err)
+	}
+	return nil
+}
+
+func (w *csvExportWriter) close() error {
+	if w.header != nil {
+		// write the header of empty exports as well
+		err := w.w.Write(w.header)
+		if err != nil {
+			return fmt.Errorf("error writing csv header: %w", err)
+		}
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// the amounts of all datasets are in gwei, apart from tx_fee_reward_wei
+type exportBalanceRow struct {
+	Validator        int64 `parquet:"name=validator, type=INT64, convertedtype=UINT_64"`
+	Epoch            int64 `parquet:"name=epoch, type=INT64, convertedtype=UINT_64"`
+	Balance          int64 `parquet:"name=balance, type=INT64, convertedtype=UINT_64"`
+	EffectiveBalance int64 `parquet:"name=effective_balance, type=INT64, convertedtype=UINT_64"`
+}
+
+type exportIncomeDetailsRow struct {
+	Validator                          int64  `parquet:"name=validator, type=INT64, convertedtype=UINT_64"`
+	Epoch                              int64  `parquet:"name=epoch, type=INT64, convertedtype=UINT_64"`
+	AttestationSourceReward            int64  `parquet:"name=attestation_source_reward, type=INT64, convertedtype=UINT_64"`
+	AttestationSourcePenalty           int64  `parquet:"name=attestation_source_penalty, type=INT64, convertedtype=UINT_64"`
+	AttestationTargetReward            int64  `parquet:"name=attestation_target_reward, type=INT64, convertedtype=UINT_64"`
+	AttestationTargetPenalty           int64  `parquet:"name=attestation_target_penalty, type=INT64, convertedtype=UINT_64"`
+	AttestationHeadReward              int64  `parquet:"name=attestation_head_reward, type=INT64, convertedtype=UINT_64"`
+	FinalityDelayPenalty               int64  `parquet:"name=finality_delay_penalty, type=INT64, convertedtype=UINT_64"`
+	ProposerSlashingInclusionReward    int64  `parquet:"name=proposer_slashing_inclusion_reward, type=INT64, convertedtype=UINT_64"`
+	ProposerAttestationInclusionReward int64  `parquet:"name=proposer_attestation_inclusion_reward, type=INT64, convertedtype=UINT_64"`
+	ProposerSyncInclusionReward        int64  `parquet:"name=proposer_sync_inclusion_reward, type=INT64, convertedtype=UINT_64"`
+	SyncCommitteeReward                int64  `parquet:"name=sync_committee_reward, type=INT64, convertedtype=UINT_64"`
+	SyncCommitteePenalty               int64  `parquet:"name=sync_committee_penalty, type=INT64, convertedtype=UINT_64"`
+	SlashingReward                     int64  `parquet:"name=slashing_reward, type=INT64, convertedtype=UINT_64"`
+	SlashingPenalty                    int64  `parquet:"name=slashing_penalty, type=INT64, convertedtype=UINT_64"`
+	ProposalsMissed                    int64  `parquet:"name=proposals_missed, type=INT64, convertedtype=UINT_64"`
+	TxFeeRewardWei                     string `parquet:"name=tx_fee_reward_wei, type=BYTE_ARRAY, convertedtype=UTF8"` // decimal, the fees can exceed an int64
+}
+
+// exportAttestationRow is an attestation duty, missed attestations have a status and inclusion slot of 0
+type exportAttestationRow struct {
+	Validator     int64 `parquet:"name=validator, type=INT64, convertedtype=UINT_64"`
+	Epoch         int64 `parquet:"name=epoch, type=INT64, convertedtype=UINT_64"`
+	AttesterSlot  int64 `parquet:"name=attester_slot, type=INT64, convertedtype=UINT_64"`
+	Status        int64 `parquet:"name=status, type=INT64, convertedtype=UINT_64"`
+	InclusionSlot int64 `parquet:"name=inclusion_slot, type=INT64, convertedtype=UINT_64"`
+	Delay         int64 `parquet:"name=delay, type=INT64"`
+}
+
+// exportProposalRow is a proposal duty with the status of the blocks table, 1 = proposed, 2 = missed, 3 = orphaned
+type exportProposalRow struct {
+	Validator       int64  `parquet:"name=validator, type=INT64, convertedtype=UINT_64" db:"proposer"`
+	Epoch           int64  `parquet:"name=epoch, type=INT64, convertedtype=UINT_64" db:"epoch"`
+	Slot            int64  `parquet:"name=slot, type=INT64, convertedtype=UINT_64" db:"slot"`
+	Status          int64  `parquet:"name=status, type=INT64, convertedtype=UINT_64" db:"status"`
+	BlockRoot       string `parquet:"name=block_root, type=BYTE_ARRAY, convertedtype=UTF8" db:"-"`
+	ExecBlockNumber int64  `parquet:"name=exec_block_number, type=INT64, convertedtype=UINT_64" db:"exec_block_number"` // 0 before the merge and for missed blocks
+}
+
+type exportWithdrawalRow struct {
+	Validator       int64  `parquet:"name=validator, type=INT64, convertedtype=UINT_64" db:"validatorindex"`
+	Epoch           int64  `parquet:"name=epoch, type=INT64, convertedtype=UINT_64" db:"epoch"`
+	Slot            int64  `parquet:"name=slot, type=INT64, convertedtype=UINT_64" db:"block_slot"`
+	WithdrawalIndex int64  `parquet:"name=withdrawal_index, type=INT64, convertedtype=UINT_64" db:"withdrawalindex"`
+	Address         string `parquet:"name=address, type=BYTE_ARRAY, convertedtype=UTF8" db:"-"`
+	Amount          int64  `parquet:"name=amount, type=INT64, convertedtype=UINT_64" db:"amount"`
+}
+
+func getExportBalanceRows(validators []uint64, startEpoch, endEpoch uint64) ([]interface{}, error) {
+	balances, err := db.BigtableClient.GetValidatorBalanceHistory(validators, startEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+	rows := []interface{}{}
+	for _, validator := range validators {
+		history := balances[validator]
+		sort.Slice(history, func(i, j int) bool { return history[i].Epoch < history[j].Epoch })
+		for _, balance := range history {
+			rows = append(rows, &exportBalanceRow{
+				Validator:        int64(validator),
+				Epoch:            int64(balance.Epoch),
+				Balance:          int64(balance.Balance),
+				EffectiveBalance: int64(balance.EffectiveBalance),
+			})
+		}
+	}
+	return rows, nil
+}
+
+func getExportIncomeDetailsRows(validators []uint64, startEpoch, endEpoch uint64) ([]interface{}, error) {
+	incomes, err := db.BigtableClient.GetValidatorIncomeDetailsHistory(validators, startEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+	rows := []interface{}{}
+	for _, validator := range validators {
+		for epoch := startEpoch; epoch <= endEpoch; epoch++ {
+			income, ok := incomes[validator][epoch]
+			if !ok {
+				continue
+			}
+			rows = append(rows, &exportIncomeDetailsRow{
+				Validator:                          int64(validator),
+				Epoch:                              int64(epoch),
+				AttestationSourceReward:            int64(income.AttestationSourceReward),
+				AttestationSourcePenalty:           int64(income.AttestationSourcePenalty),
+				AttestationTargetReward:            int64(income.AttestationTargetReward),
+				AttestationTargetPenalty:           int64(income.AttestationTargetPenalty),
+				AttestationHeadReward:              int64(income.AttestationHeadReward),
+				FinalityDelayPenalty:               int64(income.FinalityDelayPenalty),
+				ProposerSlashingInclusionReward:    int64(income.ProposerSlashingInclusionReward),
+				ProposerAttestationInclusionReward: int64(income.ProposerAttestationInclusionReward),
+				ProposerSyncInclusionReward:        int64(income.ProposerSyncInclusionReward),
+				SyncCommitteeReward:                int64(income.SyncCommitteeReward),
+				SyncCommitteePenalty:               int64(income.SyncCommitteePenalty),
+				SlashingReward:                     int64(income.SlashingReward),
+				SlashingPenalty:                    int64(income.SlashingPenalty),
+				ProposalsMissed:                    int64(income.ProposalsMissed),
+				TxFeeRewardWei:                     new(big.Int).SetBytes(income.TxFeeRewardWei).String(),
+			})
+		}
+	}
+	return rows, nil
+}
+
+func getExportAttestationRows(validators []uint64, startEpoch, endEpoch uint64) ([]interface{}, error) {
+	attestations, err := db.BigtableClient.GetValidatorAttestationHistory(validators, startEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+	rows := []interface{}{}
+	for _, validator := range validators {
+		history := attestations[validator]
+		sort.Slice(history, func(i, j int) bool { return history[i].Epoch < history[j].Epoch })
+		for _, attestation := range history {
+			rows = append(rows, &exportAttestationRow{
+				Validator:     int64(validator),
+				Epoch:         int64(attestation.Epoch),
+				AttesterSlot:  int64(attestation.AttesterSlot),
+				Status:        int64(attestation.Status),
+				InclusionSlot: int64(attestation.InclusionSlot),
+				Delay:         attestation.Delay,
+			})
+		}
+	}
+	return rows, nil
+}
+
+func getExportProposalRows(validators []uint64, startEpoch, endEpoch uint64) ([]interface{}, error) {
+	proposals := []*struct {
+		exportProposalRow
+		BlockRoot []byte `db:"blockroot"`
+	}{}
+	err := db.ReaderDb.Select(&proposals, `
+		SELECT proposer, epoch, slot, status, blockroot, COALESCE(exec_block_number, 0) AS exec_block_number
+		FROM blocks
+		WHERE proposer = ANY($1) AND epoch >= $2 AND epoch <= $3 AND status <> '0'
+		ORDER BY proposer, slot`, pq.Array(validators), startEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]interface{}, 0, len(proposals))
+	for _, proposal := range proposals {
+		proposal.exportProposalRow.BlockRoot = "0x" + hex.EncodeToString(proposal.BlockRoot)
+		rows = append(rows, &proposal.exportProposalRow)
+	}
+	return rows, nil
+}
+
+func getExportWithdrawalRows(validators []uint64, startEpoch, endEpoch uint64) ([]interface{}, error) {
+	withdrawals := []*struct {
+		exportWithdrawalRow
+		Address []byte `db:"address"`
+	}{}
+	err := db.ReaderDb.Select(&withdrawals, `
+		SELECT w.validatorindex, b.epoch, w.block_slot, w.withdrawalindex, w.address, w.amount
+		FROM blocks_withdrawals w
+		INNER JOIN blocks b ON b.blockroot = w.block_root AND b.status = '1'
+		WHERE w.validatorindex = ANY($1) AND w.block_slot >= $2 AND w.block_slot <= $3
+		ORDER BY w.validatorindex, w.withdrawalindex`, pq.Array(validators), startEpoch*utils.Config.Chain.ClConfig.SlotsPerEpoch, (endEpoch+1)*utils.Config.Chain.ClConfig.SlotsPerEpoch-1)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]interface{}, 0, len(withdrawals))
+	for _, withdrawal := range withdrawals {
+		withdrawal.exportWithdrawalRow.Address = "0x" + hex.EncodeToString(withdrawal.Address)
+		rows = append(rows, &withdrawal.exportWithdrawalRow)
+	}
+	return rows, nil
+}
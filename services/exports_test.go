@@ -0,0 +1,133 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+// memoryExportStore keeps the uploaded files in memory
+type memoryExportStore map[string][]byte
+
+func (s memoryExportStore) put(ctx context.Context, key string, body io.ReadSeeker, size int64) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) != size {
+		return fmt.Errorf("size is %v, uploaded %v bytes", size, len(data))
+	}
+	s[key] = data
+	return nil
+}
+
+func (s memoryExportStore) delete(ctx context.Context, key string) error {
+	delete(s, key)
+	return nil
+}
+
+func (s memoryExportStore) link(ctx context.Context, key string, validity time.Duration) (string, error) {
+	return "memory://" + key, nil
+}
+
+func TestWriteExport(t *testing.T) {
+	prevConfig := utils.Config
+	defer func() { utils.Config = prevConfig }()
+	utils.Config = &types.Config{}
+	utils.Config.Chain.GenesisTimestamp = 1606824023
+	utils.Config.Chain.ClConfig.SecondsPerSlot = 12
+	utils.Config.Chain.ClConfig.SlotsPerEpoch = 32
+
+	// each day is fetched separately, the rows of the test dataset are the ranges that have been requested
+	type rangeRow struct {
+		Validator int64  `parquet:"name=validator, type=INT64, convertedtype=UINT_64"`
+		From      int64  `parquet:"name=from, type=INT64, convertedtype=UINT_64"`
+		To        int64  `parquet:"name=to, type=INT64, convertedtype=UINT_64"`
+		Note      string `parquet:"name=note, type=BYTE_ARRAY, convertedtype=UTF8"`
+	}
+	exportDatasets["ranges"] = exportDataset{new(rangeRow), func(validators []uint64, startEpoch, endEpoch uint64) ([]interface{}, error) {
+		rows := []interface{}{}
+		for _, validator := range validators {
+			rows = append(rows, &rangeRow{Validator: int64(validator), From: int64(startEpoch), To: int64(endEpoch), Note: "a, \"b\""})
+		}
+		return rows, nil
+	}}
+	defer delete(exportDatasets, "ranges")
+
+	export := &types.UserExport{
+		ID:         7,
+		Network:    "mainnet",
+		Validators: []int64{1, 2},
+		StartDate:  time.Date(2020, 12, 2, 0, 0, 0, 0, time.UTC),
+		EndDate:    time.Date(2020, 12, 3, 0, 0, 0, 0, time.UTC),
+		Datasets:   []string{"ranges"},
+		Format:     ExportFormatCSV,
+	}
+	startEpoch, endEpoch := exportEpochRange(export.StartDate, export.EndDate)
+	if startEpoch != 112 || endEpoch != 562 {
+		t.Fatalf("epoch range is %v to %v, want 112 to 562", startEpoch, endEpoch)
+	}
+
+	store := memoryExportStore{}
+	files, err := writeExport(context.Background(), store, export)
+	if err != nil {
+		t.Fatalf("error writing csv export: %v", err)
+	}
+	want := `validator,from,to,note
+1,112,336,"a, ""b"""
+2,112,336,"a, ""b"""
+1,337,561,"a, ""b"""
+2,337,561,"a, ""b"""
+1,562,562,"a, ""b"""
+2,562,562,"a, ""b"""
+`
+	if got := string(store["exports/mainnet/7/ranges.csv"]); got != want {
+		t.Errorf("csv export is\n%v\nwant\n%v", got, want)
+	}
+	if len(files) != 1 || files[0].Rows != 6 || files[0].Size != int64(len(want)) {
+		t.Errorf("unexpected files %+v", files[0])
+	}
+
+	export.Format = ExportFormatParquet
+	_, err = writeExport(context.Background(), store, export)
+	if err != nil {
+		t.Fatalf("error writing parquet export: %v", err)
+	}
+	data := store["exports/mainnet/7/ranges.parquet"]
+	file, err := buffer.NewBufferFile(data)
+	if err != nil {
+		t.Fatalf("error opening parquet export: %v", err)
+	}
+	pr, err := reader.NewParquetReader(file, new(rangeRow), 1)
+	if err != nil {
+		t.Fatalf("error reading parquet export: %v", err)
+	}
+	defer pr.ReadStop()
+	rows := make([]rangeRow, pr.GetNumRows())
+	err = pr.Read(&rows)
+	if err != nil {
+		t.Fatalf("error reading parquet rows: %v", err)
+	}
+	if len(rows) != 6 || rows[5] != (rangeRow{Validator: 2, From: 562, To: 562, Note: "a, \"b\""}) {
+		t.Errorf("unexpected parquet rows %+v", rows)
+	}
+
+	var csvOut bytes.Buffer
+	w, err := newExportWriter(ExportFormatCSV, &csvOut, new(exportProposalRow))
+	if err != nil {
+		t.Fatalf("error creating csv writer: %v", err)
+	}
+	err = w.close()
+	if err != nil || csvOut.String() != "validator,epoch,slot,status,block_root,exec_block_number\n" {
+		t.Errorf("empty csv export is %q, %v", csvOut.String(), err)
+	}
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db"
+	"github.com/gobitfly/eth2-beaconchain-explorer/metrics"
+	"github.com/gobitfly/eth2-beaconchain-explorer/notify"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	"firebase.google.com/go/v4/messaging"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// digestMaxDetails is the number of notifications per title that are listed in a digest, the rest is only counted
+const digestMaxDetails = 10
+
+// heldNotification is a notification in the notification_digest_queue
+type heldNotification struct {
+	ID           uint64                    `db:"id"`
+	UserID       uint64                    `db:"user_id"`
+	Channel      types.NotificationChannel `db:"channel"`
+	EventName    types.EventName           `db:"event_name"`
+	Title        string                    `db:"title"`
+	Info         string                    `db:"info"`
+	InfoMarkdown string                    `db:"info_markdown"`
+}
+
+// holdNotifications moves the notifications of users whose channel is in digest mode or in its quiet hours into the
+// notification_digest_queue and returns the notifications that are to be delivered on the channel right away.
+// Critical events are never held, neither are emails with attachments as a digest can not carry them.
+func holdNotifications(channel types.NotificationChannel, notificationsByUserID map[uint64]map[types.EventName][]types.Notification, prefsByUserID map[uint64]map[types.NotificationChannel]*types.UserNotificationPreferences, useDB *sqlx.DB, queueKey string) map[uint64]map[types.EventName][]types.Notification {
+	now := time.Now()
+	immediate := make(map[uint64]map[types.EventName][]types.Notification, len(notificationsByUserID))
+
+	for userID, userNotifications := range notificationsByUserID {
+		prefs := prefsByUserID[userID][channel]
+		if prefs == nil {
+			immediate[userID] = userNotifications
+			continue
+		}
+		releaseAt := utils.NotificationReleaseTime(prefs, now)
+		if !releaseAt.After(now) {
+			immediate[userID] = userNotifications
+			continue
+		}
+
+		immediateUserNotifications := map[types.EventName][]types.Notification{}
+		var eventNames, titles, infos, infoMarkdowns []string
+		var dedupeKeys []sql.NullString
+		for event, ns := range userNotifications {
+			for _, n := range ns {
+				if types.CriticalEventNames[event] || (channel == types.EmailNotificationChannel && n.GetEmailAttachment() != nil) {
+					immediateUserNotifications[event] = append(immediateUserNotifications[event], n)
+					continue
+				}
+				eventNames = append(eventNames, string(event))
+				titles = append(titles, n.GetTitle())
+				infos = append(infos, n.GetInfo(true))
+				infoMarkdowns = append(infoMarkdowns, n.GetInfoMarkdown())
+				dedupeKeys = append(dedupeKeys, notificationDedupeKey(queueKey, channel, userID, event, n.GetSubscriptionID()))
+			}
+		}
+
+		if len(eventNames) > 0 {
+			_, err := useDB.Exec(`
+				INSERT INTO notification_digest_queue (user_id, channel, event_name, title, info, info_markdown, dedupe_key, release_at)
+				SELECT $1, $2, e, t, i, im, k, now() + $8 * INTERVAL '1 second'
+				FROM unnest($3::text[], $4::text[], $5::text[], $6::text[], $7::text[]) AS n(e, t, i, im, k)
+				ON CONFLICT (dedupe_key) DO NOTHING`,
+				userID, channel, pq.StringArray(eventNames), pq.StringArray(titles), pq.StringArray(infos), pq.StringArray(infoMarkdowns), pq.Array(dedupeKeys), releaseAt.Sub(now).Seconds())
+			if err != nil {
+				// rather deliver the notifications now than lose them
+				logger.WithError(err).Errorf("error holding %v notifications of user %v, delivering them immediately", channel, userID)
+				immediate[userID] = userNotifications
+				continue
+			}
+			metrics.NotificationsQueued.WithLabelValues(string(channel), "held").Add(float64(len(eventNames)))
+		}
+		if len(immediateUserNotifications) > 0 {
+			immediate[userID] = immediateUserNotifications
+		}
+	}
+	return immediate
+}
+
+// queueNotificationDigests summarizes the held notifications that are due into one digest per user and channel and queues it for sending
+func queueNotificationDigests(useDB *sqlx.DB) error {
+	var held []heldNotification
+	err := useDB.Select(&held, `
+		SELECT id, user_id, channel, event_name, title, info, info_markdown
+		FROM notification_digest_queue
+		WHERE release_at <= now()
+		ORDER BY user_id, channel, id`)
+	if err != nil {
+		return fmt.Errorf("error querying notification digest queue: %w", err)
+	}
+	if len(held) == 0 {
+		return nil
+	}
+
+	type digestKey struct {
+		userID  uint64
+		channel types.NotificationChannel
+	}
+	digests := map[digestKey][]heldNotification{}
+	userIDs := []uint64{}
+	for _, n := range held {
+		key := digestKey{n.UserID, n.Channel}
+		if len(userIDs) == 0 || userIDs[len(userIDs)-1] != n.UserID {
+			userIDs = append(userIDs, n.UserID)
+		}
+		digests[key] = append(digests[key], n)
+	}
+	logger.Infof("queuing %v digests of %v held notifications", len(digests), len(held))
+
+	// the channels are resolved on release, channels that have been deactivated in the meantime are skipped
+	emailsByUserID, err := db.GetUserEmailsByIds(userIDs)
+	if err != nil {
+		return fmt.Errorf("error getting emails of users: %w", err)
+	}
+	tokensByUserID, err := db.GetUserPushTokenByIds(userIDs)
+	if err != nil {
+		return fmt.Errorf("error getting push tokens of users: %w", err)
+	}
+	chatsByUserID, err := db.GetUserChatChannelsByIds(userIDs)
+	if err != nil {
+		return fmt.Errorf("error getting chat channels of users: %w", err)
+	}
+
+	for key, ns := range digests {
+		var content interface{}
+		switch key.channel {
+		case types.EmailNotificationChannel:
+			if email, exists := emailsByUserID[key.userID]; exists {
+				content = emailDigest(email, ns)
+			}
+		case types.PushNotificationChannel:
+			if tokens, exists := tokensByUserID[key.userID]; exists {
+				content = pushDigest(tokens, ns)
+			}
+		default:
+			for _, chat := range chatsByUserID[key.userID] {
+				if chat.Channel == key.channel {
+					content = chatDigest(chat, ns)
+				}
+			}
+		}
+
+		ids := make([]int64, 0, len(ns))
+		for _, n := range ns {
+			ids = append(ids, int64(n.ID))
+		}
+		err = queueNotificationDigest(key.channel, content, ids, useDB)
+		if err != nil {
+			return fmt.Errorf("error queuing %v digest of user %v: %w", key.channel, key.userID, err)
+		}
+		if content != nil {
+			metrics.NotificationsQueued.WithLabelValues(string(key.channel), "digest").Inc()
+		}
+	}
+	return nil
+}
+
+// queueNotificationDigest queues the digest and removes the held notifications it summarizes, a nil content only removes them
+func queueNotificationDigest(channel types.NotificationChannel, content interface{}, ids []int64, useDB *sqlx.DB) error {
+	tx, err := useDB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if content != nil {
+		_, err = tx.Exec(`INSERT INTO notification_queue (created, channel, content) VALUES (now(), $1, $2)`, channel, content)
+		if err != nil {
+			return fmt.Errorf("error writing digest to notification queue: %w", err)
+		}
+	}
+	_, err = tx.Exec(`DELETE FROM notification_digest_queue WHERE id = ANY($1)`, pq.Int64Array(ids))
+	if err != nil {
+		return fmt.Errorf("error deleting from notification digest queue: %w", err)
+	}
+	return tx.Commit()
+}
+
+// digestGroup are the held notifications of a digest that share a title
+type digestGroup struct {
+	Title         string
+	Notifications []heldNotification
+}
+
+// groupDigest groups the notifications of a digest by their title in the order the titles first occur
+func groupDigest(ns []heldNotification) []digestGroup {
+	groups := []digestGroup{}
+	index := map[string]int{}
+	for _, n := range ns {
+		i, exists := index[n.Title]
+		if !exists {
+			i = len(groups)
+			index[n.Title] = i
+			groups = append(groups, digestGroup{Title: n.Title})
+		}
+		groups[i].Notifications = append(groups[i].Notifications, n)
+	}
+	return groups
+}
+
+func digestSubject(count int) string {
+	if count == 1 {
+		return "Notification digest (1 notification)"
+	}
+	return fmt.Sprintf("Notification digest (%d notifications)", count)
+}
+
+func emailDigest(email string, ns []heldNotification) types.TransitEmailContent {
+	var msg types.Email
+	if utils.Config.Chain.Name != "mainnet" {
+		msg.Body += template.HTML(fmt.Sprintf("<b>Notice: This email contains notifications for the %s network!</b><br>", utils.Config.Chain.Name))
+	}
+
+	for i, g := range groupDigest(ns) {
+		if i > 0 {
+			msg.Body += "<br>"
+		}
+		msg.Body += template.HTML(fmt.Sprintf("%s (%d)<br>====<br><br>", template.HTMLEscapeString(g.Title), len(g.Notifications)))
+		for j, n := range g.Notifications {
+			if j == digestMaxDetails {
+				msg.Body += template.HTML(fmt.Sprintf("... and %d more<br>", len(g.Notifications)-digestMaxDetails))
+				break
+			}
+			if n.Info != "" {
+				msg.Body += template.HTML(fmt.Sprintf("%s<br>", n.Info))
+			}
+		}
+	}
+	msg.SubscriptionManageURL = template.HTML(fmt.Sprintf(`<a href="%v" style="color: white" onMouseOver="this.style.color='#F5B498'" onMouseOut="this.style.color='#FFFFFF'">Manage</a>`, "https://"+utils.Config.Frontend.SiteDomain+"/user/notifications"))
+
+	return types.TransitEmailContent{
+		Address: email,
+		Subject: fmt.Sprintf("%s: %s", utils.Config.Frontend.SiteDomain, digestSubject(len(ns))),
+		Email:   msg,
+	}
+}
+
+func pushDigest(tokens []string, ns []heldNotification) types.TransitPushContent {
+	counts := []string{}
+	for _, g := range groupDigest(ns) {
+		counts = append(counts, fmt.Sprintf("%dx %s", len(g.Notifications), g.Title))
+	}
+
+	messages := make([]*messaging.Message, 0, len(tokens))
+	for _, token := range tokens {
+		messages = append(messages, &messaging.Message{
+			Notification: &messaging.Notification{
+				Title: getNetwork() + digestSubject(len(ns)),
+				Body:  strings.Join(counts, ", "),
+			},
+			Token: token,
+			APNS: &messaging.APNSConfig{
+				Payload: &messaging.APNSPayload{
+					Aps: &messaging.Aps{Sound: "default"},
+				},
+			},
+		})
+	}
+	return types.TransitPushContent{Messages: messages}
+}
+
+func chatDigest(chat types.UserChatChannel, ns []heldNotification) types.TransitChatContent {
+	groups := groupDigest(ns)
+	counts := []string{}
+	for _, g := range groups {
+		counts = append(counts, fmt.Sprintf("%dx %s", len(g.Notifications), g.Title))
+	}
+
+	messages := []types.ChatMessage{notify.FormatChatMessage(chat.Channel, getNetwork()+digestSubject(len(ns)), strings.Join(counts, "\n"))}
+	for _, g := range groups {
+		for j, n := range g.Notifications {
+			if j == digestMaxDetails {
+				messages = append(messages, notify.FormatChatMessage(chat.Channel, g.Title, fmt.Sprintf("... and %d more", len(g.Notifications)-digestMaxDetails)))
+				break
+			}
+			if n.InfoMarkdown != "" {
+				messages = append(messages, notify.FormatChatMessage(chat.Channel, g.Title, n.InfoMarkdown))
+			}
+		}
+	}
+	return types.TransitChatContent{
+		Chat:     chat,
+		Messages: notify.BatchChatMessages(chat.Channel, messages),
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// saveNotificationHistory adds the notifications to the notification history of the users, independent of the channels they are delivered to
+func saveNotificationHistory(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, useDB *sqlx.DB, queueKey string) error {
+	var userIDs, subscriptionIDs, epochs []int64
+	var eventNames, eventFilters, titles, contents []string
+	var dedupeKeys []sql.NullString
+	for userID, userNotifications := range notificationsByUserID {
+		for event, ns := range userNotifications {
+			for _, n := range ns {
+				userIDs = append(userIDs, int64(userID))
+				subscriptionIDs = append(subscriptionIDs, int64(n.GetSubscriptionID()))
+				epochs = append(epochs, int64(n.GetEpoch()))
+				eventNames = append(eventNames, string(event))
+				eventFilters = append(eventFilters, n.GetEventFilter())
+				titles = append(titles, n.GetTitle())
+				contents = append(contents, n.GetInfo(false))
+				dedupeKeys = append(dedupeKeys, notificationDedupeKey(queueKey, userID, event, n.GetSubscriptionID()))
+			}
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	_, err := useDB.Exec(`
+		INSERT INTO users_notification_history (user_id, subscription_id, epoch, event_name, event_filter, title, content, dedupe_key)
+		SELECT u, s, e, n, f, t, c, k
+		FROM unnest($1::int[], $2::int[], $3::int[], $4::text[], $5::text[], $6::text[], $7::text[], $8::text[]) AS h(u, s, e, n, f, t, c, k)
+		ON CONFLICT (dedupe_key) DO NOTHING`,
+		pq.Int64Array(userIDs), pq.Int64Array(subscriptionIDs), pq.Int64Array(epochs),
+		pq.StringArray(eventNames), pq.StringArray(eventFilters), pq.StringArray(titles), pq.StringArray(contents), pq.Array(dedupeKeys))
+	if err != nil {
+		return fmt.Errorf("error inserting %v notifications into the notification history: %w", len(userIDs), err)
+	}
+	return nil
+}
+
+// garbageCollectNotificationHistory deletes the notifications that are older than the configured retention of the notification history
+func garbageCollectNotificationHistory() error {
+	if utils.Config.Notifications.HistoryRetention == 0 {
+		return nil
+	}
+	rowsAffected, err := db.DeleteNotificationHistoryBefore(time.Now().Add(-utils.Config.Notifications.HistoryRetention))
+	if err != nil {
+		return err
+	}
+	logger.Infof("deleted %v rows from the notification history", rowsAffected)
+	return nil
+}
@@ -0,0 +1,344 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db"
+	"github.com/gobitfly/eth2-beaconchain-explorer/metrics"
+	"github.com/gobitfly/eth2-beaconchain-explorer/notify"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// ruleRepeatEpochs is the number of epochs after which a rule that is still fulfilled notifies again
+	ruleRepeatEpochs = 225
+	// ruleFiringState is the internal state of the subscription of a rule that is fulfilled
+	ruleFiringState = "firing"
+)
+
+// notificationRuleSubscription is a rule together with the state of its subscription
+type notificationRuleSubscription struct {
+	types.NotificationRule
+	LastEpoch       *uint64        `db:"last_sent_epoch"`
+	State           sql.NullString `db:"internal_state"`
+	UnsubscribeHash sql.NullString `db:"unsubscribe_hash"`
+}
+
+type ruleValidator struct {
+	Index           uint64 `db:"validatorindex"`
+	Pubkey          []byte `db:"pubkey"`
+	ActivationEpoch uint64 `db:"activationepoch"`
+	ExitEpoch       uint64 `db:"exitepoch"`
+}
+
+// ruleEvaluationData is the data the conditions of the rules are evaluated on
+type ruleEvaluationData struct {
+	epoch uint64
+	// groups are the validators of the groups of the users, by user id and group
+	groups map[uint64]map[string][]ruleValidator
+	// missedEpochs are the epochs of the evaluation window a validator missed its attestation in, by validator index
+	missedEpochs map[uint64]map[uint64]bool
+	// machines are the latest metrics of the machines of the users, by user id and machine name
+	machines map[uint64]map[string]*types.MachineMetricSystemUser
+}
+
+// collectRuleNotifications evaluates the notification rules of all users at the given epoch.
+// A rule notifies once it becomes fulfilled and again every ruleRepeatEpochs epochs as long as it stays fulfilled.
+func collectRuleNotifications(epoch uint64) (map[uint64]map[types.EventName][]types.Notification, error) {
+	notificationsByUserID := map[uint64]map[types.EventName][]types.Notification{}
+
+	var rules []*notificationRuleSubscription
+	err := db.FrontendWriterDB.Select(&rules, `
+		SELECT
+			r.id, r.user_id, r.name, r.match, r.conditions, r.created,
+			us.id AS subscription_id, us.last_sent_ts, us.last_sent_epoch, us.internal_state, ENCODE(us.unsubscribe_hash, 'hex') AS unsubscribe_hash
+		FROM users_notification_rules r
+		INNER JOIN users_subscriptions us ON us.user_id = r.user_id AND us.event_name = $1 AND us.event_filter = r.id::text
+		WHERE us.created_epoch <= $2`,
+		utils.GetNetwork()+":"+string(types.NotificationRuleEventName), epoch)
+	if err != nil {
+		return nil, fmt.Errorf("error getting notification rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return notificationsByUserID, nil
+	}
+
+	data, err := getRuleEvaluationData(rules, epoch)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := []int64{}
+	for _, rule := range rules {
+		results, fulfilled := data.evaluate(rule)
+		if !fulfilled {
+			if rule.State.String == ruleFiringState {
+				resolved = append(resolved, int64(*rule.SubscriptionID))
+			}
+			continue
+		}
+		if rule.State.String == ruleFiringState && rule.LastEpoch != nil && *rule.LastEpoch+ruleRepeatEpochs > epoch {
+			continue
+		}
+
+		n := &notificationRuleNotification{
+			SubscriptionID:  *rule.SubscriptionID,
+			RuleID:          rule.ID,
+			RuleName:        rule.Name,
+			Epoch:           epoch,
+			Results:         results,
+			UnsubscribeHash: rule.UnsubscribeHash,
+		}
+		if _, exists := notificationsByUserID[rule.UserID]; !exists {
+			notificationsByUserID[rule.UserID] = map[types.EventName][]types.Notification{}
+		}
+		notificationsByUserID[rule.UserID][n.GetEventName()] = append(notificationsByUserID[rule.UserID][n.GetEventName()], n)
+		metrics.NotificationsCollected.WithLabelValues(string(n.GetEventName())).Inc()
+	}
+
+	if len(resolved) > 0 {
+		// rules that are not fulfilled anymore notify again as soon as they are
+		_, err = db.FrontendWriterDB.Exec(`UPDATE users_subscriptions SET internal_state = NULL WHERE id = ANY($1)`, pq.Int64Array(resolved))
+		if err != nil {
+			return nil, fmt.Errorf("error resetting state of resolved notification rules: %w", err)
+		}
+	}
+	logger.Infof("evaluated %v notification rules, %v resolved", len(rules), len(resolved))
+
+	return notificationsByUserID, nil
+}
+
+// getRuleEvaluationData fetches the validators, the missed attestations and the machine metrics the rules refer to
+func getRuleEvaluationData(rules []*notificationRuleSubscription, epoch uint64) (*ruleEvaluationData, error) {
+	data := &ruleEvaluationData{
+		epoch:        epoch,
+		groups:       map[uint64]map[string][]ruleValidator{},
+		missedEpochs: map[uint64]map[uint64]bool{},
+		machines:     map[uint64]map[string]*types.MachineMetricSystemUser{},
+	}
+
+	userIDs := []uint64{}
+	tags := []string{}
+	rowKeys := []string{}
+	window := uint64(0)
+	for _, rule := range rules {
+		for _, c := range rule.Conditions {
+			if c.IsValidatorCondition() {
+				userIDs = append(userIDs, rule.UserID)
+				tags = append(tags, utils.GetNetwork()+":"+c.Group)
+				if c.Epochs > window {
+					window = c.Epochs
+				}
+			} else {
+				rowKeys = append(rowKeys, db.BigtableClient.GetMachineRowKey(rule.UserID, "system", c.Machine))
+			}
+		}
+	}
+
+	if len(userIDs) > 0 {
+		var tagged []struct {
+			UserID uint64 `db:"user_id"`
+			Tag    string `db:"tag"`
+			Pubkey []byte `db:"validator_publickey"`
+		}
+		err := db.FrontendWriterDB.Select(&tagged, `
+			SELECT user_id, tag, validator_publickey
+			FROM users_validators_tags
+			WHERE user_id = ANY($1) AND tag = ANY($2)`, pq.Array(userIDs), pq.StringArray(tags))
+		if err != nil {
+			return nil, fmt.Errorf("error getting validator groups of notification rules: %w", err)
+		}
+
+		pubkeys := make([][]byte, 0, len(tagged))
+		for _, t := range tagged {
+			pubkeys = append(pubkeys, t.Pubkey)
+		}
+		validatorsByPubkey := make(map[string]ruleValidator, len(pubkeys))
+		batchSize := 5000
+		for i := 0; i < len(pubkeys); i += batchSize {
+			end := i + batchSize
+			if end > len(pubkeys) {
+				end = len(pubkeys)
+			}
+			var partial []ruleValidator
+			err = db.WriterDb.Select(&partial, `SELECT validatorindex, pubkey, activationepoch, exitepoch FROM validators WHERE pubkey = ANY($1)`, pq.ByteaArray(pubkeys[i:end]))
+			if err != nil {
+				return nil, fmt.Errorf("error getting validators of notification rules: %w", err)
+			}
+			for _, v := range partial {
+				validatorsByPubkey[string(v.Pubkey)] = v
+			}
+		}
+
+		indices := make([]uint64, 0, len(validatorsByPubkey))
+		seen := make(map[uint64]bool, len(validatorsByPubkey))
+		for _, t := range tagged {
+			v, ok := validatorsByPubkey[string(t.Pubkey)]
+			if !ok {
+				// deposited validators that are not in the validator set yet
+				continue
+			}
+			group := strings.TrimPrefix(t.Tag, utils.GetNetwork()+":")
+			if data.groups[t.UserID] == nil {
+				data.groups[t.UserID] = map[string][]ruleValidator{}
+			}
+			data.groups[t.UserID][group] = append(data.groups[t.UserID][group], v)
+			if !seen[v.Index] {
+				seen[v.Index] = true
+				indices = append(indices, v.Index)
+			}
+		}
+
+		if len(indices) > 0 && epoch+1 >= window {
+			missed, err := db.BigtableClient.GetValidatorMissedAttestationHistory(indices, epoch+1-window, epoch)
+			if err != nil {
+				return nil, fmt.Errorf("error getting missed attestations of notification rules: %w", err)
+			}
+			for index, slots := range missed {
+				data.missedEpochs[index] = make(map[uint64]bool, len(slots))
+				for slot := range slots {
+					data.missedEpochs[index][utils.EpochOfSlot(slot)] = true
+				}
+			}
+		}
+	}
+
+	if len(rowKeys) > 0 {
+		machines, err := db.BigtableClient.GetMachineMetricsForNotifications(rowKeys)
+		if err != nil {
+			return nil, fmt.Errorf("error getting machine metrics of notification rules: %w", err)
+		}
+		data.machines = machines
+	}
+
+	return data, nil
+}
+
+// evaluate returns whether the rule is fulfilled and a description of each fulfilled condition
+func (d *ruleEvaluationData) evaluate(rule *notificationRuleSubscription) ([]string, bool) {
+	results := []string{}
+	for _, c := range rule.Conditions {
+		value, ok := d.value(rule.UserID, c)
+		if ok && c.Compare(value) {
+			results = append(results, fmt.Sprintf("%s (currently %s)", c.String(), strconv.FormatFloat(value, 'f', -1, 64)))
+		} else if rule.Match == types.NotificationRuleMatchAll {
+			return nil, false
+		}
+	}
+	return results, len(results) > 0
+}
+
+// value returns the current value of the metric of a condition, or false if it is not known
+func (d *ruleEvaluationData) value(userID uint64, c types.NotificationRuleCondition) (float64, bool) {
+	switch c.Metric {
+	case types.RuleValidatorsOffline, types.RuleAttestationsMissed:
+		if d.epoch+1 < c.Epochs {
+			return 0, false
+		}
+		first := d.epoch + 1 - c.Epochs
+		var offline, duties, missed uint64
+		for _, v := range d.groups[userID][c.Group] {
+			validatorDuties, validatorMissed := uint64(0), uint64(0)
+			for e := first; e <= d.epoch; e++ {
+				if v.ActivationEpoch > e || v.ExitEpoch <= e {
+					continue
+				}
+				validatorDuties++
+				if d.missedEpochs[v.Index][e] {
+					validatorMissed++
+				}
+			}
+			if validatorDuties == c.Epochs && validatorMissed == c.Epochs {
+				offline++
+			}
+			duties += validatorDuties
+			missed += validatorMissed
+		}
+		if c.Metric == types.RuleValidatorsOffline {
+			return float64(offline), true
+		}
+		if duties == 0 {
+			return 0, false
+		}
+		return float64(int64(float64(missed)/float64(duties)*10000)) / 100, true
+	case types.RuleMachineCpuLoad, types.RuleMachineMemoryUsage, types.RuleMachineDiskUsage:
+		machineData, ok := d.machines[userID][c.Machine]
+		if !ok {
+			return 0, false
+		}
+		var usage float64
+		switch c.Metric {
+		case types.RuleMachineCpuLoad:
+			usage, ok = machineCpuLoad(machineData)
+		case types.RuleMachineMemoryUsage:
+			usage, ok = machineMemoryUsage(machineData)
+		default:
+			usage, ok = machineDiskUsage(machineData)
+		}
+		return float64(int64(usage*10000)) / 100, ok
+	}
+	return 0, false
+}
+
+type notificationRuleNotification struct {
+	SubscriptionID  uint64
+	RuleID          uint64
+	RuleName        string
+	Epoch           uint64
+	Results         []string
+	UnsubscribeHash sql.NullString
+}
+
+func (n *notificationRuleNotification) GetLatestState() string {
+	return ruleFiringState
+}
+
+func (n *notificationRuleNotification) GetSubscriptionID() uint64 {
+	return n.SubscriptionID
+}
+
+func (n *notificationRuleNotification) GetEventName() types.EventName {
+	return types.NotificationRuleEventName
+}
+
+func (n *notificationRuleNotification) GetEpoch() uint64 {
+	return n.Epoch
+}
+
+func (n *notificationRuleNotification) GetInfo(includeUrl bool) string {
+	generalPart := fmt.Sprintf(`Your notification rule "%s" matched at epoch %v: %s.`, html.EscapeString(n.RuleName), n.Epoch, html.EscapeString(strings.Join(n.Results, "; ")))
+	if includeUrl {
+		return generalPart + fmt.Sprintf(` Manage your rules at: <a href='https://%[1]s/user/notifications/rules'>https://%[1]s/user/notifications/rules</a>.`, utils.Config.Frontend.SiteDomain)
+	}
+	return generalPart
+}
+
+func (n *notificationRuleNotification) GetTitle() string {
+	return "Notification Rule Matched"
+}
+
+func (n *notificationRuleNotification) GetEventFilter() string {
+	return strconv.FormatUint(n.RuleID, 10)
+}
+
+func (n *notificationRuleNotification) GetEmailAttachment() *types.EmailAttachment {
+	return nil
+}
+
+func (n *notificationRuleNotification) GetUnsubscribeHash() string {
+	if n.UnsubscribeHash.Valid {
+		return n.UnsubscribeHash.String
+	}
+	return ""
+}
+
+func (n *notificationRuleNotification) GetInfoMarkdown() string {
+	return fmt.Sprintf(`Your [notification rule](https://%s/user/notifications/rules) "%s" matched at epoch %v: %s.`, utils.Config.Frontend.SiteDomain, notify.EscapeMarkdown(n.RuleName), n.Epoch, notify.EscapeMarkdown(strings.Join(n.Results, "; ")))
+}
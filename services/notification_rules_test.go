@@ -0,0 +1,145 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/notify"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+)
+
+// testMissedEpochs marks the epochs [first, last] as missed for each of the given validators
+func testMissedEpochs(missed map[uint64]map[uint64]bool, first, last uint64, indices ...uint64) map[uint64]map[uint64]bool {
+	for _, index := range indices {
+		if missed[index] == nil {
+			missed[index] = map[uint64]bool{}
+		}
+		for e := first; e <= last; e++ {
+			missed[index][e] = true
+		}
+	}
+	return missed
+}
+
+// testMachine returns the metrics of a machine that reported the given cpu load over the last five minutes
+func testMachine(cpuLoadPercent uint64, insertTs time.Time) *types.MachineMetricSystemUser {
+	return &types.MachineMetricSystemUser{
+		CurrentData:         &types.MachineMetricSystem{CpuNodeIdleSecondsTotal: 1100 - cpuLoadPercent, CpuNodeSystemSecondsTotal: 1100},
+		CurrentDataInsertTs: insertTs.Unix(),
+		FiveMinuteOldData:   &types.MachineMetricSystem{CpuNodeIdleSecondsTotal: 1000, CpuNodeSystemSecondsTotal: 1000},
+	}
+}
+
+func TestRuleValidatorsOffline(t *testing.T) {
+	// ≥3 validators in group X offline for ≥2 epochs
+	rule := &notificationRuleSubscription{NotificationRule: types.NotificationRule{
+		UserID: 1,
+		Match:  types.NotificationRuleMatchAll,
+		Conditions: []types.NotificationRuleCondition{
+			{Metric: types.RuleValidatorsOffline, Group: "X", Epochs: 2, Operator: ">=", Value: 3},
+		},
+	}}
+	groups := map[uint64]map[string][]ruleValidator{1: {"X": {
+		{Index: 1, ExitEpoch: 1000},
+		{Index: 2, ExitEpoch: 1000},
+		{Index: 3, ExitEpoch: 1000},
+		{Index: 4, ExitEpoch: 1000},
+		// only active in the last epoch of the window
+		{Index: 5, ActivationEpoch: 100, ExitEpoch: 1000},
+	}}}
+
+	tests := []struct {
+		name      string
+		epoch     uint64
+		missed    map[uint64]map[uint64]bool
+		wantValue float64
+		wantKnown bool
+		fulfilled bool
+	}{
+		{"three offline", 100, testMissedEpochs(map[uint64]map[uint64]bool{}, 99, 100, 1, 2, 3), 3, true, true},
+		{"four offline", 100, testMissedEpochs(map[uint64]map[uint64]bool{}, 99, 100, 1, 2, 3, 4), 4, true, true},
+		{"one back online", 100, testMissedEpochs(testMissedEpochs(map[uint64]map[uint64]bool{}, 99, 100, 1, 2), 99, 99, 3), 2, true, false},
+		{"offline outside of the window", 100, testMissedEpochs(map[uint64]map[uint64]bool{}, 97, 98, 1, 2, 3), 0, true, false},
+		{"not active for the whole window", 100, testMissedEpochs(map[uint64]map[uint64]bool{}, 99, 100, 1, 2, 5), 2, true, false},
+		{"window before genesis", 0, testMissedEpochs(map[uint64]map[uint64]bool{}, 0, 0, 1, 2, 3), 0, false, false},
+	}
+	for _, tt := range tests {
+		d := &ruleEvaluationData{epoch: tt.epoch, groups: groups, missedEpochs: tt.missed}
+		value, known := d.value(rule.UserID, rule.Conditions[0])
+		if value != tt.wantValue || known != tt.wantKnown {
+			t.Errorf("%v: got value %v (known %v), want %v (known %v)", tt.name, value, known, tt.wantValue, tt.wantKnown)
+		}
+		results, fulfilled := d.evaluate(rule)
+		if fulfilled != tt.fulfilled || (fulfilled && len(results) != 1) {
+			t.Errorf("%v: got fulfilled %v with results %v, want fulfilled %v", tt.name, fulfilled, results, tt.fulfilled)
+		}
+	}
+}
+
+func TestRuleMissedAttestationsAndCpuLoad(t *testing.T) {
+	// missed > 5% over 10 epochs AND CPU > 90%
+	conditions := []types.NotificationRuleCondition{
+		{Metric: types.RuleAttestationsMissed, Group: "X", Epochs: 10, Operator: ">", Value: 5},
+		{Metric: types.RuleMachineCpuLoad, Machine: "node", Operator: ">", Value: 90},
+	}
+	groups := map[uint64]map[string][]ruleValidator{1: {"X": {
+		{Index: 1, ExitEpoch: 1000},
+		{Index: 2, ExitEpoch: 1000},
+	}}}
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		match     string
+		missed    map[uint64]map[uint64]bool
+		machine   *types.MachineMetricSystemUser
+		fulfilled bool
+		results   int
+	}{
+		// 2 of 20 attestations are 10%
+		{"both fulfilled", types.NotificationRuleMatchAll, testMissedEpochs(map[uint64]map[uint64]bool{}, 99, 100, 1), testMachine(95, now), true, 2},
+		// 1 of 20 attestations are 5%
+		{"missed not above threshold", types.NotificationRuleMatchAll, testMissedEpochs(map[uint64]map[uint64]bool{}, 100, 100, 1), testMachine(95, now), false, 0},
+		{"missed outside of the window", types.NotificationRuleMatchAll, testMissedEpochs(map[uint64]map[uint64]bool{}, 80, 90, 1, 2), testMachine(95, now), false, 0},
+		{"cpu not above threshold", types.NotificationRuleMatchAll, testMissedEpochs(map[uint64]map[uint64]bool{}, 99, 100, 1), testMachine(80, now), false, 0},
+		{"cpu not reported recently", types.NotificationRuleMatchAll, testMissedEpochs(map[uint64]map[uint64]bool{}, 99, 100, 1), testMachine(95, now.Add(-2*time.Hour)), false, 0},
+		{"machine unknown", types.NotificationRuleMatchAll, testMissedEpochs(map[uint64]map[uint64]bool{}, 99, 100, 1), nil, false, 0},
+		{"any with cpu not above threshold", types.NotificationRuleMatchAny, testMissedEpochs(map[uint64]map[uint64]bool{}, 99, 100, 1), testMachine(80, now), true, 1},
+		{"any with nothing fulfilled", types.NotificationRuleMatchAny, map[uint64]map[uint64]bool{}, testMachine(80, now), false, 0},
+	}
+	for _, tt := range tests {
+		machines := map[uint64]map[string]*types.MachineMetricSystemUser{1: {}}
+		if tt.machine != nil {
+			machines[1]["node"] = tt.machine
+		}
+		d := &ruleEvaluationData{epoch: 100, groups: groups, missedEpochs: tt.missed, machines: machines}
+		rule := &notificationRuleSubscription{NotificationRule: types.NotificationRule{UserID: 1, Match: tt.match, Conditions: conditions}}
+		results, fulfilled := d.evaluate(rule)
+		if fulfilled != tt.fulfilled || len(results) != tt.results {
+			t.Errorf("%v: got fulfilled %v with results %v, want fulfilled %v with %v results", tt.name, fulfilled, results, tt.fulfilled, tt.results)
+		}
+	}
+
+	d := &ruleEvaluationData{epoch: 100, groups: groups, missedEpochs: testMissedEpochs(map[uint64]map[uint64]bool{}, 98, 100, 1)}
+	if value, _ := d.value(1, conditions[0]); value != 15 {
+		t.Errorf("got %v%% missed attestations, want 15%%", value)
+	}
+}
+
+func TestNotificationRuleInfoMarkdownEscapesName(t *testing.T) {
+	prevConfig := utils.Config
+	defer func() { utils.Config = prevConfig }()
+	utils.Config = &types.Config{}
+	utils.Config.Frontend.SiteDomain = "beaconcha.in"
+
+	n := &notificationRuleNotification{RuleName: "[click](https://evil.example) *now*", Epoch: 100, Results: []string{"cpu load of machine a_b > 90% (currently 95)"}}
+	msg := notify.FormatChatMessage(types.TelegramNotificationChannel, n.GetTitle(), n.GetInfoMarkdown())
+	if strings.Count(msg.Formatted, "<a href") != 1 {
+		t.Errorf("expected the rule name not to be formatted as a link, got %q", msg.Formatted)
+	}
+	if !strings.Contains(msg.Formatted, "[click](https://evil.example) *now*") || !strings.Contains(msg.Formatted, "machine a_b") {
+		t.Errorf("expected the rule name and the results to be shown verbatim, got %q", msg.Formatted)
+	}
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db"
+	"github.com/gobitfly/eth2-beaconchain-explorer/metrics"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+)
+
+// The collection of an epoch is split into shards which the collector instances claim with a lease in the
+// notification_collector_shards table. An instance renews the lease while it is collecting a shard, queues the
+// collected notifications and then marks the shard as completed. Shards of crashed instances are claimed by other
+// instances once their lease has expired. The queued rows carry dedupe keys derived from the epoch and shard, so a shard
+// that is collected again after its instance crashed or lost its lease before completing it is not queued twice. The
+// instance that completes the last shard of an epoch marks the epoch as notified.
+
+const notificationCollectorLeaseDefault = time.Minute * 5
+
+// shards older than this are removed from the shards table
+const notificationCollectorShardsRetention = 100
+
+func notificationCollectorLease() time.Duration {
+	if utils.Config.Notifications.CollectorLeaseDuration > 0 {
+		return utils.Config.Notifications.CollectorLeaseDuration
+	}
+	return notificationCollectorLeaseDefault
+}
+
+// getPendingNotificationEpoch returns the oldest epoch not older than minEpoch that still has shards which have not been
+// completed, or 0 if there is no such epoch
+func getPendingNotificationEpoch(minEpoch uint64) (uint64, error) {
+	var epoch uint64
+	err := db.WriterDb.Get(&epoch, "SELECT COALESCE(MIN(epoch), 0) FROM notification_collector_shards WHERE completed IS NULL AND epoch >= $1", minEpoch)
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving pending notification shards: %w", err)
+	}
+	return epoch, nil
+}
+
+// collectEpochNotificationShards creates the shards of the epoch if no instance did so yet and processes shards of the
+// epoch until all of them have been claimed
+func collectEpochNotificationShards(epoch uint64) error {
+	shardCount := utils.Config.Notifications.CollectorShards
+	if shardCount == 0 {
+		shardCount = 1
+	}
+
+	_, err := db.WriterDb.Exec(`
+		INSERT INTO notification_collector_shards (epoch, shard, shard_count)
+		SELECT $1, generate_series(0, $2 - 1), $2
+		WHERE NOT EXISTS (SELECT 1 FROM notification_collector_shards WHERE epoch = $1)
+		ON CONFLICT (epoch, shard) DO NOTHING`, epoch, shardCount)
+	if err != nil {
+		return fmt.Errorf("error creating notification shards for epoch %v: %w", epoch, err)
+	}
+
+	for {
+		shard, claimed, err := claimNotificationShard(epoch)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return nil
+		}
+
+		err = processNotificationShard(epoch, shard)
+		if err != nil {
+			releaseErr := releaseNotificationShard(epoch, shard)
+			if releaseErr != nil {
+				logger.WithError(releaseErr).Errorf("error releasing notification shard %v of epoch %v", shard.Index, epoch)
+			}
+			return err
+		}
+	}
+}
+
+// claimNotificationShard leases a shard of the epoch that has neither been completed nor is leased by another instance
+func claimNotificationShard(epoch uint64) (types.NotificationShard, bool, error) {
+	var shard struct {
+		Index uint64 `db:"shard"`
+		Count uint64 `db:"shard_count"`
+	}
+	err := db.WriterDb.Get(&shard, `
+		UPDATE notification_collector_shards SET owner = $2, lease_until = NOW() + $3 * INTERVAL '1 second'
+		WHERE (epoch, shard) = (
+			SELECT epoch, shard FROM notification_collector_shards
+			WHERE epoch = $1 AND completed IS NULL AND (lease_until IS NULL OR lease_until < NOW())
+			ORDER BY shard
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING shard, shard_count`, epoch, instanceID, notificationCollectorLease().Seconds())
+	if errors.Is(err, sql.ErrNoRows) {
+		return types.NotificationShard{}, false, nil
+	}
+	if err != nil {
+		return types.NotificationShard{}, false, fmt.Errorf("error claiming notification shard of epoch %v: %w", epoch, err)
+	}
+	return types.NotificationShard{Index: shard.Index, Count: shard.Count}, true, nil
+}
+
+// renewNotificationShardLease extends the lease of the shard until the returned function is called
+func renewNotificationShardLease(epoch uint64, shard types.NotificationShard) func() {
+	lease := notificationCollectorLease()
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				res, err := db.WriterDb.Exec(`
+					UPDATE notification_collector_shards SET lease_until = NOW() + $4 * INTERVAL '1 second'
+					WHERE epoch = $1 AND shard = $2 AND owner = $3 AND completed IS NULL`,
+					epoch, shard.Index, instanceID, lease.Seconds())
+				if err != nil {
+					logger.WithError(err).Errorf("error renewing lease of notification shard %v of epoch %v", shard.Index, epoch)
+					continue
+				}
+				if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+					logger.Warnf("lease of notification shard %v of epoch %v has been taken over by another instance", shard.Index, epoch)
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+	}
+}
+
+// completeNotificationShard marks the shard as completed and the epoch as notified if it was the last pending shard.
+// It returns false if the shard has been taken over by another instance in the meantime.
+func completeNotificationShard(epoch uint64, shard types.NotificationShard) (bool, error) {
+	tx, err := db.WriterDb.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// lock the shards of the epoch so that exactly one instance sees all of them completed
+	_, err = tx.Exec(`SELECT 1 FROM notification_collector_shards WHERE epoch = $1 FOR UPDATE`, epoch)
+	if err != nil {
+		return false, fmt.Errorf("error locking notification shards of epoch %v: %w", epoch, err)
+	}
+
+	res, err := tx.Exec(`
+		UPDATE notification_collector_shards SET completed = NOW(), lease_until = NULL
+		WHERE epoch = $1 AND shard = $2 AND owner = $3 AND completed IS NULL`, epoch, shard.Index, instanceID)
+	if err != nil {
+		return false, fmt.Errorf("error completing notification shard %v of epoch %v: %w", shard.Index, epoch, err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error completing notification shard %v of epoch %v: %w", shard.Index, epoch, err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	var pending uint64
+	err = tx.Get(&pending, `SELECT COUNT(*) FROM notification_collector_shards WHERE epoch = $1 AND completed IS NULL`, epoch)
+	if err != nil {
+		return false, fmt.Errorf("error counting pending notification shards of epoch %v: %w", epoch, err)
+	}
+	if pending == 0 {
+		_, err = tx.Exec("INSERT INTO epochs_notified VALUES ($1, NOW()) ON CONFLICT (epoch) DO NOTHING", epoch)
+		if err != nil {
+			return false, fmt.Errorf("error marking notification status for epoch %v in db: %w", epoch, err)
+		}
+	}
+	return true, tx.Commit()
+}
+
+// releaseNotificationShard drops the lease of a shard that could not be processed so it is retried right away
+func releaseNotificationShard(epoch uint64, shard types.NotificationShard) error {
+	_, err := db.WriterDb.Exec(`
+		UPDATE notification_collector_shards SET owner = NULL, lease_until = NULL
+		WHERE epoch = $1 AND shard = $2 AND owner = $3 AND completed IS NULL`, epoch, shard.Index, instanceID)
+	return err
+}
+
+func garbageCollectNotificationShards(latestEpoch uint64) error {
+	if latestEpoch < notificationCollectorShardsRetention {
+		return nil
+	}
+	_, err := db.WriterDb.Exec("DELETE FROM notification_collector_shards WHERE epoch < $1", latestEpoch-notificationCollectorShardsRetention)
+	if err != nil {
+		return fmt.Errorf("error deleting old notification shards: %w", err)
+	}
+	return nil
+}
+
+// processNotificationShard collects and queues the notifications of the shard and completes it afterwards.
+// The user db and notification rule notifications are collected as part of the first shard, so all instances need the
+// same userDbNotifications setting.
+func processNotificationShard(epoch uint64, shard types.NotificationShard) error {
+	start := time.Now()
+	logger.Infof("collecting notifications for epoch %v (shard %v of %v)", epoch, shard.Index+1, shard.Count)
+
+	stopLeaseRenewal := renewNotificationShardLease(epoch, shard)
+	defer stopLeaseRenewal()
+
+	// Network DB Notifications (network related)
+	notifications, err := collectNotifications(epoch, shard)
+	if err != nil {
+		return fmt.Errorf("error collection notifications: %w", err)
+	}
+
+	// Network DB Notifications (user related, must only run on one instance ever!!!!)
+	var userNotifications, ruleNotifications map[uint64]map[types.EventName][]types.Notification
+	if utils.Config.Notifications.UserDBNotifications && shard.Index == 0 {
+		logger.Infof("collecting user db notifications")
+		userNotifications, err = collectUserDbNotifications(epoch)
+		if err != nil {
+			logger.Errorf("error collection user db notifications: %v", err)
+			ReportStatus("notification-collector", "Error", nil)
+		}
+
+		logger.Infof("collecting notification rule notifications")
+		ruleNotifications, err = collectRuleNotifications(epoch)
+		if err != nil {
+			logger.Errorf("error collection notification rule notifications: %v", err)
+			metrics.Errors.WithLabelValues("notifications_collect_notification_rules").Inc()
+			ReportStatus("notification-collector", "Error", nil)
+		}
+	}
+
+	queueKey := fmt.Sprintf("%s:%d:%d", utils.GetNetwork(), epoch, shard.Index)
+	queueNotifications(notifications, db.FrontendWriterDB, queueKey+":network") // this caused the collected notifications to be queued and sent
+	if userNotifications != nil {
+		queueNotifications(userNotifications, db.FrontendWriterDB, queueKey+":user")
+	}
+	if ruleNotifications != nil {
+		queueNotifications(ruleNotifications, db.FrontendWriterDB, queueKey+":rule")
+	}
+
+	completed, err := completeNotificationShard(epoch, shard)
+	if err != nil {
+		return err
+	}
+	if !completed {
+		// the instance that took the shard over queues the same dedupe keys, so nothing is queued twice
+		logger.Warnf("notification shard %v of epoch %v has been taken over by another instance", shard.Index, epoch)
+		return nil
+	}
+
+	logger.
+		WithField("notifications", len(notifications)).
+		WithField("duration", time.Since(start)).
+		WithField("epoch", epoch).
+		WithField("shard", shard.Index).
+		Info("notifications completed")
+
+	metrics.TaskDuration.WithLabelValues("service_notifications").Observe(time.Since(start).Seconds())
+	return nil
+}
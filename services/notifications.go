@@ -13,11 +13,14 @@ import (
 	"html"
 	"html/template"
 	"io"
+	"math"
 	"math/big"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gobitfly/eth2-beaconchain-explorer/db"
@@ -25,15 +28,18 @@ import (
 	"github.com/gobitfly/eth2-beaconchain-explorer/mail"
 	"github.com/gobitfly/eth2-beaconchain-explorer/metrics"
 	"github.com/gobitfly/eth2-beaconchain-explorer/notify"
+	"github.com/gobitfly/eth2-beaconchain-explorer/rpc"
 	"github.com/gobitfly/eth2-beaconchain-explorer/types"
 	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
 
-	gcp_bigtable "cloud.google.com/go/bigtable"
 	"firebase.google.com/go/v4/messaging"
 	"github.com/ethereum/go-ethereum/common"
+	itypes "github.com/gobitfly/eth-rewards/types"
+	"github.com/hashicorp/go-version"
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 	"github.com/rocket-pool/rocketpool-go/utils/eth"
+	"golang.org/x/sync/errgroup"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
@@ -62,6 +68,17 @@ func notificationCollector() {
 			continue
 		}
 
+		// shards of earlier epochs that are still pending, e.g. because their collector crashed, are picked up again
+		pendingEpoch, err := getPendingNotificationEpoch(latestFinalizedEpoch - 5)
+		if err != nil {
+			logger.Error(err)
+			time.Sleep(time.Minute)
+			continue
+		}
+		if pendingEpoch != 0 && pendingEpoch <= lastNotifiedEpoch {
+			lastNotifiedEpoch = pendingEpoch - 1
+		}
+
 		logger.Infof("latest finalized epoch is %v, latest notified epoch is %v", latestFinalizedEpoch, lastNotifiedEpoch)
 
 		if latestFinalizedEpoch < lastNotifiedEpoch {
@@ -88,48 +105,17 @@ func notificationCollector() {
 				logger.Errorf("epoch notification consistency error, epochs %v - %v are not all yet exported into the db (wanted %v, got %v)", epoch, epoch-3, 4, exported)
 			}
 
-			start := time.Now()
-			logger.Infof("collecting notifications for epoch %v", epoch)
-
-			// Network DB Notifications (network related)
-			notifications, err := collectNotifications(epoch)
-
-			if err != nil {
-				logger.Errorf("error collection notifications: %v", err)
-				ReportStatus("notification-collector", "Error", nil)
-				break
-			}
-
-			_, err = db.WriterDb.Exec("INSERT INTO epochs_notified VALUES ($1, NOW())", epoch)
+			err = collectEpochNotificationShards(epoch)
 			if err != nil {
-				logger.Errorf("error marking notification status for epoch %v in db: %v", epoch, err)
+				logger.Errorf("error collecting notifications of epoch %v: %v", epoch, err)
 				ReportStatus("notification-collector", "Error", nil)
 				break
 			}
+		}
 
-			queueNotifications(notifications, db.FrontendWriterDB) // this caused the collected notifications to be queued and sent
-
-			// Network DB Notifications (user related, must only run on one instance ever!!!!)
-			if utils.Config.Notifications.UserDBNotifications {
-				logger.Infof("collecting user db notifications")
-				userNotifications, err := collectUserDbNotifications(epoch)
-				if err != nil {
-					logger.Errorf("error collection user db notifications: %v", err)
-					ReportStatus("notification-collector", "Error", nil)
-					time.Sleep(time.Minute * 2)
-					continue
-				}
-
-				queueNotifications(userNotifications, db.FrontendWriterDB)
-			}
-
-			logger.
-				WithField("notifications", len(notifications)).
-				WithField("duration", time.Since(start)).
-				WithField("epoch", epoch).
-				Info("notifications completed")
-
-			metrics.TaskDuration.WithLabelValues("service_notifications").Observe(time.Since(start).Seconds())
+		err = garbageCollectNotificationShards(latestFinalizedEpoch)
+		if err != nil {
+			logger.Error(err)
 		}
 
 		ReportStatus("notification-collector", "Running", nil)
@@ -162,6 +148,11 @@ func notificationSender() {
 		}
 
 		logger.Info("lock obtained")
+		err = queueNotificationDigests(db.FrontendWriterDB)
+		if err != nil {
+			logger.WithError(err).Error("error queuing notification digests")
+		}
+
 		err = dispatchNotifications(db.FrontendWriterDB)
 		if err != nil {
 			logger.WithError(err).Error("error dispatching notifications")
@@ -171,6 +162,11 @@ func notificationSender() {
 		if err != nil {
 			logger.WithError(err).Errorf("error garbage collecting the notification queue")
 		}
+
+		err = garbageCollectNotificationHistory()
+		if err != nil {
+			logger.WithError(err).Errorf("error garbage collecting the notification history")
+		}
 		logger.WithField("duration", time.Since(start)).Info("notifications dispatched and garbage collected")
 		metrics.TaskDuration.WithLabelValues("service_notifications_sender").Observe(time.Since(start).Seconds())
 
@@ -206,7 +202,9 @@ func notificationSender() {
 	}
 }
 
-func collectNotifications(epoch uint64) (map[uint64]map[types.EventName][]types.Notification, error) {
+// collectNotifications collects the notifications of the shard for the epoch. The network, rocketpool network
+// and sync committee notifications are only collected as part of the first shard.
+func collectNotifications(epoch uint64, shard types.NotificationShard) (map[uint64]map[types.EventName][]types.Notification, error) {
 	notificationsByUserID := map[uint64]map[types.EventName][]types.Notification{}
 	start := time.Now()
 	var err error
@@ -234,54 +232,77 @@ func collectNotifications(epoch uint64) (map[uint64]map[types.EventName][]types.
 
 	logger.Infof("started collecting notifications")
 
-	err = collectAttestationAndOfflineValidatorNotifications(notificationsByUserID, 0, epoch)
+	err = collectAttestationAndOfflineValidatorNotifications(notificationsByUserID, 0, epoch, shard)
 	if err != nil {
 		metrics.Errors.WithLabelValues("notifications_collect_missed_attestation").Inc()
 		return nil, fmt.Errorf("error collecting validator_attestation_missed notifications: %v", err)
 	}
 	logger.Infof("collecting attestation & offline notifications took: %v", time.Since(start))
 
-	err = collectBlockProposalNotifications(notificationsByUserID, 1, types.ValidatorExecutedProposalEventName, epoch)
+	err = collectBlockProposalNotifications(notificationsByUserID, 1, types.ValidatorExecutedProposalEventName, epoch, shard)
 	if err != nil {
 		metrics.Errors.WithLabelValues("notifications_collect_executed_block_proposal").Inc()
 		return nil, fmt.Errorf("error collecting validator_proposal_submitted notifications: %v", err)
 	}
 	logger.Infof("collecting block proposal proposed notifications took: %v", time.Since(start))
 
-	err = collectBlockProposalNotifications(notificationsByUserID, 2, types.ValidatorMissedProposalEventName, epoch)
+	err = collectBlockProposalNotifications(notificationsByUserID, 2, types.ValidatorMissedProposalEventName, epoch, shard)
 	if err != nil {
 		metrics.Errors.WithLabelValues("notifications_collect_missed_block_proposal").Inc()
 		return nil, fmt.Errorf("error collecting validator_proposal_missed notifications: %v", err)
 	}
 	logger.Infof("collecting block proposal missed notifications took: %v", time.Since(start))
 
-	err = collectBlockProposalNotifications(notificationsByUserID, 3, types.ValidatorMissedProposalEventName, epoch)
+	err = collectBlockProposalNotifications(notificationsByUserID, 3, types.ValidatorMissedProposalEventName, epoch, shard)
 	if err != nil {
 		metrics.Errors.WithLabelValues("notifications_collect_missed_orphaned_block_proposal").Inc()
 		return nil, fmt.Errorf("error collecting validator_proposal_missed notifications for orphaned slots: %w", err)
 	}
 	logger.Infof("collecting block proposal missed notifications for orphaned slots took: %v", time.Since(start))
 
-	err = collectValidatorGotSlashedNotifications(notificationsByUserID, epoch)
+	err = collectValidatorGotSlashedNotifications(notificationsByUserID, epoch, shard)
 	if err != nil {
 		metrics.Errors.WithLabelValues("notifications_collect_validator_got_slashed").Inc()
 		return nil, fmt.Errorf("error collecting validator_got_slashed notifications: %v", err)
 	}
 	logger.Infof("collecting validator got slashed notifications took: %v", time.Since(start))
 
-	err = collectWithdrawalNotifications(notificationsByUserID, epoch)
+	err = collectWithdrawalNotifications(notificationsByUserID, epoch, shard)
 	if err != nil {
 		metrics.Errors.WithLabelValues("notifications_collect_validator_withdrawal").Inc()
 		return nil, fmt.Errorf("error collecting withdrawal notifications: %v", err)
 	}
 	logger.Infof("collecting withdrawal notifications took: %v", time.Since(start))
 
-	err = collectNetworkNotifications(notificationsByUserID, types.NetworkLivenessIncreasedEventName)
+	err = collectConsolidationNotifications(notificationsByUserID, epoch, shard)
+	if err != nil {
+		metrics.Errors.WithLabelValues("notifications_collect_validator_consolidated").Inc()
+		return nil, fmt.Errorf("error collecting consolidation notifications: %v", err)
+	}
+	logger.Infof("collecting consolidation notifications took: %v", time.Since(start))
+
+	err = collectExecutionLayerExitNotifications(notificationsByUserID, epoch, shard)
+	if err != nil {
+		metrics.Errors.WithLabelValues("notifications_collect_validator_el_exit").Inc()
+		return nil, fmt.Errorf("error collecting execution layer exit notifications: %v", err)
+	}
+	logger.Infof("collecting execution layer exit notifications took: %v", time.Since(start))
+
+	err = collectIncomeAnomalyNotifications(notificationsByUserID, epoch, shard)
 	if err != nil {
-		metrics.Errors.WithLabelValues("notifications_collect_network").Inc()
-		return nil, fmt.Errorf("error collecting network notifications: %v", err)
+		metrics.Errors.WithLabelValues("notifications_collect_validator_income_below_network").Inc()
+		return nil, fmt.Errorf("error collecting income anomaly notifications: %v", err)
+	}
+	logger.Infof("collecting income anomaly notifications took: %v", time.Since(start))
+
+	if shard.Index == 0 {
+		err = collectNetworkNotifications(notificationsByUserID, types.NetworkLivenessIncreasedEventName)
+		if err != nil {
+			metrics.Errors.WithLabelValues("notifications_collect_network").Inc()
+			return nil, fmt.Errorf("error collecting network notifications: %v", err)
+		}
+		logger.Infof("collecting network notifications took: %v", time.Since(start))
 	}
-	logger.Infof("collecting network notifications took: %v", time.Since(start))
 
 	// Rocketpool
 	{
@@ -296,28 +317,30 @@ func collectNotifications(epoch uint64) (map[uint64]map[types.EventName][]types.
 				return nil, fmt.Errorf("error collecting rocketpool notifications: %v", err)
 			}
 		} else {
-			err = collectRocketpoolComissionNotifications(notificationsByUserID, types.RocketpoolCommissionThresholdEventName)
-			if err != nil {
-				metrics.Errors.WithLabelValues("notifications_collect_rocketpool_comission").Inc()
-				return nil, fmt.Errorf("error collecting rocketpool commission: %v", err)
-			}
-			logger.Infof("collecting rocketpool commissions took: %v", time.Since(start))
+			if shard.Index == 0 {
+				err = collectRocketpoolComissionNotifications(notificationsByUserID, types.RocketpoolCommissionThresholdEventName)
+				if err != nil {
+					metrics.Errors.WithLabelValues("notifications_collect_rocketpool_comission").Inc()
+					return nil, fmt.Errorf("error collecting rocketpool commission: %v", err)
+				}
+				logger.Infof("collecting rocketpool commissions took: %v", time.Since(start))
 
-			err = collectRocketpoolRewardClaimRoundNotifications(notificationsByUserID, types.RocketpoolNewClaimRoundStartedEventName)
-			if err != nil {
-				metrics.Errors.WithLabelValues("notifications_collect_rocketpool_reward_claim").Inc()
-				return nil, fmt.Errorf("error collecting new rocketpool claim round: %v", err)
+				err = collectRocketpoolRewardClaimRoundNotifications(notificationsByUserID, types.RocketpoolNewClaimRoundStartedEventName)
+				if err != nil {
+					metrics.Errors.WithLabelValues("notifications_collect_rocketpool_reward_claim").Inc()
+					return nil, fmt.Errorf("error collecting new rocketpool claim round: %v", err)
+				}
+				logger.Infof("collecting rocketpool claim round took: %v", time.Since(start))
 			}
-			logger.Infof("collecting rocketpool claim round took: %v", time.Since(start))
 
-			err = collectRocketpoolRPLCollateralNotifications(notificationsByUserID, types.RocketpoolCollateralMaxReached, epoch)
+			err = collectRocketpoolRPLCollateralNotifications(notificationsByUserID, types.RocketpoolCollateralMaxReached, epoch, shard)
 			if err != nil {
 				metrics.Errors.WithLabelValues("notifications_collect_rocketpool_rpl_collateral_max_reached").Inc()
 				return nil, fmt.Errorf("error collecting rocketpool max collateral: %v", err)
 			}
 			logger.Infof("collecting rocketpool max collateral took: %v", time.Since(start))
 
-			err = collectRocketpoolRPLCollateralNotifications(notificationsByUserID, types.RocketpoolCollateralMinReached, epoch)
+			err = collectRocketpoolRPLCollateralNotifications(notificationsByUserID, types.RocketpoolCollateralMinReached, epoch, shard)
 			if err != nil {
 				metrics.Errors.WithLabelValues("notifications_collect_rocketpool_rpl_collateral_min_reached").Inc()
 				return nil, fmt.Errorf("error collecting rocketpool min collateral: %v", err)
@@ -326,12 +349,14 @@ func collectNotifications(epoch uint64) (map[uint64]map[types.EventName][]types.
 		}
 	}
 
-	err = collectSyncCommittee(notificationsByUserID, types.SyncCommitteeSoon, epoch)
-	if err != nil {
-		metrics.Errors.WithLabelValues("notifications_collect_sync_committee").Inc()
-		return nil, fmt.Errorf("error collecting sync committee: %v", err)
+	if shard.Index == 0 {
+		err = collectSyncCommittee(notificationsByUserID, types.SyncCommitteeSoon, epoch)
+		if err != nil {
+			metrics.Errors.WithLabelValues("notifications_collect_sync_committee").Inc()
+			return nil, fmt.Errorf("error collecting sync committee: %v", err)
+		}
+		logger.Infof("collecting sync committee took: %v", time.Since(start))
 	}
-	logger.Infof("collecting sync committee took: %v", time.Since(start))
 
 	return notificationsByUserID, nil
 }
@@ -368,6 +393,34 @@ func collectUserDbNotifications(epoch uint64) (map[uint64]map[types.EventName][]
 		return nil, fmt.Errorf("error collecting Eth client memory notifications: %v", err)
 	}
 
+	// Monitoring (premium): beacon node sync state
+	err = collectMonitoringBeaconNodeNotSynced(notificationsByUserID, epoch)
+	if err != nil {
+		metrics.Errors.WithLabelValues("notifications_collect_monitoring_beacon_node_not_synced").Inc()
+		return nil, fmt.Errorf("error collecting beacon node sync notifications: %v", err)
+	}
+
+	// Monitoring (premium): execution client sync lag
+	err = collectMonitoringExecutionBehind(notificationsByUserID, epoch)
+	if err != nil {
+		metrics.Errors.WithLabelValues("notifications_collect_monitoring_execution_behind").Inc()
+		return nil, fmt.Errorf("error collecting execution client sync notifications: %v", err)
+	}
+
+	// Monitoring (premium): beacon node peer count
+	err = collectMonitoringPeerCountLow(notificationsByUserID, epoch)
+	if err != nil {
+		metrics.Errors.WithLabelValues("notifications_collect_monitoring_peer_count_low").Inc()
+		return nil, fmt.Errorf("error collecting beacon node peer count notifications: %v", err)
+	}
+
+	// Monitoring (premium): outdated beacon node client
+	err = collectMonitoringClientOutdated(notificationsByUserID, epoch)
+	if err != nil {
+		metrics.Errors.WithLabelValues("notifications_collect_monitoring_client_outdated").Inc()
+		return nil, fmt.Errorf("error collecting outdated client notifications: %v", err)
+	}
+
 	// New ETH clients
 	err = collectEthClientNotifications(notificationsByUserID, types.EthClientUpdateEventName)
 	if err != nil {
@@ -385,7 +438,23 @@ func collectUserDbNotifications(epoch uint64) (map[uint64]map[types.EventName][]
 	return notificationsByUserID, nil
 }
 
-func queueNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, useDB *sqlx.DB) {
+// notificationDedupeKey returns the key that prevents a row from being queued twice, rows queued without a queue key
+// are never deduplicated
+func notificationDedupeKey(queueKey string, parts ...interface{}) sql.NullString {
+	if queueKey == "" {
+		return sql.NullString{}
+	}
+	key := queueKey
+	for _, part := range parts {
+		key += fmt.Sprintf(":%v", part)
+	}
+	return sql.NullString{String: key, Valid: true}
+}
+
+// queueNotifications queues the notifications on all channels of the users. A non empty queueKey identifies the
+// notifications so that queuing them again, for example after the collecting instance crashed, does not queue any row
+// twice.
+func queueNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, useDB *sqlx.DB, queueKey string) {
 	subByEpoch := map[uint64][]uint64{}
 
 	// prevent multiple events being sent with the same subscription id
@@ -408,21 +477,46 @@ func queueNotifications(notificationsByUserID map[uint64]map[types.EventName][]t
 		}
 	}
 
-	err := queueEmailNotifications(notificationsByUserID, useDB)
+	err := saveNotificationHistory(notificationsByUserID, useDB, queueKey)
+	if err != nil {
+		logger.WithError(err).Error("error saving notification history")
+		metrics.Errors.WithLabelValues("notifications_save_history").Inc()
+	}
+
+	userIDs := make([]uint64, 0, len(notificationsByUserID))
+	for userID := range notificationsByUserID {
+		userIDs = append(userIDs, userID)
+	}
+	prefsByUserID, err := db.GetUserNotificationPreferencesByIds(userIDs)
+	if err != nil {
+		// without the preferences all notifications are delivered immediately
+		logger.WithError(err).Error("error getting notification preferences")
+	}
+
+	err = queueEmailNotifications(holdNotifications(types.EmailNotificationChannel, notificationsByUserID, prefsByUserID, useDB, queueKey), useDB, queueKey)
 	if err != nil {
 		logger.WithError(err).Error("error queuing email notifications")
 	}
 
-	err = queuePushNotification(notificationsByUserID, useDB)
+	err = queuePushNotification(holdNotifications(types.PushNotificationChannel, notificationsByUserID, prefsByUserID, useDB, queueKey), useDB, queueKey)
 	if err != nil {
 		logger.WithError(err).Error("error queuing push notifications")
 	}
 
-	err = queueWebhookNotifications(notificationsByUserID, useDB)
+	err = queueWebhookNotifications(notificationsByUserID, useDB, queueKey)
 	if err != nil {
 		logger.WithError(err).Error("error queuing webhook notifications")
 	}
 
+	chatNotificationsByChannel := make(map[types.NotificationChannel]map[uint64]map[types.EventName][]types.Notification, len(types.ChatNotificationChannels))
+	for _, channel := range types.ChatNotificationChannels {
+		chatNotificationsByChannel[channel] = holdNotifications(channel, notificationsByUserID, prefsByUserID, useDB, queueKey)
+	}
+	err = queueChatNotifications(chatNotificationsByChannel, useDB, queueKey)
+	if err != nil {
+		logger.WithError(err).Error("error queuing chat notifications")
+	}
+
 	for _, events := range notificationsByUserID {
 		for _, notifications := range events {
 			for _, n := range notifications {
@@ -497,6 +591,11 @@ func dispatchNotifications(useDB *sqlx.DB) error {
 		return fmt.Errorf("error sending webhook discord notifications, err: %w", err)
 	}
 
+	err = sendChatNotifications(useDB)
+	if err != nil {
+		return fmt.Errorf("error sending chat notifications, err: %w", err)
+	}
+
 	return nil
 }
 
@@ -512,6 +611,15 @@ func garbageCollectNotificationQueue(useDB *sqlx.DB) error {
 
 	logger.Infof("deleted %v rows from the notification_queue", rowsAffected)
 
+	rows, err = useDB.Exec(`DELETE FROM users_webhooks_deliveries WHERE created < now() - INTERVAL '30 days' AND status != $1`, types.WebhookDeliveryPending)
+	if err != nil {
+		return fmt.Errorf("error deleting from users_webhooks_deliveries %w", err)
+	}
+
+	rowsAffected, _ = rows.RowsAffected()
+
+	logger.Infof("deleted %v rows from the users_webhooks_deliveries", rowsAffected)
+
 	return nil
 }
 
@@ -523,7 +631,7 @@ func getNetwork() string {
 	return ""
 }
 
-func queuePushNotification(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, useDB *sqlx.DB) error {
+func queuePushNotification(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, useDB *sqlx.DB, queueKey string) error {
 	userIDs := []uint64{}
 	for userID := range notificationsByUserID {
 		userIDs = append(userIDs, userID)
@@ -535,13 +643,17 @@ func queuePushNotification(notificationsByUserID map[uint64]map[types.EventName]
 		return fmt.Errorf("error when sending push-notifications: could not get tokens: %w", err)
 	}
 
+	// the push notifications have to be queued before the shard they were collected for is completed
+	wg := &sync.WaitGroup{}
 	for userID, userNotifications := range notificationsByUserID {
 		userTokens, exists := tokensByUserID[userID]
 		if !exists {
 			continue
 		}
 
-		go func(userTokens []string, userNotifications map[types.EventName][]types.Notification) {
+		wg.Add(1)
+		go func(userID uint64, userTokens []string, userNotifications map[types.EventName][]types.Notification) {
+			defer wg.Done()
 			var batch []*messaging.Message
 			for event, ns := range userNotifications {
 				for _, n := range ns {
@@ -576,13 +688,15 @@ func queuePushNotification(notificationsByUserID map[uint64]map[types.EventName]
 				Messages: batch,
 			}
 
-			_, err = useDB.Exec(`INSERT INTO notification_queue (created, channel, content) VALUES ($1, 'push', $2)`, time.Now(), transitPushContent)
+			_, err := useDB.Exec(`INSERT INTO notification_queue (created, channel, content, dedupe_key) VALUES ($1, 'push', $2, $3) ON CONFLICT (dedupe_key) DO NOTHING`,
+				time.Now(), transitPushContent, notificationDedupeKey(queueKey, types.PushNotificationChannel, userID))
 			if err != nil {
 				logger.WithError(err).Errorf("error writing transit push notification to db")
 				return
 			}
-		}(userTokens, userNotifications)
+		}(userID, userTokens, userNotifications)
 	}
+	wg.Wait()
 	return nil
 }
 
@@ -628,7 +742,7 @@ func sendPushNotifications(useDB *sqlx.DB) error {
 	return nil
 }
 
-func queueEmailNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, useDB *sqlx.DB) error {
+func queueEmailNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, useDB *sqlx.DB, queueKey string) error {
 	userIDs := []uint64{}
 	for userID := range notificationsByUserID {
 		userIDs = append(userIDs, userID)
@@ -639,6 +753,7 @@ func queueEmailNotifications(notificationsByUserID map[uint64]map[types.EventNam
 		return fmt.Errorf("error when sending email-notifications: could not get emails: %w", err)
 	}
 
+	wg := &sync.WaitGroup{}
 	for userID, userNotifications := range notificationsByUserID {
 		userEmail, exists := emailsByUserID[userID]
 		if !exists {
@@ -647,7 +762,9 @@ func queueEmailNotifications(notificationsByUserID map[uint64]map[types.EventNam
 			// metrics.Errors.WithLabelValues("notifications_mail_not_found").Inc()
 			continue
 		}
-		go func(userEmail string, userNotifications map[types.EventName][]types.Notification) {
+		wg.Add(1)
+		go func(userID uint64, userEmail string, userNotifications map[types.EventName][]types.Notification) {
+			defer wg.Done()
 			attachments := []types.EmailAttachment{}
 
 			var msg types.Email
@@ -765,12 +882,14 @@ func queueEmailNotifications(notificationsByUserID map[uint64]map[types.EventNam
 				Attachments: attachments,
 			}
 
-			_, err = useDB.Exec(`INSERT INTO notification_queue (created, channel, content) VALUES ($1, 'email', $2)`, time.Now(), transitEmailContent)
+			_, err := useDB.Exec(`INSERT INTO notification_queue (created, channel, content, dedupe_key) VALUES ($1, 'email', $2, $3) ON CONFLICT (dedupe_key) DO NOTHING`,
+				time.Now(), transitEmailContent, notificationDedupeKey(queueKey, types.EmailNotificationChannel, userID))
 			if err != nil {
 				logger.WithError(err).Errorf("error writing transit email to db")
 			}
-		}(userEmail, userNotifications)
+		}(userID, userEmail, userNotifications)
 	}
+	wg.Wait()
 	return nil
 }
 
@@ -808,7 +927,7 @@ func sendEmailNotifications(useDb *sqlx.DB) error {
 	return nil
 }
 
-func queueWebhookNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, useDB *sqlx.DB) error {
+func queueWebhookNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, useDB *sqlx.DB, queueKey string) error {
 	for userID, userNotifications := range notificationsByUserID {
 		var webhooks []types.UserWebhook
 		err := useDB.Select(&webhooks, `
@@ -833,7 +952,7 @@ func queueWebhookNotifications(notificationsByUserID map[uint64]map[types.EventN
 		}
 		// webhook => [] notifications
 		discordNotifMap := make(map[uint64][]types.TransitDiscordContent)
-		notifs := make([]types.TransitWebhook, 0)
+		deliveries := make([]types.UserWebhookDelivery, 0)
 		// send the notifications to each registered webhook
 		for _, w := range webhooks {
 			for event, notifications := range userNotifications {
@@ -846,6 +965,9 @@ func queueWebhookNotifications(notificationsByUserID map[uint64]map[types.EventN
 					}
 				}
 				if eventSubscribed {
+					isDiscord := w.Destination.Valid && w.Destination.String == "webhook_discord"
+					// webhooks with too many failed deliveries are paused, their events are logged as failed deliveries so they can be replayed
+					paused := false
 					if len(notifications) > 0 {
 						// reset Retries
 						if w.Retries > 5 && w.LastSent.Valid && w.LastSent.Time.Add(time.Hour).Before(time.Now()) {
@@ -856,17 +978,19 @@ func queueWebhookNotifications(notificationsByUserID map[uint64]map[types.EventN
 							}
 						} else if w.Retries > 5 && !w.LastSent.Valid {
 							logger.Warnf("webhook '%v' has more than 5 retries and does not have a valid last_sent timestamp", w.Url)
-							continue
+							paused = true
 						}
 
 						if w.Retries >= 5 {
-							// early return
-							continue
+							paused = true
 						}
 					}
+					if paused && isDiscord {
+						continue
+					}
 
 					for _, n := range notifications {
-						if w.Destination.Valid && w.Destination.String == "webhook_discord" {
+						if isDiscord {
 							if _, exists := discordNotifMap[w.ID]; !exists {
 								discordNotifMap[w.ID] = make([]types.TransitDiscordContent, 0)
 							}
@@ -905,139 +1029,243 @@ func queueWebhookNotifications(notificationsByUserID map[uint64]map[types.EventN
 								Fields:      fields,
 							})
 						} else {
-							notifs = append(notifs, types.TransitWebhook{
-								Channel: w.Destination.String,
+							event := types.WebhookEvent{
+								ID:          webhookEventID(userID, n),
+								Network:     utils.GetNetwork(),
+								Name:        string(n.GetEventName()),
+								Title:       n.GetTitle(),
+								Description: n.GetInfo(false),
+								Epoch:       n.GetEpoch(),
+								Target:      n.GetEventFilter(),
+							}
+							delivery := types.UserWebhookDelivery{
+								UserID:    userID,
+								WebhookID: w.ID,
+								EventID:   event.ID,
+								EventName: event.Name,
+								Status:    types.WebhookDeliveryPending,
 								Content: types.TransitWebhookContent{
 									Webhook: w,
-									Event: types.WebhookEvent{
-										Network:     utils.GetNetwork(),
-										Name:        string(n.GetEventName()),
-										Title:       n.GetTitle(),
-										Description: n.GetInfo(false),
-										Epoch:       n.GetEpoch(),
-										Target:      n.GetEventFilter(),
-									},
+									Event:   event,
 								},
-							})
+							}
+							if paused {
+								errMsg := fmt.Sprintf("not sent, the webhook is paused after %v failed deliveries", w.Retries)
+								delivery.Status = types.WebhookDeliveryFailed
+								delivery.Error = &errMsg
+							}
+							deliveries = append(deliveries, delivery)
 						}
 					}
 				}
 			}
 		}
-		// process notifs
-		for _, n := range notifs {
-			_, err = useDB.Exec(`INSERT INTO notification_queue (created, channel, content) VALUES (now(), $1, $2);`, n.Channel, n.Content)
+		// process deliveries, an event that has already been queued for a webhook is not queued again
+		for _, d := range deliveries {
+			_, err = useDB.Exec(`
+				INSERT INTO users_webhooks_deliveries (user_id, webhook_id, event_id, event_name, content, status, error)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)
+				ON CONFLICT (webhook_id, event_id) DO NOTHING`,
+				d.UserID, d.WebhookID, d.EventID, d.EventName, d.Content, d.Status, d.Error)
 			if err != nil {
-				logger.WithError(err).Errorf("error inserting into webhooks_queue")
+				logger.WithError(err).Errorf("error inserting into users_webhooks_deliveries")
 			} else {
-				metrics.NotificationsQueued.WithLabelValues(n.Channel, n.Content.Event.Name).Inc()
+				metrics.NotificationsQueued.WithLabelValues("webhook", d.EventName).Inc()
 			}
 		}
 		// process discord notifs
-		for _, dNotifs := range discordNotifMap {
-			for _, n := range dNotifs {
-				_, err = useDB.Exec(`INSERT INTO notification_queue (created, channel, content) VALUES (now(), 'webhook_discord', $1);`, n)
-				if err != nil {
-					logger.WithError(err).Errorf("error inserting into webhooks_queue (discord)")
-					continue
-				} else {
-					metrics.NotificationsQueued.WithLabelValues("webhook_discord", "multi").Inc()
-				}
+		for webhookID, dNotifs := range discordNotifMap {
+			err = queueDiscordNotifications(webhookID, dNotifs, useDB, queueKey)
+			if err != nil {
+				logger.WithError(err).Errorf("error inserting into webhooks_queue (discord)")
 			}
 		}
 	}
 	return nil
 }
 
-func sendWebhookNotifications(useDB *sqlx.DB) error {
-	var notificationQueueItem []types.TransitWebhook
+// queueDiscordNotifications queues the batches of a discord webhook in one transaction. The batches are keyed by their
+// position, so none of them are queued if the first batch has already been queued.
+func queueDiscordNotifications(webhookID uint64, dNotifs []types.TransitDiscordContent, useDB *sqlx.DB, queueKey string) error {
+	tx, err := useDB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	err := useDB.Select(&notificationQueueItem, `SELECT
-		id,
-		created,
-		sent,
-		channel,
-		content
-	FROM notification_queue WHERE sent IS null AND channel = 'webhook' ORDER BY created ASC`)
+	for i, n := range dNotifs {
+		res, err := tx.Exec(`INSERT INTO notification_queue (created, channel, content, dedupe_key) VALUES (now(), 'webhook_discord', $1, $2) ON CONFLICT (dedupe_key) DO NOTHING`,
+			n, notificationDedupeKey(queueKey, "webhook_discord", webhookID, i))
+		if err != nil {
+			return err
+		}
+		if rowsAffected, _ := res.RowsAffected(); i == 0 && rowsAffected == 0 {
+			return nil
+		}
+	}
+	err = tx.Commit()
 	if err != nil {
-		return fmt.Errorf("error querying notification queue, err: %w", err)
+		return fmt.Errorf("error committing transaction: %w", err)
 	}
-	client := &http.Client{Timeout: time.Second * 30}
+	metrics.NotificationsQueued.WithLabelValues("webhook_discord", "multi").Add(float64(len(dNotifs)))
+	return nil
+}
 
-	logger.Infof("processing %v webhook notifications", len(notificationQueueItem))
+// webhookEventID returns a stable id of a notification that receivers can use to deduplicate deliveries. It is derived
+// from the identifying fields of the notification only, so rewording a notification text does not change it. A
+// subscription is notified at most once per event and epoch, see queueNotifications.
+func webhookEventID(userID uint64, n types.Notification) string {
+	h := sha256.Sum256([]byte(fmt.Sprintf("%s:%s:%d:%d:%d", utils.GetNetwork(), n.GetEventName(), n.GetSubscriptionID(), n.GetEpoch(), userID)))
+	return hex.EncodeToString(h[:])
+}
 
-	for _, n := range notificationQueueItem {
-		// do not retry after 5 attempts
-		if n.Content.Webhook.Retries > 5 {
-			_, err := db.FrontendWriterDB.Exec(`DELETE FROM notification_queue WHERE id = $1`, n.Id)
-			if err != nil {
-				return fmt.Errorf("error deleting from notification queue: %w", err)
-			}
-			continue
-		}
+const (
+	webhookDeliveryMaxAttempts  = 10
+	webhookDeliveryBatchSize    = 200
+	webhookDeliveryConcurrency  = 20
+	webhookDeliveryClaimTimeout = 5 * time.Minute
+	webhookResponseBodyLimit    = 4096
+)
 
-		reqBody := new(bytes.Buffer)
+// webhookRetryBackoff returns the delay before the next attempt of a delivery,
+// it starts at 30 seconds and doubles with every failed attempt up to 6 hours
+func webhookRetryBackoff(attempts uint64) time.Duration {
+	backoff := 30 * time.Second
+	for i := uint64(1); i < attempts && backoff < 6*time.Hour; i++ {
+		backoff *= 2
+	}
+	if backoff > 6*time.Hour {
+		backoff = 6 * time.Hour
+	}
+	return backoff
+}
 
-		err := json.NewEncoder(reqBody).Encode(n.Content)
-		if err != nil {
-			logger.WithError(err).Errorf("error marschalling webhook event")
-		}
+// webhookDelivery is a pending delivery of the delivery log that has been claimed for sending
+type webhookDelivery struct {
+	ID        uint64                      `db:"id"`
+	WebhookID uint64                      `db:"webhook_id"`
+	EventID   string                      `db:"event_id"`
+	Attempts  uint64                      `db:"attempts"`
+	Content   types.TransitWebhookContent `db:"content"`
+	Url       string                      `db:"url"`
+	Secret    sql.NullString              `db:"secret"`
+}
 
-		_, err = url.Parse(n.Content.Webhook.Url)
-		if err != nil {
-			_, err := db.FrontendWriterDB.Exec(`DELETE FROM notification_queue WHERE id = $1`, n.Id)
-			if err != nil {
-				return fmt.Errorf("error deleting from notification queue: %w", err)
-			}
-			continue
-		}
+func sendWebhookNotifications(useDB *sqlx.DB) error {
+	var deliveries []webhookDelivery
+
+	// claim the due deliveries by moving their next attempt into the future,
+	// deliveries that are not updated after sending (e.g. because the process crashed) are picked up again once the claim expired
+	err := useDB.Select(&deliveries, `
+		UPDATE users_webhooks_deliveries d
+		SET next_attempt = now() + $2 * INTERVAL '1 second'
+		FROM users_webhooks w
+		WHERE d.id IN (
+			SELECT id FROM users_webhooks_deliveries
+			WHERE status = $1 AND next_attempt <= now()
+			ORDER BY next_attempt ASC
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		) AND w.user_id = d.user_id AND w.id = d.webhook_id
+		RETURNING d.id, d.webhook_id, d.event_id, d.attempts, d.content, w.url, w.secret`,
+		types.WebhookDeliveryPending, webhookDeliveryClaimTimeout.Seconds(), webhookDeliveryBatchSize)
+	if err != nil {
+		return fmt.Errorf("error claiming webhook deliveries, err: %w", err)
+	}
+	client := &http.Client{Timeout: time.Second * 10}
 
-		go func(n types.TransitWebhook) {
-			if n.Content.Webhook.Retries > 0 {
-				time.Sleep(time.Duration(n.Content.Webhook.Retries) * time.Second)
-			}
-			resp, err := client.Post(n.Content.Webhook.Url, "application/json", reqBody)
-			if err != nil {
-				logger.WithError(err).Errorf("error sending request")
-			} else {
-				metrics.NotificationsSent.WithLabelValues("webhook", resp.Status).Inc()
-			}
+	logger.Infof("processing %v webhook notifications", len(deliveries))
 
-			_, err = useDB.Exec(`UPDATE notification_queue SET sent = now() WHERE id = $1`, n.Id)
-			if err != nil {
-				logger.WithError(err).Errorf("error updating notification_queue table")
-				return
-			}
+	g := &errgroup.Group{}
+	g.SetLimit(webhookDeliveryConcurrency)
+	for _, d := range deliveries {
+		d := d
+		g.Go(func() error {
+			sendWebhookDelivery(useDB, client, d)
+			return nil
+		})
+	}
+	return g.Wait()
+}
 
-			if resp != nil && resp.StatusCode < 400 {
-				_, err = useDB.Exec(`UPDATE users_webhooks SET retries = 0, last_sent = now() WHERE id = $1;`, n.Content.Webhook.ID)
-				if err != nil {
-					logger.WithError(err).Errorf("error updating users_webhooks table; setting retries to zero")
-					return
-				}
-			} else {
-				var errResp types.ErrorResponse
+// sendWebhookDelivery posts the signed payload of a delivery and records the outcome in the delivery log
+func sendWebhookDelivery(useDB *sqlx.DB, client *http.Client, d webhookDelivery) {
+	payload, err := json.Marshal(d.Content)
+	if err != nil {
+		logger.WithError(err).Errorf("error marshalling webhook event of delivery %v", d.ID)
+		return
+	}
 
-				if resp != nil {
-					b, err := io.ReadAll(resp.Body)
-					if err != nil {
-						logger.WithError(err).Error("error reading body")
-					}
+	var responseStatus sql.NullInt64
+	var responseBody sql.NullString
 
-					errResp.Status = resp.Status
-					errResp.Body = string(b)
-				}
+	req, err := http.NewRequest(http.MethodPost, d.Url, bytes.NewReader(payload))
+	if err == nil {
+		timestamp := time.Now().Unix()
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Webhook-Event-Id", d.EventID)
+		req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+		if d.Secret.Valid && d.Secret.String != "" {
+			req.Header.Set("X-Webhook-Signature", "sha256="+utils.SignWebhookPayload(d.Secret.String, timestamp, payload))
+		}
 
-				_, err = useDB.Exec(`UPDATE users_webhooks SET retries = retries + 1, last_sent = now(), request = $2, response = $3 WHERE id = $1;`, n.Content.Webhook.ID, n.Content, errResp)
-				if err != nil {
-					logger.WithError(err).Errorf("error updating users_webhooks table; increasing retries")
-					return
-				}
+		var resp *http.Response
+		resp, err = client.Do(req)
+		if err == nil {
+			defer resp.Body.Close()
+			metrics.NotificationsSent.WithLabelValues("webhook", resp.Status).Inc()
+
+			b, readErr := io.ReadAll(io.LimitReader(resp.Body, webhookResponseBodyLimit))
+			if readErr != nil {
+				logger.WithError(readErr).Warnf("error reading response body of webhook delivery %v", d.ID)
 			}
-		}(n)
+			responseStatus = sql.NullInt64{Int64: int64(resp.StatusCode), Valid: true}
+			responseBody = sql.NullString{String: strings.ToValidUTF8(strings.ReplaceAll(string(b), "\x00", ""), ""), Valid: true}
+			if resp.StatusCode >= 400 {
+				err = fmt.Errorf("unexpected response status %v", resp.Status)
+			}
+		}
+	}
+	attempts := d.Attempts + 1
 
+	if err == nil {
+		_, err = useDB.Exec(`
+			UPDATE users_webhooks_deliveries
+			SET status = $2, attempts = $3, last_attempt = now(), response_status = $4, response_body = $5, error = NULL
+			WHERE id = $1`, d.ID, types.WebhookDeliveryDelivered, attempts, responseStatus, responseBody)
+		if err != nil {
+			logger.WithError(err).Errorf("error updating users_webhooks_deliveries table; marking delivery %v as delivered", d.ID)
+			return
+		}
+		_, err = useDB.Exec(`UPDATE users_webhooks SET retries = 0, last_sent = now() WHERE id = $1;`, d.WebhookID)
+		if err != nil {
+			logger.WithError(err).Errorf("error updating users_webhooks table; setting retries to zero")
+		}
+		return
+	}
+
+	logger.WithError(err).Warnf("error sending webhook delivery %v (attempt %v)", d.ID, attempts)
+
+	status := types.WebhookDeliveryPending
+	if attempts >= webhookDeliveryMaxAttempts {
+		status = types.WebhookDeliveryFailed
+	}
+	_, updateErr := useDB.Exec(`
+		UPDATE users_webhooks_deliveries
+		SET status = $2, attempts = $3, last_attempt = now(), next_attempt = $4, response_status = $5, response_body = $6, error = $7
+		WHERE id = $1`, d.ID, status, attempts, time.Now().Add(webhookRetryBackoff(attempts)), responseStatus, responseBody, err.Error())
+	if updateErr != nil {
+		logger.WithError(updateErr).Errorf("error updating users_webhooks_deliveries table; recording failed attempt of delivery %v", d.ID)
+		return
+	}
+
+	if status == types.WebhookDeliveryFailed {
+		errResp := types.ErrorResponse{Status: err.Error(), Body: responseBody.String}
+		_, updateErr = useDB.Exec(`UPDATE users_webhooks SET retries = retries + 1, last_sent = now(), request = $2, response = $3 WHERE id = $1;`, d.WebhookID, d.Content, errResp)
+		if updateErr != nil {
+			logger.WithError(updateErr).Errorf("error updating users_webhooks table; increasing retries")
+		}
 	}
-	return nil
 }
 
 func sendDiscordNotifications(useDB *sqlx.DB) error {
@@ -1156,11 +1384,109 @@ func sendDiscordNotifications(useDB *sqlx.DB) error {
 	return nil
 }
 
+// queueChatNotifications queues the notifications of a user for each of the telegram, slack and matrix chats of the user,
+// the notifications are batched into as few messages as the limits of the channel allow
+func queueChatNotifications(notificationsByChannel map[types.NotificationChannel]map[uint64]map[types.EventName][]types.Notification, useDB *sqlx.DB, queueKey string) error {
+	userIDs := []uint64{}
+	seen := map[uint64]bool{}
+	for _, notificationsByUserID := range notificationsByChannel {
+		for userID := range notificationsByUserID {
+			if !seen[userID] {
+				seen[userID] = true
+				userIDs = append(userIDs, userID)
+			}
+		}
+	}
+	chatsByUserID, err := db.GetUserChatChannelsByIds(userIDs)
+	if err != nil {
+		return fmt.Errorf("error getting chat channels of users: %w", err)
+	}
+
+	for userID, chats := range chatsByUserID {
+		for _, chat := range chats {
+			userNotifications := notificationsByChannel[chat.Channel][userID]
+			messages := []types.ChatMessage{}
+			for event, ns := range userNotifications {
+				for _, n := range ns {
+					info := n.GetInfoMarkdown()
+					if info == "" {
+						continue
+					}
+					messages = append(messages, notify.FormatChatMessage(chat.Channel, getNetwork()+n.GetTitle(), info))
+					metrics.NotificationsQueued.WithLabelValues(string(chat.Channel), string(event)).Inc()
+				}
+			}
+			if len(messages) == 0 {
+				continue
+			}
+
+			content := types.TransitChatContent{
+				Chat:     chat,
+				Messages: notify.BatchChatMessages(chat.Channel, messages),
+			}
+			_, err = useDB.Exec(`INSERT INTO notification_queue (created, channel, content, dedupe_key) VALUES (now(), $1, $2, $3) ON CONFLICT (dedupe_key) DO NOTHING`,
+				chat.Channel, content, notificationDedupeKey(queueKey, chat.Channel, userID))
+			if err != nil {
+				logger.WithError(err).Errorf("error writing transit %v notification to db", chat.Channel)
+			}
+		}
+	}
+	return nil
+}
+
+func sendChatNotifications(useDB *sqlx.DB) error {
+	var notificationQueueItem []types.TransitChat
+
+	channels := make([]string, 0, len(types.ChatNotificationChannels))
+	for _, ch := range types.ChatNotificationChannels {
+		channels = append(channels, string(ch))
+	}
+	err := useDB.Select(&notificationQueueItem, `SELECT
+		id,
+		created,
+		sent,
+		channel,
+		content
+	FROM notification_queue WHERE sent IS null AND channel::text = ANY($1) ORDER BY created ASC`, pq.StringArray(channels))
+	if err != nil {
+		return fmt.Errorf("error querying notification queue, err: %w", err)
+	}
+
+	logger.Infof("processing %v chat notifications", len(notificationQueueItem))
+
+	for _, n := range notificationQueueItem {
+		for i, m := range n.Content.Messages {
+			txnID := fmt.Sprintf("%d-%d-%d", n.Created.Time.Unix(), n.Id, i)
+			err = notify.SendChatMessage(n.Content.Chat, m, txnID)
+
+			// wait and try once more if the chat service asks us to slow down
+			var rateLimitErr *notify.ChatRateLimitError
+			if errors.As(err, &rateLimitErr) && rateLimitErr.RetryAfter <= time.Second*30 {
+				time.Sleep(rateLimitErr.RetryAfter)
+				err = notify.SendChatMessage(n.Content.Chat, m, txnID)
+			}
+
+			if err != nil {
+				metrics.Errors.WithLabelValues("notifications_send_" + n.Channel).Inc()
+				logger.WithError(err).Warnf("error sending %v notification of user %v", n.Channel, n.Content.Chat.UserID)
+			} else {
+				metrics.NotificationsSent.WithLabelValues(n.Channel, "200").Inc()
+			}
+		}
+
+		_, err = useDB.Exec(`UPDATE notification_queue SET sent = now() WHERE id = $1`, n.Id)
+		if err != nil {
+			return fmt.Errorf("error updating sent status for %v notification with id: %v, err: %w", n.Channel, n.Id, err)
+		}
+	}
+	return nil
+}
+
 func getUrlPart(validatorIndex uint64) string {
 	return fmt.Sprintf(` For more information visit: <a href='https://%s/validator/%v'>https://%s/validator/%v</a>.`, utils.Config.Frontend.SiteDomain, validatorIndex, utils.Config.Frontend.SiteDomain, validatorIndex)
 }
 
-func collectBlockProposalNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, status uint64, eventName types.EventName, epoch uint64) error {
+func collectBlockProposalNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, status uint64, eventName types.EventName, epoch uint64, shard types.NotificationShard) error {
 	type dbResult struct {
 		Proposer      uint64 `db:"proposer"`
 		Status        uint64 `db:"status"`
@@ -1169,7 +1495,7 @@ func collectBlockProposalNotifications(notificationsByUserID map[uint64]map[type
 		ExecRewardETH float64
 	}
 
-	_, subMap, err := db.GetSubsForEventFilter(eventName)
+	_, subMap, err := db.GetSubsForEventFilter(eventName, shard)
 	if err != nil {
 		return fmt.Errorf("error getting subscriptions for (missed) block proposals %w", err)
 	}
@@ -1265,22 +1591,141 @@ func collectBlockProposalNotifications(notificationsByUserID map[uint64]map[type
 	return nil
 }
 
-type validatorProposalNotification struct {
-	SubscriptionID     uint64
-	ValidatorIndex     uint64
-	ValidatorPublicKey string
-	Epoch              uint64
-	Slot               uint64
-	Status             uint64 // * Can be 0 = scheduled, 1 executed, 2 missed */
-	EventName          types.EventName
-	EventFilter        string
-	Reward             float64
-	UnsubscribeHash    sql.NullString
-}
+// upcomingProposalNotifier queues the upcoming block proposal notifications once per new head epoch. It runs next to
+// the finalized epoch collection, so the proposal warnings are also sent while the chain does not finalize. Every
+// collector instance runs it for all users, the notifications of a proposal are queued with a dedupe key derived from
+// its slot, so each of them is only queued once.
+func upcomingProposalNotifier() {
+	var lastCollectedEpoch uint64
+	for {
+		head, err := rpc.CurrentClient.GetChainHead()
+		if err != nil {
+			utils.LogError(err, "error getting chain head for upcoming block proposal notifications", 0)
+			time.Sleep(time.Second * time.Duration(utils.Config.Chain.ClConfig.SecondsPerSlot))
+			continue
+		}
 
-func (n *validatorProposalNotification) GetLatestState() string {
-	return ""
-}
+		// the epochs between the last collected one and the head are caught up on if the head jumped several epochs
+		for epoch := max(head.HeadEpoch, lastCollectedEpoch+1); epoch <= head.HeadEpoch+1; epoch++ {
+			start := time.Now()
+			err := queueUpcomingProposalNotifications(epoch)
+			if err != nil {
+				metrics.Errors.WithLabelValues("notifications_collect_upcoming_block_proposal").Inc()
+				utils.LogError(err, "error collecting validator_proposal_upcoming notifications", 0, map[string]interface{}{"epoch": epoch})
+				break
+			}
+			lastCollectedEpoch = epoch
+			logger.Infof("collecting upcoming block proposal notifications of epoch %v took: %v", epoch, time.Since(start))
+		}
+
+		time.Sleep(time.Second * time.Duration(utils.Config.Chain.ClConfig.SecondsPerSlot))
+	}
+}
+
+// queueUpcomingProposalNotifications queues the notifications of the proposals of the epoch, one batch per proposal slot
+func queueUpcomingProposalNotifications(epoch uint64) error {
+	notificationsBySlot, err := collectUpcomingProposalNotifications(epoch)
+	if err != nil {
+		return err
+	}
+	for slot, notificationsByUserID := range notificationsBySlot {
+		queueNotifications(notificationsByUserID, db.FrontendWriterDB, fmt.Sprintf("%s:upcoming_proposal:%d", utils.GetNetwork(), slot))
+	}
+	return nil
+}
+
+// collectUpcomingProposalNotifications collects notifications for watched validators that are assigned to propose a block
+// in the epoch, mapped by the slot of the proposal. The proposer duties of an epoch are known one epoch in advance, so
+// the notifications of the epoch after the head epoch are sent roughly 6 to 13 minutes before the proposal.
+func collectUpcomingProposalNotifications(epoch uint64) (map[uint64]map[uint64]map[types.EventName][]types.Notification, error) {
+	_, subMap, err := db.GetSubsForEventFilter(types.ValidatorUpcomingProposalEventName, types.NotificationShard{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting subscriptions for upcoming block proposals %w", err)
+	}
+	if len(subMap) == 0 {
+		return nil, nil
+	}
+
+	assignments, err := rpc.CurrentClient.GetEpochAssignments(epoch)
+	if err != nil {
+		return nil, fmt.Errorf("error getting proposer duties of epoch %v: %w", epoch, err)
+	}
+
+	notificationsBySlot := map[uint64]map[uint64]map[types.EventName][]types.Notification{}
+	for slot, proposer := range assignments.ProposerAssignments {
+		if utils.SlotToTime(slot).Before(time.Now()) {
+			continue
+		}
+		pubkey, err := GetPubkeyForIndex(proposer)
+		if err != nil {
+			utils.LogError(err, "error retrieving pubkey for validator", 0, map[string]interface{}{"validator": proposer})
+			continue
+		}
+		subscribers, ok := subMap[hex.EncodeToString(pubkey)]
+		if !ok {
+			continue
+		}
+		for _, sub := range subscribers {
+			if sub.UserID == nil || sub.ID == nil {
+				return nil, fmt.Errorf("error expected userId and subId to be defined but got user: %v, sub: %v", sub.UserID, sub.ID)
+			}
+			// the epoch of the duty is stored as the last sent epoch, so a duty is only notified once
+			if sub.LastEpoch != nil && *sub.LastEpoch >= epoch {
+				continue
+			}
+			logger.Infof("creating %v notification for validator %v in slot %v", types.ValidatorUpcomingProposalEventName, proposer, slot)
+			n := &validatorProposalNotification{
+				SubscriptionID:  *sub.ID,
+				ValidatorIndex:  proposer,
+				Epoch:           epoch,
+				Status:          0,
+				EventName:       types.ValidatorUpcomingProposalEventName,
+				EventFilter:     hex.EncodeToString(pubkey),
+				Slot:            slot,
+				UnsubscribeHash: sub.UnsubscribeHash,
+			}
+			if _, exists := notificationsBySlot[slot]; !exists {
+				notificationsBySlot[slot] = map[uint64]map[types.EventName][]types.Notification{}
+			}
+			if _, exists := notificationsBySlot[slot][*sub.UserID]; !exists {
+				notificationsBySlot[slot][*sub.UserID] = map[types.EventName][]types.Notification{}
+			}
+			notificationsBySlot[slot][*sub.UserID][n.GetEventName()] = append(notificationsBySlot[slot][*sub.UserID][n.GetEventName()], n)
+			metrics.NotificationsCollected.WithLabelValues(string(n.GetEventName())).Inc()
+		}
+	}
+
+	return notificationsBySlot, nil
+}
+
+// proposalEta returns the time until the proposal at the given slot in minutes
+func proposalEta(slot uint64) string {
+	minutes := int64(math.Round(time.Until(utils.SlotToTime(slot)).Minutes()))
+	if minutes <= 0 {
+		return "now"
+	}
+	if minutes == 1 {
+		return "in ~1 minute"
+	}
+	return fmt.Sprintf("in ~%v minutes", minutes)
+}
+
+type validatorProposalNotification struct {
+	SubscriptionID     uint64
+	ValidatorIndex     uint64
+	ValidatorPublicKey string
+	Epoch              uint64
+	Slot               uint64
+	Status             uint64 // * Can be 0 = scheduled, 1 executed, 2 missed */
+	EventName          types.EventName
+	EventFilter        string
+	Reward             float64
+	UnsubscribeHash    sql.NullString
+}
+
+func (n *validatorProposalNotification) GetLatestState() string {
+	return ""
+}
 
 func (n *validatorProposalNotification) GetUnsubscribeHash() string {
 	if n.UnsubscribeHash.Valid {
@@ -1316,7 +1761,7 @@ func (n *validatorProposalNotification) GetInfo(includeUrl bool) string {
 	}
 	switch n.Status {
 	case 0:
-		generalPart = fmt.Sprintf(`New scheduled block proposal at slot %s for Validator %s.`, slot, vali)
+		generalPart = fmt.Sprintf(`Validator %s will propose a block at slot %s in epoch %v (%s).`, vali, slot, utils.EpochOfSlot(n.Slot), proposalEta(n.Slot))
 	case 1:
 		generalPart = fmt.Sprintf(`Validator %s proposed block at slot %s with %v %v execution reward.`, vali, slot, n.Reward, utils.Config.Frontend.ElCurrency)
 	case 2:
@@ -1330,7 +1775,7 @@ func (n *validatorProposalNotification) GetInfo(includeUrl bool) string {
 func (n *validatorProposalNotification) GetTitle() string {
 	switch n.Status {
 	case 0:
-		return "Block Proposal Scheduled"
+		return "Upcoming Block Proposal"
 	case 1:
 		return "New Block Proposal"
 	case 2:
@@ -1349,7 +1794,7 @@ func (n *validatorProposalNotification) GetInfoMarkdown() string {
 	var generalPart = ""
 	switch n.Status {
 	case 0:
-		generalPart = fmt.Sprintf(`New scheduled block proposal at slot [%[3]v](https://%[1]v/slot/%[3]v) for Validator [%[2]v](https://%[1]v/validator/%[2]v).`, utils.Config.Frontend.SiteDomain, n.ValidatorIndex, n.Slot)
+		generalPart = fmt.Sprintf(`Validator [%[2]v](https://%[1]v/validator/%[2]v) will propose a block at slot [%[3]v](https://%[1]v/slot/%[3]v) in epoch [%[4]v](https://%[1]v/epoch/%[4]v) (%[5]v).`, utils.Config.Frontend.SiteDomain, n.ValidatorIndex, n.Slot, utils.EpochOfSlot(n.Slot), proposalEta(n.Slot))
 	case 1:
 		generalPart = fmt.Sprintf(`Validator [%[2]v](https://%[1]v/validator/%[2]v) proposed a new block at slot [%[3]v](https://%[1]v/slot/%[3]v) with %[4]v %[5]v execution reward.`, utils.Config.Frontend.SiteDomain, n.ValidatorIndex, n.Slot, n.Reward, utils.Config.Frontend.ElCurrency)
 	case 2:
@@ -1361,8 +1806,9 @@ func (n *validatorProposalNotification) GetInfoMarkdown() string {
 	return generalPart
 }
 
-func collectAttestationAndOfflineValidatorNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, status uint64, epoch uint64) error {
-	_, subMap, err := db.GetSubsForEventFilter(types.ValidatorMissedAttestationEventName)
+func collectAttestationAndOfflineValidatorNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, status uint64, epoch uint64, shard types.NotificationShard) error {
+	// the attestations are split by validator index, so the subscriptions of all users are needed
+	_, subMap, err := db.GetSubsForEventFilter(types.ValidatorMissedAttestationEventName, types.NotificationShard{})
 	if err != nil {
 		return fmt.Errorf("error getting subscriptions for missted attestations %w", err)
 	}
@@ -1381,7 +1827,7 @@ func collectAttestationAndOfflineValidatorNotifications(notificationsByUserID ma
 		return err
 	}
 
-	participationPerEpoch, err := db.GetValidatorAttestationHistoryForNotifications(epoch-3, epoch)
+	participationPerEpoch, err := db.GetValidatorAttestationHistoryForNotifications(epoch-3, epoch, shard)
 	if err != nil {
 		return fmt.Errorf("error getting validator attestations from db %w", err)
 	}
@@ -1505,6 +1951,9 @@ func collectAttestationAndOfflineValidatorNotifications(notificationsByUserID ma
 	}
 
 	for _, validator := range validators {
+		if !shard.ContainsValidator(validator) {
+			continue
+		}
 		if participationPerEpoch[epochNMinus3][types.ValidatorIndex(validator)] && !participationPerEpoch[epochNMinus2][types.ValidatorIndex(validator)] && !participationPerEpoch[epochNMinus1][types.ValidatorIndex(validator)] && !participationPerEpoch[types.Epoch(epoch)][types.ValidatorIndex(validator)] {
 			logger.Infof("validator %v detected as offline in epoch %v (did not attest since epoch %v)", validator, epoch, epochNMinus2)
 			pubkey, err := GetPubkeyForIndex(validator)
@@ -1543,7 +1992,7 @@ func collectAttestationAndOfflineValidatorNotifications(notificationsByUserID ma
 		return fmt.Errorf("retrieved more than %v online validators notifications: %v, exiting", onlineValidatorsLimit, len(onlineValidators))
 	}
 
-	_, subMap, err = db.GetSubsForEventFilter(types.ValidatorIsOfflineEventName)
+	_, subMap, err = db.GetSubsForEventFilter(types.ValidatorIsOfflineEventName, types.NotificationShard{})
 	if err != nil {
 		return fmt.Errorf("failed to get subs for %v: %v", types.ValidatorIsOfflineEventName, err)
 	}
@@ -1868,7 +2317,7 @@ func (n *validatorGotSlashedNotification) GetInfoMarkdown() string {
 	return generalPart
 }
 
-func collectValidatorGotSlashedNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64) error {
+func collectValidatorGotSlashedNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64, shard types.NotificationShard) error {
 	dbResult, err := db.GetValidatorsGotSlashed(epoch)
 	if err != nil {
 		return fmt.Errorf("error getting slashed validators from database, err: %w", err)
@@ -1876,7 +2325,7 @@ func collectValidatorGotSlashedNotifications(notificationsByUserID map[uint64]ma
 	query := ""
 	resultsLen := len(dbResult)
 	for i, event := range dbResult {
-		query += fmt.Sprintf(`SELECT %d AS ref, id, user_id, ENCODE(unsubscribe_hash, 'hex') AS unsubscribe_hash from users_subscriptions where event_name = $1 AND event_filter = '%x'`, i, event.SlashedValidatorPubkey)
+		query += fmt.Sprintf(`SELECT %d AS ref, id, user_id, ENCODE(unsubscribe_hash, 'hex') AS unsubscribe_hash from users_subscriptions where event_name = $1 AND event_filter = '%x' AND ($2 <= 1 OR user_id %% $2 = $3)`, i, event.SlashedValidatorPubkey)
 		if i < resultsLen-1 {
 			query += " UNION "
 		}
@@ -1897,7 +2346,7 @@ func collectValidatorGotSlashedNotifications(notificationsByUserID map[uint64]ma
 	if utils.Config.Chain.ClConfig.ConfigName != "" {
 		name = utils.Config.Chain.ClConfig.ConfigName + ":" + name
 	}
-	err = db.FrontendWriterDB.Select(&subscribers, query, name)
+	err = db.FrontendWriterDB.Select(&subscribers, query, name, shard.Count, shard.Index)
 	if err != nil {
 		return fmt.Errorf("error querying subscribers, err: %w", err)
 	}
@@ -1990,10 +2439,10 @@ func (n *validatorWithdrawalNotification) GetInfoMarkdown() string {
 }
 
 // collectWithdrawalNotifications collects all notifications validator withdrawals
-func collectWithdrawalNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64) error {
+func collectWithdrawalNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64, shard types.NotificationShard) error {
 
 	// get all users that are subscribed to this event (scale: a few thousand rows depending on how many users we have)
-	_, subMap, err := db.GetSubsForEventFilter(types.ValidatorReceivedWithdrawalEventName)
+	_, subMap, err := db.GetSubsForEventFilter(types.ValidatorReceivedWithdrawalEventName, shard)
 	if err != nil {
 		return fmt.Errorf("error getting subscriptions for missed attestations %w", err)
 	}
@@ -2044,6 +2493,476 @@ func collectWithdrawalNotifications(notificationsByUserID map[uint64]map[types.E
 	return nil
 }
 
+// formatRequestValidator returns the index of a validator referenced by an execution layer request or, if it has none yet, its public key
+func formatRequestValidator(index *uint64, pubkey []byte) string {
+	if index == nil {
+		return fmt.Sprintf("%#x", pubkey)
+	}
+	return fmt.Sprintf("%v", *index)
+}
+
+type validatorConsolidatedNotification struct {
+	SubscriptionID  uint64
+	Epoch           uint64
+	Slot            uint64
+	Source          string
+	Target          string
+	IsTarget        bool
+	EventFilter     string
+	UnsubscribeHash sql.NullString
+}
+
+func (n *validatorConsolidatedNotification) GetLatestState() string {
+	return ""
+}
+
+func (n *validatorConsolidatedNotification) GetUnsubscribeHash() string {
+	if n.UnsubscribeHash.Valid {
+		return n.UnsubscribeHash.String
+	}
+	return ""
+}
+
+func (n *validatorConsolidatedNotification) GetEmailAttachment() *types.EmailAttachment {
+	return nil
+}
+
+func (n *validatorConsolidatedNotification) GetSubscriptionID() uint64 {
+	return n.SubscriptionID
+}
+
+func (n *validatorConsolidatedNotification) GetEpoch() uint64 {
+	return n.Epoch
+}
+
+func (n *validatorConsolidatedNotification) GetEventName() types.EventName {
+	return types.ValidatorConsolidatedEventName
+}
+
+func (n *validatorConsolidatedNotification) GetInfo(includeUrl bool) string {
+	generalPart := fmt.Sprintf(`A consolidation of validator %v into validator %v has been requested in slot %v.`, n.Source, n.Target, n.Slot)
+	if n.Source == n.Target {
+		generalPart = fmt.Sprintf(`A switch of validator %v to compounding withdrawal credentials has been requested in slot %v.`, n.Source, n.Slot)
+	}
+	if includeUrl {
+		validator := n.Source
+		if n.IsTarget {
+			validator = n.Target
+		}
+		return generalPart + fmt.Sprintf(` For more information visit: <a href='https://%s/validator/%v'>https://%s/validator/%v</a>.`, utils.Config.Frontend.SiteDomain, validator, utils.Config.Frontend.SiteDomain, validator)
+	}
+	return generalPart
+}
+
+func (n *validatorConsolidatedNotification) GetTitle() string {
+	return "Validator Consolidated"
+}
+
+func (n *validatorConsolidatedNotification) GetEventFilter() string {
+	return n.EventFilter
+}
+
+func (n *validatorConsolidatedNotification) GetInfoMarkdown() string {
+	if n.Source == n.Target {
+		return fmt.Sprintf(`A switch of validator [%[1]v](https://%[3]v/validator/%[1]v) to compounding withdrawal credentials has been requested in slot [%[2]v](https://%[3]v/slot/%[2]v).`, n.Source, n.Slot, utils.Config.Frontend.SiteDomain)
+	}
+	return fmt.Sprintf(`A consolidation of validator [%[1]v](https://%[4]v/validator/%[1]v) into validator [%[2]v](https://%[4]v/validator/%[2]v) has been requested in slot [%[3]v](https://%[4]v/slot/%[3]v).`, n.Source, n.Target, n.Slot, utils.Config.Frontend.SiteDomain)
+}
+
+// collectConsolidationNotifications collects the notifications for consolidation requests with a watched validator as source or target
+func collectConsolidationNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64, shard types.NotificationShard) error {
+	_, subMap, err := db.GetSubsForEventFilter(types.ValidatorConsolidatedEventName, shard)
+	if err != nil {
+		return fmt.Errorf("error getting subscriptions for consolidations %w", err)
+	}
+
+	events, err := db.GetEpochConsolidationRequests(epoch)
+	if err != nil {
+		return fmt.Errorf("error getting consolidation requests from database, err: %w", err)
+	}
+
+	for _, event := range events {
+		pubkeys := [][]byte{event.SourcePubkey}
+		if !bytes.Equal(event.SourcePubkey, event.TargetPubkey) {
+			pubkeys = append(pubkeys, event.TargetPubkey)
+		}
+		for i, pubkey := range pubkeys {
+			for _, sub := range subMap[hex.EncodeToString(pubkey)] {
+				if sub.UserID == nil || sub.ID == nil {
+					return fmt.Errorf("error expected userId and subId to be defined but got user: %v, sub: %v", sub.UserID, sub.ID)
+				}
+				if sub.LastEpoch != nil {
+					lastSentEpoch := *sub.LastEpoch
+					if lastSentEpoch >= epoch || epoch < sub.CreatedEpoch {
+						continue
+					}
+				}
+				n := &validatorConsolidatedNotification{
+					SubscriptionID:  *sub.ID,
+					Epoch:           epoch,
+					Slot:            event.Slot,
+					Source:          formatRequestValidator(event.SourceValidatorIndex, event.SourcePubkey),
+					Target:          formatRequestValidator(event.TargetValidatorIndex, event.TargetPubkey),
+					IsTarget:        i == 1,
+					EventFilter:     hex.EncodeToString(pubkey),
+					UnsubscribeHash: sub.UnsubscribeHash,
+				}
+				if _, exists := notificationsByUserID[*sub.UserID]; !exists {
+					notificationsByUserID[*sub.UserID] = map[types.EventName][]types.Notification{}
+				}
+				notificationsByUserID[*sub.UserID][n.GetEventName()] = append(notificationsByUserID[*sub.UserID][n.GetEventName()], n)
+				metrics.NotificationsCollected.WithLabelValues(string(n.GetEventName())).Inc()
+			}
+		}
+	}
+
+	return nil
+}
+
+type validatorExecutionLayerExitNotification struct {
+	SubscriptionID  uint64
+	Epoch           uint64
+	Slot            uint64
+	Validator       string
+	Address         []byte
+	EventFilter     string
+	UnsubscribeHash sql.NullString
+}
+
+func (n *validatorExecutionLayerExitNotification) GetLatestState() string {
+	return ""
+}
+
+func (n *validatorExecutionLayerExitNotification) GetUnsubscribeHash() string {
+	if n.UnsubscribeHash.Valid {
+		return n.UnsubscribeHash.String
+	}
+	return ""
+}
+
+func (n *validatorExecutionLayerExitNotification) GetEmailAttachment() *types.EmailAttachment {
+	return nil
+}
+
+func (n *validatorExecutionLayerExitNotification) GetSubscriptionID() uint64 {
+	return n.SubscriptionID
+}
+
+func (n *validatorExecutionLayerExitNotification) GetEpoch() uint64 {
+	return n.Epoch
+}
+
+func (n *validatorExecutionLayerExitNotification) GetEventName() types.EventName {
+	return types.ValidatorExecutionLayerExitEventName
+}
+
+func (n *validatorExecutionLayerExitNotification) GetInfo(includeUrl bool) string {
+	generalPart := fmt.Sprintf(`The withdrawal address %#x requested the exit of validator %v in slot %v.`, n.Address, n.Validator, n.Slot)
+	if includeUrl {
+		return generalPart + fmt.Sprintf(` For more information visit: <a href='https://%s/validator/%v'>https://%s/validator/%v</a>.`, utils.Config.Frontend.SiteDomain, n.Validator, utils.Config.Frontend.SiteDomain, n.Validator)
+	}
+	return generalPart
+}
+
+func (n *validatorExecutionLayerExitNotification) GetTitle() string {
+	return "Validator Exit Requested"
+}
+
+func (n *validatorExecutionLayerExitNotification) GetEventFilter() string {
+	return n.EventFilter
+}
+
+func (n *validatorExecutionLayerExitNotification) GetInfoMarkdown() string {
+	return fmt.Sprintf(`The withdrawal address [%[3]v](https://%[5]v/address/0x%[4]x) requested the exit of validator [%[1]v](https://%[5]v/validator/%[1]v) in slot [%[2]v](https://%[5]v/slot/%[2]v).`, n.Validator, n.Slot, utils.FormatHashRaw(n.Address), n.Address, utils.Config.Frontend.SiteDomain)
+}
+
+// collectExecutionLayerExitNotifications collects the notifications for exits of watched validators requested by their withdrawal address
+func collectExecutionLayerExitNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64, shard types.NotificationShard) error {
+	_, subMap, err := db.GetSubsForEventFilter(types.ValidatorExecutionLayerExitEventName, shard)
+	if err != nil {
+		return fmt.Errorf("error getting subscriptions for execution layer exits %w", err)
+	}
+
+	events, err := db.GetEpochExitRequests(epoch)
+	if err != nil {
+		return fmt.Errorf("error getting exit requests from database, err: %w", err)
+	}
+
+	for _, event := range events {
+		for _, sub := range subMap[hex.EncodeToString(event.ValidatorPubkey)] {
+			if sub.UserID == nil || sub.ID == nil {
+				return fmt.Errorf("error expected userId and subId to be defined but got user: %v, sub: %v", sub.UserID, sub.ID)
+			}
+			if sub.LastEpoch != nil {
+				lastSentEpoch := *sub.LastEpoch
+				if lastSentEpoch >= epoch || epoch < sub.CreatedEpoch {
+					continue
+				}
+			}
+			n := &validatorExecutionLayerExitNotification{
+				SubscriptionID:  *sub.ID,
+				Epoch:           epoch,
+				Slot:            event.Slot,
+				Validator:       formatRequestValidator(event.ValidatorIndex, event.ValidatorPubkey),
+				Address:         event.SourceAddress,
+				EventFilter:     hex.EncodeToString(event.ValidatorPubkey),
+				UnsubscribeHash: sub.UnsubscribeHash,
+			}
+			if _, exists := notificationsByUserID[*sub.UserID]; !exists {
+				notificationsByUserID[*sub.UserID] = map[types.EventName][]types.Notification{}
+			}
+			notificationsByUserID[*sub.UserID][n.GetEventName()] = append(notificationsByUserID[*sub.UserID][n.GetEventName()], n)
+			metrics.NotificationsCollected.WithLabelValues(string(n.GetEventName())).Inc()
+		}
+	}
+
+	return nil
+}
+
+const (
+	// incomeAnomalyWindow is the number of epochs the attestation income of a validator is compared to the network over
+	incomeAnomalyWindow = 32
+	// incomeAnomalyInterval is the number of epochs between two checks, the windows of subsequent checks overlap
+	incomeAnomalyInterval = 8
+)
+
+type validatorIncomeAnomalyNotification struct {
+	SubscriptionID  uint64
+	ValidatorIndex  uint64
+	Epoch           uint64
+	StartEpoch      uint64
+	Ratio           float64
+	Threshold       float64
+	EventFilter     string
+	UnsubscribeHash sql.NullString
+}
+
+func (n *validatorIncomeAnomalyNotification) GetLatestState() string {
+	return ""
+}
+
+func (n *validatorIncomeAnomalyNotification) GetSubscriptionID() uint64 {
+	return n.SubscriptionID
+}
+
+func (n *validatorIncomeAnomalyNotification) GetEventName() types.EventName {
+	return types.ValidatorIncomeBelowNetworkEventName
+}
+
+func (n *validatorIncomeAnomalyNotification) GetEpoch() uint64 {
+	return n.Epoch
+}
+
+func (n *validatorIncomeAnomalyNotification) GetInfo(includeUrl bool) string {
+	generalPart := fmt.Sprintf(`Validator %v earned %.0f%% of the network average attestation income per staked ETH in epochs %v - %v, which is below your threshold of %.0f%%. This is usually caused by late or wrong-head attestations.`, n.ValidatorIndex, n.Ratio*100, n.StartEpoch, n.Epoch, n.Threshold*100)
+	if includeUrl {
+		return generalPart + getUrlPart(n.ValidatorIndex)
+	}
+	return generalPart
+}
+
+func (n *validatorIncomeAnomalyNotification) GetTitle() string {
+	return "Income Below Network"
+}
+
+func (n *validatorIncomeAnomalyNotification) GetEventFilter() string {
+	return n.EventFilter
+}
+
+func (n *validatorIncomeAnomalyNotification) GetEmailAttachment() *types.EmailAttachment {
+	return nil
+}
+
+func (n *validatorIncomeAnomalyNotification) GetUnsubscribeHash() string {
+	if n.UnsubscribeHash.Valid {
+		return n.UnsubscribeHash.String
+	}
+	return ""
+}
+
+func (n *validatorIncomeAnomalyNotification) GetInfoMarkdown() string {
+	return fmt.Sprintf(`Validator [%[1]v](https://%[6]v/validator/%[1]v) earned %.0[2]f%% of the network average attestation income per staked ETH in epochs [%[3]v](https://%[6]v/epoch/%[3]v) - [%[4]v](https://%[6]v/epoch/%[4]v), which is below your threshold of %.0[5]f%%. This is usually caused by late or wrong-head attestations.`, n.ValidatorIndex, n.Ratio*100, n.StartEpoch, n.Epoch, n.Threshold*100, utils.Config.Frontend.SiteDomain)
+}
+
+// attestationIncome returns the net attestation income of an epoch in gwei.
+// Proposals and sync committee duties are left out as they are assigned randomly and would make single validators look like outliers.
+func attestationIncome(income *itypes.ValidatorEpochIncome) int64 {
+	if income == nil {
+		return 0
+	}
+	return int64(income.AttestationSourceReward+income.AttestationTargetReward+income.AttestationHeadReward) -
+		int64(income.AttestationSourcePenalty+income.AttestationTargetPenalty)
+}
+
+// attestationIncomeRate returns the attestation income of a validator per gwei of effective balance and epoch over the incomeAnomalyWindow
+func attestationIncomeRate(incomes map[uint64]*itypes.ValidatorEpochIncome, effectiveBalance uint64) float64 {
+	var income float64
+	for _, epochIncome := range incomes {
+		income += float64(attestationIncome(epochIncome))
+	}
+	return income / (float64(effectiveBalance) * incomeAnomalyWindow)
+}
+
+// networkAttestationIncomeRate returns the attestation income of the network per gwei of effective balance and epoch over the
+// window ending at epoch, from the total income of all validators and the effective balance eligible for rewards of each epoch.
+// It returns false if the income or the balance of an epoch of the window has not been exported yet.
+func networkAttestationIncomeRate(startEpoch, epoch uint64) (float64, bool, error) {
+	totals, err := db.BigtableClient.GetTotalValidatorIncomeDetailsHistory(startEpoch, epoch)
+	if err != nil {
+		return 0, false, fmt.Errorf("error getting total validator income of epochs %v - %v: %w", startEpoch, epoch, err)
+	}
+
+	var balances []struct {
+		Epoch         uint64 `db:"epoch"`
+		EligibleEther uint64 `db:"eligibleether"`
+	}
+	err = db.WriterDb.Select(&balances, `SELECT epoch, eligibleether FROM epochs WHERE epoch BETWEEN $1 AND $2 AND eligibleether > 0`, startEpoch, epoch)
+	if err != nil {
+		return 0, false, fmt.Errorf("error getting eligible ether of epochs %v - %v: %w", startEpoch, epoch, err)
+	}
+	eligible := make(map[uint64]uint64, len(balances))
+	for _, b := range balances {
+		eligible[b.Epoch] = b.EligibleEther
+	}
+
+	rate, complete := totalAttestationIncomeRate(totals, eligible, startEpoch, epoch)
+	return rate, complete, nil
+}
+
+// totalAttestationIncomeRate returns the total attestation income per gwei of eligible effective balance and epoch,
+// or false if the income or the eligible balance of an epoch of the window is missing
+func totalAttestationIncomeRate(totals map[uint64]*itypes.ValidatorEpochIncome, eligible map[uint64]uint64, startEpoch, epoch uint64) (float64, bool) {
+	var income, balance float64
+	for e := startEpoch; e <= epoch; e++ {
+		total, ok := totals[e]
+		if !ok || eligible[e] == 0 {
+			return 0, false
+		}
+		income += float64(attestationIncome(total))
+		balance += float64(eligible[e])
+	}
+	return income / balance, true
+}
+
+// incomeAnomalyDue returns the threshold of the subscription and whether the subscription has to be notified of the ratio
+// of the income of its validator to the network income
+func incomeAnomalyDue(sub types.Subscription, ratio float64, epoch uint64) (float64, bool) {
+	threshold := sub.EventThreshold
+	if threshold == 0 {
+		threshold = types.ValidatorIncomeBelowNetworkThresholdDefault
+	}
+	if ratio >= threshold {
+		return threshold, false
+	}
+	if epoch < sub.CreatedEpoch+incomeAnomalyWindow {
+		// the window has to be fully covered by the subscription
+		return threshold, false
+	}
+	if sub.LastEpoch != nil && *sub.LastEpoch+utils.EpochsPerDay() > epoch {
+		// at most once a day per validator
+		return threshold, false
+	}
+	return threshold, true
+}
+
+// collectIncomeAnomalyNotifications collects notifications for watched validators whose attestation income per staked ETH
+// over the last incomeAnomalyWindow epochs is below the threshold of the subscription relative to the network.
+// The network reference is the total attestation income of all validators per staked ETH eligible for rewards during the window.
+func collectIncomeAnomalyNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64, shard types.NotificationShard) error {
+	if epoch < incomeAnomalyWindow || epoch%incomeAnomalyInterval != 0 {
+		return nil
+	}
+	startEpoch := epoch - incomeAnomalyWindow + 1
+
+	pubkeys, subMap, err := db.GetSubsForEventFilter(types.ValidatorIncomeBelowNetworkEventName, shard)
+	if err != nil {
+		return fmt.Errorf("error getting subscriptions for income anomalies %w", err)
+	}
+	if len(pubkeys) == 0 {
+		return nil
+	}
+
+	networkRate, complete, err := networkAttestationIncomeRate(startEpoch, epoch)
+	if err != nil {
+		return err
+	}
+	if !complete {
+		logger.Warnf("skipping income anomaly notifications for epoch %v, the income of epochs %v - %v is not fully exported yet", epoch, startEpoch, epoch)
+		return nil
+	}
+	if networkRate <= 0 {
+		return nil
+	}
+
+	// only validators that have been active with an unchanged status during the whole window are compared
+	type validator struct {
+		Index            uint64 `db:"validatorindex"`
+		Pubkey           []byte `db:"pubkey"`
+		EffectiveBalance uint64 `db:"effectivebalance"`
+	}
+	validators := make([]validator, 0, len(pubkeys))
+	batchSize := 5000
+	for i := 0; i < len(pubkeys); i += batchSize {
+		end := i + batchSize
+		if end > len(pubkeys) {
+			end = len(pubkeys)
+		}
+		var partial []validator
+		err = db.WriterDb.Select(&partial, `
+			SELECT validatorindex, pubkey, effectivebalance
+			FROM validators
+			WHERE pubkey = ANY($1) AND activationepoch <= $2 AND exitepoch > $3 AND NOT slashed AND effectivebalance > 0`,
+			pq.ByteaArray(pubkeys[i:end]), startEpoch, epoch)
+		if err != nil {
+			return fmt.Errorf("error getting validators for income anomalies: %w", err)
+		}
+		validators = append(validators, partial...)
+	}
+	if len(validators) == 0 {
+		return nil
+	}
+
+	indices := make([]uint64, 0, len(validators))
+	for _, v := range validators {
+		indices = append(indices, v.Index)
+	}
+	incomes, err := db.BigtableClient.GetValidatorIncomeDetailsHistory(indices, startEpoch, epoch)
+	if err != nil {
+		return fmt.Errorf("error getting validator income of epochs %v - %v: %w", startEpoch, epoch, err)
+	}
+
+	for _, v := range validators {
+		ratio := attestationIncomeRate(incomes[v.Index], v.EffectiveBalance) / networkRate
+
+		for _, sub := range subMap[hex.EncodeToString(v.Pubkey)] {
+			if sub.UserID == nil || sub.ID == nil {
+				return fmt.Errorf("error expected userId and subId to be defined but got user: %v, sub: %v", sub.UserID, sub.ID)
+			}
+			threshold, due := incomeAnomalyDue(sub, ratio, epoch)
+			if !due {
+				continue
+			}
+
+			n := &validatorIncomeAnomalyNotification{
+				SubscriptionID:  *sub.ID,
+				ValidatorIndex:  v.Index,
+				Epoch:           epoch,
+				StartEpoch:      startEpoch,
+				Ratio:           ratio,
+				Threshold:       threshold,
+				EventFilter:     hex.EncodeToString(v.Pubkey),
+				UnsubscribeHash: sub.UnsubscribeHash,
+			}
+			if _, exists := notificationsByUserID[*sub.UserID]; !exists {
+				notificationsByUserID[*sub.UserID] = map[types.EventName][]types.Notification{}
+			}
+			notificationsByUserID[*sub.UserID][n.GetEventName()] = append(notificationsByUserID[*sub.UserID][n.GetEventName()], n)
+			metrics.NotificationsCollected.WithLabelValues(string(n.GetEventName())).Inc()
+		}
+	}
+	return nil
+}
+
 type ethClientNotification struct {
 	SubscriptionID  uint64
 	UserID          uint64
@@ -2209,6 +3128,7 @@ type MachineEvents struct {
 	MachineName     string         `db:"machine"`
 	UnsubscribeHash sql.NullString `db:"unsubscribe_hash"`
 	EventThreshold  float64        `db:"event_threshold"`
+	Detail          string         `db:"-"`
 }
 
 func collectMonitoringMachineOffline(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64) error {
@@ -2230,16 +3150,45 @@ func isMachineDataRecent(machineData *types.MachineMetricSystemUser) bool {
 	return machineData.CurrentDataInsertTs >= nowTs-60*60
 }
 
+// machineDiskUsage returns the share of the disk space of the machine that is used, or false if the machine did not report recently
+func machineDiskUsage(machineData *types.MachineMetricSystemUser) (float64, bool) {
+	if !isMachineDataRecent(machineData) {
+		return 0, false
+	}
+	return 1 - float64(machineData.CurrentData.DiskNodeBytesFree)/float64(machineData.CurrentData.DiskNodeBytesTotal+1), true
+}
+
+// machineCpuLoad returns the cpu load of the machine over the last five minutes, or false if the machine did not report recently
+func machineCpuLoad(machineData *types.MachineMetricSystemUser) (float64, bool) {
+	if !isMachineDataRecent(machineData) {
+		return 0, false
+	}
+	if machineData.FiveMinuteOldData == nil { // no compare data found (5 min old data)
+		return 0, false
+	}
+
+	idle := float64(machineData.CurrentData.CpuNodeIdleSecondsTotal) - float64(machineData.FiveMinuteOldData.CpuNodeIdleSecondsTotal)
+	total := float64(machineData.CurrentData.CpuNodeSystemSecondsTotal) - float64(machineData.FiveMinuteOldData.CpuNodeSystemSecondsTotal)
+	return float64(1) - (idle / total), true
+}
+
+// machineMemoryUsage returns the share of the memory of the machine that is used, or false if the machine did not report recently
+func machineMemoryUsage(machineData *types.MachineMetricSystemUser) (float64, bool) {
+	if !isMachineDataRecent(machineData) {
+		return 0, false
+	}
+
+	memFree := float64(machineData.CurrentData.MemoryNodeBytesFree) + float64(machineData.CurrentData.MemoryNodeBytesCached) + float64(machineData.CurrentData.MemoryNodeBytesBuffers)
+	memTotal := float64(machineData.CurrentData.MemoryNodeBytesTotal)
+	return float64(1) - (memFree / memTotal), true
+}
+
 func collectMonitoringMachineDiskAlmostFull(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64) error {
 	return collectMonitoringMachine(notificationsByUserID, types.MonitoringMachineDiskAlmostFullEventName, 750,
 		// notify condition
 		func(subscribeData *MachineEvents, machineData *types.MachineMetricSystemUser) bool {
-			if !isMachineDataRecent(machineData) {
-				return false
-			}
-
-			percentFree := float64(machineData.CurrentData.DiskNodeBytesFree) / float64(machineData.CurrentData.DiskNodeBytesTotal+1)
-			return percentFree < subscribeData.EventThreshold
+			usage, ok := machineDiskUsage(machineData)
+			return ok && 1-usage < subscribeData.EventThreshold
 		},
 		epoch,
 	)
@@ -2249,37 +3198,139 @@ func collectMonitoringMachineCPULoad(notificationsByUserID map[uint64]map[types.
 	return collectMonitoringMachine(notificationsByUserID, types.MonitoringMachineCpuLoadEventName, 10,
 		// notify condition
 		func(subscribeData *MachineEvents, machineData *types.MachineMetricSystemUser) bool {
-			if !isMachineDataRecent(machineData) {
-				return false
-			}
+			percentLoad, ok := machineCpuLoad(machineData)
+			return ok && percentLoad > subscribeData.EventThreshold
+		},
+		epoch,
+	)
+}
 
-			if machineData.FiveMinuteOldData == nil { // no compare data found (5 min old data)
-				return false
+func collectMonitoringMachineMemoryUsage(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64) error {
+	return collectMonitoringMachine(notificationsByUserID, types.MonitoringMachineMemoryUsageEventName, 10,
+		// notify condition
+		func(subscribeData *MachineEvents, machineData *types.MachineMetricSystemUser) bool {
+			memUsage, ok := machineMemoryUsage(machineData)
+			return ok && memUsage > subscribeData.EventThreshold
+		},
+		epoch,
+	)
+}
+
+// isMachineNodeDataRecent reports whether the beacon node sent its metrics within the last 10 minutes,
+// older data is covered by the machine offline event
+func isMachineNodeDataRecent(machineData *types.MachineMetricNodeUser) bool {
+	return machineData.CurrentDataInsertTs >= time.Now().Unix()-10*60
+}
+
+func collectMonitoringBeaconNodeNotSynced(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64) error {
+	currentSlot := utils.TimeToSlot(uint64(time.Now().Unix()))
+	return collectMonitoringBeaconNode(notificationsByUserID, types.MonitoringMachineBeaconNodeNotSyncedEventName, 30,
+		// notify condition
+		func(subscribeData *MachineEvents, machineData *types.MachineMetricNodeUser) (string, bool) {
+			maxLag := uint64(types.MachineBeaconNodeSyncLagSlotsDefault)
+			if subscribeData.EventThreshold > 0 {
+				maxLag = uint64(subscribeData.EventThreshold)
 			}
+			headSlot := machineData.CurrentData.SyncBeaconHeadSlot
+			if headSlot > 0 && headSlot+maxLag < currentSlot {
+				return fmt.Sprintf("is %v slots behind the head of the chain", currentSlot-headSlot), true
+			}
+			if !machineData.CurrentData.SyncEth2Synced {
+				return "is not synced", true
+			}
+			return "", false
+		},
+		epoch,
+	)
+}
 
-			idle := float64(machineData.CurrentData.CpuNodeIdleSecondsTotal) - float64(machineData.FiveMinuteOldData.CpuNodeIdleSecondsTotal)
-			total := float64(machineData.CurrentData.CpuNodeSystemSecondsTotal) - float64(machineData.FiveMinuteOldData.CpuNodeSystemSecondsTotal)
-			percentLoad := float64(1) - (idle / total)
+// collectMonitoringExecutionBehind notifies if the execution head block reported by the beacon node is further behind the most
+// recent block the explorer indexed than the threshold of the subscription
+func collectMonitoringExecutionBehind(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64) error {
+	latestBlock, err := db.BigtableClient.GetMostRecentBlockFromDataTable()
+	if err != nil {
+		return fmt.Errorf("error getting most recent execution block: %w", err)
+	}
+	headBlock := latestBlock.GetNumber()
 
-			return percentLoad > subscribeData.EventThreshold
+	return collectMonitoringBeaconNode(notificationsByUserID, types.MonitoringMachineExecutionBehindEventName, 30,
+		// notify condition
+		func(subscribeData *MachineEvents, machineData *types.MachineMetricNodeUser) (string, bool) {
+			return executionBehind(subscribeData.EventThreshold, machineData.CurrentData, headBlock)
 		},
 		epoch,
 	)
 }
 
-func collectMonitoringMachineMemoryUsage(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64) error {
-	return collectMonitoringMachine(notificationsByUserID, types.MonitoringMachineMemoryUsageEventName, 10,
+// executionBehind returns whether the execution client of the beacon node is more than threshold blocks behind headBlock
+func executionBehind(threshold float64, node *types.MachineMetricNode, headBlock uint64) (string, bool) {
+	maxLag := uint64(types.MachineExecutionLagBlocksDefault)
+	if threshold > 0 {
+		maxLag = uint64(threshold)
+	}
+	// only reported by recent versions of the metrics exporters
+	clientBlock := node.SyncEth1HeadBlockNumber
+	if clientBlock == 0 || headBlock == 0 {
+		return "", false
+	}
+	if clientBlock+maxLag < headBlock {
+		return fmt.Sprintf("is %v blocks behind the head of the chain", headBlock-clientBlock), true
+	}
+	return "", false
+}
+
+func collectMonitoringPeerCountLow(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64) error {
+	return collectMonitoringBeaconNode(notificationsByUserID, types.MonitoringMachinePeerCountLowEventName, 30,
 		// notify condition
-		func(subscribeData *MachineEvents, machineData *types.MachineMetricSystemUser) bool {
-			if !isMachineDataRecent(machineData) {
-				return false
+		func(subscribeData *MachineEvents, machineData *types.MachineMetricNodeUser) (string, bool) {
+			minPeers := uint64(types.MachinePeerCountDefault)
+			if subscribeData.EventThreshold > 0 {
+				minPeers = uint64(subscribeData.EventThreshold)
 			}
+			peers := machineData.CurrentData.NetworkPeersConnected
+			if peers < minPeers {
+				return fmt.Sprintf("is only connected to %v peers", peers), true
+			}
+			return "", false
+		},
+		epoch,
+	)
+}
 
-			memFree := float64(machineData.CurrentData.MemoryNodeBytesFree) + float64(machineData.CurrentData.MemoryNodeBytesCached) + float64(machineData.CurrentData.MemoryNodeBytesBuffers)
-			memTotal := float64(machineData.CurrentData.MemoryNodeBytesTotal)
-			memUsage := float64(1) - (memFree / memTotal)
+var clientVersionRE = regexp.MustCompile(`\d+\.\d+\.\d+`)
 
-			return memUsage > subscribeData.EventThreshold
+// isClientVersionOutdated reports whether the reported version of a client is older than the released version.
+// Versions are compared by their first major.minor.patch part, e.g. "Lighthouse/v5.1.0-abcdef" is 5.1.0.
+func isClientVersionOutdated(reported, released string) bool {
+	reportedVersion, err := version.NewVersion(clientVersionRE.FindString(reported))
+	if err != nil {
+		return false
+	}
+	releasedVersion, err := version.NewVersion(clientVersionRE.FindString(released))
+	if err != nil {
+		return false
+	}
+	return reportedVersion.LessThan(releasedVersion)
+}
+
+func collectMonitoringClientOutdated(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64) error {
+	updatedClients := ethclients.GetUpdatedClients() // only check if there are new releases
+	if len(updatedClients) == 0 {
+		return nil
+	}
+
+	return collectMonitoringBeaconNode(notificationsByUserID, types.MonitoringMachineClientOutdatedEventName, 450,
+		// notify condition
+		func(_ *MachineEvents, machineData *types.MachineMetricNodeUser) (string, bool) {
+			for _, client := range updatedClients {
+				if !strings.EqualFold(client.Name, machineData.CurrentData.ClientName) {
+					continue
+				}
+				if isClientVersionOutdated(machineData.CurrentData.ClientVersion, client.Version) {
+					return fmt.Sprintf("is running %v %v, version %v has been released", client.Name, machineData.CurrentData.ClientVersion, client.Version), true
+				}
+			}
+			return "", false
 		},
 		epoch,
 	)
@@ -2295,24 +3346,12 @@ func collectMonitoringMachine(
 	epoch uint64,
 ) error {
 
-	var allSubscribed []MachineEvents
-	err := db.FrontendWriterDB.Select(&allSubscribed,
-		`SELECT 
-			us.user_id,
-			max(us.id) AS id,
-			ENCODE((array_agg(us.unsubscribe_hash))[1], 'hex') AS unsubscribe_hash,
-			event_filter AS machine,
-			COALESCE(event_threshold, 0) AS event_threshold
-		FROM users_subscriptions us 
-		WHERE us.event_name = $1 AND us.created_epoch <= $2 
-		AND (us.last_sent_epoch < ($2 - $3) OR us.last_sent_epoch IS NULL)
-		group by us.user_id, machine, event_threshold`,
-		eventName, epoch, epochWaitInBetween)
+	allSubscribed, err := getMachineEventSubscriptions(eventName, epochWaitInBetween, epoch)
 	if err != nil {
 		return err
 	}
 
-	rowKeys := gcp_bigtable.RowList{}
+	rowKeys := []string{}
 	for _, data := range allSubscribed {
 		rowKeys = append(rowKeys, db.BigtableClient.GetMachineRowKey(data.UserID, "system", data.MachineName))
 	}
@@ -2339,6 +3378,84 @@ func collectMonitoringMachine(
 		}
 	}
 
+	return addMachineNotifications(notificationsByUserID, eventName, allSubscribed, result, epoch)
+}
+
+// collectMonitoringBeaconNode collects the notifications of a machine event that is based on the metrics of the beacon node process.
+// The notify condition returns a description of the state of the beacon node that is included in the notification.
+func collectMonitoringBeaconNode(
+	notificationsByUserID map[uint64]map[types.EventName][]types.Notification,
+	eventName types.EventName,
+	epochWaitInBetween int,
+	notifyConditionFullfilled func(subscribeData *MachineEvents, machineData *types.MachineMetricNodeUser) (string, bool),
+	epoch uint64,
+) error {
+
+	allSubscribed, err := getMachineEventSubscriptions(eventName, epochWaitInBetween, epoch)
+	if err != nil {
+		return err
+	}
+	if len(allSubscribed) == 0 {
+		return nil
+	}
+
+	rowKeys := []string{}
+	for _, data := range allSubscribed {
+		rowKeys = append(rowKeys, db.BigtableClient.GetMachineRowKey(data.UserID, "beaconnode", data.MachineName))
+	}
+
+	machineDataOfSubscribed, err := db.BigtableClient.GetMachineNodeMetricsForNotifications(rowKeys)
+	if err != nil {
+		return err
+	}
+
+	var result []MachineEvents
+	for _, data := range allSubscribed {
+		currentMachineData, found := machineDataOfSubscribed[data.UserID][data.MachineName]
+		if !found || !isMachineNodeDataRecent(currentMachineData) {
+			continue
+		}
+
+		detail, fulfilled := notifyConditionFullfilled(&data, currentMachineData)
+		if fulfilled {
+			data.Detail = detail
+			result = append(result, data)
+		}
+	}
+
+	return addMachineNotifications(notificationsByUserID, eventName, allSubscribed, result, epoch)
+}
+
+// getMachineEventSubscriptions returns the subscriptions of a machine event that have not been notified within the last epochWaitInBetween epochs
+func getMachineEventSubscriptions(eventName types.EventName, epochWaitInBetween int, epoch uint64) ([]MachineEvents, error) {
+	var allSubscribed []MachineEvents
+	err := db.FrontendWriterDB.Select(&allSubscribed,
+		`SELECT 
+			us.user_id,
+			max(us.id) AS id,
+			ENCODE((array_agg(us.unsubscribe_hash))[1], 'hex') AS unsubscribe_hash,
+			event_filter AS machine,
+			COALESCE(event_threshold, 0) AS event_threshold
+		FROM users_subscriptions us 
+		WHERE us.event_name = $1 AND us.created_epoch <= $2 
+		AND (us.last_sent_epoch < ($2 - $3) OR us.last_sent_epoch IS NULL)
+		group by us.user_id, machine, event_threshold`,
+		eventName, epoch, epochWaitInBetween)
+	if err != nil {
+		return nil, err
+	}
+	return allSubscribed, nil
+}
+
+// addMachineNotifications adds the notifications of the machines that fulfill the condition of the event.
+// If a large share of the subscribed machines would be notified the cause is most likely on our side and no notifications are added.
+func addMachineNotifications(
+	notificationsByUserID map[uint64]map[types.EventName][]types.Notification,
+	eventName types.EventName,
+	allSubscribed []MachineEvents,
+	result []MachineEvents,
+	epoch uint64,
+) error {
 	subThreshold := uint64(10)
 	if utils.Config.Notifications.MachineEventThreshold != 0 {
 		subThreshold = utils.Config.Notifications.MachineEventThreshold
@@ -2355,7 +3472,7 @@ func collectMonitoringMachine(
 	}
 
 	var subScriptionCount uint64
-	err = db.FrontendWriterDB.Get(&subScriptionCount,
+	err := db.FrontendWriterDB.Get(&subScriptionCount,
 		`SELECT 
 			COUNT(DISTINCT user_id)
 			FROM users_subscriptions
@@ -2388,6 +3505,7 @@ func collectMonitoringMachine(
 			EventName:       eventName,
 			Epoch:           epoch,
 			UnsubscribeHash: r.UnsubscribeHash,
+			Detail:          r.Detail,
 		}
 		//logrus.Infof("notify %v %v", eventName, n)
 		if _, exists := notificationsByUserID[r.UserID]; !exists {
@@ -2415,6 +3533,7 @@ type monitorMachineNotification struct {
 	Epoch           uint64
 	EventName       types.EventName
 	UnsubscribeHash sql.NullString
+	Detail          string
 }
 
 func (n *monitorMachineNotification) GetLatestState() string {
@@ -2458,6 +3577,14 @@ func (n *monitorMachineNotification) GetInfo(includeUrl bool) string {
 		return fmt.Sprintf(`Your staking machine "%v" has switched to your configured ETH2 fallback`, n.MachineName)
 	case types.MonitoringMachineMemoryUsageEventName:
 		return fmt.Sprintf(`Your staking machine "%v" has reached your configured RAM threshold.`, n.MachineName)
+	case types.MonitoringMachineBeaconNodeNotSyncedEventName:
+		return fmt.Sprintf(`The beacon node of your staking machine "%v" %v.`, n.MachineName, n.Detail)
+	case types.MonitoringMachineExecutionBehindEventName:
+		return fmt.Sprintf(`The execution client of your staking machine "%v" %v.`, n.MachineName, n.Detail)
+	case types.MonitoringMachinePeerCountLowEventName:
+		return fmt.Sprintf(`The beacon node of your staking machine "%v" %v.`, n.MachineName, n.Detail)
+	case types.MonitoringMachineClientOutdatedEventName:
+		return fmt.Sprintf(`The beacon node of your staking machine "%v" %v.`, n.MachineName, n.Detail)
 	}
 	return ""
 }
@@ -2476,6 +3603,14 @@ func (n *monitorMachineNotification) GetTitle() string {
 		return "ETH2 Fallback Active"
 	case types.MonitoringMachineMemoryUsageEventName:
 		return "Memory Warning"
+	case types.MonitoringMachineBeaconNodeNotSyncedEventName:
+		return "Beacon Node Not Synced"
+	case types.MonitoringMachineExecutionBehindEventName:
+		return "Execution Client Behind"
+	case types.MonitoringMachinePeerCountLowEventName:
+		return "Low Peer Count"
+	case types.MonitoringMachineClientOutdatedEventName:
+		return "Client Update Available"
 	}
 	return ""
 }
@@ -2920,9 +4055,9 @@ func collectRocketpoolRewardClaimRoundNotifications(notificationsByUserID map[ui
 	return nil
 }
 
-func collectRocketpoolRPLCollateralNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, eventName types.EventName, epoch uint64) error {
+func collectRocketpoolRPLCollateralNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, eventName types.EventName, epoch uint64, shard types.NotificationShard) error {
 
-	pubkeys, subMap, err := db.GetSubsForEventFilter(eventName)
+	pubkeys, subMap, err := db.GetSubsForEventFilter(eventName, shard)
 	if err != nil {
 		return fmt.Errorf("error getting subscriptions for RocketpoolRPLCollateral %w", err)
 	}
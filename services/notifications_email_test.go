@@ -0,0 +1,246 @@
+package services
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db"
+	"github.com/gobitfly/eth2-beaconchain-explorer/db/dbtest"
+	"github.com/gobitfly/eth2-beaconchain-explorer/mail"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var updateEmailSnapshots = flag.Bool("update", false, "update the email snapshots in testdata/emails")
+
+// taxReportTestNotification replaces the pdf report, which is generated from the database, with a fixed attachment
+type taxReportTestNotification struct {
+	*taxReportNotification
+}
+
+func (n *taxReportTestNotification) GetEmailAttachment() *types.EmailAttachment {
+	return &types.EmailAttachment{Name: "income_history_20241101_20241201.pdf", Attachment: []byte("%PDF-1.4")}
+}
+
+// TestEmailNotificationSnapshots queues an email for every notification through queueEmailNotifications,
+// sends the queue through the mail package and compares the mails with the snapshots in testdata/emails.
+// Run the test with -update to regenerate the snapshots after changing a notification text or the mail layout.
+func TestEmailNotificationSnapshots(t *testing.T) {
+	prevConfig, prevWriter := utils.Config, db.FrontendWriterDB
+	defer func() { utils.Config, db.FrontendWriterDB = prevConfig, prevWriter }()
+	utils.Config = &types.Config{}
+	utils.Config.Chain.Name = "mainnet"
+	utils.Config.Chain.ClConfig.SlotsPerEpoch = 32
+	utils.Config.Chain.ClConfig.SecondsPerSlot = 12
+	// slot 3200 is a few minutes ahead, the eta of the upcoming proposal is masked in the snapshot
+	utils.Config.Chain.GenesisTimestamp = uint64(time.Now().Unix()) - 3190*12
+	utils.Config.Frontend.SiteDomain = "beaconcha.in"
+	utils.Config.Frontend.ClCurrency = "ETH"
+	utils.Config.Frontend.ClCurrencyDivisor = 1e9
+	utils.Config.Frontend.ElCurrency = "ETH"
+	utils.Config.Frontend.MainCurrency = "ETH"
+
+	hash := func(name string) sql.NullString {
+		return sql.NullString{Valid: true, String: name + "-hash"}
+	}
+	notifications := map[string]types.Notification{
+		"validator_proposal_submitted": &validatorProposalNotification{SubscriptionID: 1, ValidatorIndex: 1, Epoch: 100, Slot: 3200, Status: 1, Reward: 0.0521, EventName: types.ValidatorExecutedProposalEventName, UnsubscribeHash: hash("proposal-submitted")},
+		"validator_proposal_missed":    &validatorProposalNotification{SubscriptionID: 2, ValidatorIndex: 1, Epoch: 100, Slot: 3200, Status: 2, EventName: types.ValidatorMissedProposalEventName, UnsubscribeHash: hash("proposal-missed")},
+		"validator_is_offline":         &validatorIsOfflineNotification{SubscriptionID: 3, ValidatorIndex: 2, EventEpoch: 100, IsOffline: true, EventName: types.ValidatorIsOfflineEventName, InternalState: "98", UnsubscribeHash: hash("offline")},
+		"validator_is_online":          &validatorIsOfflineNotification{SubscriptionID: 4, ValidatorIndex: 2, EventEpoch: 110, EpochsOffline: 12, EventName: types.ValidatorIsOfflineEventName, InternalState: "110", UnsubscribeHash: hash("online")},
+		"validator_attestation_missed": &validatorAttestationNotification{SubscriptionID: 5, ValidatorIndex: 3, Epoch: 100, EventName: types.ValidatorMissedAttestationEventName, UnsubscribeHash: hash("attestation-missed")},
+		"validator_got_slashed":        &validatorGotSlashedNotification{SubscriptionID: 6, ValidatorIndex: 4, Epoch: 100, Slasher: 5, Reason: "Attestation Violation", UnsubscribeHash: hash("slashed")},
+		"validator_withdrawal":         &validatorWithdrawalNotification{SubscriptionID: 7, ValidatorIndex: 6, Epoch: 100, Slot: 3201, Amount: 18123456, UnsubscribeHash: hash("withdrawal")},
+		"validator_consolidated":       &validatorConsolidatedNotification{SubscriptionID: 8, Epoch: 100, Slot: 3202, Source: "7", Target: "8", UnsubscribeHash: hash("consolidated")},
+		"validator_el_exit":            &validatorExecutionLayerExitNotification{SubscriptionID: 9, Epoch: 100, Slot: 3203, Validator: "9", Address: []byte{0xde, 0xad, 0xbe, 0xef}, UnsubscribeHash: hash("el-exit")},
+		"validator_income_below":       &validatorIncomeAnomalyNotification{SubscriptionID: 10, ValidatorIndex: 10, Epoch: 100, StartEpoch: 91, Ratio: 0.82, Threshold: 0.9, UnsubscribeHash: hash("income-below")},
+		"eth_client_update":            &ethClientNotification{SubscriptionID: 11, UserID: 1, Epoch: 100, EthClient: "Lighthouse", UnsubscribeHash: hash("eth-client")},
+		"user_tax_report":              &taxReportTestNotification{&taxReportNotification{SubscriptionID: 12, UserID: 1, Epoch: 100, EventFilter: "validators=1,2&currency=USD", UnsubscribeHash: hash("tax-report")}},
+		"network_liveness_increased":   &networkNotification{SubscriptionID: 13, UserID: 1, Epoch: 100, UnsubscribeHash: hash("network")},
+		"rocketpool_commission":        &rocketpoolNotification{SubscriptionID: 14, UserID: 1, Epoch: 100, EventName: types.RocketpoolCommissionThresholdEventName, ExtraData: "15.00%", UnsubscribeHash: hash("rp-commission")},
+		"rocketpool_new_claimround":    &rocketpoolNotification{SubscriptionID: 15, UserID: 1, Epoch: 100, EventName: types.RocketpoolNewClaimRoundStartedEventName, UnsubscribeHash: hash("rp-claimround")},
+		"rocketpool_collateral_min":    &rocketpoolNotification{SubscriptionID: 16, UserID: 1, Epoch: 100, EventName: types.RocketpoolCollateralMinReached, ExtraData: "10.00", UnsubscribeHash: hash("rp-collateral-min")},
+		"rocketpool_collateral_max":    &rocketpoolNotification{SubscriptionID: 17, UserID: 1, Epoch: 100, EventName: types.RocketpoolCollateralMaxReached, ExtraData: "150.00", UnsubscribeHash: hash("rp-collateral-max")},
+		"validator_synccommittee_soon": &rocketpoolNotification{SubscriptionID: 18, UserID: 1, Epoch: 100, EventName: types.SyncCommitteeSoon, ExtraData: "11|256|512", UnsubscribeHash: hash("sync-committee")},
+		"validator_proposal_upcoming":  &validatorProposalNotification{SubscriptionID: 30, ValidatorIndex: 1, Epoch: 100, Slot: 3200, Status: 0, EventName: types.ValidatorUpcomingProposalEventName, UnsubscribeHash: hash("proposal-upcoming")},
+		"notification_rule":            &notificationRuleNotification{SubscriptionID: 19, RuleID: 1, RuleName: "node <down>", Epoch: 100, Results: []string{"3 validators offline in group watchlist >= 3", "disk usage of node1 is 95.5% > 90"}, UnsubscribeHash: hash("rule")},
+	}
+	// only the beacon node events describe the state of the node, the others have no detail
+	machineDetails := map[types.EventName]string{
+		types.MonitoringMachineBeaconNodeNotSyncedEventName: "is 40 slots behind the head of the chain",
+		types.MonitoringMachinePeerCountLowEventName:        "is only connected to 3 peers",
+		types.MonitoringMachineClientOutdatedEventName:      "is running Lighthouse v5.1.0, version v5.2.0 has been released",
+	}
+	for i, event := range types.MachineEvents {
+		notifications[string(event)] = &monitorMachineNotification{SubscriptionID: uint64(20 + i), MachineName: "node1", UserID: 1, Epoch: 100, EventName: event, Detail: machineDetails[event], UnsubscribeHash: hash(string(event))}
+	}
+
+	// every mail goes to its own user so its content does not depend on map iteration order
+	fake := &fakeNotificationDB{emails: map[uint64]string{}}
+	notificationsByUserID := map[uint64]map[types.EventName][]types.Notification{}
+	names := map[string]string{}
+	sortedNames := make([]string, 0, len(notifications))
+	for name := range notifications {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+	for i, name := range sortedNames {
+		userID := uint64(i + 1)
+		email := fmt.Sprintf("user%d@example.com", userID)
+		fake.emails[userID] = email
+		names[email] = name
+		n := notifications[name]
+		notificationsByUserID[userID] = map[types.EventName][]types.Notification{n.GetEventName(): {n}}
+	}
+
+	// the digest summarizes notifications that have been held for a user in digest mode
+	digestUserID := uint64(len(sortedNames) + 1)
+	digestEmail := fmt.Sprintf("user%d@example.com", digestUserID)
+	fake.emails[digestUserID] = digestEmail
+	names[digestEmail] = "notification_digest"
+	for i, validator := range []uint64{3, 11, 12} {
+		fake.held = append(fake.held, heldNotification{ID: uint64(i + 1), UserID: digestUserID, Channel: types.EmailNotificationChannel, EventName: types.ValidatorMissedAttestationEventName,
+			Title: "Attestation Missed", Info: fmt.Sprintf("Validator %d missed an attestation in epoch 100.", validator)})
+	}
+	fake.held = append(fake.held, heldNotification{ID: 4, UserID: digestUserID, Channel: types.EmailNotificationChannel, EventName: types.ValidatorIsOfflineEventName,
+		Title: "Validator is Offline", Info: "Validator 2 is offline since epoch 98."})
+
+	fakeDB := fake.sqlx()
+	db.FrontendWriterDB = fakeDB
+	transport := mail.NewMemoryTransport()
+	mail.SetTransport(transport)
+	defer mail.SetTransport(nil)
+
+	err := queueEmailNotifications(notificationsByUserID, fakeDB, "")
+	if err != nil {
+		t.Fatalf("error queueing email notifications: %v", err)
+	}
+	err = queueNotificationDigests(fakeDB)
+	if err != nil {
+		t.Fatalf("error queueing notification digests: %v", err)
+	}
+	err = sendEmailNotifications(fakeDB)
+	if err != nil {
+		t.Fatalf("error sending email notifications: %v", err)
+	}
+	for _, query := range fake.unexpected {
+		t.Errorf("unexpected query: %v", query)
+	}
+
+	messages := transport.Messages()
+	if len(messages) != len(names) {
+		t.Fatalf("got %v mails, want %v", len(messages), len(names))
+	}
+	for _, msg := range messages {
+		name := names[msg.To]
+		t.Run(name, func(t *testing.T) {
+			compareEmailSnapshot(t, filepath.Join("testdata", "emails", name+".golden"), formatEmailSnapshot(msg))
+		})
+	}
+}
+
+// the sync committee mail contains the time until the committee starts
+var syncCommitteeDurationRE = regexp.MustCompile(`which is in -?[0-9hms.]+ and`)
+
+// the upcoming proposal mail contains the time until the proposal
+var proposalEtaRE = regexp.MustCompile(`\((in ~[0-9]+ minutes?|now)\)`)
+
+func formatEmailSnapshot(msg *mail.Message) string {
+	attachments := []string{}
+	for _, att := range msg.Attachments {
+		attachments = append(attachments, fmt.Sprintf("%s (%d bytes)", att.Name, len(att.Attachment)))
+	}
+	snapshot := fmt.Sprintf("To: %s\nSubject: %s\nAttachments: %s\n\n%s\n\n%s\n", msg.To, msg.Subject, strings.Join(attachments, ", "), msg.Text, msg.HTML)
+	snapshot = syncCommitteeDurationRE.ReplaceAllString(snapshot, "which is in <duration> and")
+	return proposalEtaRE.ReplaceAllString(snapshot, "(<eta>)")
+}
+
+func compareEmailSnapshot(t *testing.T, path, got string) {
+	if *updateEmailSnapshots {
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			t.Fatalf("error creating snapshot directory: %v", err)
+		}
+		err = os.WriteFile(path, []byte(got), 0o644)
+		if err != nil {
+			t.Fatalf("error writing snapshot: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error reading snapshot, run the test with -update to create it: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("mail does not match snapshot %v, run the test with -update if the change is intended\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// fakeNotificationDB answers the queries of the email notification path from memory:
+// the user emails, the held notifications of digests, the notification queue and the sent mail counter.
+type fakeNotificationDB struct {
+	emails     map[uint64]string
+	held       []heldNotification
+	queue      [][]byte
+	unexpected []string
+}
+
+func (f *fakeNotificationDB) sqlx() *sqlx.DB {
+	return (&dbtest.DB{Query: f.query, Exec: f.exec}).Sqlx()
+}
+
+func (f *fakeNotificationDB) exec(query string, args []driver.Value) (driver.Result, error) {
+	switch {
+	case strings.Contains(query, "INSERT INTO notification_queue"):
+		content, ok := args[1].([]byte)
+		if !ok {
+			return nil, fmt.Errorf("unexpected notification queue content %T", args[1])
+		}
+		f.queue = append(f.queue, content)
+	case strings.Contains(query, "UPDATE notification_queue set sent"), strings.Contains(query, "INSERT INTO mails_sent"), strings.Contains(query, "DELETE FROM notification_digest_queue"):
+	default:
+		f.unexpected = append(f.unexpected, query)
+		return nil, fmt.Errorf("unexpected query")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (f *fakeNotificationDB) query(query string, args []driver.Value) (*dbtest.Rows, error) {
+	rows := &dbtest.Rows{}
+	switch {
+	case strings.Contains(query, "SELECT id, email FROM users"):
+		rows.Columns = []string{"id", "email"}
+		for id, email := range f.emails {
+			rows.Values = append(rows.Values, []driver.Value{int64(id), email})
+		}
+	case strings.Contains(query, "FROM notification_digest_queue"):
+		rows.Columns = []string{"id", "user_id", "channel", "event_name", "title", "info", "info_markdown"}
+		for _, n := range f.held {
+			rows.Values = append(rows.Values, []driver.Value{int64(n.ID), int64(n.UserID), string(n.Channel), string(n.EventName), n.Title, n.Info, n.InfoMarkdown})
+		}
+	case strings.Contains(query, "FROM users_devices"):
+		rows.Columns = []string{"user_id", "notification_token"}
+	case strings.Contains(query, "FROM users_chat_channels"):
+		rows.Columns = []string{"user_id", "channel", "target"}
+	case strings.Contains(query, "FROM notification_queue WHERE sent IS null AND channel = 'email'"):
+		rows.Columns = []string{"id", "created", "sent", "channel", "content"}
+		for i, content := range f.queue {
+			rows.Values = append(rows.Values, []driver.Value{int64(i + 1), time.Now(), nil, "email", content})
+		}
+	default:
+		f.unexpected = append(f.unexpected, query)
+		return nil, fmt.Errorf("unexpected query")
+	}
+	return rows, nil
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"database/sql/driver"
+	"math"
+	"testing"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/db/dbtest"
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
+	"github.com/gobitfly/eth2-beaconchain-explorer/utils"
+
+	itypes "github.com/gobitfly/eth-rewards/types"
+)
+
+func TestQueueDiscordNotificationsDedupe(t *testing.T) {
+	queued := map[string]bool{}
+	fakeDB := (&dbtest.DB{
+		Exec: func(query string, args []driver.Value) (driver.Result, error) {
+			key := args[1].(string)
+			if queued[key] {
+				return driver.RowsAffected(0), nil
+			}
+			queued[key] = true
+			return driver.RowsAffected(1), nil
+		},
+	}).Sqlx()
+
+	batches := []types.TransitDiscordContent{{}, {}}
+	err := queueDiscordNotifications(1, batches, fakeDB, "mainnet:100:0:network")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queued) != 2 || !queued["mainnet:100:0:network:webhook_discord:1:0"] || !queued["mainnet:100:0:network:webhook_discord:1:1"] {
+		t.Fatalf("expected both batches to be queued with their dedupe keys, got %v", queued)
+	}
+
+	// a shard that is collected again yields an additional batch, it must not be queued as the first batch already was
+	err = queueDiscordNotifications(1, append(batches, types.TransitDiscordContent{}), fakeDB, "mainnet:100:0:network")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queued) != 2 {
+		t.Fatalf("expected the batches not to be queued again, got %v", queued)
+	}
+}
+
+func TestWebhookEventIDIgnoresNotificationText(t *testing.T) {
+	prevConfig := utils.Config
+	defer func() { utils.Config = prevConfig }()
+	utils.Config = &types.Config{}
+	utils.Config.Chain.Name = "mainnet"
+
+	n := &validatorIncomeAnomalyNotification{SubscriptionID: 10, ValidatorIndex: 10, Epoch: 100, StartEpoch: 91, Ratio: 0.82, Threshold: 0.9}
+	reworded := &validatorIncomeAnomalyNotification{SubscriptionID: 10, ValidatorIndex: 10, Epoch: 100, StartEpoch: 91, Ratio: 0.79, Threshold: 0.9}
+	if n.GetInfo(false) == reworded.GetInfo(false) {
+		t.Fatal("expected the notifications to differ in their text")
+	}
+	if webhookEventID(1, n) != webhookEventID(1, reworded) {
+		t.Errorf("event id depends on the notification text")
+	}
+	if webhookEventID(1, n) == webhookEventID(2, n) {
+		t.Errorf("event id does not depend on the user")
+	}
+	next := &validatorIncomeAnomalyNotification{SubscriptionID: 10, ValidatorIndex: 10, Epoch: 101, StartEpoch: 92, Ratio: 0.82, Threshold: 0.9}
+	if webhookEventID(1, n) == webhookEventID(1, next) {
+		t.Errorf("event id does not depend on the epoch")
+	}
+}
+
+// testEpochIncomes returns the same attestation income for each epoch of [startEpoch, epoch]
+func testEpochIncomes(startEpoch, epoch uint64, reward uint64) map[uint64]*itypes.ValidatorEpochIncome {
+	incomes := map[uint64]*itypes.ValidatorEpochIncome{}
+	for e := startEpoch; e <= epoch; e++ {
+		incomes[e] = &itypes.ValidatorEpochIncome{AttestationSourceReward: reward, AttestationTargetReward: reward, AttestationHeadReward: reward, AttestationTargetPenalty: reward / 2}
+	}
+	return incomes
+}
+
+func TestIncomeAnomalyRatio(t *testing.T) {
+	startEpoch, epoch := uint64(100-incomeAnomalyWindow+1), uint64(100)
+	// the network has 1000 validators earning 10000 gwei (3*4000-2000) per epoch each
+	totals := testEpochIncomes(startEpoch, epoch, 4000*1000)
+	eligible := map[uint64]uint64{}
+	for e := startEpoch; e <= epoch; e++ {
+		eligible[e] = 1000 * 32e9
+	}
+	networkRate, complete := totalAttestationIncomeRate(totals, eligible, startEpoch, epoch)
+	if !complete {
+		t.Fatal("expected the window to be complete")
+	}
+	if want := 10000 / 32e9; math.Abs(networkRate-want) > 1e-18 {
+		t.Errorf("got network rate %v, want %v", networkRate, want)
+	}
+
+	tests := []struct {
+		name             string
+		reward           uint64
+		sourcePenalty    uint64
+		effectiveBalance uint64
+		want             float64
+	}{
+		{"same as the network", 4000, 0, 32e9, 1},
+		{"half of the network", 2000, 0, 32e9, 0.5},
+		{"lower effective balance", 2000, 0, 16e9, 1},
+		{"penalties only", 0, 1000, 32e9, -0.1},
+	}
+	for _, tt := range tests {
+		incomes := testEpochIncomes(startEpoch, epoch, tt.reward)
+		for _, income := range incomes {
+			income.AttestationSourcePenalty = tt.sourcePenalty
+		}
+		if ratio := attestationIncomeRate(incomes, tt.effectiveBalance) / networkRate; math.Abs(ratio-tt.want) > 1e-9 {
+			t.Errorf("%v: got ratio %v, want %v", tt.name, ratio, tt.want)
+		}
+	}
+}
+
+func TestIncomeAnomalyWindowCoverage(t *testing.T) {
+	startEpoch, epoch := uint64(100-incomeAnomalyWindow+1), uint64(100)
+	eligible := func(first, last uint64) map[uint64]uint64 {
+		eligible := map[uint64]uint64{}
+		for e := first; e <= last; e++ {
+			eligible[e] = 32e9
+		}
+		return eligible
+	}
+
+	tests := []struct {
+		name     string
+		totals   map[uint64]*itypes.ValidatorEpochIncome
+		eligible map[uint64]uint64
+		complete bool
+	}{
+		{"complete", testEpochIncomes(startEpoch, epoch, 1), eligible(startEpoch, epoch), true},
+		{"last income not exported", testEpochIncomes(startEpoch, epoch-1, 1), eligible(startEpoch, epoch), false},
+		{"first income not exported", testEpochIncomes(startEpoch+1, epoch, 1), eligible(startEpoch, epoch), false},
+		{"last epoch not exported", testEpochIncomes(startEpoch, epoch, 1), eligible(startEpoch, epoch-1), false},
+		{"nothing exported", nil, nil, false},
+	}
+	for _, tt := range tests {
+		if _, complete := totalAttestationIncomeRate(tt.totals, tt.eligible, startEpoch, epoch); complete != tt.complete {
+			t.Errorf("%v: got complete %v, want %v", tt.name, complete, tt.complete)
+		}
+	}
+}
+
+func TestIncomeAnomalyDue(t *testing.T) {
+	prevConfig := utils.Config
+	defer func() { utils.Config = prevConfig }()
+	utils.Config = &types.Config{}
+	utils.Config.Chain.ClConfig.SlotsPerEpoch = 32
+	utils.Config.Chain.ClConfig.SecondsPerSlot = 12
+	epochsPerDay := utils.EpochsPerDay()
+
+	lastEpoch := func(epoch uint64) *uint64 { return &epoch }
+	epoch := uint64(1000)
+	tests := []struct {
+		name          string
+		sub           types.Subscription
+		ratio         float64
+		wantThreshold float64
+		wantDue       bool
+	}{
+		{"below the default threshold", types.Subscription{}, 0.5, types.ValidatorIncomeBelowNetworkThresholdDefault, true},
+		{"above the default threshold", types.Subscription{}, 0.8, types.ValidatorIncomeBelowNetworkThresholdDefault, false},
+		{"below the threshold of the subscription", types.Subscription{EventThreshold: 0.9}, 0.8, 0.9, true},
+		{"at the threshold of the subscription", types.Subscription{EventThreshold: 0.9}, 0.9, 0.9, false},
+		{"subscribed during the window", types.Subscription{CreatedEpoch: epoch - incomeAnomalyWindow + 1}, 0.5, types.ValidatorIncomeBelowNetworkThresholdDefault, false},
+		{"subscribed before the window", types.Subscription{CreatedEpoch: epoch - incomeAnomalyWindow}, 0.5, types.ValidatorIncomeBelowNetworkThresholdDefault, true},
+		{"notified less than a day ago", types.Subscription{LastEpoch: lastEpoch(epoch - epochsPerDay + 1)}, 0.5, types.ValidatorIncomeBelowNetworkThresholdDefault, false},
+		{"notified a day ago", types.Subscription{LastEpoch: lastEpoch(epoch - epochsPerDay)}, 0.5, types.ValidatorIncomeBelowNetworkThresholdDefault, true},
+	}
+	for _, tt := range tests {
+		threshold, due := incomeAnomalyDue(tt.sub, tt.ratio, epoch)
+		if threshold != tt.wantThreshold || due != tt.wantDue {
+			t.Errorf("%v: got threshold %v (due %v), want %v (due %v)", tt.name, threshold, due, tt.wantThreshold, tt.wantDue)
+		}
+	}
+}
+
+func TestExecutionBehind(t *testing.T) {
+	tests := []struct {
+		name        string
+		threshold   float64
+		clientBlock uint64
+		headBlock   uint64
+		want        string
+		fulfilled   bool
+	}{
+		{"within the default lag", 0, 1000, 1000 + types.MachineExecutionLagBlocksDefault, "", false},
+		{"behind the default lag", 0, 1000, 1001 + types.MachineExecutionLagBlocksDefault, "is 21 blocks behind the head of the chain", true},
+		{"within the threshold", 50, 1000, 1050, "", false},
+		{"behind the threshold", 50, 1000, 1051, "is 51 blocks behind the head of the chain", true},
+		{"ahead of the indexed head", 0, 1100, 1000, "", false},
+		{"head not reported", 0, 0, 1000, "", false},
+		{"indexed head unknown", 0, 1000, 0, "", false},
+	}
+	for _, tt := range tests {
+		detail, fulfilled := executionBehind(tt.threshold, &types.MachineMetricNode{SyncEth1HeadBlockNumber: tt.clientBlock}, tt.headBlock)
+		if detail != tt.want || fulfilled != tt.fulfilled {
+			t.Errorf("%v: got %q (fulfilled %v), want %q (fulfilled %v)", tt.name, detail, fulfilled, tt.want, tt.fulfilled)
+		}
+	}
+}
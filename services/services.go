@@ -8,6 +8,7 @@ import (
 	"html/template"
 	"math"
 	"math/big"
+	"os"
 	"sort"
 	"strings"
 	"sync"
@@ -33,6 +34,15 @@ import (
 
 var logger = logrus.New().WithField("module", "services")
 
+// instanceID identifies this process as the owner of the work it leases, like notification shards and export jobs
+var instanceID = func() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano())
+}()
+
 // Init will initialize the services
 func Init() {
 	ready := &sync.WaitGroup{}
@@ -87,10 +97,17 @@ func Init() {
 	ready.Add(1)
 	go latestExportedStatisticDayUpdater(ready)
 
+	ready.Add(1)
+	go chainEventsUpdater(ready)
+
 	if utils.Config.RatelimitUpdater.Enabled {
 		go ratelimit.DBUpdater()
 	}
 
+	if utils.Config.Exports.WorkerEnabled && ExportsEnabled() {
+		go exportWorker()
+	}
+
 	ready.Wait()
 }
 
@@ -108,6 +125,7 @@ func InitNotificationCollector(pubkeyCachePath string) {
 	go ethclients.Init()
 
 	go notificationCollector()
+	go upcomingProposalNotifier()
 }
 
 func getRelaysPageData() (*types.RelaysResp, error) {
@@ -1283,6 +1301,7 @@ func gasNowUpdater(wg *sync.WaitGroup) {
 		if err != nil {
 			logger.Errorf("error caching latestFinalizedEpoch: %v", err)
 		}
+		publishGasEvent(data)
 		if firstRun {
 			wg.Done()
 			firstRun = false
@@ -1,6 +1,6 @@
 var csrfToken = ""
 
-const VALIDATOR_EVENTS = ["validator_attestation_missed", "validator_proposal_missed", "validator_proposal_submitted", "validator_got_slashed", "validator_synccommittee_soon", "validator_is_offline", "validator_withdrawal"]
+const VALIDATOR_EVENTS = ["validator_attestation_missed", "validator_proposal_missed", "validator_proposal_submitted", "validator_proposal_upcoming", "validator_got_slashed", "validator_synccommittee_soon", "validator_is_offline", "validator_withdrawal", "validator_income_below_network"]
 
 // const MONITORING_EVENTS = ['monitoring_machine_offline', 'monitoring_hdd_almostfull', 'monitoring_cpu_load']
 
@@ -421,11 +421,19 @@ function loadMonitoringData(data) {
           //       ></i>
           //     </span>`
           // }
-          if (data) {
-            return (data * 100).toFixed(0) + "%"
-          } else {
+          if (!data) {
             return "N/A"
           }
+          switch (row.notification.replace(/^[a-zA-Z]+:/, "")) {
+            case "monitoring_beacon_node_not_synced":
+              return data + " slots"
+            case "monitoring_execution_behind":
+              return data + " blocks"
+            case "monitoring_peer_count_low":
+              return data + " peers"
+            default:
+              return (data * 100).toFixed(0) + "%"
+          }
         },
       },
       {
@@ -679,6 +687,9 @@ function loadValidatorsData(data) {
                   case "validator_proposal_missed":
                     badgeColor = "badge-light"
                     break
+                  case "validator_proposal_upcoming":
+                    badgeColor = "badge-light"
+                    break
                   case "validator_got_slashed":
                     badgeColor = "badge-light"
                     break
@@ -690,6 +701,9 @@ function loadValidatorsData(data) {
                     break
                   case "validator_withdrawal":
                     badgeColor = "badge-light"
+                    break
+                  case "validator_income_below_network":
+                    badgeColor = "badge-light"
                 }
                 notifications += `<span style="font-size: 12px; font-weight: 500;" class="badge badge-pill ${badgeColor} ${textColor} badge-custom-size mr-1 my-1">${n.replace("validator", "").replaceAll("_", " ")}</span>`
               }
@@ -1095,6 +1109,16 @@ $(function () {
           case "monitoring_hdd_almostfull":
             t = parseFloat($("#hdd-input-range-val").val()) / 100
             break
+          // the beacon node thresholds are absolute numbers of slots, blocks and peers
+          case "monitoring_beacon_node_not_synced":
+            t = parseInt($("#not-synced-input-val").val())
+            break
+          case "monitoring_execution_behind":
+            t = parseInt($("#execution-behind-input-val").val())
+            break
+          case "monitoring_peer_count_low":
+            t = parseInt($("#peers-input-val").val())
+            break
           default:
             t = 0
         }
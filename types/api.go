@@ -2,10 +2,14 @@ package types
 
 import (
 	"database/sql/driver"
+	"encoding/hex"
 	"encoding/json"
 	"math/big"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 )
 
@@ -14,6 +18,119 @@ type ApiResponse struct {
 	Data   interface{} `json:"data"`
 }
 
+// ApiPaginatedResponse is the response of the cursor paginated api v2 endpoints
+type ApiPaginatedResponse struct {
+	Status string      `json:"status"`
+	Data   interface{} `json:"data"`
+	Paging *ApiPaging  `json:"paging"`
+}
+
+// ApiPaging points to the next page of a paginated response, it is null on the last page
+type ApiPaging struct {
+	NextCursor string `json:"next_cursor"`
+	Next       string `json:"next"`
+}
+
+// ApiExportRequest submits an export job, its validators are selected by exactly one of Validators, WithdrawalAddress or Dashboard
+type ApiExportRequest struct {
+	Validators        string   `json:"validators"`         // comma separated list of validator indices or pubkeys
+	WithdrawalAddress string   `json:"withdrawal_address"` // withdrawal address or withdrawal credentials
+	Dashboard         bool     `json:"dashboard"`          // the validators of the saved dashboard of the user
+	StartDate         string   `json:"start_date"`         // first day of the export as YYYY-MM-DD in utc
+	EndDate           string   `json:"end_date"`           // last day of the export as YYYY-MM-DD in utc
+	Datasets          []string `json:"datasets"`           // any of balances, income_details, attestations, proposals and withdrawals
+	Format            string   `json:"format"`             // csv or parquet, defaults to csv
+}
+
+// ApiExportJob is the state of an export job, its files can be downloaded once it is completed
+type ApiExportJob struct {
+	ID         uint64           `json:"id"`
+	Status     string           `json:"status"` // one of pending, running, completed, failed or expired
+	Validators []uint64         `json:"validators"`
+	StartDate  string           `json:"start_date"`
+	EndDate    string           `json:"end_date"`
+	Datasets   []string         `json:"datasets"`
+	Format     string           `json:"format"`
+	Created    time.Time        `json:"created"`
+	Completed  *time.Time       `json:"completed"`
+	Expires    *time.Time       `json:"expires"` // the files are deleted afterwards
+	Error      string           `json:"error,omitempty"`
+	Files      []*ApiExportFile `json:"files"`
+}
+
+type ApiExportFile struct {
+	Dataset     string    `json:"dataset"`
+	Rows        uint64    `json:"rows"`
+	Size        int64     `json:"size"`
+	DownloadURL string    `json:"download_url"`
+	LinkExpires time.Time `json:"link_expires"` // a new link is returned by every request of the job
+}
+
+// ApiStreamRequest is a message of a client of the websocket streaming api
+type ApiStreamRequest struct {
+	Action     string   `json:"action"` // one of subscribe or unsubscribe
+	Topics     []string `json:"topics"`
+	Validators string   `json:"validators"` // comma separated list of validator indices or pubkeys, required for the validator topics
+}
+
+// ApiStreamMessage is a message of the websocket streaming api to its clients
+type ApiStreamMessage struct {
+	Topic string      `json:"topic"`
+	Data  interface{} `json:"data,omitempty"`
+	Error string      `json:"error,omitempty"`
+}
+
+// ApiStreamSubscriptions are the subscriptions of a websocket streaming api connection
+type ApiStreamSubscriptions struct {
+	Topics     []string `json:"topics"`
+	Validators []uint64 `json:"validators"`
+}
+
+type ApiStreamSlot struct {
+	Slot            uint64   `db:"slot" json:"slot"`
+	Epoch           uint64   `db:"epoch" json:"epoch"`
+	Proposer        uint64   `db:"proposer" json:"proposer"`
+	BlockRoot       HexBytes `db:"blockroot" json:"blockroot"`
+	Status          string   `db:"status" json:"status"` // 1 = proposed, 2 = missed, 3 = orphaned
+	ExecBlockNumber *uint64  `db:"exec_block_number" json:"exec_block_number"`
+}
+
+type ApiStreamEpoch struct {
+	Epoch uint64 `json:"epoch"`
+}
+
+type ApiStreamGas struct {
+	Rapid     *big.Int `json:"rapid"`
+	Fast      *big.Int `json:"fast"`
+	Standard  *big.Int `json:"standard"`
+	Slow      *big.Int `json:"slow"`
+	Timestamp int64    `json:"timestamp"`
+}
+
+// ApiStreamValidatorDuty is an upcoming duty of a validator
+type ApiStreamValidatorDuty struct {
+	Validator uint64 `json:"validator"`
+	Duty      string `json:"duty"` // one of proposal or sync
+	// Slot and Epoch are set for proposals
+	Slot  uint64 `json:"slot,omitempty"`
+	Epoch uint64 `json:"epoch,omitempty"`
+	// Period and its first and last epoch are set for sync committee duties
+	Period     uint64 `json:"period,omitempty"`
+	StartEpoch uint64 `json:"start_epoch,omitempty"`
+	EndEpoch   uint64 `json:"end_epoch,omitempty"`
+}
+
+// ApiStreamValidatorResult is the result of a duty of a validator
+type ApiStreamValidatorResult struct {
+	Validator uint64 `json:"validator"`
+	Duty      string `json:"duty"` // one of proposal or attestation
+	Slot      uint64 `json:"slot"`
+	Epoch     uint64 `json:"epoch"`
+	// Status of a proposal is 1 = proposed, 2 = missed, 3 = orphaned, of an attestation 0 = missed, 1 = attested
+	Status        uint64 `json:"status"`
+	InclusionSlot uint64 `json:"inclusion_slot,omitempty"`
+}
+
 type StatsSystem struct {
 	CPUCores                      uint64 `mapstructure:"cpu_cores"`
 	CPUThreads                    uint64 `mapstructure:"cpu_threads"`
@@ -61,6 +178,7 @@ type StatsAdditionalsBeaconnode struct {
 	SyncBeaconHeadSlot              uint64 `mapstructure:"sync_beacon_head_slot"`
 	SyncEth1FallbackConfigured      bool   `mapstructure:"sync_eth1_fallback_configured"`
 	SyncEth1FallbackConnected       bool   `mapstructure:"sync_eth1_fallback_connected"`
+	SyncEth1HeadBlockNumber         uint64 `mapstructure:"sync_eth1_head_block_number"`
 }
 
 type StatsMeta struct {
@@ -72,16 +190,16 @@ type StatsMeta struct {
 }
 
 type StatsDataStruct struct {
-	Validator interface{} `json:"validator"`
-	Node      interface{} `json:"node"`
-	System    interface{} `json:"system"`
+	Validator []*MachineMetricValidator `json:"validator"`
+	Node      []*MachineMetricNode      `json:"node"`
+	System    []*MachineMetricSystem    `json:"system"`
 }
 
 type WidgetResponse struct {
-	Eff             any   `json:"efficiency"`
-	Validator       any   `json:"validator"`
-	Epoch           int64 `json:"epoch"`
-	RocketpoolStats any   `json:"rocketpool_network_stats"`
+	Eff             []*ValidatorEffectiveness    `json:"efficiency"`
+	Validator       []ApiWidgetValidatorResponse `json:"validator"`
+	Epoch           int64                        `json:"epoch"`
+	RocketpoolStats []APIRocketpoolStatsResponse `json:"rocketpool_network_stats"`
 }
 
 type UsersNotificationsRequest struct {
@@ -93,6 +211,16 @@ type UsersNotificationsRequest struct {
 	JoinValidator bool     `json:"join_validator"`
 }
 
+// NotificationHistoryUpdateRequest selects the notifications of the notification history that are marked as read or acknowledged
+type NotificationHistoryUpdateRequest struct {
+	IDs []uint64 `json:"ids"`
+	All bool     `json:"all"`
+}
+
+type NotificationHistoryUnreadResponse struct {
+	Unread uint64 `json:"unread"`
+}
+
 type DashboardRequest struct {
 	IndicesOrPubKey string `json:"indicesOrPubkey"`
 }
@@ -303,195 +431,307 @@ type ApiWithdrawalCredentialsResponse struct {
 }
 
 type APIEpochResponse struct {
-	Epoch                   uint64 `json:"epoch"`
-	Ts                      uint64 `json:"ts"`
-	AttestationsCount       uint64 `json:"attestationscount"`
-	AttesterSlashingsCount  uint64 `json:"attesterslashingscount"`
-	AverageValidatorBalance uint64 `json:"averagevalidatorbalance"`
-	BlocksCount             uint64 `json:"blockscount"`
-	DepositsCount           uint64 `json:"depositscount"`
-	EligibleEther           uint64 `json:"eligibleether"`
-	Finalized               bool   `json:"finalized"`
-	GlobalParticipationRate uint64 `json:"globalparticipationrate"`
-	MissedBlocks            uint64 `json:"missedblocks"`
-	OrphanedBlocks          uint64 `json:"orphanedblocks"`
-	ProposedBlocks          uint64 `json:"proposedblocks"`
-	ProposerSlashingsCount  uint64 `json:"proposerslashingscount"`
-	ScheduledBlocks         uint64 `json:"scheduledblocks"`
-	TotalValidatorBalance   uint64 `json:"totalvalidatorbalance"`
-	ValidatorsCount         uint64 `json:"validatorscount"`
-	VoluntaryExitsCount     uint64 `json:"voluntaryexitscount"`
-	VotedEther              uint64 `json:"votedether"`
-	RewardsExported         uint64 `json:"rewards_exported"`
-	WithdrawalCount         uint64 `json:"withdrawalcount"`
+	AttestationsCount       uint64    `db:"attestationscount" json:"attestationscount"`
+	AttesterSlashingsCount  uint64    `db:"attesterslashingscount" json:"attesterslashingscount"`
+	AverageValidatorBalance uint64    `db:"averagevalidatorbalance" json:"averagevalidatorbalance"`
+	BlocksCount             uint64    `db:"blockscount" json:"blockscount"`
+	DepositsCount           uint64    `db:"depositscount" json:"depositscount"`
+	EligibleEther           *uint64   `db:"eligibleether" json:"eligibleether"`
+	Epoch                   uint64    `db:"epoch" json:"epoch"`
+	Finalized               bool      `db:"finalized" json:"finalized"`
+	GlobalParticipationRate *float64  `db:"globalparticipationrate" json:"globalparticipationrate"`
+	MissedBlocks            uint64    `db:"missedblocks" json:"missedblocks"`
+	OrphanedBlocks          uint64    `db:"orphanedblocks" json:"orphanedblocks"`
+	ProposedBlocks          uint64    `db:"proposedblocks" json:"proposedblocks"`
+	ProposerSlashingsCount  uint64    `db:"proposerslashingscount" json:"proposerslashingscount"`
+	RewardsExported         bool      `db:"rewards_exported" json:"rewards_exported"`
+	ScheduledBlocks         uint64    `db:"scheduledblocks" json:"scheduledblocks"`
+	TotalValidatorBalance   uint64    `db:"totalvalidatorbalance" json:"totalvalidatorbalance"`
+	Ts                      time.Time `db:"-" json:"ts"`
+	ValidatorsCount         uint64    `db:"validatorscount" json:"validatorscount"`
+	VotedEther              *uint64   `db:"votedether" json:"votedether"`
+	VoluntaryExitsCount     uint64    `db:"voluntaryexitscount" json:"voluntaryexitscount"`
+	WithdrawalCount         uint64    `db:"withdrawalcount" json:"withdrawalcount"`
+}
+
+// ApiDashboardEpochResponse is the summary of an epoch that is part of the app dashboard
+type ApiDashboardEpochResponse struct {
+	AttestationsCount       uint64   `db:"attestationscount" json:"attestationscount"`
+	AttesterSlashingsCount  uint64   `db:"attesterslashingscount" json:"attesterslashingscount"`
+	AverageValidatorBalance uint64   `db:"averagevalidatorbalance" json:"averagevalidatorbalance"`
+	BlocksCount             uint64   `db:"blockscount" json:"blockscount"`
+	DepositsCount           uint64   `db:"depositscount" json:"depositscount"`
+	EligibleEther           *uint64  `db:"eligibleether" json:"eligibleether"`
+	Epoch                   uint64   `db:"epoch" json:"epoch"`
+	Finalized               bool     `db:"finalized" json:"finalized"`
+	GlobalParticipationRate *float64 `db:"globalparticipationrate" json:"globalparticipationrate"`
+	ProposerSlashingsCount  uint64   `db:"proposerslashingscount" json:"proposerslashingscount"`
+	TotalValidatorBalance   uint64   `db:"totalvalidatorbalance" json:"totalvalidatorbalance"`
+	ValidatorsCount         uint64   `db:"validatorscount" json:"validatorscount"`
+	VoluntaryExitsCount     uint64   `db:"voluntaryexitscount" json:"voluntaryexitscount"`
+	VotedEther              *uint64  `db:"votedether" json:"votedether"`
+}
+
+// APIBlockResponse contains the columns of a block that are shared by the epoch, slot and proposal endpoints
+type APIBlockResponse struct {
+	Attestationscount          uint64   `db:"attestationscount" json:"attestationscount"`
+	Attesterslashingscount     uint64   `db:"attesterslashingscount" json:"attesterslashingscount"`
+	Blockroot                  HexBytes `db:"blockroot" json:"blockroot"`
+	Depositscount              uint64   `db:"depositscount" json:"depositscount"`
+	Epoch                      uint64   `db:"epoch" json:"epoch"`
+	Eth1dataBlockhash          HexBytes `db:"eth1data_blockhash" json:"eth1data_blockhash"`
+	Eth1dataDepositcount       uint64   `db:"eth1data_depositcount" json:"eth1data_depositcount"`
+	Eth1dataDepositroot        HexBytes `db:"eth1data_depositroot" json:"eth1data_depositroot"`
+	ExecBaseFeePerGas          *uint64  `db:"exec_base_fee_per_gas" json:"exec_base_fee_per_gas"`
+	ExecBlockHash              HexBytes `db:"exec_block_hash" json:"exec_block_hash"`
+	ExecBlockNumber            *uint64  `db:"exec_block_number" json:"exec_block_number"`
+	ExecExtraData              HexBytes `db:"exec_extra_data" json:"exec_extra_data"`
+	ExecFeeRecipient           HexBytes `db:"exec_fee_recipient" json:"exec_fee_recipient"`
+	ExecGasLimit               *uint64  `db:"exec_gas_limit" json:"exec_gas_limit"`
+	ExecGasUsed                *uint64  `db:"exec_gas_used" json:"exec_gas_used"`
+	ExecLogsBloom              HexBytes `db:"exec_logs_bloom" json:"exec_logs_bloom"`
+	ExecParentHash             HexBytes `db:"exec_parent_hash" json:"exec_parent_hash"`
+	ExecRandom                 HexBytes `db:"exec_random" json:"exec_random"`
+	ExecReceiptsRoot           HexBytes `db:"exec_receipts_root" json:"exec_receipts_root"`
+	ExecStateRoot              HexBytes `db:"exec_state_root" json:"exec_state_root"`
+	ExecTimestamp              *uint64  `db:"exec_timestamp" json:"exec_timestamp"`
+	ExecTransactionsCount      uint64   `db:"exec_transactions_count" json:"exec_transactions_count"`
+	Graffiti                   HexBytes `db:"graffiti" json:"graffiti"`
+	GraffitiText               *string  `db:"graffiti_text" json:"graffiti_text"`
+	Parentroot                 HexBytes `db:"parentroot" json:"parentroot"`
+	Proposer                   uint64   `db:"proposer" json:"proposer"`
+	Proposerslashingscount     uint64   `db:"proposerslashingscount" json:"proposerslashingscount"`
+	Randaoreveal               HexBytes `db:"randaoreveal" json:"randaoreveal"`
+	Signature                  HexBytes `db:"signature" json:"signature"`
+	Slot                       uint64   `db:"slot" json:"slot"`
+	Stateroot                  HexBytes `db:"stateroot" json:"stateroot"`
+	Status                     string   `db:"status" json:"status"`
+	SyncaggregateBits          HexBytes `db:"syncaggregate_bits" json:"syncaggregate_bits"`
+	SyncaggregateParticipation float64  `db:"syncaggregate_participation" json:"syncaggregate_participation"`
+	SyncaggregateSignature     HexBytes `db:"syncaggregate_signature" json:"syncaggregate_signature"`
+	Voluntaryexitscount        uint64   `db:"voluntaryexitscount" json:"voluntaryexitscount"`
+	WithdrawalCount            uint64   `db:"withdrawalcount" json:"withdrawalcount"`
 }
 
 type APISlotResponse struct {
-	Attestationscount          uint64  `json:"attestationscount"`
-	Attesterslashingscount     uint64  `json:"attesterslashingscount"`
-	Blockroot                  string  `json:"blockroot"`
-	Depositscount              uint64  `json:"depositscount"`
-	Epoch                      uint64  `json:"epoch"`
-	Eth1dataBlockhash          string  `json:"eth1data_blockhash"`
-	Eth1dataDepositcount       uint64  `json:"eth1data_depositcount"`
-	Eth1dataDepositroot        string  `json:"eth1data_depositroot"`
-	ExecBaseFeePerGas          uint64  `json:"exec_base_fee_per_gas" extensions:"x-nullable"`
-	ExecBlockHash              string  `json:"exec_block_hash" extensions:"x-nullable"`
-	ExecBlockNumber            uint64  `json:"exec_block_number" extensions:"x-nullable"`
-	ExecExtraData              string  `json:"exec_extra_data" extensions:"x-nullable"`
-	ExecFeeRecipient           string  `json:"exec_fee_recipient" extensions:"x-nullable"`
-	ExecGasLimit               uint64  `json:"exec_gas_limit" extensions:"x-nullable"`
-	ExecGasUsed                uint64  `json:"exec_gas_used" extensions:"x-nullable"`
-	ExecLogsBloom              string  `json:"exec_logs_bloom" extensions:"x-nullable"`
-	ExecParentHash             string  `json:"exec_parent_hash" extensions:"x-nullable"`
-	ExecRandom                 string  `json:"exec_random" extensions:"x-nullable"`
-	ExecReceiptsRoot           string  `json:"exec_receipts_root" extensions:"x-nullable"`
-	ExecStateRoot              string  `json:"exec_state_root" extensions:"x-nullable"`
-	ExecTimestamp              uint64  `json:"exec_timestamp" extensions:"x-nullable"`
-	ExecTransactionsCount      uint64  `json:"exec_transactions_count" extensions:"x-nullable"`
-	Graffiti                   string  `json:"graffiti"`
-	GraffitiText               string  `json:"graffiti_text"`
-	Parentroot                 string  `json:"parentroot"`
-	Proposer                   uint64  `json:"proposer"`
-	Proposerslashingscount     uint64  `json:"proposerslashingscount"`
-	Randaoreveal               string  `json:"randaoreveal"`
-	Signature                  string  `json:"signature"`
-	Slot                       uint64  `json:"slot"`
-	Stateroot                  string  `json:"stateroot"`
-	Status                     string  `json:"status"`
-	SyncaggregateBits          string  `json:"syncaggregate_bits"`
-	SyncaggregateParticipation float64 `json:"syncaggregate_participation"`
-	SyncaggregateSignature     string  `json:"syncaggregate_signature"`
-	Voluntaryexitscount        uint64  `json:"voluntaryexitscount"`
-	WithdrawalCount            uint64  `json:"withdrawalcount"`
+	APIBlockResponse
+	Votes *uint64 `db:"votes" json:"votes"` // number of attestation votes for the block, null if no attestation voted for it
 }
 
 type APIAttestationResponse struct {
-	Aggregationbits string  `json:"aggregationbits"`
-	Beaconblockroot string  `json:"beaconblockroot"`
-	BlockIndex      int64   `json:"block_index"`
-	BlockRoot       string  `json:"block_root"`
-	BlockSlot       int64   `json:"block_slot"`
-	Committeeindex  int64   `json:"committeeindex"`
-	Signature       string  `json:"signature"`
-	Slot            int64   `json:"slot"`
-	SourceEpoch     int64   `json:"source_epoch"`
-	SourceRoot      string  `json:"source_root"`
-	TargetEpoch     int64   `json:"target_epoch"`
-	TargetRoot      string  `json:"target_root"`
-	Validators      []int64 `json:"validators"`
+	Aggregationbits HexBytes      `db:"aggregationbits" json:"aggregationbits"`
+	Beaconblockroot HexBytes      `db:"beaconblockroot" json:"beaconblockroot"`
+	BlockIndex      uint64        `db:"block_index" json:"block_index"`
+	BlockRoot       HexBytes      `db:"block_root" json:"block_root"`
+	BlockSlot       uint64        `db:"block_slot" json:"block_slot"`
+	Committeeindex  uint64        `db:"committeeindex" json:"committeeindex"`
+	Signature       HexBytes      `db:"signature" json:"signature"`
+	Slot            uint64        `db:"slot" json:"slot"`
+	SourceEpoch     uint64        `db:"source_epoch" json:"source_epoch"`
+	SourceRoot      HexBytes      `db:"source_root" json:"source_root"`
+	TargetEpoch     uint64        `db:"target_epoch" json:"target_epoch"`
+	TargetRoot      HexBytes      `db:"target_root" json:"target_root"`
+	Validators      pq.Int64Array `db:"validators" json:"validators"`
 }
 
 type APIDepositResponse struct {
-	Amount                uint64 `json:"amount"`
-	BlockIndex            uint64 `json:"block_index"`
-	BlockRoot             string `json:"block_root"`
-	BlockSlot             uint64 `json:"block_slot"`
-	Proof                 string `json:"proof"`
-	Publickey             string `json:"publickey"`
-	Signature             string `json:"signature"`
-	Withdrawalcredentials string `json:"withdrawalcredentials"`
+	Amount                uint64   `db:"amount" json:"amount"`
+	BlockIndex            uint64   `db:"block_index" json:"block_index"`
+	BlockRoot             HexBytes `db:"block_root" json:"block_root"`
+	BlockSlot             uint64   `db:"block_slot" json:"block_slot"`
+	Proof                 *string  `db:"proof" json:"proof"` // postgres array literal of the hex encoded proof
+	Publickey             HexBytes `db:"publickey" json:"publickey"`
+	Signature             HexBytes `db:"signature" json:"signature"`
+	Withdrawalcredentials HexBytes `db:"withdrawalcredentials" json:"withdrawalcredentials"`
 }
 
 type APIAttesterSlashingResponse struct {
-	Attestation1_beaconblockroot string   `json:"attestation1_beaconblockroot"`
-	Attestation1_index           uint64   `json:"attestation1_index"`
-	Attestation1_indices         []uint64 `json:"attestation1_indices"`
-	Attestation1_signature       string   `json:"attestation1_signature"`
-	Attestation1_slot            uint64   `json:"attestation1_slot"`
-	Attestation1_source_epoch    uint64   `json:"attestation1_source_epoch"`
-	Attestation1_source_root     string   `json:"attestation1_source_root"`
-	Attestation1_target_epoch    uint64   `json:"attestation1_target_epoch"`
-	Attestation1_target_root     string   `json:"attestation1_target_root"`
-	Attestation2_beaconblockroot string   `json:"attestation2_beaconblockroot"`
-	Attestation2_index           uint64   `json:"attestation2_index"`
-	Attestation2_indices         []uint64 `json:"attestation2_indices"`
-	Attestation2_signature       string   `json:"attestation2_signature"`
-	Attestation2_slot            uint64   `json:"attestation2_slot"`
-	Attestation2_source_epoch    uint64   `json:"attestation2_source_epoch"`
-	Attestation2_source_root     string   `json:"attestation2_source_root"`
-	Attestation2_target_epoch    uint64   `json:"attestation2_target_epoch"`
-	Attestation2_target_root     string   `json:"attestation2_target_root"`
-	BlockIndex                   uint64   `json:"block_index"`
-	BlockRoot                    string   `json:"block_root"`
-	BlockSlot                    uint64   `json:"block_slot"`
+	Attestation1_beaconblockroot HexBytes      `db:"attestation1_beaconblockroot" json:"attestation1_beaconblockroot"`
+	Attestation1_index           uint64        `db:"attestation1_index" json:"attestation1_index"`
+	Attestation1_indices         pq.Int64Array `db:"attestation1_indices" json:"attestation1_indices"`
+	Attestation1_signature       HexBytes      `db:"attestation1_signature" json:"attestation1_signature"`
+	Attestation1_slot            uint64        `db:"attestation1_slot" json:"attestation1_slot"`
+	Attestation1_source_epoch    uint64        `db:"attestation1_source_epoch" json:"attestation1_source_epoch"`
+	Attestation1_source_root     HexBytes      `db:"attestation1_source_root" json:"attestation1_source_root"`
+	Attestation1_target_epoch    uint64        `db:"attestation1_target_epoch" json:"attestation1_target_epoch"`
+	Attestation1_target_root     HexBytes      `db:"attestation1_target_root" json:"attestation1_target_root"`
+	Attestation2_beaconblockroot HexBytes      `db:"attestation2_beaconblockroot" json:"attestation2_beaconblockroot"`
+	Attestation2_index           uint64        `db:"attestation2_index" json:"attestation2_index"`
+	Attestation2_indices         pq.Int64Array `db:"attestation2_indices" json:"attestation2_indices"`
+	Attestation2_signature       HexBytes      `db:"attestation2_signature" json:"attestation2_signature"`
+	Attestation2_slot            uint64        `db:"attestation2_slot" json:"attestation2_slot"`
+	Attestation2_source_epoch    uint64        `db:"attestation2_source_epoch" json:"attestation2_source_epoch"`
+	Attestation2_source_root     HexBytes      `db:"attestation2_source_root" json:"attestation2_source_root"`
+	Attestation2_target_epoch    uint64        `db:"attestation2_target_epoch" json:"attestation2_target_epoch"`
+	Attestation2_target_root     HexBytes      `db:"attestation2_target_root" json:"attestation2_target_root"`
+	BlockIndex                   uint64        `db:"block_index" json:"block_index"`
+	BlockRoot                    HexBytes      `db:"block_root" json:"block_root"`
+	BlockSlot                    uint64        `db:"block_slot" json:"block_slot"`
 }
 
 type APIProposerSlashingResponse struct {
-	BlockIndex        uint64 `json:"block_index"`
-	BlockRoot         string `json:"block_root"`
-	BlockSlot         uint64 `json:"block_slot"`
-	Header1Bodyroot   string `json:"header1_bodyroot"`
-	Header1Parentroot string `json:"header1_parentroot"`
-	Header1Signature  string `json:"header1_signature"`
-	Header1Slot       uint64 `json:"header1_slot"`
-	Header1Stateroot  string `json:"header1_stateroot"`
-	Header2Bodyroot   string `json:"header2_bodyroot"`
-	Header2Parentroot string `json:"header2_parentroot"`
-	Header2Signature  string `json:"header2_signature"`
-	Header2Slot       uint64 `json:"header2_slot"`
-	Header2Stateroot  string `json:"header2_stateroot"`
-	ProposerIndex     uint64 `json:"proposerindex"`
+	BlockIndex        uint64   `db:"block_index" json:"block_index"`
+	BlockRoot         HexBytes `db:"block_root" json:"block_root"`
+	BlockSlot         uint64   `db:"block_slot" json:"block_slot"`
+	Header1Bodyroot   HexBytes `db:"header1_bodyroot" json:"header1_bodyroot"`
+	Header1Parentroot HexBytes `db:"header1_parentroot" json:"header1_parentroot"`
+	Header1Signature  HexBytes `db:"header1_signature" json:"header1_signature"`
+	Header1Slot       uint64   `db:"header1_slot" json:"header1_slot"`
+	Header1Stateroot  HexBytes `db:"header1_stateroot" json:"header1_stateroot"`
+	Header2Bodyroot   HexBytes `db:"header2_bodyroot" json:"header2_bodyroot"`
+	Header2Parentroot HexBytes `db:"header2_parentroot" json:"header2_parentroot"`
+	Header2Signature  HexBytes `db:"header2_signature" json:"header2_signature"`
+	Header2Slot       uint64   `db:"header2_slot" json:"header2_slot"`
+	Header2Stateroot  HexBytes `db:"header2_stateroot" json:"header2_stateroot"`
+	ProposerIndex     uint64   `db:"proposerindex" json:"proposerindex"`
+}
+
+type APIReorgResponse struct {
+	Slot               uint64        `db:"slot" json:"slot"`
+	Depth              uint64        `db:"depth" json:"depth"`
+	CommonAncestorSlot uint64        `db:"common_ancestor_slot" json:"common_ancestor_slot"`
+	OldHeadRoot        HexBytes      `db:"old_head_root" json:"old_head_root"`
+	NewHeadRoot        HexBytes      `db:"new_head_root" json:"new_head_root"`
+	OldHeadProposer    uint64        `db:"old_head_proposer" json:"old_head_proposer"`
+	NewHeadProposer    uint64        `db:"new_head_proposer" json:"new_head_proposer"`
+	OrphanedSlots      pq.Int64Array `db:"orphaned_slots" json:"orphaned_slots"`
+	OrphanedProposers  pq.Int64Array `db:"orphaned_proposers" json:"orphaned_proposers"`
+	DetectedAt         UnixTime      `db:"detected_at" json:"detected_at"`
 }
 
 type APIVoluntaryExitResponse struct {
-	BlockIndex     uint64 `json:"block_index"`
-	BlockRoot      string `json:"block_root"`
-	BlockSlot      uint64 `json:"block_slot"`
-	Epoch          uint64 `json:"epoch"`
-	Signature      string `json:"signature"`
-	ValidatorIndex uint64 `json:"validatorindex"`
+	BlockIndex     uint64   `db:"block_index" json:"block_index"`
+	BlockRoot      HexBytes `db:"block_root" json:"block_root"`
+	BlockSlot      uint64   `db:"block_slot" json:"block_slot"`
+	Epoch          uint64   `db:"epoch" json:"epoch"`
+	Signature      HexBytes `db:"signature" json:"signature"`
+	ValidatorIndex uint64   `db:"validatorindex" json:"validatorindex"`
+}
+
+type APISlotWithdrawalResponse struct {
+	Address        HexBytes `db:"address" json:"address"`
+	Amount         uint64   `db:"amount" json:"amount"`
+	BlockSlot      uint64   `db:"block_slot" json:"block_slot"`
+	Index          uint64   `db:"withdrawalindex" json:"withdrawalindex"`
+	ValidatorIndex uint64   `db:"validatorindex" json:"validatorindex"`
+}
+
+// APIDepositRequestResponse is an execution layer deposit request (EIP-6110), the validator index is null until the deposit has been processed
+type APIDepositRequestResponse struct {
+	Amount                uint64   `db:"amount" json:"amount"`
+	BlockSlot             uint64   `db:"block_slot" json:"block_slot"`
+	DepositIndex          uint64   `db:"deposit_index" json:"deposit_index"`
+	Pubkey                HexBytes `db:"pubkey" json:"pubkey"`
+	RequestIndex          uint64   `db:"request_index" json:"request_index"`
+	Signature             HexBytes `db:"signature" json:"signature"`
+	ValidatorIndex        *uint64  `db:"validatorindex" json:"validatorindex"`
+	WithdrawalCredentials HexBytes `db:"withdrawal_credentials" json:"withdrawal_credentials"`
+}
+
+// APIWithdrawalRequestResponse is an execution layer withdrawal request (EIP-7002), an amount of 0 requests a full exit of the validator
+type APIWithdrawalRequestResponse struct {
+	Amount          uint64   `db:"amount" json:"amount"`
+	BlockSlot       uint64   `db:"block_slot" json:"block_slot"`
+	RequestIndex    uint64   `db:"request_index" json:"request_index"`
+	SourceAddress   HexBytes `db:"source_address" json:"source_address"`
+	ValidatorIndex  *uint64  `db:"validatorindex" json:"validatorindex"`
+	ValidatorPubkey HexBytes `db:"validator_pubkey" json:"validator_pubkey"`
+}
+
+// APIConsolidationRequestResponse is an execution layer consolidation request (EIP-7251)
+type APIConsolidationRequestResponse struct {
+	BlockSlot            uint64   `db:"block_slot" json:"block_slot"`
+	RequestIndex         uint64   `db:"request_index" json:"request_index"`
+	SourceAddress        HexBytes `db:"source_address" json:"source_address"`
+	SourcePubkey         HexBytes `db:"source_pubkey" json:"source_pubkey"`
+	SourceValidatorIndex *uint64  `db:"source_validatorindex" json:"source_validatorindex"`
+	TargetPubkey         HexBytes `db:"target_pubkey" json:"target_pubkey"`
+	TargetValidatorIndex *uint64  `db:"target_validatorindex" json:"target_validatorindex"`
 }
 
 type APISyncCommitteeResponse struct {
-	EndEpoch   uint64   `json:"end_epoch"`
-	Period     uint64   `json:"period"`
-	StartEpoch uint64   `json:"start_epoch"`
-	Validators []uint64 `json:"validators"`
+	EndEpoch   uint64        `db:"end_epoch" json:"end_epoch"`
+	Period     uint64        `db:"period" json:"period"`
+	StartEpoch uint64        `db:"start_epoch" json:"start_epoch"`
+	Validators pq.Int64Array `db:"validators" json:"validators"`
+}
+
+type ApiEthStoreDayResponse struct {
+	Apr                       float64    `db:"apr" json:"apr"`
+	AvgApr31d                 float64    `db:"avgapr31d" json:"avgapr31d"`
+	AvgApr7d                  float64    `db:"avgapr7d" json:"avgapr7d"`
+	AvgConsensusRewards31dWei NumericInt `db:"avgconsensus_rewards31d_wei" json:"avgconsensus_rewards31d_wei"`
+	AvgConsensusRewards7dWei  NumericInt `db:"avgconsensus_rewards7d_wei" json:"avgconsensus_rewards7d_wei"`
+	AvgTxFees31dWei           NumericInt `db:"avgtx_fees31d_wei" json:"avgtx_fees31d_wei"`
+	AvgTxFees7dWei            NumericInt `db:"avgtx_fees7d_wei" json:"avgtx_fees7d_wei"`
+	ClApr                     float64    `db:"cl_apr" json:"cl_apr"`
+	ClAvgApr31d               float64    `db:"cl_avgapr31d" json:"cl_avgapr31d"`
+	ClAvgApr7d                float64    `db:"cl_avgapr7d" json:"cl_avgapr7d"`
+	ConsensusRewardsSumWei    NumericInt `db:"consensus_rewards_sum_wei" json:"consensus_rewards_sum_wei"`
+	Day                       uint64     `db:"day" json:"day"`
+	DayEnd                    time.Time  `db:"-" json:"day_end"`
+	DayStart                  time.Time  `db:"-" json:"day_start"`
+	DepositsSumWei            NumericInt `db:"deposits_sum_wei" json:"deposits_sum_wei"`
+	EffectiveBalancesSumWei   NumericInt `db:"effective_balances_sum_wei" json:"effective_balances_sum_wei"`
+	ElApr                     float64    `db:"el_apr" json:"el_apr"`
+	ElAvgApr31d               float64    `db:"el_avgapr31d" json:"el_avgapr31d"`
+	ElAvgApr7d                float64    `db:"el_avgapr7d" json:"el_avgapr7d"`
+	EndBalancesSumWei         NumericInt `db:"end_balances_sum_wei" json:"end_balances_sum_wei"`
+	StartBalancesSumWei       NumericInt `db:"start_balances_sum_wei" json:"start_balances_sum_wei"`
+	TotalRewardsWei           NumericInt `db:"total_rewards_wei" json:"total_rewards_wei"`
+	TxFeesSumWei              NumericInt `db:"tx_fees_sum_wei" json:"tx_fees_sum_wei"`
 }
 
 type APIRocketpoolStatsResponse struct {
-	ClaimIntervalTime      string  `json:"claim_interval_time"`
-	ClaimIntervalTimeStart int64   `json:"claim_interval_time_start"`
-	CurrentNodeDemand      float64 `json:"current_node_demand"`
-	CurrentNodeFee         float64 `json:"current_node_fee"`
-	EffectiveRPLStaked     float64 `json:"effective_rpl_staked"`
-	MinipoolCount          int64   `json:"minipool_count"`
-	NodeCount              int64   `json:"node_count"`
-	NodeOperatorRewards    float64 `json:"node_operator_rewards"`
-	OdaoMemberCount        int64   `json:"odao_member_count"`
-	RethApr                float64 `json:"reth_apr"`
-	RethExchangeRate       float64 `json:"reth_exchange_rate"`
-	RethSupply             float64 `json:"reth_supply"`
-	RplPrice               int64   `json:"rpl_price"`
-	TotalEthBalance        float64 `json:"total_eth_balance"`
-	TotalEthStaking        float64 `json:"total_eth_staking"`
+	ClaimIntervalTime      string     `db:"claim_interval_time" json:"claim_interval_time"`
+	ClaimIntervalTimeStart UnixTime   `db:"claim_interval_time_start" json:"claim_interval_time_start"`
+	CurrentNodeDemand      NumericInt `db:"current_node_demand" json:"current_node_demand"`
+	CurrentNodeFee         float64    `db:"current_node_fee" json:"current_node_fee"`
+	EffectiveRPLStaked     NumericInt `db:"effective_rpl_staked" json:"effective_rpl_staked"`
+	MinipoolCount          NumericInt `db:"minipool_count" json:"minipool_count"`
+	NodeCount              NumericInt `db:"node_count" json:"node_count"`
+	NodeOperatorRewards    NumericInt `db:"node_operator_rewards" json:"node_operator_rewards"`
+	OdaoMemberCount        NumericInt `db:"odao_member_count" json:"odao_member_count"`
+	RethApr                float64    `db:"reth_apr" json:"reth_apr"`
+	RethExchangeRate       float64    `db:"reth_exchange_rate" json:"reth_exchange_rate"`
+	RethSupply             NumericInt `db:"reth_supply" json:"reth_supply"`
+	RplPrice               NumericInt `db:"rpl_price" json:"rpl_price"`
+	TotalEthBalance        NumericInt `db:"total_eth_balance" json:"total_eth_balance"`
+	TotalEthStaking        NumericInt `db:"total_eth_staking" json:"total_eth_staking"`
 }
 
+// ApiRocketpoolValidatorResponse is a rocketpool minipool, the node columns are null if the node of the minipool is not known yet
 type ApiRocketpoolValidatorResponse struct {
-	ClaimedSmoothingPool   float64 `json:"claimed_smoothing_pool"`
-	Index                  uint64  `json:"index"`
-	MinipoolAddress        string  `json:"minipool_address"`
-	MinipoolDepositType    string  `json:"minipool_deposit_type"`
-	MinipoolNodeFee        float64 `json:"minipool_node_fee"`
-	MinipoolStatus         string  `json:"minipool_status"`
-	MinipoolStatusTime     uint64  `json:"minipool_status_time"`
-	NodeAddress            string  `json:"node_address"`
-	NodeMaxRplStake        float64 `json:"node_max_rpl_stake"`
-	NodeMinRplStake        float64 `json:"node_min_rpl_stake"`
-	NodeRplStake           float64 `json:"node_rpl_stake"`
-	NodeTimezoneLocation   string  `json:"node_timezone_location"`
-	PenaltyCount           uint64  `json:"penalty_count"`
-	RplCumulativeRewards   float64 `json:"rpl_cumulative_rewards"`
-	SmoothingPoolOptedIn   bool    `json:"smoothing_pool_opted_in"`
-	UnclaimedRplRewards    float64 `json:"unclaimed_rpl_rewards"`
-	UnclaimedSmoothingPool float64 `json:"unclaimed_smoothing_pool"`
+	ClaimedSmoothingPool   *NumericInt `db:"claimed_smoothing_pool" json:"claimed_smoothing_pool"`
+	EffectiveRplStake      NumericInt  `db:"effective_rpl_stake" json:"effective_rpl_stake"`
+	Index                  uint64      `db:"index" json:"index"`
+	IsVacant               bool        `db:"is_vacant" json:"is_vacant"`
+	MinipoolAddress        HexBytes    `db:"minipool_address" json:"minipool_address"`
+	MinipoolDepositType    string      `db:"minipool_deposit_type" json:"minipool_deposit_type"`
+	MinipoolNodeFee        float64     `db:"minipool_node_fee" json:"minipool_node_fee"`
+	MinipoolStatus         string      `db:"minipool_status" json:"minipool_status"`
+	MinipoolStatusTime     *UnixTime   `db:"minipool_status_time" json:"minipool_status_time"`
+	NodeAddress            HexBytes    `db:"node_address" json:"node_address"`
+	NodeDepositBalance     NumericInt  `db:"node_deposit_balance" json:"node_deposit_balance"`
+	NodeDepositCredit      NumericInt  `db:"node_deposit_credit" json:"node_deposit_credit"`
+	NodeMaxRplStake        *NumericInt `db:"node_max_rpl_stake" json:"node_max_rpl_stake"`
+	NodeMinRplStake        *NumericInt `db:"node_min_rpl_stake" json:"node_min_rpl_stake"`
+	NodeRefundBalance      NumericInt  `db:"node_refund_balance" json:"node_refund_balance"`
+	NodeRplStake           *NumericInt `db:"node_rpl_stake" json:"node_rpl_stake"`
+	NodeTimezoneLocation   *string     `db:"node_timezone_location" json:"node_timezone_location"`
+	PenaltyCount           NumericInt  `db:"penalty_count" json:"penalty_count"`
+	RplCumulativeRewards   *NumericInt `db:"rpl_cumulative_rewards" json:"rpl_cumulative_rewards"`
+	SmoothingPoolOptedIn   bool        `db:"smoothing_pool_opted_in" json:"smoothing_pool_opted_in"`
+	UnclaimedRplRewards    *NumericInt `db:"unclaimed_rpl_rewards" json:"unclaimed_rpl_rewards"`
+	UnclaimedSmoothingPool *NumericInt `db:"unclaimed_smoothing_pool" json:"unclaimed_smoothing_pool"`
+	UserDepositBalance     NumericInt  `db:"user_deposit_balance" json:"user_deposit_balance"`
+	Version                uint64      `db:"version" json:"version"`
 }
 
 type ApiValidatorQueueResponse struct {
-	BeaconchainEntering uint64 `json:"beaconchain_entering"`
-	BeaconchainExiting  uint64 `json:"beaconchain_exiting"`
-	ValidatorsCount     uint64 `json:"validators_count"`
+	BeaconchainEntering uint64 `db:"beaconchain_entering" json:"beaconchain_entering"`
+	BeaconchainExiting  uint64 `db:"beaconchain_exiting" json:"beaconchain_exiting"`
+	ValidatorsCount     uint64 `db:"validatorscount" json:"validatorscount"`
 }
 
 type APIValidatorResponse struct {
@@ -511,38 +751,39 @@ type APIValidatorResponse struct {
 }
 
 type ApiValidatorDailyStatsResponse struct {
-	ValidatorIndex        uint64    `json:"validatorindex"`
-	AttesterSlashings     uint64    `json:"attester_slashings"`
-	Day                   uint64    `json:"day"`
-	DayStart              time.Time `json:"day_start"`
-	DayEnd                time.Time `json:"day_end"`
-	Deposits              uint64    `json:"deposits"`
-	DepositsAmount        uint64    `json:"deposits_amount"`
-	Withdrawals           uint64    `json:"withdrawals"`
-	WithdrawalsAmount     uint64    `json:"withdrawals_amount"`
-	EndBalance            uint64    `json:"end_balance"`
-	EndEffectiveBalance   uint64    `json:"end_effective_balance"`
-	MaxBalance            uint64    `json:"max_balance"`
-	MaxEffectiveBalance   uint64    `json:"max_effective_balance"`
-	MinBalance            uint64    `json:"min_balance"`
-	MinEffectiveBalance   uint64    `json:"min_effective_balance"`
-	MissedAttestations    uint64    `json:"missed_attestations"`
-	MissedBlocks          uint64    `json:"missed_blocks"`
-	MissedSync            uint64    `json:"missed_sync"`
-	OrphanedAttestations  uint64    `json:"orphaned_attestations"`
-	OrphanedBlocks        uint64    `json:"orphaned_blocks"`
-	OrphanedSync          uint64    `json:"orphaned_sync"`
-	ParticipatedSync      uint64    `json:"participated_sync"`
-	ProposedBlocks        uint64    `json:"proposed_blocks"`
-	ProposerSlashings     uint64    `json:"proposer_slashings"`
-	StartBalance          uint64    `json:"start_balance"`
-	StartEffectiveBalance uint64    `json:"start_effective_balance"`
+	ValidatorIndex        uint64    `db:"validatorindex" json:"validatorindex"`
+	AttesterSlashings     uint64    `db:"attester_slashings" json:"attester_slashings"`
+	Day                   uint64    `db:"day" json:"day"`
+	DayStart              time.Time `db:"-" json:"day_start"`
+	DayEnd                time.Time `db:"-" json:"day_end"`
+	Deposits              uint64    `db:"deposits" json:"deposits"`
+	DepositsAmount        uint64    `db:"deposits_amount" json:"deposits_amount"`
+	Withdrawals           uint64    `db:"withdrawals" json:"withdrawals"`
+	WithdrawalsAmount     uint64    `db:"withdrawals_amount" json:"withdrawals_amount"`
+	EndBalance            *uint64   `db:"end_balance" json:"end_balance"`
+	EndEffectiveBalance   *uint64   `db:"end_effective_balance" json:"end_effective_balance"`
+	MaxBalance            *uint64   `db:"max_balance" json:"max_balance"`
+	MaxEffectiveBalance   *uint64   `db:"max_effective_balance" json:"max_effective_balance"`
+	MinBalance            *uint64   `db:"min_balance" json:"min_balance"`
+	MinEffectiveBalance   *uint64   `db:"min_effective_balance" json:"min_effective_balance"`
+	MissedAttestations    uint64    `db:"missed_attestations" json:"missed_attestations"`
+	MissedBlocks          uint64    `db:"missed_blocks" json:"missed_blocks"`
+	MissedSync            uint64    `db:"missed_sync" json:"missed_sync"`
+	OrphanedAttestations  uint64    `db:"orphaned_attestations" json:"orphaned_attestations"`
+	OrphanedBlocks        uint64    `db:"orphaned_blocks" json:"orphaned_blocks"`
+	OrphanedSync          uint64    `db:"orphaned_sync" json:"orphaned_sync"`
+	ParticipatedSync      uint64    `db:"participated_sync" json:"participated_sync"`
+	ProposedBlocks        uint64    `db:"proposed_blocks" json:"proposed_blocks"`
+	ProposerSlashings     uint64    `db:"proposer_slashings" json:"proposer_slashings"`
+	StartBalance          *uint64   `db:"start_balance" json:"start_balance"`
+	StartEffectiveBalance *uint64   `db:"start_effective_balance" json:"start_effective_balance"`
 }
 
+// ApiValidatorEth1Response is a validator deposit of an eth1 address, the validator index is null until the deposit has been processed
 type ApiValidatorEth1Response struct {
-	PublicKey      string `json:"public_key"`
-	ValidSignature bool   `json:"valid_signature"`
-	ValidatorIndex uint64 `json:"validator_index"`
+	PublicKey      HexBytes `db:"publickey" json:"publickey"`
+	ValidSignature bool     `db:"valid_signature" json:"valid_signature"`
+	ValidatorIndex *uint64  `db:"validatorindex" json:"validatorindex"`
 }
 
 type ApiValidatorIncomeHistoryResponse struct {
@@ -605,13 +846,20 @@ type ApiValidatorBlsChangeResponse struct {
 }
 
 type ApiValidatorPerformanceResponse struct {
-	Balance         uint64 `json:"balance"`
-	Performance1d   uint64 `json:"performance1d"`
-	Performance31d  uint64 `json:"performance31d"`
-	Performance365d uint64 `json:"performance365d"`
-	Performance7d   uint64 `json:"performance7d"`
-	Rank7d          uint64 `json:"rank7d"`
-	Validatorindex  uint64 `json:"validatorindex"`
+	Balance          uint64 `db:"balance" json:"balance"`
+	Performance1d    int64  `db:"performance1d" json:"performance1d"`
+	Performance31d   int64  `db:"performance31d" json:"performance31d"`
+	Performance365d  int64  `db:"performance365d" json:"performance365d"`
+	Performance7d    int64  `db:"performance7d" json:"performance7d"`
+	PerformanceTotal int64  `db:"performancetotal" json:"performancetotal"`
+	Rank7d           uint64 `db:"rank7d" json:"rank7d"`
+	Validatorindex   uint64 `db:"validatorindex" json:"validatorindex"`
+}
+
+// ApiValidatorCurrentPerformanceResponse adds the income of the current day to the performance of a validator
+type ApiValidatorCurrentPerformanceResponse struct {
+	ApiValidatorPerformanceResponse
+	PerformanceToday int64 `json:"performancetoday"`
 }
 
 type ApiValidatorExecutionPerformanceResponse struct {
@@ -622,19 +870,19 @@ type ApiValidatorExecutionPerformanceResponse struct {
 }
 
 type ApiValidatorDepositsResponse struct {
-	Amount                uint64 `json:"amount"`
-	BlockNumber           uint64 `json:"block_number"`
-	BlockTs               uint64 `json:"block_ts"`
-	FromAddress           string `json:"from_address"`
-	MerkletreeIndex       string `json:"merkletree_index"`
-	Publickey             string `json:"publickey"`
-	Removed               bool   `json:"removed"`
-	Signature             string `json:"signature"`
-	TxHash                string `json:"tx_hash"`
-	TxIndex               uint64 `json:"tx_index"`
-	TxInput               string `json:"tx_input"`
-	ValidSignature        bool   `json:"valid_signature"`
-	WithdrawalCredentials string `json:"withdrawal_credentials"`
+	Amount                uint64   `db:"amount" json:"amount"`
+	BlockNumber           uint64   `db:"block_number" json:"block_number"`
+	BlockTs               UnixTime `db:"block_ts" json:"block_ts"`
+	FromAddress           HexBytes `db:"from_address" json:"from_address"`
+	MerkletreeIndex       HexBytes `db:"merkletree_index" json:"merkletree_index"`
+	Publickey             HexBytes `db:"publickey" json:"publickey"`
+	Removed               bool     `db:"removed" json:"removed"`
+	Signature             HexBytes `db:"signature" json:"signature"`
+	TxHash                HexBytes `db:"tx_hash" json:"tx_hash"`
+	TxIndex               uint64   `db:"tx_index" json:"tx_index"`
+	TxInput               HexBytes `db:"tx_input" json:"tx_input"`
+	ValidSignature        bool     `db:"valid_signature" json:"valid_signature"`
+	WithdrawalCredentials HexBytes `db:"withdrawal_credentials" json:"withdrawal_credentials"`
 }
 
 type ApiValidatorAttestationsResponse struct {
@@ -649,44 +897,60 @@ type ApiValidatorAttestationsResponse struct {
 	WeekEnd        time.Time `json:"week_end"`
 }
 
-// convert this json object to a golang struct called ApiValidatorProposalsResponse
-type ApiValidatorProposalsResponse struct {
-	Attestationscount          uint64  `db:"attestationscount" json:"attestationscount"`
-	Attesterslashingscount     uint64  `db:"attesterslashingscount" json:"attesterslashingscount"`
-	Blockroot                  string  `db:"blockroot" json:"blockroot"`
-	Depositscount              uint64  `db:"depositscount" json:"depositscount"`
-	Epoch                      uint64  `db:"epoch" json:"epoch"`
-	Eth1dataBlockhash          string  `db:"eth1data_blockhash" json:"eth1data_blockhash"`
-	Eth1dataDepositcount       uint64  `db:"eth1data_depositcount" json:"eth1data_depositcount"`
-	Eth1dataDepositroot        string  `db:"eth1data_depositroot" json:"eth1data_depositroot"`
-	ExecBaseFeePerGas          *uint64 `db:"exec_base_fee_per_gas" json:"exec_base_fee_per_gas,omitempty"`
-	ExecBlockHash              *string `db:"exec_block_hash" json:"exec_block_hash,omitempty"`
-	ExecBlockNumber            *uint64 `db:"exec_block_number" json:"exec_block_number,omitempty"`
-	ExecExtra_data             *string `db:"exec_extra_data" json:"exec_extra_data,omitempty"`
-	ExecFeeRecipient           *string `db:"exec_fee_recipient" json:"exec_fee_recipient,omitempty"`
-	ExecGasLimit               *uint64 `db:"exec_gas_limit" json:"exec_gas_limit,omitempty"`
-	ExecGasUsed                *uint64 `db:"exec_gas_used" json:"exec_gas_used,omitempty"`
-	ExecLogsBloom              *string `db:"exec_logs_bloom" json:"exec_logs_bloom,omitempty"`
-	ExecParentHash             *string `db:"exec_parent_hash" json:"exec_parent_hash,omitempty"`
-	ExecRandom                 *string `db:"exec_random" json:"exec_random,omitempty"`
-	ExecReceiptsRoot           *string `db:"exec_receipts_root" json:"exec_receipts_root,omitempty"`
-	ExecStateRoot              *string `db:"exec_state_root" json:"exec_state_root,omitempty"`
-	ExecTimestamp              *uint64 `db:"exec_timestamp" json:"exec_timestamp,omitempty"`
-	ExecTransactionsCount      *uint64 `db:"exec_transactions_count" json:"exec_transactions_count,omitempty"`
-	Graffiti                   string  `db:"graffiti" json:"graffiti"`
-	GraffitiText               string  `db:"graffiti_text" json:"graffiti_text"`
-	Parentroot                 string  `db:"parentroot" json:"parentroot"`
-	Proposer                   uint64  `db:"proposer" json:"proposer"`
-	Proposerslashingscount     uint64  `db:"proposerslashingscount" json:"proposerslashingscount"`
-	Randaoreveal               string  `db:"randaoreveal" json:"randaoreveal"`
-	Signature                  string  `db:"signature" json:"signature"`
-	Slot                       uint64  `db:"slot" json:"slot"`
-	Stateroot                  string  `db:"stateroot" json:"stateroot"`
-	Status                     string  `db:"status" json:"status"`
-	SyncaggregateBits          string  `db:"syncaggregate_bits" json:"syncaggregate_bits"`
-	SyncaggregateParticipation float64 `db:"syncaggregate_participation" json:"syncaggregate_participation"`
-	SyncaggregateSignature     string  `db:"syncaggregate_signature" json:"syncaggregate_signature"`
-	Voluntaryexitscount        uint64  `db:"voluntaryexitscount" json:"voluntaryexitscount"`
+type ApiGraffitiwallResponse struct {
+	Color     string `db:"color" json:"color"`
+	Slot      uint64 `db:"slot" json:"slot"`
+	Validator uint64 `db:"validator" json:"validator"`
+	X         uint64 `db:"x" json:"x"`
+	Y         uint64 `db:"y" json:"y"`
+}
+
+// ApiDashboardValidatorResponse is a validator of the app dashboard. The balances are only set if the
+// validator has a balance in the latest epoch, in that case the performance includes the current day.
+type ApiDashboardValidatorResponse struct {
+	ActivationEligibilityEpoch uint64   `db:"activationeligibilityepoch" json:"activationeligibilityepoch"`
+	ActivationEpoch            uint64   `db:"activationepoch" json:"activationepoch"`
+	Balance                    *uint64  `db:"-" json:"balance,omitempty"`
+	EffectiveBalance           *uint64  `db:"-" json:"effectivebalance,omitempty"`
+	ExitEpoch                  uint64   `db:"exitepoch" json:"exitepoch"`
+	LastAttestationSlot        uint64   `db:"-" json:"lastattestationslot"`
+	Name                       string   `db:"name" json:"name"`
+	Performance1d              int64    `db:"performance1d" json:"performance1d"`
+	Performance31d             int64    `db:"performance31d" json:"performance31d"`
+	Performance365d            int64    `db:"performance365d" json:"performance365d"`
+	Performance7d              int64    `db:"performance7d" json:"performance7d"`
+	PerformanceTotal           int64    `db:"performancetotal" json:"performancetotal"`
+	Pubkey                     HexBytes `db:"pubkey" json:"pubkey"`
+	Rank7d                     uint64   `db:"rank7d" json:"rank7d"`
+	RankPercentage             *float64 `db:"rankpercentage" json:"rankpercentage"`
+	Slashed                    bool     `db:"slashed" json:"slashed"`
+	Status                     string   `db:"status" json:"status"`
+	ValidatorIndex             uint64   `db:"validatorindex" json:"validatorindex"`
+	WithdrawableEpoch          uint64   `db:"withdrawableepoch" json:"withdrawableepoch"`
+	WithdrawalCredentials      HexBytes `db:"withdrawalcredentials" json:"withdrawalcredentials"`
+}
+
+// ApiWidgetValidatorResponse is a validator of the mobile widget. The effective balance is only set if the
+// validator has a balance in the latest epoch, in that case the performance includes the current day.
+type ApiWidgetValidatorResponse struct {
+	ActivationEligibilityEpoch uint64   `db:"activationeligibilityepoch" json:"activationeligibilityepoch"`
+	ActivationEpoch            uint64   `db:"activationepoch" json:"activationepoch"`
+	Balance                    uint64   `db:"balance" json:"balance"`
+	EffectiveBalance           *uint64  `db:"-" json:"effectivebalance,omitempty"`
+	ExitEpoch                  uint64   `db:"exitepoch" json:"exitepoch"`
+	LastAttestationSlot        uint64   `db:"-" json:"lastattestationslot"`
+	MinipoolDeposit            *float64 `db:"minipool_deposit" json:"minipool_deposit"`
+	MinipoolNodeFee            *float64 `db:"minipool_node_fee" json:"minipool_node_fee"`
+	Performance1d              int64    `db:"performance1d" json:"performance1d"`
+	Performance31d             int64    `db:"performance31d" json:"performance31d"`
+	Performance365d            int64    `db:"performance365d" json:"performance365d"`
+	Performance7d              int64    `db:"performance7d" json:"performance7d"`
+	PerformanceTotal           int64    `db:"performancetotal" json:"performancetotal"`
+	Pubkey                     HexBytes `db:"pubkey" json:"pubkey"`
+	Rank7d                     uint64   `db:"rank7d" json:"rank7d"`
+	Slashed                    bool     `db:"slashed" json:"slashed"`
+	Status                     string   `db:"status" json:"status"`
+	ValidatorIndex             uint64   `db:"validatorindex" json:"validatorindex"`
 }
 
 type EnsDomainResponse struct {
@@ -700,3 +964,81 @@ type ApiProposalLuckResponse struct {
 	NextProposalEstimateTs  *int64   `json:"next_proposal_estimate_ts"` // The estimated timestamp of the next proposal
 	TimeFrameName           *string  `json:"time_frame_name"`           // The timeframe for which the luck is calculated
 }
+
+// HexBytes is a bytea column of an api response. It is encoded as 0x prefixed hex string, empty values are encoded as null.
+type HexBytes []byte
+
+func (b *HexBytes) Scan(value interface{}) error {
+	if value == nil {
+		*b = nil
+		return nil
+	}
+	v, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	*b = append(HexBytes{}, v...)
+	return nil
+}
+
+func (b HexBytes) MarshalJSON() ([]byte, error) {
+	if len(b) == 0 {
+		return []byte("null"), nil
+	}
+	return json.Marshal("0x" + hex.EncodeToString(b))
+}
+
+// NumericInt is a numeric column of an api response. It is encoded as json number, fractional digits are truncated.
+type NumericInt struct {
+	Int *big.Int
+}
+
+func (n *NumericInt) Scan(value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		n.Int = nil
+		return nil
+	case int64:
+		n.Int = big.NewInt(v)
+		return nil
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return errors.Errorf("unsupported numeric value of type %T", value)
+	}
+	s, _, _ = strings.Cut(s, ".")
+	i, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return errors.Errorf("invalid numeric value %q", s)
+	}
+	n.Int = i
+	return nil
+}
+
+func (n NumericInt) MarshalJSON() ([]byte, error) {
+	if n.Int == nil {
+		return []byte("null"), nil
+	}
+	return []byte(n.Int.String()), nil
+}
+
+// UnixTime is a timestamp column of an api response. It is encoded as unix timestamp in seconds.
+type UnixTime struct {
+	time.Time
+}
+
+func (t *UnixTime) Scan(value interface{}) error {
+	v, ok := value.(time.Time)
+	if !ok {
+		return errors.New("type assertion to time.Time failed")
+	}
+	t.Time = v
+	return nil
+}
+
+func (t UnixTime) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(t.Unix(), 10)), nil
+}
@@ -28,6 +28,8 @@ type ClChainConfig struct {
 	CappellaForkEpoch    uint64 `yaml:"CAPELLA_FORK_EPOCH"`
 	DenebForkVersion     string `yaml:"DENEB_FORK_VERSION"`
 	DenebForkEpoch       uint64 `yaml:"DENEB_FORK_EPOCH"`
+	ElectraForkVersion   string `yaml:"ELECTRA_FORK_VERSION"`
+	ElectraForkEpoch     uint64 `yaml:"ELECTRA_FORK_EPOCH"`
 	Eip6110ForkVersion   string `yaml:"EIP6110_FORK_VERSION"`
 	Eip6110ForkEpoch     uint64 `yaml:"EIP6110_FORK_EPOCH"`
 	Eip7002ForkVersion   string `yaml:"EIP7002_FORK_VERSION"`
@@ -87,6 +89,8 @@ type ClChainConfig struct {
 	MinDepositAmount               uint64 `yaml:"MIN_DEPOSIT_AMOUNT"`
 	MaxEffectiveBalance            uint64 `yaml:"MAX_EFFECTIVE_BALANCE"`
 	EffectiveBalanceIncrement      uint64 `yaml:"EFFECTIVE_BALANCE_INCREMENT"`
+	MinActivationBalance           uint64 `yaml:"MIN_ACTIVATION_BALANCE"`
+	MaxEffectiveBalanceElectra     uint64 `yaml:"MAX_EFFECTIVE_BALANCE_ELECTRA"`
 	MinAttestationInclusionDelay   uint64 `yaml:"MIN_ATTESTATION_INCLUSION_DELAY"`
 	SlotsPerEpoch                  uint64 `yaml:"SLOTS_PER_EPOCH"`
 	MinSeedLookahead               uint64 `yaml:"MIN_SEED_LOOKAHEAD"`
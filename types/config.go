@@ -36,6 +36,11 @@ type Config struct {
 		EmulatorPort        int    `yaml:"emulatorPort" envconfig:"BIGTABLE_EMULATOR_PORT"`
 		EmulatorHost        string `yaml:"emulatorHost" envconfig:"BIGTABLE_EMULATOR_HOST"`
 		V2SchemaCutOffEpoch uint64 `yaml:"v2SchemaCutOffEpoch" envconfig:"BIGTABLE_V2_SCHEMA_CUTT_OFF_EPOCH"`
+		Backend             string `yaml:"backend" envconfig:"BIGTABLE_BACKEND"` // bigtable (default) or postgres
+		Retention           struct {
+			Balances     ValidatorHistoryRetention `yaml:"balances" envconfig:"BIGTABLE_RETENTION_BALANCES"`
+			Attestations ValidatorHistoryRetention `yaml:"attestations" envconfig:"BIGTABLE_RETENTION_ATTESTATIONS"`
+		} `yaml:"retention"`
 	} `yaml:"bigtable"`
 	BlobIndexer struct {
 		S3 struct {
@@ -72,6 +77,12 @@ type Config struct {
 			Host     string `yaml:"host" envconfig:"INDEXER_NODE_HOST"`
 			Type     string `yaml:"type" envconfig:"INDEXER_NODE_TYPE"`
 			PageSize int32  `yaml:"pageSize" envconfig:"INDEXER_NODE_PAGE_SIZE"`
+			// Endpoints is an optional list of beacon node urls, if set the exporter fails over between them
+			Endpoints []string `yaml:"endpoints" envconfig:"INDEXER_NODE_ENDPOINTS"`
+			// MaxSyncDistance defaults to 2 slots if unset, 0 only uses fully synced nodes
+			MaxSyncDistance *uint64 `yaml:"maxSyncDistance" envconfig:"INDEXER_NODE_MAX_SYNC_DISTANCE"`
+			// DisableSSZ forces json encoded requests for blocks and states, by default ssz is used if the node supports it
+			DisableSSZ bool `yaml:"disableSSZ" envconfig:"INDEXER_NODE_DISABLE_SSZ"`
 		} `yaml:"node"`
 		Eth1DepositContractFirstBlock uint64 `yaml:"eth1DepositContractFirstBlock" envconfig:"INDEXER_ETH1_DEPOSIT_CONTRACT_FIRST_BLOCK"`
 		PubKeyTagsExporter            struct {
@@ -172,6 +183,10 @@ type Config struct {
 		JwtValidityInMinutes                 int           `yaml:"jwtValidityInMinutes" envconfig:"FRONTEND_JWT_VALIDITY_INMINUTES"`
 		MaxMailsPerEmailPerDay               int           `yaml:"maxMailsPerEmailPerDay" envconfig:"FRONTEND_MAX_MAIL_PER_EMAIL_PER_DAY"`
 		Mail                                 struct {
+			Transport string `yaml:"transport" envconfig:"FRONTEND_MAIL_TRANSPORT"` // smtp, mailgun or file, if empty smtp or mailgun is used depending on which one is configured
+			File      struct {
+				Directory string `yaml:"directory" envconfig:"FRONTEND_MAIL_FILE_DIRECTORY"` // maildir the file transport writes the mails to
+			} `yaml:"file"`
 			SMTP struct {
 				Server   string `yaml:"server" envconfig:"FRONTEND_MAIL_SMTP_SERVER"`
 				Host     string `yaml:"host" envconfig:"FRONTEND_MAIL_SMTP_HOST"`
@@ -234,11 +249,35 @@ type Config struct {
 		MachineEventThreshold                         uint64  `yaml:"machineEventThreshold" envconfig:"MACHINE_EVENT_THRESHOLD"`
 		MachineEventFirstRatioThreshold               float64 `yaml:"machineEventFirstRatioThreshold" envconfig:"MACHINE_EVENT_FIRST_RATIO_THRESHOLD"`
 		MachineEventSecondRatioThreshold              float64 `yaml:"machineEventSecondRatioThreshold" envconfig:"MACHINE_EVENT_SECOND_RATIO_THRESHOLD"`
+		Telegram                                      struct {
+			BotToken string `yaml:"botToken" envconfig:"NOTIFICATIONS_TELEGRAM_BOT_TOKEN"`
+			BotName  string `yaml:"botName" envconfig:"NOTIFICATIONS_TELEGRAM_BOT_NAME"` // username of the bot without the @, shown in the setup flow
+		} `yaml:"telegram"`
+		Matrix struct {
+			Homeserver  string `yaml:"homeserver" envconfig:"NOTIFICATIONS_MATRIX_HOMESERVER"` // e.g. https://matrix.org
+			AccessToken string `yaml:"accessToken" envconfig:"NOTIFICATIONS_MATRIX_ACCESS_TOKEN"`
+			UserID      string `yaml:"userId" envconfig:"NOTIFICATIONS_MATRIX_USER_ID"` // users invite this account into the room they want to be notified in
+		} `yaml:"matrix"`
+		CollectorShards        uint64        `yaml:"collectorShards" envconfig:"NOTIFICATIONS_COLLECTOR_SHARDS"`                // number of parts the collection of an epoch is split into, all collector instances must use the same value
+		CollectorLeaseDuration time.Duration `yaml:"collectorLeaseDuration" envconfig:"NOTIFICATIONS_COLLECTOR_LEASE_DURATION"` // time after which a shard of a crashed collector is taken over by another instance, defaults to 5 minutes
+		HistoryRetention       time.Duration `yaml:"historyRetention" envconfig:"NOTIFICATIONS_HISTORY_RETENTION"`              // time after which notifications are deleted from the notification history of the users, kept forever if not set
 	} `yaml:"notifications"`
 	RatelimitUpdater struct {
 		Enabled        bool          `yaml:"enabled" envconfig:"RATELIMIT_UPDATER_ENABLED"`
 		UpdateInterval time.Duration `yaml:"updateInterval" envconfig:"RATELIMIT_UPDATER_UPDATE_INTERVAL"`
 	} `yaml:"ratelimitUpdater"`
+	Exports struct {
+		WorkerEnabled bool `yaml:"workerEnabled" envconfig:"EXPORTS_WORKER_ENABLED"` // runs the worker that writes the files of the export jobs in this instance
+		S3            struct {
+			Endpoint        string `yaml:"endpoint" envconfig:"EXPORTS_S3_ENDPOINT"`
+			Bucket          string `yaml:"bucket" envconfig:"EXPORTS_S3_BUCKET"` // exports are disabled if no bucket is configured
+			AccessKeyId     string `yaml:"accessKeyId" envconfig:"EXPORTS_S3_ACCESS_KEY_ID"`
+			AccessKeySecret string `yaml:"accessKeySecret" envconfig:"EXPORTS_S3_ACCESS_KEY_SECRET"`
+		} `yaml:"s3"`
+		MaxDays      uint64        `yaml:"maxDays" envconfig:"EXPORTS_MAX_DAYS"`           // maximum number of days of an export, defaults to 31
+		Retention    time.Duration `yaml:"retention" envconfig:"EXPORTS_RETENTION"`        // time after which the files of an export are deleted, defaults to 7 days
+		LinkValidity time.Duration `yaml:"linkValidity" envconfig:"EXPORTS_LINK_VALIDITY"` // validity of the download links, defaults to 1 hour
+	} `yaml:"exports"`
 	SSVExporter struct {
 		Enabled bool   `yaml:"enabled" envconfig:"SSV_EXPORTER_ENABLED"`
 		Address string `yaml:"address" envconfig:"SSV_EXPORTER_ADDRESS"`
@@ -275,6 +314,14 @@ type DatabaseConfig struct {
 	SSL          bool
 }
 
+// ValidatorHistoryRetention configures how long the per epoch validator history of a family is kept in bigtable.
+// Raw history is removed from bigtable once it has been archived, or once it has been downsampled if archiving is disabled.
+type ValidatorHistoryRetention struct {
+	Resolution          string `yaml:"resolution" envconfig:"RESOLUTION"`                     // hourly or daily, empty disables downsampling
+	DownsampleAfterDays uint64 `yaml:"downsampleAfterDays" envconfig:"DOWNSAMPLE_AFTER_DAYS"` // days after which the raw history is aggregated to the resolution
+	ArchiveAfterDays    uint64 `yaml:"archiveAfterDays" envconfig:"ARCHIVE_AFTER_DAYS"`       // days after which the raw history is exported to parquet files in the blob indexer s3 bucket, 0 disables archiving
+}
+
 type ServiceMonitoringConfiguration struct {
 	Name     string        `yaml:"name" envconfig:"NAME"`
 	Duration time.Duration `yaml:"duration" envconfig:"DURATION"`
@@ -300,6 +347,8 @@ type ConfigJsonResponse struct {
 		CapellaForkEpoch                        string `json:"CAPELLA_FORK_EPOCH"`
 		DenebForkVersion                        string `json:"DENEB_FORK_VERSION"`
 		DenebForkEpoch                          string `json:"DENEB_FORK_EPOCH"`
+		ElectraForkVersion                      string `json:"ELECTRA_FORK_VERSION"`
+		ElectraForkEpoch                        string `json:"ELECTRA_FORK_EPOCH"`
 		SecondsPerSlot                          string `json:"SECONDS_PER_SLOT"`
 		SecondsPerEth1Block                     string `json:"SECONDS_PER_ETH1_BLOCK"`
 		MinValidatorWithdrawabilityDelay        string `json:"MIN_VALIDATOR_WITHDRAWABILITY_DELAY"`
@@ -327,6 +376,8 @@ type ConfigJsonResponse struct {
 		MinDepositAmount                        string `json:"MIN_DEPOSIT_AMOUNT"`
 		MaxEffectiveBalance                     string `json:"MAX_EFFECTIVE_BALANCE"`
 		EffectiveBalanceIncrement               string `json:"EFFECTIVE_BALANCE_INCREMENT"`
+		MinActivationBalance                    string `json:"MIN_ACTIVATION_BALANCE"`
+		MaxEffectiveBalanceElectra              string `json:"MAX_EFFECTIVE_BALANCE_ELECTRA"`
 		MinAttestationInclusionDelay            string `json:"MIN_ATTESTATION_INCLUSION_DELAY"`
 		SlotsPerEpoch                           string `json:"SLOTS_PER_EPOCH"`
 		MinSeedLookahead                        string `json:"MIN_SEED_LOOKAHEAD"`
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/jackc/pgtype"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
@@ -130,6 +131,9 @@ type Block struct {
 	ExcessBlobGas              uint64
 	BlobKZGCommitments         [][]byte
 	BlobKZGProofs              [][]byte
+	DepositRequests            []*DepositRequest       // present only after electra
+	WithdrawalRequests         []*WithdrawalRequest    // present only after electra
+	ConsolidationRequests      []*ConsolidationRequest // present only after electra
 	AttestationDuties          map[ValidatorIndex][]Slot
 	SyncDuties                 map[ValidatorIndex]bool
 	Finalized                  bool
@@ -148,6 +152,31 @@ type BLSToExecutionChange struct {
 	Address        []byte
 }
 
+// DepositRequest is a deposit processed via the execution layer (EIP-6110)
+type DepositRequest struct {
+	Pubkey                []byte
+	WithdrawalCredentials []byte
+	Amount                uint64
+	Signature             []byte
+	Index                 uint64
+}
+
+// WithdrawalRequest is a withdrawal or exit triggered by the withdrawal address of a validator (EIP-7002).
+// An amount of 0 requests a full exit of the validator.
+type WithdrawalRequest struct {
+	SourceAddress   []byte
+	ValidatorPubkey []byte
+	Amount          uint64
+}
+
+// ConsolidationRequest moves the balance of the source validator to the target validator (EIP-7251).
+// If source and target are equal the request switches the validator to compounding withdrawal credentials.
+type ConsolidationRequest struct {
+	SourceAddress []byte
+	SourcePubkey  []byte
+	TargetPubkey  []byte
+}
+
 type Transaction struct {
 	Raw []byte
 	// Note: below values may be nil/0 if Raw fails to decode into a valid transaction
@@ -676,3 +705,18 @@ type ValidatorStatsTableDbRow struct {
 	MEVPerformance31d  decimal.Decimal `db:"-"`
 	MEVPerformance365d decimal.Decimal `db:"-"`
 }
+
+// Reorg holds the data of a chain reorganization detected by the slot exporter
+type Reorg struct {
+	ID                 uint64        `db:"id" json:"id"`
+	Slot               uint64        `db:"slot" json:"slot"`
+	Depth              uint64        `db:"depth" json:"depth"`
+	CommonAncestorSlot uint64        `db:"common_ancestor_slot" json:"common_ancestor_slot"`
+	OldHeadRoot        []byte        `db:"old_head_root" json:"old_head_root"`
+	NewHeadRoot        []byte        `db:"new_head_root" json:"new_head_root"`
+	OldHeadProposer    uint64        `db:"old_head_proposer" json:"old_head_proposer"`
+	NewHeadProposer    uint64        `db:"new_head_proposer" json:"new_head_proposer"`
+	OrphanedSlots      pq.Int64Array `db:"orphaned_slots" json:"orphaned_slots"`
+	OrphanedProposers  pq.Int64Array `db:"orphaned_proposers" json:"orphaned_proposers"`
+	DetectedAt         time.Time     `db:"detected_at" json:"detected_at"`
+}
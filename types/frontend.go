@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
+	"fmt"
 	"html/template"
 	"math/big"
 	"strings"
@@ -24,12 +25,16 @@ const (
 	ValidatorBalanceDecreasedEventName               EventName = "validator_balance_decreased"
 	ValidatorMissedProposalEventName                 EventName = "validator_proposal_missed"
 	ValidatorExecutedProposalEventName               EventName = "validator_proposal_submitted"
+	ValidatorUpcomingProposalEventName               EventName = "validator_proposal_upcoming"
 	ValidatorMissedAttestationEventName              EventName = "validator_attestation_missed"
 	ValidatorGotSlashedEventName                     EventName = "validator_got_slashed"
 	ValidatorDidSlashEventName                       EventName = "validator_did_slash"
 	ValidatorIsOfflineEventName                      EventName = "validator_is_offline"
 	ValidatorReceivedWithdrawalEventName             EventName = "validator_withdrawal"
 	ValidatorReceivedDepositEventName                EventName = "validator_received_deposit"
+	ValidatorConsolidatedEventName                   EventName = "validator_consolidated"
+	ValidatorExecutionLayerExitEventName             EventName = "validator_el_exit"
+	ValidatorIncomeBelowNetworkEventName             EventName = "validator_income_below_network"
 	NetworkSlashingEventName                         EventName = "network_slashing"
 	NetworkValidatorActivationQueueFullEventName     EventName = "network_validator_activation_queue_full"
 	NetworkValidatorActivationQueueNotFullEventName  EventName = "network_validator_activation_queue_not_full"
@@ -43,12 +48,17 @@ const (
 	MonitoringMachineMemoryUsageEventName            EventName = "monitoring_memory_usage"
 	MonitoringMachineSwitchedToETH2FallbackEventName EventName = "monitoring_fallback_eth2inuse"
 	MonitoringMachineSwitchedToETH1FallbackEventName EventName = "monitoring_fallback_eth1inuse"
+	MonitoringMachineBeaconNodeNotSyncedEventName    EventName = "monitoring_beacon_node_not_synced"
+	MonitoringMachineExecutionBehindEventName        EventName = "monitoring_execution_behind"
+	MonitoringMachinePeerCountLowEventName           EventName = "monitoring_peer_count_low"
+	MonitoringMachineClientOutdatedEventName         EventName = "monitoring_client_outdated"
 	TaxReportEventName                               EventName = "user_tax_report"
 	RocketpoolCommissionThresholdEventName           EventName = "rocketpool_commision_threshold"
 	RocketpoolNewClaimRoundStartedEventName          EventName = "rocketpool_new_claimround"
 	RocketpoolCollateralMinReached                   EventName = "rocketpool_colleteral_min"
 	RocketpoolCollateralMaxReached                   EventName = "rocketpool_colleteral_max"
 	SyncCommitteeSoon                                EventName = "validator_synccommittee_soon"
+	NotificationRuleEventName                        EventName = "notification_rule"
 )
 
 var MachineEvents = []EventName{
@@ -59,6 +69,10 @@ var MachineEvents = []EventName{
 	MonitoringMachineMemoryUsageEventName,
 	MonitoringMachineSwitchedToETH2FallbackEventName,
 	MonitoringMachineSwitchedToETH1FallbackEventName,
+	MonitoringMachineBeaconNodeNotSyncedEventName,
+	MonitoringMachineExecutionBehindEventName,
+	MonitoringMachinePeerCountLowEventName,
+	MonitoringMachineClientOutdatedEventName,
 }
 
 var UserIndexEvents = []EventName{
@@ -71,18 +85,26 @@ var UserIndexEvents = []EventName{
 	MonitoringMachineMemoryUsageEventName,
 	MonitoringMachineSwitchedToETH2FallbackEventName,
 	MonitoringMachineSwitchedToETH1FallbackEventName,
+	MonitoringMachineBeaconNodeNotSyncedEventName,
+	MonitoringMachineExecutionBehindEventName,
+	MonitoringMachinePeerCountLowEventName,
+	MonitoringMachineClientOutdatedEventName,
 }
 
 var EventLabel map[EventName]string = map[EventName]string{
 	ValidatorBalanceDecreasedEventName:               "Your validator(s) balance decreased",
 	ValidatorMissedProposalEventName:                 "Your validator(s) missed a proposal",
 	ValidatorExecutedProposalEventName:               "Your validator(s) submitted a proposal",
+	ValidatorUpcomingProposalEventName:               "Your validator(s) will propose a block soon",
 	ValidatorMissedAttestationEventName:              "Your validator(s) missed an attestation",
 	ValidatorGotSlashedEventName:                     "Your validator(s) got slashed",
 	ValidatorDidSlashEventName:                       "Your validator(s) slashed another validator",
 	ValidatorIsOfflineEventName:                      "Your validator(s) state changed",
 	ValidatorReceivedDepositEventName:                "Your validator(s) received a deposit",
 	ValidatorReceivedWithdrawalEventName:             "A withdrawal was initiated for your validators",
+	ValidatorConsolidatedEventName:                   "Your validator(s) got consolidated",
+	ValidatorExecutionLayerExitEventName:             "An exit of your validator(s) was requested by its withdrawal address",
+	ValidatorIncomeBelowNetworkEventName:             "Your validator(s) earned less than the network",
 	NetworkSlashingEventName:                         "A slashing event has been registered by the network",
 	NetworkValidatorActivationQueueFullEventName:     "The activation queue is full",
 	NetworkValidatorActivationQueueNotFullEventName:  "The activation queue is empty",
@@ -96,12 +118,17 @@ var EventLabel map[EventName]string = map[EventName]string{
 	MonitoringMachineMemoryUsageEventName:            "Your machine(s) has a high memory load",
 	MonitoringMachineSwitchedToETH2FallbackEventName: "Your machine(s) is using its consensus client fallback",
 	MonitoringMachineSwitchedToETH1FallbackEventName: "Your machine(s) is using its execution client fallback",
+	MonitoringMachineBeaconNodeNotSyncedEventName:    "Your beacon node(s) is not synced",
+	MonitoringMachineExecutionBehindEventName:        "Your execution client(s) is behind the head of the chain",
+	MonitoringMachinePeerCountLowEventName:           "Your beacon node(s) has few peers",
+	MonitoringMachineClientOutdatedEventName:         "Your beacon node(s) is running an outdated client version",
 	TaxReportEventName:                               "You have an available tax report",
 	RocketpoolCommissionThresholdEventName:           "Your configured Rocket Pool commission threshold is reached",
 	RocketpoolNewClaimRoundStartedEventName:          "Your Rocket Pool claim from last round is available",
 	RocketpoolCollateralMinReached:                   "You reached the Rocket Pool min RPL collateral",
 	RocketpoolCollateralMaxReached:                   "You reached the Rocket Pool max RPL collateral",
 	SyncCommitteeSoon:                                "Your validator(s) will soon be part of the sync committee",
+	NotificationRuleEventName:                        "One of your notification rules matched",
 }
 
 func IsUserIndexed(event EventName) bool {
@@ -126,12 +153,16 @@ var EventNames = []EventName{
 	ValidatorBalanceDecreasedEventName,
 	ValidatorExecutedProposalEventName,
 	ValidatorMissedProposalEventName,
+	ValidatorUpcomingProposalEventName,
 	ValidatorMissedAttestationEventName,
 	ValidatorGotSlashedEventName,
 	ValidatorDidSlashEventName,
 	ValidatorIsOfflineEventName,
 	ValidatorReceivedDepositEventName,
 	ValidatorReceivedWithdrawalEventName,
+	ValidatorConsolidatedEventName,
+	ValidatorExecutionLayerExitEventName,
+	ValidatorIncomeBelowNetworkEventName,
 	NetworkSlashingEventName,
 	NetworkValidatorActivationQueueFullEventName,
 	NetworkValidatorActivationQueueNotFullEventName,
@@ -145,6 +176,10 @@ var EventNames = []EventName{
 	MonitoringMachineSwitchedToETH2FallbackEventName,
 	MonitoringMachineSwitchedToETH1FallbackEventName,
 	MonitoringMachineMemoryUsageEventName,
+	MonitoringMachineBeaconNodeNotSyncedEventName,
+	MonitoringMachineExecutionBehindEventName,
+	MonitoringMachinePeerCountLowEventName,
+	MonitoringMachineClientOutdatedEventName,
 	TaxReportEventName,
 	RocketpoolCommissionThresholdEventName,
 	RocketpoolNewClaimRoundStartedEventName,
@@ -169,6 +204,23 @@ type MachineMetricSystemUser struct {
 	FiveMinuteOldDataInsertTs int64
 }
 
+type MachineMetricNodeUser struct {
+	UserID              uint64
+	Machine             string
+	CurrentData         *MachineMetricNode
+	CurrentDataInsertTs int64
+}
+
+// Default thresholds of the beacon node monitoring events, used if the user did not configure a threshold
+const (
+	MachineBeaconNodeSyncLagSlotsDefault = 32 // slots the beacon node head may be behind the chain head
+	MachineExecutionLagBlocksDefault     = 20 // blocks the execution client head may be behind the chain head
+	MachinePeerCountDefault              = 10 // minimum number of peers of the beacon node
+)
+
+// ValidatorIncomeBelowNetworkThresholdDefault is the share of the network income a validator has to earn if the user did not configure a threshold
+const ValidatorIncomeBelowNetworkThresholdDefault = 0.75
+
 // this is the source of truth for the validator events that are supported by the user/notification page
 var AddWatchlistEvents = []EventNameDesc{
 	{
@@ -184,6 +236,11 @@ var AddWatchlistEvents = []EventNameDesc{
 		Desc:  "Proposals submitted",
 		Event: ValidatorExecutedProposalEventName,
 	},
+	{
+		Desc:  "Upcoming proposals",
+		Event: ValidatorUpcomingProposalEventName,
+		Info:  template.HTML(`<i data-toggle="tooltip" data-html="true" title="<div class='text-left'>Will trigger a notifcation as soon as the proposer duties of the next epoch are known, which is 6 to 13 minutes before the proposal</div>" class="fas fa-question-circle"></i>`),
+	},
 	{
 		Desc:  "Validator got slashed",
 		Event: ValidatorGotSlashedEventName,
@@ -202,6 +259,21 @@ var AddWatchlistEvents = []EventNameDesc{
 		Event: ValidatorReceivedWithdrawalEventName,
 		Info:  template.HTML(`<i data-toggle="tooltip" data-html="true" title="<div class='text-left'>Will trigger a notifcation when:<br><ul><li>A partial withdrawal is processed</li><li>Your validator exits and its full balance is withdrawn</li></ul> <div>Requires that your validator has 0x01 credentials</div></div>" class="fas fa-question-circle"></i>`),
 	},
+	{
+		Desc:  "Validator consolidated",
+		Event: ValidatorConsolidatedEventName,
+		Info:  template.HTML(`<i data-toggle="tooltip" data-html="true" title="<div class='text-left'>Will trigger a notifcation when a consolidation of your validator into another validator or of another validator into yours is requested</div>" class="fas fa-question-circle"></i>`),
+	},
+	{
+		Desc:  "Exit requested by withdrawal address",
+		Event: ValidatorExecutionLayerExitEventName,
+		Info:  template.HTML(`<i data-toggle="tooltip" data-html="true" title="<div class='text-left'>Will trigger a notifcation when the withdrawal address of your validator requests its exit through the execution layer</div>" class="fas fa-question-circle"></i>`),
+	},
+	{
+		Desc:  "Income below network",
+		Event: ValidatorIncomeBelowNetworkEventName,
+		Info:  template.HTML(`<i data-toggle="tooltip" data-html="true" title="<div class='text-left'>Will trigger a notifcation when the attestation income of your validator per staked ETH over the last 32 epochs falls below 75% of the network average (the percentage can be changed with a premium plan)<br><ul><li>Catches late or wrong-head attestations that are not missed duties</li><li>At most once a day per validator</li></ul></div>" class="fas fa-question-circle"></i>`),
+	},
 }
 
 // this is the source of truth for the network events that are supported by the user/notification page
@@ -248,6 +320,22 @@ type Notification interface {
 	GetInfoMarkdown() string
 }
 
+// NotificationShard is the part of an epoch one notification collector instance processes.
+// Attestation and offline notifications are split by validator index, the other subscriptions by user id.
+// A Count of 0 or 1 selects everything.
+type NotificationShard struct {
+	Index uint64
+	Count uint64
+}
+
+func (s NotificationShard) ContainsUser(userID uint64) bool {
+	return s.Count <= 1 || userID%s.Count == s.Index
+}
+
+func (s NotificationShard) ContainsValidator(validatorIndex uint64) bool {
+	return s.Count <= 1 || validatorIndex%s.Count == s.Index
+}
+
 // func UnMarschal
 
 type Subscription struct {
@@ -292,7 +380,7 @@ type OAuthCodeData struct {
 }
 
 type MobileSettingsData struct {
-	NotifyToken string `json:"notify_token"`
+	NotifyEnabled bool `db:"notify_enabled" json:"notify_enabled"`
 }
 
 type MobileSubscription struct {
@@ -369,6 +457,7 @@ type TransitWebhookContent struct {
 }
 
 type WebhookEvent struct {
+	ID          string `json:"id,omitempty"`
 	Network     string `json:"network,omitempty"`
 	Name        string `json:"event,omitempty"`
 	Title       string `json:"title,omitempty"`
@@ -465,6 +554,93 @@ type UserWebhook struct {
 	Request     sql.NullString `db:"request" json:"request"`
 	Destination sql.NullString `db:"destination" json:"destination"`
 	EventNames  pq.StringArray `db:"event_names" json:"-"`
+	Secret      sql.NullString `db:"secret" json:"-"`
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// UserNotificationHistoryEntry is a notification in the inbox of a user, it is added when the notification is queued for sending
+type UserNotificationHistoryEntry struct {
+	ID             uint64     `db:"id" json:"id"`
+	UserID         uint64     `db:"user_id" json:"-"`
+	SubscriptionID uint64     `db:"subscription_id" json:"subscriptionId"`
+	EventName      string     `db:"event_name" json:"eventName"`
+	EventFilter    string     `db:"event_filter" json:"eventFilter"`
+	Epoch          uint64     `db:"epoch" json:"epoch"`
+	Title          string     `db:"title" json:"title"`
+	Content        string     `db:"content" json:"content"`
+	Created        time.Time  `db:"created" json:"created"`
+	Read           *time.Time `db:"read_ts" json:"read"`
+	Acknowledged   *time.Time `db:"acknowledged_ts" json:"acknowledged"`
+}
+
+// UserWebhookDelivery is an entry of the delivery log of a webhook, pending deliveries are retried with an exponential backoff
+type UserWebhookDelivery struct {
+	ID             uint64                `db:"id" json:"id"`
+	UserID         uint64                `db:"user_id" json:"-"`
+	WebhookID      uint64                `db:"webhook_id" json:"webhookId"`
+	EventID        string                `db:"event_id" json:"eventId"`
+	EventName      string                `db:"event_name" json:"eventName"`
+	Content        TransitWebhookContent `db:"content" json:"payload"`
+	Created        time.Time             `db:"created" json:"created"`
+	Status         string                `db:"status" json:"status"`
+	Attempts       uint64                `db:"attempts" json:"attempts"`
+	NextAttempt    time.Time             `db:"next_attempt" json:"nextAttempt"`
+	LastAttempt    *time.Time            `db:"last_attempt" json:"lastAttempt"`
+	ResponseStatus *int64                `db:"response_status" json:"responseStatus"`
+	ResponseBody   *string               `db:"response_body" json:"responseBody"`
+	Error          *string               `db:"error" json:"error"`
+}
+
+// UserExport is a bulk export job of the history of a set of validators, the export worker writes a file per dataset to the exports bucket
+type UserExport struct {
+	ID         uint64          `db:"id"`
+	UserID     uint64          `db:"user_id"`
+	Network    string          `db:"network"`
+	Validators pq.Int64Array   `db:"validators"`
+	StartDate  time.Time       `db:"start_date"`
+	EndDate    time.Time       `db:"end_date"`
+	Datasets   pq.StringArray  `db:"datasets"`
+	Format     string          `db:"format"`
+	Status     string          `db:"status"`
+	Created    time.Time       `db:"created"`
+	Started    *time.Time      `db:"started"`
+	Completed  *time.Time      `db:"completed"`
+	Expires    *time.Time      `db:"expires"`
+	Attempts   uint64          `db:"attempts"`
+	Files      UserExportFiles `db:"files"`
+	Error      *string         `db:"error"`
+}
+
+// UserExportFile is a file of a completed export that is stored under Key in the exports bucket
+type UserExportFile struct {
+	Dataset string `json:"dataset"`
+	Key     string `json:"key"`
+	Rows    uint64 `json:"rows"`
+	Size    int64  `json:"size"`
+}
+
+type UserExportFiles []*UserExportFile
+
+func (f *UserExportFiles) Scan(value interface{}) error {
+	if value == nil {
+		*f = nil
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, f)
+}
+
+func (f UserExportFiles) Value() (driver.Value, error) {
+	return json.Marshal(f)
 }
 
 type UserWebhookSubscriptions struct {
@@ -481,6 +657,9 @@ var NotificationChannelLabels map[NotificationChannel]template.HTML = map[Notifi
 	PushNotificationChannel:           "Push Notification",
 	WebhookNotificationChannel:        `Webhook Notification (<a href="/user/webhooks">configure</a>)`,
 	WebhookDiscordNotificationChannel: "Discord Notification",
+	TelegramNotificationChannel:       `Telegram Notification (<a href="/user/notifications/channels">configure</a>)`,
+	SlackNotificationChannel:          `Slack Notification (<a href="/user/notifications/channels">configure</a>)`,
+	MatrixNotificationChannel:         `Matrix Notification (<a href="/user/notifications/channels">configure</a>)`,
 }
 
 const (
@@ -488,6 +667,9 @@ const (
 	PushNotificationChannel           NotificationChannel = "push"
 	WebhookNotificationChannel        NotificationChannel = "webhook"
 	WebhookDiscordNotificationChannel NotificationChannel = "webhook_discord"
+	TelegramNotificationChannel       NotificationChannel = "telegram"
+	SlackNotificationChannel          NotificationChannel = "slack"
+	MatrixNotificationChannel         NotificationChannel = "matrix"
 )
 
 var NotificationChannels = []NotificationChannel{
@@ -495,6 +677,66 @@ var NotificationChannels = []NotificationChannel{
 	PushNotificationChannel,
 	WebhookNotificationChannel,
 	WebhookDiscordNotificationChannel,
+	TelegramNotificationChannel,
+	SlackNotificationChannel,
+	MatrixNotificationChannel,
+}
+
+// ChatNotificationChannels are the channels that deliver notifications into a chat the user configured on /user/notifications/channels
+var ChatNotificationChannels = []NotificationChannel{
+	TelegramNotificationChannel,
+	SlackNotificationChannel,
+	MatrixNotificationChannel,
+}
+
+// IsChatNotificationChannel returns whether the channel delivers notifications into a chat
+func IsChatNotificationChannel(channel NotificationChannel) bool {
+	for _, ch := range ChatNotificationChannels {
+		if ch == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// UserChatChannel is the chat a user receives the notifications of a chat channel in
+type UserChatChannel struct {
+	UserID  uint64              `db:"user_id" json:"userId"`
+	Channel NotificationChannel `db:"channel" json:"channel"`
+	// Target is the telegram chat id, the slack incoming webhook url or the matrix room id
+	Target string `db:"target" json:"target"`
+}
+
+// ChatMessage is a message formatted for a chat channel, Formatted holds the telegram html, the slack mrkdwn or the matrix html and Body the plain text
+type ChatMessage struct {
+	Body      string `json:"body"`
+	Formatted string `json:"formatted"`
+}
+
+type TransitChat struct {
+	Id      uint64             `db:"id,omitempty"`
+	Created sql.NullTime       `db:"created"`
+	Sent    sql.NullTime       `db:"sent"`
+	Channel string             `db:"channel"`
+	Content TransitChatContent `db:"content"`
+}
+
+type TransitChatContent struct {
+	Chat     UserChatChannel
+	Messages []ChatMessage
+}
+
+func (e *TransitChatContent) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &e)
+}
+
+func (a TransitChatContent) Value() (driver.Value, error) {
+	return json.Marshal(a)
 }
 
 func GetNotificationChannel(channel string) (NotificationChannel, error) {
@@ -506,6 +748,234 @@ func GetNotificationChannel(channel string) (NotificationChannel, error) {
 	return "", errors.Errorf("Could not convert channel from string to NotificationChannel type. %v is not a known channel type", channel)
 }
 
+type NotificationDeliveryMode string
+
+const (
+	ImmediateDeliveryMode    NotificationDeliveryMode = "immediate"
+	HourlyDigestDeliveryMode NotificationDeliveryMode = "hourly"
+	DailyDigestDeliveryMode  NotificationDeliveryMode = "daily"
+)
+
+var NotificationDeliveryModes = []NotificationDeliveryMode{
+	ImmediateDeliveryMode,
+	HourlyDigestDeliveryMode,
+	DailyDigestDeliveryMode,
+}
+
+var NotificationDeliveryModeLabels = map[NotificationDeliveryMode]string{
+	ImmediateDeliveryMode:    "Immediately",
+	HourlyDigestDeliveryMode: "Hourly digest",
+	DailyDigestDeliveryMode:  "Daily digest",
+}
+
+// DigestNotificationChannels are the channels that support digests and quiet hours, webhooks are always delivered immediately
+var DigestNotificationChannels = []NotificationChannel{
+	EmailNotificationChannel,
+	PushNotificationChannel,
+	TelegramNotificationChannel,
+	SlackNotificationChannel,
+	MatrixNotificationChannel,
+}
+
+// IsDigestNotificationChannel returns whether the channel supports digests and quiet hours
+func IsDigestNotificationChannel(channel NotificationChannel) bool {
+	for _, ch := range DigestNotificationChannels {
+		if ch == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// CriticalEventNames are delivered immediately, regardless of the delivery mode and the quiet hours of the channel
+var CriticalEventNames = map[EventName]bool{
+	ValidatorGotSlashedEventName:       true,
+	ValidatorUpcomingProposalEventName: true,
+}
+
+// UserNotificationPreferences are the delivery mode and the quiet hours of a notification channel of a user
+type UserNotificationPreferences struct {
+	UserID       uint64                   `db:"user_id"`
+	Channel      NotificationChannel      `db:"channel"`
+	DeliveryMode NotificationDeliveryMode `db:"delivery_mode"`
+	// QuietHoursStart and QuietHoursEnd are hours of the day in the time zone of the user
+	QuietHoursStart sql.NullInt16  `db:"quiet_hours_start"`
+	QuietHoursEnd   sql.NullInt16  `db:"quiet_hours_end"`
+	Timezone        sql.NullString `db:"notifications_timezone"`
+}
+
+type NotificationRuleMetric string
+
+const (
+	// RuleValidatorsOffline is the number of validators of a group that missed all attestations of the last epochs
+	RuleValidatorsOffline NotificationRuleMetric = "validators_offline"
+	// RuleAttestationsMissed is the percentage of attestations the validators of a group missed in the last epochs
+	RuleAttestationsMissed NotificationRuleMetric = "attestations_missed"
+	// RuleMachineCpuLoad, RuleMachineMemoryUsage and RuleMachineDiskUsage are percentages reported by a monitored machine
+	RuleMachineCpuLoad     NotificationRuleMetric = "machine_cpu_load"
+	RuleMachineMemoryUsage NotificationRuleMetric = "machine_memory_usage"
+	RuleMachineDiskUsage   NotificationRuleMetric = "machine_disk_usage"
+)
+
+var NotificationRuleMetricLabels = map[NotificationRuleMetric]string{
+	RuleValidatorsOffline:  "Validators offline",
+	RuleAttestationsMissed: "Missed attestations (%)",
+	RuleMachineCpuLoad:     "Machine CPU load (%)",
+	RuleMachineMemoryUsage: "Machine memory usage (%)",
+	RuleMachineDiskUsage:   "Machine disk usage (%)",
+}
+
+var NotificationRuleMetrics = []NotificationRuleMetric{
+	RuleValidatorsOffline,
+	RuleAttestationsMissed,
+	RuleMachineCpuLoad,
+	RuleMachineMemoryUsage,
+	RuleMachineDiskUsage,
+}
+
+var NotificationRuleOperators = []string{">", ">=", "<", "<="}
+
+const (
+	NotificationRuleMatchAll = "all"
+	NotificationRuleMatchAny = "any"
+
+	// NotificationRuleMaxConditions is the maximum number of conditions of a rule
+	NotificationRuleMaxConditions = 5
+	// NotificationRuleMaxEpochs is the longest window a validator condition can be evaluated over
+	NotificationRuleMaxEpochs = 32
+	// NotificationRuleMaxPerUser is the maximum number of rules of a user
+	NotificationRuleMaxPerUser = 20
+)
+
+// NotificationRuleCondition compares a metric of a validator group or of a machine to a value.
+// Group is a validator tag of the user without the network prefix, e.g. watchlist.
+type NotificationRuleCondition struct {
+	Metric   NotificationRuleMetric `json:"metric"`
+	Group    string                 `json:"group,omitempty"`
+	Machine  string                 `json:"machine,omitempty"`
+	Epochs   uint64                 `json:"epochs,omitempty"`
+	Operator string                 `json:"operator"`
+	Value    float64                `json:"value"`
+}
+
+// IsValidatorCondition returns whether the condition is evaluated on a validator group
+func (c NotificationRuleCondition) IsValidatorCondition() bool {
+	return c.Metric == RuleValidatorsOffline || c.Metric == RuleAttestationsMissed
+}
+
+// Compare returns whether the value of the metric fulfills the condition
+func (c NotificationRuleCondition) Compare(value float64) bool {
+	switch c.Operator {
+	case ">":
+		return value > c.Value
+	case ">=":
+		return value >= c.Value
+	case "<":
+		return value < c.Value
+	case "<=":
+		return value <= c.Value
+	}
+	return false
+}
+
+func (c NotificationRuleCondition) String() string {
+	switch c.Metric {
+	case RuleValidatorsOffline:
+		return fmt.Sprintf("validators of group %s offline for %v epochs %s %v", c.Group, c.Epochs, c.Operator, c.Value)
+	case RuleAttestationsMissed:
+		return fmt.Sprintf("missed attestations of group %s over %v epochs %s %v%%", c.Group, c.Epochs, c.Operator, c.Value)
+	case RuleMachineCpuLoad:
+		return fmt.Sprintf("cpu load of machine %s %s %v%%", c.Machine, c.Operator, c.Value)
+	case RuleMachineMemoryUsage:
+		return fmt.Sprintf("memory usage of machine %s %s %v%%", c.Machine, c.Operator, c.Value)
+	case RuleMachineDiskUsage:
+		return fmt.Sprintf("disk usage of machine %s %s %v%%", c.Machine, c.Operator, c.Value)
+	}
+	return string(c.Metric)
+}
+
+type NotificationRuleConditions []NotificationRuleCondition
+
+func (e *NotificationRuleConditions) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &e)
+}
+
+func (a NotificationRuleConditions) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+// NotificationRule notifies the user when all (or any) of its conditions are fulfilled.
+// Every rule has a subscription of the NotificationRuleEventName event with the id of the rule as event filter,
+// which keeps track of when the rule was last sent and whether it is still fulfilled.
+type NotificationRule struct {
+	ID             uint64                     `db:"id" json:"id"`
+	UserID         uint64                     `db:"user_id" json:"-"`
+	Name           string                     `db:"name" json:"name"`
+	Match          string                     `db:"match" json:"match"`
+	Conditions     NotificationRuleConditions `db:"conditions" json:"conditions"`
+	Created        time.Time                  `db:"created" json:"created"`
+	LastSent       *time.Time                 `db:"last_sent_ts" json:"last_sent,omitempty"`
+	SubscriptionID *uint64                    `db:"subscription_id" json:"-"`
+}
+
+// Validate checks the rule and sets the defaults of its match mode and of the groups of its conditions
+func (r *NotificationRule) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" || len(r.Name) > 100 {
+		return errors.New("the name of a rule must be between 1 and 100 characters long")
+	}
+	if r.Match == "" {
+		r.Match = NotificationRuleMatchAll
+	}
+	if r.Match != NotificationRuleMatchAll && r.Match != NotificationRuleMatchAny {
+		return errors.Errorf("invalid match %q, must be %q or %q", r.Match, NotificationRuleMatchAll, NotificationRuleMatchAny)
+	}
+	if len(r.Conditions) == 0 || len(r.Conditions) > NotificationRuleMaxConditions {
+		return errors.Errorf("a rule must have between 1 and %v conditions", NotificationRuleMaxConditions)
+	}
+	for i := range r.Conditions {
+		c := &r.Conditions[i]
+		if _, ok := NotificationRuleMetricLabels[c.Metric]; !ok {
+			return errors.Errorf("condition %v: invalid metric %q", i+1, c.Metric)
+		}
+		validOperator := false
+		for _, op := range NotificationRuleOperators {
+			if c.Operator == op {
+				validOperator = true
+			}
+		}
+		if !validOperator {
+			return errors.Errorf("condition %v: invalid operator %q", i+1, c.Operator)
+		}
+		if c.Value < 0 || (c.Metric != RuleValidatorsOffline && c.Value > 100) {
+			return errors.Errorf("condition %v: invalid value %v", i+1, c.Value)
+		}
+		if c.IsValidatorCondition() {
+			c.Group = strings.TrimSpace(c.Group)
+			if c.Group == "" {
+				c.Group = string(ValidatorTagsWatchlist)
+			}
+			if c.Epochs == 0 || c.Epochs > NotificationRuleMaxEpochs {
+				return errors.Errorf("condition %v: the number of epochs must be between 1 and %v", i+1, NotificationRuleMaxEpochs)
+			}
+			c.Machine = ""
+		} else {
+			c.Machine = strings.TrimSpace(c.Machine)
+			if c.Machine == "" {
+				return errors.Errorf("condition %v: a machine is required", i+1)
+			}
+			c.Group = ""
+			c.Epochs = 0
+		}
+	}
+	return nil
+}
+
 type ErrorResponse struct {
 	Status string // e.g. "200 OK"
 	Body   string
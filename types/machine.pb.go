@@ -400,6 +400,7 @@ type MachineMetricNode struct {
 	SyncBeaconHeadSlot              uint64 `protobuf:"varint,16,opt,name=sync_beacon_head_slot,json=syncBeaconHeadSlot,proto3" json:"sync_beacon_head_slot,omitempty"`
 	SyncEth1FallbackConfigured      bool   `protobuf:"varint,17,opt,name=sync_eth1_fallback_configured,json=syncEth1FallbackConfigured,proto3" json:"sync_eth1_fallback_configured,omitempty"`
 	SyncEth1FallbackConnected       bool   `protobuf:"varint,18,opt,name=sync_eth1_fallback_connected,json=syncEth1FallbackConnected,proto3" json:"sync_eth1_fallback_connected,omitempty"`
+	SyncEth1HeadBlockNumber         uint64 `protobuf:"varint,20,opt,name=sync_eth1_head_block_number,json=syncEth1HeadBlockNumber,proto3" json:"sync_eth1_head_block_number,omitempty"`
 	// do not store in bigtable but include them in generated model
 	Machine *string `protobuf:"bytes,19,opt,name=machine,proto3,oneof" json:"machine,omitempty"`
 }
@@ -562,6 +563,13 @@ func (x *MachineMetricNode) GetSyncEth1FallbackConnected() bool {
 	return false
 }
 
+func (x *MachineMetricNode) GetSyncEth1HeadBlockNumber() uint64 {
+	if x != nil {
+		return x.SyncEth1HeadBlockNumber
+	}
+	return 0
+}
+
 func (x *MachineMetricNode) GetMachine() string {
 	if x != nil && x.Machine != nil {
 		return *x.Machine
@@ -682,7 +690,7 @@ var file_machine_proto_rawDesc = []byte{
 	0x41, 0x63, 0x74, 0x69, 0x76, 0x65, 0x12, 0x1d, 0x0a, 0x07, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e,
 	0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x07, 0x6d, 0x61, 0x63, 0x68, 0x69,
 	0x6e, 0x65, 0x88, 0x01, 0x01, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e,
-	0x65, 0x22, 0x87, 0x08, 0x0a, 0x11, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x4d, 0x65, 0x74,
+	0x65, 0x22, 0xc5, 0x08, 0x0a, 0x11, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x4d, 0x65, 0x74,
 	0x72, 0x69, 0x63, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73,
 	0x74, 0x61, 0x6d, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65,
 	0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x29, 0x0a, 0x10, 0x65, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x65,
@@ -744,10 +752,14 @@ var file_machine_proto_rawDesc = []byte{
 	0x6c, 0x62, 0x61, 0x63, 0x6b, 0x5f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x18,
 	0x12, 0x20, 0x01, 0x28, 0x08, 0x52, 0x19, 0x73, 0x79, 0x6e, 0x63, 0x45, 0x74, 0x68, 0x31, 0x46,
 	0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64,
-	0x12, 0x1d, 0x0a, 0x07, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x18, 0x13, 0x20, 0x01, 0x28,
-	0x09, 0x48, 0x00, 0x52, 0x07, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x88, 0x01, 0x01, 0x42,
-	0x0a, 0x0a, 0x08, 0x5f, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x42, 0x09, 0x5a, 0x07, 0x2e,
-	0x2f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x12, 0x3c, 0x0a, 0x1b, 0x73, 0x79, 0x6e, 0x63, 0x5f, 0x65, 0x74, 0x68, 0x31, 0x5f, 0x68, 0x65,
+	0x61, 0x64, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18,
+	0x14, 0x20, 0x01, 0x28, 0x04, 0x52, 0x17, 0x73, 0x79, 0x6e, 0x63, 0x45, 0x74, 0x68, 0x31, 0x48,
+	0x65, 0x61, 0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x1d,
+	0x0a, 0x07, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x18, 0x13, 0x20, 0x01, 0x28, 0x09, 0x48,
+	0x00, 0x52, 0x07, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x88, 0x01, 0x01, 0x42, 0x0a, 0x0a,
+	0x08, 0x5f, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x42, 0x09, 0x5a, 0x07, 0x2e, 0x2f, 0x74,
+	0x79, 0x70, 0x65, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
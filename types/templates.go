@@ -406,7 +406,10 @@ type ValidatorPageData struct {
 	Rocketpool                               *RocketpoolValidatorPageData
 	ShowMultipleWithdrawalCredentialsWarning bool
 	CappellaHasHappened                      bool
+	ElectraHasHappened                       bool
 	BLSChange                                *BLSChange
+	WithdrawalRequests                       []*WithdrawalRequestData
+	ConsolidationRequests                    []*ConsolidationRequestData
 	IsWithdrawableAddress                    bool
 	EstimatedNextWithdrawal                  template.HTML
 	AddValidatorWatchlistModal               *AddValidatorWatchlistModal
@@ -609,38 +612,41 @@ type VotesVisChartData struct {
 
 // BlockPageData is a struct block data used in the block/slot page
 type BlockPageData struct {
-	Epoch                  uint64  `db:"epoch"`
-	EpochFinalized         bool    `db:"epoch_finalized"`
-	PrevEpochFinalized     bool    `db:"prev_epoch_finalized"`
-	EpochParticipationRate float64 `db:"epoch_participation_rate"`
-	Ts                     time.Time
-	NextSlot               uint64
-	PreviousSlot           uint64
-	Proposer               uint64  `db:"proposer"`
-	BlockRoot              []byte  `db:"blockroot"`
-	ParentRoot             []byte  `db:"parentroot"`
-	StateRoot              []byte  `db:"stateroot"`
-	Signature              []byte  `db:"signature"`
-	RandaoReveal           []byte  `db:"randaoreveal"`
-	Graffiti               []byte  `db:"graffiti"`
-	ProposerName           string  `db:"name"`
-	Eth1dataDepositroot    []byte  `db:"eth1data_depositroot"`
-	Eth1dataDepositcount   uint64  `db:"eth1data_depositcount"`
-	Eth1dataBlockhash      []byte  `db:"eth1data_blockhash"`
-	SyncAggregateBits      []byte  `db:"syncaggregate_bits"`
-	SyncAggregateSignature []byte  `db:"syncaggregate_signature"`
-	SyncAggParticipation   float64 `db:"syncaggregate_participation"`
-	ProposerSlashingsCount uint64  `db:"proposerslashingscount"`
-	AttesterSlashingsCount uint64  `db:"attesterslashingscount"`
-	AttestationsCount      uint64  `db:"attestationscount"`
-	DepositsCount          uint64  `db:"depositscount"`
-	WithdrawalCount        uint64  `db:"withdrawalcount"`
-	BLSChangeCount         uint64  `db:"bls_change_count"`
-	VoluntaryExitscount    uint64  `db:"voluntaryexitscount"`
-	SlashingsCount         uint64
-	VotesCount             uint64
-	VotingValidatorsCount  uint64
-	Mainnet                bool
+	Epoch                      uint64  `db:"epoch"`
+	EpochFinalized             bool    `db:"epoch_finalized"`
+	PrevEpochFinalized         bool    `db:"prev_epoch_finalized"`
+	EpochParticipationRate     float64 `db:"epoch_participation_rate"`
+	Ts                         time.Time
+	NextSlot                   uint64
+	PreviousSlot               uint64
+	Proposer                   uint64  `db:"proposer"`
+	BlockRoot                  []byte  `db:"blockroot"`
+	ParentRoot                 []byte  `db:"parentroot"`
+	StateRoot                  []byte  `db:"stateroot"`
+	Signature                  []byte  `db:"signature"`
+	RandaoReveal               []byte  `db:"randaoreveal"`
+	Graffiti                   []byte  `db:"graffiti"`
+	ProposerName               string  `db:"name"`
+	Eth1dataDepositroot        []byte  `db:"eth1data_depositroot"`
+	Eth1dataDepositcount       uint64  `db:"eth1data_depositcount"`
+	Eth1dataBlockhash          []byte  `db:"eth1data_blockhash"`
+	SyncAggregateBits          []byte  `db:"syncaggregate_bits"`
+	SyncAggregateSignature     []byte  `db:"syncaggregate_signature"`
+	SyncAggParticipation       float64 `db:"syncaggregate_participation"`
+	ProposerSlashingsCount     uint64  `db:"proposerslashingscount"`
+	AttesterSlashingsCount     uint64  `db:"attesterslashingscount"`
+	AttestationsCount          uint64  `db:"attestationscount"`
+	DepositsCount              uint64  `db:"depositscount"`
+	WithdrawalCount            uint64  `db:"withdrawalcount"`
+	BLSChangeCount             uint64  `db:"bls_change_count"`
+	DepositRequestsCount       uint64  `db:"deposit_requests_count"`
+	WithdrawalRequestsCount    uint64  `db:"withdrawal_requests_count"`
+	ConsolidationRequestsCount uint64  `db:"consolidation_requests_count"`
+	VoluntaryExitscount        uint64  `db:"voluntaryexitscount"`
+	SlashingsCount             uint64
+	VotesCount                 uint64
+	VotingValidatorsCount      uint64
+	Mainnet                    bool
 
 	ExecParentHash        []byte        `db:"exec_parent_hash"`
 	ExecFeeRecipient      []byte        `db:"exec_fee_recipient"`
@@ -1272,11 +1278,18 @@ type UserNotificationsCenterPageData struct {
 type NotificationChannelsModal struct {
 	CsrfField            template.HTML
 	NotificationChannels []UserNotificationChannels
+	Timezone             string
+	DeliveryModes        []NotificationDeliveryMode
+	Hours                []int
 }
 
 type UserNotificationChannels struct {
-	Channel NotificationChannel `db:"channel"`
-	Active  bool                `db:"active"`
+	Channel         NotificationChannel      `db:"channel"`
+	Active          bool                     `db:"active"`
+	DeliveryMode    NotificationDeliveryMode `db:"delivery_mode"`
+	QuietHoursStart sql.NullInt16            `db:"quiet_hours_start"`
+	QuietHoursEnd   sql.NullInt16            `db:"quiet_hours_end"`
+	SupportsDigest  bool                     `db:"-"`
 }
 
 type UserValidatorNotificationTableData struct {
@@ -1466,9 +1479,52 @@ type UserWebhookRow struct {
 	Request      *map[string]interface{} `db:"request" json:"request"`
 	Events       []EventNameCheckbox     `db:"event_names" json:"-"`
 	Discord      bool
+	Signed       bool
 	CsrfField    template.HTML
 }
 
+type NotificationChannelsPageData struct {
+	Channels  []ChatChannelSetup
+	CsrfField template.HTML
+	Flashes   []interface{}
+}
+
+// NotificationRulesPageData is the data of the notification rules page
+type NotificationRulesPageData struct {
+	Rules         []*NotificationRule
+	Groups        []string
+	Metrics       []NotificationRuleMetric
+	MetricLabels  map[NotificationRuleMetric]string
+	Operators     []string
+	ConditionRows []int // indices of the condition rows of the form
+	MaxEpochs     int
+	CsrfField     template.HTML
+	Flashes       []interface{}
+}
+
+// ChatChannelSetup is the setup of a chat channel on the notification channels page
+type ChatChannelSetup struct {
+	Channel      NotificationChannel
+	Label        string
+	Available    bool // whether the channel has been configured by the operator
+	Target       string
+	Placeholder  string
+	Instructions template.HTML
+}
+
+type WebhookDeliveriesPageData struct {
+	WebhookID  uint64
+	Url        string
+	Deliveries []WebhookDeliveryRow
+	CsrfField  template.HTML
+	Flashes    []interface{}
+}
+
+type WebhookDeliveryRow struct {
+	*UserWebhookDelivery
+	Payload string
+}
+
 type AdConfigurationPageData struct {
 	Configurations []*AdConfig
 	CsrfField      template.HTML
@@ -2032,6 +2088,42 @@ type ValidatorsBLSChange struct {
 	WithdrawalCredentialsOld []byte `db:"withdrawalcredentials" json:"withdrawalcredentials,omitempty"`
 }
 
+// DepositRequestData is a deposit request processed via the execution layer (EIP-6110)
+type DepositRequestData struct {
+	Slot                  uint64  `db:"block_slot" json:"slot"`
+	BlockRoot             []byte  `db:"block_root" json:"blockroot"`
+	RequestIndex          uint64  `db:"request_index" json:"request_index"`
+	ValidatorIndex        *uint64 `db:"validatorindex" json:"validatorindex"`
+	Pubkey                []byte  `db:"pubkey" json:"pubkey"`
+	WithdrawalCredentials []byte  `db:"withdrawal_credentials" json:"withdrawal_credentials"`
+	Amount                uint64  `db:"amount" json:"amount"`
+	Signature             []byte  `db:"signature" json:"signature"`
+	DepositIndex          uint64  `db:"deposit_index" json:"deposit_index"`
+}
+
+// WithdrawalRequestData is a withdrawal or exit triggered via the execution layer (EIP-7002), an amount of 0 requests a full exit
+type WithdrawalRequestData struct {
+	Slot            uint64  `db:"block_slot" json:"slot"`
+	BlockRoot       []byte  `db:"block_root" json:"blockroot"`
+	RequestIndex    uint64  `db:"request_index" json:"request_index"`
+	ValidatorIndex  *uint64 `db:"validatorindex" json:"validatorindex"`
+	SourceAddress   []byte  `db:"source_address" json:"source_address"`
+	ValidatorPubkey []byte  `db:"validator_pubkey" json:"validator_pubkey"`
+	Amount          uint64  `db:"amount" json:"amount"`
+}
+
+// ConsolidationRequestData is a request to consolidate the source into the target validator (EIP-7251)
+type ConsolidationRequestData struct {
+	Slot                 uint64  `db:"block_slot" json:"slot"`
+	BlockRoot            []byte  `db:"block_root" json:"blockroot"`
+	RequestIndex         uint64  `db:"request_index" json:"request_index"`
+	SourceAddress        []byte  `db:"source_address" json:"source_address"`
+	SourceValidatorIndex *uint64 `db:"source_validatorindex" json:"source_validatorindex"`
+	SourcePubkey         []byte  `db:"source_pubkey" json:"source_pubkey"`
+	TargetValidatorIndex *uint64 `db:"target_validatorindex" json:"target_validatorindex"`
+	TargetPubkey         []byte  `db:"target_pubkey" json:"target_pubkey"`
+}
+
 // AdConfig is a struct to hold the configuration for one specific ad banner placement
 type AdConfig struct {
 	Id              string `db:"id"`
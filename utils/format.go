@@ -33,6 +33,7 @@ import (
 
 const CalculatingHint = `Calculating…`
 const BeginningOfSetWithdrawalCredentials = "010000000000000000000000"
+const BeginningOfCompoundingWithdrawalCredentials = "020000000000000000000000"
 
 func FormatMessageToHtml(message string) template.HTML {
 	message = fmt.Sprint(strings.Replace(message, "Error: ", "", 1))
@@ -691,7 +692,7 @@ func FormatHashRaw(hash []byte, trunc_opt ...bool) string {
 
 // WithdrawalCredentialsToAddress converts withdrawalCredentials to an address if possible
 func WithdrawalCredentialsToAddress(credentials []byte) ([]byte, error) {
-	if IsValidWithdrawalCredentials(fmt.Sprintf("%#x", credentials)) && bytes.Equal(credentials[:1], []byte{0x01}) {
+	if IsValidWithdrawalCredentials(fmt.Sprintf("%#x", credentials)) && HasExecutionWithdrawalCredentials(credentials) {
 		return credentials[12:], nil
 	}
 	return nil, fmt.Errorf("invalid withdrawal credentials")
@@ -721,6 +722,8 @@ func formatWithdrawalHash(hash []byte) template.HTML {
 	var colorClass string
 	if hash[0] == 0x01 {
 		colorClass = "text-success"
+	} else if hash[0] == 0x02 {
+		colorClass = "text-info"
 	} else {
 		colorClass = "text-warning"
 	}
@@ -734,7 +737,7 @@ func FormatWithdawalCredentials(hash []byte, addCopyButton bool) template.HTML {
 	}
 
 	var text template.HTML
-	if hash[0] == 0x01 {
+	if HasExecutionWithdrawalCredentials(hash) {
 		text = template.HTML(fmt.Sprintf("<a href=\"/address/0x%x\">%s</a>", hash[12:], formatWithdrawalHash(hash)))
 	} else {
 		text = formatWithdrawalHash(hash)
@@ -1242,6 +1245,10 @@ func FormatNotificationChannel(ch types.NotificationChannel) template.HTML {
 	return label
 }
 
+func FormatNotificationDeliveryMode(mode types.NotificationDeliveryMode) string {
+	return types.NotificationDeliveryModeLabels[mode]
+}
+
 func FormatTokenBalance(balance *types.Eth1AddressBalance) template.HTML {
 	p := message.NewPrinter(language.English)
 
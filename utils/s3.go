@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// NewS3Client returns a client of an s3 compatible object storage that is addressed with path style urls
+func NewS3Client(endpoint, accessKeyId, accessKeySecret string) *s3.Client {
+	s3Resolver := aws.EndpointResolverWithOptionsFunc(func(service, region string, options ...interface{}) (aws.Endpoint, error) {
+		return aws.Endpoint{
+			PartitionID:       "aws",
+			URL:               endpoint,
+			SigningRegion:     "us-east-2",
+			HostnameImmutable: true,
+		}, nil
+	})
+	return s3.NewFromConfig(aws.Config{
+		Region:                      "us-east-2",
+		Credentials:                 credentials.NewStaticCredentialsProvider(accessKeyId, accessKeySecret, ""),
+		EndpointResolverWithOptions: s3Resolver,
+	}, func(o *s3.Options) {
+		o.UsePathStyle = true
+	})
+}
@@ -4,9 +4,9 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/hmac"
 	securerand "crypto/rand"
 	"crypto/sha256"
-	"database/sql"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -30,6 +30,7 @@ import (
 	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // the time zones of notification preferences must resolve on hosts without a zoneinfo database
 	"unicode/utf8"
 
 	"github.com/gobitfly/eth2-beaconchain-explorer/config"
@@ -48,7 +49,6 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/kataras/i18n"
 	"github.com/kelseyhightower/envconfig"
-	"github.com/lib/pq"
 	"github.com/mvdan/xurls"
 	"github.com/prysmaticlabs/prysm/v3/beacon-chain/core/signing"
 	prysm_params "github.com/prysmaticlabs/prysm/v3/config/params"
@@ -87,6 +87,7 @@ func GetTemplateFuncs() template.FuncMap {
 		"formatHTML":                              FormatMessageToHtml,
 		"formatBalance":                           FormatBalance,
 		"formatNotificationChannel":               FormatNotificationChannel,
+		"formatNotificationDeliveryMode":          FormatNotificationDeliveryMode,
 		"formatBalanceSql":                        FormatBalanceSql,
 		"formatCurrentBalance":                    FormatCurrentBalance,
 		"formatElCurrency":                        FormatElCurrency,
@@ -109,6 +110,7 @@ func GetTemplateFuncs() template.FuncMap {
 		"formatHash":                              FormatHash,
 		"formatWithdawalCredentials":              FormatWithdawalCredentials,
 		"formatAddressToWithdrawalCredentials":    FormatAddressToWithdrawalCredentials,
+		"withdrawalCredentialsType":               WithdrawalCredentialsType,
 		"formatBitlist":                           FormatBitlist,
 		"formatBitvectorValidators":               formatBitvectorValidators,
 		"formatParticipation":                     FormatParticipation,
@@ -494,6 +496,8 @@ func ReadConfig(cfg *types.Config, path string) error {
 			CappellaForkEpoch:                       mustParseUint(jr.Data.CapellaForkEpoch),
 			DenebForkVersion:                        jr.Data.DenebForkVersion,
 			DenebForkEpoch:                          mustParseUint(jr.Data.DenebForkEpoch),
+			ElectraForkVersion:                      jr.Data.ElectraForkVersion,
+			ElectraForkEpoch:                        mustParseUint(jr.Data.ElectraForkEpoch),
 			SecondsPerSlot:                          mustParseUint(jr.Data.SecondsPerSlot),
 			SecondsPerEth1Block:                     mustParseUint(jr.Data.SecondsPerEth1Block),
 			MinValidatorWithdrawabilityDelay:        mustParseUint(jr.Data.MinValidatorWithdrawabilityDelay),
@@ -520,6 +524,8 @@ func ReadConfig(cfg *types.Config, path string) error {
 			MinDepositAmount:                        mustParseUint(jr.Data.MinDepositAmount),
 			MaxEffectiveBalance:                     mustParseUint(jr.Data.MaxEffectiveBalance),
 			EffectiveBalanceIncrement:               mustParseUint(jr.Data.EffectiveBalanceIncrement),
+			MinActivationBalance:                    mustParseUint(jr.Data.MinActivationBalance),
+			MaxEffectiveBalanceElectra:              mustParseUint(jr.Data.MaxEffectiveBalanceElectra),
 			MinAttestationInclusionDelay:            mustParseUint(jr.Data.MinAttestationInclusionDelay),
 			SlotsPerEpoch:                           mustParseUint(jr.Data.SlotsPerEpoch),
 			MinSeedLookahead:                        mustParseUint(jr.Data.MinSeedLookahead),
@@ -572,6 +578,9 @@ func ReadConfig(cfg *types.Config, path string) error {
 		if jr.Data.DenebForkEpoch == "" {
 			chainCfg.DenebForkEpoch = 18446744073709551615
 		}
+		if jr.Data.ElectraForkEpoch == "" {
+			chainCfg.ElectraForkEpoch = 18446744073709551615
+		}
 
 		cfg.Chain.ClConfig = chainCfg
 
@@ -611,6 +620,18 @@ func ReadConfig(cfg *types.Config, path string) error {
 			return fmt.Errorf("error decoding Chain Config file %v: %v", cfg.Chain.ClConfigPath, err)
 		}
 		cfg.Chain.ClConfig = *chainConfig
+		if cfg.Chain.ClConfig.ElectraForkVersion == "" {
+			// chain configs predating electra do not schedule the fork
+			cfg.Chain.ClConfig.ElectraForkEpoch = 18446744073709551615
+		}
+	}
+
+	// chain configs predating electra do not define the balance limits of compounding validators
+	if cfg.Chain.ClConfig.MinActivationBalance == 0 {
+		cfg.Chain.ClConfig.MinActivationBalance = cfg.Chain.ClConfig.MaxEffectiveBalance
+	}
+	if cfg.Chain.ClConfig.MaxEffectiveBalanceElectra == 0 {
+		cfg.Chain.ClConfig.MaxEffectiveBalanceElectra = cfg.Chain.ClConfig.MinActivationBalance * 64
 	}
 
 	type MinimalELConfig struct {
@@ -768,6 +789,11 @@ func ReadConfig(cfg *types.Config, path string) error {
 
 	cfg.Chain.Id = cfg.Chain.ClConfig.DepositChainID
 
+	if cfg.Indexer.Node.MaxSyncDistance == nil {
+		maxSyncDistance := uint64(2)
+		cfg.Indexer.Node.MaxSyncDistance = &maxSyncDistance
+	}
+
 	if cfg.RedisSessionStoreEndpoint == "" && cfg.RedisCacheEndpoint != "" {
 		logrus.Infof("using RedisCacheEndpoint %s as RedisSessionStoreEndpoint as no dedicated RedisSessionStoreEndpoint was provided", cfg.RedisCacheEndpoint)
 		cfg.RedisSessionStoreEndpoint = cfg.RedisCacheEndpoint
@@ -859,6 +885,7 @@ func IsApiRequest(r *http.Request) bool {
 var eth1AddressRE = regexp.MustCompile("^(0x)?[0-9a-fA-F]{40}$")
 var withdrawalCredentialsRE = regexp.MustCompile("^(0x)?00[0-9a-fA-F]{62}$")
 var withdrawalCredentialsAddressRE = regexp.MustCompile("^(0x)?" + BeginningOfSetWithdrawalCredentials + "[0-9a-fA-F]{40}$")
+var withdrawalCredentialsCompoundingRE = regexp.MustCompile("^(0x)?" + BeginningOfCompoundingWithdrawalCredentials + "[0-9a-fA-F]{40}$")
 var eth1TxRE = regexp.MustCompile("^(0x)?[0-9a-fA-F]{64}$")
 var zeroHashRE = regexp.MustCompile("^(0x)?0+$")
 var hashRE = regexp.MustCompile("^(0x)?[0-9a-fA-F]{96}$")
@@ -892,7 +919,48 @@ func IsHash(s string) bool {
 
 // IsValidWithdrawalCredentials verifies whether a string represents valid withdrawal credentials.
 func IsValidWithdrawalCredentials(s string) bool {
-	return withdrawalCredentialsRE.MatchString(s) || withdrawalCredentialsAddressRE.MatchString(s)
+	return withdrawalCredentialsRE.MatchString(s) || withdrawalCredentialsAddressRE.MatchString(s) || withdrawalCredentialsCompoundingRE.MatchString(s)
+}
+
+// HasExecutionWithdrawalCredentials returns true if the withdrawal credentials point to an execution layer address (0x01 or 0x02)
+func HasExecutionWithdrawalCredentials(credentials []byte) bool {
+	return len(credentials) > 0 && (credentials[0] == 0x01 || credentials[0] == 0x02)
+}
+
+// IsCompoundingWithdrawalCredentials returns true for compounding (0x02) withdrawal credentials
+func IsCompoundingWithdrawalCredentials(credentials []byte) bool {
+	return len(credentials) > 0 && credentials[0] == 0x02
+}
+
+// WithdrawalCredentialsType returns the name of the type of the withdrawal credentials
+func WithdrawalCredentialsType(credentials []byte) string {
+	if len(credentials) == 0 {
+		return "unknown"
+	}
+	switch credentials[0] {
+	case 0x00:
+		return "bls"
+	case 0x01:
+		return "execution"
+	case 0x02:
+		return "compounding"
+	}
+	return "unknown"
+}
+
+// MaxEffectiveBalanceForCredentials returns the maximum effective balance of a validator with the given withdrawal credentials.
+// Only compounding validators can grow their effective balance above the activation balance.
+func MaxEffectiveBalanceForCredentials(credentials []byte) uint64 {
+	if IsCompoundingWithdrawalCredentials(credentials) {
+		return Config.Chain.ClConfig.MaxEffectiveBalanceElectra
+	}
+	return Config.Chain.ClConfig.MinActivationBalance
+}
+
+// IsPartiallyWithdrawable returns true if the withdrawal sweep will skim the excess balance of the validator
+func IsPartiallyWithdrawable(credentials []byte, effectiveBalance, balance uint64) bool {
+	maxEffectiveBalance := MaxEffectiveBalanceForCredentials(credentials)
+	return HasExecutionWithdrawalCredentials(credentials) && effectiveBalance == maxEffectiveBalance && balance > maxEffectiveBalance
 }
 
 // https://github.com/badoux/checkmail/blob/f9f80cb795fa/checkmail.go#L37
@@ -951,124 +1019,6 @@ func GenerateRandomBytesSecure(n int) ([]byte, error) {
 	return b, nil
 }
 
-func SqlRowsToJSON(rows *sql.Rows) ([]interface{}, error) {
-	columnTypes, err := rows.ColumnTypes()
-
-	if err != nil {
-		return nil, fmt.Errorf("error getting column types: %w", err)
-	}
-
-	count := len(columnTypes)
-	finalRows := []interface{}{}
-
-	for rows.Next() {
-
-		scanArgs := make([]interface{}, count)
-
-		for i, v := range columnTypes {
-			switch v.DatabaseTypeName() {
-			case "VARCHAR", "TEXT", "UUID":
-				scanArgs[i] = new(sql.NullString)
-			case "BOOL":
-				scanArgs[i] = new(sql.NullBool)
-			case "INT4", "INT8":
-				scanArgs[i] = new(sql.NullInt64)
-			case "FLOAT8":
-				scanArgs[i] = new(sql.NullFloat64)
-			case "TIMESTAMP":
-				scanArgs[i] = new(sql.NullTime)
-			case "_INT4", "_INT8":
-				scanArgs[i] = new(pq.Int64Array)
-			default:
-				scanArgs[i] = new(sql.NullString)
-			}
-		}
-
-		err := rows.Scan(scanArgs...)
-
-		if err != nil {
-			return nil, fmt.Errorf("error scanning rows: %w", err)
-		}
-
-		masterData := map[string]interface{}{}
-
-		for i, v := range columnTypes {
-
-			//log.Println(v.Name(), v.DatabaseTypeName())
-			if z, ok := (scanArgs[i]).(*sql.NullBool); ok {
-				if z.Valid {
-					masterData[v.Name()] = z.Bool
-				} else {
-					masterData[v.Name()] = nil
-				}
-				continue
-			}
-
-			if z, ok := (scanArgs[i]).(*sql.NullString); ok {
-				if z.Valid {
-					if v.DatabaseTypeName() == "BYTEA" {
-						if len(z.String) > 0 {
-							masterData[v.Name()] = "0x" + hex.EncodeToString([]byte(z.String))
-						} else {
-							masterData[v.Name()] = nil
-						}
-					} else if v.DatabaseTypeName() == "NUMERIC" {
-						nbr, _ := new(big.Int).SetString(z.String, 10)
-						masterData[v.Name()] = nbr
-					} else {
-						masterData[v.Name()] = z.String
-					}
-				} else {
-					masterData[v.Name()] = nil
-				}
-				continue
-			}
-
-			if z, ok := (scanArgs[i]).(*sql.NullInt64); ok {
-				if z.Valid {
-					masterData[v.Name()] = z.Int64
-				} else {
-					masterData[v.Name()] = nil
-				}
-				continue
-			}
-
-			if z, ok := (scanArgs[i]).(*sql.NullInt32); ok {
-				if z.Valid {
-					masterData[v.Name()] = z.Int32
-				} else {
-					masterData[v.Name()] = nil
-				}
-				continue
-			}
-
-			if z, ok := (scanArgs[i]).(*sql.NullFloat64); ok {
-				if z.Valid {
-					masterData[v.Name()] = z.Float64
-				} else {
-					masterData[v.Name()] = nil
-				}
-				continue
-			}
-
-			if z, ok := (scanArgs[i]).(*sql.NullTime); ok {
-				if z.Valid {
-					masterData[v.Name()] = z.Time.Unix()
-				} else {
-					masterData[v.Name()] = nil
-				}
-				continue
-			}
-
-			masterData[v.Name()] = scanArgs[i]
-		}
-
-		finalRows = append(finalRows, masterData)
-	}
-
-	return finalRows, nil
-}
-
 // GenerateAPIKey generates an API key for a user
 func GenerateRandomAPIKey() (string, error) {
 	const apiLength = 28
@@ -1088,6 +1038,64 @@ func GenerateRandomAPIKey() (string, error) {
 	return apiKeyBase64, nil
 }
 
+// GenerateWebhookSecret generates the secret that is used to sign the payloads of a webhook
+func GenerateWebhookSecret() (string, error) {
+	b, err := GenerateRandomBytesSecure(32)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// SignWebhookPayload returns the hex encoded HMAC-SHA256 of "<timestamp>.<payload>" using the secret of the webhook.
+// The timestamp is part of the signed content so receivers can reject replayed requests.
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("%d.", timestamp)))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// DailyDigestHour is the hour of the day in the time zone of the user daily notification digests are released at
+const DailyDigestHour = 8
+
+// NotificationReleaseTime returns the time a notification collected at now is delivered at on a channel with the given preferences.
+// Digests are released at the start of the next hour or at DailyDigestHour, releases that fall into the quiet hours are moved to their end.
+func NotificationReleaseTime(prefs *types.UserNotificationPreferences, now time.Time) time.Time {
+	loc := time.UTC
+	if prefs.Timezone.Valid {
+		if l, err := time.LoadLocation(prefs.Timezone.String); err == nil {
+			loc = l
+		}
+	}
+	t := now.In(loc)
+
+	switch prefs.DeliveryMode {
+	case types.HourlyDigestDeliveryMode:
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+	case types.DailyDigestDeliveryMode:
+		release := time.Date(t.Year(), t.Month(), t.Day(), DailyDigestHour, 0, 0, 0, loc)
+		if !release.After(t) {
+			release = time.Date(t.Year(), t.Month(), t.Day()+1, DailyDigestHour, 0, 0, 0, loc)
+		}
+		t = release
+	}
+
+	if prefs.QuietHoursStart.Valid && prefs.QuietHoursEnd.Valid && prefs.QuietHoursStart.Int16 != prefs.QuietHoursEnd.Int16 {
+		start, end := int(prefs.QuietHoursStart.Int16), int(prefs.QuietHoursEnd.Int16)
+		h := t.Hour()
+		quiet := (start < end && h >= start && h < end) || (start > end && (h >= start || h < end))
+		if quiet {
+			release := time.Date(t.Year(), t.Month(), t.Day(), end, 0, 0, 0, loc)
+			if !release.After(t) {
+				release = time.Date(t.Year(), t.Month(), t.Day()+1, end, 0, 0, 0, loc)
+			}
+			t = release
+		}
+	}
+	return t.In(now.Location())
+}
+
 // Glob walks through a directory and returns files with a given extension
 func Glob(dir string, ext string) ([]string, error) {
 	files := []string{}
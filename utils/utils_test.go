@@ -1,7 +1,11 @@
 package utils
 
 import (
+	"database/sql"
 	"testing"
+	"time"
+
+	"github.com/gobitfly/eth2-beaconchain-explorer/types"
 )
 
 func TestIsValidUrl(t *testing.T) {
@@ -26,3 +30,76 @@ func TestIsValidUrl(t *testing.T) {
 		}
 	}
 }
+
+func TestIsPartiallyWithdrawable(t *testing.T) {
+	Config = &types.Config{}
+	Config.Chain.ClConfig.MinActivationBalance = 32e9
+	Config.Chain.ClConfig.MaxEffectiveBalanceElectra = 2048e9
+
+	bls := []byte{0x00, 0x01}
+	execution := []byte{0x01, 0x01}
+	compounding := []byte{0x02, 0x01}
+
+	tests := []struct {
+		name             string
+		credentials      []byte
+		effectiveBalance uint64
+		balance          uint64
+		withdrawable     bool
+	}{
+		{"bls above activation balance", bls, 32e9, 33e9, false},
+		{"execution above activation balance", execution, 32e9, 33e9, true},
+		{"execution at activation balance", execution, 32e9, 32e9, false},
+		{"execution below max effective balance", execution, 31e9, 33e9, false},
+		{"compounding above activation balance", compounding, 33e9, 34e9, false},
+		{"compounding above max effective balance", compounding, 2048e9, 2049e9, true},
+	}
+	for _, tt := range tests {
+		if v := IsPartiallyWithdrawable(tt.credentials, tt.effectiveBalance, tt.balance); v != tt.withdrawable {
+			t.Errorf("wrong partial withdrawal eligibility for %v: got %v, want %v", tt.name, v, tt.withdrawable)
+		}
+	}
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	signature := SignWebhookPayload("whsec_test", 1700000000, []byte(`{"event":"validator_got_slashed"}`))
+	if signature != "2d4ed22b49ba6ea55b95bf56c983a61dd83fd0bee917ec40f5e664e9116b1069" {
+		t.Errorf("unexpected signature %v", signature)
+	}
+}
+
+func TestNotificationReleaseTime(t *testing.T) {
+	// 2024-11-29 22:30 in Berlin
+	now := time.Date(2024, 11, 29, 21, 30, 0, 0, time.UTC)
+	prefs := func(mode types.NotificationDeliveryMode, timezone string, quietStart, quietEnd int16) types.UserNotificationPreferences {
+		p := types.UserNotificationPreferences{
+			DeliveryMode: mode,
+			Timezone:     sql.NullString{String: timezone, Valid: timezone != ""},
+		}
+		if quietStart >= 0 {
+			p.QuietHoursStart = sql.NullInt16{Int16: quietStart, Valid: true}
+			p.QuietHoursEnd = sql.NullInt16{Int16: quietEnd, Valid: true}
+		}
+		return p
+	}
+
+	tests := []struct {
+		name    string
+		prefs   types.UserNotificationPreferences
+		release time.Time
+	}{
+		{"immediate", prefs(types.ImmediateDeliveryMode, "Europe/Berlin", -1, -1), now},
+		{"hourly", prefs(types.HourlyDigestDeliveryMode, "Europe/Berlin", -1, -1), time.Date(2024, 11, 29, 22, 0, 0, 0, time.UTC)},
+		{"daily", prefs(types.DailyDigestDeliveryMode, "Europe/Berlin", -1, -1), time.Date(2024, 11, 30, 7, 0, 0, 0, time.UTC)},
+		{"daily without timezone", prefs(types.DailyDigestDeliveryMode, "", -1, -1), time.Date(2024, 11, 30, 8, 0, 0, 0, time.UTC)},
+		{"invalid timezone", prefs(types.HourlyDigestDeliveryMode, "Mars/Olympus", -1, -1), time.Date(2024, 11, 29, 22, 0, 0, 0, time.UTC)},
+		{"outside quiet hours", prefs(types.ImmediateDeliveryMode, "Europe/Berlin", 9, 17), now},
+		{"overnight quiet hours", prefs(types.ImmediateDeliveryMode, "Europe/Berlin", 22, 7), time.Date(2024, 11, 30, 6, 0, 0, 0, time.UTC)},
+		{"digest released in quiet hours", prefs(types.HourlyDigestDeliveryMode, "Europe/Berlin", 23, 6), time.Date(2024, 11, 30, 5, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if release := NotificationReleaseTime(&tt.prefs, now); !release.Equal(tt.release) {
+			t.Errorf("%v: got release time %v, want %v", tt.name, release, tt.release)
+		}
+	}
+}